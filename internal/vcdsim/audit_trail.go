@@ -0,0 +1,183 @@
+package vcdsim
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditTimestampFormat is the format of the timestamps of audit events
+const auditTimestampFormat = "2006-01-02T15:04:05.000Z"
+
+// AuditEvent is an event of the simulated audit trail. Events are not generated by the simulated
+// operations, and must be added with AddAuditEvent
+type AuditEvent struct {
+	EventType   string
+	Status      string // SUCCESS or FAILURE
+	Timestamp   time.Time
+	OrgName     string
+	UserName    string
+	EntityId    string
+	EntityName  string
+	Description string
+}
+
+type auditEventEntry struct {
+	id     string
+	userId string
+	AuditEvent
+}
+
+func (sim *Simulator) registerAuditTrailRoutes() {
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/auditTrail/?`, sim.getAuditTrail)
+}
+
+// AddAuditEvent adds an event to the audit trail and returns its ID
+func (sim *Simulator) AddAuditEvent(event AuditEvent) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	entry := &auditEventEntry{id: newId(), userId: newId(), AuditEvent: event}
+	sim.auditEvents = append(sim.auditEvents, entry)
+	return entry.id
+}
+
+func (sim *Simulator) auditEventView(event *auditEventEntry) map[string]interface{} {
+	org := map[string]string{"name": event.OrgName}
+	if entry := sim.findOrgByName(event.OrgName); entry != nil {
+		org["id"] = entry.urn()
+	}
+	return map[string]interface{}{
+		"eventId":          event.id,
+		"description":      event.Description,
+		"operatingOrg":     org,
+		"user":             map[string]string{"name": event.UserName, "id": "urn:vcloud:user:" + event.userId},
+		"eventEntity":      map[string]string{"name": event.EntityName, "id": event.EntityId},
+		"taskId":           nil,
+		"eventType":        event.EventType,
+		"serviceNamespace": "com.vmware.cloud",
+		"eventStatus":      event.Status,
+		"timestamp":        event.Timestamp.UTC().Format(auditTimestampFormat),
+		"external":         false,
+		"additionalProperties": map[string]string{
+			"currentContext.user.clientIpAddress": "192.0.2.1",
+		},
+	}
+}
+
+// getAuditTrail returns a page of the audit events matching the 'filter' query parameter, sorted by timestamp
+func (sim *Simulator) getAuditTrail(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	conditions, ok := parseFiqlConditions(query.Get("filter"))
+	if !ok {
+		sim.writeError(w, r, http.StatusBadRequest, "unsupported filter "+query.Get("filter"))
+		return
+	}
+	var events []*auditEventEntry
+	for _, event := range sim.auditEvents {
+		fields := map[string]string{
+			"eventType":         event.EventType,
+			"eventStatus":       event.Status,
+			"timestamp":         event.Timestamp.UTC().Format(auditTimestampFormat),
+			"operatingOrg.name": event.OrgName,
+			"user.name":         event.UserName,
+			"eventEntity.id":    event.EntityId,
+			"eventEntity.name":  event.EntityName,
+		}
+		if matchesFiqlConditions(conditions, fields) {
+			events = append(events, event)
+		}
+	}
+	descending := query.Get("sortDesc") == "timestamp"
+	sort.SliceStable(events, func(i, j int) bool {
+		if descending {
+			return events[i].Timestamp.After(events[j].Timestamp)
+		}
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	page, pageSize := 1, 128
+	if value, err := strconv.Atoi(query.Get("page")); err == nil && value > 0 {
+		page = value
+	}
+	if value, err := strconv.Atoi(query.Get("pageSize")); err == nil && value > 0 {
+		pageSize = value
+	}
+	pageCount := (len(events) + pageSize - 1) / pageSize
+	values := []interface{}{}
+	for i := (page - 1) * pageSize; i < len(events) && i < page*pageSize; i++ {
+		values = append(values, sim.auditEventView(events[i]))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resultTotal": len(events),
+		"pageCount":   pageCount,
+		"page":        page,
+		"pageSize":    pageSize,
+		"values":      values,
+	})
+}
+
+// fiqlCondition is a comparison of a FIQL filter, such as "timestamp=ge=2024-01-01T00:00:00.000Z"
+type fiqlCondition struct {
+	field    string
+	operator string
+	value    string
+}
+
+// parseFiqlConditions returns the conditions of a FIQL filter. Only conjunctions of comparisons are supported
+func parseFiqlConditions(filter string) ([]fiqlCondition, bool) {
+	var conditions []fiqlCondition
+	if filter == "" {
+		return conditions, true
+	}
+	for _, text := range strings.Split(filter, ";") {
+		text = strings.Trim(text, "()")
+		found := false
+		for _, operator := range []string{"==", "!=", "=ge=", "=gt=", "=le=", "=lt="} {
+			field, value, ok := strings.Cut(text, operator)
+			if ok {
+				conditions = append(conditions, fiqlCondition{field: field, operator: operator, value: value})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return conditions, true
+}
+
+// matchesFiqlConditions returns true if all the conditions on known fields match. Equality supports '*'
+// wildcards, and the other comparisons are lexical, which is correct for timestamps in the same format
+func matchesFiqlConditions(conditions []fiqlCondition, fields map[string]string) bool {
+	for _, condition := range conditions {
+		actual, known := fields[condition.field]
+		if !known {
+			continue
+		}
+		var matches bool
+		switch condition.operator {
+		case "==", "!=":
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(condition.value), `\*`, ".*") + "$"
+			matches = regexp.MustCompile(pattern).MatchString(actual)
+			if condition.operator == "!=" {
+				matches = !matches
+			}
+		case "=ge=":
+			matches = actual >= condition.value
+		case "=gt=":
+			matches = actual > condition.value
+		case "=le=":
+			matches = actual <= condition.value
+		case "=lt=":
+			matches = actual < condition.value
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package vcdsim
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// catalogEntry is the state of a simulated catalog. Media items are the only catalog items simulated
+type catalogEntry struct {
+	id          string
+	name        string
+	description string
+	org         *orgEntry
+	created     time.Time
+}
+
+func (sim *Simulator) registerCatalogRoutes() {
+	sim.handle(http.MethodPost, `/api/admin/org/([^/]+)/catalogs`, sim.createCatalog)
+	sim.handle(http.MethodGet, `/api/(?:admin/)?catalog/([^/]+)`, sim.getCatalog)
+	sim.handle(http.MethodPut, `/api/admin/catalog/([^/]+)`, sim.updateCatalog)
+	sim.handle(http.MethodDelete, `/api/admin/catalog/([^/]+)`, sim.deleteCatalog)
+}
+
+// AddCatalog adds an empty catalog to an organization and returns its ID
+func (sim *Simulator) AddCatalog(orgName, catalogName string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	catalog := &catalogEntry{
+		id:      newId(),
+		name:    catalogName,
+		org:     sim.mustFindOrg(orgName),
+		created: time.Now(),
+	}
+	sim.catalogs[catalog.id] = catalog
+	return catalog.urn()
+}
+
+func (catalog *catalogEntry) urn() string {
+	return "urn:vcloud:catalog:" + catalog.id
+}
+
+// orgCatalogs returns the catalogs of an organization, sorted by name
+func (sim *Simulator) orgCatalogs(org *orgEntry) []*catalogEntry {
+	var catalogs []*catalogEntry
+	for _, catalog := range sim.catalogs {
+		if catalog.org == org {
+			catalogs = append(catalogs, catalog)
+		}
+	}
+	sort.Slice(catalogs, func(i, j int) bool { return catalogs[i].name < catalogs[j].name })
+	return catalogs
+}
+
+func (sim *Simulator) catalogReference(catalog *catalogEntry) *types.Reference {
+	return &types.Reference{
+		HREF: sim.href("/api/admin/catalog/%s", catalog.id),
+		ID:   catalog.urn(),
+		Type: types.MimeAdminCatalog,
+		Name: catalog.name,
+	}
+}
+
+func (sim *Simulator) catalogView(catalog *catalogEntry) types.AdminCatalog {
+	items := &types.CatalogItems{}
+	for _, media := range sim.catalogMedia(catalog) {
+		items.CatalogItem = append(items.CatalogItem, sim.catalogItemReference(media))
+	}
+	return types.AdminCatalog{
+		Xmlns: types.XMLNamespaceVCloud,
+		Catalog: types.Catalog{
+			HREF:         sim.href("/api/admin/catalog/%s", catalog.id),
+			Type:         types.MimeAdminCatalog,
+			ID:           catalog.urn(),
+			Name:         catalog.name,
+			CatalogItems: []*types.CatalogItems{items},
+			Description:  catalog.description,
+			DateCreated:  catalog.created.Format(time.RFC3339),
+			Link: types.LinkList{
+				{Rel: "up", Type: types.MimeAdminOrg, HREF: sim.href("/api/admin/org/%s", catalog.org.id)},
+				{Rel: "add", Type: types.MimeMediaItem, HREF: sim.href("/api/catalog/%s/action/upload", catalog.id)},
+			},
+			Owner: &types.Owner{User: &types.Reference{Type: "application/vnd.vmware.admin.user+xml", Name: AdminUser}},
+		},
+	}
+}
+
+func (sim *Simulator) createCatalog(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	var create types.AdminCatalog
+	if err := readXML(r, &create); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, catalog := range sim.orgCatalogs(org) {
+		if catalog.name == create.Name {
+			sim.writeError(w, r, http.StatusBadRequest, "DUPLICATE_NAME: the catalog name "+create.Name+" is already used")
+			return
+		}
+	}
+
+	catalog := &catalogEntry{
+		id:          newId(),
+		name:        create.Name,
+		description: create.Description,
+		org:         org,
+		created:     time.Now(),
+	}
+	task := sim.runTask("catalogCreateCatalog", sim.catalogReference(catalog), func() {
+		sim.catalogs[catalog.id] = catalog
+	})
+	result := sim.catalogView(catalog)
+	result.Tasks = &types.TasksInProgress{Task: []*types.Task{task}}
+	writeXML(w, http.StatusCreated, result)
+}
+
+func (sim *Simulator) getCatalog(w http.ResponseWriter, r *http.Request, params []string) {
+	catalog, ok := sim.catalogs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "catalog "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.catalogView(catalog))
+}
+
+// updateCatalog changes the name and the description of a catalog. The update is synchronous, as in VCD
+func (sim *Simulator) updateCatalog(w http.ResponseWriter, r *http.Request, params []string) {
+	catalog, ok := sim.catalogs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "catalog "+params[0])
+		return
+	}
+	var update types.AdminCatalog
+	if err := readXML(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.Name != "" {
+		catalog.name = update.Name
+	}
+	catalog.description = update.Description
+	writeXML(w, http.StatusOK, sim.catalogView(catalog))
+}
+
+func (sim *Simulator) deleteCatalog(w http.ResponseWriter, r *http.Request, params []string) {
+	catalog, ok := sim.catalogs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "catalog "+params[0])
+		return
+	}
+	sim.writeTask(w, "catalogDelete", sim.catalogReference(catalog), func() {
+		delete(sim.catalogs, catalog.id)
+		for _, media := range sim.catalogMedia(catalog) {
+			delete(sim.media, media.id)
+		}
+		sim.deleteMetadataOf("/api/catalog/" + catalog.id)
+	})
+}
@@ -0,0 +1,216 @@
+package vcdsim
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// edgeGatewayEntry is the state of a simulated NSX-T edge gateway. The gateway has a single uplink to
+// an IP Space backed Tier-0 external network, so that no IP allocation needs to be simulated
+type edgeGatewayEntry struct {
+	id          string
+	name        string
+	description string
+	vdc         *vdcEntry
+	// firewallRules are the ordered user defined firewall rules
+	firewallRules []*types.NsxtFirewallRule
+}
+
+func (sim *Simulator) registerEdgeGatewayRoutes() {
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/edgeGateways/?`, sim.getEdgeGateways)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/edgeGateways/([^/]+)`, sim.getEdgeGateway)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/edgeGateways/([^/]+)/firewall/rules/?`, sim.getEdgeGatewayFirewall)
+	sim.handle(http.MethodPut, `/cloudapi/1.0.0/edgeGateways/([^/]+)/firewall/rules/?`, sim.updateEdgeGatewayFirewall)
+	sim.handle(http.MethodDelete, `/cloudapi/1.0.0/edgeGateways/([^/]+)/firewall/rules/?`, sim.deleteEdgeGatewayFirewall)
+	sim.handle(http.MethodDelete, `/cloudapi/1.0.0/edgeGateways/([^/]+)/firewall/rules/([^/]+)`, sim.deleteEdgeGatewayFirewallRule)
+}
+
+// AddNsxtEdgeGateway adds an NSX-T edge gateway to a VDC and returns its ID
+func (sim *Simulator) AddNsxtEdgeGateway(orgName, vdcName, name string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vdc := sim.mustFindVdc(orgName, vdcName)
+	if !vdc.nsxt {
+		panic("vcdsim: VDC " + vdcName + " is not backed by NSX-T")
+	}
+	edge := &edgeGatewayEntry{
+		id:   newId(),
+		name: name,
+		vdc:  vdc,
+	}
+	sim.edgeGateways[edge.id] = edge
+	return edge.urn()
+}
+
+func (edge *edgeGatewayEntry) urn() string {
+	return "urn:vcloud:gateway:" + edge.id
+}
+
+func (sim *Simulator) edgeGatewayView(edge *edgeGatewayEntry) types.OpenAPIEdgeGateway {
+	vdcRef := &types.OpenApiReference{ID: edge.vdc.urn(), Name: edge.vdc.name}
+	return types.OpenAPIEdgeGateway{
+		Status:      "REALIZED",
+		ID:          edge.urn(),
+		Name:        edge.name,
+		Description: edge.description,
+		OwnerRef:    vdcRef,
+		OrgVdc:      vdcRef,
+		Org:         &types.OpenApiReference{ID: edge.vdc.org.urn(), Name: edge.vdc.org.name},
+		EdgeGatewayUplinks: []types.EdgeGatewayUplinks{{
+			UplinkID:     "urn:vcloud:network:" + edge.id,
+			UplinkName:   "simulated-provider-gateway",
+			Connected:    true,
+			BackingType:  addrOf(types.ExternalNetworkBackingTypeNsxtTier0Router),
+			UsingIpSpace: addrOf(true),
+		}},
+		GatewayBacking: &types.OpenAPIEdgeGatewayBacking{
+			BackingID:   "simulated-tier1-" + edge.id,
+			GatewayType: "NSXT_BACKED",
+		},
+		EdgeClusterConfig: &types.OpenAPIEdgeGatewayEdgeClusterConfig{
+			PrimaryEdgeCluster: types.OpenAPIEdgeGatewayEdgeCluster{BackingID: "simulated-edge-cluster"},
+		},
+	}
+}
+
+// getEdgeGateways returns the edge gateways matching the 'filter' query parameter
+func (sim *Simulator) getEdgeGateways(w http.ResponseWriter, r *http.Request, _ []string) {
+	filter := parseFilter(r.URL.Query())
+	var edges []*edgeGatewayEntry
+	for _, edge := range sim.edgeGateways {
+		if matchesFilter(filter, map[string]string{
+			"name":        edge.name,
+			"id":          edge.urn(),
+			"ownerRef.id": edge.vdc.urn(),
+			"orgVdc.id":   edge.vdc.urn(),
+			"orgRef.id":   edge.vdc.org.urn(),
+		}) {
+			edges = append(edges, edge)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].name < edges[j].name })
+
+	var values []interface{}
+	for _, edge := range edges {
+		values = append(values, sim.edgeGatewayView(edge))
+	}
+	writePage(w, values)
+}
+
+func (sim *Simulator) getEdgeGateway(w http.ResponseWriter, r *http.Request, params []string) {
+	edge, ok := sim.edgeGateways[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "edge gateway "+params[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, sim.edgeGatewayView(edge))
+}
+
+// getEdgeGatewayFirewall returns the user defined firewall rules. System and default rules are not simulated
+func (sim *Simulator) getEdgeGatewayFirewall(w http.ResponseWriter, r *http.Request, params []string) {
+	edge, ok := sim.edgeGateways[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "edge gateway "+params[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, types.NsxtFirewallRuleContainer{UserDefinedRules: edge.firewallRules})
+}
+
+// updateEdgeGatewayFirewall replaces the whole list of user defined firewall rules, keeping the order. As in
+// VCD, rules without an ID get a new one, while rules with an unknown ID are rejected
+func (sim *Simulator) updateEdgeGatewayFirewall(w http.ResponseWriter, r *http.Request, params []string) {
+	edge, ok := sim.edgeGateways[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "edge gateway "+params[0])
+		return
+	}
+	var container types.NsxtFirewallRuleContainer
+	if err := readJSON(r, &container); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	known := make(map[string]bool)
+	for _, rule := range edge.firewallRules {
+		known[rule.ID] = true
+	}
+	for _, rule := range container.UserDefinedRules {
+		if rule.ID == "" {
+			rule.ID = newId()
+			continue
+		}
+		if !known[rule.ID] {
+			sim.writeError(w, r, http.StatusBadRequest, "unknown firewall rule "+rule.ID)
+			return
+		}
+	}
+	edge.firewallRules = container.UserDefinedRules
+	writeJSON(w, http.StatusOK, types.NsxtFirewallRuleContainer{UserDefinedRules: edge.firewallRules})
+}
+
+func (sim *Simulator) deleteEdgeGatewayFirewall(w http.ResponseWriter, r *http.Request, params []string) {
+	edge, ok := sim.edgeGateways[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "edge gateway "+params[0])
+		return
+	}
+	edge.firewallRules = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (sim *Simulator) deleteEdgeGatewayFirewallRule(w http.ResponseWriter, r *http.Request, params []string) {
+	edge, ok := sim.edgeGateways[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "edge gateway "+params[0])
+		return
+	}
+	for index, rule := range edge.firewallRules {
+		if rule.ID == params[1] {
+			edge.firewallRules = append(edge.firewallRules[:index:index], edge.firewallRules[index+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	sim.notFound(w, r, "firewall rule "+params[1])
+}
+
+// parseFilter returns the conditions of an OpenAPI FIQL 'filter' query parameter. Only conjunctions of
+// equality conditions ("a==x;b==y") are supported
+func parseFilter(query url.Values) map[string]string {
+	conditions := make(map[string]string)
+	filter := query.Get("filter")
+	if filter == "" {
+		return conditions
+	}
+	for _, condition := range strings.Split(filter, ";") {
+		key, value, found := strings.Cut(condition, "==")
+		if found {
+			conditions[strings.Trim(key, "()")] = strings.Trim(value, "()")
+		}
+	}
+	return conditions
+}
+
+// matchesFilter returns true if all the filter conditions on known fields match. Values that contain
+// a UUID are compared by UUID, so that URNs and HREFs can be used interchangeably
+func matchesFilter(filter, fields map[string]string) bool {
+	for key, wanted := range filter {
+		actual, known := fields[key]
+		if !known {
+			continue
+		}
+		if wantedUuid := uuidOf(wanted); wantedUuid != "" && uuidOf(actual) != "" {
+			if !strings.EqualFold(wantedUuid, uuidOf(actual)) {
+				return false
+			}
+			continue
+		}
+		if actual != wanted {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,460 @@
+package vcdsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// ipSpaceEntry is the state of a simulated IP Space: its definition, as given to AddIpSpace, and its IP
+// allocations. Org assignments are implicit: each organization with allocations is assigned the default
+// quotas of the IP Space
+type ipSpaceEntry struct {
+	id          string
+	definition  types.IpSpace
+	allocations []*ipAllocationEntry
+	// assignmentIds are the IDs of the org assignments, by organization ID
+	assignmentIds map[string]string
+}
+
+// ipAllocationEntry is an allocated floating IP or IP prefix, with its first and last address
+type ipAllocationEntry struct {
+	allocation  types.IpSpaceIpAllocation
+	org         *orgEntry
+	first, last netip.Addr
+}
+
+// ipBlock is a requested floating IP or IP prefix
+type ipBlock struct {
+	value       string
+	first, last netip.Addr
+}
+
+func (sim *Simulator) registerIpSpaceRoutes() {
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/ipSpaces/summaries/?`, sim.getIpSpaceSummaries)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/ipSpaces/orgAssignments/?`, sim.getIpSpaceOrgAssignments)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)`, sim.getIpSpace)
+	sim.handle(http.MethodPost, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)/allocate`, sim.allocateIpSpaceIp)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)/allocations/?`, sim.getIpSpaceAllocations)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)/allocations/([^/]+)`, sim.getIpSpaceAllocation)
+	sim.handle(http.MethodPut, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)/allocations/([^/]+)`, sim.updateIpSpaceAllocation)
+	sim.handle(http.MethodDelete, `/cloudapi/1.0.0/ipSpaces/(urn:[^/]+)/allocations/([^/]+)`, sim.deleteIpSpaceAllocation)
+}
+
+// AddIpSpace adds an IP Space with the given name, IP ranges, IP prefix sequences and default quotas, and
+// returns its ID
+func (sim *Simulator) AddIpSpace(definition types.IpSpace) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	ipSpace := &ipSpaceEntry{id: newId(), definition: definition, assignmentIds: make(map[string]string)}
+	if ipSpace.definition.Type == "" {
+		ipSpace.definition.Type = types.IpSpacePublic
+	}
+	ipSpace.definition.ID = ipSpace.urn()
+	sim.ipSpaces[ipSpace.id] = ipSpace
+	return ipSpace.urn()
+}
+
+// IpSpaceAllocations returns the values of the allocations of the IP Space with the given ID, sorted by
+// address
+func (sim *Simulator) IpSpaceAllocations(ipSpaceId string) []string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	ipSpace, ok := sim.ipSpaces[uuidOf(ipSpaceId)]
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, entry := range ipSpace.sortedAllocations() {
+		values = append(values, entry.allocation.Value)
+	}
+	return values
+}
+
+func (ipSpace *ipSpaceEntry) urn() string {
+	return "urn:vcloud:ipSpace:" + ipSpace.id
+}
+
+func (ipSpace *ipSpaceEntry) sortedAllocations() []*ipAllocationEntry {
+	result := append([]*ipAllocationEntry{}, ipSpace.allocations...)
+	sort.Slice(result, func(i, j int) bool { return result[i].first.Less(result[j].first) })
+	return result
+}
+
+func (ipSpace *ipSpaceEntry) findAllocation(id string) (*ipAllocationEntry, int, bool) {
+	for index, entry := range ipSpace.allocations {
+		if uuidOf(entry.allocation.ID) == uuidOf(id) {
+			return entry, index, true
+		}
+	}
+	return nil, -1, false
+}
+
+// isFree reports whether no allocation overlaps the addresses from first to last
+func (ipSpace *ipSpaceEntry) isFree(first, last netip.Addr) bool {
+	for _, entry := range ipSpace.allocations {
+		if !entry.last.Less(first) && !last.Less(entry.first) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRanges reports whether the addresses from first to last are all in one IP range
+func (ipSpace *ipSpaceEntry) inRanges(first, last netip.Addr) bool {
+	for _, ipRange := range ipSpace.definition.IPSpaceRanges.IPRanges {
+		start, errStart := netip.ParseAddr(ipRange.StartIPAddress)
+		end, errEnd := netip.ParseAddr(ipRange.EndIPAddress)
+		if errStart == nil && errEnd == nil && !first.Less(start) && !end.Less(last) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixes returns all the prefixes of the IP prefix sequences with the given length
+func (ipSpace *ipSpaceEntry) prefixes(length int) []netip.Prefix {
+	var result []netip.Prefix
+	for _, ipSpacePrefixes := range ipSpace.definition.IPSpacePrefixes {
+		for _, sequence := range ipSpacePrefixes.IPPrefixSequence {
+			start, err := netip.ParseAddr(sequence.StartingPrefixIPAddress)
+			if err != nil || sequence.PrefixLength != length {
+				continue
+			}
+			for i := 0; i < sequence.TotalPrefixCount; i++ {
+				prefix := netip.PrefixFrom(start, length)
+				result = append(result, prefix)
+				start = prefixLast(prefix).Next()
+			}
+		}
+	}
+	return result
+}
+
+// prefixLast returns the last address of a prefix
+func prefixLast(prefix netip.Prefix) netip.Addr {
+	last := prefix.Addr().As4()
+	for bit := prefix.Bits(); bit < 32; bit++ {
+		last[bit/8] |= 1 << (7 - bit%8)
+	}
+	return netip.AddrFrom4(last)
+}
+
+// freeIps returns the first 'quantity' free addresses of the IP ranges, which are not necessarily
+// contiguous
+func (ipSpace *ipSpaceEntry) freeIps(quantity int) []netip.Addr {
+	var result []netip.Addr
+	for _, ipRange := range ipSpace.definition.IPSpaceRanges.IPRanges {
+		start, errStart := netip.ParseAddr(ipRange.StartIPAddress)
+		end, errEnd := netip.ParseAddr(ipRange.EndIPAddress)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		for address := start; !end.Less(address) && len(result) < quantity; address = address.Next() {
+			if ipSpace.isFree(address, address) {
+				result = append(result, address)
+			}
+		}
+	}
+	return result
+}
+
+// requestedBlocks returns the floating IPs or IP prefixes requested, or an error if the request can't be
+// satisfied. As in VCD, a floating IP range in 'value' results in one allocation per address, and a
+// 'quantity' of floating IPs gets the first free addresses
+func (ipSpace *ipSpaceEntry) requestedBlocks(request types.IpSpaceIpAllocationRequest) ([]ipBlock, error) {
+	quantity := 1
+	if request.Quantity != nil {
+		quantity = *request.Quantity
+	}
+	var blocks []ipBlock
+	switch request.Type {
+	case types.IpSpaceIpAllocationTypeFloatingIp:
+		if request.Value == "" {
+			free := ipSpace.freeIps(quantity)
+			if len(free) < quantity {
+				return nil, fmt.Errorf("IP Space %s has only %d free IP addresses", ipSpace.definition.Name, len(free))
+			}
+			for _, address := range free {
+				blocks = append(blocks, ipBlock{value: address.String(), first: address, last: address})
+			}
+			return blocks, nil
+		}
+		startValue, endValue, isRange := strings.Cut(request.Value, "-")
+		if !isRange {
+			endValue = startValue
+		}
+		start, errStart := netip.ParseAddr(startValue)
+		end, errEnd := netip.ParseAddr(endValue)
+		if errStart != nil || errEnd != nil || end.Less(start) {
+			return nil, fmt.Errorf("invalid IP address or range %s", request.Value)
+		}
+		if !ipSpace.inRanges(start, end) {
+			return nil, fmt.Errorf("%s is not in the IP ranges of IP Space %s", request.Value, ipSpace.definition.Name)
+		}
+		for address := start; !end.Less(address); address = address.Next() {
+			if !ipSpace.isFree(address, address) {
+				return nil, fmt.Errorf("IP address %s is already allocated", address)
+			}
+			blocks = append(blocks, ipBlock{value: address.String(), first: address, last: address})
+		}
+		return blocks, nil
+	case types.IpSpaceIpAllocationTypeIpPrefix:
+		if request.Value != "" {
+			requested, err := netip.ParsePrefix(request.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP prefix %s", request.Value)
+			}
+			for _, prefix := range ipSpace.prefixes(requested.Bits()) {
+				if prefix == requested {
+					if !ipSpace.isFree(prefix.Addr(), prefixLast(prefix)) {
+						return nil, fmt.Errorf("IP prefix %s is already allocated", prefix)
+					}
+					return []ipBlock{{value: prefix.String(), first: prefix.Addr(), last: prefixLast(prefix)}}, nil
+				}
+			}
+			return nil, fmt.Errorf("%s is not in the IP prefixes of IP Space %s", request.Value, ipSpace.definition.Name)
+		}
+		if request.PrefixLength == nil {
+			return nil, fmt.Errorf("a prefix length is required to allocate an IP prefix")
+		}
+		for _, prefix := range ipSpace.prefixes(*request.PrefixLength) {
+			if len(blocks) < quantity && ipSpace.isFree(prefix.Addr(), prefixLast(prefix)) {
+				blocks = append(blocks, ipBlock{value: prefix.String(), first: prefix.Addr(), last: prefixLast(prefix)})
+			}
+		}
+		if len(blocks) < quantity {
+			return nil, fmt.Errorf("IP Space %s has only %d free IP prefixes of length %d", ipSpace.definition.Name,
+				len(blocks), *request.PrefixLength)
+		}
+		return blocks, nil
+	}
+	return nil, fmt.Errorf("invalid allocation type %s", request.Type)
+}
+
+// tenantOrg returns the organization of the tenant context of a request
+func (sim *Simulator) tenantOrg(r *http.Request) (*orgEntry, bool) {
+	org, ok := sim.orgs[uuidOf(r.Header.Get(types.HeaderTenantContext))]
+	return org, ok
+}
+
+func (sim *Simulator) ipSpaceOrgAssignment(ipSpace *ipSpaceEntry, org *orgEntry) types.IpSpaceOrgAssignment {
+	defaultQuotas := &types.IpSpaceOrgAssignmentQuotas{
+		FloatingIPQuota: addrOf(ipSpace.definition.IPSpaceRanges.DefaultFloatingIPQuota),
+	}
+	for _, ipSpacePrefixes := range ipSpace.definition.IPSpacePrefixes {
+		for _, sequence := range ipSpacePrefixes.IPPrefixSequence {
+			defaultQuotas.IPPrefixQuotas = append(defaultQuotas.IPPrefixQuotas, types.IpSpaceOrgAssignmentIPPrefixQuotas{
+				PrefixLength: addrOf(sequence.PrefixLength),
+				Quota:        addrOf(ipSpacePrefixes.DefaultQuotaForPrefixLength),
+			})
+		}
+	}
+	return types.IpSpaceOrgAssignment{
+		ID:            "urn:vcloud:ipSpaceOrgAssignment:" + ipSpace.assignmentIds[org.id],
+		IPSpaceRef:    &types.OpenApiReference{ID: ipSpace.urn(), Name: ipSpace.definition.Name},
+		OrgRef:        &types.OpenApiReference{ID: org.urn(), Name: org.name},
+		IPSpaceType:   ipSpace.definition.Type,
+		DefaultQuotas: defaultQuotas,
+	}
+}
+
+func (sim *Simulator) getIpSpace(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, ipSpace.definition)
+}
+
+// getIpSpaceSummaries returns the IP Spaces matching the 'filter' query parameter, sorted by name
+func (sim *Simulator) getIpSpaceSummaries(w http.ResponseWriter, r *http.Request, _ []string) {
+	filter := parseFilter(r.URL.Query())
+	var ipSpaces []*ipSpaceEntry
+	for _, ipSpace := range sim.ipSpaces {
+		if matchesFilter(filter, map[string]string{"name": ipSpace.definition.Name, "id": ipSpace.urn()}) {
+			ipSpaces = append(ipSpaces, ipSpace)
+		}
+	}
+	sort.Slice(ipSpaces, func(i, j int) bool { return ipSpaces[i].definition.Name < ipSpaces[j].definition.Name })
+	var values []interface{}
+	for _, ipSpace := range ipSpaces {
+		values = append(values, ipSpace.definition)
+	}
+	writePage(w, values)
+}
+
+// getIpSpaceOrgAssignments returns the org assignments matching the 'filter' query parameter, one for each
+// organization with allocations in an IP Space
+func (sim *Simulator) getIpSpaceOrgAssignments(w http.ResponseWriter, r *http.Request, _ []string) {
+	filter := parseFilter(r.URL.Query())
+	var ipSpaces []*ipSpaceEntry
+	for _, ipSpace := range sim.ipSpaces {
+		ipSpaces = append(ipSpaces, ipSpace)
+	}
+	sort.Slice(ipSpaces, func(i, j int) bool { return ipSpaces[i].definition.Name < ipSpaces[j].definition.Name })
+
+	var values []interface{}
+	for _, ipSpace := range ipSpaces {
+		assigned := make(map[*orgEntry]bool)
+		for _, entry := range ipSpace.sortedAllocations() {
+			if assigned[entry.org] {
+				continue
+			}
+			assigned[entry.org] = true
+			if matchesFilter(filter, map[string]string{"ipSpaceRef.id": ipSpace.urn(), "orgRef.id": entry.org.urn()}) {
+				values = append(values, sim.ipSpaceOrgAssignment(ipSpace, entry.org))
+			}
+		}
+	}
+	writePage(w, values)
+}
+
+// allocateIpSpaceIp allocates IP addresses or prefixes to the organization of the tenant context. As in VCD,
+// the allocations are returned as JSON in the result of the task
+func (sim *Simulator) allocateIpSpaceIp(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	org, ok := sim.tenantOrg(r)
+	if !ok {
+		sim.writeError(w, r, http.StatusBadRequest, "IP allocations require the tenant context of an organization")
+		return
+	}
+	var request types.IpSpaceIpAllocationRequest
+	if err := readJSON(r, &request); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	blocks, err := ipSpace.requestedBlocks(request)
+	if err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var results []types.IpSpaceIpAllocationRequestResult
+	owner := &types.Reference{ID: ipSpace.urn(), Name: ipSpace.definition.Name}
+	task := sim.runTask("ipSpaceIpAllocation", owner, func() {
+		if ipSpace.assignmentIds[org.id] == "" {
+			ipSpace.assignmentIds[org.id] = newId()
+		}
+		for _, block := range blocks {
+			entry := &ipAllocationEntry{
+				allocation: types.IpSpaceIpAllocation{
+					ID:             "urn:vcloud:ipSpaceIpAllocation:" + newId(),
+					OrgRef:         &types.OpenApiReference{ID: org.urn(), Name: org.name},
+					Type:           request.Type,
+					UsageState:     "UNUSED",
+					Value:          block.value,
+					AllocationDate: time.Now().UTC().Format(time.RFC3339Nano),
+				},
+				org:   org,
+				first: block.first,
+				last:  block.last,
+			}
+			ipSpace.allocations = append(ipSpace.allocations, entry)
+			results = append(results, types.IpSpaceIpAllocationRequestResult{ID: entry.allocation.ID, Value: block.value})
+		}
+	})
+	if results != nil {
+		text, _ := json.Marshal(results)
+		task.Result = &types.TaskResult{}
+		task.Result.ResultContent.Text = string(text)
+	}
+	w.Header().Set("Location", task.HREF)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// getIpSpaceAllocations returns the allocations matching the 'filter' query parameter. With a tenant
+// context, only the allocations of its organization are returned
+func (sim *Simulator) getIpSpaceAllocations(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	filter := parseFilter(r.URL.Query())
+	tenant, isTenant := sim.tenantOrg(r)
+	var values []interface{}
+	for _, entry := range ipSpace.sortedAllocations() {
+		if isTenant && entry.org != tenant {
+			continue
+		}
+		if matchesFilter(filter, map[string]string{
+			"type":       entry.allocation.Type,
+			"value":      entry.allocation.Value,
+			"usageState": entry.allocation.UsageState,
+			"orgRef.id":  entry.org.urn(),
+		}) {
+			values = append(values, entry.allocation)
+		}
+	}
+	writePage(w, values)
+}
+
+func (sim *Simulator) getIpSpaceAllocation(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	entry, _, ok := ipSpace.findAllocation(params[1])
+	if !ok {
+		sim.notFound(w, r, "IP allocation "+params[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, entry.allocation)
+}
+
+// updateIpSpaceAllocation changes the usage state and the description of an allocation. The other fields
+// can't be changed
+func (sim *Simulator) updateIpSpaceAllocation(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	entry, _, ok := ipSpace.findAllocation(params[1])
+	if !ok {
+		sim.notFound(w, r, "IP allocation "+params[1])
+		return
+	}
+	var update types.IpSpaceIpAllocation
+	if err := readJSON(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.Description != "" && update.UsageState != "USED_MANUAL" {
+		sim.writeError(w, r, http.StatusBadRequest, "a description can only be set with the usage state USED_MANUAL")
+		return
+	}
+	if update.UsageState != "" {
+		entry.allocation.UsageState = update.UsageState
+	}
+	entry.allocation.Description = update.Description
+	writeJSON(w, http.StatusOK, entry.allocation)
+}
+
+func (sim *Simulator) deleteIpSpaceAllocation(w http.ResponseWriter, r *http.Request, params []string) {
+	ipSpace, ok := sim.ipSpaces[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "IP Space "+params[0])
+		return
+	}
+	_, index, ok := ipSpace.findAllocation(params[1])
+	if !ok {
+		sim.notFound(w, r, "IP allocation "+params[1])
+		return
+	}
+	ipSpace.allocations = append(ipSpace.allocations[:index:index], ipSpace.allocations[index+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,303 @@
+package vcdsim
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// mediaEntry is the state of a simulated media item, and of the catalog item that holds it. The contents
+// are not kept: the upload only counts the bytes received
+type mediaEntry struct {
+	id            string
+	catalogItemId string
+	name          string
+	description   string
+	catalog       *catalogEntry
+	size          int64
+	received      int64
+	uploadTask    *types.Task
+	created       time.Time
+}
+
+func (sim *Simulator) registerMediaRoutes() {
+	sim.handle(http.MethodPost, `/api/catalog/([^/]+)/action/upload`, sim.createMedia)
+	sim.handle(http.MethodGet, `/api/catalogItem/([^/]+)`, sim.getCatalogItem)
+	sim.handle(http.MethodGet, `/api/media/([^/]+)`, sim.getMedia)
+	sim.handle(http.MethodDelete, `/api/media/([^/]+)`, sim.deleteMedia)
+	sim.handle(http.MethodPut, `/transfer/([^/]+)/file`, sim.uploadMediaFile)
+	sim.handle(http.MethodPost, `/api/vApp/vm-([^/]+)/media/action/(insertMedia|ejectMedia)`, sim.mediaAction)
+}
+
+// CatalogMedia returns the names of the media items of the catalog with the given ID, sorted by name
+func (sim *Simulator) CatalogMedia(catalogId string) []string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var names []string
+	for _, media := range sim.catalogMedia(sim.catalogs[uuidOf(catalogId)]) {
+		names = append(names, media.name)
+	}
+	return names
+}
+
+// InsertedMedia returns the name of the media item inserted into the VM with the given ID, or an empty
+// string if there is none
+func (sim *Simulator) InsertedMedia(vmId string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vm, ok := sim.vms[uuidOf(vmId)]
+	if !ok || vm.media == nil {
+		return ""
+	}
+	return vm.media.name
+}
+
+// catalogMedia returns the media items of a catalog, sorted by name
+func (sim *Simulator) catalogMedia(catalog *catalogEntry) []*mediaEntry {
+	var result []*mediaEntry
+	for _, media := range sim.media {
+		if media.catalog == catalog {
+			result = append(result, media)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// findMediaByCatalogItem returns the media item held by a catalog item
+func (sim *Simulator) findMediaByCatalogItem(catalogItemId string) (*mediaEntry, bool) {
+	for _, media := range sim.media {
+		if media.catalogItemId == catalogItemId {
+			return media, true
+		}
+	}
+	return nil, false
+}
+
+func (media *mediaEntry) urn() string {
+	return "urn:vcloud:media:" + media.id
+}
+
+// isUploaded reports whether all the bytes of the media item have been received
+func (media *mediaEntry) isUploaded() bool {
+	return media.received >= media.size
+}
+
+func (sim *Simulator) mediaReference(media *mediaEntry) *types.Reference {
+	return &types.Reference{HREF: sim.href("/api/media/%s", media.id), ID: media.urn(), Type: types.MimeMediaItem, Name: media.name}
+}
+
+func (sim *Simulator) catalogItemReference(media *mediaEntry) *types.Reference {
+	return &types.Reference{
+		HREF: sim.href("/api/catalogItem/%s", media.catalogItemId),
+		ID:   "urn:vcloud:catalogitem:" + media.catalogItemId,
+		Type: types.MimeCatalogItem,
+		Name: media.name,
+	}
+}
+
+func (sim *Simulator) catalogItemView(media *mediaEntry) types.CatalogItem {
+	reference := sim.catalogItemReference(media)
+	mediaReference := sim.mediaReference(media)
+	return types.CatalogItem{
+		HREF:        reference.HREF,
+		Type:        reference.Type,
+		ID:          reference.ID,
+		Name:        media.name,
+		Size:        media.size,
+		DateCreated: media.created.Format(time.RFC3339),
+		Description: media.description,
+		Entity:      &types.Entity{HREF: mediaReference.HREF, Type: mediaReference.Type, ID: mediaReference.ID, Name: media.name},
+		Link: types.LinkList{
+			{Rel: "up", Type: types.MimeCatalog, HREF: sim.href("/api/catalog/%s", media.catalog.id)},
+		},
+	}
+}
+
+// mediaView returns a media item. While it is being uploaded, it lists the file to upload and the
+// upload task
+func (sim *Simulator) mediaView(media *mediaEntry) types.Media {
+	reference := sim.mediaReference(media)
+	result := types.Media{
+		HREF:        reference.HREF,
+		Type:        reference.Type,
+		ID:          reference.ID,
+		Name:        media.name,
+		Status:      statusResolved,
+		ImageType:   "iso",
+		Size:        media.size,
+		Description: media.description,
+		Owner:       &types.Reference{Type: "application/vnd.vmware.admin.user+xml", Name: AdminUser},
+	}
+	if !media.isUploaded() {
+		result.Status = 0
+		result.Files = &types.FilesList{File: []*types.File{{
+			Name:             "file",
+			Size:             media.size,
+			BytesTransferred: media.received,
+			Link: types.LinkList{
+				{Rel: "upload:default", HREF: sim.href("/transfer/%s/file", media.id)},
+			},
+		}}}
+	}
+	if media.uploadTask != nil && media.uploadTask.Status == "running" {
+		result.Tasks = &types.TasksInProgress{Task: []*types.Task{media.uploadTask}}
+	}
+	return result
+}
+
+// createMedia creates a media item in a catalog, waiting for its contents to be uploaded. The import
+// task keeps running until the last byte is received
+func (sim *Simulator) createMedia(w http.ResponseWriter, r *http.Request, params []string) {
+	catalog, ok := sim.catalogs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "catalog "+params[0])
+		return
+	}
+	var create types.Media
+	if err := readXML(r, &create); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, media := range sim.catalogMedia(catalog) {
+		if media.name == create.Name {
+			sim.writeError(w, r, http.StatusBadRequest, "DUPLICATE_NAME: the catalog item name "+create.Name+" is already used")
+			return
+		}
+	}
+	if create.Size <= 0 {
+		sim.writeError(w, r, http.StatusBadRequest, "the size of media "+create.Name+" must be greater than zero")
+		return
+	}
+
+	media := &mediaEntry{
+		id:            newId(),
+		catalogItemId: newId(),
+		name:          create.Name,
+		description:   create.Description,
+		catalog:       catalog,
+		size:          create.Size,
+		created:       time.Now(),
+	}
+	media.uploadTask = sim.runTask("vdcUploadMedia", sim.mediaReference(media), nil)
+	if media.uploadTask.Status == "success" {
+		media.uploadTask.Status = "running"
+		media.uploadTask.EndTime = ""
+		media.uploadTask.Progress = 0
+	}
+	sim.media[media.id] = media
+	writeXML(w, http.StatusCreated, sim.catalogItemView(media))
+}
+
+func (sim *Simulator) getCatalogItem(w http.ResponseWriter, r *http.Request, params []string) {
+	media, ok := sim.findMediaByCatalogItem(params[0])
+	if !ok {
+		sim.notFound(w, r, "catalog item "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.catalogItemView(media))
+}
+
+func (sim *Simulator) getMedia(w http.ResponseWriter, r *http.Request, params []string) {
+	media, ok := sim.media[params[0]]
+	if !ok {
+		sim.notFound(w, r, "media "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.mediaView(media))
+}
+
+// uploadMediaFile receives a part of the contents of a media item, as described by the Content-Range header
+func (sim *Simulator) uploadMediaFile(w http.ResponseWriter, r *http.Request, params []string) {
+	media, ok := sim.media[params[0]]
+	if !ok || media.isUploaded() {
+		sim.notFound(w, r, "upload of media "+params[0])
+		return
+	}
+	var start, end, total int64
+	if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, "invalid Content-Range: "+err.Error())
+		return
+	}
+	if start != media.received || end < start || total != media.size {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("unexpected range %d-%d/%d, %d bytes of %d received",
+			start, end, total, media.received, media.size))
+		return
+	}
+	received, err := io.Copy(io.Discard, r.Body)
+	if err != nil || received != end-start+1 {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("expected %d bytes, received %d", end-start+1, received))
+		return
+	}
+
+	media.received += received
+	if media.isUploaded() && media.uploadTask.Status == "running" {
+		media.uploadTask.Status = "success"
+		media.uploadTask.EndTime = time.Now().Format(time.RFC3339)
+		media.uploadTask.Progress = 100
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteMedia removes a media item and its catalog item. As in VCD, a media item inserted into a VM can't
+// be removed
+func (sim *Simulator) deleteMedia(w http.ResponseWriter, r *http.Request, params []string) {
+	media, ok := sim.media[params[0]]
+	if !ok {
+		sim.notFound(w, r, "media "+params[0])
+		return
+	}
+	for _, vm := range sim.vms {
+		if vm.media == media {
+			sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("media %s is inserted into VM %s", media.name, vm.name))
+			return
+		}
+	}
+	sim.writeTask(w, "vdcDeleteMedia", sim.mediaReference(media), func() {
+		delete(sim.media, media.id)
+	})
+}
+
+// mediaAction inserts a media item into the CD-ROM of a VM, or ejects it. Ejecting a media item that is not
+// inserted succeeds, as in VCD
+func (sim *Simulator) mediaAction(w http.ResponseWriter, r *http.Request, params []string) {
+	vm, ok := sim.vms[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	var mediaParams types.MediaInsertOrEjectParams
+	if err := readXML(r, &mediaParams); err != nil || mediaParams.Media == nil {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid media parameters: %v", err))
+		return
+	}
+	media, ok := sim.media[uuidOf(mediaParams.Media.HREF)]
+	if !ok {
+		sim.notFound(w, r, "media "+mediaParams.Media.HREF)
+		return
+	}
+
+	if params[1] == "ejectMedia" {
+		sim.writeTask(w, "vappEjectCdFloppy", sim.vmReference(vm), func() {
+			if vm.media == media {
+				vm.media = nil
+			}
+		})
+		return
+	}
+	if !media.isUploaded() {
+		sim.writeError(w, r, http.StatusBadRequest, "media "+media.name+" is not ready")
+		return
+	}
+	if vm.media != nil && vm.media != media {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("the CD-ROM of VM %s already holds media %s", vm.name, vm.media.name))
+		return
+	}
+	sim.writeTask(w, "vappInsertCdFloppy", sim.vmReference(vm), func() {
+		vm.media = media
+	})
+}
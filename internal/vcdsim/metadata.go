@@ -0,0 +1,114 @@
+package vcdsim
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func (sim *Simulator) registerMetadataRoutes() {
+	sim.handle(http.MethodGet, `(/api/.+)/metadata/?`, sim.getMetadata)
+	sim.handle(http.MethodPost, `(/api/.+)/metadata/?`, sim.mergeMetadata)
+	sim.handle(http.MethodGet, `(/api/.+)/metadata/(?:GENERAL/|SYSTEM/)?([^/]+)`, sim.getMetadataValue)
+	sim.handle(http.MethodPut, `(/api/.+)/metadata/(?:GENERAL/|SYSTEM/)?([^/]+)`, sim.setMetadataValue)
+	sim.handle(http.MethodDelete, `(/api/.+)/metadata/(?:GENERAL/|SYSTEM/)?([^/]+)`, sim.deleteMetadataValue)
+}
+
+// metadataKey returns the key under which the metadata of the entity at the given path is stored. The
+// user and admin paths of an entity share the same metadata
+func metadataKey(entityPath string) string {
+	return strings.Replace(entityPath, "/api/admin/", "/api/", 1)
+}
+
+// deleteMetadataOf removes the metadata of a deleted entity
+func (sim *Simulator) deleteMetadataOf(entityPath string) {
+	delete(sim.metadata, metadataKey(entityPath))
+}
+
+func (sim *Simulator) getMetadata(w http.ResponseWriter, r *http.Request, params []string) {
+	result := types.Metadata{
+		Xmlns: types.XMLNamespaceVCloud,
+		Xsi:   types.XMLNamespaceXSI,
+		HREF:  sim.href("%s/metadata", params[0]),
+		Type:  types.MimeMetaData,
+	}
+	entries := sim.metadata[metadataKey(params[0])]
+	var keys []string
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result.MetadataEntry = append(result.MetadataEntry, entries[key])
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (sim *Simulator) mergeMetadata(w http.ResponseWriter, r *http.Request, params []string) {
+	var metadata types.Metadata
+	if err := readXML(r, &metadata); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	owner := &types.Reference{HREF: sim.href("%s", params[0])}
+	sim.writeTask(w, "metadataUpdate", owner, func() {
+		for _, entry := range metadata.MetadataEntry {
+			sim.storeMetadata(params[0], entry.Key, entry.Domain, entry.TypedValue)
+		}
+	})
+}
+
+func (sim *Simulator) getMetadataValue(w http.ResponseWriter, r *http.Request, params []string) {
+	entry, ok := sim.metadata[metadataKey(params[0])][params[1]]
+	if !ok {
+		sim.notFound(w, r, "metadata entry "+params[1])
+		return
+	}
+	writeXML(w, http.StatusOK, types.MetadataValue{
+		Xmlns:      types.XMLNamespaceVCloud,
+		Xsi:        types.XMLNamespaceXSI,
+		Domain:     entry.Domain,
+		TypedValue: entry.TypedValue,
+	})
+}
+
+func (sim *Simulator) setMetadataValue(w http.ResponseWriter, r *http.Request, params []string) {
+	var value types.MetadataValue
+	if err := readXML(r, &value); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	owner := &types.Reference{HREF: sim.href("%s", params[0])}
+	sim.writeTask(w, "metadataUpdate", owner, func() {
+		sim.storeMetadata(params[0], params[1], value.Domain, value.TypedValue)
+	})
+}
+
+func (sim *Simulator) deleteMetadataValue(w http.ResponseWriter, r *http.Request, params []string) {
+	if _, ok := sim.metadata[metadataKey(params[0])][params[1]]; !ok {
+		sim.notFound(w, r, "metadata entry "+params[1])
+		return
+	}
+	owner := &types.Reference{HREF: sim.href("%s", params[0])}
+	sim.writeTask(w, "metadataDelete", owner, func() {
+		delete(sim.metadata[metadataKey(params[0])], params[1])
+	})
+}
+
+func (sim *Simulator) storeMetadata(entityPath, key string, domain *types.MetadataDomainTag, value *types.MetadataTypedValue) {
+	entityKey := metadataKey(entityPath)
+	if sim.metadata[entityKey] == nil {
+		sim.metadata[entityKey] = make(map[string]*types.MetadataEntry)
+	}
+	sim.metadata[entityKey][key] = &types.MetadataEntry{
+		Xmlns:      types.XMLNamespaceVCloud,
+		Xsi:        types.XMLNamespaceXSI,
+		HREF:       sim.href("%s/metadata/%s", entityPath, key),
+		Type:       types.MimeMetaDataValue,
+		Domain:     domain,
+		Key:        key,
+		TypedValue: value,
+	}
+}
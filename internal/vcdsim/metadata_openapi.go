@@ -0,0 +1,113 @@
+package vcdsim
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// openApiMetadataEntry is an OpenAPI metadata entry of an entity, with the version used as its ETag. The entries
+// are stored by entity URN and entry ID
+type openApiMetadataEntry struct {
+	entry   types.OpenApiMetadataEntry
+	version int
+}
+
+func (sim *Simulator) registerOpenApiMetadataRoutes() {
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/entities/([^/]+)/metadata/?`, sim.getOpenApiMetadata)
+	sim.handle(http.MethodPost, `/cloudapi/1.0.0/entities/([^/]+)/metadata/?`, sim.addOpenApiMetadata)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/entities/([^/]+)/metadata/([^/]+)`, sim.getOpenApiMetadataEntry)
+	sim.handle(http.MethodPut, `/cloudapi/1.0.0/entities/([^/]+)/metadata/([^/]+)`, sim.updateOpenApiMetadataEntry)
+	sim.handle(http.MethodDelete, `/cloudapi/1.0.0/entities/([^/]+)/metadata/([^/]+)`, sim.deleteOpenApiMetadataEntry)
+}
+
+func (entry *openApiMetadataEntry) etag() string {
+	return fmt.Sprintf(`"%d"`, entry.version)
+}
+
+// getOpenApiMetadata returns the metadata entries of an entity, filtered by key with the 'filter' query parameter
+func (sim *Simulator) getOpenApiMetadata(w http.ResponseWriter, r *http.Request, params []string) {
+	filter := parseFilter(r.URL.Query())
+	entries := sim.openApiMetadata[params[0]]
+	var ids []string
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var values []interface{}
+	for _, id := range ids {
+		entry := entries[id].entry
+		if matchesFilter(filter, map[string]string{"keyValue.key": entry.KeyValue.Key}) {
+			values = append(values, entry)
+		}
+	}
+	writePage(w, values)
+}
+
+func (sim *Simulator) addOpenApiMetadata(w http.ResponseWriter, r *http.Request, params []string) {
+	var entry types.OpenApiMetadataEntry
+	if err := readJSON(r, &entry); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, existing := range sim.openApiMetadata[params[0]] {
+		if existing.entry.KeyValue.Domain == entry.KeyValue.Domain && existing.entry.KeyValue.Namespace == entry.KeyValue.Namespace &&
+			existing.entry.KeyValue.Key == entry.KeyValue.Key {
+			sim.writeError(w, r, http.StatusBadRequest, "duplicate metadata key "+entry.KeyValue.Key)
+			return
+		}
+	}
+	entry.ID = "urn:vcloud:metadata:" + newId()
+	if sim.openApiMetadata[params[0]] == nil {
+		sim.openApiMetadata[params[0]] = make(map[string]*openApiMetadataEntry)
+	}
+	stored := &openApiMetadataEntry{entry: entry, version: 1}
+	sim.openApiMetadata[params[0]][entry.ID] = stored
+	w.Header().Set("Etag", stored.etag())
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (sim *Simulator) getOpenApiMetadataEntry(w http.ResponseWriter, r *http.Request, params []string) {
+	stored, ok := sim.openApiMetadata[params[0]][params[1]]
+	if !ok {
+		sim.notFound(w, r, "metadata entry "+params[1])
+		return
+	}
+	w.Header().Set("Etag", stored.etag())
+	writeJSON(w, http.StatusOK, stored.entry)
+}
+
+// updateOpenApiMetadataEntry changes the value and persistence of an entry. As in VCD, a stale 'If-Match' header
+// is rejected
+func (sim *Simulator) updateOpenApiMetadataEntry(w http.ResponseWriter, r *http.Request, params []string) {
+	stored, ok := sim.openApiMetadata[params[0]][params[1]]
+	if !ok {
+		sim.notFound(w, r, "metadata entry "+params[1])
+		return
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != stored.etag() {
+		sim.writeError(w, r, http.StatusPreconditionFailed, "the metadata entry "+params[1]+" was modified")
+		return
+	}
+	var entry types.OpenApiMetadataEntry
+	if err := readJSON(r, &entry); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	stored.entry.IsPersistent = entry.IsPersistent
+	stored.entry.KeyValue.Value.Value = entry.KeyValue.Value.Value
+	stored.version++
+	w.Header().Set("Etag", stored.etag())
+	writeJSON(w, http.StatusOK, stored.entry)
+}
+
+func (sim *Simulator) deleteOpenApiMetadataEntry(w http.ResponseWriter, r *http.Request, params []string) {
+	if _, ok := sim.openApiMetadata[params[0]][params[1]]; !ok {
+		sim.notFound(w, r, "metadata entry "+params[1])
+		return
+	}
+	delete(sim.openApiMetadata[params[0]], params[1])
+	w.WriteHeader(http.StatusNoContent)
+}
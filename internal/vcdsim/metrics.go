@@ -0,0 +1,175 @@
+package vcdsim
+
+import (
+	"encoding/xml"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// metricSample is a value of a VM metric collected at a given time
+type metricSample struct {
+	name      string
+	unit      string
+	timestamp time.Time
+	value     float64
+}
+
+// metricsSpec is the body of the requests for current and historic metrics. Only absolute time periods
+// are supported
+type metricsSpec struct {
+	Start          string   `xml:"AbsoluteTimePeriod>Start"`
+	End            string   `xml:"AbsoluteTimePeriod>End"`
+	MetricPatterns []string `xml:"MetricPattern"`
+}
+
+type metricValue struct {
+	Name  string  `xml:"name,attr"`
+	Unit  string  `xml:"unit,attr"`
+	Value float64 `xml:"value,attr"`
+}
+
+type currentUsage struct {
+	XMLName xml.Name      `xml:"CurrentUsage"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Metrics []metricValue `xml:"Metric"`
+}
+
+type metricSeriesSample struct {
+	Timestamp string  `xml:"timestamp,attr"`
+	Value     float64 `xml:"value,attr"`
+}
+
+type metricSeries struct {
+	Name             string               `xml:"name,attr"`
+	Unit             string               `xml:"unit,attr"`
+	ExpectedInterval int                  `xml:"expectedInterval,attr"`
+	Samples          []metricSeriesSample `xml:"Sample"`
+}
+
+type historicUsage struct {
+	XMLName xml.Name       `xml:"HistoricUsage"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Series  []metricSeries `xml:"MetricSeries"`
+}
+
+// metricsInterval is the interval in seconds between historic samples reported by the simulator
+const metricsInterval = 300
+
+func (sim *Simulator) registerMetricsRoutes() {
+	const path = `/api/vApp/vm-([^/]+)/metrics/(current|historic)`
+	sim.handle(http.MethodGet, path, sim.getMetrics)
+	sim.handle(http.MethodPost, path, sim.getMetrics)
+}
+
+// AddVmMetricSample records a value of a metric of the VM with the given ID. The current value of a
+// metric is its most recent sample, which is only reported while the VM is powered on
+func (sim *Simulator) AddVmMetricSample(vmId, name, unit string, timestamp time.Time, value float64) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vm, ok := sim.vms[uuidOf(vmId)]
+	if !ok {
+		panic("vcdsim: VM " + vmId + " not found")
+	}
+	vm.metrics = append(vm.metrics, &metricSample{name: name, unit: unit, timestamp: timestamp, value: value})
+}
+
+// getMetrics returns the current or historic metrics of a VM matching the patterns of an optional spec.
+// Without a time period, historic metrics cover the last 24 hours
+func (sim *Simulator) getMetrics(w http.ResponseWriter, r *http.Request, params []string) {
+	vm, ok := sim.vms[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	spec := metricsSpec{}
+	if r.Method == http.MethodPost {
+		if err := readXML(r, &spec); err != nil {
+			sim.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var patterns []*regexp.Regexp
+	for _, pattern := range spec.MetricPatterns {
+		patterns = append(patterns, regexp.MustCompile("^"+strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")+"$"))
+	}
+	matches := func(name string) bool {
+		for _, pattern := range patterns {
+			if pattern.MatchString(name) {
+				return true
+			}
+		}
+		return len(patterns) == 0
+	}
+	samples := make([]*metricSample, 0, len(vm.metrics))
+	for _, sample := range vm.metrics {
+		if matches(sample.name) {
+			samples = append(samples, sample)
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].timestamp.Before(samples[j].timestamp)
+	})
+
+	if params[1] == "current" {
+		usage := currentUsage{Xmlns: types.XMLNamespaceVCloud, Metrics: []metricValue{}}
+		if vm.status == statusPoweredOn {
+			latest := make(map[string]*metricSample)
+			var names []string
+			for _, sample := range samples {
+				if latest[sample.name] == nil {
+					names = append(names, sample.name)
+				}
+				latest[sample.name] = sample
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				usage.Metrics = append(usage.Metrics, metricValue{Name: name, Unit: latest[name].unit, Value: latest[name].value})
+			}
+		}
+		writeXML(w, http.StatusOK, usage)
+		return
+	}
+
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	for _, bound := range []struct {
+		text  string
+		value *time.Time
+	}{{spec.Start, &start}, {spec.End, &end}} {
+		if bound.text == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, bound.text)
+		if err != nil {
+			sim.writeError(w, r, http.StatusBadRequest, "invalid time period: "+err.Error())
+			return
+		}
+		*bound.value = parsed
+	}
+	usage := historicUsage{Xmlns: types.XMLNamespaceVCloud}
+	series := make(map[string]*metricSeries)
+	var names []string
+	for _, sample := range samples {
+		if sample.timestamp.Before(start) || sample.timestamp.After(end) {
+			continue
+		}
+		if series[sample.name] == nil {
+			series[sample.name] = &metricSeries{Name: sample.name, Unit: sample.unit, ExpectedInterval: metricsInterval}
+			names = append(names, sample.name)
+		}
+		series[sample.name].Samples = append(series[sample.name].Samples, metricSeriesSample{
+			Timestamp: sample.timestamp.UTC().Format(time.RFC3339),
+			Value:     sample.value,
+		})
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		usage.Series = append(usage.Series, *series[name])
+	}
+	writeXML(w, http.StatusOK, usage)
+}
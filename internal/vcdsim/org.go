@@ -0,0 +1,182 @@
+package vcdsim
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// orgEntry is the state of a simulated organization
+type orgEntry struct {
+	id          string
+	name        string
+	fullName    string
+	description string
+	// emailSettings keeps the SMTP password, which is never returned
+	emailSettings  orgEmailSettings
+	passwordPolicy types.OrgPasswordPolicySettings
+}
+
+// Default lease settings of simulated organizations
+const (
+	defaultRuntimeLease = 7 * 24 * 3600
+	defaultStorageLease = 30 * 24 * 3600
+)
+
+func (sim *Simulator) registerOrgRoutes() {
+	sim.handle(http.MethodGet, `/api/org/?`, sim.getOrgList)
+	sim.handle(http.MethodGet, `/api/org/([^/]+)`, sim.getOrg)
+	sim.handle(http.MethodGet, `/api/admin/org/([^/]+)`, sim.getAdminOrg)
+	sim.handle(http.MethodGet, `/api/admin/org/([^/]+)/settings/email`, sim.getOrgEmailSettings)
+	sim.handle(http.MethodPut, `/api/admin/org/([^/]+)/settings/email`, sim.updateOrgEmailSettings)
+	sim.handle(http.MethodGet, `/api/admin/org/([^/]+)/settings/passwordPolicy`, sim.getOrgPasswordPolicy)
+	sim.handle(http.MethodPut, `/api/admin/org/([^/]+)/settings/passwordPolicy`, sim.updateOrgPasswordPolicy)
+}
+
+// AddOrg adds an organization and returns its ID
+func (sim *Simulator) AddOrg(name string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.addOrg(name).urn()
+}
+
+func (sim *Simulator) addOrg(name string) *orgEntry {
+	org := &orgEntry{
+		id:       newId(),
+		name:     name,
+		fullName: name,
+		emailSettings: orgEmailSettings{
+			IsDefaultSmtpServer:     true,
+			IsDefaultOrgEmail:       true,
+			IsAlertEmailToAllAdmins: true,
+			SmtpServerSettings:      &smtpServerSettings{Port: 25},
+		},
+		passwordPolicy: types.OrgPasswordPolicySettings{
+			InvalidLoginsBeforeLockout:    5,
+			AccountLockoutIntervalMinutes: 10,
+		},
+	}
+	sim.orgs[org.id] = org
+	return org
+}
+
+func (org *orgEntry) urn() string {
+	return "urn:vcloud:org:" + org.id
+}
+
+func (sim *Simulator) findOrgByName(name string) *orgEntry {
+	for _, org := range sim.orgs {
+		if strings.EqualFold(org.name, name) {
+			return org
+		}
+	}
+	return nil
+}
+
+// sortedOrgs returns the organizations sorted by name, to give a stable order to lists
+func (sim *Simulator) sortedOrgs() []*orgEntry {
+	var orgs []*orgEntry
+	for _, org := range sim.orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].name < orgs[j].name })
+	return orgs
+}
+
+func (sim *Simulator) orgReference(org *orgEntry) *types.Reference {
+	return &types.Reference{
+		HREF: sim.href("/api/org/%s", org.id),
+		ID:   org.urn(),
+		Type: types.MimeOrg,
+		Name: org.name,
+	}
+}
+
+func (sim *Simulator) getOrgList(w http.ResponseWriter, _ *http.Request, _ []string) {
+	orgList := types.OrgList{}
+	for _, org := range sim.sortedOrgs() {
+		orgList.Org = append(orgList.Org, &types.Org{
+			HREF: sim.href("/api/org/%s", org.id),
+			Type: types.MimeOrg,
+			Name: org.name,
+		})
+	}
+	writeXML(w, http.StatusOK, orgList)
+}
+
+func (sim *Simulator) getOrg(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	result := types.Org{
+		HREF:        sim.href("/api/org/%s", org.id),
+		Type:        types.MimeOrg,
+		ID:          org.urn(),
+		Name:        org.name,
+		FullName:    org.fullName,
+		Description: org.description,
+		IsEnabled:   true,
+	}
+	for _, vdc := range sim.orgVdcs(org) {
+		result.Link = append(result.Link, &types.Link{Rel: "down", Type: types.MimeVDC, Name: vdc.name, HREF: sim.href("/api/vdc/%s", vdc.id)})
+	}
+	for _, catalog := range sim.orgCatalogs(org) {
+		result.Link = append(result.Link, &types.Link{Rel: "down", Type: types.MimeCatalog, Name: catalog.name, HREF: sim.href("/api/catalog/%s", catalog.id)})
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (sim *Simulator) getAdminOrg(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	href := sim.href("/api/admin/org/%s", org.id)
+	result := types.AdminOrg{
+		Xmlns:       types.XMLNamespaceVCloud,
+		HREF:        href,
+		Type:        types.MimeAdminOrg,
+		ID:          org.urn(),
+		Name:        org.name,
+		FullName:    org.fullName,
+		Description: org.description,
+		IsEnabled:   true,
+		Link: types.LinkList{
+			{Rel: "add", Type: types.MimeAdminCatalog, HREF: href + "/catalogs"},
+		},
+		Catalogs: &types.CatalogsList{},
+		Vdcs:     &types.VDCList{},
+		OrgSettings: &types.OrgSettings{
+			OrgGeneralSettings: &types.OrgGeneralSettings{
+				CanPublishCatalogs: true,
+			},
+			OrgVAppLeaseSettings: &types.VAppLeaseSettings{
+				DeleteOnStorageLeaseExpiration:   addrOf(false),
+				DeploymentLeaseSeconds:           addrOf(defaultRuntimeLease),
+				StorageLeaseSeconds:              addrOf(defaultStorageLease),
+				PowerOffOnRuntimeLeaseExpiration: addrOf(false),
+			},
+			OrgVAppTemplateSettings: &types.VAppTemplateLeaseSettings{
+				DeleteOnStorageLeaseExpiration: addrOf(false),
+				StorageLeaseSeconds:            addrOf(defaultStorageLease),
+			},
+		},
+	}
+	for _, vdc := range sim.orgVdcs(org) {
+		result.Vdcs.Vdcs = append(result.Vdcs.Vdcs, sim.vdcReference(vdc, true))
+	}
+	for _, catalog := range sim.orgCatalogs(org) {
+		result.Catalogs.Catalog = append(result.Catalogs.Catalog, sim.catalogReference(catalog))
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+// addrOf returns the address of a value
+func addrOf[T any](value T) *T {
+	return &value
+}
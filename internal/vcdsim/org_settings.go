@@ -0,0 +1,139 @@
+package vcdsim
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// orgEmailSettings is the email configuration of an organization
+type orgEmailSettings struct {
+	XMLName                 xml.Name            `xml:"OrgEmailSettings"`
+	Xmlns                   string              `xml:"xmlns,attr"`
+	HREF                    string              `xml:"href,attr,omitempty"`
+	Type                    string              `xml:"type,attr,omitempty"`
+	IsDefaultSmtpServer     bool                `xml:"IsDefaultSmtpServer"`
+	IsDefaultOrgEmail       bool                `xml:"IsDefaultOrgEmail"`
+	FromEmailAddress        string              `xml:"FromEmailAddress"`
+	DefaultSubjectPrefix    string              `xml:"DefaultSubjectPrefix"`
+	IsAlertEmailToAllAdmins bool                `xml:"IsAlertEmailToAllAdmins"`
+	AlertEmailTo            string              `xml:"AlertEmailTo,omitempty"`
+	SmtpServerSettings      *smtpServerSettings `xml:"SmtpServerSettings,omitempty"`
+}
+
+type smtpServerSettings struct {
+	IsUseAuthentication bool   `xml:"IsUseAuthentication"`
+	Host                string `xml:"Host"`
+	Port                int    `xml:"Port"`
+	Username            string `xml:"Username,omitempty"`
+	Password            string `xml:"Password,omitempty"`
+	SmtpSecureMode      string `xml:"SmtpSecureMode,omitempty"`
+}
+
+const (
+	orgEmailSettingsMime  = "application/vnd.vmware.admin.organizationEmailSettings+xml"
+	orgPasswordPolicyMime = "application/vnd.vmware.admin.organizationPasswordPolicySettings+xml"
+)
+
+// OrgSmtpPassword returns the SMTP password of an organization, which the API never returns
+func (sim *Simulator) OrgSmtpPassword(orgName string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	org := sim.findOrgByName(orgName)
+	if org == nil || org.emailSettings.SmtpServerSettings == nil {
+		return ""
+	}
+	return org.emailSettings.SmtpServerSettings.Password
+}
+
+func (sim *Simulator) orgEmailSettingsView(org *orgEntry) orgEmailSettings {
+	result := org.emailSettings
+	result.Xmlns = types.XMLNamespaceVCloud
+	result.HREF = sim.href("/api/admin/org/%s/settings/email", org.id)
+	result.Type = orgEmailSettingsMime
+	if org.emailSettings.SmtpServerSettings != nil {
+		smtpServer := *org.emailSettings.SmtpServerSettings
+		smtpServer.Password = ""
+		result.SmtpServerSettings = &smtpServer
+	}
+	return result
+}
+
+func (sim *Simulator) getOrgEmailSettings(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.orgEmailSettingsView(org))
+}
+
+func (sim *Simulator) updateOrgEmailSettings(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	var update orgEmailSettings
+	if err := readXML(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !update.IsDefaultOrgEmail && update.FromEmailAddress == "" {
+		sim.writeError(w, r, http.StatusBadRequest, "a sender address is required when the default one is not used")
+		return
+	}
+	if !update.IsDefaultSmtpServer && (update.SmtpServerSettings == nil || update.SmtpServerSettings.Host == "") {
+		sim.writeError(w, r, http.StatusBadRequest, "an SMTP server is required when the default one is not used")
+		return
+	}
+	// A request without password keeps the one of the same user
+	if previous := org.emailSettings.SmtpServerSettings; update.SmtpServerSettings != nil && previous != nil &&
+		update.SmtpServerSettings.Password == "" && update.SmtpServerSettings.Username == previous.Username {
+		update.SmtpServerSettings.Password = previous.Password
+	}
+	update.HREF, update.Type, update.Xmlns = "", "", ""
+	org.emailSettings = update
+	writeXML(w, http.StatusOK, sim.orgEmailSettingsView(org))
+}
+
+func (sim *Simulator) orgPasswordPolicyView(org *orgEntry) types.OrgPasswordPolicySettings {
+	result := org.passwordPolicy
+	result.Xmlns = types.XMLNamespaceVCloud
+	result.HREF = sim.href("/api/admin/org/%s/settings/passwordPolicy", org.id)
+	result.Type = orgPasswordPolicyMime
+	return result
+}
+
+func (sim *Simulator) getOrgPasswordPolicy(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.orgPasswordPolicyView(org))
+}
+
+func (sim *Simulator) updateOrgPasswordPolicy(w http.ResponseWriter, r *http.Request, params []string) {
+	org, ok := sim.orgs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "org "+params[0])
+		return
+	}
+	var update types.OrgPasswordPolicySettings
+	if err := readXML(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.InvalidLoginsBeforeLockout < 1 || update.AccountLockoutIntervalMinutes < 1 {
+		sim.writeError(w, r, http.StatusBadRequest, "invalid logins and lockout interval must be positive")
+		return
+	}
+	org.passwordPolicy = types.OrgPasswordPolicySettings{
+		AccountLockoutEnabled:         update.AccountLockoutEnabled,
+		InvalidLoginsBeforeLockout:    update.InvalidLoginsBeforeLockout,
+		AccountLockoutIntervalMinutes: update.AccountLockoutIntervalMinutes,
+	}
+	writeXML(w, http.StatusOK, sim.orgPasswordPolicyView(org))
+}
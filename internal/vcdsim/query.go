@@ -0,0 +1,248 @@
+package vcdsim
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// queryResult is the root element of the query service results
+type queryResult struct {
+	XMLName xml.Name `xml:"QueryResultRecords"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	types.QueryResultRecordsType
+}
+
+func (sim *Simulator) registerQueryRoutes() {
+	sim.handle(http.MethodGet, `/api/query`, sim.query)
+	// The requests that govcd makes to keep the session alive during uploads have the parameters in
+	// the escaped path
+	sim.handle(http.MethodGet, `/api/query\?(.*)`, sim.query)
+}
+
+// parseRawQuery splits the query string of a request. The query service is often called with filters that
+// are not URL encoded, so url.ParseQuery can't be used
+func parseRawQuery(rawQuery string) map[string]string {
+	params := make(map[string]string)
+	for _, param := range strings.Split(rawQuery, "&") {
+		key, value, _ := strings.Cut(param, "=")
+		params[key] = value
+	}
+	return params
+}
+
+// parseQueryFilter returns the conditions of a query service filter. Only conjunctions of equality
+// conditions ("a==x;b==y") are supported
+func parseQueryFilter(filter string, encoded bool) map[string]string {
+	conditions := make(map[string]string)
+	for _, condition := range strings.Split(strings.Trim(filter, "()"), ";") {
+		key, value, found := strings.Cut(condition, "==")
+		if !found {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil && encoded {
+			value = unescaped
+		}
+		conditions[strings.Trim(key, "()")] = strings.Trim(value, "()")
+	}
+	return conditions
+}
+
+// query serves the typed queries for the simulated entities. Queries of other types return no records
+func (sim *Simulator) query(w http.ResponseWriter, r *http.Request, pathParams []string) {
+	rawQuery := r.URL.RawQuery
+	if len(pathParams) > 0 {
+		rawQuery = pathParams[0]
+	}
+	params := parseRawQuery(rawQuery)
+	filter := parseQueryFilter(params["filter"], params["filterEncoded"] == "true")
+	queryType := params["type"]
+
+	result := queryResult{Xmlns: types.XMLNamespaceVCloud}
+	result.Type = "application/vnd.vmware.vcloud.query.records+xml"
+	result.Name = queryType
+	count := 0
+
+	switch queryType {
+	case types.QtOrgVdc, types.QtAdminOrgVdc:
+		for _, org := range sim.sortedOrgs() {
+			for _, vdc := range sim.orgVdcs(org) {
+				if !matchesFilter(filter, map[string]string{"name": vdc.name, "id": vdc.urn(), "orgName": org.name, "org": org.urn()}) ||
+					!sim.matchesMetadataFilter(filter, fmt.Sprintf("/api/vdc/%s", vdc.id)) {
+					continue
+				}
+				record := &types.QueryResultOrgVdcRecordType{
+					HREF:      sim.vdcReference(vdc, queryType == types.QtAdminOrgVdc).HREF,
+					Name:      vdc.name,
+					IsEnabled: "true",
+					OrgName:   org.name,
+					Org:       sim.href("/api/org/%s", org.id),
+					Status:    "READY",
+				}
+				if queryType == types.QtAdminOrgVdc {
+					result.OrgVdcAdminRecord = append(result.OrgVdcAdminRecord, record)
+				} else {
+					result.OrgVdcRecord = append(result.OrgVdcRecord, record)
+				}
+				count++
+			}
+		}
+	case types.QtCatalog, types.QtAdminCatalog:
+		for _, org := range sim.sortedOrgs() {
+			for _, catalog := range sim.orgCatalogs(org) {
+				if !matchesFilter(filter, map[string]string{"name": catalog.name, "id": catalog.urn(), "orgName": org.name, "org": org.urn()}) ||
+					!sim.matchesMetadataFilter(filter, fmt.Sprintf("/api/catalog/%s", catalog.id)) {
+					continue
+				}
+				record := &types.CatalogRecord{
+					HREF:         sim.href("/api/catalog/%s", catalog.id),
+					ID:           catalog.urn(),
+					Name:         catalog.name,
+					Description:  catalog.description,
+					IsLocal:      true,
+					CreationDate: catalog.created.Format(time.RFC3339),
+					OrgName:      org.name,
+					OwnerName:    AdminUser,
+					Version:      1,
+					Status:       "RESOLVED",
+				}
+				if queryType == types.QtAdminCatalog {
+					result.AdminCatalogRecord = append(result.AdminCatalogRecord, record)
+				} else {
+					result.CatalogRecord = append(result.CatalogRecord, record)
+				}
+				count++
+			}
+		}
+	case types.QtVapp, types.QtAdminVapp:
+		for _, vapp := range sim.sortedVapps() {
+			if !matchesFilter(filter, map[string]string{"name": vapp.name, "id": vapp.urn(), "vdc": vapp.vdc.urn(), "vdcName": vapp.vdc.name,
+				"org": vapp.vdc.org.urn()}) || !sim.matchesMetadataFilter(filter, fmt.Sprintf("/api/vApp/vapp-%s", vapp.id)) {
+				continue
+			}
+			record := &types.QueryResultVAppRecordType{
+				HREF:         sim.href("/api/vApp/vapp-%s", vapp.id),
+				Name:         vapp.name,
+				CreationDate: vapp.created.Format(time.RFC3339),
+				Deployed:     vapp.deployed,
+				OwnerName:    AdminUser,
+				Status:       types.VAppStatuses[vapp.status],
+				VdcHREF:      sim.href("/api/vdc/%s", vapp.vdc.id),
+				VdcName:      vapp.vdc.name,
+				MetaData:     sim.recordMetadata(fmt.Sprintf("/api/vApp/vapp-%s", vapp.id), params["fields"]),
+			}
+			if queryType == types.QtAdminVapp {
+				result.AdminVAppRecord = append(result.AdminVAppRecord, record)
+			} else {
+				result.VAppRecord = append(result.VAppRecord, record)
+			}
+			count++
+		}
+	case types.QtVm, types.QtAdminVm:
+		for _, vapp := range sim.sortedVapps() {
+			for _, vm := range sim.vappVms(vapp) {
+				if !matchesFilter(filter, map[string]string{"name": vm.name, "id": vm.urn(), "containerName": vapp.name,
+					"container": vapp.urn(), "vdc": vapp.vdc.urn(), "org": vapp.vdc.org.urn(), "isVAppTemplate": "false"}) ||
+					!sim.matchesMetadataFilter(filter, fmt.Sprintf("/api/vApp/vm-%s", vm.id)) {
+					continue
+				}
+				record := &types.QueryResultVMRecordType{
+					HREF:          sim.href("/api/vApp/vm-%s", vm.id),
+					ID:            vm.urn(),
+					Name:          vm.name,
+					Type:          types.MimeVM,
+					ContainerName: vapp.name,
+					ContainerID:   sim.href("/api/vApp/vapp-%s", vapp.id),
+					OwnerName:     AdminUser,
+					VdcHREF:       sim.href("/api/vdc/%s", vapp.vdc.id),
+					VdcName:       vapp.vdc.name,
+					Status:        types.VAppStatuses[vm.status],
+					Deployed:      vm.deployed,
+					MetaData:      sim.recordMetadata(fmt.Sprintf("/api/vApp/vm-%s", vm.id), params["fields"]),
+				}
+				if queryType == types.QtAdminVm {
+					result.AdminVMRecord = append(result.AdminVMRecord, record)
+				} else {
+					result.VMRecord = append(result.VMRecord, record)
+				}
+				count++
+			}
+		}
+	}
+
+	result.Page = 1
+	result.PageSize = 128
+	if pageSize, err := strconv.Atoi(params["pageSize"]); err == nil && pageSize > count {
+		result.PageSize = pageSize
+	}
+	result.Total = float64(count)
+	writeXML(w, http.StatusOK, result)
+}
+
+// matchesMetadataFilter checks the 'metadata:key==TYPE:value' and 'metadata@SYSTEM:key==TYPE:value' conditions
+// of a query filter against the metadata of the entity at the given path
+func (sim *Simulator) matchesMetadataFilter(filter map[string]string, entityPath string) bool {
+	entries := sim.metadata[metadataKey(entityPath)]
+	for condition, wanted := range filter {
+		prefix, key, found := strings.Cut(condition, ":")
+		if !found || (prefix != "metadata" && prefix != "metadata@SYSTEM") {
+			continue
+		}
+		entry, ok := entries[key]
+		if !ok || entry.TypedValue == nil {
+			return false
+		}
+		isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+		if isSystem != (prefix == "metadata@SYSTEM") {
+			return false
+		}
+		valueType, value, _ := strings.Cut(wanted, ":")
+		if !strings.EqualFold("Metadata"+valueType+"Value", entry.TypedValue.XsiType) || entry.TypedValue.Value != value {
+			return false
+		}
+	}
+	return true
+}
+
+// recordMetadata returns the metadata entries of the entity at the given path that are requested by
+// the 'fields' parameter of a query, as 'metadata:key' or 'metadata@SYSTEM:key'
+func (sim *Simulator) recordMetadata(entityPath, fields string) *types.Metadata {
+	entries := sim.metadata[metadataKey(entityPath)]
+	var result *types.Metadata
+	for _, field := range strings.Split(fields, ",") {
+		prefix, key, found := strings.Cut(field, ":")
+		if !found || (prefix != "metadata" && prefix != "metadata@SYSTEM") {
+			continue
+		}
+		entry, ok := entries[key]
+		if !ok {
+			continue
+		}
+		isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+		if isSystem != (prefix == "metadata@SYSTEM") {
+			continue
+		}
+		if result == nil {
+			result = &types.Metadata{}
+		}
+		result.MetadataEntry = append(result.MetadataEntry, entry)
+	}
+	return result
+}
+
+// sortedVapps returns all vApps, sorted by name
+func (sim *Simulator) sortedVapps() []*vappEntry {
+	var vapps []*vappEntry
+	for _, org := range sim.sortedOrgs() {
+		for _, vdc := range sim.orgVdcs(org) {
+			vapps = append(vapps, sim.vdcVapps(vdc)...)
+		}
+	}
+	return vapps
+}
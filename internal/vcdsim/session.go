@@ -0,0 +1,74 @@
+package vcdsim
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func (sim *Simulator) registerSessionRoutes() {
+	sim.handle(http.MethodGet, `/api/versions`, sim.getVersions)
+	sim.handle(http.MethodGet, `/api/admin/?`, sim.getAdminInfo)
+	sim.handle(http.MethodPost, `/cloudapi/1.0.0/sessions(/provider)?`, sim.createSession)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/sessions/current`, sim.getCurrentSession)
+	sim.handle(http.MethodDelete, `/cloudapi/1.0.0/sessions/current`, sim.deleteCurrentSession)
+}
+
+// getVersions returns the supported API versions, with the login URL that govcd uses for authentication
+func (sim *Simulator) getVersions(w http.ResponseWriter, _ *http.Request, _ []string) {
+	var versions govcd.SupportedVersions
+	for _, version := range Versions {
+		versions.VersionInfos = append(versions.VersionInfos, govcd.VersionInfo{
+			Version:  version,
+			LoginUrl: sim.href("/cloudapi/1.0.0/sessions"),
+		})
+	}
+	writeXML(w, http.StatusOK, versions)
+}
+
+// getAdminInfo returns the description from which govcd reads the VCD version and build date
+func (sim *Simulator) getAdminInfo(w http.ResponseWriter, _ *http.Request, _ []string) {
+	writeXML(w, http.StatusOK, types.VCloud{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        "VMware Cloud Director",
+		HREF:        sim.href("/api/admin"),
+		Type:        "application/vnd.vmware.admin.vcloud+xml",
+		Description: Build,
+	})
+}
+
+// createSession accepts the basic authentication of the administrator in the System organization, or of
+// the same user in any tenant organization, and returns the access token in the response header
+func (sim *Simulator) createSession(w http.ResponseWriter, r *http.Request, params []string) {
+	user, password, ok := r.BasicAuth()
+	if !ok || password != AdminPassword {
+		sim.writeError(w, r, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	userName, orgName, _ := strings.Cut(user, "@")
+	isProvider := params[0] != ""
+	if userName != AdminUser || isProvider != strings.EqualFold(orgName, SystemOrg) || sim.findOrgByName(orgName) == nil {
+		sim.writeError(w, r, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	w.Header().Set(govcd.BearerTokenHeader, sim.token)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   "urn:vcloud:session:" + newId(),
+		"user": map[string]string{"name": userName},
+		"org":  map[string]string{"name": orgName},
+	})
+}
+
+func (sim *Simulator) getCurrentSession(w http.ResponseWriter, _ *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   "urn:vcloud:session:" + newId(),
+		"user": map[string]string{"name": AdminUser},
+		"org":  map[string]string{"name": SystemOrg},
+	})
+}
+
+func (sim *Simulator) deleteCurrentSession(w http.ResponseWriter, _ *http.Request, _ []string) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,338 @@
+// Package vcdsim provides an in-memory stand-in for the VMware Cloud Director XML and OpenAPI endpoints
+// used by the provider. It keeps stateful fakes of organizations, VDCs, vApps, VMs, catalogs, media, NSX-T
+// edge gateways, IP Spaces and tasks, so that resource lifecycles can be tested without a live VCD.
+//
+// The simulator only implements the subset of the API that the provider needs for those entities.
+// Requests to endpoints that are not implemented get a 501 response, and are recorded, so that a test
+// can report them with Unhandled().
+//
+// The server uses TLS with a self-signed certificate, because govcd only extracts IDs from https HREFs.
+// Clients must therefore allow insecure connections.
+package vcdsim
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Credentials accepted by the simulator
+const (
+	SystemOrg     = "System"
+	AdminUser     = "administrator"
+	AdminPassword = "simulated-password" // #nosec G101 -- This is a fake credential for the simulator
+)
+
+// Versions are the API versions advertised by the simulator
+var Versions = []string{"37.0", "37.1", "37.2", "37.3", "38.0", "38.1"}
+
+// Build is the VCD version and build date reported by the simulator
+const Build = "10.5.1.23400185 Wed Jan 24 2024 04:46:42 GMT"
+
+// handlerFunc serves a request. params holds the sub-matches of the route pattern
+type handlerFunc func(w http.ResponseWriter, r *http.Request, params []string)
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handler handlerFunc
+}
+
+// injectedError is an error returned to the next request matching method and path
+type injectedError struct {
+	method  string
+	path    *regexp.Regexp
+	status  int
+	message string
+}
+
+// Simulator is an HTTP server that simulates a VCD
+type Simulator struct {
+	server *httptest.Server
+	routes []route
+	token  string
+
+	mu              sync.Mutex
+	orgs            map[string]*orgEntry
+	vdcs            map[string]*vdcEntry
+	vapps           map[string]*vappEntry
+	vms             map[string]*vmEntry
+	catalogs        map[string]*catalogEntry
+	media           map[string]*mediaEntry
+	edgeGateways    map[string]*edgeGatewayEntry
+	ipSpaces        map[string]*ipSpaceEntry
+	tasks           map[string]*types.Task
+	metadata        map[string]map[string]*types.MetadataEntry
+	openApiMetadata map[string]map[string]*openApiMetadataEntry
+	auditEvents     []*auditEventEntry
+	errors          []injectedError
+	failingTasks    []string
+	stalledTasks    []string
+	unhandled       []string
+	requestCount    int
+}
+
+// New starts a simulator with the System organization only
+func New() *Simulator {
+	sim := &Simulator{
+		token:           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		orgs:            make(map[string]*orgEntry),
+		vdcs:            make(map[string]*vdcEntry),
+		vapps:           make(map[string]*vappEntry),
+		vms:             make(map[string]*vmEntry),
+		catalogs:        make(map[string]*catalogEntry),
+		media:           make(map[string]*mediaEntry),
+		edgeGateways:    make(map[string]*edgeGatewayEntry),
+		ipSpaces:        make(map[string]*ipSpaceEntry),
+		tasks:           make(map[string]*types.Task),
+		metadata:        make(map[string]map[string]*types.MetadataEntry),
+		openApiMetadata: make(map[string]map[string]*openApiMetadataEntry),
+	}
+	sim.server = httptest.NewTLSServer(http.HandlerFunc(sim.serveHTTP))
+	sim.registerRoutes()
+	sim.addOrg(SystemOrg)
+	return sim
+}
+
+// URL returns the API endpoint of the simulator, to be used as the provider URL
+func (sim *Simulator) URL() string {
+	return sim.server.URL + "/api"
+}
+
+// Close shuts down the simulator
+func (sim *Simulator) Close() {
+	sim.server.Close()
+}
+
+// Unhandled returns the requests that the simulator could not serve, as "METHOD path"
+func (sim *Simulator) Unhandled() []string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return append([]string{}, sim.unhandled...)
+}
+
+// RequestCount returns the number of API requests received since the simulator started
+func (sim *Simulator) RequestCount() int {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.requestCount
+}
+
+// InjectError makes the next request matching the method and the path regular expression fail with the
+// given HTTP status and message
+func (sim *Simulator) InjectError(method, pathRegex string, status int, message string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.errors = append(sim.errors, injectedError{
+		method:  method,
+		path:    regexp.MustCompile(pathRegex),
+		status:  status,
+		message: message,
+	})
+}
+
+// FailNextTask makes the next task with an operation name containing operationName end with an error,
+// leaving the entities unchanged
+func (sim *Simulator) FailNextTask(operationName string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.failingTasks = append(sim.failingTasks, operationName)
+}
+
+// StallNextTask makes the next task with an operation name containing operationName stay running forever.
+// The entities are changed as if the task had started its work.
+func (sim *Simulator) StallNextTask(operationName string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.stalledTasks = append(sim.stalledTasks, operationName)
+}
+
+// handle registers a handler for a method and a path pattern. The pattern must match the whole path.
+func (sim *Simulator) handle(method, pattern string, handler handlerFunc) {
+	sim.routes = append(sim.routes, route{
+		method:  method,
+		pattern: regexp.MustCompile("^" + pattern + "$"),
+		handler: handler,
+	})
+}
+
+func (sim *Simulator) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	sim.mu.Lock()
+	sim.requestCount++
+	sim.mu.Unlock()
+	if !sim.isPublic(r) && !sim.isAuthorized(r) {
+		sim.writeError(w, r, http.StatusUnauthorized, "the request is not authenticated")
+		return
+	}
+	if sim.popInjectedError(w, r) {
+		return
+	}
+
+	for _, rt := range sim.routes {
+		if rt.method != r.Method {
+			continue
+		}
+		matches := rt.pattern.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		sim.mu.Lock()
+		rt.handler(w, r, matches[1:])
+		sim.mu.Unlock()
+		return
+	}
+
+	sim.mu.Lock()
+	sim.unhandled = append(sim.unhandled, r.Method+" "+path)
+	sim.mu.Unlock()
+	sim.writeError(w, r, http.StatusNotImplemented, fmt.Sprintf("%s %s is not implemented by the simulator", r.Method, path))
+}
+
+// isPublic returns true for the requests that don't need authentication
+func (sim *Simulator) isPublic(r *http.Request) bool {
+	return r.URL.Path == "/api/versions" ||
+		(r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/cloudapi/1.0.0/sessions"))
+}
+
+func (sim *Simulator) isAuthorized(r *http.Request) bool {
+	if r.Header.Get("X-Vmware-Vcloud-Access-Token") == sim.token {
+		return true
+	}
+	return r.Header.Get("Authorization") == "Bearer "+sim.token
+}
+
+func (sim *Simulator) popInjectedError(w http.ResponseWriter, r *http.Request) bool {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	for i, injected := range sim.errors {
+		if injected.method == r.Method && injected.path.MatchString(r.URL.Path) {
+			sim.errors = append(sim.errors[:i], sim.errors[i+1:]...)
+			sim.writeError(w, r, injected.status, injected.message)
+			return true
+		}
+	}
+	return false
+}
+
+// isOpenApi returns true for the requests using the OpenAPI (JSON) endpoints
+func isOpenApi(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/cloudapi/")
+}
+
+// writeXML writes an XML document with the given status
+func writeXML(w http.ResponseWriter, status int, body interface{}) {
+	text, err := xml.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/*+xml;version="+Versions[len(Versions)-1])
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(text)
+}
+
+// writeJSON writes a JSON document with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	text, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;version="+Versions[len(Versions)-1])
+	w.WriteHeader(status)
+	_, _ = w.Write(text)
+}
+
+// writeError writes an error in the format of the API being called
+func (sim *Simulator) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	minorCode := "BAD_REQUEST"
+	switch status {
+	case http.StatusNotFound, http.StatusForbidden:
+		minorCode = "RESOURCE_NOT_FOUND"
+		message = "[ ENF ] " + message
+	case http.StatusUnauthorized:
+		minorCode = "UNAUTHORIZED"
+	case http.StatusNotImplemented:
+		minorCode = "NOT_IMPLEMENTED"
+	}
+	if isOpenApi(r) {
+		writeJSON(w, status, types.OpenApiError{
+			MinorErrorCode: minorCode,
+			Message:        message,
+		})
+		return
+	}
+	writeXML(w, status, types.Error{
+		Message:        message,
+		MajorErrorCode: status,
+		MinorErrorCode: minorCode,
+	})
+}
+
+// notFound writes a "not found" error for the given entity
+func (sim *Simulator) notFound(w http.ResponseWriter, r *http.Request, entity string) {
+	sim.writeError(w, r, http.StatusForbidden, fmt.Sprintf("%s not found", entity))
+}
+
+// readXML decodes an XML request body
+func readXML(r *http.Request, body interface{}) error {
+	text, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(text, body)
+}
+
+// readJSON decodes a JSON request body
+func readJSON(r *http.Request, body interface{}) error {
+	text, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(text, body)
+}
+
+// href returns the full URL of a path of the simulator
+func (sim *Simulator) href(format string, args ...interface{}) string {
+	return sim.server.URL + fmt.Sprintf(format, args...)
+}
+
+// newId returns a new UUID
+func newId() string {
+	return uuid.NewString()
+}
+
+// uuidOf returns the UUID at the end of an ID or HREF
+func uuidOf(identifier string) string {
+	reUuid := regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	return reUuid.FindString(identifier)
+}
+
+// registerRoutes registers the handlers of all simulated endpoints
+func (sim *Simulator) registerRoutes() {
+	sim.registerSessionRoutes()
+	sim.registerTaskRoutes()
+	sim.registerOrgRoutes()
+	sim.registerVdcRoutes()
+	sim.registerVappRoutes()
+	sim.registerMetricsRoutes()
+	sim.registerCatalogRoutes()
+	sim.registerMediaRoutes()
+	sim.registerEdgeGatewayRoutes()
+	sim.registerIpSpaceRoutes()
+	sim.registerQueryRoutes()
+	sim.registerMetadataRoutes()
+	sim.registerOpenApiMetadataRoutes()
+	sim.registerAuditTrailRoutes()
+}
@@ -0,0 +1,350 @@
+//go:build unit || ALL
+
+package vcdsim
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// newTestClient starts a simulator with one org and one NSX-T VDC, and returns a client authenticated as
+// system administrator
+func newTestClient(t *testing.T) (*Simulator, *govcd.VCDClient) {
+	sim := New()
+	t.Cleanup(func() {
+		sim.Close()
+		if unhandled := sim.Unhandled(); len(unhandled) > 0 {
+			t.Errorf("requests not handled by the simulator:\n%s", strings.Join(unhandled, "\n"))
+		}
+	})
+	sim.AddOrg("test-org")
+	sim.AddVdc("test-org", "test-vdc", true)
+
+	apiUrl, err := url.Parse(sim.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := govcd.NewVCDClient(*apiUrl, true)
+	if err := client.Authenticate(AdminUser, AdminPassword, SystemOrg); err != nil {
+		t.Fatalf("error authenticating: %s", err)
+	}
+	return sim, client
+}
+
+func TestSimulatorAuthentication(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	apiUrl, err := url.Parse(sim.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := govcd.NewVCDClient(*apiUrl, true)
+	if err := client.Authenticate(AdminUser, "wrong-password", SystemOrg); err == nil {
+		t.Fatal("expected an error with a wrong password")
+	}
+	if err := client.Authenticate(AdminUser, AdminPassword, SystemOrg); err != nil {
+		t.Fatalf("error authenticating: %s", err)
+	}
+}
+
+func TestSimulatorVappLifecycle(t *testing.T) {
+	sim, client := newTestClient(t)
+
+	org, err := client.GetOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("error retrieving org: %s", err)
+	}
+	vdc, err := org.GetVDCByName("test-vdc", false)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+
+	vapp, err := vdc.CreateRawVApp("test-vapp", "description")
+	if err != nil {
+		t.Fatalf("error creating vApp: %s", err)
+	}
+	if _, err := vdc.CreateRawVApp("test-vapp", ""); err == nil {
+		t.Fatal("expected an error when creating a vApp with a duplicate name")
+	}
+	sim.AddVm("test-org", "test-vdc", "test-vapp", "test-vm")
+
+	task, err := vapp.PowerOn()
+	if err != nil {
+		t.Fatalf("error powering on vApp: %s", err)
+	}
+	if err := task.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for power on: %s", err)
+	}
+	if status, _ := sim.Status(vapp.VApp.ID); status != statusPoweredOn {
+		t.Fatalf("expected vApp status %d, got %d", statusPoweredOn, status)
+	}
+	vm, err := vapp.GetVMByName("test-vm", true)
+	if err != nil {
+		t.Fatalf("error retrieving VM: %s", err)
+	}
+	if vm.VM.Status != statusPoweredOn {
+		t.Fatalf("expected the VM to be powered on with its vApp, got status %d", vm.VM.Status)
+	}
+
+	if _, err := vapp.Delete(); err == nil {
+		t.Fatal("expected an error when deleting a deployed vApp")
+	}
+	task, err = vapp.Undeploy()
+	if err != nil {
+		t.Fatalf("error undeploying vApp: %s", err)
+	}
+	if err := task.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for undeploy: %s", err)
+	}
+	if err := vapp.Refresh(); err != nil {
+		t.Fatalf("error refreshing vApp: %s", err)
+	}
+	if vapp.VApp.Deployed {
+		t.Fatal("expected the vApp to be undeployed")
+	}
+
+	task, err = vapp.Delete()
+	if err != nil {
+		t.Fatalf("error deleting vApp: %s", err)
+	}
+	if err := task.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for delete: %s", err)
+	}
+	if _, found := sim.Status(vapp.VApp.ID); found {
+		t.Fatal("expected the vApp to be deleted")
+	}
+	if _, err := vdc.GetVAppByName("test-vapp", true); !govcd.ContainsNotFound(err) {
+		t.Fatalf("expected a 'not found' error, got %v", err)
+	}
+}
+
+func TestSimulatorCatalogAndMetadata(t *testing.T) {
+	_, client := newTestClient(t)
+
+	adminOrg, err := client.GetAdminOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("error retrieving admin org: %s", err)
+	}
+	catalog, err := adminOrg.CreateCatalog("test-catalog", "description")
+	if err != nil {
+		t.Fatalf("error creating catalog: %s", err)
+	}
+	if err := catalog.AddMetadataEntryWithVisibility("key", "value", types.MetadataStringValue, types.MetadataReadWriteVisibility, false); err != nil {
+		t.Fatalf("error adding metadata: %s", err)
+	}
+	metadata, err := catalog.GetMetadata()
+	if err != nil {
+		t.Fatalf("error retrieving metadata: %s", err)
+	}
+	if len(metadata.MetadataEntry) != 1 || metadata.MetadataEntry[0].TypedValue.Value != "value" {
+		t.Fatalf("unexpected metadata: %#v", metadata.MetadataEntry)
+	}
+
+	records, err := client.Client.QueryCatalogRecords("test-catalog", govcd.TenantContext{OrgName: "test-org"})
+	if err != nil {
+		t.Fatalf("error querying catalogs: %s", err)
+	}
+	if len(records) != 1 || records[0].OrgName != "test-org" {
+		t.Fatalf("unexpected catalog records: %#v", records)
+	}
+
+	if err := catalog.Delete(false, false); err != nil {
+		t.Fatalf("error deleting catalog: %s", err)
+	}
+	if _, err := adminOrg.GetAdminCatalogByName("test-catalog", true); !govcd.ContainsNotFound(err) {
+		t.Fatalf("expected a 'not found' error, got %v", err)
+	}
+}
+
+func TestSimulatorMediaUploadAndInsert(t *testing.T) {
+	sim, client := newTestClient(t)
+	catalogId := sim.AddCatalog("test-org", "test-catalog")
+	sim.AddVapp("test-org", "test-vdc", "test-vapp")
+	vmId := sim.AddVm("test-org", "test-vdc", "test-vapp", "test-vm")
+
+	// govcd only uploads files with the signature of an ISO image
+	image := make([]byte, 40000)
+	copy(image[32769:], "CD001")
+	fileName := filepath.Join(t.TempDir(), "test.iso")
+	if err := os.WriteFile(fileName, image, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	org, err := client.GetOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("error retrieving org: %s", err)
+	}
+	catalog, err := org.GetCatalogByName("test-catalog", false)
+	if err != nil {
+		t.Fatalf("error retrieving catalog: %s", err)
+	}
+	uploadTask, err := catalog.UploadMediaImage("test.iso", "description", fileName, 16*1024)
+	if err != nil {
+		t.Fatalf("error uploading media: %s", err)
+	}
+	if err := uploadTask.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for the upload: %s", err)
+	}
+	if media := sim.CatalogMedia(catalogId); len(media) != 1 || media[0] != "test.iso" {
+		t.Fatalf("expected the uploaded media in the catalog, got %v", media)
+	}
+
+	vdc, err := org.GetVDCByName("test-vdc", false)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	vmRecord, err := vdc.QueryVM("test-vapp", "test-vm")
+	if err != nil {
+		t.Fatalf("error retrieving VM: %s", err)
+	}
+	vm, err := client.Client.GetVMByHref(vmRecord.VM.HREF)
+	if err != nil {
+		t.Fatalf("error retrieving VM: %s", err)
+	}
+	task, err := vm.HandleInsertMedia(org, "test-catalog", "test.iso")
+	if err != nil {
+		t.Fatalf("error inserting media: %s", err)
+	}
+	if err := task.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for the insertion: %s", err)
+	}
+	if inserted := sim.InsertedMedia(vmId); inserted != "test.iso" {
+		t.Fatalf("expected the media to be inserted, got %q", inserted)
+	}
+
+	media, err := catalog.GetMediaByName("test.iso", true)
+	if err != nil {
+		t.Fatalf("error retrieving media: %s", err)
+	}
+	if _, err := media.Delete(); err == nil {
+		t.Fatal("expected an error when deleting an inserted media")
+	}
+	vm, err = vm.HandleEjectMediaAndAnswer(org, "test-catalog", "test.iso", true)
+	if err != nil {
+		t.Fatalf("error ejecting media: %s", err)
+	}
+	if inserted := sim.InsertedMedia(vmId); inserted != "" {
+		t.Fatalf("expected the media to be ejected, got %q", inserted)
+	}
+	task, err = media.Delete()
+	if err != nil {
+		t.Fatalf("error deleting media: %s", err)
+	}
+	if err := task.WaitTaskCompletion(); err != nil {
+		t.Fatalf("error waiting for the deletion: %s", err)
+	}
+	if media := sim.CatalogMedia(catalogId); len(media) != 0 {
+		t.Fatalf("expected no media in the catalog, got %v", media)
+	}
+}
+
+func TestSimulatorNsxtEdgeGateway(t *testing.T) {
+	sim, client := newTestClient(t)
+	edgeId := sim.AddNsxtEdgeGateway("test-org", "test-vdc", "test-edge")
+
+	org, err := client.GetOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("error retrieving org: %s", err)
+	}
+	vdc, err := org.GetVDCByName("test-vdc", false)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	if !vdc.IsNsxt() {
+		t.Fatal("expected an NSX-T VDC")
+	}
+	edge, err := vdc.GetNsxtEdgeGatewayByName("test-edge")
+	if err != nil {
+		t.Fatalf("error retrieving edge gateway: %s", err)
+	}
+	if edge.EdgeGateway.ID != edgeId {
+		t.Fatalf("expected edge gateway ID %s, got %s", edgeId, edge.EdgeGateway.ID)
+	}
+	if _, err := vdc.GetNsxtEdgeGatewayByName("missing-edge"); !govcd.ContainsNotFound(err) {
+		t.Fatalf("expected a 'not found' error, got %v", err)
+	}
+}
+
+func TestSimulatorIpSpaceAllocation(t *testing.T) {
+	sim, client := newTestClient(t)
+	ipSpaceId := sim.AddIpSpace(types.IpSpace{
+		Name: "test-ip-space",
+		IPSpaceRanges: types.IPSpaceRanges{
+			IPRanges: []types.IpSpaceRangeValues{{StartIPAddress: "10.0.0.1", EndIPAddress: "10.0.0.4"}},
+		},
+		IPSpacePrefixes: []types.IPSpacePrefixes{{
+			IPPrefixSequence: []types.IPPrefixSequence{{StartingPrefixIPAddress: "192.168.0.0", PrefixLength: 30, TotalPrefixCount: 2}},
+		}},
+	})
+
+	org, err := client.GetOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("error retrieving org: %s", err)
+	}
+	allocate := func(request types.IpSpaceIpAllocationRequest) ([]types.IpSpaceIpAllocationRequestResult, error) {
+		return org.IpSpaceAllocateIp(ipSpaceId, &request)
+	}
+	if _, err := allocate(types.IpSpaceIpAllocationRequest{Type: types.IpSpaceIpAllocationTypeFloatingIp, Value: "10.0.0.2"}); err != nil {
+		t.Fatalf("error allocating IP: %s", err)
+	}
+	results, err := allocate(types.IpSpaceIpAllocationRequest{Type: types.IpSpaceIpAllocationTypeFloatingIp, Quantity: addrOf(2)})
+	if err != nil {
+		t.Fatalf("error allocating IPs: %s", err)
+	}
+	if len(results) != 2 || results[0].Value != "10.0.0.1" || results[1].Value != "10.0.0.3" {
+		t.Fatalf("expected the first free IPs 10.0.0.1 and 10.0.0.3, got %v", results)
+	}
+	if _, err := allocate(types.IpSpaceIpAllocationRequest{Type: types.IpSpaceIpAllocationTypeFloatingIp, Value: "10.0.0.3-10.0.0.4"}); err == nil {
+		t.Fatal("expected an error when allocating a range with an allocated IP")
+	}
+	if _, err := allocate(types.IpSpaceIpAllocationRequest{Type: types.IpSpaceIpAllocationTypeIpPrefix, PrefixLength: addrOf(30), Quantity: addrOf(1)}); err != nil {
+		t.Fatalf("error allocating IP prefix: %s", err)
+	}
+	if allocations := sim.IpSpaceAllocations(ipSpaceId); strings.Join(allocations, ",") != "10.0.0.1,10.0.0.2,10.0.0.3,192.168.0.0/30" {
+		t.Fatalf("unexpected allocations %v", allocations)
+	}
+
+	allocation, err := org.GetIpSpaceAllocationById(ipSpaceId, results[0].ID)
+	if err != nil {
+		t.Fatalf("error retrieving allocation: %s", err)
+	}
+	if err := allocation.Delete(); err != nil {
+		t.Fatalf("error deleting allocation: %s", err)
+	}
+	if _, err := org.GetIpSpaceAllocationById(ipSpaceId, results[0].ID); !govcd.ContainsNotFound(err) {
+		t.Fatalf("expected a 'not found' error, got %v", err)
+	}
+}
+
+func TestSimulatorErrorInjection(t *testing.T) {
+	sim, client := newTestClient(t)
+
+	sim.InjectError(http.MethodGet, `^/api/org/`, http.StatusInternalServerError, "simulated outage")
+	if _, err := client.GetOrgByName("test-org"); err == nil || !strings.Contains(err.Error(), "simulated outage") {
+		t.Fatalf("expected the injected error, got %v", err)
+	}
+
+	org, err := client.GetOrgByName("test-org")
+	if err != nil {
+		t.Fatalf("expected the injected error to be consumed, got %s", err)
+	}
+	vdc, err := org.GetVDCByName("test-vdc", false)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	sim.FailNextTask("vdcComposeVapp")
+	if _, err := vdc.CreateRawVApp("failing-vapp", ""); err == nil {
+		t.Fatal("expected the vApp creation task to fail")
+	}
+	if _, err := vdc.GetVAppByName("failing-vapp", true); !govcd.ContainsNotFound(err) {
+		t.Fatalf("expected the failed vApp not to exist, got %v", err)
+	}
+}
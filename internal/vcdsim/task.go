@@ -0,0 +1,79 @@
+package vcdsim
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func (sim *Simulator) registerTaskRoutes() {
+	sim.handle(http.MethodGet, `/api/task/([^/]+)`, sim.getTask)
+}
+
+// runTask creates a task for an operation on the owner entity. Operations complete immediately: apply is
+// called and the task is recorded as successful, unless a failure was requested with FailNextTask, in
+// which case the task ends with an error and apply is not called, or the task was stalled with
+// StallNextTask, in which case apply is called and the task keeps running.
+func (sim *Simulator) runTask(operationName string, owner *types.Reference, apply func()) *types.Task {
+	id := newId()
+	now := time.Now().Format(time.RFC3339)
+	task := &types.Task{
+		HREF:          sim.href("/api/task/%s", id),
+		Type:          types.MimeTask,
+		ID:            "urn:vcloud:task:" + id,
+		Name:          "task",
+		Status:        "success",
+		Operation:     operationName + " " + owner.Name,
+		OperationName: operationName,
+		StartTime:     now,
+		EndTime:       now,
+		Owner:         owner,
+		Progress:      100,
+	}
+
+	if takeMatchingTask(&sim.stalledTasks, operationName) {
+		task.Status = "running"
+		task.EndTime = ""
+		task.Progress = 50
+	}
+	if takeMatchingTask(&sim.failingTasks, operationName) {
+		task.Status = "error"
+		task.Error = &types.Error{
+			Message:        "simulated failure of " + operationName,
+			MajorErrorCode: http.StatusInternalServerError,
+			MinorErrorCode: "INTERNAL_SERVER_ERROR",
+		}
+	} else if apply != nil {
+		apply()
+	}
+	sim.tasks[id] = task
+	return task
+}
+
+// takeMatchingTask removes from names the first name contained in operationName, and reports whether
+// there was one
+func takeMatchingTask(names *[]string, operationName string) bool {
+	for i, name := range *names {
+		if strings.Contains(operationName, name) {
+			*names = append((*names)[:i], (*names)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// writeTask runs a task and writes it as an accepted response
+func (sim *Simulator) writeTask(w http.ResponseWriter, operationName string, owner *types.Reference, apply func()) {
+	writeXML(w, http.StatusAccepted, sim.runTask(operationName, owner, apply))
+}
+
+func (sim *Simulator) getTask(w http.ResponseWriter, r *http.Request, params []string) {
+	task, ok := sim.tasks[params[0]]
+	if !ok {
+		sim.notFound(w, r, "task "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, task)
+}
@@ -0,0 +1,804 @@
+package vcdsim
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Status values of vApps and VMs, as listed in types.VAppStatuses
+const (
+	statusResolved   = 1
+	statusPoweredOn  = 4
+	statusSuspended  = 3
+	statusPoweredOff = 8
+)
+
+// powerState is the part of the state shared by vApps and VMs
+type powerState struct {
+	status   int
+	deployed bool
+	snapshot *types.SnapshotItem
+}
+
+// vappEntry is the state of a simulated vApp
+type vappEntry struct {
+	powerState
+	id           string
+	name         string
+	description  string
+	vdc          *vdcEntry
+	runtimeLease int
+	storageLease int
+	properties   *types.ProductSection
+	networks     []string
+	created      time.Time
+}
+
+// vmEntry is the state of a simulated VM
+type vmEntry struct {
+	powerState
+	id             string
+	name           string
+	description    string
+	vapp           *vappEntry
+	created        time.Time
+	metrics        []*metricSample
+	memory         int64
+	cpus           int
+	coresPerSocket int
+	properties     *types.ProductSection
+	media          *mediaEntry
+}
+
+func (sim *Simulator) registerVappRoutes() {
+	const entity = `/api/vApp/(vapp|vm)-([^/]+)`
+	sim.handle(http.MethodGet, entity, sim.getVappOrVm)
+	sim.handle(http.MethodPut, `/api/vApp/vapp-([^/]+)`, sim.updateVapp)
+	sim.handle(http.MethodDelete, `/api/vApp/vapp-([^/]+)`, sim.deleteVapp)
+	sim.handle(http.MethodPost, entity+`/power/action/(\w+)`, sim.powerAction)
+	sim.handle(http.MethodPost, entity+`/action/(deploy|undeploy)`, sim.deployAction)
+	sim.handle(http.MethodPost, entity+`/action/(createSnapshot|revertToCurrentSnapshot|removeAllSnapshots)`, sim.snapshotAction)
+	sim.handle(http.MethodGet, entity+`/snapshotSection`, sim.getSnapshotSection)
+	sim.handle(http.MethodGet, `/api/vApp/vapp-([^/]+)/leaseSettingsSection/?`, sim.getLeaseSettings)
+	sim.handle(http.MethodPut, `/api/vApp/vapp-([^/]+)/leaseSettingsSection/?`, sim.updateLeaseSettings)
+	sim.handle(http.MethodGet, entity+`/productSections/?`, sim.getProductSections)
+	sim.handle(http.MethodPut, entity+`/productSections/?`, sim.updateProductSections)
+	sim.handle(http.MethodGet, `/api/vApp/vapp-([^/]+)/networkConfigSection/?`, sim.getNetworkConfig)
+	sim.handle(http.MethodPut, `/api/vApp/vapp-([^/]+)/networkConfigSection/?`, sim.updateNetworkConfig)
+	sim.handle(http.MethodGet, `/api/vApp/vm-([^/]+)/guestCustomizationSection/?`, sim.getGuestCustomization)
+	sim.handle(http.MethodGet, `/api/vApp/vm-([^/]+)/virtualHardwareSection/?`, sim.getVirtualHardware)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/securityTags/vm/([^/]+)`, sim.getVmSecurityTags)
+	sim.handle(http.MethodPost, `/api/vApp/vapp-([^/]+)/action/recomposeVApp`, sim.recomposeVapp)
+	sim.handle(http.MethodPost, `/api/vApp/vm-([^/]+)/action/reconfigureVm`, sim.reconfigureVm)
+}
+
+// AddVapp adds a powered off vApp to a VDC and returns its ID
+func (sim *Simulator) AddVapp(orgName, vdcName, vappName string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vapp := &vappEntry{
+		powerState:   powerState{status: statusPoweredOff},
+		id:           newId(),
+		name:         vappName,
+		vdc:          sim.mustFindVdc(orgName, vdcName),
+		runtimeLease: defaultRuntimeLease,
+		storageLease: defaultStorageLease,
+		created:      time.Now(),
+	}
+	sim.vapps[vapp.id] = vapp
+	return vapp.urn()
+}
+
+// AddVappNetwork adds an isolated network to a vApp
+func (sim *Simulator) AddVappNetwork(orgName, vdcName, vappName, networkName string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vdc := sim.mustFindVdc(orgName, vdcName)
+	for _, vapp := range sim.vdcVapps(vdc) {
+		if vapp.name == vappName {
+			vapp.networks = append(vapp.networks, networkName)
+			return
+		}
+	}
+	panic("vcdsim: vApp " + vappName + " not found in VDC " + vdcName)
+}
+
+// AddVm adds a powered off VM, with 1 CPU and 1024 MB of memory, to a vApp and returns its ID
+func (sim *Simulator) AddVm(orgName, vdcName, vappName, vmName string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vdc := sim.mustFindVdc(orgName, vdcName)
+	for _, vapp := range sim.vdcVapps(vdc) {
+		if vapp.name == vappName {
+			vm := &vmEntry{
+				powerState:     powerState{status: statusPoweredOff},
+				id:             newId(),
+				name:           vmName,
+				vapp:           vapp,
+				created:        time.Now(),
+				memory:         1024,
+				cpus:           1,
+				coresPerSocket: 1,
+			}
+			sim.vms[vm.id] = vm
+			return vm.urn()
+		}
+	}
+	panic("vcdsim: vApp " + vappName + " not found in VDC " + vdcName)
+}
+
+// Status returns the status of the vApp or VM with the given ID, as listed in types.VAppStatuses, and
+// false if the entity does not exist
+func (sim *Simulator) Status(id string) (int, bool) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if vapp, ok := sim.vapps[uuidOf(id)]; ok {
+		return vapp.status, true
+	}
+	if vm, ok := sim.vms[uuidOf(id)]; ok {
+		return vm.status, true
+	}
+	return 0, false
+}
+
+// Snapshot returns the current snapshot of the vApp or VM with the given ID, or nil if there is none
+func (sim *Simulator) Snapshot(id string) *types.SnapshotItem {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if vapp, ok := sim.vapps[uuidOf(id)]; ok {
+		return vapp.snapshot
+	}
+	if vm, ok := sim.vms[uuidOf(id)]; ok {
+		return vm.snapshot
+	}
+	return nil
+}
+
+// VmLocation returns the names of the vApp and the VDC of the VM with the given ID, the VM name, and false
+// if the VM does not exist
+func (sim *Simulator) VmLocation(id string) (vappName, vdcName, vmName string, found bool) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	vm, ok := sim.vms[uuidOf(id)]
+	if !ok {
+		return "", "", "", false
+	}
+	return vm.vapp.name, vm.vapp.vdc.name, vm.name, true
+}
+
+func (vapp *vappEntry) urn() string {
+	return "urn:vcloud:vapp:" + vapp.id
+}
+
+func (vm *vmEntry) urn() string {
+	return "urn:vcloud:vm:" + vm.id
+}
+
+// vdcVapps returns the vApps of a VDC, sorted by name
+func (sim *Simulator) vdcVapps(vdc *vdcEntry) []*vappEntry {
+	var vapps []*vappEntry
+	for _, vapp := range sim.vapps {
+		if vapp.vdc == vdc {
+			vapps = append(vapps, vapp)
+		}
+	}
+	sort.Slice(vapps, func(i, j int) bool { return vapps[i].name < vapps[j].name })
+	return vapps
+}
+
+// vappVms returns the VMs of a vApp, sorted by name
+func (sim *Simulator) vappVms(vapp *vappEntry) []*vmEntry {
+	var vms []*vmEntry
+	for _, vm := range sim.vms {
+		if vm.vapp == vapp {
+			vms = append(vms, vm)
+		}
+	}
+	sort.Slice(vms, func(i, j int) bool { return vms[i].name < vms[j].name })
+	return vms
+}
+
+func (sim *Simulator) vappReference(vapp *vappEntry) *types.Reference {
+	return &types.Reference{HREF: sim.href("/api/vApp/vapp-%s", vapp.id), ID: vapp.urn(), Type: types.MimeVApp, Name: vapp.name}
+}
+
+func (sim *Simulator) vmReference(vm *vmEntry) *types.Reference {
+	return &types.Reference{HREF: sim.href("/api/vApp/vm-%s", vm.id), ID: vm.urn(), Type: types.MimeVM, Name: vm.name}
+}
+
+func (sim *Simulator) vappView(vapp *vappEntry) types.VApp {
+	href := sim.href("/api/vApp/vapp-%s", vapp.id)
+	result := types.VApp{
+		HREF:        href,
+		Type:        types.MimeVApp,
+		ID:          vapp.urn(),
+		Name:        vapp.name,
+		Status:      vapp.status,
+		Deployed:    vapp.deployed,
+		Description: vapp.description,
+		DateCreated: vapp.created.Format(time.RFC3339),
+		Link: types.LinkList{
+			{Rel: "up", Type: types.MimeVDC, HREF: sim.href("/api/vdc/%s", vapp.vdc.id)},
+			{Rel: "edit", Type: types.MimeLeaseSettingSection, HREF: href + "/leaseSettingsSection/"},
+		},
+		LeaseSettingsSection: &types.LeaseSettingsSection{
+			HREF:                     href + "/leaseSettingsSection/",
+			Type:                     types.MimeLeaseSettingSection,
+			DeploymentLeaseInSeconds: vapp.runtimeLease,
+			StorageLeaseInSeconds:    vapp.storageLease,
+		},
+		Owner: &types.Owner{User: &types.Reference{Type: "application/vnd.vmware.admin.user+xml", Name: AdminUser}},
+	}
+	vms := sim.vappVms(vapp)
+	if len(vms) > 0 {
+		result.Children = &types.VAppChildren{}
+		for _, vm := range vms {
+			vmView := sim.vmView(vm)
+			result.Children.VM = append(result.Children.VM, &vmView)
+		}
+	}
+	return result
+}
+
+func (sim *Simulator) vmView(vm *vmEntry) types.Vm {
+	cpus, coresPerSocket := vm.cpus, vm.coresPerSocket
+	href := sim.href("/api/vApp/vm-%s", vm.id)
+	hardware := &types.VirtualHardwareSection{}
+	if vm.media != nil {
+		hardware.Item = append(hardware.Item, &types.VirtualHardwareItem{
+			ResourceType:    15,
+			ResourceSubType: types.VMsCDResourceSubType,
+			ElementName:     "CD/DVD Drive 1",
+			Description:     vm.media.name,
+			InstanceID:      3002,
+		})
+	}
+	return types.Vm{
+		HREF:        href,
+		Type:        types.MimeVM,
+		ID:          vm.urn(),
+		Name:        vm.name,
+		Status:      vm.status,
+		Deployed:    vm.deployed,
+		Description: vm.description,
+		DateCreated: vm.created.Format(time.RFC3339),
+		Link: types.LinkList{
+			{Rel: "up", Type: types.MimeVApp, HREF: sim.href("/api/vApp/vapp-%s", vm.vapp.id)},
+			{Rel: types.RelMediaInsertMedia, Type: types.MimeMediaInsertOrEjectParams, HREF: href + "/media/action/insertMedia"},
+			{Rel: types.RelMediaEjectMedia, Type: types.MimeMediaInsertOrEjectParams, HREF: href + "/media/action/ejectMedia"},
+		},
+		VAppParent: sim.vappReference(vm.vapp),
+		VmSpecSection: &types.VmSpecSection{
+			NumCpus:           &cpus,
+			NumCoresPerSocket: &coresPerSocket,
+			MemoryResourceMb:  &types.MemoryResourceMb{Configured: vm.memory},
+			DiskSection:       &types.DiskSection{},
+		},
+		VMCapabilities:         &types.VmCapabilities{},
+		VirtualHardwareSection: hardware,
+		BootOptions: &types.BootOptions{
+			BootDelay:            addrOf(0),
+			BootRetryDelay:       addrOf(0),
+			BootRetryEnabled:     addrOf(false),
+			EfiSecureBootEnabled: addrOf(false),
+			EnterBiosSetup:       addrOf(false),
+		},
+		NetworkConnectionSection: &types.NetworkConnectionSection{
+			HREF: sim.href("/api/vApp/vm-%s/networkConnectionSection/", vm.id),
+			Type: types.MimeNetworkConnectionSection,
+		},
+	}
+}
+
+// findPowerEntity returns the power state, the reference and the VMs of the vApp or VM identified by the
+// first two route parameters
+func (sim *Simulator) findPowerEntity(params []string) (*powerState, *types.Reference, []*vmEntry, bool) {
+	if params[0] == "vapp" {
+		vapp, ok := sim.vapps[params[1]]
+		if !ok {
+			return nil, nil, nil, false
+		}
+		return &vapp.powerState, sim.vappReference(vapp), sim.vappVms(vapp), true
+	}
+	vm, ok := sim.vms[params[1]]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return &vm.powerState, sim.vmReference(vm), []*vmEntry{vm}, true
+}
+
+func (sim *Simulator) getVappOrVm(w http.ResponseWriter, r *http.Request, params []string) {
+	if params[0] == "vapp" {
+		vapp, ok := sim.vapps[params[1]]
+		if !ok {
+			sim.notFound(w, r, "vApp "+params[1])
+			return
+		}
+		writeXML(w, http.StatusOK, sim.vappView(vapp))
+		return
+	}
+	vm, ok := sim.vms[params[1]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[1])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.vmView(vm))
+}
+
+// updateVapp changes the name and the description of a vApp
+func (sim *Simulator) updateVapp(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	var update types.VApp
+	if err := readXML(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	sim.writeTask(w, "vappUpdateVApp", sim.vappReference(vapp), func() {
+		if update.Name != "" {
+			vapp.name = update.Name
+		}
+		vapp.description = update.Description
+	})
+}
+
+func (sim *Simulator) deleteVapp(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	if vapp.deployed {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("The requested operation could not be executed on vApp \"%s\". Stop the vApp and try again.", vapp.name))
+		return
+	}
+	sim.writeTask(w, "vdcDeleteVapp", sim.vappReference(vapp), func() {
+		for _, vm := range sim.vappVms(vapp) {
+			delete(sim.vms, vm.id)
+			sim.deleteMetadataOf("/api/vApp/vm-" + vm.id)
+		}
+		delete(sim.vapps, vapp.id)
+		sim.deleteMetadataOf("/api/vApp/vapp-" + vapp.id)
+	})
+}
+
+// powerAction runs one of the power actions on a vApp or a VM. On a vApp, the action applies to all
+// its VMs
+func (sim *Simulator) powerAction(w http.ResponseWriter, r *http.Request, params []string) {
+	state, ref, vms, ok := sim.findPowerEntity(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	action := params[2]
+	var newStatus int
+	switch action {
+	case "powerOn", "reboot", "reset":
+		newStatus = statusPoweredOn
+	case "powerOff", "shutdown":
+		newStatus = statusPoweredOff
+	case "suspend":
+		newStatus = statusSuspended
+	default:
+		sim.writeError(w, r, http.StatusBadRequest, "unknown power action "+action)
+		return
+	}
+	if action != "powerOn" && !state.deployed {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("The requested operation could not be executed since %s \"%s\" is not running.", ref.Type, ref.Name))
+		return
+	}
+	sim.writeTask(w, params[0]+"Power"+action, ref, func() {
+		// Powering on deploys the entity. Powering off through the power actions leaves it deployed, as
+		// VCD does: only undeploy releases the resources
+		state.status = newStatus
+		state.deployed = true
+		for _, vm := range vms {
+			vm.status = newStatus
+			vm.deployed = true
+		}
+	})
+}
+
+// deployAction deploys or undeploys a vApp or a VM. Undeploy fails, as in VCD, when the entity is not
+// deployed
+func (sim *Simulator) deployAction(w http.ResponseWriter, r *http.Request, params []string) {
+	state, ref, vms, ok := sim.findPowerEntity(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	if params[2] == "deploy" {
+		var deployParams types.DeployVAppParams
+		if err := readXML(r, &deployParams); err != nil {
+			sim.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		newStatus := state.status
+		if deployParams.PowerOn {
+			newStatus = statusPoweredOn
+		}
+		sim.writeTask(w, params[0]+"Deploy", ref, func() {
+			state.deployed = true
+			state.status = newStatus
+			for _, vm := range vms {
+				vm.deployed = true
+				vm.status = newStatus
+			}
+		})
+		return
+	}
+
+	var undeployParams types.UndeployVAppParams
+	if err := readXML(r, &undeployParams); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !state.deployed {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("The requested operation could not be executed since vApp \"%s\" is not running.", ref.Name))
+		return
+	}
+	newStatus := statusPoweredOff
+	if undeployParams.UndeployPowerAction == "suspend" {
+		newStatus = statusSuspended
+	}
+	sim.writeTask(w, params[0]+"Undeploy", ref, func() {
+		state.deployed = false
+		state.status = newStatus
+		for _, vm := range vms {
+			vm.deployed = false
+			vm.status = newStatus
+		}
+	})
+}
+
+// snapshotParams is the payload of the 'createSnapshot' action
+type snapshotParams struct {
+	Name   string `xml:"name,attr,omitempty"`
+	Memory bool   `xml:"memory,attr"`
+}
+
+// snapshotAction creates, reverts or removes the snapshot of a vApp or a VM. Each entity has at most one
+// snapshot, as in VCD
+func (sim *Simulator) snapshotAction(w http.ResponseWriter, r *http.Request, params []string) {
+	state, ref, vms, ok := sim.findPowerEntity(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	switch params[2] {
+	case "createSnapshot":
+		var createParams snapshotParams
+		if err := readXML(r, &createParams); err != nil {
+			sim.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		// The snapshot of a vApp is taken on all its VMs
+		sim.writeTask(w, params[0]+"CreateSnapshot", ref, func() {
+			created := time.Now().Format(time.RFC3339Nano)
+			state.snapshot = &types.SnapshotItem{
+				Created:   created,
+				PoweredOn: createParams.Memory && state.status == statusPoweredOn,
+				Size:      4096,
+			}
+			for _, vm := range vms {
+				vm.snapshot = &types.SnapshotItem{
+					Created:   created,
+					PoweredOn: createParams.Memory && vm.status == statusPoweredOn,
+					Size:      4096,
+				}
+			}
+		})
+	case "revertToCurrentSnapshot":
+		if state.snapshot == nil {
+			sim.writeError(w, r, http.StatusBadRequest, ref.Name+" has no snapshot")
+			return
+		}
+		sim.writeTask(w, params[0]+"RevertToCurrentSnapshot", ref, func() {
+			newStatus := statusPoweredOff
+			if state.snapshot.PoweredOn {
+				newStatus = statusPoweredOn
+			}
+			state.status = newStatus
+			state.deployed = newStatus == statusPoweredOn
+			for _, vm := range vms {
+				vm.status = newStatus
+				vm.deployed = state.deployed
+			}
+		})
+	case "removeAllSnapshots":
+		sim.writeTask(w, params[0]+"RemoveAllSnapshots", ref, func() {
+			state.snapshot = nil
+			for _, vm := range vms {
+				vm.snapshot = nil
+			}
+		})
+	}
+}
+
+func (sim *Simulator) getSnapshotSection(w http.ResponseWriter, r *http.Request, params []string) {
+	state, _, _, ok := sim.findPowerEntity(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	section := types.SnapshotSection{
+		Info: "Snapshot information section",
+		HREF: sim.href("/api/vApp/%s-%s/snapshotSection", params[0], params[1]),
+	}
+	if state.snapshot != nil {
+		section.Snapshot = []*types.SnapshotItem{state.snapshot}
+	}
+	writeXML(w, http.StatusOK, section)
+}
+
+func (sim *Simulator) getLeaseSettings(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.vappView(vapp).LeaseSettingsSection)
+}
+
+func (sim *Simulator) updateLeaseSettings(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	var lease types.UpdateLeaseSettingsSection
+	if err := readXML(r, &lease); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	sim.writeTask(w, "vappUpdateLeaseSettings", sim.vappReference(vapp), func() {
+		if lease.DeploymentLeaseInSeconds != nil {
+			vapp.runtimeLease = *lease.DeploymentLeaseInSeconds
+		}
+		if lease.StorageLeaseInSeconds != nil {
+			vapp.storageLease = *lease.StorageLeaseInSeconds
+		}
+	})
+}
+
+// findPropertiesOf returns the product section of the vApp or VM identified by the first two route parameters,
+// with a reference to the entity
+func (sim *Simulator) findPropertiesOf(params []string) (**types.ProductSection, *types.Reference, bool) {
+	if params[0] == "vapp" {
+		vapp, ok := sim.vapps[params[1]]
+		if !ok {
+			return nil, nil, false
+		}
+		return &vapp.properties, sim.vappReference(vapp), true
+	}
+	vm, ok := sim.vms[params[1]]
+	if !ok {
+		return nil, nil, false
+	}
+	return &vm.properties, sim.vmReference(vm), true
+}
+
+func (sim *Simulator) getProductSections(w http.ResponseWriter, r *http.Request, params []string) {
+	properties, _, ok := sim.findPropertiesOf(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	result := types.ProductSectionList{
+		Xmlns:          types.XMLNamespaceVCloud,
+		ProductSection: *properties,
+	}
+	if result.ProductSection == nil {
+		result.ProductSection = &types.ProductSection{}
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (sim *Simulator) updateProductSections(w http.ResponseWriter, r *http.Request, params []string) {
+	properties, ref, ok := sim.findPropertiesOf(params)
+	if !ok {
+		sim.notFound(w, r, params[0]+" "+params[1])
+		return
+	}
+	var sections types.ProductSectionList
+	if err := readXML(r, &sections); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	sim.writeTask(w, "vappUpdateProductSections", ref, func() {
+		*properties = sections.ProductSection
+	})
+}
+
+// getNetworkConfig returns the network configuration of a vApp. Only the names of vApp networks are
+// simulated, as isolated networks without IP settings
+func (sim *Simulator) getNetworkConfig(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	section := types.NetworkConfigSection{
+		Xmlns: types.XMLNamespaceVCloud,
+		Ovf:   types.XMLNamespaceOVF,
+		HREF:  sim.href("/api/vApp/vapp-%s/networkConfigSection/", vapp.id),
+		Type:  types.MimeNetworkConfigSection,
+	}
+	for _, name := range vapp.networks {
+		section.NetworkConfig = append(section.NetworkConfig, types.VAppNetworkConfiguration{
+			NetworkName:   name,
+			Configuration: &types.NetworkConfiguration{FenceMode: types.FenceModeIsolated},
+		})
+	}
+	writeXML(w, http.StatusOK, section)
+}
+
+// getGuestCustomization returns the guest customization section of a VM. Guest customization is not
+// simulated, so it is always disabled
+func (sim *Simulator) getGuestCustomization(w http.ResponseWriter, r *http.Request, params []string) {
+	vm, ok := sim.vms[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	enabled := false
+	writeXML(w, http.StatusOK, types.GuestCustomizationSection{
+		Xmlns:   types.XMLNamespaceVCloud,
+		Ovf:     types.XMLNamespaceOVF,
+		HREF:    sim.href("/api/vApp/vm-%s/guestCustomizationSection/", vm.id),
+		Type:    types.MimeGuestCustomizationSection,
+		Enabled: &enabled,
+	})
+}
+
+// getVirtualHardware returns the virtual hardware section of a VM. Hardware items and extra configuration
+// are not simulated, so the section is empty
+func (sim *Simulator) getVirtualHardware(w http.ResponseWriter, r *http.Request, params []string) {
+	vm, ok := sim.vms[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, types.VirtualHardwareSection{
+		Xmlns: types.XMLNamespaceVCloud,
+		HREF:  sim.href("/api/vApp/vm-%s/virtualHardwareSection/", vm.id),
+		Type:  types.MimeVirtualHardwareSection,
+	})
+}
+
+// getVmSecurityTags returns the security tags of a VM. Security tags are not simulated, so there are none
+func (sim *Simulator) getVmSecurityTags(w http.ResponseWriter, r *http.Request, params []string) {
+	if _, ok := sim.vms[uuidOf(params[0])]; !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, types.EntitySecurityTags{Tags: []string{}})
+}
+
+// updateNetworkConfig accepts network configuration changes. vApp networks are not simulated, so the only
+// supported change is the removal of all networks, which leaves the vApp unchanged
+func (sim *Simulator) updateNetworkConfig(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	sim.writeTask(w, "vappUpdateNetworkConfigSection", sim.vappReference(vapp), nil)
+}
+
+// findVmByHref returns the VM of a reference, or nil if the reference is not to an existing VM
+func (sim *Simulator) findVmByHref(href string) *vmEntry {
+	_, id, found := strings.Cut(href, "/api/vApp/vm-")
+	if !found {
+		return nil
+	}
+	return sim.vms[strings.TrimSuffix(id, "/")]
+}
+
+// hasVmNamed returns true if the vApp has a VM with the given name, other than the given VM
+func (sim *Simulator) hasVmNamed(vapp *vappEntry, name string, other *vmEntry) bool {
+	for _, vm := range sim.vappVms(vapp) {
+		if vm != other && vm.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// recomposeVapp moves a VM into a vApp. Only moves are simulated: the sourced item must reference an
+// undeployed VM of the same Org, with 'sourceDelete' set. As in VCD, the VM keeps its ID
+func (sim *Simulator) recomposeVapp(w http.ResponseWriter, r *http.Request, params []string) {
+	vapp, ok := sim.vapps[params[0]]
+	if !ok {
+		sim.notFound(w, r, "vApp "+params[0])
+		return
+	}
+	var recompose types.ReComposeVAppParams
+	if err := readXML(r, &recompose); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	item := recompose.SourcedItem
+	if item == nil || item.Source == nil || !item.SourceDelete {
+		sim.writeError(w, r, http.StatusBadRequest, "vcdsim: only VM moves are simulated in vApp recompose")
+		return
+	}
+	vm := sim.findVmByHref(item.Source.HREF)
+	if vm == nil {
+		sim.notFound(w, r, "VM "+item.Source.HREF)
+		return
+	}
+	if vm.vapp.vdc.org != vapp.vdc.org {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("VM \"%s\" cannot be moved to a vApp of another organization.", vm.name))
+		return
+	}
+	if vm.deployed {
+		sim.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("The requested operation could not be executed since VM \"%s\" is running.", vm.name))
+		return
+	}
+	if sim.hasVmNamed(vapp, vm.name, vm) {
+		sim.writeError(w, r, http.StatusBadRequest, "DUPLICATE_NAME: the VM name "+vm.name+" is already used in vApp "+vapp.name)
+		return
+	}
+	sim.writeTask(w, "vappRecomposeVapp", sim.vappReference(vapp), func() {
+		vm.vapp = vapp
+	})
+}
+
+// reconfigureVm changes the name, the description and the CPUs and memory of a VM. Other sections are not
+// simulated
+func (sim *Simulator) reconfigureVm(w http.ResponseWriter, r *http.Request, params []string) {
+	vm, ok := sim.vms[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VM "+params[0])
+		return
+	}
+	var update types.Vm
+	if err := readXML(r, &update); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.Name != "" && sim.hasVmNamed(vm.vapp, update.Name, vm) {
+		sim.writeError(w, r, http.StatusBadRequest, "DUPLICATE_NAME: the VM name "+update.Name+" is already used in vApp "+vm.vapp.name)
+		return
+	}
+	spec := update.VmSpecSection
+	if spec != nil && vm.deployed && sim.changesVmSizing(vm, spec) {
+		// Hot add is not simulated, so CPUs and memory can only change while the VM is undeployed
+		sim.writeError(w, r, http.StatusBadRequest, "The requested operation could not be executed since VM \""+vm.name+"\" is running.")
+		return
+	}
+	sim.writeTask(w, "vappUpdateVm", sim.vmReference(vm), func() {
+		if update.Name != "" {
+			vm.name = update.Name
+		}
+		vm.description = update.Description
+		if spec == nil {
+			return
+		}
+		if spec.MemoryResourceMb != nil {
+			vm.memory = spec.MemoryResourceMb.Configured
+		}
+		if spec.NumCpus != nil {
+			vm.cpus = *spec.NumCpus
+		}
+		if spec.NumCoresPerSocket != nil {
+			vm.coresPerSocket = *spec.NumCoresPerSocket
+		}
+	})
+}
+
+// changesVmSizing returns true if the spec section changes the CPUs or the memory of the VM
+func (sim *Simulator) changesVmSizing(vm *vmEntry, spec *types.VmSpecSection) bool {
+	return (spec.MemoryResourceMb != nil && spec.MemoryResourceMb.Configured != vm.memory) ||
+		(spec.NumCpus != nil && *spec.NumCpus != vm.cpus) ||
+		(spec.NumCoresPerSocket != nil && *spec.NumCoresPerSocket != vm.coresPerSocket)
+}
@@ -0,0 +1,241 @@
+package vcdsim
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// vdcEntry is the state of a simulated VDC
+type vdcEntry struct {
+	id   string
+	name string
+	org  *orgEntry
+	nsxt bool
+	// storageProfileId is the UUID of the only storage profile of the VDC, named "*"
+	storageProfileId string
+}
+
+func (sim *Simulator) registerVdcRoutes() {
+	sim.handle(http.MethodGet, `/api/vdc/([^/]+)`, sim.getVdc)
+	sim.handle(http.MethodGet, `/api/admin/vdc/([^/]+)`, sim.getAdminVdc)
+	sim.handle(http.MethodGet, `/api/vdcStorageProfile/([^/]+)`, sim.getStorageProfile)
+	sim.handle(http.MethodPost, `/api/vdc/([^/]+)/action/composeVApp`, sim.composeVapp)
+	sim.handle(http.MethodGet, `/cloudapi/1.0.0/vdcs/([^/]+)/capabilities`, sim.getVdcCapabilities)
+}
+
+// AddVdc adds a VDC to an organization and returns its ID. When nsxt is true, the VDC is backed by NSX-T,
+// otherwise by NSX-V
+func (sim *Simulator) AddVdc(orgName, vdcName string, nsxt bool) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	org := sim.mustFindOrg(orgName)
+	vdc := &vdcEntry{
+		id:               newId(),
+		name:             vdcName,
+		org:              org,
+		nsxt:             nsxt,
+		storageProfileId: newId(),
+	}
+	sim.vdcs[vdc.id] = vdc
+	return vdc.urn()
+}
+
+func (vdc *vdcEntry) urn() string {
+	return "urn:vcloud:vdc:" + vdc.id
+}
+
+func (sim *Simulator) mustFindOrg(name string) *orgEntry {
+	org := sim.findOrgByName(name)
+	if org == nil {
+		panic("vcdsim: org " + name + " not found")
+	}
+	return org
+}
+
+func (sim *Simulator) mustFindVdc(orgName, vdcName string) *vdcEntry {
+	org := sim.mustFindOrg(orgName)
+	for _, vdc := range sim.orgVdcs(org) {
+		if vdc.name == vdcName {
+			return vdc
+		}
+	}
+	panic("vcdsim: VDC " + vdcName + " not found in org " + orgName)
+}
+
+// orgVdcs returns the VDCs of an organization, sorted by name
+func (sim *Simulator) orgVdcs(org *orgEntry) []*vdcEntry {
+	var vdcs []*vdcEntry
+	for _, vdc := range sim.vdcs {
+		if vdc.org == org {
+			vdcs = append(vdcs, vdc)
+		}
+	}
+	sort.Slice(vdcs, func(i, j int) bool { return vdcs[i].name < vdcs[j].name })
+	return vdcs
+}
+
+func (sim *Simulator) vdcReference(vdc *vdcEntry, admin bool) *types.Reference {
+	if admin {
+		return &types.Reference{HREF: sim.href("/api/admin/vdc/%s", vdc.id), ID: vdc.urn(), Type: types.MimeAdminVDC, Name: vdc.name}
+	}
+	return &types.Reference{HREF: sim.href("/api/vdc/%s", vdc.id), ID: vdc.urn(), Type: types.MimeVDC, Name: vdc.name}
+}
+
+// vdcView returns the user view of a VDC, with its vApps as resource entities
+func (sim *Simulator) vdcView(vdc *vdcEntry) types.Vdc {
+	result := types.Vdc{
+		HREF:            sim.href("/api/vdc/%s", vdc.id),
+		Type:            types.MimeVDC,
+		ID:              vdc.urn(),
+		Name:            vdc.name,
+		Status:          1,
+		AllocationModel: "Flex",
+		IsEnabled:       true,
+		Link: types.LinkList{
+			{Rel: "up", Type: types.MimeOrg, HREF: sim.href("/api/org/%s", vdc.org.id)},
+			{Rel: "edit", Type: types.MimeAdminVDC, HREF: sim.href("/api/admin/vdc/%s", vdc.id)},
+		},
+		ComputeCapacity: []*types.ComputeCapacity{{
+			CPU:    &types.CapacityWithUsage{Units: "MHz"},
+			Memory: &types.CapacityWithUsage{Units: "MB"},
+		}},
+		VdcStorageProfiles: &types.VdcStorageProfiles{
+			VdcStorageProfile: []*types.Reference{{
+				HREF: sim.href("/api/vdcStorageProfile/%s", vdc.storageProfileId),
+				ID:   "urn:vcloud:vdcstorageProfile:" + vdc.storageProfileId,
+				Type: "application/vnd.vmware.vcloud.vdcStorageProfile+xml",
+				Name: "*",
+			}},
+		},
+	}
+	entities := &types.ResourceEntities{}
+	for _, vapp := range sim.vdcVapps(vdc) {
+		entities.ResourceEntity = append(entities.ResourceEntity, &types.ResourceReference{
+			HREF: sim.href("/api/vApp/vapp-%s", vapp.id),
+			ID:   vapp.urn(),
+			Type: types.MimeVApp,
+			Name: vapp.name,
+		})
+	}
+	result.ResourceEntities = []*types.ResourceEntities{entities}
+	return result
+}
+
+func (sim *Simulator) getVdc(w http.ResponseWriter, r *http.Request, params []string) {
+	vdc, ok := sim.vdcs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VDC "+params[0])
+		return
+	}
+	writeXML(w, http.StatusOK, sim.vdcView(vdc))
+}
+
+// getStorageProfile returns the storage profile of a VDC. Storage profiles have no limit and no usage
+func (sim *Simulator) getStorageProfile(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, vdc := range sim.vdcs {
+		if vdc.storageProfileId == params[0] {
+			writeXML(w, http.StatusOK, types.VdcStorageProfile{
+				Xmlns:   types.XMLNamespaceVCloud,
+				ID:      "urn:vcloud:vdcstorageProfile:" + vdc.storageProfileId,
+				Name:    "*",
+				Enabled: addrOf(true),
+				Units:   "MB",
+				Default: true,
+			})
+			return
+		}
+	}
+	sim.notFound(w, r, "storage profile "+params[0])
+}
+
+func (sim *Simulator) getAdminVdc(w http.ResponseWriter, r *http.Request, params []string) {
+	vdc, ok := sim.vdcs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VDC "+params[0])
+		return
+	}
+	result := types.AdminVdc{
+		Xmlns: types.XMLNamespaceVCloud,
+		Vdc:   sim.vdcView(vdc),
+	}
+	result.HREF = sim.href("/api/admin/vdc/%s", vdc.id)
+	result.Type = types.MimeAdminVDC
+	result.Link = types.LinkList{
+		{Rel: "up", Type: types.MimeAdminOrg, HREF: sim.href("/api/admin/org/%s", vdc.org.id)},
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+// composeVapp creates an empty vApp. Only the name and the description of the compose parameters are used
+func (sim *Simulator) composeVapp(w http.ResponseWriter, r *http.Request, params []string) {
+	vdc, ok := sim.vdcs[params[0]]
+	if !ok {
+		sim.notFound(w, r, "VDC "+params[0])
+		return
+	}
+	var compose types.ComposeVAppParams
+	if err := readXML(r, &compose); err != nil {
+		sim.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, vapp := range sim.vdcVapps(vdc) {
+		if vapp.name == compose.Name {
+			sim.writeError(w, r, http.StatusBadRequest, "DUPLICATE_NAME: the vApp name "+compose.Name+" is already used")
+			return
+		}
+	}
+
+	vapp := &vappEntry{
+		id:           newId(),
+		name:         compose.Name,
+		description:  compose.Description,
+		vdc:          vdc,
+		powerState:   powerState{status: statusResolved},
+		runtimeLease: defaultRuntimeLease,
+		storageLease: defaultStorageLease,
+		created:      time.Now(),
+	}
+	task := sim.runTask("vdcComposeVapp", sim.vappReference(vapp), func() {
+		sim.vapps[vapp.id] = vapp
+	})
+	result := sim.vappView(vapp)
+	result.Tasks = &types.TasksInProgress{Task: []*types.Task{task}}
+	writeXML(w, http.StatusCreated, result)
+}
+
+func (sim *Simulator) getVdcCapabilities(w http.ResponseWriter, r *http.Request, params []string) {
+	vdc, ok := sim.vdcs[uuidOf(params[0])]
+	if !ok {
+		sim.notFound(w, r, "VDC "+params[0])
+		return
+	}
+	networkProvider := "NSX_V"
+	if vdc.nsxt {
+		networkProvider = types.VdcCapabilityNetworkProviderNsxt
+	}
+	writePage(w, []interface{}{
+		types.VdcCapability{
+			Name:     "networkProvider",
+			Value:    networkProvider,
+			Type:     "String",
+			Category: "General",
+		},
+	})
+}
+
+// writePage writes a single page of OpenAPI results
+func writePage(w http.ResponseWriter, values []interface{}) {
+	if values == nil {
+		values = []interface{}{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resultTotal": len(values),
+		"pageCount":   1,
+		"page":        1,
+		"pageSize":    128,
+		"values":      values,
+	})
+}
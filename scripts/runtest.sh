@@ -90,6 +90,20 @@ function unit_test {
     fi
 }
 
+function simulator_test {
+    if [ -n "$VERBOSE" ]
+    then
+        echo "go test -race -tags unit ../internal/vcdsim/ || exit 1"
+        echo "go test -race -tags simulator -v -timeout 5m -run TestSimulator . -vcd-simulator"
+    fi
+    if [ -z "$DRY_RUN" ]
+    then
+        go test -race -tags unit ../internal/vcdsim/ || exit 1
+        go test -race -tags simulator -v -timeout 5m -run TestSimulator . -vcd-simulator
+        check_exit_code
+    fi
+}
+
 function short_test {
     # If we are creating binary test files, we remove the old ones,
     # to avoid leftovers from previous runs to affect the current test
@@ -349,6 +363,9 @@ case $wanted in
     unit)
         unit_test
         ;;
+    simulator)
+        simulator_test
+        ;;
     short)
         export VCD_SKIP_TEMPLATE_WRITING=1
         short_test
@@ -0,0 +1,200 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultRetryOnStatus contains the HTTP statuses that are retried when the provider doesn't set 'retry_on_status'
+var defaultRetryOnStatus = []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+
+const (
+	// throttleBaseDelay is the delay before the first retry of a throttled request. It doubles at every retry
+	throttleBaseDelay = 500 * time.Millisecond
+	// throttleMaxDelay is the maximum delay between two retries of a throttled request
+	throttleMaxDelay = 30 * time.Second
+)
+
+// apiThrottle is an http.RoundTripper that coordinates all the API calls made through a VCDClient:
+// * it limits the number of requests sent to VCD at the same time, when maxConcurrent is greater than 0
+// * it retries the requests that get a response with one of the statuses in retryOn, with exponential backoff
+// and jitter, until maxWait is reached
+//
+// It also counts the throttled responses, so that they can be reported in the diagnostics.
+type apiThrottle struct {
+	transport http.RoundTripper
+	slots     chan struct{} // nil when the number of concurrent requests is not limited
+	retryOn   map[int]bool
+	maxWait   time.Duration
+
+	throttled atomic.Int64 // responses received with one of the retried statuses
+	retried   atomic.Int64 // requests sent again after a throttled response
+	reported  atomic.Int64 // value of 'throttled' when it was last reported in the diagnostics
+}
+
+// newApiThrottle wraps transport with the concurrency limit and the retries. maxWait is the total time spent
+// retrying a request before giving up and returning the last response
+func newApiThrottle(transport http.RoundTripper, maxConcurrent int, retryOnStatus []int, maxWait time.Duration) *apiThrottle {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	throttle := &apiThrottle{
+		transport: transport,
+		retryOn:   make(map[int]bool),
+		maxWait:   maxWait,
+	}
+	if maxConcurrent > 0 {
+		throttle.slots = make(chan struct{}, maxConcurrent)
+	}
+	for _, status := range retryOnStatus {
+		throttle.retryOn[status] = true
+	}
+	return throttle
+}
+
+// RoundTrip sends the request when a slot is available, and sends it again while the response has a retried
+// status and maxWait is not exhausted. Requests with a body that can't be replayed are never retried
+func (throttle *apiThrottle) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		if err := throttle.acquire(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := throttle.transport.RoundTrip(req)
+		throttle.release()
+		if err != nil || !throttle.retryOn[resp.StatusCode] {
+			return resp, err
+		}
+		throttle.throttled.Add(1)
+
+		delay := throttleDelay(attempt, resp.Header.Get("Retry-After"))
+		canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !canReplay || time.Since(start)+delay > throttle.maxWait {
+			log.Printf("[DEBUG] %s %s throttled with status %d: giving up after %d attempts",
+				req.Method, req.URL.Path, resp.StatusCode, attempt+1)
+			return resp, nil
+		}
+		log.Printf("[DEBUG] %s %s throttled with status %d: retrying in %s",
+			req.Method, req.URL.Path, resp.StatusCode, delay)
+
+		// The response is discarded, and the connection is reused for the next attempt
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		req, err = replayRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		throttle.retried.Add(1)
+	}
+}
+
+func (throttle *apiThrottle) acquire(ctx context.Context) error {
+	if throttle.slots == nil {
+		return nil
+	}
+	select {
+	case throttle.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (throttle *apiThrottle) release() {
+	if throttle.slots != nil {
+		<-throttle.slots
+	}
+}
+
+// replayRequest returns a copy of req with a fresh body, to be sent again
+func replayRequest(req *http.Request) (*http.Request, error) {
+	replay := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("error preparing the body of %s %s for a retry: %s", req.Method, req.URL.Path, err)
+		}
+		replay.Body = body
+	}
+	return replay, nil
+}
+
+// throttleDelay returns the delay before retrying a request for the given attempt (starting from 0).
+// The delay doubles at every attempt up to throttleMaxDelay, and is picked randomly in its upper half, so
+// that parallel requests throttled together don't come back together. A 'Retry-After' header expressed in
+// seconds is honored when it asks for a longer delay
+func throttleDelay(attempt int, retryAfter string) time.Duration {
+	delay := throttleMaxDelay
+	if attempt < 16 {
+		delay = min(throttleBaseDelay<<attempt, throttleMaxDelay)
+	}
+	// #nosec G404 -- The jitter doesn't need a secure random number generator
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		delay = max(delay, time.Duration(seconds)*time.Second)
+	}
+	return delay
+}
+
+// throttleDiagnostics returns a warning when VCD throttled some requests since the previous call
+func (throttle *apiThrottle) throttleDiagnostics() diag.Diagnostics {
+	throttled := throttle.throttled.Load()
+	previous := throttle.reported.Swap(throttled)
+	if throttled <= previous {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("VCLOUD throttled %d API requests", throttled-previous),
+		Detail: fmt.Sprintf("VCLOUD responded %d times with one of the statuses in 'retry_on_status' (%d times since the "+
+			"start of the run, %d requests retried). Consider lowering 'max_concurrent_requests' or the Terraform parallelism.",
+			throttled-previous, throttled, throttle.retried.Load()),
+	}}
+}
+
+// withThrottleDiagnostics returns copies of the given resources or data sources, whose operations add
+// throttleDiagnostics to their own diagnostics
+func withThrottleDiagnostics(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	wrapped := make(map[string]*schema.Resource, len(resources))
+	for name, resource := range resources {
+		resourceCopy := *resource
+		resourceCopy.CreateContext = addThrottleDiagnostics(resource.CreateContext)
+		resourceCopy.ReadContext = addThrottleDiagnostics(resource.ReadContext)
+		resourceCopy.UpdateContext = addThrottleDiagnostics(resource.UpdateContext)
+		resourceCopy.DeleteContext = addThrottleDiagnostics(resource.DeleteContext)
+		wrapped[name] = &resourceCopy
+	}
+	return wrapped
+}
+
+func addThrottleDiagnostics[F ~func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics](operation F) F {
+	if operation == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := operation(ctx, d, meta)
+		if vcdClient, ok := meta.(*VCDClient); ok && vcdClient.throttle != nil {
+			diags = append(diags, vcdClient.throttle.throttleDiagnostics()...)
+		}
+		return diags
+	}
+}
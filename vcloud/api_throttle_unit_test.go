@@ -0,0 +1,147 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Test_apiThrottleRetry checks that throttled requests are sent again with the same body, and reported once in
+// the diagnostics
+func Test_apiThrottleRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	throttle := newApiThrottle(nil, 0, defaultRetryOnStatus, time.Minute)
+	client := http.Client{Transport: throttle}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+
+	diags := throttle.throttleDiagnostics()
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "VCLOUD throttled 1 API requests" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if diags := throttle.throttleDiagnostics(); len(diags) != 0 {
+		t.Fatalf("expected the throttled requests to be reported only once, got %v", diags)
+	}
+}
+
+// Test_apiThrottleGiveUp checks that the last throttled response is returned when the retry timeout is
+// exhausted, and that statuses that are not configured are not retried
+func Test_apiThrottleGiveUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := http.Client{Transport: newApiThrottle(nil, 0, defaultRetryOnStatus, 0)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("expected a single call with status %d, got %d calls and status %d",
+			http.StatusServiceUnavailable, calls.Load(), resp.StatusCode)
+	}
+
+	client = http.Client{Transport: newApiThrottle(nil, 0, []int{http.StatusTooManyRequests}, time.Minute)}
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	if calls.Load() != 2 {
+		t.Fatalf("expected status %d not to be retried", http.StatusServiceUnavailable)
+	}
+}
+
+// Test_apiThrottleConcurrency checks that no more than the configured number of requests is in flight
+func Test_apiThrottleConcurrency(t *testing.T) {
+	const maxConcurrent = 2
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := http.Client{Transport: newApiThrottle(nil, maxConcurrent, nil, time.Minute)}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			_ = resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if maxInFlight.Load() > maxConcurrent {
+		t.Fatalf("expected at most %d concurrent requests, got %d", maxConcurrent, maxInFlight.Load())
+	}
+}
+
+// Test_throttleDelay checks the bounds of the backoff delays
+func Test_throttleDelay(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		delay := throttleDelay(attempt, "")
+		upper := throttleMaxDelay
+		if attempt < 16 {
+			upper = min(throttleBaseDelay<<attempt, throttleMaxDelay)
+		}
+		if delay < upper/2 || delay > upper {
+			t.Fatalf("attempt %d: delay %s not between %s and %s", attempt, delay, upper/2, upper)
+		}
+	}
+	if delay := throttleDelay(0, "10"); delay != 10*time.Second {
+		t.Fatalf("expected the 'Retry-After' delay to be honored, got %s", delay)
+	}
+	if delay := throttleDelay(0, "Wed, 21 Oct 2015 07:28:00 GMT"); delay > throttleBaseDelay {
+		t.Fatalf("expected a 'Retry-After' date to be ignored, got %s", delay)
+	}
+}
@@ -0,0 +1,715 @@
+package vcloud
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// This file contains the download of catalog items to local files. Files are downloaded in chunks, using
+// HTTP range requests, into a partial file named after the target with downloadPartSuffix. A chunk that
+// fails is requested again from the last byte received, and a partial file left by an interrupted run is
+// resumed by the next one. The partial file is renamed to its final name only when complete.
+//
+// The SHA-256 of a downloaded media item is written next to it, with downloadChecksumSuffix, in the format
+// of sha256sum. An existing file is only kept when it matches that checksum, and it is hashed again only
+// when it was modified after the checksum was written.
+//
+// vApp templates are exported as OVF (descriptor, disk files and manifest in the same directory) or as
+// OVA (a tar archive with the same contents). VCD doesn't publish the checksums of the exported files, so
+// the manifest is generated from the downloaded data, after the size of every file has been checked against
+// the descriptor. It contains the SHA-256 of every file, and is used to verify an existing export before
+// downloading it again. The ID of the exported template is written next to the export, with
+// exportSourceSuffix, so that an export of another template with the same file name is not reused.
+
+const (
+	// downloadChunkAttempts is the number of times a chunk is requested before the download fails
+	downloadChunkAttempts = 5
+	// downloadPartSuffix is added to the name of a file while it is being downloaded
+	downloadPartSuffix = ".part"
+	// downloadChecksumSuffix is added to the name of a downloaded media item to get the file with its SHA-256
+	downloadChecksumSuffix = ".sha256"
+	// exportSourceSuffix is added to the name of an exported vApp template to get the file with the ID of
+	// the template
+	exportSourceSuffix = ".source"
+	// ovaStagingSuffix is added to the name of an OVA to get the directory where its files are
+	// downloaded before being archived
+	ovaStagingSuffix = ".parts"
+)
+
+var (
+	// downloadChunkSize is the size of the ranges requested when downloading a file
+	downloadChunkSize int64 = 64 * 1024 * 1024
+	// downloadRetryDelay is the pause before requesting again a chunk that failed, multiplied by the attempt number
+	downloadRetryDelay = 2 * time.Second
+)
+
+// ovfManifestLine matches the lines of an OVF manifest, such as "SHA256(disk1.vmdk)= 0a1b..."
+var ovfManifestLine = regexp.MustCompile(`^(\w+)\((.+)\)\s*=\s*([0-9a-fA-F]+)$`)
+
+// enableCatalogItemDownload makes the files of a media item or vApp template available for download.
+// links are the links of the entity, refresh reloads it, and downloadHref returns the download link
+// found in the reloaded entity
+func enableCatalogItemDownload(client *govcd.Client, links types.LinkList, refresh func() error, downloadHref func() string) (string, error) {
+	enableHref := ""
+	for _, link := range links {
+		if link.Rel == "enable" {
+			enableHref = link.HREF
+			break
+		}
+	}
+	if enableHref == "" {
+		return "", fmt.Errorf("no link found to enable the download")
+	}
+	task, err := client.ExecuteTaskRequest(enableHref, http.MethodPost, "", "error enabling download: %s", nil)
+	if err != nil {
+		return "", err
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return "", fmt.Errorf("error enabling download: %s", err)
+	}
+	err = refresh()
+	if err != nil {
+		return "", err
+	}
+	href := downloadHref()
+	if href == "" {
+		return "", fmt.Errorf("no download link found after enabling the download")
+	}
+	return href, nil
+}
+
+// findDownloadHref returns the default download link among the given ones
+func findDownloadHref(links types.LinkList) string {
+	for _, link := range links {
+		if link.Rel == types.RelDownloadDefault {
+			return link.HREF
+		}
+	}
+	return ""
+}
+
+// downloadFile downloads href into fileName, resuming the partial file of a previous download if there is one.
+// When size is greater than zero, it is the expected size of the file. Returns the SHA-256 of the file
+func downloadFile(client *govcd.Client, href, fileName string, size int64) (string, error) {
+	downloadUrl, err := url.ParseRequestURI(href)
+	if err != nil {
+		return "", fmt.Errorf("error parsing download URL '%s': %s", href, err)
+	}
+
+	partName := fileName + downloadPartSuffix
+	partFile, err := os.OpenFile(filepath.Clean(partName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("error opening file '%s': %s", partName, err)
+	}
+	defer func() {
+		_ = partFile.Close()
+	}()
+
+	// The data from a previous download is part of the checksum
+	checksum := sha256.New()
+	offset, err := io.Copy(checksum, partFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading file '%s': %s", partName, err)
+	}
+	if size > 0 && offset > size {
+		offset, err = restartDownload(partFile, checksum)
+		if err != nil {
+			return "", err
+		}
+	}
+	if offset > 0 {
+		log.Printf("[DEBUG] resuming download of %s from byte %d", fileName, offset)
+	}
+
+	attempt := 0
+	for size <= 0 || offset < size {
+		end := offset + downloadChunkSize - 1
+		if size > 0 && end >= size {
+			end = size - 1
+		}
+		received, complete, err := downloadChunk(client, *downloadUrl, partFile, checksum, offset, end, &size)
+		if received < 0 {
+			// A server that ignores ranges sends the whole file again
+			offset, err = restartDownload(partFile, checksum)
+			if err != nil {
+				return "", err
+			}
+			err = fmt.Errorf("the download of '%s' could not be resumed and restarts from the beginning", href)
+		} else {
+			offset += received
+		}
+		if err == nil && complete {
+			break
+		}
+		if err == nil && received > 0 {
+			attempt = 0
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("no data received from byte %d", offset)
+		}
+		attempt++
+		if attempt >= downloadChunkAttempts {
+			return "", fmt.Errorf("error downloading '%s' after %d attempts, %d bytes were saved in '%s' "+
+				"and will be resumed by the next download: %s", href, attempt, offset, partName, err)
+		}
+		log.Printf("[DEBUG] error downloading %s from byte %d (attempt %d): %s", fileName, offset, attempt, err)
+		time.Sleep(time.Duration(attempt) * downloadRetryDelay)
+	}
+	if size > 0 && offset != size {
+		return "", fmt.Errorf("downloaded %d bytes from '%s', expected %d", offset, href, size)
+	}
+
+	err = partFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("error closing file '%s': %s", partName, err)
+	}
+	err = os.Rename(partName, fileName)
+	if err != nil {
+		return "", fmt.Errorf("error renaming '%s' to '%s': %s", partName, fileName, err)
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+// restartDownload empties the partial file of a download that can't be resumed
+func restartDownload(partFile *os.File, checksum hash.Hash) (int64, error) {
+	checksum.Reset()
+	err := partFile.Truncate(0)
+	if err == nil {
+		_, err = partFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error emptying file '%s': %s", partFile.Name(), err)
+	}
+	return 0, nil
+}
+
+// downloadChunk requests the bytes from offset to end (both included) and appends them to partFile.
+// An unknown size (zero) is set from the Content-Range header of the response. Returns the number of
+// bytes received, or -1 when the server sent the whole file and it must be requested again from the
+// start, and whether the download is complete
+func downloadChunk(client *govcd.Client, downloadUrl url.URL, partFile *os.File, checksum hash.Hash, offset, end int64, size *int64) (int64, bool, error) {
+	request := client.NewRequest(map[string]string{}, http.MethodGet, downloadUrl, nil)
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end))
+	response, err := client.Http.Do(request)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		if *size <= 0 {
+			*size = contentRangeSize(response.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		// The server ignored the range: the body is the whole file
+		if offset > 0 {
+			if response.ContentLength != offset {
+				return -1, false, fmt.Errorf("the server does not support resuming downloads")
+			}
+			// The partial file already contains the whole file
+			return 0, true, nil
+		}
+		received, err := io.Copy(io.MultiWriter(partFile, checksum), response.Body)
+		if err != nil {
+			return -1, false, err
+		}
+		if *size <= 0 {
+			*size = received
+		}
+		return received, true, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Only expected when the size is unknown and the previous chunk ended at the end of the file
+		if *size <= 0 {
+			*size = contentRangeSize(response.Header.Get("Content-Range"))
+			return 0, *size == offset, nil
+		}
+		return 0, false, fmt.Errorf("range %d-%d not satisfiable", offset, end)
+	default:
+		return 0, false, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	received, err := io.Copy(io.MultiWriter(partFile, checksum), response.Body)
+	if err != nil {
+		return received, false, err
+	}
+	return received, *size > 0 && offset+received >= *size, nil
+}
+
+// contentRangeSize returns the total size from a Content-Range header such as "bytes 0-99/1234", or
+// zero when it is not known
+func contentRangeSize(contentRange string) int64 {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return 0
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// fileSha256 returns the SHA-256 and the size of a local file
+func fileSha256(fileName string) (string, int64, error) {
+	file, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	checksum := sha256.New()
+	size, err := io.Copy(checksum, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading file '%s': %s", fileName, err)
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), size, nil
+}
+
+// checkDownloadChecksum compares the SHA-256 of a downloaded file with the expected one, if any, and
+// removes the file when they don't match
+func checkDownloadChecksum(fileName, actual, expected string) error {
+	if expected == "" || strings.EqualFold(actual, expected) {
+		return nil
+	}
+	err := os.Remove(fileName)
+	if err != nil {
+		log.Printf("[DEBUG] error removing file %s: %s", fileName, err)
+	}
+	return fmt.Errorf("the SHA-256 of '%s' is %s, expected %s. The file has been removed", fileName, actual, expected)
+}
+
+// writeDownloadChecksum writes the SHA-256 of a downloaded file next to it
+func writeDownloadChecksum(fileName, checksum string) error {
+	checksumName := fileName + downloadChecksumSuffix
+	err := os.WriteFile(checksumName, []byte(fmt.Sprintf("%s  %s\n", checksum, filepath.Base(fileName))), 0600)
+	if err != nil {
+		return fmt.Errorf("error writing file '%s': %s", checksumName, err)
+	}
+	return nil
+}
+
+// downloadedFileChecksum returns the SHA-256 written next to a file by a previous download, when the file
+// has the given size and still matches it. The file is hashed again only when it was modified after the
+// checksum was written. Returns an empty string when there is no such checksum or the file doesn't match it
+func downloadedFileChecksum(fileName string, size int64) string {
+	checksumName := fileName + downloadChecksumSuffix
+	fileInfo, err := os.Stat(fileName)
+	if err != nil || fileInfo.Size() != size {
+		return ""
+	}
+	checksumInfo, err := os.Stat(checksumName)
+	if err != nil {
+		return ""
+	}
+	// #nosec G304 -- the checksum file is written by the provider
+	contents, err := os.ReadFile(checksumName)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return ""
+	}
+	recorded := strings.ToLower(fields[0])
+	if !fileInfo.ModTime().After(checksumInfo.ModTime()) {
+		return recorded
+	}
+
+	actual, _, err := fileSha256(fileName)
+	if err != nil || actual != recorded {
+		log.Printf("[DEBUG] file %s was modified after its download", fileName)
+		return ""
+	}
+	err = writeDownloadChecksum(fileName, actual)
+	if err != nil {
+		log.Printf("[DEBUG] %s", err)
+	}
+	return actual
+}
+
+// downloadMedia downloads the contents of a media item into fileName and returns the SHA-256 of the file.
+// An existing file is kept when it matches the checksum written by a previous download, or expectedChecksum
+// when it is set
+func downloadMedia(client *govcd.Client, media *govcd.Media, fileName, expectedChecksum string) (string, error) {
+	fileName = filepath.Clean(fileName)
+	checksum := downloadedFileChecksum(fileName, media.Media.Size)
+	if checksum == "" && expectedChecksum != "" {
+		// A file downloaded by other means is kept when it matches the expected checksum
+		actual, size, err := fileSha256(fileName)
+		if err == nil && size == media.Media.Size && strings.EqualFold(actual, expectedChecksum) {
+			checksum = actual
+			err = writeDownloadChecksum(fileName, actual)
+			if err != nil {
+				log.Printf("[DEBUG] %s", err)
+			}
+		}
+	}
+	if checksum != "" && (expectedChecksum == "" || strings.EqualFold(checksum, expectedChecksum)) {
+		log.Printf("[DEBUG] media %s was already downloaded into %s", media.Media.Name, fileName)
+		return checksum, nil
+	}
+	// The checksum of a previous download no longer applies
+	_ = os.Remove(fileName + downloadChecksumSuffix)
+
+	var downloadSize int64
+	href, err := enableCatalogItemDownload(client, media.Media.Link, media.Refresh, func() string {
+		if media.Media.Files == nil {
+			return ""
+		}
+		for _, file := range media.Media.Files.File {
+			if href := findDownloadHref(file.Link); href != "" {
+				downloadSize = file.Size
+				return href
+			}
+		}
+		return ""
+	})
+	if err != nil {
+		return "", fmt.Errorf("error preparing the download of media %s: %s", media.Media.Name, err)
+	}
+	if downloadSize <= 0 {
+		downloadSize = media.Media.Size
+	}
+
+	checksum, err = downloadFile(client, href, fileName, downloadSize)
+	if err != nil {
+		return "", err
+	}
+	err = checkDownloadChecksum(fileName, checksum, expectedChecksum)
+	if err != nil {
+		return "", err
+	}
+	return checksum, writeDownloadChecksum(fileName, checksum)
+}
+
+// downloadVappTemplate exports a vApp template into fileName, as OVF or OVA depending on the extension
+// of the file name, and returns the SHA-256 of fileName. An existing export of the same template that
+// matches its manifest is kept, unless the SHA-256 of fileName doesn't match expectedChecksum
+func downloadVappTemplate(client *govcd.Client, vAppTemplate *govcd.VAppTemplate, fileName, expectedChecksum string) (string, error) {
+	fileName = filepath.Clean(fileName)
+	isOva := strings.EqualFold(filepath.Ext(fileName), ".ova")
+
+	templateId := vAppTemplate.VAppTemplate.ID
+	if verifyOvfExport(fileName, isOva) == nil {
+		checksum, _, err := fileSha256(fileName)
+		// An export of another template is only kept when it matches the expected checksum, as a file
+		// exported by other means
+		sameSource := exportSource(fileName) == templateId
+		if err == nil && (sameSource || expectedChecksum != "") && (expectedChecksum == "" || strings.EqualFold(checksum, expectedChecksum)) {
+			log.Printf("[DEBUG] vApp template %s was already exported into %s", vAppTemplate.VAppTemplate.Name, fileName)
+			if !sameSource {
+				err = writeExportSource(fileName, templateId)
+				if err != nil {
+					log.Printf("[DEBUG] %s", err)
+				}
+			}
+			return checksum, nil
+		}
+	}
+	// The source of a previous export no longer applies
+	_ = os.Remove(fileName + exportSourceSuffix)
+
+	descriptorHref, err := enableCatalogItemDownload(client, vAppTemplate.VAppTemplate.Link, vAppTemplate.Refresh, func() string {
+		return findDownloadHref(vAppTemplate.VAppTemplate.Link)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error preparing the download of vApp template %s: %s", vAppTemplate.VAppTemplate.Name, err)
+	}
+	descriptorUrl, err := url.ParseRequestURI(descriptorHref)
+	if err != nil {
+		return "", fmt.Errorf("error parsing download URL '%s': %s", descriptorHref, err)
+	}
+
+	directory := filepath.Dir(fileName)
+	baseName := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	descriptorName := filepath.Base(fileName)
+	if isOva {
+		directory = fileName + ovaStagingSuffix
+		descriptorName = baseName + ".ovf"
+		err = os.MkdirAll(directory, 0700)
+		if err != nil {
+			return "", fmt.Errorf("error creating directory '%s': %s", directory, err)
+		}
+	}
+
+	// The descriptor is small, and is always downloaded again
+	descriptorPath := filepath.Join(directory, descriptorName)
+	_ = os.Remove(descriptorPath + downloadPartSuffix)
+	descriptorChecksum, err := downloadFile(client, descriptorHref, descriptorPath, 0)
+	if err != nil {
+		return "", err
+	}
+	// #nosec G304 -- the descriptor was just downloaded by the provider
+	descriptorText, err := os.ReadFile(descriptorPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading OVF descriptor '%s': %s", descriptorPath, err)
+	}
+	var envelope govcd.Envelope
+	err = xml.Unmarshal(descriptorText, &envelope)
+	if err != nil {
+		return "", fmt.Errorf("error parsing OVF descriptor of vApp template %s: %s", vAppTemplate.VAppTemplate.Name, err)
+	}
+
+	names := []string{descriptorName}
+	checksums := map[string]string{descriptorName: descriptorChecksum}
+	for _, file := range envelope.File {
+		if file.HREF == "" || file.HREF != filepath.Base(file.HREF) || file.HREF == "." || file.HREF == ".." {
+			return "", fmt.Errorf("unexpected file name '%s' in the OVF descriptor", file.HREF)
+		}
+		if file.ChunkSize > 0 {
+			return "", fmt.Errorf("file '%s' of the OVF descriptor is split in chunks, which is not supported", file.HREF)
+		}
+		fileUrl, err := descriptorUrl.Parse("./" + url.PathEscape(file.HREF))
+		if err != nil {
+			return "", fmt.Errorf("error building the download URL of '%s': %s", file.HREF, err)
+		}
+		log.Printf("[DEBUG] downloading file %s of vApp template %s", file.HREF, vAppTemplate.VAppTemplate.Name)
+		checksum, err := downloadFile(client, fileUrl.String(), filepath.Join(directory, file.HREF), int64(file.Size))
+		if err != nil {
+			return "", err
+		}
+		names = append(names, file.HREF)
+		checksums[file.HREF] = checksum
+	}
+
+	manifestName := baseName + ".mf"
+	manifest := ""
+	for _, name := range names {
+		manifest += fmt.Sprintf("SHA256(%s)= %s\n", name, checksums[name])
+	}
+	err = os.WriteFile(filepath.Join(directory, manifestName), []byte(manifest), 0600)
+	if err != nil {
+		return "", fmt.Errorf("error writing OVF manifest: %s", err)
+	}
+
+	if isOva {
+		// The manifest follows the descriptor, as required by the OVF specification
+		names = append([]string{descriptorName, manifestName}, names[1:]...)
+		err = writeOva(fileName, directory, names)
+		if err != nil {
+			return "", err
+		}
+		err = os.RemoveAll(directory)
+		if err != nil {
+			log.Printf("[DEBUG] error removing directory %s: %s", directory, err)
+		}
+	}
+
+	checksum, _, err := fileSha256(fileName)
+	if err != nil {
+		return "", err
+	}
+	err = checkDownloadChecksum(fileName, checksum, expectedChecksum)
+	if err != nil {
+		return "", err
+	}
+	return checksum, writeExportSource(fileName, templateId)
+}
+
+// writeExportSource writes the ID of the exported vApp template next to the export
+func writeExportSource(fileName, templateId string) error {
+	sourceName := fileName + exportSourceSuffix
+	err := os.WriteFile(sourceName, []byte(templateId+"\n"), 0600)
+	if err != nil {
+		return fmt.Errorf("error writing file '%s': %s", sourceName, err)
+	}
+	return nil
+}
+
+// exportSource returns the ID of the vApp template written next to an export, or an empty string when
+// there is none
+func exportSource(fileName string) string {
+	// #nosec G304 -- the source file is written by the provider
+	contents, err := os.ReadFile(fileName + exportSourceSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
+
+// writeOva archives the given files of a directory into an OVA file, in the given order
+func writeOva(fileName, directory string, names []string) error {
+	partName := fileName + downloadPartSuffix
+	ova, err := os.OpenFile(filepath.Clean(partName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating file '%s': %s", partName, err)
+	}
+	defer func() {
+		_ = ova.Close()
+	}()
+
+	writer := tar.NewWriter(ova)
+	for _, name := range names {
+		err = addFileToTar(writer, filepath.Join(directory, name), name)
+		if err != nil {
+			return fmt.Errorf("error adding '%s' to '%s': %s", name, fileName, err)
+		}
+	}
+	err = writer.Close()
+	if err == nil {
+		err = ova.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("error writing '%s': %s", fileName, err)
+	}
+	return os.Rename(partName, fileName)
+}
+
+// addFileToTar adds a local file to a tar archive. Modification times are not set, so that the
+// same files always produce the same archive
+func addFileToTar(writer *tar.Writer, path, name string) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	err = writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     info.Size(),
+		Mode:     0644,
+		ModTime:  time.Unix(0, 0),
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+// parseOvfManifest returns the SHA-256 of each file listed in an OVF manifest
+func parseOvfManifest(reader io.Reader) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		matches := ovfManifestLine.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid manifest line '%s'", line)
+		}
+		if matches[1] != "SHA256" {
+			return nil, fmt.Errorf("unsupported manifest algorithm '%s'", matches[1])
+		}
+		checksums[matches[2]] = strings.ToLower(matches[3])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("empty manifest")
+	}
+	return checksums, nil
+}
+
+// verifyOvfExport checks that all the files of an OVF or OVA export match its manifest
+func verifyOvfExport(fileName string, isOva bool) error {
+	baseName := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	manifestName := baseName + ".mf"
+	descriptorName := baseName + ".ovf"
+
+	if !isOva {
+		manifestFile, err := os.Open(filepath.Join(filepath.Dir(fileName), manifestName))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = manifestFile.Close()
+		}()
+		expected, err := parseOvfManifest(manifestFile)
+		if err != nil {
+			return err
+		}
+		if _, found := expected[filepath.Base(fileName)]; !found {
+			return fmt.Errorf("the manifest does not contain '%s'", filepath.Base(fileName))
+		}
+		for name, checksum := range expected {
+			actual, _, err := fileSha256(filepath.Join(filepath.Dir(fileName), name))
+			if err != nil {
+				return err
+			}
+			if actual != checksum {
+				return fmt.Errorf("the SHA-256 of '%s' does not match the manifest", name)
+			}
+		}
+		return nil
+	}
+
+	ova, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = ova.Close()
+	}()
+	actual := make(map[string]string)
+	var expected map[string]string
+	reader := tar.NewReader(ova)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Name == manifestName {
+			expected, err = parseOvfManifest(reader)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		checksum := sha256.New()
+		_, err = io.Copy(checksum, reader)
+		if err != nil {
+			return err
+		}
+		actual[header.Name] = hex.EncodeToString(checksum.Sum(nil))
+	}
+	if expected == nil {
+		return fmt.Errorf("no manifest found in '%s'", fileName)
+	}
+	if _, found := expected[descriptorName]; !found {
+		return fmt.Errorf("the manifest does not contain '%s'", descriptorName)
+	}
+	for name, checksum := range expected {
+		if actual[name] != checksum {
+			return fmt.Errorf("the SHA-256 of '%s' does not match the manifest", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// testDownloadContents returns data that is different in every chunk
+func testDownloadContents(size int) []byte {
+	contents := make([]byte, size)
+	for i := range contents {
+		contents[i] = byte(i % 251)
+	}
+	return contents
+}
+
+func testSha256(contents []byte) string {
+	checksum := sha256.Sum256(contents)
+	return hex.EncodeToString(checksum[:])
+}
+
+// testDownloadServer serves contents with range support. When failAfter is greater than zero, the first
+// response is cut after sending that many bytes. The Range headers of all requests are recorded
+type testDownloadServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	ranges    []string
+	failAfter int
+}
+
+func newTestDownloadServer(t *testing.T, contents []byte, supportRanges bool, failAfter int) *testDownloadServer {
+	server := &testDownloadServer{failAfter: failAfter}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		server.ranges = append(server.ranges, r.Header.Get("Range"))
+		failAfter := server.failAfter
+		server.failAfter = 0
+		server.mu.Unlock()
+
+		if !supportRanges {
+			r.Header.Del("Range")
+		}
+		if failAfter > 0 {
+			start := 0
+			_, _ = fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+			w.Header().Set("Content-Length", "1000")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(contents[start : start+failAfter])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(contents))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (server *testDownloadServer) client() *govcd.Client {
+	return &govcd.Client{Http: *server.Server.Client()}
+}
+
+func setTestDownloadChunks(t *testing.T, chunkSize int64) {
+	previousChunkSize, previousDelay := downloadChunkSize, downloadRetryDelay
+	downloadChunkSize, downloadRetryDelay = chunkSize, 0
+	t.Cleanup(func() {
+		downloadChunkSize, downloadRetryDelay = previousChunkSize, previousDelay
+	})
+}
+
+// Test_downloadFile checks chunked downloads, with known and unknown sizes, and the recovery from errors
+func Test_downloadFile(t *testing.T) {
+	setTestDownloadChunks(t, 1000)
+	contents := testDownloadContents(4500)
+
+	tests := []struct {
+		name          string
+		size          int64
+		supportRanges bool
+		failAfter     int
+		partial       int
+		wantRanges    []string
+	}{
+		{
+			name:          "KnownSize",
+			size:          4500,
+			supportRanges: true,
+			wantRanges:    []string{"bytes=0-999", "bytes=1000-1999", "bytes=2000-2999", "bytes=3000-3999", "bytes=4000-4499"},
+		},
+		{
+			name:          "UnknownSize",
+			supportRanges: true,
+			// The size is known after the first response
+			wantRanges: []string{"bytes=0-999", "bytes=1000-1999", "bytes=2000-2999", "bytes=3000-3999", "bytes=4000-4499"},
+		},
+		{
+			name:          "ResumePartialFile",
+			size:          4500,
+			supportRanges: true,
+			partial:       2500,
+			wantRanges:    []string{"bytes=2500-3499", "bytes=3500-4499"},
+		},
+		{
+			name:          "RetryInterruptedChunk",
+			size:          4500,
+			supportRanges: true,
+			partial:       2500,
+			failAfter:     300,
+			wantRanges:    []string{"bytes=2500-3499", "bytes=2800-3799", "bytes=3800-4499"},
+		},
+		{
+			name:       "NoRangeSupport",
+			size:       4500,
+			wantRanges: []string{"bytes=0-999"},
+		},
+		{
+			name:       "NoRangeSupportWithPartialFile",
+			size:       4500,
+			partial:    2500,
+			wantRanges: []string{"bytes=2500-3499", "bytes=0-999"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestDownloadServer(t, contents, tt.supportRanges, tt.failAfter)
+			fileName := filepath.Join(t.TempDir(), "media.iso")
+			if tt.partial > 0 {
+				err := os.WriteFile(fileName+downloadPartSuffix, contents[:tt.partial], 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			checksum, err := downloadFile(server.client(), server.URL+"/transfer/media.iso", fileName, tt.size)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if checksum != testSha256(contents) {
+				t.Errorf("unexpected checksum %s", checksum)
+			}
+			downloaded, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(downloaded, contents) {
+				t.Errorf("the downloaded file (%d bytes) differs from the original one", len(downloaded))
+			}
+			if _, err := os.Stat(fileName + downloadPartSuffix); !os.IsNotExist(err) {
+				t.Errorf("expected the partial file to be renamed")
+			}
+			if strings.Join(server.ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("expected ranges %v, got %v", tt.wantRanges, server.ranges)
+			}
+		})
+	}
+}
+
+// Test_downloadFileKeepsPartialFile checks that a failed download leaves the received data for the next one
+func Test_downloadFileKeepsPartialFile(t *testing.T) {
+	setTestDownloadChunks(t, 1000)
+	contents := testDownloadContents(2500)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=0-999" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(contents))
+	}))
+	defer server.Close()
+
+	fileName := filepath.Join(t.TempDir(), "media.iso")
+	_, err := downloadFile(&govcd.Client{Http: *server.Client()}, server.URL+"/file", fileName, 2500)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	partial, err := os.ReadFile(fileName + downloadPartSuffix)
+	if err != nil {
+		t.Fatalf("expected a partial file: %s", err)
+	}
+	if !bytes.Equal(partial, contents[:1000]) {
+		t.Errorf("expected the first chunk in the partial file, got %d bytes", len(partial))
+	}
+	if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Errorf("expected no file with the final name")
+	}
+}
+
+func Test_checkDownloadChecksum(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "media.iso")
+	contents := []byte("contents")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := testSha256(contents)
+
+	if err := checkDownloadChecksum(fileName, checksum, ""); err != nil {
+		t.Errorf("unexpected error without expected checksum: %s", err)
+	}
+	if err := checkDownloadChecksum(fileName, checksum, strings.ToUpper(checksum)); err != nil {
+		t.Errorf("unexpected error with matching checksum: %s", err)
+	}
+	if err := checkDownloadChecksum(fileName, checksum, testSha256([]byte("other"))); err == nil {
+		t.Errorf("expected an error with a different checksum")
+	}
+	if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Errorf("expected the file to be removed after a checksum mismatch")
+	}
+}
+
+func Test_downloadedFileChecksum(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "media.iso")
+	contents := []byte("contents")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := testSha256(contents)
+
+	// A file of the right size that was not downloaded by the provider is not trusted
+	if got := downloadedFileChecksum(fileName, int64(len(contents))); got != "" {
+		t.Errorf("expected no checksum without checksum file, got %s", got)
+	}
+
+	err = writeDownloadChecksum(fileName, checksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := downloadedFileChecksum(fileName, int64(len(contents))); got != checksum {
+		t.Errorf("expected checksum %s, got %s", checksum, got)
+	}
+	if got := downloadedFileChecksum(fileName, int64(len(contents))+1); got != "" {
+		t.Errorf("expected no checksum with a different size, got %s", got)
+	}
+
+	// A file modified after the download is hashed again
+	later := time.Now().Add(time.Hour)
+	err = os.Chtimes(fileName, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := downloadedFileChecksum(fileName, int64(len(contents))); got != checksum {
+		t.Errorf("expected checksum %s after touching the file, got %s", checksum, got)
+	}
+	err = os.WriteFile(fileName, []byte("modified"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(fileName, later.Add(time.Hour), later.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := downloadedFileChecksum(fileName, int64(len(contents))); got != "" {
+		t.Errorf("expected no checksum after changing the file, got %s", got)
+	}
+}
+
+func Test_parseOvfManifest(t *testing.T) {
+	checksum := testSha256([]byte("disk"))
+	tests := []struct {
+		name     string
+		manifest string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "Valid",
+			manifest: "SHA256(template.ovf)= " + strings.ToUpper(checksum) + "\nSHA256(disk 1.vmdk) = " + checksum + "\n\n",
+			want:     map[string]string{"template.ovf": checksum, "disk 1.vmdk": checksum},
+		},
+		{
+			name:     "UnsupportedAlgorithm",
+			manifest: "SHA1(template.ovf)= 0123456789abcdef0123456789abcdef01234567\n",
+			wantErr:  true,
+		},
+		{
+			name:     "InvalidLine",
+			manifest: "template.ovf " + checksum + "\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Empty",
+			manifest: "\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOvfManifest(strings.NewReader(tt.manifest))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOvfManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for name, checksum := range tt.want {
+				if got[name] != checksum {
+					t.Errorf("expected %s for %s, got %s", checksum, name, got[name])
+				}
+			}
+		})
+	}
+}
+
+// testOvfExport writes the files of an OVF export and its manifest into a directory
+func testOvfExport(t *testing.T, directory string) {
+	files := map[string]string{
+		"template.ovf": "<Envelope/>",
+		"disk1.vmdk":   "first disk",
+		"disk2.vmdk":   "second disk",
+	}
+	manifest := ""
+	for _, name := range []string{"template.ovf", "disk1.vmdk", "disk2.vmdk"} {
+		err := os.WriteFile(filepath.Join(directory, name), []byte(files[name]), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest += "SHA256(" + name + ")= " + testSha256([]byte(files[name])) + "\n"
+	}
+	err := os.WriteFile(filepath.Join(directory, "template.mf"), []byte(manifest), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_verifyOvfExport(t *testing.T) {
+	t.Run("Ovf", func(t *testing.T) {
+		directory := t.TempDir()
+		testOvfExport(t, directory)
+		fileName := filepath.Join(directory, "template.ovf")
+		if err := verifyOvfExport(fileName, false); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		err := os.WriteFile(filepath.Join(directory, "disk2.vmdk"), []byte("changed disk"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verifyOvfExport(fileName, false); err == nil {
+			t.Fatal("expected an error after changing a disk")
+		}
+	})
+
+	t.Run("Ova", func(t *testing.T) {
+		directory := t.TempDir()
+		staging := filepath.Join(directory, "template.ova"+ovaStagingSuffix)
+		err := os.Mkdir(staging, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testOvfExport(t, staging)
+		fileName := filepath.Join(directory, "template.ova")
+		err = writeOva(fileName, staging, []string{"template.ovf", "template.mf", "disk1.vmdk", "disk2.vmdk"})
+		if err != nil {
+			t.Fatalf("error writing OVA: %s", err)
+		}
+		if err := verifyOvfExport(fileName, true); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		// The same files always produce the same archive
+		firstChecksum, _, err := fileSha256(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = writeOva(fileName, staging, []string{"template.ovf", "template.mf", "disk1.vmdk", "disk2.vmdk"})
+		if err != nil {
+			t.Fatalf("error writing OVA: %s", err)
+		}
+		secondChecksum, _, err := fileSha256(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if firstChecksum != secondChecksum {
+			t.Errorf("expected the same OVA checksum, got %s and %s", firstChecksum, secondChecksum)
+		}
+
+		// An archive without one of the files in the manifest is not valid
+		err = writeOva(fileName, staging, []string{"template.ovf", "template.mf", "disk1.vmdk"})
+		if err != nil {
+			t.Fatalf("error writing OVA: %s", err)
+		}
+		if err := verifyOvfExport(fileName, true); err == nil {
+			t.Fatal("expected an error for a missing disk")
+		}
+	})
+}
+
+func Test_downloadVappTemplateReusesExport(t *testing.T) {
+	directory := t.TempDir()
+	testOvfExport(t, directory)
+	fileName := filepath.Join(directory, "template.ovf")
+	descriptorChecksum, _, err := fileSha256(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The template has no link to enable the download, so that a new export fails at once
+	vAppTemplate := &govcd.VAppTemplate{VAppTemplate: &types.VAppTemplate{ID: "urn:vcloud:vapptemplate:first", Name: "template"}}
+
+	// An export without source is only kept when it matches the expected checksum
+	if _, err := downloadVappTemplate(nil, vAppTemplate, fileName, ""); err == nil {
+		t.Fatal("expected an export without source to be downloaded again")
+	}
+	checksum, err := downloadVappTemplate(nil, vAppTemplate, fileName, descriptorChecksum)
+	if err != nil || checksum != descriptorChecksum {
+		t.Fatalf("expected the export matching the checksum to be kept, got %s (error %v)", checksum, err)
+	}
+	if source := exportSource(fileName); source != vAppTemplate.VAppTemplate.ID {
+		t.Fatalf("expected the source %s to be written, got %q", vAppTemplate.VAppTemplate.ID, source)
+	}
+
+	// An export of the same template is kept
+	checksum, err = downloadVappTemplate(nil, vAppTemplate, fileName, "")
+	if err != nil || checksum != descriptorChecksum {
+		t.Fatalf("expected the export of the same template to be kept, got %s (error %v)", checksum, err)
+	}
+
+	// An export of another template with the same file name is downloaded again
+	vAppTemplate.VAppTemplate.ID = "urn:vcloud:vapptemplate:second"
+	if _, err := downloadVappTemplate(nil, vAppTemplate, fileName, ""); err == nil || !strings.Contains(err.Error(), "no link found to enable the download") {
+		t.Fatalf("expected the export of another template to be downloaded again, got %v", err)
+	}
+	if source := exportSource(fileName); source != "" {
+		t.Errorf("expected the source of the previous export to be removed, got %q", source)
+	}
+}
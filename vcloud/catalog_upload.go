@@ -0,0 +1,960 @@
+package vcloud
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// This file contains the upload of local files to catalog items. The catalog item is created first, then
+// VCD provides an upload link for every file it needs, and the files are sent in chunks with a
+// Content-Range header. A chunk that fails is sent again, starting from the bytes that VCD reports as
+// received.
+//
+// The source of the upload is recorded in a metadata entry of the catalog item, with uploadMarkerKey, which is
+// removed when the upload completes. When an upload fails and the item can still receive files, the item is
+// kept, and the next upload of the same source into the same catalog and item name resumes the files that were
+// not complete. An item with the marker that can't be resumed is removed, unless it has a task running, which
+// may be another upload in progress. The marker also records when the last upload attempt started: the first
+// upload into a catalog in a provider run removes the items of that catalog whose marker is older than
+// uploadMarkerMaxAge, so that the items of uploads that are never run again don't stay in the catalog.
+
+const (
+	// uploadChunkAttempts is the number of times a chunk is sent before the upload fails
+	uploadChunkAttempts = 5
+	// uploadMarkerKey is the key of the metadata entry that records the source of an upload in its catalog item
+	uploadMarkerKey = "terraform-provider-vcloud.upload"
+	// ovfDescriptorName is the name given by VCD to the OVF descriptor in the files of a vApp template
+	ovfDescriptorName = "descriptor.ovf"
+	// mimeUploadVappTemplateParams is the type of the request creating a vApp template from uploaded files
+	mimeUploadVappTemplateParams = "application/vnd.vmware.vcloud.uploadVAppTemplateParams+xml"
+)
+
+var (
+	// uploadRetryDelay is the pause before sending again a chunk that failed, multiplied by the attempt number
+	uploadRetryDelay = 2 * time.Second
+	// uploadLinksDelay is the pause between checks for the upload links of a vApp template
+	uploadLinksDelay = 5 * time.Second
+	// uploadCancelTimeout is how long the tasks of an upload that can't be resumed are given to stop
+	uploadCancelTimeout = 2 * time.Minute
+	// uploadMarkerMaxAge is the age of an upload marker after which its incomplete catalog item is removed
+	uploadMarkerMaxAge = 7 * 24 * time.Hour
+	// staleUploadsRemoved contains the IDs of the catalogs whose stale uploads were removed in this provider run
+	staleUploadsRemoved sync.Map
+)
+
+// catalogUpload defines the upload of a local file into a new catalog item
+type catalogUpload struct {
+	client      *govcd.Client
+	catalog     *govcd.Catalog
+	name        string
+	description string
+	// fileName is the ISO or other media file, or the OVA or OVF of a vApp template
+	fileName string
+	// pieceSize is the size of the chunks, in bytes
+	pieceSize int64
+	// checksum is the expected SHA-256 of fileName, if any
+	checksum string
+	// progressOrigin is the resource name used to show the upload progress on screen, which is not shown when empty
+	progressOrigin string
+
+	record   *uploadProgress
+	uploaded int64
+	total    int64
+}
+
+// uploadProgress is the record of an upload. The source fields are saved in the marker of the catalog item
+type uploadProgress struct {
+	ItemHref      string `json:"-"`
+	EntityHref    string `json:"-"`
+	SourceSize    int64  `json:"source_size"`
+	SourceModTime int64  `json:"source_mod_time"`
+	Checksum      string `json:"checksum,omitempty"`
+	// Started is the time, in seconds since the epoch, when the last attempt of the upload started
+	Started int64 `json:"started,omitempty"`
+	// marked is true when the marker is in the catalog item, so that the upload can be resumed
+	marked bool
+}
+
+// uploadEntity contains the fields shared by media items and vApp templates that are used during an upload
+type uploadEntity struct {
+	HREF                  string                 `xml:"href,attr,omitempty"`
+	Name                  string                 `xml:"name,attr"`
+	OvfDescriptorUploaded string                 `xml:"ovfDescriptorUploaded,attr,omitempty"`
+	Tasks                 *types.TasksInProgress `xml:"Tasks,omitempty"`
+	Files                 *types.FilesList       `xml:"Files,omitempty"`
+}
+
+// uploadMediaParams is the request to create a media item
+type uploadMediaParams struct {
+	XMLName     xml.Name `xml:"Media"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	Name        string   `xml:"name,attr"`
+	ImageType   string   `xml:"imageType,attr"`
+	Size        int64    `xml:"size,attr"`
+	Description string   `xml:"Description"`
+}
+
+// uploadVappTemplateParams is the request to create a vApp template from uploaded files
+type uploadVappTemplateParams struct {
+	XMLName     xml.Name `xml:"UploadVAppTemplateParams"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	Name        string   `xml:"name,attr"`
+	Description string   `xml:"Description"`
+}
+
+// uploadSection is a part of a local file
+type uploadSection struct {
+	fileName string
+	offset   int64
+	size     int64
+}
+
+// uploadSource is the contents of a file to upload, made of one or more sections of local files, such as
+// an entry of an OVA archive, or the chunks of a file split by an OVF
+type uploadSource []uploadSection
+
+func (source uploadSource) size() int64 {
+	var size int64
+	for _, section := range source {
+		size += section.size
+	}
+	return size
+}
+
+// read fills data with the contents of the source starting from offset
+func (source uploadSource) read(offset int64, data []byte) error {
+	for _, section := range source {
+		if len(data) == 0 {
+			return nil
+		}
+		if offset >= section.size {
+			offset -= section.size
+			continue
+		}
+		length := section.size - offset
+		if length > int64(len(data)) {
+			length = int64(len(data))
+		}
+		err := readFileAt(section.fileName, section.offset+offset, data[:length])
+		if err != nil {
+			return err
+		}
+		data = data[length:]
+		offset = 0
+	}
+	if len(data) > 0 {
+		return fmt.Errorf("reading beyond the end of the file")
+	}
+	return nil
+}
+
+func readFileAt(fileName string, offset int64, data []byte) error {
+	file, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	_, err = file.ReadAt(data, offset)
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %s", fileName, err)
+	}
+	return nil
+}
+
+// isIsoImage returns true if the file has the signature of an ISO 9660 or UDF image
+func isIsoImage(fileName string) (bool, error) {
+	header := make([]byte, 37000)
+	file, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	_, err = io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("error reading file '%s': %s", fileName, err)
+	}
+	// The volume descriptors start at 32769, 34817 or 36865, with "CD001" for ISO 9660 and "BEA01" for UDF
+	for _, offset := range []int{32769, 34817, 36865} {
+		signature := string(header[offset : offset+5])
+		if signature == "CD001" || signature == "BEA01" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ovfUploadSources returns the OVF descriptor of an OVA archive or OVF file, and the sources of the files
+// it references, by name. The files of an OVA are read from the archive, and the files of an OVF from
+// its directory
+func ovfUploadSources(fileName string) (uploadSource, map[string]uploadSource, error) {
+	var descriptor uploadSource
+	var findFile func(name string) (uploadSection, bool)
+
+	isOvf, err := isOvfFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isOvf {
+		info, err := os.Stat(fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		descriptor = uploadSource{{fileName: fileName, size: info.Size()}}
+		directory := filepath.Dir(fileName)
+		findFile = func(name string) (uploadSection, bool) {
+			filePath := filepath.Join(directory, filepath.FromSlash(name))
+			info, err := os.Stat(filePath)
+			if err != nil || !info.Mode().IsRegular() {
+				return uploadSection{}, false
+			}
+			return uploadSection{fileName: filePath, size: info.Size()}, true
+		}
+	} else {
+		entries, descriptorEntry, err := ovaEntries(fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		descriptor = uploadSource{descriptorEntry}
+		findFile = func(name string) (uploadSection, bool) {
+			entry, found := entries[name]
+			return entry, found
+		}
+	}
+
+	data := make([]byte, descriptor.size())
+	err = descriptor.read(0, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var envelope govcd.Envelope
+	err = xml.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing the OVF descriptor of '%s': %s", fileName, err)
+	}
+
+	files := make(map[string]uploadSource)
+	for _, reference := range envelope.File {
+		var source uploadSource
+		if reference.ChunkSize > 0 {
+			// The file is split in chunks named after the reference with a sequence number
+			for i := 0; i*reference.ChunkSize < reference.Size; i++ {
+				chunkName := fmt.Sprintf("%s.%09d", reference.HREF, i)
+				section, found := findFile(chunkName)
+				if !found {
+					return nil, nil, fmt.Errorf("file '%s' referenced by the OVF descriptor of '%s' not found", chunkName, fileName)
+				}
+				source = append(source, section)
+			}
+		} else {
+			section, found := findFile(reference.HREF)
+			if !found {
+				return nil, nil, fmt.Errorf("file '%s' referenced by the OVF descriptor of '%s' not found", reference.HREF, fileName)
+			}
+			source = uploadSource{section}
+		}
+		files[reference.HREF] = source
+	}
+	return descriptor, files, nil
+}
+
+// isOvfFile returns true if the file is an OVF descriptor rather than an OVA archive
+func isOvfFile(fileName string) (bool, error) {
+	if strings.EqualFold(filepath.Ext(fileName), ".ovf") {
+		return true, nil
+	}
+	file, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	header := make([]byte, 512)
+	count, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("error reading file '%s': %s", fileName, err)
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(header[:count]), []byte("<")), nil
+}
+
+// ovaEntries returns the location of the files in an OVA archive by name, and the OVF descriptor, which
+// is the first .ovf file of the archive. The files are not extracted
+func ovaEntries(fileName string) (map[string]uploadSection, uploadSection, error) {
+	file, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return nil, uploadSection{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	entries := make(map[string]uploadSection)
+	var descriptor uploadSection
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, uploadSection{}, fmt.Errorf("error reading OVA '%s': %s", fileName, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// The reader doesn't buffer, so the file is positioned at the start of the entry data
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, uploadSection{}, fmt.Errorf("error reading OVA '%s': %s", fileName, err)
+		}
+		entry := uploadSection{fileName: fileName, offset: offset, size: header.Size}
+		name := path.Base(header.Name)
+		entries[name] = entry
+		if descriptor.fileName == "" && strings.EqualFold(path.Ext(name), ".ovf") {
+			descriptor = entry
+		}
+	}
+	if descriptor.fileName == "" {
+		return nil, uploadSection{}, fmt.Errorf("no OVF descriptor found in '%s'", fileName)
+	}
+	return entries, descriptor, nil
+}
+
+// readUploadMarker returns the record of an upload saved in the marker of the catalog item, or nil if the
+// item has no marker
+func readUploadMarker(client *govcd.Client, itemHref string) (*uploadProgress, error) {
+	metadata := &types.Metadata{}
+	_, err := client.ExecuteRequest(itemHref+"/metadata", http.MethodGet, types.MimeMetaData,
+		"error retrieving the metadata of the catalog item: %s", nil, metadata)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range metadata.MetadataEntry {
+		if entry.Key != uploadMarkerKey || entry.TypedValue == nil {
+			continue
+		}
+		var record uploadProgress
+		err = json.Unmarshal([]byte(entry.TypedValue.Value), &record)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the upload marker of %s: %s", itemHref, err)
+		}
+		record.marked = true
+		return &record, nil
+	}
+	return nil, nil
+}
+
+// writeUploadMarker saves the record in the marker of its catalog item
+func writeUploadMarker(ctx context.Context, client *govcd.Client, record *uploadProgress) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	value := &types.MetadataValue{
+		Xmlns:      types.XMLNamespaceVCloud,
+		Xsi:        types.XMLNamespaceXSI,
+		TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: string(data)},
+	}
+	task, err := client.ExecuteTaskRequest(record.ItemHref+"/metadata/"+uploadMarkerKey, http.MethodPut,
+		types.MimeMetaDataValue, "error adding the upload marker: %s", value)
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
+	if err != nil {
+		return err
+	}
+	record.marked = true
+	return nil
+}
+
+// removeUploadMarker removes the marker from the catalog item of a completed upload
+func removeUploadMarker(ctx context.Context, client *govcd.Client, record *uploadProgress) {
+	if !record.marked {
+		return
+	}
+	task, err := client.ExecuteTaskRequest(record.ItemHref+"/metadata/"+uploadMarkerKey, http.MethodDelete,
+		"", "error removing the upload marker: %s", nil)
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
+	if err != nil {
+		log.Printf("[DEBUG] error removing the upload marker of %s: %s", record.ItemHref, err)
+		return
+	}
+	record.marked = false
+}
+
+// matches returns true if the record is about an upload of the given source
+func (record *uploadProgress) matches(source os.FileInfo) bool {
+	return record.SourceSize == source.Size() && record.SourceModTime == source.ModTime().UnixNano()
+}
+
+func getUploadEntity(client *govcd.Client, href string) (*uploadEntity, error) {
+	entity := &uploadEntity{}
+	_, err := client.ExecuteRequest(href, http.MethodGet, "", "error retrieving catalog item being uploaded: %s", nil, entity)
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// uploadFailed returns the task of the entity that failed, if any
+func (entity *uploadEntity) uploadFailed() *types.Task {
+	if entity.Tasks == nil {
+		return nil
+	}
+	for _, task := range entity.Tasks.Task {
+		switch task.Status {
+		case "error", "aborted", "canceled":
+			return task
+		}
+	}
+	return nil
+}
+
+// running returns true if the entity has a task in progress, such as the upload or the import of its files
+func (entity *uploadEntity) running() bool {
+	if entity.Tasks == nil {
+		return false
+	}
+	for _, task := range entity.Tasks.Task {
+		if isTaskRunning(task) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTaskRunning(task *types.Task) bool {
+	return task.Status == "running" || task.Status == "queued" || task.Status == "preRunning"
+}
+
+// incomplete returns true if VCD is still waiting for files of the entity
+func (entity *uploadEntity) incomplete() bool {
+	if entity.Files == nil {
+		return false
+	}
+	for _, file := range entity.Files.File {
+		if file.Size <= 0 || file.BytesTransferred < file.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelUpload cancels the running tasks of an entity being uploaded by this provider, and waits for them to stop
+func cancelUpload(client *govcd.Client, record *uploadProgress) {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadCancelTimeout)
+	defer cancel()
+	entity, err := getUploadEntity(client, record.EntityHref)
+	if err != nil || entity.Tasks == nil {
+		return
+	}
+	for _, taskItem := range entity.Tasks.Task {
+		if !isTaskRunning(taskItem) {
+			continue
+		}
+		task := govcd.NewTask(client)
+		task.Task = taskItem
+		err = task.CancelTask()
+		if err == nil {
+			err = waitForTask(ctx, *task)
+		}
+		if err != nil {
+			log.Printf("[DEBUG] error cancelling task %s of catalog item %s: %s", taskItem.HREF, record.ItemHref, err)
+		}
+	}
+}
+
+// removeIncompleteUpload deletes the catalog item of an upload. An item whose entity still has a task
+// running is never deleted, as the task may belong to an upload in progress
+func removeIncompleteUpload(client *govcd.Client, name string, record *uploadProgress) error {
+	entity, err := getUploadEntity(client, record.EntityHref)
+	if err == nil && entity.running() {
+		return fmt.Errorf("the incomplete catalog item '%s' has a task running, and is not removed", name)
+	}
+	log.Printf("[DEBUG] removing incomplete catalog item %s (%s)", name, record.ItemHref)
+	err = client.ExecuteRequestWithoutResponse(record.ItemHref, http.MethodDelete, "", "error deleting catalog item: %s", nil)
+	if err != nil && !govcd.ContainsNotFound(err) {
+		return fmt.Errorf("error deleting incomplete catalog item '%s': %s", name, err)
+	}
+	return nil
+}
+
+// uploadMedia uploads the file into a new media item. When checkIso is true, only ISO images are accepted
+func (u *catalogUpload) uploadMedia(ctx context.Context, checkIso bool) error {
+	if checkIso {
+		isIso, err := isIsoImage(u.fileName)
+		if err != nil {
+			return err
+		}
+		if !isIso {
+			return fmt.Errorf("file '%s' is not an ISO image. Set 'upload_any_file' to upload other files", u.fileName)
+		}
+	}
+	info, err := os.Stat(u.fileName)
+	if err != nil {
+		return err
+	}
+	params := &uploadMediaParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        u.name,
+		ImageType:   "iso",
+		Size:        info.Size(),
+		Description: u.description,
+	}
+	err = u.start(ctx, types.MimeMediaItem, params)
+	if err != nil {
+		return err
+	}
+
+	source := uploadSource{{fileName: u.fileName, size: info.Size()}}
+	return u.finish(ctx, func(entity *uploadEntity) error {
+		if entity.Files == nil || len(entity.Files.File) != 1 {
+			return nil
+		}
+		u.total = source.size()
+		return u.uploadFile(ctx, entity.Files.File[0], source)
+	})
+}
+
+// uploadVappTemplate uploads the OVA or OVF file into a new vApp template
+func (u *catalogUpload) uploadVappTemplate(ctx context.Context) error {
+	descriptor, files, err := ovfUploadSources(u.fileName)
+	if err != nil {
+		return err
+	}
+	params := &uploadVappTemplateParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        u.name,
+		Description: u.description,
+	}
+	err = u.start(ctx, mimeUploadVappTemplateParams, params)
+	if err != nil {
+		return err
+	}
+
+	return u.finish(ctx, func(entity *uploadEntity) error {
+		if !entity.incomplete() {
+			return nil
+		}
+		u.total = descriptor.size()
+		for _, source := range files {
+			u.total += source.size()
+		}
+		if entity.OvfDescriptorUploaded != "true" {
+			if len(entity.Files.File) != 1 {
+				return fmt.Errorf("expected one upload link for the OVF descriptor, found %d", len(entity.Files.File))
+			}
+			err := u.uploadFile(ctx, entity.Files.File[0], descriptor)
+			if err != nil {
+				return err
+			}
+		} else {
+			u.uploaded += descriptor.size()
+		}
+		// VCD adds the upload links of the other files after processing the descriptor
+		entity, err := u.waitForUploadLinks(ctx, len(files)+1)
+		if err != nil {
+			return err
+		}
+		for _, file := range entity.Files.File {
+			if file.Name == ovfDescriptorName {
+				continue
+			}
+			source, found := files[file.Name]
+			if !found {
+				return fmt.Errorf("VCD requested file '%s', which is not referenced by the OVF descriptor", file.Name)
+			}
+			err = u.uploadFile(ctx, file, source)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// start verifies the source file and creates the catalog item with the given request, unless an item with the
+// same name holds a previous upload of the same file that can be resumed
+func (u *catalogUpload) start(ctx context.Context, contentType string, params interface{}) error {
+	info, err := os.Stat(u.fileName)
+	if err != nil {
+		return err
+	}
+	if _, removed := staleUploadsRemoved.LoadOrStore(u.catalog.Catalog.ID, true); !removed {
+		u.removeStaleUploads()
+	}
+	record, err := u.previousUpload(info)
+	if err != nil {
+		return err
+	}
+
+	if u.checksum != "" && (record == nil || !strings.EqualFold(record.Checksum, u.checksum)) {
+		if u.progressOrigin != "" {
+			logForScreen(u.progressOrigin, fmt.Sprintf("%s.%s: verifying the SHA-256 of %s\n", u.progressOrigin, u.name, u.fileName))
+		}
+		checksum, _, err := fileSha256(u.fileName)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(checksum, u.checksum) {
+			// A resumable item is kept for an upload with the right checksum
+			return fmt.Errorf("the SHA-256 of '%s' is %s, expected %s", u.fileName, checksum, u.checksum)
+		}
+		if record != nil {
+			record.Checksum = u.checksum
+		}
+	}
+	if record != nil {
+		// The age of the marker counts from the last attempt
+		record.Started = time.Now().Unix()
+		err = writeUploadMarker(ctx, u.client, record)
+		if err != nil {
+			log.Printf("[DEBUG] error updating the record of the upload of %s: %s", u.fileName, err)
+		}
+		u.record = record
+		return nil
+	}
+
+	createHref := ""
+	for _, link := range u.catalog.Catalog.Link {
+		if link.Rel == "add" && link.Type == contentType {
+			createHref = link.HREF
+			break
+		}
+	}
+	if createHref == "" {
+		return fmt.Errorf("no link found to upload into catalog '%s'", u.catalog.Catalog.Name)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	catalogItem := &types.CatalogItem{}
+	_, err = u.client.ExecuteRequest(createHref, http.MethodPost, contentType, "error creating catalog item: %s", params, catalogItem)
+	if err != nil {
+		return err
+	}
+	if catalogItem.Entity == nil {
+		return fmt.Errorf("the catalog item '%s' was created without entity", u.name)
+	}
+	u.record = &uploadProgress{
+		ItemHref:      catalogItem.HREF,
+		EntityHref:    catalogItem.Entity.HREF,
+		SourceSize:    info.Size(),
+		SourceModTime: info.ModTime().UnixNano(),
+		Checksum:      u.checksum,
+		Started:       time.Now().Unix(),
+	}
+	err = writeUploadMarker(ctx, u.client, u.record)
+	if err != nil {
+		// The upload continues, but can't be resumed if it fails
+		log.Printf("[DEBUG] error recording the upload of %s: %s", u.fileName, err)
+	}
+	return nil
+}
+
+// previousUpload returns the record of the upload held by the catalog item with the same name, when it can be
+// resumed with the source file. An item with an upload marker that can't be resumed is removed, and any other
+// item with the same name stops the upload
+func (u *catalogUpload) previousUpload(source os.FileInfo) (*uploadProgress, error) {
+	itemHref := ""
+	for _, catalogItems := range u.catalog.Catalog.CatalogItems {
+		for _, catalogItem := range catalogItems.CatalogItem {
+			if catalogItem.Name == u.name {
+				itemHref = catalogItem.HREF
+			}
+		}
+	}
+	if itemHref == "" {
+		return nil, nil
+	}
+	record, err := readUploadMarker(u.client, itemHref)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("catalog item '%s' already exists. Upload with different name", u.name)
+	}
+	catalogItem := &types.CatalogItem{}
+	_, err = u.client.ExecuteRequest(itemHref, http.MethodGet, "", "error retrieving catalog item: %s", nil, catalogItem)
+	if err != nil {
+		return nil, err
+	}
+	if catalogItem.Entity == nil {
+		return nil, fmt.Errorf("the catalog item '%s' has no entity", u.name)
+	}
+	record.ItemHref = itemHref
+	record.EntityHref = catalogItem.Entity.HREF
+
+	entity, err := getUploadEntity(u.client, record.EntityHref)
+	if err != nil {
+		return nil, err
+	}
+	if failedTask := entity.uploadFailed(); failedTask != nil {
+		log.Printf("[DEBUG] the catalog item %s of a previous upload can't be resumed: %s", u.name, failedTask.Status)
+	} else if record.matches(source) {
+		log.Printf("[DEBUG] resuming the upload of %s into catalog item %s", u.fileName, u.name)
+		return record, nil
+	} else {
+		log.Printf("[DEBUG] the catalog item %s was uploaded from a different source", u.name)
+	}
+	err = removeIncompleteUpload(u.client, u.name, record)
+	if err != nil {
+		return nil, fmt.Errorf("%s. A previous upload into it, from a different source, may still be in progress", err)
+	}
+	return nil, nil
+}
+
+// removeStaleUploads removes the incomplete catalog items of the catalog, other than the one being uploaded, whose
+// upload marker is older than uploadMarkerMaxAge. Items with a task running are kept, and errors are only logged,
+// as they don't affect the upload
+func (u *catalogUpload) removeStaleUploads() {
+	for _, catalogItems := range u.catalog.Catalog.CatalogItems {
+		for _, catalogItem := range catalogItems.CatalogItem {
+			if catalogItem.Name == u.name {
+				continue
+			}
+			record, err := readUploadMarker(u.client, catalogItem.HREF)
+			if err != nil {
+				log.Printf("[DEBUG] error reading the upload marker of catalog item %s: %s", catalogItem.Name, err)
+				continue
+			}
+			if record == nil || record.Started == 0 || time.Since(time.Unix(record.Started, 0)) < uploadMarkerMaxAge {
+				continue
+			}
+			item := &types.CatalogItem{}
+			_, err = u.client.ExecuteRequest(catalogItem.HREF, http.MethodGet, "", "error retrieving catalog item: %s", nil, item)
+			if err != nil || item.Entity == nil {
+				log.Printf("[DEBUG] error retrieving the stale catalog item %s: %v", catalogItem.Name, err)
+				continue
+			}
+			record.ItemHref = catalogItem.HREF
+			record.EntityHref = item.Entity.HREF
+			log.Printf("[DEBUG] the upload into catalog item %s started on %s and was not resumed since", catalogItem.Name,
+				time.Unix(record.Started, 0).Format(time.RFC3339))
+			err = removeIncompleteUpload(u.client, catalogItem.Name, record)
+			if err != nil {
+				log.Printf("[DEBUG] %s", err)
+			}
+		}
+	}
+}
+
+// finish uploads the files of the catalog item with uploadFiles and waits for VCD to import them. When the
+// upload fails, the catalog item is kept if it can be resumed, and removed otherwise
+func (u *catalogUpload) finish(ctx context.Context, uploadFiles func(entity *uploadEntity) error) error {
+	entity, err := getUploadEntity(u.client, u.record.EntityHref)
+	if err == nil {
+		err = uploadFiles(entity)
+	}
+	if err != nil {
+		return u.abort(err)
+	}
+
+	if u.progressOrigin != "" {
+		logForScreen(u.progressOrigin, fmt.Sprintf("%s.%s: upload complete, waiting for VCD to import the catalog item\n", u.progressOrigin, u.name))
+	}
+	entity, err = getUploadEntity(u.client, u.record.EntityHref)
+	if err != nil {
+		return u.abort(err)
+	}
+	if entity.Tasks != nil {
+		for _, taskItem := range entity.Tasks.Task {
+			task := govcd.NewTask(u.client)
+			task.Task = taskItem
+			err = waitForTask(ctx, *task)
+			if err != nil {
+				return u.abort(err)
+			}
+		}
+	}
+	removeUploadMarker(ctx, u.client, u.record)
+	return nil
+}
+
+// abort handles an upload that failed with the given error. The catalog item is kept for the next upload
+// when VCD can still receive its files, and removed otherwise
+func (u *catalogUpload) abort(err error) error {
+	entity, entityErr := getUploadEntity(u.client, u.record.EntityHref)
+	if u.record.marked && entityErr == nil && entity.uploadFailed() == nil && (entity.incomplete() || entity.running()) {
+		return fmt.Errorf("%s. The incomplete catalog item '%s' is kept and its upload will be resumed by the next "+
+			"upload of '%s'", err, u.name, u.fileName)
+	}
+	// The upload can't be resumed, and its tasks are stopped before removing the item
+	cancelUpload(u.client, u.record)
+	removeErr := removeIncompleteUpload(u.client, u.name, u.record)
+	if removeErr != nil {
+		return fmt.Errorf("%s. %s", err, removeErr)
+	}
+	return fmt.Errorf("%s. The incomplete catalog item '%s' has been removed", err, u.name)
+}
+
+// waitForUploadLinks waits until the entity being uploaded has the given number of files
+func (u *catalogUpload) waitForUploadLinks(ctx context.Context, count int) (*uploadEntity, error) {
+	for {
+		entity, err := getUploadEntity(u.client, u.record.EntityHref)
+		if err != nil {
+			return nil, err
+		}
+		if task := entity.uploadFailed(); task != nil {
+			message := task.Status
+			if task.Error != nil {
+				message = task.Error.Message
+			}
+			return nil, fmt.Errorf("error processing the OVF descriptor: %s", message)
+		}
+		if entity.Files != nil && len(entity.Files.File) >= count {
+			return entity, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("the upload links were not provided in time: %s", ctx.Err())
+		case <-time.After(uploadLinksDelay):
+		}
+	}
+}
+
+// uploadFile sends the source to the upload link of the file, starting from the bytes already received by
+// VCD. A chunk that fails is sent again, up to uploadChunkAttempts times
+func (u *catalogUpload) uploadFile(ctx context.Context, file *types.File, source uploadSource) error {
+	uploadHref := ""
+	for _, link := range file.Link {
+		if link.Rel == "upload:default" {
+			uploadHref = link.HREF
+			break
+		}
+	}
+	if uploadHref == "" {
+		return fmt.Errorf("no upload link found for file '%s'", file.Name)
+	}
+	uploadUrl, err := url.ParseRequestURI(uploadHref)
+	if err != nil {
+		return fmt.Errorf("error parsing upload URL '%s': %s", uploadHref, err)
+	}
+
+	size := source.size()
+	if file.Size > 0 && file.Size != size {
+		return fmt.Errorf("the size of file '%s' is %d, but VCD expects %d", file.Name, size, file.Size)
+	}
+	offset := file.BytesTransferred
+	if offset < 0 || offset > size {
+		offset = 0
+	}
+	u.uploaded += offset
+	if offset > 0 {
+		log.Printf("[DEBUG] resuming the upload of %s from byte %d", file.Name, offset)
+	}
+
+	pieceSize := u.pieceSize
+	if pieceSize <= 0 {
+		pieceSize = 1024 * 1024
+	}
+	data := make([]byte, pieceSize)
+	attempt := 0
+	for offset < size {
+		if ctx.Err() != nil {
+			return fmt.Errorf("upload of '%s' interrupted after %d of %d bytes: %s", file.Name, offset, size, ctx.Err())
+		}
+		length := size - offset
+		if length > pieceSize {
+			length = pieceSize
+		}
+		err = source.read(offset, data[:length])
+		if err != nil {
+			return err
+		}
+		err = uploadChunk(u.client, *uploadUrl, data[:length], offset, size)
+		if err == nil {
+			offset += length
+			u.uploaded += length
+			attempt = 0
+			u.showProgress()
+			continue
+		}
+
+		attempt++
+		if attempt >= uploadChunkAttempts {
+			return fmt.Errorf("error uploading '%s' after %d attempts, %d of %d bytes were uploaded: %s",
+				file.Name, attempt, offset, size, err)
+		}
+		log.Printf("[DEBUG] error uploading %s from byte %d (attempt %d): %s", file.Name, offset, attempt, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(attempt) * uploadRetryDelay):
+		}
+		// The failed chunk may have been partially received
+		received, err := u.bytesTransferred(file.Name)
+		if err == nil && received <= size && received != offset {
+			u.uploaded += received - offset
+			offset = received
+		}
+	}
+	return nil
+}
+
+// bytesTransferred returns the number of bytes of a file received by VCD
+func (u *catalogUpload) bytesTransferred(fileName string) (int64, error) {
+	entity, err := getUploadEntity(u.client, u.record.EntityHref)
+	if err != nil {
+		return 0, err
+	}
+	if entity.Files != nil {
+		for _, file := range entity.Files.File {
+			if file.Name == fileName {
+				return file.BytesTransferred, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("file '%s' not found in catalog item '%s'", fileName, u.name)
+}
+
+func (u *catalogUpload) showProgress() {
+	if u.progressOrigin == "" || u.total <= 0 {
+		return
+	}
+	logForScreen(u.progressOrigin, fmt.Sprintf("%s.%s: Upload progress %.2f%%\n", u.progressOrigin, u.name,
+		float64(u.uploaded)*100/float64(u.total)))
+}
+
+// uploadChunk sends data as the bytes of a file starting from offset
+func uploadChunk(client *govcd.Client, uploadUrl url.URL, data []byte, offset, size int64) error {
+	request := client.NewRequestWitNotEncodedParams(nil, nil, http.MethodPut, uploadUrl, bytes.NewReader(data))
+	request.ContentLength = int64(len(data))
+	request.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(data))-1, size))
+	response, err := client.Http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,508 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// testUploadServer simulates VCD receiving a media item. PUT requests starting at failFrom or later
+// store half of the chunk and fail, until failures reaches zero
+type testUploadServer struct {
+	*httptest.Server
+	mu               sync.Mutex
+	size             int64
+	received         []byte
+	bytesTransferred int64
+	failFrom         int64
+	failures         int
+	created          int
+	deleted          bool
+	ranges           []string
+	// marker is the value of the upload marker of the catalog item, if any
+	marker string
+	// taskStatus overrides the status of the upload task
+	taskStatus string
+}
+
+func newTestUploadServer(t *testing.T, size int64) *testUploadServer {
+	server := &testUploadServer{size: size, received: make([]byte, size), failFrom: -1}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (server *testUploadServer) handle(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	taskStatus := "running"
+	if server.bytesTransferred == server.size {
+		taskStatus = "success"
+	}
+	if server.taskStatus != "" {
+		taskStatus = server.taskStatus
+	}
+	task := &types.Task{HREF: server.URL + "/task/1", Status: taskStatus}
+	metadataTask := &types.Task{HREF: server.URL + "/task/metadata", Status: "success"}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/catalog/1/media":
+		server.created++
+		server.writeXml(w, &types.CatalogItem{
+			HREF:   server.URL + "/catalogItem/1",
+			Name:   "media",
+			Entity: &types.Entity{HREF: server.URL + "/media/1"},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/media/1":
+		if server.deleted {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		server.writeXml(w, &types.Media{
+			HREF:  server.URL + "/media/1",
+			Name:  "media",
+			Tasks: &types.TasksInProgress{Task: []*types.Task{task}},
+			Files: &types.FilesList{File: []*types.File{{
+				Name:             "file",
+				Size:             server.size,
+				BytesTransferred: server.bytesTransferred,
+				Link:             types.LinkList{{Rel: "upload:default", HREF: server.URL + "/transfer/1/file"}},
+			}}},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/task/1":
+		server.writeXml(w, task)
+	case r.Method == http.MethodPost && r.URL.Path == "/task/1/action/cancel":
+		server.taskStatus = "aborted"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/task/metadata":
+		server.writeXml(w, metadataTask)
+	case r.Method == http.MethodGet && r.URL.Path == "/catalogItem/1" && !server.deleted:
+		server.writeXml(w, &types.CatalogItem{
+			HREF:   server.URL + "/catalogItem/1",
+			Name:   "media",
+			Entity: &types.Entity{HREF: server.URL + "/media/1"},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/catalogItem/1/metadata":
+		metadata := &types.Metadata{}
+		if server.marker != "" {
+			metadata.MetadataEntry = []*types.MetadataEntry{{
+				Key:        uploadMarkerKey,
+				TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: server.marker},
+			}}
+		}
+		server.writeXml(w, metadata)
+	case r.Method == http.MethodPut && r.URL.Path == "/catalogItem/1/metadata/"+uploadMarkerKey:
+		value := &types.MetadataValue{}
+		if err := xml.NewDecoder(r.Body).Decode(value); err != nil || value.TypedValue == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		server.marker = value.TypedValue.Value
+		server.writeXml(w, metadataTask)
+	case r.Method == http.MethodDelete && r.URL.Path == "/catalogItem/1/metadata/"+uploadMarkerKey:
+		server.marker = ""
+		server.writeXml(w, metadataTask)
+	case r.Method == http.MethodPut && r.URL.Path == "/transfer/1/file":
+		contentRange := r.Header.Get("Content-Range")
+		server.ranges = append(server.ranges, contentRange)
+		var start, end, total int64
+		_, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total)
+		if err != nil || total != server.size || start > server.bytesTransferred {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := new(bytes.Buffer)
+		_, _ = data.ReadFrom(r.Body)
+		if server.failures > 0 && server.failFrom >= 0 && start >= server.failFrom {
+			server.failures--
+			half := int64(data.Len() / 2)
+			copy(server.received[start:], data.Bytes()[:half])
+			server.bytesTransferred = start + half
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		copy(server.received[start:], data.Bytes())
+		server.bytesTransferred = end + 1
+	case r.Method == http.MethodDelete && r.URL.Path == "/catalogItem/1":
+		server.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (server *testUploadServer) writeXml(w http.ResponseWriter, value interface{}) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", types.MimeMediaItem)
+	_, _ = w.Write(data)
+}
+
+// upload returns the upload of the file into the catalog, which contains the catalog item when it was created
+func (server *testUploadServer) upload(fileName, checksum string) *catalogUpload {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	var catalogItems []*types.CatalogItems
+	if server.created > 0 && !server.deleted {
+		catalogItems = []*types.CatalogItems{{CatalogItem: []*types.Reference{{
+			HREF: server.URL + "/catalogItem/1",
+			Name: "media",
+		}}}}
+	}
+	return &catalogUpload{
+		client: &govcd.Client{Http: *server.Server.Client()},
+		catalog: &govcd.Catalog{Catalog: &types.Catalog{
+			ID:           "urn:vcloud:catalog:1",
+			Name:         "catalog",
+			CatalogItems: catalogItems,
+			Link:         types.LinkList{{Rel: "add", Type: types.MimeMediaItem, HREF: server.URL + "/catalog/1/media"}},
+		}},
+		name:      "media",
+		fileName:  fileName,
+		pieceSize: 1000,
+		checksum:  checksum,
+	}
+}
+
+func setTestUploadDelays(t *testing.T) {
+	previousRetryDelay, previousLinksDelay := uploadRetryDelay, uploadLinksDelay
+	uploadRetryDelay, uploadLinksDelay = 0, 0
+	t.Cleanup(func() {
+		uploadRetryDelay, uploadLinksDelay = previousRetryDelay, previousLinksDelay
+	})
+}
+
+// Test_catalogUploadRetriesChunk checks that a failed chunk is sent again from the bytes received by VCD
+func Test_catalogUploadRetriesChunk(t *testing.T) {
+	setTestUploadDelays(t)
+	contents := testDownloadContents(4500)
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestUploadServer(t, int64(len(contents)))
+	server.failFrom, server.failures = 2000, 1
+	err = server.upload(fileName, "").uploadMedia(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(server.received, contents) {
+		t.Errorf("the uploaded contents don't match the file")
+	}
+	expectedRanges := []string{
+		"bytes 0-999/4500", "bytes 1000-1999/4500", "bytes 2000-2999/4500", "bytes 2500-3499/4500", "bytes 3500-4499/4500",
+	}
+	if strings.Join(server.ranges, ",") != strings.Join(expectedRanges, ",") {
+		t.Errorf("expected ranges %v, got %v", expectedRanges, server.ranges)
+	}
+	if server.marker != "" {
+		t.Errorf("expected the upload marker to be removed, got %s", server.marker)
+	}
+}
+
+// Test_catalogUploadResume checks that a failed upload keeps the catalog item, and that the next upload
+// resumes it instead of creating a new one
+func Test_catalogUploadResume(t *testing.T) {
+	setTestUploadDelays(t)
+	contents := testDownloadContents(4500)
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestUploadServer(t, int64(len(contents)))
+	server.failFrom, server.failures = 3000, uploadChunkAttempts
+	err = server.upload(fileName, "").uploadMedia(context.Background(), false)
+	if err == nil || !strings.Contains(err.Error(), "will be resumed") {
+		t.Fatalf("expected a resumable error, got %v", err)
+	}
+	if server.deleted {
+		t.Fatalf("the incomplete catalog item should not be deleted")
+	}
+	if !strings.Contains(server.marker, "source_size") {
+		t.Fatalf("expected the upload marker to be kept, got %q", server.marker)
+	}
+
+	resumeFrom := server.bytesTransferred
+	server.ranges = nil
+	err = server.upload(fileName, testSha256(contents)).uploadMedia(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error resuming the upload: %s", err)
+	}
+	if server.created != 1 {
+		t.Errorf("expected the catalog item to be created once, got %d", server.created)
+	}
+	if !bytes.Equal(server.received, contents) {
+		t.Errorf("the uploaded contents don't match the file")
+	}
+	if len(server.ranges) == 0 || !strings.HasPrefix(server.ranges[0], fmt.Sprintf("bytes %d-", resumeFrom)) {
+		t.Errorf("expected the upload to resume from byte %d, got %v", resumeFrom, server.ranges)
+	}
+	if server.marker != "" {
+		t.Errorf("expected the upload marker to be removed, got %s", server.marker)
+	}
+}
+
+// Test_catalogUploadAbandoned checks that the incomplete item of an upload from a different source is removed
+// only when no task runs for it, and that an item without upload marker is never replaced
+func Test_catalogUploadAbandoned(t *testing.T) {
+	setTestUploadDelays(t)
+	contents := testDownloadContents(4500)
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	differentSource := fmt.Sprintf(`{"source_size":%d,"source_mod_time":1}`, len(contents))
+
+	t.Run("running", func(t *testing.T) {
+		server := newTestUploadServer(t, int64(len(contents)))
+		server.created, server.bytesTransferred, server.marker = 1, 1000, differentSource
+		err := server.upload(fileName, "").uploadMedia(context.Background(), false)
+		if err == nil || !strings.Contains(err.Error(), "may still be in progress") {
+			t.Fatalf("expected the upload to stop on the running item, got %v", err)
+		}
+		if server.deleted || server.created != 1 {
+			t.Errorf("expected the item being uploaded to be kept")
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		server := newTestUploadServer(t, int64(len(contents)))
+		server.created, server.bytesTransferred, server.marker, server.taskStatus = 1, 1000, differentSource, "error"
+		upload := server.upload(fileName, "")
+		err = upload.start(context.Background(), types.MimeMediaItem, &uploadMediaParams{Name: "media"})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !server.deleted || server.created != 2 {
+			t.Errorf("expected the failed item to be replaced, deleted: %v, created: %d", server.deleted, server.created)
+		}
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		server := newTestUploadServer(t, int64(len(contents)))
+		err := server.upload(fileName, strings.Repeat("0", 64)).uploadMedia(context.Background(), false)
+		if err == nil || !strings.Contains(err.Error(), "SHA-256") {
+			t.Fatalf("expected a checksum error, got %v", err)
+		}
+		if server.created != 0 {
+			t.Errorf("expected no catalog item to be created with a wrong checksum, got %d", server.created)
+		}
+	})
+
+	t.Run("unmarked", func(t *testing.T) {
+		server := newTestUploadServer(t, int64(len(contents)))
+		server.created, server.bytesTransferred = 1, int64(len(contents))
+		err := server.upload(fileName, "").uploadMedia(context.Background(), false)
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected an error for the existing item, got %v", err)
+		}
+		if server.deleted {
+			t.Errorf("expected the existing item to be kept")
+		}
+	})
+}
+
+// Test_catalogUploadRemovesStaleUploads checks that the incomplete items of other uploads are removed once their
+// marker is older than uploadMarkerMaxAge, unless a task runs for them
+func Test_catalogUploadRemovesStaleUploads(t *testing.T) {
+	setTestUploadDelays(t)
+	contents := testDownloadContents(4500)
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err := os.WriteFile(fileName, contents, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	marker := func(started time.Time) string {
+		return fmt.Sprintf(`{"source_size":%d,"source_mod_time":1,"started":%d}`, len(contents), started.Unix())
+	}
+
+	tests := []struct {
+		name        string
+		marker      string
+		taskStatus  string
+		wantDeleted bool
+	}{
+		{name: "stale", marker: marker(time.Now().Add(-uploadMarkerMaxAge - time.Hour)), taskStatus: "aborted", wantDeleted: true},
+		{name: "recent", marker: marker(time.Now().Add(-time.Hour)), taskStatus: "aborted"},
+		{name: "running", marker: marker(time.Now().Add(-uploadMarkerMaxAge - time.Hour)), taskStatus: "running"},
+		{name: "withoutStart", marker: fmt.Sprintf(`{"source_size":%d,"source_mod_time":1}`, len(contents)), taskStatus: "aborted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestUploadServer(t, int64(len(contents)))
+			server.created, server.marker, server.taskStatus = 1, tt.marker, tt.taskStatus
+			// The upload of another item in the same catalog
+			upload := server.upload(fileName, "")
+			upload.name = "other"
+			upload.removeStaleUploads()
+			if server.deleted != tt.wantDeleted {
+				t.Errorf("expected the item of the other upload to be deleted: %v, got %v", tt.wantDeleted, server.deleted)
+			}
+		})
+	}
+}
+
+func testOvaEntry(t *testing.T, writer *tar.Writer, name string, contents []byte) {
+	err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = writer.Write(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ovfUploadSources(t *testing.T) {
+	descriptor := []byte(`<?xml version="1.0"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <References>
+    <File ovf:href="disk1.vmdk" ovf:id="file1" ovf:size="1500"/>
+    <File ovf:href="disk2.vmdk" ovf:id="file2" ovf:size="2500" ovf:chunkSize="1000"/>
+  </References>
+</Envelope>`)
+	disk1 := testDownloadContents(1500)
+	disk2 := testDownloadContents(2500)
+	chunks := map[string][]byte{
+		"disk2.vmdk.000000000": disk2[:1000],
+		"disk2.vmdk.000000001": disk2[1000:2000],
+		"disk2.vmdk.000000002": disk2[2000:],
+	}
+
+	directory := t.TempDir()
+	ovaName := filepath.Join(directory, "template.ova")
+	buffer := new(bytes.Buffer)
+	writer := tar.NewWriter(buffer)
+	testOvaEntry(t, writer, "template.ovf", descriptor)
+	testOvaEntry(t, writer, "template.mf", []byte("SHA256(disk1.vmdk)= 00\n"))
+	testOvaEntry(t, writer, "disk1.vmdk", disk1)
+	for _, name := range []string{"disk2.vmdk.000000000", "disk2.vmdk.000000001", "disk2.vmdk.000000002"} {
+		testOvaEntry(t, writer, name, chunks[name])
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ovaName, buffer.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ovfDirectory := filepath.Join(directory, "ovf")
+	ovfName := filepath.Join(ovfDirectory, "template.ovf")
+	files := map[string][]byte{"template.ovf": descriptor, "disk1.vmdk": disk1}
+	for name, contents := range chunks {
+		files[name] = contents
+	}
+	if err := os.Mkdir(ovfDirectory, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(ovfDirectory, name), contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readAll := func(source uploadSource, offset int64) []byte {
+		data := make([]byte, source.size()-offset)
+		err := source.read(offset, data)
+		if err != nil {
+			t.Fatalf("error reading source: %s", err)
+		}
+		return data
+	}
+
+	for _, fileName := range []string{ovaName, ovfName} {
+		t.Run(filepath.Ext(fileName), func(t *testing.T) {
+			descriptorSource, sources, err := ovfUploadSources(fileName)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !bytes.Equal(readAll(descriptorSource, 0), descriptor) {
+				t.Errorf("wrong descriptor contents")
+			}
+			if len(sources) != 2 {
+				t.Fatalf("expected 2 files, got %d", len(sources))
+			}
+			if !bytes.Equal(readAll(sources["disk1.vmdk"], 0), disk1) {
+				t.Errorf("wrong contents for disk1.vmdk")
+			}
+			if len(sources["disk2.vmdk"]) != 3 {
+				t.Errorf("expected 3 chunks for disk2.vmdk, got %d", len(sources["disk2.vmdk"]))
+			}
+			// Reading across chunks
+			if !bytes.Equal(readAll(sources["disk2.vmdk"], 900), disk2[900:]) {
+				t.Errorf("wrong contents for disk2.vmdk")
+			}
+		})
+	}
+
+	t.Run("MissingFile", func(t *testing.T) {
+		err := os.Remove(filepath.Join(ovfDirectory, "disk1.vmdk"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = ovfUploadSources(ovfName)
+		if err == nil || !strings.Contains(err.Error(), "disk1.vmdk") {
+			t.Errorf("expected an error about the missing file, got %v", err)
+		}
+	})
+}
+
+func Test_isIsoImage(t *testing.T) {
+	directory := t.TempDir()
+	tests := []struct {
+		name   string
+		offset int
+		marker string
+		want   bool
+	}{
+		{name: "Iso9660", offset: 32769, marker: "CD001", want: true},
+		{name: "Udf", offset: 34817, marker: "BEA01", want: true},
+		{name: "Other", offset: 32769, marker: "XXXXX", want: false},
+		{name: "Small", offset: 0, marker: "CD001", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents := make([]byte, 40000)
+			if tt.name == "Small" {
+				contents = make([]byte, 100)
+			}
+			copy(contents[tt.offset:], tt.marker)
+			fileName := filepath.Join(directory, tt.name)
+			err := os.WriteFile(fileName, contents, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := isIsoImage(fileName)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("isIsoImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
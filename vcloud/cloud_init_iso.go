@@ -0,0 +1,239 @@
+package vcloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// This file contains a minimal ISO9660 image writer, used to build cloud-init NoCloud seed images. The images
+// contain a single root directory with a few regular files. File names are stored both in the primary volume
+// descriptor (upper case, ISO9660 level 2) and in a Joliet supplementary volume descriptor, which keeps the
+// original names. Operating systems reading the image use the Joliet names.
+//
+// Layout of the image, in sectors of isoSectorSize bytes:
+//
+//	0-15   system area (empty)
+//	16     primary volume descriptor
+//	17     Joliet supplementary volume descriptor
+//	18     volume descriptor set terminator
+//	19-22  path tables (L and M for the primary, then for the Joliet descriptor)
+//	23     root directory (primary)
+//	24     root directory (Joliet)
+//	25-    file contents, each one starting on a sector boundary
+//
+// All dates are left "not specified", so that the same files always produce the same image.
+
+const (
+	isoSectorSize        = 2048
+	isoFirstFileSector   = 25
+	isoMaxVolumeIdLength = 16 // Joliet volume identifiers hold 16 UCS-2 characters
+)
+
+// isoFile is a regular file to be stored in the root directory of an ISO9660 image
+type isoFile struct {
+	name    string
+	content []byte
+}
+
+// cloudInitSeedFiles returns the files of a NoCloud seed image. The 'network-config' file is only included when
+// networkConfig is not empty
+func cloudInitSeedFiles(userData, metaData, networkConfig string) []isoFile {
+	files := []isoFile{
+		{name: "meta-data", content: []byte(metaData)},
+		{name: "user-data", content: []byte(userData)},
+	}
+	if networkConfig != "" {
+		files = append(files, isoFile{name: "network-config", content: []byte(networkConfig)})
+	}
+	return files
+}
+
+// buildIso9660Image returns an ISO9660 image with the given volume identifier and files in its root directory
+func buildIso9660Image(volumeId string, files []isoFile) ([]byte, error) {
+	if volumeId == "" || len(volumeId) > isoMaxVolumeIdLength {
+		return nil, fmt.Errorf("volume identifier must have between 1 and %d characters, got '%s'", isoMaxVolumeIdLength, volumeId)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+
+	sortedFiles := make([]isoFile, len(files))
+	copy(sortedFiles, files)
+	sort.Slice(sortedFiles, func(i, j int) bool { return sortedFiles[i].name < sortedFiles[j].name })
+
+	// Assign file extents and check that all the directory entries fit into a single sector
+	extents := make([]uint32, len(sortedFiles))
+	nextSector := uint32(isoFirstFileSector)
+	primaryDirSize, jolietDirSize := 2*34, 2*34 // '.' and '..' entries
+	for index, file := range sortedFiles {
+		if file.name == "" || strings.ContainsAny(file.name, "/;") {
+			return nil, fmt.Errorf("invalid file name '%s'", file.name)
+		}
+		if index > 0 && sortedFiles[index-1].name == file.name {
+			return nil, fmt.Errorf("duplicate file name '%s'", file.name)
+		}
+		extents[index] = nextSector
+		nextSector += uint32((len(file.content) + isoSectorSize - 1) / isoSectorSize)
+		primaryDirSize += isoDirectoryRecordLength(len(isoPrimaryFileName(file.name)))
+		jolietDirSize += isoDirectoryRecordLength(len(isoJolietString(file.name)))
+	}
+	if primaryDirSize > isoSectorSize || jolietDirSize > isoSectorSize {
+		return nil, fmt.Errorf("too many files for a single directory sector")
+	}
+	totalSectors := nextSector
+
+	image := make([]byte, int(totalSectors)*isoSectorSize)
+	sector := func(number uint32) []byte {
+		return image[int(number)*isoSectorSize : int(number+1)*isoSectorSize]
+	}
+
+	const (
+		primaryRootSector = 23
+		jolietRootSector  = 24
+	)
+	writeIsoVolumeDescriptor(sector(16), 1, volumeId, totalSectors, 19, 20, primaryRootSector, false)
+	writeIsoVolumeDescriptor(sector(17), 2, volumeId, totalSectors, 21, 22, jolietRootSector, true)
+	terminator := sector(18)
+	terminator[0] = 255
+	copy(terminator[1:6], "CD001")
+	terminator[6] = 1
+
+	writeIsoPathTable(sector(19), primaryRootSector, binary.LittleEndian)
+	writeIsoPathTable(sector(20), primaryRootSector, binary.BigEndian)
+	writeIsoPathTable(sector(21), jolietRootSector, binary.LittleEndian)
+	writeIsoPathTable(sector(22), jolietRootSector, binary.BigEndian)
+
+	for _, root := range []struct {
+		sector uint32
+		joliet bool
+	}{{primaryRootSector, false}, {jolietRootSector, true}} {
+		var directory bytes.Buffer
+		directory.Write(isoDirectoryRecord([]byte{0}, root.sector, isoSectorSize, true))
+		directory.Write(isoDirectoryRecord([]byte{1}, root.sector, isoSectorSize, true))
+		for index, file := range sortedFiles {
+			name := isoPrimaryFileName(file.name)
+			if root.joliet {
+				name = isoJolietString(file.name)
+			}
+			directory.Write(isoDirectoryRecord(name, extents[index], uint32(len(file.content)), false))
+		}
+		copy(sector(root.sector), directory.Bytes())
+	}
+
+	for index, file := range sortedFiles {
+		copy(image[int(extents[index])*isoSectorSize:], file.content)
+	}
+
+	return image, nil
+}
+
+// writeIsoVolumeDescriptor fills a primary (type 1) or supplementary (type 2) volume descriptor
+func writeIsoVolumeDescriptor(descriptor []byte, descriptorType byte, volumeId string, totalSectors, lPathTable, mPathTable, rootSector uint32, joliet bool) {
+	descriptor[0] = descriptorType
+	copy(descriptor[1:6], "CD001")
+	descriptor[6] = 1
+
+	identifier := func(offset, length int, value string) {
+		field := descriptor[offset : offset+length]
+		if joliet {
+			for i := 0; i+1 < length; i += 2 {
+				field[i], field[i+1] = 0, ' '
+			}
+			copy(field, isoJolietString(value))
+			return
+		}
+		for i := range field {
+			field[i] = ' '
+		}
+		copy(field, value)
+	}
+	identifier(8, 32, "")
+	identifier(40, 32, volumeId)
+	isoPutBothEndian32(descriptor[80:88], totalSectors)
+	if joliet {
+		copy(descriptor[88:91], "%/E") // UCS-2 level 3
+	}
+	isoPutBothEndian16(descriptor[120:124], 1)
+	isoPutBothEndian16(descriptor[124:128], 1)
+	isoPutBothEndian16(descriptor[128:132], isoSectorSize)
+	isoPutBothEndian32(descriptor[132:140], isoPathTableSize)
+	binary.LittleEndian.PutUint32(descriptor[140:144], lPathTable)
+	binary.BigEndian.PutUint32(descriptor[148:152], mPathTable)
+	copy(descriptor[156:190], isoDirectoryRecord([]byte{0}, rootSector, isoSectorSize, true))
+	for _, field := range [][2]int{{190, 128}, {318, 128}, {446, 128}, {574, 128}, {702, 37}, {739, 37}, {776, 37}} {
+		identifier(field[0], field[1], "")
+	}
+	for _, offset := range []int{813, 830, 847, 864} {
+		copy(descriptor[offset:offset+16], "0000000000000000")
+	}
+	descriptor[881] = 1
+}
+
+// isoPathTableSize is the size of a path table with the root directory only
+const isoPathTableSize = 10
+
+func writeIsoPathTable(table []byte, rootSector uint32, order binary.ByteOrder) {
+	table[0] = 1 // length of the root directory identifier
+	order.PutUint32(table[2:6], rootSector)
+	order.PutUint16(table[6:8], 1) // the root directory is its own parent
+}
+
+// isoDirectoryRecordLength returns the length of a directory record, which must be even
+func isoDirectoryRecordLength(nameLength int) int {
+	return 33 + nameLength + (1 - nameLength%2)
+}
+
+func isoDirectoryRecord(name []byte, extent, size uint32, directory bool) []byte {
+	record := make([]byte, isoDirectoryRecordLength(len(name)))
+	record[0] = byte(len(record))
+	isoPutBothEndian32(record[2:10], extent)
+	isoPutBothEndian32(record[10:18], size)
+	if directory {
+		record[25] = 2
+	}
+	isoPutBothEndian16(record[28:32], 1)
+	record[32] = byte(len(name))
+	copy(record[33:], name)
+	return record
+}
+
+// isoPrimaryFileName returns the name of a file in the primary volume descriptor, in upper case with
+// characters outside of the ISO9660 set replaced by underscores, and the mandatory version suffix
+func isoPrimaryFileName(name string) []byte {
+	base, extension, _ := strings.Cut(strings.ToUpper(name), ".")
+	replace := func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}
+	base, extension = strings.Map(replace, base), strings.Map(replace, extension)
+	if len(base)+len(extension) > 30 {
+		base = base[:30-len(extension)]
+	}
+	return []byte(base + "." + extension + ";1")
+}
+
+// isoJolietString returns a string encoded as big endian UCS-2, as used by Joliet
+func isoJolietString(value string) []byte {
+	encoded := utf16.Encode([]rune(value))
+	result := make([]byte, 2*len(encoded))
+	for i, unit := range encoded {
+		binary.BigEndian.PutUint16(result[2*i:], unit)
+	}
+	return result
+}
+
+func isoPutBothEndian16(field []byte, value uint16) {
+	binary.LittleEndian.PutUint16(field[0:2], value)
+	binary.BigEndian.PutUint16(field[2:4], value)
+}
+
+func isoPutBothEndian32(field []byte, value uint32) {
+	binary.LittleEndian.PutUint32(field[0:4], value)
+	binary.BigEndian.PutUint32(field[4:8], value)
+}
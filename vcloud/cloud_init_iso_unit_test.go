@@ -0,0 +1,141 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// readIsoRootDirectory returns the files of the root directory of the volume descriptor in the given sector
+func readIsoRootDirectory(t *testing.T, image []byte, descriptorSector int, joliet bool) map[string]string {
+	descriptor := image[descriptorSector*isoSectorSize:]
+	rootRecord := descriptor[156:190]
+	rootExtent := binary.LittleEndian.Uint32(rootRecord[2:6])
+	if binary.BigEndian.Uint32(rootRecord[6:10]) != rootExtent {
+		t.Fatalf("root extent is not stored in both byte orders")
+	}
+
+	files := make(map[string]string)
+	directory := image[int(rootExtent)*isoSectorSize : int(rootExtent+1)*isoSectorSize]
+	for offset := 0; offset < len(directory) && directory[offset] != 0; offset += int(directory[offset]) {
+		record := directory[offset : offset+int(directory[offset])]
+		if record[25]&2 != 0 {
+			continue
+		}
+		rawName := record[33 : 33+int(record[32])]
+		name := string(rawName)
+		if joliet {
+			units := make([]uint16, len(rawName)/2)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(rawName[2*i:])
+			}
+			name = string(utf16.Decode(units))
+		}
+		extent := binary.LittleEndian.Uint32(record[2:6])
+		size := binary.LittleEndian.Uint32(record[10:14])
+		files[name] = string(image[int(extent)*isoSectorSize : int(extent)*isoSectorSize+int(size)])
+	}
+	return files
+}
+
+func Test_buildIso9660Image(t *testing.T) {
+	bigFile := strings.Repeat("x", 3*isoSectorSize+1)
+	image, err := buildIso9660Image("cidata", cloudInitSeedFiles("#cloud-config\n", bigFile, "version: 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(image)%isoSectorSize != 0 {
+		t.Fatalf("image size %d is not a multiple of the sector size", len(image))
+	}
+	// Same check as the one done by the media upload
+	if string(image[32769:32774]) != "CD001" || string(image[34817:34822]) != "CD001" {
+		t.Fatalf("missing volume descriptor signatures")
+	}
+	if image[16*isoSectorSize] != 1 || image[17*isoSectorSize] != 2 || image[18*isoSectorSize] != 255 {
+		t.Fatalf("unexpected volume descriptor types")
+	}
+	if volumeId := strings.TrimRight(string(image[16*isoSectorSize+40:16*isoSectorSize+72]), " "); volumeId != "cidata" {
+		t.Fatalf("unexpected volume identifier '%s'", volumeId)
+	}
+	if totalSectors := binary.LittleEndian.Uint32(image[16*isoSectorSize+80:]); int(totalSectors)*isoSectorSize != len(image) {
+		t.Fatalf("volume size of %d sectors does not match image size %d", totalSectors, len(image))
+	}
+	if !bytes.Equal(image[17*isoSectorSize+88:17*isoSectorSize+91], []byte("%/E")) {
+		t.Fatalf("missing Joliet escape sequence")
+	}
+
+	wantJoliet := map[string]string{"meta-data": bigFile, "user-data": "#cloud-config\n", "network-config": "version: 2\n"}
+	if got := readIsoRootDirectory(t, image, 17, true); !reflect.DeepEqual(got, wantJoliet) {
+		t.Fatalf("unexpected Joliet files: %v", got)
+	}
+	wantPrimary := map[string]string{"META_DATA.;1": bigFile, "USER_DATA.;1": "#cloud-config\n", "NETWORK_CONFIG.;1": "version: 2\n"}
+	if got := readIsoRootDirectory(t, image, 16, false); !reflect.DeepEqual(got, wantPrimary) {
+		t.Fatalf("unexpected primary files: %v", got)
+	}
+
+	// Images are reproducible
+	again, err := buildIso9660Image("cidata", cloudInitSeedFiles("#cloud-config\n", bigFile, "version: 2\n"))
+	if err != nil || !bytes.Equal(image, again) {
+		t.Fatalf("building the same image twice gave different results (error: %v)", err)
+	}
+}
+
+func Test_buildIso9660ImageErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		volumeId string
+		files    []isoFile
+	}{
+		{name: "no volume identifier", volumeId: "", files: []isoFile{{name: "a"}}},
+		{name: "long volume identifier", volumeId: strings.Repeat("v", 17), files: []isoFile{{name: "a"}}},
+		{name: "no files", volumeId: "cidata"},
+		{name: "empty name", volumeId: "cidata", files: []isoFile{{name: ""}}},
+		{name: "path", volumeId: "cidata", files: []isoFile{{name: "dir/file"}}},
+		{name: "duplicate", volumeId: "cidata", files: []isoFile{{name: "a"}, {name: "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildIso9660Image(tt.volumeId, tt.files); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func Test_cloudInitSeedFiles(t *testing.T) {
+	if files := cloudInitSeedFiles("user", "meta", ""); len(files) != 2 {
+		t.Errorf("expected no 'network-config' file when it is empty, got %v", files)
+	}
+	if files := cloudInitSeedFiles("user", "meta", "network"); len(files) != 3 {
+		t.Errorf("expected a 'network-config' file, got %v", files)
+	}
+}
+
+func Test_cloudInitContentHash(t *testing.T) {
+	hashes := map[string]bool{}
+	for _, content := range [][3]string{
+		{"a", "", ""},
+		{"", "a", ""},
+		{"", "", "a"},
+		{"a1:", "", ""},
+		{"a", "1:", ""},
+	} {
+		hash := cloudInitContentHash(content[0], content[1], content[2])
+		if len(hash) != 64 {
+			t.Fatalf("unexpected hash %s", hash)
+		}
+		if hashes[hash] {
+			t.Errorf("content %q has the same hash as other content", content)
+		}
+		hashes[hash] = true
+	}
+	if cloudInitContentHash("a", "b", "c") != cloudInitContentHash("a", "b", "c") {
+		t.Errorf("hash is not stable")
+	}
+}
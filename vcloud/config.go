@@ -14,6 +14,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
 	"github.com/vmware/go-vcloud-director/v3/util"
 )
 
@@ -40,6 +41,21 @@ type Config struct {
 	MaxRetryTimeout         int
 	InsecureFlag            bool
 
+	// MaxConcurrentRequests limits the number of API requests sent to VCD at the same time. 0 means no limit
+	MaxConcurrentRequests int
+	// RetryOnStatus contains the HTTP statuses of the responses that are retried with exponential backoff,
+	// within MaxRetryTimeout
+	RetryOnStatus []int
+	// LookupCache enables the cache of Org, VDC and edge gateway lookups
+	LookupCache bool
+	// DistributedLocks enables the leases on vApps, edge gateways and VDC groups, recorded in their metadata, that
+	// prevent different processes from modifying them at the same time
+	DistributedLocks bool
+	// DistributedLockTtl is the number of seconds after which a lease that is not renewed can be broken
+	DistributedLockTtl int
+	// DistributedLockTimeout is the number of seconds spent waiting for a lease held by another process
+	DistributedLockTimeout int
+
 	// UseSamlAdfs specifies if SAML auth is used for authenticating VCD instead of local login.
 	// The following conditions must be met so that authentication SAML authentication works:
 	// * SAML IdP (Identity Provider) is Active Directory Federation Service (ADFS)
@@ -57,6 +73,12 @@ type Config struct {
 	// IgnoredMetadata allows to configure a set of metadata entries that should be ignored by all the
 	// API operations related to metadata.
 	IgnoredMetadata []govcd.IgnoredMetadata
+
+	// DefaultMetadata contains the metadata entries added to every resource whose metadata_entry uses the XML
+	// metadata API. The resources with OpenAPI metadata entries don't receive them
+	DefaultMetadata map[string]types.MetadataValue
+	// DeletionProtection is the value of deletion_protection for the resources that support it and don't set it
+	DeletionProtection bool
 }
 
 type VCDClient struct {
@@ -66,6 +88,17 @@ type VCDClient struct {
 	Vdc             string // name of default VDC
 	MaxRetryTimeout int
 	InsecureFlag    bool
+
+	// throttle coordinates the API calls made through this client
+	throttle *apiThrottle
+	// lookups caches the Orgs, VDCs and edge gateways retrieved during a run. It is nil when disabled
+	lookups *lookupCache
+	// locks takes the leases of the distributed locks. It is nil when disabled
+	locks *distributedLocks
+	// defaultMetadata contains the metadata entries added to every resource that supports metadata_entry
+	defaultMetadata map[string]types.MetadataValue
+	// deletionProtection is the value of deletion_protection for the resources that support it and don't set it
+	deletionProtection bool
 }
 
 // StringMap type is used to simplify reading resource definitions
@@ -154,13 +187,39 @@ func debugPrintf(format string, args ...interface{}) {
 // This is a global mutexKV for all resources
 var vcdMutexKV = newMutexKV()
 
-func (cli *VCDClient) lockVapp(d *schema.ResourceData) {
+// acquireLease takes the distributed lock on the object returned by getStore, when enabled, after the in-process
+// lock on key was taken. A nil store means that the object doesn't exist yet, and there is nothing to lease.
+// On failure, the in-process lock is released
+func (cli *VCDClient) acquireLease(key, object string, getStore func() (leaseStore, error)) error {
+	if cli.locks == nil {
+		return nil
+	}
+	store, err := getStore()
+	if err == nil && store != nil {
+		err = cli.locks.acquire(key, object, store)
+	}
+	if err != nil {
+		vcdMutexKV.kvUnlock(key)
+		return err
+	}
+	return nil
+}
+
+// releaseLease releases the distributed lock taken with key, if any
+func (cli *VCDClient) releaseLease(key string) {
+	if cli.locks != nil {
+		cli.locks.release(key)
+	}
+}
+
+// lockVapp locks a vApp resource, using 'name' field.
+// With distributed locks, the lease is recorded in the vApp metadata, when the vApp exists
+func (cli *VCDClient) lockVapp(d *schema.ResourceData) error {
 	vappName := d.Get("name").(string)
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+	return cli.lockVappByName(cli.getOrgName(d), cli.getVdcName(d), vappName)
 }
 
 func (cli *VCDClient) unLockVapp(d *schema.ResourceData) {
@@ -168,19 +227,46 @@ func (cli *VCDClient) unLockVapp(d *schema.ResourceData) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
+	cli.unLockVappByName(cli.getOrgName(d), cli.getVdcName(d), vappName)
+}
+
+// lockVappByName locks the vApp with the given name. All the vApp locks, of the vApp itself or of the
+// resources that belong to it, go through this function so that they share the same key and lease
+func (cli *VCDClient) lockVappByName(orgName, vdcName, vappName string) error {
+	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", orgName, vdcName, vappName)
+	vcdMutexKV.kvLock(key)
+
+	return cli.acquireLease(key, fmt.Sprintf("vApp %s", vappName), func() (leaseStore, error) {
+		_, vdc, err := cli.GetOrgAndVdc(orgName, vdcName)
+		if err != nil {
+			return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+		}
+		vapp, err := vdc.GetVAppByName(vappName, false)
+		if govcd.ContainsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving vApp %s: %s", vappName, err)
+		}
+		return xmlLeaseStore{client: cli.VCDClient, href: vapp.VApp.HREF, isSystem: cli.Client.IsSysAdmin}, nil
+	})
+}
+
+func (cli *VCDClient) unLockVappByName(orgName, vdcName, vappName string) {
+	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", orgName, vdcName, vappName)
+	cli.releaseLease(key)
 	vcdMutexKV.kvUnlock(key)
 }
 
 // lockEdgeGateway locks an edge gateway resource
 // id field is used as key
-func (cli *VCDClient) lockEdgeGateway(d *schema.ResourceData) {
+// With distributed locks, the lease is recorded in the edge gateway metadata
+func (cli *VCDClient) lockEdgeGateway(d *schema.ResourceData) error {
 	edgeGatewayId := d.Id()
 	if edgeGatewayId == "" {
 		panic("edge gateway ID not found")
 	}
-
-	vcdMutexKV.kvLock(edgeGatewayId)
+	return cli.lockById(edgeGatewayId)
 }
 
 // unlockEdgeGateway unlocks an Edge Gateway resource
@@ -190,58 +276,46 @@ func (cli *VCDClient) unlockEdgeGateway(d *schema.ResourceData) {
 	if edgeGatewayId == "" {
 		panic("edge gateway ID not found")
 	}
-
-	vcdMutexKV.kvUnlock(edgeGatewayId)
+	cli.unlockById(edgeGatewayId)
 }
 
 // lockParentVappWithName locks using provided vappName.
 // Parent means the resource belongs to the vApp being locked
-func (cli *VCDClient) lockParentVappWithName(d *schema.ResourceData, vappName string) {
+func (cli *VCDClient) lockParentVappWithName(d *schema.ResourceData, vappName string) error {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+	return cli.lockVappByName(cli.getOrgName(d), cli.getVdcName(d), vappName)
 }
 
 func (cli *VCDClient) unLockParentVappWithName(d *schema.ResourceData, vappName string) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvUnlock(key)
+	cli.unLockVappByName(cli.getOrgName(d), cli.getVdcName(d), vappName)
 }
 
 // function lockParentVapp locks using vapp_name name existing in resource parameters.
 // Parent means the resource belongs to the vApp being locked
-func (cli *VCDClient) lockParentVapp(d *schema.ResourceData) {
-	vappName := d.Get("vapp_name").(string)
-	if vappName == "" {
-		panic("vApp name not found")
-	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvLock(key)
+func (cli *VCDClient) lockParentVapp(d *schema.ResourceData) error {
+	return cli.lockParentVappWithName(d, d.Get("vapp_name").(string))
 }
 
 func (cli *VCDClient) unLockParentVapp(d *schema.ResourceData) {
-	vappName := d.Get("vapp_name").(string)
-	if vappName == "" {
-		panic("vApp name not found")
-	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", cli.getOrgName(d), cli.getVdcName(d), vappName)
-	vcdMutexKV.kvUnlock(key)
+	cli.unLockParentVappWithName(d, d.Get("vapp_name").(string))
 }
 
-func (cli *VCDClient) lockVappWithName(org, vdc, vappName string) func() {
+func (cli *VCDClient) lockVappWithName(org, vdc, vappName string) (func(), error) {
 	if vappName == "" {
 		panic("vApp name not found")
 	}
-	key := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", org, vdc, vappName)
-	vcdMutexKV.kvLock(key)
+	if err := cli.lockVappByName(org, vdc, vappName); err != nil {
+		return nil, err
+	}
 
 	return func() {
-		vcdMutexKV.kvUnlock(key)
-	}
+		cli.unLockVappByName(org, vdc, vappName)
+	}, nil
 }
 
 // lockParentVm locks using vapp_name and vm_name names existing in resource parameters.
@@ -276,23 +350,42 @@ func (cli *VCDClient) unLockParentVm(d *schema.ResourceData) {
 }
 
 // lockById locks on supplied ID field
-func (cli *VCDClient) lockById(id string) {
+// With distributed locks, the lease is recorded in the metadata of edge gateways and VDC Groups
+func (cli *VCDClient) lockById(id string) error {
 	vcdMutexKV.kvLock(id)
+	store, object := cli.leaseStoreById(id)
+	return cli.acquireLease(id, object, func() (leaseStore, error) {
+		return store, nil
+	})
 }
 
 // unlockById unlocks on supplied ID field
 func (cli *VCDClient) unlockById(id string) {
+	cli.releaseLease(id)
 	vcdMutexKV.kvUnlock(id)
 }
 
+// leaseStoreById returns where the lease of the entity with the given ID is recorded, and the entity description
+// used in messages. Only edge gateways and VDC Groups are leased: the store is nil for other entities
+func (cli *VCDClient) leaseStoreById(id string) (leaseStore, string) {
+	switch {
+	case govcd.OwnerIsVdcGroup(id):
+		return openApiLeaseStore{client: &cli.Client, entityId: id}, fmt.Sprintf("VDC Group %s", id)
+	case strings.HasPrefix(id, "urn:vcloud:gateway:"):
+		href := fmt.Sprintf("%s/admin/edgeGateway/%s", cli.Client.VCDHREF.String(), extractUuid(id))
+		return xmlLeaseStore{client: cli.VCDClient, href: href, isSystem: cli.Client.IsSysAdmin}, fmt.Sprintf("edge gateway %s", id)
+	}
+	return nil, id
+}
+
 // lockParentVdcGroup locks on VDC Group ID using 'vdc_group_id' field
-func (cli *VCDClient) lockParentVdcGroup(d *schema.ResourceData) {
+// With distributed locks, the lease is recorded in the VDC Group metadata
+func (cli *VCDClient) lockParentVdcGroup(d *schema.ResourceData) error {
 	vdcGroupId := d.Get("vdc_group_id").(string)
 	if vdcGroupId == "" {
 		panic("'vdc_group_id' is empty")
 	}
-
-	vcdMutexKV.kvLock(vdcGroupId)
+	return cli.lockById(vdcGroupId)
 }
 
 // unlockParentVdcGroup unlocks on VDC Group ID using 'vdc_group_id' field
@@ -301,8 +394,7 @@ func (cli *VCDClient) unlockParentVdcGroup(d *schema.ResourceData) {
 	if vdcGroupId == "" {
 		panic("'vdc_group_id' is empty")
 	}
-
-	vcdMutexKV.kvUnlock(vdcGroupId)
+	cli.unlockById(vdcGroupId)
 }
 
 // lockParentExternalNetwork locks on External Network using 'external_network_id' field
@@ -326,12 +418,13 @@ func (cli *VCDClient) unlockParentExternalNetwork(d *schema.ResourceData) {
 }
 
 // lockIfOwnerIsVdcGroup locks VDC Group based on `owner_id` field (if it is a VDC Group)
-func (cli *VCDClient) lockIfOwnerIsVdcGroup(d *schema.ResourceData) {
+func (cli *VCDClient) lockIfOwnerIsVdcGroup(d *schema.ResourceData) error {
 	vdcGroupId := d.Get("owner_id")
 	vdcGroupIdValue := vdcGroupId.(string)
 	if govcd.OwnerIsVdcGroup(vdcGroupIdValue) {
-		vcdMutexKV.kvLock(vdcGroupIdValue)
+		return cli.lockById(vdcGroupIdValue)
 	}
+	return nil
 }
 
 // unLockIfOwnerIsVdcGroup unlocks VDC Group based on `owner_id` field (if it is a VDC Group)
@@ -339,13 +432,13 @@ func (cli *VCDClient) unLockIfOwnerIsVdcGroup(d *schema.ResourceData) {
 	vdcGroupId := d.Get("owner_id")
 	vdcGroupIdValue := vdcGroupId.(string)
 	if govcd.OwnerIsVdcGroup(vdcGroupIdValue) {
-		vcdMutexKV.kvUnlock(vdcGroupIdValue)
+		cli.unlockById(vdcGroupIdValue)
 	}
 }
 
 // function lockParentEdgeGtw locks using edge_gateway or edge_gateway_id name existing in resource parameters.
 // Edge Gateway is used as a lock key. If only `name` is present in resource - it will find the Edge Gateway itself
-func (cli *VCDClient) lockParentEdgeGtw(d *schema.ResourceData) {
+func (cli *VCDClient) lockParentEdgeGtw(d *schema.ResourceData) error {
 	var edgeGtwIdValue string
 	var edgeGtwNameValue string
 
@@ -378,7 +471,7 @@ func (cli *VCDClient) lockParentEdgeGtw(d *schema.ResourceData) {
 		panic("edge gateway ID not found")
 	}
 
-	vcdMutexKV.kvLock(edgeGtwIdValue)
+	return cli.lockById(edgeGtwIdValue)
 }
 
 func (cli *VCDClient) unLockParentEdgeGtw(d *schema.ResourceData) {
@@ -414,7 +507,7 @@ func (cli *VCDClient) unLockParentEdgeGtw(d *schema.ResourceData) {
 		panic("edge gateway ID not found")
 	}
 
-	vcdMutexKV.kvUnlock(edgeGtwIdValue)
+	cli.unlockById(edgeGtwIdValue)
 }
 
 // lockParentVdcGroupOrEdgeGateway handles lock of parent Edge Gateway or parent VDC group, depending
@@ -433,12 +526,16 @@ func (cli *VCDClient) lockParentVdcGroupOrEdgeGateway(d *schema.ResourceData) (f
 	// To find out parent lock object, Edge Gateway must be looked up and its OwnerRef must be checked
 	// Note. It is not safe to do multiple locks in the same resource as it can result in a deadlock
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		cli.lockById(parentEdgeGatewayOwnerId)
+		if err := cli.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return nil, err
+		}
 		return func() {
 			cli.unlockById(parentEdgeGatewayOwnerId)
 		}, nil
 	} else {
-		cli.lockParentEdgeGtw(d)
+		if err := cli.lockParentEdgeGtw(d); err != nil {
+			return nil, err
+		}
 		return func() {
 			cli.unLockParentEdgeGtw(d)
 		}, nil
@@ -488,14 +585,20 @@ func (cli *VCDClient) GetOrgAndVdc(orgName, vdcName string) (org *govcd.Org, vdc
 	if vdcName == "" {
 		return nil, nil, fmt.Errorf("empty VDC name provided")
 	}
-	org, err = cli.VCDClient.GetOrgByName(orgName)
+	org, err = cachedLookup(cli.lookups, lookupCacheKey("org", orgName), cloneOrg, func() (*govcd.Org, error) {
+		return cli.VCDClient.GetOrgByName(orgName)
+	}, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error retrieving Org %s: %s", orgName, err)
 	}
 	if org.Org.Name == "" || org.Org.HREF == "" || org.Org.ID == "" {
 		return nil, nil, fmt.Errorf("empty Org %s found ", orgName)
 	}
-	vdc, err = org.GetVDCByName(vdcName, false)
+	vdc, err = cachedLookup(cli.lookups, lookupCacheKey("vdc", orgName, vdcName), cloneVdc, func() (*govcd.Vdc, error) {
+		return org.GetVDCByName(vdcName, false)
+	}, func(vdc *govcd.Vdc) []string {
+		return []string{lookupCacheKey("vdcId", vdc.Vdc.ID)}
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("error retrieving VDC %s: %s", vdcName, err)
 	}
@@ -517,7 +620,11 @@ func (cli *VCDClient) GetAdminOrg(orgName string) (org *govcd.AdminOrg, err erro
 		return nil, fmt.Errorf("empty Org name provided")
 	}
 
-	org, err = cli.VCDClient.GetAdminOrgByName(orgName)
+	org, err = cachedLookup(cli.lookups, lookupCacheKey("adminOrg", orgName), cloneAdminOrg, func() (*govcd.AdminOrg, error) {
+		return cli.VCDClient.GetAdminOrgByName(orgName)
+	}, func(org *govcd.AdminOrg) []string {
+		return []string{lookupCacheKey("adminOrgId", org.AdminOrg.ID)}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Org %s: %s", orgName, err)
 	}
@@ -527,6 +634,24 @@ func (cli *VCDClient) GetAdminOrg(orgName string) (org *govcd.AdminOrg, err erro
 	return org, err
 }
 
+// GetAdminOrgById finds an Org by ID, sharing the lookup cache with GetAdminOrg
+func (cli *VCDClient) GetAdminOrgById(orgId string) (*govcd.AdminOrg, error) {
+	return cachedLookup(cli.lookups, lookupCacheKey("adminOrgId", orgId), cloneAdminOrg, func() (*govcd.AdminOrg, error) {
+		return cli.VCDClient.GetAdminOrgById(orgId)
+	}, func(org *govcd.AdminOrg) []string {
+		return []string{lookupCacheKey("adminOrg", org.AdminOrg.Name)}
+	})
+}
+
+// GetVdcById finds a VDC of the Org by ID, sharing the lookup cache with GetOrgAndVdc
+func (cli *VCDClient) GetVdcById(org *govcd.Org, vdcId string) (*govcd.Vdc, error) {
+	return cachedLookup(cli.lookups, lookupCacheKey("vdcId", vdcId), cloneVdc, func() (*govcd.Vdc, error) {
+		return org.GetVDCById(vdcId, false)
+	}, func(vdc *govcd.Vdc) []string {
+		return []string{lookupCacheKey("vdc", org.Org.Name, vdc.Vdc.Name)}
+	})
+}
+
 // GetOrg finds org using the names provided in the args.
 // If the name is empty, it will use the default
 // org name from the provider.
@@ -539,7 +664,9 @@ func (cli *VCDClient) GetOrg(orgName string) (org *govcd.Org, err error) {
 		return nil, fmt.Errorf("empty Org name provided")
 	}
 
-	org, err = cli.VCDClient.GetOrgByName(orgName)
+	org, err = cachedLookup(cli.lookups, lookupCacheKey("org", orgName), cloneOrg, func() (*govcd.Org, error) {
+		return cli.VCDClient.GetOrgByName(orgName)
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Org %s: %s", orgName, err)
 	}
@@ -578,7 +705,9 @@ func (cli *VCDClient) GetEdgeGateway(orgName, vdcName, edgeGwName string) (eg *g
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Org and VDC: %s", err)
 	}
-	eg, err = vdc.GetEdgeGatewayByName(edgeGwName, true)
+	eg, err = cachedLookup(cli.lookups, lookupCacheKey("edgeGateway", vdc.Vdc.ID, edgeGwName), cloneEdgeGateway, func() (*govcd.EdgeGateway, error) {
+		return vdc.GetEdgeGatewayByName(edgeGwName, true)
+	}, nil)
 
 	if err != nil {
 		if os.Getenv("GOVCD_DEBUG") != "" {
@@ -595,11 +724,15 @@ func (cli *VCDClient) GetNsxtEdgeGateway(orgName, vdcName, edgeGwName string) (e
 	if edgeGwName == "" {
 		return nil, fmt.Errorf("empty NSX-T Edge Gateway name provided")
 	}
-	_, vdc, err := cli.GetOrgAndVdc(orgName, vdcName)
+	org, vdc, err := cli.GetOrgAndVdc(orgName, vdcName)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Org and VDC: %s", err)
 	}
-	eg, err = vdc.GetNsxtEdgeGatewayByName(edgeGwName)
+	eg, err = cachedLookup(cli.lookups, lookupCacheKey("nsxtEdgeGateway", vdc.Vdc.ID, edgeGwName), cloneNsxtEdgeGateway, func() (*govcd.NsxtEdgeGateway, error) {
+		return vdc.GetNsxtEdgeGatewayByName(edgeGwName)
+	}, func(eg *govcd.NsxtEdgeGateway) []string {
+		return []string{lookupCacheKey("nsxtEdgeGatewayId", org.Org.ID, eg.EdgeGateway.ID)}
+	})
 
 	if err != nil {
 		if os.Getenv("GOVCD_DEBUG") != "" {
@@ -620,7 +753,9 @@ func (cli *VCDClient) GetNsxtEdgeGatewayById(orgName, edgeGwId string) (eg *govc
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving Org: %s", err)
 	}
-	eg, err = org.GetNsxtEdgeGatewayById(edgeGwId)
+	eg, err = cachedLookup(cli.lookups, lookupCacheKey("nsxtEdgeGatewayId", org.Org.ID, edgeGwId), cloneNsxtEdgeGateway, func() (*govcd.NsxtEdgeGateway, error) {
+		return org.GetNsxtEdgeGatewayById(edgeGwId)
+	}, nil)
 
 	if err != nil {
 		if os.Getenv("GOVCD_DEBUG") != "" {
@@ -722,7 +857,10 @@ func (c *Config) Client() (*VCDClient, error) {
 		c.ServiceAccountTokenFile + "#" +
 		c.SysOrg + "#" +
 		c.Vdc + "#" +
-		c.Href
+		c.Href + "#" +
+		fmt.Sprintf("%d#%v#%t", c.MaxConcurrentRequests, c.RetryOnStatus, c.LookupCache) + "#" +
+		fmt.Sprintf("%t#%d#%d#%t", c.DistributedLocks, c.DistributedLockTtl, c.DistributedLockTimeout, c.DeletionProtection) + "#" +
+		metadataValuesToString(c.DefaultMetadata)
 	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte(rawData)))
 
 	// The cached connection is served only if the variable VCD_CACHE is set
@@ -741,6 +879,10 @@ func (c *Config) Client() (*VCDClient, error) {
 			delete(cachedVCDClients.conMap, checksum)
 			cachedVCDClients.Unlock()
 		} else {
+			// A cached connection starts a new run, which must not see the lookups of the previous one
+			if client.connection.lookups != nil {
+				client.connection.lookups.invalidate()
+			}
 			return client.connection, nil
 		}
 	}
@@ -759,11 +901,26 @@ func (c *Config) Client() (*VCDClient, error) {
 			govcd.WithHttpUserAgent(userAgent),
 			govcd.WithIgnoredMetadata(c.IgnoredMetadata),
 		),
-		SysOrg:          c.SysOrg,
-		Org:             c.Org,
-		Vdc:             c.Vdc,
-		MaxRetryTimeout: c.MaxRetryTimeout,
-		InsecureFlag:    c.InsecureFlag}
+		SysOrg:             c.SysOrg,
+		Org:                c.Org,
+		Vdc:                c.Vdc,
+		MaxRetryTimeout:    c.MaxRetryTimeout,
+		InsecureFlag:       c.InsecureFlag,
+		defaultMetadata:    c.DefaultMetadata,
+		deletionProtection: c.DeletionProtection}
+
+	// All the API calls, including the authentication, go through the throttle
+	vcdClient.throttle = newApiThrottle(vcdClient.Client.Http.Transport, c.MaxConcurrentRequests, c.RetryOnStatus,
+		time.Duration(c.MaxRetryTimeout)*time.Second)
+	vcdClient.Client.Http.Transport = vcdClient.throttle
+	if c.LookupCache {
+		vcdClient.lookups = newLookupCache()
+		vcdClient.Client.Http.Transport = &lookupCacheInvalidator{transport: vcdClient.throttle, cache: vcdClient.lookups}
+	}
+	if c.DistributedLocks {
+		vcdClient.locks = newDistributedLocks(time.Duration(c.DistributedLockTtl)*time.Second,
+			time.Duration(c.DistributedLockTimeout)*time.Second)
+	}
 
 	err = ProviderAuthenticate(vcdClient.VCDClient, c.User, c.Password, c.Token, c.SysOrg, c.ApiToken, c.ApiTokenFile, c.ServiceAccountTokenFile)
 	if err != nil {
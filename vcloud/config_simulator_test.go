@@ -0,0 +1,39 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"testing"
+)
+
+// TestSimulatorOrgAndVdcLookup checks the lookup of the org and VDC of a resource, with the provider defaults when they are not set
+func TestSimulatorOrgAndVdcLookup(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+
+	// The org and VDC of the provider configuration are used when a resource doesn't set them
+	org, vdc, err := vcdClient.GetOrgAndVdc("", "")
+	if err != nil {
+		t.Fatalf("error retrieving the default org and VDC: %s", err)
+	}
+	if org.Org.Name != simulatorOrg || vdc.Vdc.Name != simulatorVdc {
+		t.Fatalf("expected org %s and VDC %s, got %s and %s", simulatorOrg, simulatorVdc, org.Org.Name, vdc.Vdc.Name)
+	}
+	if vdc.IsNsxt() {
+		t.Fatalf("expected VDC %s to be backed by NSX-V", simulatorVdc)
+	}
+
+	_, vdc, err = vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc)
+	if err != nil {
+		t.Fatalf("error retrieving VDC %s: %s", simulatorNsxtVdc, err)
+	}
+	if !vdc.IsNsxt() {
+		t.Fatalf("expected VDC %s to be backed by NSX-T", simulatorNsxtVdc)
+	}
+
+	if _, _, err := vcdClient.GetOrgAndVdc(simulatorOrg, "missing-vdc"); err == nil {
+		t.Fatal("expected an error when retrieving a VDC that does not exist")
+	}
+	if _, _, err := vcdClient.GetOrgAndVdc("missing-org", simulatorVdc); err == nil {
+		t.Fatal("expected an error when retrieving an org that does not exist")
+	}
+}
@@ -1,4 +1,4 @@
-//go:build api || functional || catalog || vapp || network || extnetwork || org || query || vm || vdc || gateway || disk || binary || lb || lbServiceMonitor || lbServerPool || lbAppProfile || lbAppRule || lbVirtualServer || access_control || user || standaloneVm || search || auth || nsxt || role || alb || certificate || vdcGroup || ldap || rde || uiPlugin || providerVdc || cse || slz || multisite || ALL
+//go:build api || functional || catalog || vapp || network || extnetwork || org || query || vm || vdc || gateway || disk || binary || lb || lbServiceMonitor || lbServerPool || lbAppProfile || lbAppRule || lbVirtualServer || access_control || user || standaloneVm || search || auth || nsxt || role || alb || certificate || vdcGroup || ldap || rde || uiPlugin || providerVdc || cse || slz || multisite || simulator || ALL
 
 package vcloud
 
@@ -29,6 +29,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/viettelidc-provider/terraform-provider-vcloud/v3/internal/vcdsim"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/util"
 )
@@ -64,6 +65,7 @@ func init() {
 	setStringFlag(&vcdSkipPattern, "vcd-skip-pattern", "VCD_SKIP_PATTERN", "Skip tests that match the pattern (implies vcd-pre-post-checks")
 	setBoolFlag(&skipLeftoversRemoval, "vcd-skip-leftovers-removal", "VCD_SKIP_LEFTOVERS_REMOVAL", "Do not attempt removal of leftovers at the end of the test suite")
 	setBoolFlag(&silentLeftoversRemoval, "vcd-silent-leftovers-removal", "VCD_SILENT_LEFTOVERS_REMOVAL", "Omit details during removal of leftovers")
+	setBoolFlag(&vcdSimulator, "vcd-simulator", "VCD_SIMULATOR", "Run the tests against a local VCD simulator instead of a live VCD")
 	setStringFlag(&testListFileName, "vcd-partition-tests-file", "VCD_PARTITION_TESTS_FILE", "Name of the file containing the tests to run in the current partition node")
 	setIntFlag(&numberOfPartitions, "vcd-partitions", "VCD_PARTITIONS", "")
 	setIntFlag(&partitionNode, "vcd-partition-node", "VCD_PARTITION_NODE", "")
@@ -335,6 +337,12 @@ var (
 
 	// silentLeftoversRemoval omits details while removing leftovers
 	silentLeftoversRemoval = false
+
+	// vcdSimulator runs the tests against a local VCD simulator instead of a live VCD
+	vcdSimulator = false
+
+	// testSimulator is the simulator started by TestMain when vcdSimulator is set
+	testSimulator *vcdsim.Simulator
 )
 
 const (
@@ -831,6 +839,43 @@ func getConfigStruct(config string) TestConfig {
 	return configStruct
 }
 
+// Names of the entities that the simulator started by TestMain contains
+const (
+	simulatorOrg         = "sim-org"
+	simulatorVdc         = "sim-vdc"
+	simulatorNsxtVdc     = "sim-nsxt-vdc"
+	simulatorEdgeGateway = "sim-nsxt-edge"
+	simulatorCatalog     = "sim-catalog"
+)
+
+// startTestSimulator starts a VCD simulator with an organization, an NSX-V and an NSX-T VDC, an NSX-T edge
+// gateway and a catalog, and returns the configuration that points the tests at it.
+// Only the tests that use those entities, and the operations that the simulator implements, can run in
+// this mode.
+func startTestSimulator() (*vcdsim.Simulator, TestConfig) {
+	sim := vcdsim.New()
+	sim.AddOrg(simulatorOrg)
+	sim.AddVdc(simulatorOrg, simulatorVdc, false)
+	sim.AddVdc(simulatorOrg, simulatorNsxtVdc, true)
+	sim.AddNsxtEdgeGateway(simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway)
+	sim.AddCatalog(simulatorOrg, simulatorCatalog)
+
+	var config TestConfig
+	config.Provider.Url = sim.URL()
+	config.Provider.User = vcdsim.AdminUser
+	config.Provider.Password = vcdsim.AdminPassword
+	config.Provider.SysOrg = vcdsim.SystemOrg
+	config.Provider.AllowInsecure = true
+	config.Provider.TerraformAcceptanceTests = true
+	config.Provider.MaxRetryTimeout = 10
+	config.VCD.Org = simulatorOrg
+	config.VCD.Vdc = simulatorVdc
+	config.VCD.Catalog.Name = simulatorCatalog
+	config.Nsxt.Vdc = simulatorNsxtVdc
+	config.Nsxt.EdgeGateway = simulatorEdgeGateway
+	return sim, config
+}
+
 // setTestEnv enables environment variables that are also used in non-test code
 func setTestEnv() {
 	if enableDebug {
@@ -903,7 +948,13 @@ func TestMain(m *testing.M) {
 	// If VCD_SHORT_TEST is defined, it means that "make test" is called,
 	// and we won't really run any tests involving vcd connections.
 	configFile := getConfigFileName()
-	if configFile != "" {
+	if vcdSimulator {
+		testSimulator, testConfig = startTestSimulator()
+		_ = os.Setenv("TF_ACC", "1")
+		_ = os.Setenv("VCD_MAX_RETRY_TIMEOUT", strconv.Itoa(testConfig.Provider.MaxRetryTimeout))
+		// There are no leftovers to remove when the simulator goes away with the suite
+		skipLeftoversRemoval = true
+	} else if configFile != "" {
 		testConfig = getConfigStruct(configFile)
 	}
 	if vcdRemoveTestList {
@@ -918,7 +969,7 @@ func TestMain(m *testing.M) {
 	}
 	if !vcdShortTest {
 
-		if configFile == "" {
+		if configFile == "" && !vcdSimulator {
 			fmt.Println("No configuration file found")
 			os.Exit(1)
 		}
@@ -964,6 +1015,12 @@ func TestMain(m *testing.M) {
 		fmt.Printf("Pass: %5d - Skip: %5d - Fail: %5d\n", vcdPassCount, vcdSkipCount, vcdFailCount)
 	}
 
+	if testSimulator != nil {
+		if unhandled := testSimulator.Unhandled(); len(unhandled) > 0 {
+			fmt.Printf("Requests not handled by the simulator:\n  %s\n", strings.Join(unhandled, "\n  "))
+		}
+		testSimulator.Close()
+	}
 	if skipLeftoversRemoval || vcdShortTest {
 		os.Exit(exitCode)
 	}
@@ -0,0 +1,380 @@
+package vcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// auditEventsMaxPageSize is the largest page of audit events that VCD returns
+const auditEventsMaxPageSize = 128
+
+// auditEvent is an event of the VCD audit trail
+type auditEvent struct {
+	EventID              string                  `json:"eventId"`
+	Description          string                  `json:"description"`
+	OperatingOrg         *types.OpenApiReference `json:"operatingOrg"`
+	User                 *types.OpenApiReference `json:"user"`
+	EventEntity          *types.OpenApiReference `json:"eventEntity"`
+	TaskID               *string                 `json:"taskId"`
+	EventType            string                  `json:"eventType"`
+	ServiceNamespace     string                  `json:"serviceNamespace"`
+	EventStatus          string                  `json:"eventStatus"`
+	Timestamp            string                  `json:"timestamp"`
+	External             bool                    `json:"external"`
+	AdditionalProperties map[string]interface{}  `json:"additionalProperties"`
+}
+
+func datasourceVcdAuditEvents() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdAuditEventsRead,
+		Schema: map[string]*schema.Schema{
+			"org_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the organization in which the events happened. All the organizations visible to the user when not set",
+			},
+			"entity_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID (URN) of the entity affected by the events, such as a VM or an edge gateway",
+			},
+			"entity_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the entity affected by the events. '*' can be used as wildcard",
+			},
+			"event_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Type of the events, such as 'com/vmware/cloud/event/vm/modify'. '*' can be used as wildcard",
+			},
+			"user_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the user who caused the events. '*' can be used as wildcard",
+			},
+			"exclude_user_names": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of users whose events are not returned, such as the user running Terraform",
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"SUCCESS", "FAILURE"}, false),
+				Description:  "Status of the events. One of 'SUCCESS', 'FAILURE'",
+			},
+			"since": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "Only events that happened at this time or later, in RFC 3339 format",
+			},
+			"until": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "Only events that happened before this time, in RFC 3339 format",
+			},
+			"sort_ascending": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Return the oldest events first. By default, the newest events are returned first",
+			},
+			"page_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      auditEventsMaxPageSize,
+				ValidateFunc: validation.IntBetween(1, auditEventsMaxPageSize),
+				Description:  "Number of events retrieved with each request to VCD",
+			},
+			"max_events": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of events to return. 0 returns all the matching events",
+			},
+			"total_events": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of events matching the filters, which can be more than the events returned",
+			},
+			"truncated": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if more events match the filters than the ones returned, because of 'max_events'",
+			},
+			"events": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Events matching the filters",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event ID",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event type",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event status",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Time of the event",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event description",
+						},
+						"org_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the organization in which the event happened",
+						},
+						"org_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the organization in which the event happened",
+						},
+						"user_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user who caused the event",
+						},
+						"user_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the user who caused the event",
+						},
+						"entity_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the entity affected by the event",
+						},
+						"entity_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the entity affected by the event",
+						},
+						"entity_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the entity affected by the event, taken from its ID, such as 'vm' or 'gateway'",
+						},
+						"task_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the task related to the event, if any",
+						},
+						"service_namespace": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Namespace of the service that generated the event",
+						},
+						"external": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the event was generated by an external service",
+						},
+						"additional_properties": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Additional details of the event, such as the client IP address",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdAuditEventsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	filter, err := auditEventsFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	queryParameters := url.Values{}
+	if filter != "" {
+		queryParameters.Set("filter", filter)
+	}
+	if d.Get("sort_ascending").(bool) {
+		queryParameters.Set("sortAsc", "timestamp")
+	} else {
+		queryParameters.Set("sortDesc", "timestamp")
+	}
+
+	events, total, err := getAuditEvents(&vcdClient.Client, queryParameters, d.Get("page_size").(int), d.Get("max_events").(int))
+	if err != nil {
+		return diag.Errorf("error retrieving audit events: %s", err)
+	}
+
+	eventList := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		eventList = append(eventList, flattenAuditEvent(event))
+	}
+	err = d.Set("events", eventList)
+	if err != nil {
+		return diag.Errorf("error setting events: %s", err)
+	}
+	dSet(d, "total_events", total)
+	dSet(d, "truncated", total > len(events))
+
+	if filter == "" {
+		filter = "all"
+	}
+	d.SetId(filter)
+	return nil
+}
+
+// auditEventsFilter returns the FIQL filter for the audit trail built from the data source arguments
+func auditEventsFilter(d *schema.ResourceData) (string, error) {
+	var conditions []string
+	for field, argument := range map[string]string{
+		"operatingOrg.name": "org_name",
+		"eventEntity.id":    "entity_id",
+		"eventEntity.name":  "entity_name",
+		"eventType":         "event_type",
+		"user.name":         "user_name",
+		"eventStatus":       "status",
+	} {
+		value := d.Get(argument).(string)
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, ";,") {
+			return "", fmt.Errorf("'%s' cannot contain ';' or ','", argument)
+		}
+		conditions = append(conditions, field+"=="+value)
+	}
+	for _, userName := range d.Get("exclude_user_names").(*schema.Set).List() {
+		if strings.ContainsAny(userName.(string), ";,") {
+			return "", fmt.Errorf("'exclude_user_names' cannot contain ';' or ','")
+		}
+		conditions = append(conditions, "user.name!="+userName.(string))
+	}
+	for argument, operator := range map[string]string{"since": "=ge=", "until": "=lt="} {
+		value := d.Get(argument).(string)
+		if value == "" {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", fmt.Errorf("error parsing '%s': %s", argument, err)
+		}
+		conditions = append(conditions, "timestamp"+operator+timestamp.UTC().Format(types.FiqlQueryTimestampFormat))
+	}
+	// The order of map iterations is random, while the filter is used as ID
+	sort.Strings(conditions)
+	return strings.Join(conditions, ";"), nil
+}
+
+// getAuditEvents retrieves the events of the audit trail one page at a time, until maxEvents events are
+// retrieved (all of them when maxEvents is 0). Returns the events and the total number of matching events
+func getAuditEvents(client *govcd.Client, queryParameters url.Values, pageSize, maxEvents int) ([]*auditEvent, int, error) {
+	urlRef, err := client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0 + types.OpenApiEndpointAuditTrail)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var events []*auditEvent
+	total := 0
+	for page := 1; ; page++ {
+		parameters := url.Values{}
+		for key, values := range queryParameters {
+			parameters[key] = values
+		}
+		parameters.Set("page", strconv.Itoa(page))
+		parameters.Set("pageSize", strconv.Itoa(pageSize))
+
+		pages := &types.OpenApiPages{}
+		err = client.OpenApiGetItem(client.APIVersion, urlRef, parameters, pages, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		var pageEvents []*auditEvent
+		if len(pages.Values) > 0 {
+			err = json.Unmarshal(pages.Values, &pageEvents)
+			if err != nil {
+				return nil, 0, fmt.Errorf("error decoding audit events: %s", err)
+			}
+		}
+		total = pages.ResultTotal
+		events = append(events, pageEvents...)
+		if maxEvents > 0 && len(events) >= maxEvents {
+			return events[:maxEvents], total, nil
+		}
+		if len(pageEvents) == 0 || page >= pages.PageCount {
+			break
+		}
+	}
+	if total < len(events) {
+		total = len(events)
+	}
+	return events, total, nil
+}
+
+func flattenAuditEvent(event *auditEvent) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":                event.EventID,
+		"type":              event.EventType,
+		"status":            event.EventStatus,
+		"timestamp":         event.Timestamp,
+		"description":       event.Description,
+		"service_namespace": event.ServiceNamespace,
+		"external":          event.External,
+	}
+	if event.OperatingOrg != nil {
+		result["org_name"] = event.OperatingOrg.Name
+		result["org_id"] = event.OperatingOrg.ID
+	}
+	if event.User != nil {
+		result["user_name"] = event.User.Name
+		result["user_id"] = event.User.ID
+	}
+	if event.EventEntity != nil {
+		result["entity_name"] = event.EventEntity.Name
+		result["entity_id"] = event.EventEntity.ID
+		// IDs are URNs such as urn:vcloud:vm:<uuid>
+		if parts := strings.Split(event.EventEntity.ID, ":"); len(parts) == 4 {
+			result["entity_type"] = parts[2]
+		}
+	}
+	if event.TaskID != nil {
+		result["task_id"] = *event.TaskID
+	}
+	properties := make(map[string]interface{})
+	for key, value := range event.AdditionalProperties {
+		if value != nil {
+			properties[key] = fmt.Sprint(value)
+		}
+	}
+	result["additional_properties"] = properties
+	return result
+}
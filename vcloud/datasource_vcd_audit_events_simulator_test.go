@@ -0,0 +1,136 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/viettelidc-provider/terraform-provider-vcloud/v3/internal/vcdsim"
+)
+
+// TestSimulatorAuditEvents checks the filters, sort order and pagination of the audit events data source
+func TestSimulatorAuditEvents(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	gatewayId := "urn:vcloud:gateway:11111111-2222-3333-4444-555555555555"
+	vmId := "urn:vcloud:vm:66666666-7777-8888-9999-000000000000"
+	events := []vcdsim.AuditEvent{
+		{EventType: "com/vmware/cloud/event/gateway/modify", Status: "SUCCESS", UserName: "terraform", EntityId: gatewayId, EntityName: "gw", Description: "e1"},
+		{EventType: "com/vmware/cloud/event/gateway/modify", Status: "SUCCESS", UserName: "alice", EntityId: gatewayId, EntityName: "gw", Description: "e2"},
+		{EventType: "com/vmware/cloud/event/vm/modify", Status: "FAILURE", UserName: "bob", EntityId: vmId, EntityName: "web-1", Description: "e3"},
+		{EventType: "com/vmware/cloud/event/vm/modify", Status: "SUCCESS", UserName: "terraform", EntityId: vmId, EntityName: "web-1", Description: "e4"},
+		{EventType: "com/vmware/cloud/event/vm/create", Status: "SUCCESS", UserName: "alice", EntityId: vmId, EntityName: "web-1", Description: "e5"},
+	}
+	for i, event := range events {
+		event.OrgName = simulatorOrg
+		event.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		sim.AddAuditEvent(event)
+	}
+	sim.AddAuditEvent(vcdsim.AuditEvent{EventType: "com/vmware/cloud/event/vm/modify", Status: "SUCCESS",
+		OrgName: "other-org", UserName: "carol", EntityName: "other", Timestamp: start, Description: "e6"})
+
+	readEvents := func(t *testing.T, values map[string]interface{}) *schema.ResourceData {
+		resource := datasourceVcdAuditEvents()
+		d := simulatorResourceData(t, resource, values)
+		if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error reading audit events: %v", diags)
+		}
+		return d
+	}
+	descriptions := func(d *schema.ResourceData) string {
+		var result []string
+		for _, event := range d.Get("events").([]interface{}) {
+			result = append(result, event.(map[string]interface{})["description"].(string))
+		}
+		return strings.Join(result, ",")
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   string
+	}{
+		{"All", map[string]interface{}{}, "e5,e4,e3,e2,e1,e6"},
+		{"Ascending", map[string]interface{}{"org_name": simulatorOrg, "sort_ascending": true}, "e1,e2,e3,e4,e5"},
+		{"Entity", map[string]interface{}{"entity_id": gatewayId}, "e2,e1"},
+		{"EventTypeWildcard", map[string]interface{}{"org_name": simulatorOrg, "event_type": "*/modify"}, "e4,e3,e2,e1"},
+		{"User", map[string]interface{}{"user_name": "alice"}, "e5,e2"},
+		{"ExcludedUsers", map[string]interface{}{
+			"org_name":           simulatorOrg,
+			"exclude_user_names": []interface{}{"terraform", "bob"},
+		}, "e5,e2"},
+		{"Status", map[string]interface{}{"status": "FAILURE"}, "e3"},
+		{"TimeWindow", map[string]interface{}{
+			"org_name": simulatorOrg,
+			"since":    "2024-05-01T11:00:00Z",
+			"until":    "2024-05-01T13:00:00Z",
+		}, "e3,e2"},
+		{"TimeWindowWithOffset", map[string]interface{}{
+			"org_name": simulatorOrg,
+			"since":    "2024-05-01T15:00:00+02:00",
+		}, "e5,e4"},
+		{"NoMatches", map[string]interface{}{"user_name": "dave"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := descriptions(readEvents(t, tt.values)); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Pagination", func(t *testing.T) {
+		d := readEvents(t, map[string]interface{}{"org_name": simulatorOrg, "page_size": 2, "max_events": 0})
+		if got := descriptions(d); got != "e5,e4,e3,e2,e1" {
+			t.Errorf("expected all the events over three pages, got %q", got)
+		}
+		if d.Get("total_events").(int) != 5 || d.Get("truncated").(bool) {
+			t.Errorf("expected 5 events, not truncated, got %d, %t", d.Get("total_events"), d.Get("truncated"))
+		}
+
+		d = readEvents(t, map[string]interface{}{"org_name": simulatorOrg, "page_size": 2, "max_events": 3})
+		if got := descriptions(d); got != "e5,e4,e3" {
+			t.Errorf("expected the newest 3 events, got %q", got)
+		}
+		if d.Get("total_events").(int) != 5 || !d.Get("truncated").(bool) {
+			t.Errorf("expected 5 events, truncated, got %d, %t", d.Get("total_events"), d.Get("truncated"))
+		}
+	})
+
+	t.Run("Attributes", func(t *testing.T) {
+		d := readEvents(t, map[string]interface{}{"entity_id": gatewayId, "user_name": "alice"})
+		list := d.Get("events").([]interface{})
+		if len(list) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(list))
+		}
+		event := list[0].(map[string]interface{})
+		expected := map[string]interface{}{
+			"type":        "com/vmware/cloud/event/gateway/modify",
+			"status":      "SUCCESS",
+			"timestamp":   "2024-05-01T11:00:00.000Z",
+			"org_name":    simulatorOrg,
+			"user_name":   "alice",
+			"entity_name": "gw",
+			"entity_id":   gatewayId,
+			"entity_type": "gateway",
+		}
+		for key, value := range expected {
+			if event[key] != value {
+				t.Errorf("expected %s %v, got %v", key, value, event[key])
+			}
+		}
+		if event["org_id"] == "" || event["user_id"] == "" {
+			t.Errorf("expected org and user IDs, got %q and %q", event["org_id"], event["user_id"])
+		}
+		properties := event["additional_properties"].(map[string]interface{})
+		if properties["currentContext.user.clientIpAddress"] == nil {
+			t.Errorf("expected additional properties, got %v", properties)
+		}
+	})
+}
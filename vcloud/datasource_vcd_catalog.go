@@ -258,7 +258,7 @@ func datasourceVcdCatalogRead(_ context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(err)
 	}
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog", catalog)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog", catalog, "datasource")...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -0,0 +1,136 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdCatalogItems() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdCatalogItemsRead,
+		Schema: map[string]*schema.Schema{
+			"catalog_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the catalog containing the items",
+			},
+			"filter": listFilterSchema("catalog items", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"date":       elementDate,
+				"metadata":   elementMetadata,
+			}),
+			"catalog_items": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Catalog items matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog item ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog item name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog item HREF",
+						},
+						"catalog_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the catalog containing the item",
+						},
+						"entity_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the entity referenced by the item, either 'vapptemplate' or 'media'",
+						},
+						"entity_href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "HREF of the vApp template or media referenced by the item",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user owning the item",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog item status",
+						},
+						"is_published": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the catalog containing the item is published",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Timestamp of when the item was created",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdCatalogItemsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	catalogId := d.Get("catalog_id").(string)
+	catalog, err := vcdClient.Client.GetCatalogById(catalogId)
+	if err != nil {
+		return diag.Errorf("error retrieving catalog '%s': %s", catalogId, err)
+	}
+
+	queryType := types.QtCatalogItem
+	if vcdClient.Client.IsSysAdmin {
+		queryType = types.QtAdminCatalogItem
+	}
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		return catalog.SearchByFilter(queryType, "catalog", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, queryType, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving catalog items: %s", err)
+	}
+
+	catalogItems := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		catalogItem, ok := queryItem.(govcd.QueryCatalogItem)
+		if !ok {
+			continue
+		}
+		catalogItems = append(catalogItems, map[string]interface{}{
+			"id":           "urn:vcloud:catalogitem:" + extractUuid(catalogItem.HREF),
+			"name":         catalogItem.Name,
+			"href":         catalogItem.HREF,
+			"catalog_name": catalogItem.CatalogName,
+			"entity_type":  catalogItem.EntityType,
+			"entity_href":  catalogItem.Entity,
+			"owner_name":   catalogItem.OwnerName,
+			"status":       catalogItem.Status,
+			"is_published": catalogItem.IsPublished,
+			"created":      catalogItem.CreationDate,
+		})
+	}
+	err = d.Set("catalog_items", catalogItems)
+	if err != nil {
+		return diag.Errorf("error setting catalog items: %s", err)
+	}
+
+	d.SetId(listFilterId(catalog.Catalog.ID, d.Get("filter")))
+	return nil
+}
@@ -85,9 +85,22 @@ func datasourceVcdCatalogMedia() *schema.Resource {
 				Description: "Storage profile name",
 			},
 			"download_to_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Will download the contents of the media item into the given file. " +
+					"An interrupted download is resumed by the next read",
+			},
+			"download_checksum": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"download_to_file"},
+				ValidateFunc: validateSha256(),
+				Description:  "Expected SHA-256 of the file set in 'download_to_file'. The download fails if it doesn't match",
+			},
+			"download_sha256": {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Will download the contents of the media item into the given file",
+				Computed:    true,
+				Description: "SHA-256 of the file set in 'download_to_file'",
 			},
 			"filter": {
 				Type:        schema.TypeList,
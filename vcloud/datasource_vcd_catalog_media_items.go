@@ -0,0 +1,146 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdCatalogMediaItems() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdCatalogMediaItemsRead,
+		Schema: map[string]*schema.Schema{
+			"catalog_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the catalog containing the media items",
+			},
+			"filter": listFilterSchema("media items", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"date":       elementDate,
+				"metadata":   elementMetadata,
+			}),
+			"media_items": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Media items matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Media item ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Media item name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Media item HREF",
+						},
+						"catalog_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the catalog containing the media item",
+						},
+						"catalog_item_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog Item ID of the media item",
+						},
+						"is_iso": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the media item is an ISO image",
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the media item in bytes",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Media item status",
+						},
+						"storage_profile": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the storage profile of the media item",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user owning the media item",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Timestamp of when the media item was created",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdCatalogMediaItemsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	catalogId := d.Get("catalog_id").(string)
+	catalog, err := vcdClient.Client.GetCatalogById(catalogId)
+	if err != nil {
+		return diag.Errorf("error retrieving catalog '%s': %s", catalogId, err)
+	}
+
+	queryType := types.QtMedia
+	if vcdClient.Client.IsSysAdmin {
+		queryType = types.QtAdminMedia
+	}
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		return catalog.SearchByFilter(queryType, "catalog", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, queryType, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving media items: %s", err)
+	}
+
+	mediaItems := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		media, ok := queryItem.(govcd.QueryMedia)
+		if !ok {
+			continue
+		}
+		catalogItemId := ""
+		if media.CatalogItem != "" {
+			catalogItemId = "urn:vcloud:catalogitem:" + extractUuid(media.CatalogItem)
+		}
+		mediaItems = append(mediaItems, map[string]interface{}{
+			"id":              "urn:vcloud:media:" + extractUuid(media.HREF),
+			"name":            media.Name,
+			"href":            media.HREF,
+			"catalog_name":    media.CatalogName,
+			"catalog_item_id": catalogItemId,
+			"is_iso":          media.IsIso,
+			"size":            int(media.StorageB),
+			"status":          media.Status,
+			"storage_profile": media.StorageProfileName,
+			"owner_name":      media.OwnerName,
+			"created":         media.CreationDate,
+		})
+	}
+	err = d.Set("media_items", mediaItems)
+	if err != nil {
+		return diag.Errorf("error setting media items: %s", err)
+	}
+
+	d.SetId(listFilterId(catalog.Catalog.ID, d.Get("filter")))
+	return nil
+}
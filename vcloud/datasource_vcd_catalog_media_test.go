@@ -55,10 +55,12 @@ func TestAccVcdCatalogAndMediaDatasource(t *testing.T) {
 	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
 
 	defer func() {
-		if fileExists(tempFile) {
-			err := os.Remove(tempFile)
-			if err != nil {
-				fmt.Printf("error deleting file '%s': %s", tempFile, err)
+		for _, fileName := range []string{tempFile, tempFile + downloadChecksumSuffix} {
+			if fileExists(fileName) {
+				err := os.Remove(fileName)
+				if err != nil {
+					fmt.Printf("error deleting file '%s': %s", fileName, err)
+				}
 			}
 		}
 	}()
@@ -2,9 +2,11 @@ package vcloud
 
 import (
 	"context"
+	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func datasourceVcdCatalogVappTemplate() *schema.Resource {
@@ -83,6 +85,25 @@ func datasourceVcdCatalogVappTemplate() *schema.Resource {
 					},
 				},
 			},
+			"download_to_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`(?i)\.(ovf|ova)$`), "must be a file name ending in .ovf or .ova"),
+				Description: "Will export the vApp Template into the given file, as OVF or OVA depending on its extension. " +
+					"An interrupted download is resumed by the next read",
+			},
+			"download_checksum": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"download_to_file"},
+				ValidateFunc: validateSha256(),
+				Description:  "Expected SHA-256 of the file set in 'download_to_file'. The download fails if it doesn't match",
+			},
+			"download_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 of the file set in 'download_to_file'",
+			},
 			"filter": {
 				Type:        schema.TypeList,
 				MaxItems:    1,
@@ -0,0 +1,183 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdCatalogVappTemplates() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdCatalogVappTemplatesRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"catalog_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "ID of the catalog containing the vApp Templates. Can't be used if a specific VDC identifier is set",
+				ExactlyOneOf: []string{"catalog_id", "vdc_id"},
+			},
+			"vdc_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "ID of the VDC to which the vApp Templates belong. Can't be used if a specific Catalog identifier is set",
+				ExactlyOneOf: []string{"catalog_id", "vdc_id"},
+			},
+			"filter": listFilterSchema("vApp Templates", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"date":       elementDate,
+				"metadata":   elementMetadata,
+			}),
+			"vapp_templates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "vApp Templates matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp Template ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp Template name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp Template HREF",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp Template description",
+						},
+						"catalog_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the catalog containing the vApp Template",
+						},
+						"catalog_item_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Catalog Item ID of the vApp Template",
+						},
+						"vdc_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VDC to which the vApp Template belongs",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp Template status",
+						},
+						"storage_profile": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the storage profile of the vApp Template",
+						},
+						"vm_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of VMs in the vApp Template",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user owning the vApp Template",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Timestamp of when the vApp Template was created",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdCatalogVappTemplatesRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	queryType := types.QtVappTemplate
+	if vcdClient.Client.IsSysAdmin {
+		queryType = types.QtAdminVappTemplate
+	}
+
+	var id string
+	var searchFunc searchByFilterFunc
+	if catalogId, isSearchedByCatalog := d.GetOk("catalog_id"); isSearchedByCatalog {
+		catalog, err := vcdClient.Client.GetCatalogById(catalogId.(string))
+		if err != nil {
+			return diag.Errorf("error retrieving catalog '%s': %s", catalogId, err)
+		}
+		id = catalog.Catalog.ID
+		searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+			return catalog.SearchByFilter(queryType, "catalogName", criteria)
+		}
+	} else {
+		adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+		if err != nil {
+			return diag.Errorf(errorRetrievingOrg, err)
+		}
+		vdc, err := adminOrg.GetVDCById(d.Get("vdc_id").(string), false)
+		if err != nil {
+			return diag.Errorf("error retrieving VDC '%s': %s", d.Get("vdc_id"), err)
+		}
+		id = vdc.Vdc.ID
+		searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+			return vdc.SearchByFilter(queryType, "vdc", criteria)
+		}
+	}
+
+	queryItems, err := getEntitiesByFilter(searchFunc, queryType, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving vApp Templates: %s", err)
+	}
+
+	vAppTemplates := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		vAppTemplate, ok := queryItem.(govcd.QueryVAppTemplate)
+		if !ok {
+			continue
+		}
+		catalogItemId := ""
+		if vAppTemplate.CatalogItem != "" {
+			catalogItemId = "urn:vcloud:catalogitem:" + extractUuid(vAppTemplate.CatalogItem)
+		}
+		vAppTemplates = append(vAppTemplates, map[string]interface{}{
+			"id":              "urn:vcloud:vapptemplate:" + extractUuid(vAppTemplate.HREF),
+			"name":            vAppTemplate.Name,
+			"href":            vAppTemplate.HREF,
+			"description":     vAppTemplate.Description,
+			"catalog_name":    vAppTemplate.CatalogName,
+			"catalog_item_id": catalogItemId,
+			"vdc_name":        vAppTemplate.VdcName,
+			"status":          vAppTemplate.Status,
+			"storage_profile": vAppTemplate.StorageProfileName,
+			"vm_count":        vAppTemplate.NumberOfVms,
+			"owner_name":      vAppTemplate.OwnerName,
+			"created":         vAppTemplate.CreationDate,
+		})
+	}
+	err = d.Set("vapp_templates", vAppTemplates)
+	if err != nil {
+		return diag.Errorf("error setting vApp Templates: %s", err)
+	}
+
+	d.SetId(listFilterId(id, d.Get("filter")))
+	return nil
+}
@@ -0,0 +1,124 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdEdgeGateways() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdEdgeGatewaysRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"filter": listFilterSchema("edge gateways", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"metadata":   elementMetadata,
+			}),
+			"edge_gateways": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Edge gateways matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Edge gateway ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Edge gateway name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Edge gateway HREF",
+						},
+						"vdc_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VDC owning the edge gateway",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Edge gateway status",
+						},
+						"ha_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "High availability status of the edge gateway",
+						},
+						"external_network_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of external networks connected to the edge gateway",
+						},
+						"org_network_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of Org VDC networks connected to the edge gateway",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdEdgeGatewaysRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		return vdc.SearchByFilter(queryType, "vdc", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, types.QtEdgeGateway, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving edge gateways: %s", err)
+	}
+
+	edgeGateways := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		edgeGateway, ok := queryItem.(govcd.QueryEdgeGateway)
+		if !ok {
+			continue
+		}
+		edgeGateways = append(edgeGateways, map[string]interface{}{
+			"id":                     "urn:vcloud:gateway:" + extractUuid(edgeGateway.HREF),
+			"name":                   edgeGateway.Name,
+			"href":                   edgeGateway.HREF,
+			"vdc_name":               edgeGateway.OrgVdcName,
+			"status":                 edgeGateway.GatewayStatus,
+			"ha_status":              edgeGateway.HaStatus,
+			"external_network_count": edgeGateway.NumberOfExtNetworks,
+			"org_network_count":      edgeGateway.NumberOfOrgNetworks,
+		})
+	}
+	err = d.Set("edge_gateways", edgeGateways)
+	if err != nil {
+		return diag.Errorf("error setting edge gateways: %s", err)
+	}
+
+	d.SetId(listFilterId(vdc.Vdc.ID, d.Get("filter")))
+	return nil
+}
@@ -92,6 +92,7 @@ func datasourceVcdExternalNetworkV2() *schema.Resource {
 					},
 				},
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("External Network"),
 		},
 	}
 }
@@ -113,5 +114,6 @@ func datasourceVcdExternalNetworkV2Read(_ context.Context, d *schema.ResourceDat
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	return nil
+
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_external_network_v2", newOpenApiEntityMetadata(vcdClient, "vcd_external_network_v2", d.Id(), extNet.ExternalNetwork.Name))
 }
@@ -168,7 +168,7 @@ func dataSourceVcdIndependentDiskRead(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("unable to find queried disk with name %s: and href: %s, %s", identifier, disk.Disk.HREF, err)
 	}
 
-	diags := setMainData(d, vcdClient, disk, diskRecord)
+	diags := setMainData(d, vcdClient, disk, diskRecord, "datasource")
 	if diags != nil && diags.HasError() {
 		return diags
 	}
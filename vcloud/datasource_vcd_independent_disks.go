@@ -0,0 +1,208 @@
+package vcloud
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdIndependentDisks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdIndependentDisksRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"filter": listFilterSchema("independent disks", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"metadata":   elementMetadata,
+			}),
+			"disks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Independent disks matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Independent disk ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Independent disk name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Independent disk HREF",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Independent disk description",
+						},
+						"size_in_mb": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the disk in MB",
+						},
+						"bus_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Bus type of the disk, like SCSI or NVME",
+						},
+						"bus_sub_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Bus subtype of the disk",
+						},
+						"storage_profile": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the storage profile of the disk",
+						},
+						"iops": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "IOPS request for the disk",
+						},
+						"sharing_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Sharing type of the disk",
+						},
+						"encrypted": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the disk is encrypted",
+						},
+						"is_attached": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the disk is attached to a VM",
+						},
+						"attached_vm_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of VMs the disk is attached to",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Independent disk status",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user owning the disk",
+						},
+						"datastore_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the datastore of the disk",
+						},
+						"uuid": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "UUID of the disk in vCenter",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdIndependentDisksRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	// Disks are not supported by the search engine, so the criteria are evaluated here
+	criteria, err := buildCriteria(d.Get("filter"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var nameRegex *regexp.Regexp
+	if criteria.Filters[types.FilterNameRegex] != "" {
+		nameRegex, err = regexp.Compile(criteria.Filters[types.FilterNameRegex])
+		if err != nil {
+			return diag.Errorf("error compiling regular expression '%s': %s", criteria.Filters[types.FilterNameRegex], err)
+		}
+	}
+
+	diskRecords, err := vdc.QueryDisks("*")
+	if err != nil {
+		return diag.Errorf("error retrieving independent disks: %s", err)
+	}
+
+	disks := make([]map[string]interface{}, 0, len(*diskRecords))
+	for _, diskRecord := range *diskRecords {
+		if nameRegex != nil && !nameRegex.MatchString(diskRecord.Name) {
+			continue
+		}
+		if len(criteria.Metadata) > 0 {
+			disk, err := vdc.GetDiskByHref(diskRecord.HREF)
+			if err != nil {
+				return diag.Errorf("error retrieving independent disk '%s': %s", diskRecord.Name, err)
+			}
+			metadata, err := disk.GetMetadata()
+			if err != nil {
+				return diag.Errorf("error retrieving metadata of independent disk '%s': %s", diskRecord.Name, err)
+			}
+			matches, err := metadataMatchesCriteria(metadata, criteria.Metadata, criteria.UseMetadataApiFilter)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if !matches {
+				continue
+			}
+		}
+		busType := busTypesFromValues[diskRecord.BusType]
+		if diskRecord.BusSubType == "vmware.nvme.controller" {
+			busType = busTypesFromValues["20nvme"]
+		}
+		disks = append(disks, map[string]interface{}{
+			"id":                "urn:vcloud:disk:" + extractUuid(diskRecord.HREF),
+			"name":              diskRecord.Name,
+			"href":              diskRecord.HREF,
+			"description":       diskRecord.Description,
+			"size_in_mb":        int(diskRecord.SizeMb),
+			"bus_type":          busType,
+			"bus_sub_type":      busSubTypesFromValues[diskRecord.BusSubType],
+			"storage_profile":   diskRecord.StorageProfileName,
+			"iops":              int(diskRecord.Iops),
+			"sharing_type":      diskRecord.SharingType,
+			"encrypted":         diskRecord.Encrypted,
+			"is_attached":       diskRecord.IsAttached,
+			"attached_vm_count": int(diskRecord.AttachedVmCount),
+			"status":            diskRecord.Status,
+			"owner_name":        diskRecord.OwnerName,
+			"datastore_name":    diskRecord.DataStoreName,
+			"uuid":              diskRecord.UUID,
+		})
+	}
+	err = d.Set("disks", disks)
+	if err != nil {
+		return diag.Errorf("error setting independent disks: %s", err)
+	}
+
+	d.SetId(listFilterId(vdc.Vdc.ID, d.Get("filter")))
+	return nil
+}
@@ -139,6 +139,7 @@ func datasourceVcdIpSpace() *schema.Resource {
 				Computed:    true,
 				Description: "Flag whether SNAT rule creation should be enabled (VCD 10.5.0+)",
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("IP Space"),
 		},
 	}
 }
@@ -172,5 +173,5 @@ func datasourceVcdIpSpaceRead(ctx context.Context, d *schema.ResourceData, meta
 
 	d.SetId(ipSpace.IpSpace.ID)
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_ip_space", newOpenApiEntityMetadata(vcdClient, "vcd_ip_space", d.Id(), ipSpace.IpSpace.Name))
 }
@@ -0,0 +1,434 @@
+package vcloud
+
+import (
+	"context"
+	"log"
+	"net/netip"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+var ipSpaceUsageCounts = map[string]*schema.Schema{
+	"total_count": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of IP addresses or prefixes",
+	},
+	"allocated_count": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of allocated IP addresses or prefixes",
+	},
+	"used_count": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of allocated IP addresses or prefixes used by a network service or marked for manual use",
+	},
+	"free_count": {
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of IP addresses or prefixes that are not allocated",
+	},
+}
+
+// withIpSpaceUsageCounts returns the given schema with the utilization counts added
+func withIpSpaceUsageCounts(fields map[string]*schema.Schema) map[string]*schema.Schema {
+	for name, field := range ipSpaceUsageCounts {
+		fields[name] = field
+	}
+	return fields
+}
+
+func datasourceVcdIpSpaceUsage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdIpSpaceUsageRead,
+
+		Schema: map[string]*schema.Schema{
+			"ip_space_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of IP Space",
+			},
+			"floating_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Utilization of all the IP ranges",
+				Elem:        &schema.Resource{Schema: withIpSpaceUsageCounts(map[string]*schema.Schema{})},
+			},
+			"ip_ranges": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Utilization of each IP range",
+				Elem: &schema.Resource{
+					Schema: withIpSpaceUsageCounts(map[string]*schema.Schema{
+						"start_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Start address of the IP range",
+						},
+						"end_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "End address of the IP range",
+						},
+					}),
+				},
+			},
+			"ip_prefixes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Utilization of the IP prefixes of each prefix length",
+				Elem: &schema.Resource{
+					Schema: withIpSpaceUsageCounts(map[string]*schema.Schema{
+						"prefix_length": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Prefix length",
+						},
+						"free_prefixes": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "IP prefixes that are not allocated, in CIDR format",
+						},
+					}),
+				},
+			},
+			"free_ip_blocks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Blocks of contiguous IP addresses of the IP ranges that are not allocated",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"start_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "First address of the block",
+						},
+						"end_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Last address of the block",
+						},
+						"count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of addresses in the block",
+						},
+					},
+				},
+			},
+			"org_usage": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Consumption of each organization that has allocations or quotas in the IP Space",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"org_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Organization ID",
+						},
+						"org_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Organization name",
+						},
+						"floating_ip_quota": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Floating IP quota of the organization, custom or default. '-1' - unlimited",
+						},
+						"floating_ip_allocated_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of floating IPs allocated to the organization",
+						},
+						"floating_ip_used_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of floating IPs of the organization that are in use",
+						},
+						"ip_prefixes": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Consumption of IP prefixes for each prefix length",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"prefix_length": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Prefix length",
+									},
+									"quota": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "IP prefix quota of the organization, custom or default. '-1' - unlimited",
+									},
+									"allocated_count": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Number of IP prefixes allocated to the organization",
+									},
+									"used_count": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Number of IP prefixes of the organization that are in use",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdIpSpaceUsageRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[TRACE] IP Space Usage DS read initiated")
+
+	vcdClient := meta.(*VCDClient)
+
+	ipSpaceId := d.Get("ip_space_id").(string)
+	ipSpace, err := vcdClient.GetIpSpaceById(ipSpaceId)
+	if err != nil {
+		return diag.Errorf("error getting IP Space by ID '%s': %s", ipSpaceId, err)
+	}
+	floatingIps, err := getIpSpaceAllocations(ipSpace, types.IpSpaceIpAllocationTypeFloatingIp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ipPrefixes, err := getIpSpaceAllocations(ipSpace, types.IpSpaceIpAllocationTypeIpPrefix)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	orgAssignments, err := ipSpace.GetAllOrgAssignments(nil)
+	if err != nil {
+		return diag.Errorf("error retrieving Org Assignments of IP Space '%s': %s", ipSpace.IpSpace.Name, err)
+	}
+	assignments := make([]*types.IpSpaceOrgAssignment, 0, len(orgAssignments))
+	for _, orgAssignment := range orgAssignments {
+		assignments = append(assignments, orgAssignment.IpSpaceOrgAssignment)
+	}
+
+	usage, err := computeIpSpaceUsage(ipSpace.IpSpace, floatingIps, ipPrefixes, assignments)
+	if err != nil {
+		return diag.Errorf("error computing utilization of IP Space '%s': %s", ipSpace.IpSpace.Name, err)
+	}
+	for field, value := range usage {
+		err = d.Set(field, value)
+		if err != nil {
+			return diag.Errorf("error storing '%s': %s", field, err)
+		}
+	}
+	d.SetId(ipSpace.IpSpace.ID)
+
+	return nil
+}
+
+// computeIpSpaceUsage returns the values of the computed fields of vcloud_ip_space_usage
+func computeIpSpaceUsage(ipSpace *types.IpSpace, floatingIps, ipPrefixes []*types.IpSpaceIpAllocation,
+	assignments []*types.IpSpaceOrgAssignment) (map[string]interface{}, error) {
+
+	isUsed := func(allocation *types.IpSpaceIpAllocation) bool {
+		return allocation.UsageState != "UNUSED"
+	}
+
+	ranges, err := ipSpaceRangeBlocks(ipSpace)
+	if err != nil {
+		return nil, err
+	}
+	allocatedBlocks, err := allocationBlocks(floatingIps)
+	if err != nil {
+		return nil, err
+	}
+	var totals [4]int64 // total, allocated, used, free
+	ipRanges := make([]map[string]interface{}, 0, len(ranges))
+	for _, ipRange := range ranges {
+		var allocated, used int64
+		for i, block := range allocatedBlocks {
+			if ipRange.contains(block) {
+				allocated += block.size()
+				if isUsed(floatingIps[i]) {
+					used += block.size()
+				}
+			}
+		}
+		total := ipRange.size()
+		ipRanges = append(ipRanges, map[string]interface{}{
+			"start_address":   ipRange.start.String(),
+			"end_address":     ipRange.end.String(),
+			"total_count":     total,
+			"allocated_count": allocated,
+			"used_count":      used,
+			"free_count":      total - allocated,
+		})
+		for i, value := range []int64{total, allocated, used, total - allocated} {
+			totals[i] += value
+		}
+	}
+
+	freeBlocks := make([]map[string]interface{}, 0)
+	for _, block := range freeIpBlocks(ranges, allocatedBlocks) {
+		freeBlocks = append(freeBlocks, map[string]interface{}{
+			"start_address": block.start.String(),
+			"end_address":   block.end.String(),
+			"count":         block.size(),
+		})
+	}
+
+	prefixUsage, err := ipSpacePrefixUsage(ipSpace, ipPrefixes)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := make([]map[string]interface{}, 0, len(prefixUsage))
+	defaultPrefixQuotas := make(map[int]int)
+	for _, usage := range prefixUsage {
+		prefixes = append(prefixes, map[string]interface{}{
+			"prefix_length":   usage.prefixLength,
+			"total_count":     usage.total,
+			"allocated_count": usage.allocated,
+			"used_count":      usage.used,
+			"free_count":      usage.total - usage.allocated,
+			"free_prefixes":   usage.free,
+		})
+	}
+	for _, ipSpacePrefixes := range ipSpace.IPSpacePrefixes {
+		for _, sequence := range ipSpacePrefixes.IPPrefixSequence {
+			defaultPrefixQuotas[sequence.PrefixLength] = ipSpacePrefixes.DefaultQuotaForPrefixLength
+		}
+	}
+
+	return map[string]interface{}{
+		"floating_ips": []map[string]interface{}{{
+			"total_count":     totals[0],
+			"allocated_count": totals[1],
+			"used_count":      totals[2],
+			"free_count":      totals[3],
+		}},
+		"ip_ranges":      ipRanges,
+		"ip_prefixes":    prefixes,
+		"free_ip_blocks": freeBlocks,
+		"org_usage":      ipSpaceOrgUsage(ipSpace, floatingIps, ipPrefixes, assignments, defaultPrefixQuotas),
+	}, nil
+}
+
+// ipSpaceOrgUsage returns the consumption of each organization with allocations or an Org Assignment,
+// sorted by organization name. Custom quotas take precedence over the default ones
+func ipSpaceOrgUsage(ipSpace *types.IpSpace, floatingIps, ipPrefixes []*types.IpSpaceIpAllocation,
+	assignments []*types.IpSpaceOrgAssignment, defaultPrefixQuotas map[int]int) []map[string]interface{} {
+
+	type prefixConsumption struct {
+		quota, allocated, used int
+	}
+	type orgConsumption struct {
+		id, name                        string
+		floatingAllocated, floatingUsed int
+		prefixes                        map[int]*prefixConsumption
+		defaultQuotas, customQuotas     *types.IpSpaceOrgAssignmentQuotas
+	}
+
+	orgs := make(map[string]*orgConsumption)
+	getOrg := func(ref *types.OpenApiReference) *orgConsumption {
+		if orgs[ref.ID] == nil {
+			orgs[ref.ID] = &orgConsumption{id: ref.ID, name: ref.Name, prefixes: make(map[int]*prefixConsumption)}
+		}
+		if orgs[ref.ID].name == "" {
+			orgs[ref.ID].name = ref.Name
+		}
+		return orgs[ref.ID]
+	}
+	for _, assignment := range assignments {
+		if assignment.OrgRef == nil {
+			continue
+		}
+		org := getOrg(assignment.OrgRef)
+		org.defaultQuotas, org.customQuotas = assignment.DefaultQuotas, assignment.CustomQuotas
+	}
+	for _, allocation := range floatingIps {
+		if allocation.OrgRef == nil {
+			continue
+		}
+		org := getOrg(allocation.OrgRef)
+		org.floatingAllocated++
+		if allocation.UsageState != "UNUSED" {
+			org.floatingUsed++
+		}
+	}
+	for _, allocation := range ipPrefixes {
+		if allocation.OrgRef == nil {
+			continue
+		}
+		org := getOrg(allocation.OrgRef)
+		prefix, err := netip.ParsePrefix(allocation.Value)
+		if err != nil {
+			continue
+		}
+		length := prefix.Bits()
+		if org.prefixes[length] == nil {
+			org.prefixes[length] = &prefixConsumption{}
+		}
+		org.prefixes[length].allocated++
+		if allocation.UsageState != "UNUSED" {
+			org.prefixes[length].used++
+		}
+	}
+
+	result := make([]map[string]interface{}, 0, len(orgs))
+	for _, org := range orgs {
+		floatingIpQuota := ipSpace.IPSpaceRanges.DefaultFloatingIPQuota
+		prefixQuotas := make(map[int]int)
+		for length, quota := range defaultPrefixQuotas {
+			prefixQuotas[length] = quota
+		}
+		for _, quotas := range []*types.IpSpaceOrgAssignmentQuotas{org.defaultQuotas, org.customQuotas} {
+			if quotas == nil {
+				continue
+			}
+			if quotas.FloatingIPQuota != nil {
+				floatingIpQuota = *quotas.FloatingIPQuota
+			}
+			for _, prefixQuota := range quotas.IPPrefixQuotas {
+				if prefixQuota.PrefixLength != nil && prefixQuota.Quota != nil {
+					prefixQuotas[*prefixQuota.PrefixLength] = *prefixQuota.Quota
+				}
+			}
+		}
+		for length, quota := range prefixQuotas {
+			if org.prefixes[length] == nil {
+				org.prefixes[length] = &prefixConsumption{}
+			}
+			org.prefixes[length].quota = quota
+		}
+		lengths := make([]int, 0, len(org.prefixes))
+		for length := range org.prefixes {
+			lengths = append(lengths, length)
+		}
+		sort.Ints(lengths)
+		prefixes := make([]map[string]interface{}, 0, len(lengths))
+		for _, length := range lengths {
+			prefixes = append(prefixes, map[string]interface{}{
+				"prefix_length":   length,
+				"quota":           org.prefixes[length].quota,
+				"allocated_count": org.prefixes[length].allocated,
+				"used_count":      org.prefixes[length].used,
+			})
+		}
+		result = append(result, map[string]interface{}{
+			"org_id":                      org.id,
+			"org_name":                    org.name,
+			"floating_ip_quota":           floatingIpQuota,
+			"floating_ip_allocated_count": org.floatingAllocated,
+			"floating_ip_used_count":      org.floatingUsed,
+			"ip_prefixes":                 prefixes,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i]["org_name"].(string) < result[j]["org_name"].(string)
+	})
+	return result
+}
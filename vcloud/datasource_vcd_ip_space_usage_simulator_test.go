@@ -0,0 +1,99 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorIpSpaceUsage checks the utilization and the free blocks reported for the floating IPs and
+// IP prefixes allocated with vcloud_ip_space_ip_allocation
+func TestSimulatorIpSpaceUsage(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	allocationResource := resourceVcdIpAllocation()
+	usageDataSource := datasourceVcdIpSpaceUsage()
+
+	ipSpaceId := sim.AddIpSpace(types.IpSpace{
+		Name: "public",
+		IPSpaceRanges: types.IPSpaceRanges{
+			IPRanges:               []types.IpSpaceRangeValues{{StartIPAddress: "10.0.0.1", EndIPAddress: "10.0.0.10"}},
+			DefaultFloatingIPQuota: 5,
+		},
+		IPSpacePrefixes: []types.IPSpacePrefixes{{
+			IPPrefixSequence:            []types.IPPrefixSequence{{StartingPrefixIPAddress: "192.168.0.0", PrefixLength: 28, TotalPrefixCount: 4}},
+			DefaultQuotaForPrefixLength: 2,
+		}},
+	})
+	org, err := vcdClient.GetOrg(simulatorOrg)
+	if err != nil {
+		t.Fatalf("error retrieving Org: %s", err)
+	}
+
+	for _, values := range []map[string]interface{}{
+		{"type": "FLOATING_IP", "quantity": 3, "usage_state": "USED_MANUAL"},
+		{"type": "FLOATING_IP"},
+		{"type": "IP_PREFIX", "prefix_length": "28"},
+	} {
+		values["org_id"] = org.Org.ID
+		values["ip_space_id"] = ipSpaceId
+		d := simulatorResourceData(t, allocationResource, values)
+		if diags := allocationResource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error creating IP allocation %v: %v", values, diags)
+		}
+	}
+
+	d := simulatorResourceData(t, usageDataSource, map[string]interface{}{"ip_space_id": ipSpaceId})
+	if diags := usageDataSource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading IP Space usage: %v", diags)
+	}
+
+	counts := func(prefix string) [4]int {
+		return [4]int{d.Get(prefix + ".total_count").(int), d.Get(prefix + ".allocated_count").(int),
+			d.Get(prefix + ".used_count").(int), d.Get(prefix + ".free_count").(int)}
+	}
+	if got := counts("floating_ips.0"); got != [4]int{10, 4, 3, 6} {
+		t.Errorf("expected floating IP counts [total allocated used free] [10 4 3 6], got %v", got)
+	}
+	if got := counts("ip_ranges.0"); got != [4]int{10, 4, 3, 6} {
+		t.Errorf("expected IP range counts [10 4 3 6], got %v", got)
+	}
+	freeBlocks := d.Get("free_ip_blocks").([]interface{})
+	wantBlocks := []interface{}{
+		map[string]interface{}{"start_address": "10.0.0.5", "end_address": "10.0.0.10", "count": 6},
+	}
+	if !reflect.DeepEqual(freeBlocks, wantBlocks) {
+		t.Errorf("expected free IP blocks %v, got %v", wantBlocks, freeBlocks)
+	}
+
+	if length := d.Get("ip_prefixes.0.prefix_length").(int); length != 28 {
+		t.Fatalf("expected prefix length 28, got %d", length)
+	}
+	if got := counts("ip_prefixes.0"); got != [4]int{4, 1, 0, 3} {
+		t.Errorf("expected IP prefix counts [4 1 0 3], got %v", got)
+	}
+	wantPrefixes := []interface{}{"192.168.0.16/28", "192.168.0.32/28", "192.168.0.48/28"}
+	if freePrefixes := d.Get("ip_prefixes.0.free_prefixes").([]interface{}); !reflect.DeepEqual(freePrefixes, wantPrefixes) {
+		t.Errorf("expected free prefixes %v, got %v", wantPrefixes, freePrefixes)
+	}
+
+	// The Org gets the default quotas of the IP Space
+	orgUsage := d.Get("org_usage").([]interface{})
+	wantOrgUsage := []interface{}{map[string]interface{}{
+		"org_id":                      org.Org.ID,
+		"org_name":                    simulatorOrg,
+		"floating_ip_quota":           5,
+		"floating_ip_allocated_count": 4,
+		"floating_ip_used_count":      3,
+		"ip_prefixes": []interface{}{map[string]interface{}{
+			"prefix_length": 28, "quota": 2, "allocated_count": 1, "used_count": 0,
+		}},
+	}}
+	if !reflect.DeepEqual(orgUsage, wantOrgUsage) {
+		t.Errorf("expected Org usage %v, got %v", wantOrgUsage, orgUsage)
+	}
+}
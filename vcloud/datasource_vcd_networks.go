@@ -0,0 +1,162 @@
+package vcloud
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdNetworks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdNetworksRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"network_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"routed", "isolated", "direct"}, false),
+				Description:  "If set, only the networks of this type ('routed', 'isolated' or 'direct') are retrieved",
+			},
+			"filter": listFilterSchema("networks", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"ip":         elementIp,
+				"metadata":   elementMetadata,
+			}),
+			"networks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Org VDC networks matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Network ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Network name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Network HREF",
+						},
+						"network_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Network type, either 'routed', 'isolated' or 'direct'",
+						},
+						"vdc_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VDC owning the network",
+						},
+						"gateway": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Gateway IP address",
+						},
+						"netmask": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Network mask",
+						},
+						"dns1": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "First DNS server",
+						},
+						"dns2": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Second DNS server",
+						},
+						"dns_suffix": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "DNS suffix",
+						},
+						"connected_to": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the edge gateway or external network the network is connected to",
+						},
+						"shared": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the network is shared with other VDCs",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdNetworksRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		return vdc.SearchByFilter(queryType, "vdc", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, types.QtOrgVdcNetwork, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving networks: %s", err)
+	}
+
+	wantedType := d.Get("network_type").(string)
+	networks := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		network, ok := queryItem.(govcd.QueryOrgVdcNetwork)
+		if !ok {
+			continue
+		}
+		networkType := strings.TrimPrefix(network.GetType(), "network_")
+		if wantedType != "" && wantedType != networkType {
+			continue
+		}
+		networks = append(networks, map[string]interface{}{
+			"id":           "urn:vcloud:network:" + extractUuid(network.HREF),
+			"name":         network.Name,
+			"href":         network.HREF,
+			"network_type": networkType,
+			"vdc_name":     network.VdcName,
+			"gateway":      network.DefaultGateway,
+			"netmask":      network.Netmask,
+			"dns1":         network.Dns1,
+			"dns2":         network.Dns2,
+			"dns_suffix":   network.DnsSuffix,
+			"connected_to": network.ConnectedTo,
+			"shared":       network.IsShared,
+		})
+	}
+	err = d.Set("networks", networks)
+	if err != nil {
+		return diag.Errorf("error setting networks: %s", err)
+	}
+
+	d.SetId(listFilterId(vdc.Vdc.ID, d.Get("filter")))
+	return nil
+}
@@ -228,6 +228,7 @@ func datasourceVcdAlbPool() *schema.Resource {
 				Computed:    true,
 				Description: "Health message",
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("NSX-T ALB Pool"),
 		},
 	}
 }
@@ -256,5 +257,5 @@ func datasourceVcdAlbPoolRead(_ context.Context, d *schema.ResourceData, meta in
 	}
 	d.SetId(albPool.NsxtAlbPool.ID)
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_alb_pool", newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_pool", d.Id(), albPool.NsxtAlbPool.Name))
 }
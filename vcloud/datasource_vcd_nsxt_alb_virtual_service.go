@@ -110,6 +110,7 @@ func datasourceVcdAlbVirtualService() *schema.Resource {
 				Computed:    true,
 				Description: "Preserves Client IP on a Virtual Service when enabled (VCD 10.4.1+)",
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("NSX-T ALB Virtual Service"),
 		},
 	}
 }
@@ -138,5 +139,6 @@ func datasourceVcdAlbVirtualServiceRead(_ context.Context, d *schema.ResourceDat
 	}
 	d.SetId(albVirtualService.NsxtAlbVirtualService.ID)
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_alb_virtual_service",
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_virtual_service", d.Id(), albVirtualService.NsxtAlbVirtualService.Name))
 }
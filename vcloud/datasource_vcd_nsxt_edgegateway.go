@@ -198,6 +198,7 @@ func datasourceVcdNsxtEdgeGateway() *schema.Resource {
 				Computed:    true,
 				Description: "Total number of IPs allocated for this Gateway from NSX-T Segment backed External Network uplinks",
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("NSX-T Edge Gateway"),
 		},
 	}
 }
@@ -280,5 +281,5 @@ func datasourceVcdNsxtEdgeGatewayRead(_ context.Context, d *schema.ResourceData,
 
 	d.SetId(edge.EdgeGateway.ID)
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_edgegateway", newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", d.Id(), edge.EdgeGateway.Name))
 }
@@ -0,0 +1,41 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestSimulatorNsxtEdgeGatewayDataSource checks the lookup of an NSX-T edge gateway, which is only found in an NSX-T VDC
+func TestSimulatorNsxtEdgeGatewayDataSource(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	dataSource := datasourceVcdNsxtEdgeGateway()
+
+	d := simulatorResourceData(t, dataSource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorNsxtVdc,
+		"name": simulatorEdgeGateway,
+	})
+	if diags := dataSource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading edge gateway: %v", diags)
+	}
+	if !strings.HasPrefix(d.Id(), "urn:vcloud:gateway:") {
+		t.Fatalf("unexpected edge gateway ID %s", d.Id())
+	}
+	if d.Get("vdc").(string) != simulatorNsxtVdc {
+		t.Fatalf("unexpected VDC %q", d.Get("vdc"))
+	}
+
+	// NSX-T edge gateways can't be looked up in an NSX-V VDC
+	d = simulatorResourceData(t, dataSource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": simulatorEdgeGateway,
+	})
+	if diags := dataSource.ReadContext(ctx, d, vcdClient); !diags.HasError() {
+		t.Fatal("expected an error when reading an edge gateway in an NSX-V VDC")
+	}
+}
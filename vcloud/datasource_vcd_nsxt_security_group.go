@@ -61,6 +61,7 @@ func datasourceVcdNsxtSecurityGroup() *schema.Resource {
 				Description: "Set of VM IDs",
 				Elem:        nsxtFirewallGroupMemberVms,
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("NSX-T Security Group"),
 		},
 	}
 }
@@ -135,5 +136,6 @@ func datasourceVcdSecurityGroupRead(_ context.Context, d *schema.ResourceData, m
 
 	d.SetId(securityGroup.NsxtFirewallGroup.ID)
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_security_group",
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_security_group", d.Id(), securityGroup.NsxtFirewallGroup.Name))
 }
@@ -180,7 +180,7 @@ func datasourceVcdOrgRead(_ context.Context, d *schema.ResourceData, meta interf
 	log.Printf("Org with id %s found", identifier)
 	d.SetId(adminOrg.AdminOrg.ID)
 
-	diags := setOrgData(d, vcdClient, adminOrg)
+	diags := setOrgData(d, vcdClient, adminOrg, "datasource")
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -0,0 +1,86 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourceVcdOrgEmailSettings() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdOrgEmailSettingsRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"use_default_sender": { // IsDefaultOrgEmail
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If true, emails are sent with the sender address of the system",
+			},
+			"sender_email": { // FromEmailAddress
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Sender address of the Org emails",
+			},
+			"subject_prefix": { // DefaultSubjectPrefix
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Text added at the start of the subject of the Org emails",
+			},
+			"alert_all_admins": { // IsAlertEmailToAllAdmins
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If true, alerts are sent to all the Org administrators",
+			},
+			"alert_recipients": { // AlertEmailTo
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Addresses that receive the alerts when `alert_all_admins` is false",
+			},
+			"use_default_smtp_server": { // IsDefaultSmtpServer
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If true, the Org uses the SMTP server of the system",
+			},
+			"smtp_server": { // SmtpServerSettings
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "SMTP server of the Org, when `use_default_smtp_server` is false",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": { // Host
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Host name or IP address of the SMTP server",
+						},
+						"port": { // Port
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Port of the SMTP server",
+						},
+						"security_mode": { // SmtpSecureMode
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Security of the connection to the SMTP server (one of NONE, STARTTLS, SSL)",
+						},
+						"username": { // Username
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User name to authenticate with the SMTP server",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdOrgEmailSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdOrgEmailSettingsRead(ctx, d, meta, "datasource")
+}
@@ -0,0 +1,41 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourceVcdOrgPasswordPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdOrgPasswordPolicyRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"account_lockout_enabled": { // AccountLockoutEnabled
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If true, users are locked out after `invalid_logins_before_lockout` failed logins",
+			},
+			"invalid_logins_before_lockout": { // InvalidLoginsBeforeLockout
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of invalid login attempts that trigger the lockout of an account",
+			},
+			"lockout_interval_minutes": { // AccountLockoutIntervalMinutes
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of minutes a locked out account stays locked",
+			},
+		},
+	}
+}
+
+func datasourceVcdOrgPasswordPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdOrgPasswordPolicyRead(ctx, d, meta, "datasource")
+}
@@ -0,0 +1,190 @@
+package vcloud
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdOrgUsers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdOrgUsersRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"filter": listFilterSchema("users", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+			}),
+			"users": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Users matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User name",
+						},
+						"role": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Role within the organization",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user's description",
+						},
+						"provider_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Identity provider type for this user. One of: 'INTEGRATED', 'SAML', 'OAUTH'",
+						},
+						"full_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user's full name",
+						},
+						"email_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user's email address",
+						},
+						"telephone": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user's telephone",
+						},
+						"instant_messaging": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user's instant messaging",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the user is enabled and can log in",
+						},
+						"is_group_role": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if this user has a group role",
+						},
+						"is_locked": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the user account has been locked due to too many invalid login attempts",
+						},
+						"is_external": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the user account was imported from an external resource, like an LDAP",
+						},
+						"deployed_vm_quota": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Quota of vApps that this user can deploy. A value of 0 specifies an unlimited quota",
+						},
+						"stored_vm_quota": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Quota of vApps that this user can store. A value of 0 specifies an unlimited quota",
+						},
+						"group_names": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "List of group names that this user belongs to",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdOrgUsersRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	// Users are not supported by the search engine, so the criteria are evaluated here
+	criteria, err := buildCriteria(d.Get("filter"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var nameRegex *regexp.Regexp
+	if criteria.Filters[types.FilterNameRegex] != "" {
+		nameRegex, err = regexp.Compile(criteria.Filters[types.FilterNameRegex])
+		if err != nil {
+			return diag.Errorf("error compiling regular expression '%s': %s", criteria.Filters[types.FilterNameRegex], err)
+		}
+	}
+
+	users := make([]map[string]interface{}, 0)
+	if adminOrg.AdminOrg.Users != nil {
+		for _, userReference := range adminOrg.AdminOrg.Users.User {
+			if nameRegex != nil && !nameRegex.MatchString(userReference.Name) {
+				continue
+			}
+			orgUser, err := adminOrg.GetUserByHref(userReference.HREF)
+			if err != nil {
+				return diag.Errorf("error retrieving user '%s': %s", userReference.Name, err)
+			}
+
+			var groups []string
+			if orgUser.User.GroupReferences != nil {
+				for _, groupReference := range orgUser.User.GroupReferences.GroupReference {
+					groups = append(groups, groupReference.Name)
+				}
+			}
+			role := ""
+			if orgUser.User.Role != nil {
+				role = orgUser.User.Role.Name
+			}
+			users = append(users, map[string]interface{}{
+				"id":                orgUser.User.ID,
+				"name":              orgUser.User.Name,
+				"role":              role,
+				"description":       orgUser.User.Description,
+				"provider_type":     orgUser.User.ProviderType,
+				"full_name":         orgUser.User.FullName,
+				"email_address":     orgUser.User.EmailAddress,
+				"telephone":         orgUser.User.Telephone,
+				"instant_messaging": orgUser.User.IM,
+				"enabled":           orgUser.User.IsEnabled,
+				"is_group_role":     orgUser.User.IsGroupRole,
+				"is_locked":         orgUser.User.IsLocked,
+				"is_external":       orgUser.User.IsExternal,
+				"deployed_vm_quota": orgUser.User.DeployedVmQuota,
+				"stored_vm_quota":   orgUser.User.StoredVmQuota,
+				"group_names":       convertStringsToTypeSet(groups),
+			})
+		}
+	}
+	err = d.Set("users", users)
+	if err != nil {
+		return diag.Errorf("error setting users: %s", err)
+	}
+
+	d.SetId(listFilterId(adminOrg.AdminOrg.ID, d.Get("filter")))
+	return nil
+}
@@ -259,7 +259,7 @@ func datasourceVcdOrgVdcRead(_ context.Context, d *schema.ResourceData, meta int
 
 	d.SetId(adminVdc.AdminVdc.ID)
 
-	diags := setOrgVdcData(d, vcdClient, adminVdc)
+	diags := setOrgVdcData(d, vcdClient, adminVdc, "datasource")
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -84,7 +84,7 @@ func datasourceVcdRdeRead(_ context.Context, d *schema.ResourceData, meta interf
 		dSet(d, "owner_user_id", rde.DefinedEntity.Owner.ID)
 	}
 
-	diags := updateOpenApiMetadataInState(d, vcdClient, "vcd_rde", rde)
+	diags := updateOpenApiMetadataInState(d, vcdClient, "vcd_rde", sdkOpenApiMetadata{rde})
 	if diags != nil && diags.HasError() {
 		return diags
 	}
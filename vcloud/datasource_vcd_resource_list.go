@@ -23,6 +23,7 @@ type resourceRef struct {
 	href         string
 	parent       string
 	importId     bool
+	importParent bool // the parent is part of the import ID, after the ancestors of the list
 }
 
 type vappNetworkType int
@@ -84,18 +85,25 @@ func datasourceVcdResourceList() *schema.Resource {
 				Default:     "name",
 				Description: "How the list should be built",
 				ValidateFunc: validation.StringInSlice([]string{
-					"name",      // The list will contain only the entity name
-					"id",        // The list will contain only the entity ID
-					"href",      // The list will contain only the entity HREF
-					"import",    // The list will contain the terraform import command
-					"name_id",   // The list will contain name + ID for each item
-					"hierarchy", // The list will contain parent names + resource name for each item
+					"name",         // The list will contain only the entity name
+					"id",           // The list will contain only the entity ID
+					"href",         // The list will contain only the entity HREF
+					"import",       // The list will contain the terraform import command
+					"import_block", // The list will contain the Terraform 1.5+ import block
+					"name_id",      // The list will contain name + ID for each item
+					"hierarchy",    // The list will contain parent names + resource name for each item
 				}, true),
 			},
 			"import_file_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "File where to store the import info - Only used with 'import' list mode ",
+				Description: "File where to store the import info - Only used with 'import' and 'import_block' list modes",
+			},
+			"hcl_skeleton_file_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "File where to store the resource definitions of the imported resources, with their " +
+					"required arguments filled from VCD - Only used with 'import' and 'import_block' list modes",
 			},
 			"name_regex": {
 				Type:         schema.TypeString,
@@ -133,7 +141,7 @@ func getSiteAssociationList(d *schema.ResourceData, meta interface{}, resType st
 			importId: false,
 		})
 	}
-	return genericResourceList(d, resType, nil, items)
+	return genericResourceList(d, meta, resType, nil, items)
 }
 
 func getOrgAssociationList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
@@ -156,7 +164,7 @@ func getOrgAssociationList(d *schema.ResourceData, meta interface{}, resType str
 			importId: false,
 		})
 	}
-	return genericResourceList(d, resType, nil, items)
+	return genericResourceList(d, meta, resType, nil, items)
 }
 
 func getOrgList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
@@ -176,7 +184,7 @@ func getOrgList(d *schema.ResourceData, meta interface{}, resType string) (list
 			importId: false,
 		})
 	}
-	return genericResourceList(d, resType, nil, items)
+	return genericResourceList(d, meta, resType, nil, items)
 }
 
 func getPvdcList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -194,10 +202,10 @@ func getPvdcList(d *schema.ResourceData, meta interface{}) (list []string, err e
 			href:         pvdc.HREF,
 			parent:       "",
 			importId:     false,
-			resourceType: "vcd_provider_vdc",
+			resourceType: "vcloud_provider_vdc",
 		})
 	}
-	return genericResourceList(d, "vcd_provider_vdc", nil, items)
+	return genericResourceList(d, meta, "vcloud_provider_vdc", nil, items)
 }
 
 func getVdcGroups(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -219,10 +227,10 @@ func getVdcGroups(d *schema.ResourceData, meta interface{}) (list []string, err
 			href:         "",
 			parent:       org.AdminOrg.Name,
 			importId:     false,
-			resourceType: "vcd_vdc_group",
+			resourceType: "vcloud_vdc_group",
 		})
 	}
-	return genericResourceList(d, "vcd_vdc_group", []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, "vcloud_vdc_group", []string{org.AdminOrg.Name}, items)
 }
 
 func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -253,7 +261,7 @@ func externalNetworkList(d *schema.ResourceData, meta interface{}) (list []strin
 			importId: false,
 		})
 	}
-	return genericResourceList(d, "vcd_external_network", nil, items)
+	return genericResourceList(d, meta, "vcloud_external_network", nil, items)
 }
 
 func rightsList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -279,7 +287,7 @@ func rightsList(d *schema.ResourceData, meta interface{}) (list []string, err er
 			importId: false,
 		})
 	}
-	return genericResourceList(d, "vcd_right", []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, "vcloud_right", []string{org.AdminOrg.Name}, items)
 }
 
 func rolesList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -305,7 +313,7 @@ func rolesList(d *schema.ResourceData, meta interface{}) (list []string, err err
 			importId: false,
 		})
 	}
-	return genericResourceList(d, "vcd_role", []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, "vcloud_role", []string{org.AdminOrg.Name}, items)
 
 }
 
@@ -326,7 +334,7 @@ func globalRolesList(d *schema.ResourceData, meta interface{}) (list []string, e
 			importId: false,
 		})
 	}
-	return genericResourceList(d, "vcd_global_role", nil, items)
+	return genericResourceList(d, meta, "vcloud_global_role", nil, items)
 }
 
 func libraryCertificateList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -362,7 +370,7 @@ func libraryCertificateList(d *schema.ResourceData, meta interface{}) (list []st
 		})
 
 	}
-	return genericResourceList(d, "vcd_certificate_library", ancestors, items)
+	return genericResourceList(d, meta, "vcloud_library_certificate", ancestors, items)
 }
 
 func rightsBundlesList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -383,7 +391,7 @@ func rightsBundlesList(d *schema.ResourceData, meta interface{}) (list []string,
 			importId: false,
 		})
 	}
-	return genericResourceList(d, "vcd_rights_bundle", nil, items)
+	return genericResourceList(d, meta, "vcloud_rights_bundle", nil, items)
 }
 
 func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
@@ -406,7 +414,7 @@ func catalogList(d *schema.ResourceData, meta interface{}, resType string) (list
 			href: catalog.Catalog.HREF,
 		})
 	}
-	return genericResourceList(d, resType, []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, resType, []string{org.AdminOrg.Name}, items)
 }
 
 // catalogItemList finds either catalogItem or mediaItem
@@ -441,13 +449,13 @@ func catalogItemList(d *schema.ResourceData, meta interface{}, wantResource stri
 				Name: reference.Name,
 			}
 			switch wantResource {
-			case "vcd_catalog_item":
+			case "vcloud_catalog_item":
 				entity.HREF = catalogItem.CatalogItem.HREF
 				entity.ID = catalogItem.CatalogItem.ID
 				wanted = true
-			case "vcd_catalog_media":
+			case "vcloud_catalog_media":
 				wanted = catalogItem.CatalogItem.Entity.Type == types.MimeMediaItem
-			case "vcd_catalog_vapp_template":
+			case "vcloud_catalog_vapp_template":
 				wanted = catalogItem.CatalogItem.Entity.Type == types.MimeVAppTemplate
 			}
 			if wanted {
@@ -462,7 +470,7 @@ func catalogItemList(d *schema.ResourceData, meta interface{}, wantResource stri
 			}
 		}
 	}
-	return genericResourceList(d, "vcd_catalog_item", []string{org.AdminOrg.Name, catalogName}, items)
+	return genericResourceList(d, meta, "vcloud_catalog_item", []string{org.AdminOrg.Name, catalogName}, items)
 }
 
 // vappTemplateList finds all vApp Templates
@@ -498,7 +506,7 @@ func vappTemplateList(d *schema.ResourceData, meta interface{}) (list []string,
 		})
 	}
 
-	return genericResourceList(d, "vcd_catalog_vapp_template", []string{org.Org.Name, catalogName}, items)
+	return genericResourceList(d, meta, "vcloud_catalog_vapp_template", []string{org.Org.Name, catalogName}, items)
 }
 
 func vdcList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
@@ -518,7 +526,7 @@ func vdcList(d *schema.ResourceData, meta interface{}, resType string) (list []s
 			parent: org.AdminOrg.Name,
 		})
 	}
-	return genericResourceList(d, resType, []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, resType, []string{org.AdminOrg.Name}, items)
 }
 
 func orgUserList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -538,7 +546,7 @@ func orgUserList(d *schema.ResourceData, meta interface{}) (list []string, err e
 			parent: org.AdminOrg.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_org_user", []string{org.AdminOrg.Name}, items)
+	return genericResourceList(d, meta, "vcloud_org_user", []string{org.AdminOrg.Name}, items)
 }
 
 func networkList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -548,7 +556,7 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 	if err != nil {
 		return list, err
 	}
-	wantedType := d.Get("resource_type").(string)
+	wantedType := resourceListType(d)
 	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), vdcName)
 	if err != nil {
 		return list, err
@@ -574,9 +582,9 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 		}
 		resourceType = "network"
 		if wantedType != "network" {
-			resourceType = "vcd_network_" + networkType
+			resourceType = "vcloud_network_" + networkType
 		}
-		trueResourceType = "vcd_network_" + networkType
+		trueResourceType = "vcloud_network_" + networkType
 		if wantedType != resourceType {
 			continue
 		}
@@ -594,7 +602,7 @@ func networkList(d *schema.ResourceData, meta interface{}) (list []string, err e
 		})
 	}
 
-	return genericResourceList(d, resourceType, []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, resourceType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 // orgNetworkListV2 uses OpenAPI endpoint to query Org VDC networks and return their list
@@ -604,7 +612,7 @@ func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string,
 	if err != nil {
 		return list, err
 	}
-	wantedType := d.Get("resource_type").(string)
+	wantedType := resourceListType(d)
 	org, vdc, err := client.GetOrgAndVdc(d.Get("org").(string), vdcName)
 	if err != nil {
 		return list, err
@@ -621,11 +629,11 @@ func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string,
 		trueResourceType := ""
 		switch net.OpenApiOrgVdcNetwork.NetworkType {
 		case types.OrgVdcNetworkTypeRouted:
-			resourceType = "vcd_network_routed_v2"
+			resourceType = "vcloud_network_routed_v2"
 		case types.OrgVdcNetworkTypeIsolated:
-			resourceType = "vcd_network_isolated_v2"
+			resourceType = "vcloud_network_isolated_v2"
 		case types.OrgVdcNetworkTypeOpaque: // Used for Imported
-			resourceType = "vcd_nsxt_network_imported"
+			resourceType = "vcloud_nsxt_network_imported"
 		}
 
 		trueResourceType = resourceType
@@ -647,7 +655,7 @@ func orgNetworkListV2(d *schema.ResourceData, meta interface{}) (list []string,
 		})
 	}
 
-	return genericResourceList(d, resourceType, []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, resourceType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 func getVdcName(client *VCDClient, d *schema.ResourceData) (string, error) {
@@ -694,7 +702,7 @@ func getEdgeGatewayList(d *schema.ResourceData, meta interface{}, resType string
 			parent: vdc.Vdc.Name,
 		})
 	}
-	return genericResourceList(d, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 func distributedSwitchList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -723,7 +731,7 @@ func distributedSwitchList(d *schema.ResourceData, meta interface{}) (list []str
 			id:   dsw.BackingRef.ID,
 		})
 	}
-	return genericResourceList(d, "vcd_distributed_switch", []string{vCenter.VSphereVCenter.Name}, items)
+	return genericResourceList(d, meta, "vcloud_distributed_switch", []string{vCenter.VSphereVCenter.Name}, items)
 }
 
 func transportZoneList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -762,7 +770,7 @@ func transportZoneList(d *schema.ResourceData, meta interface{}) (list []string,
 			id:   tz.Id,
 		})
 	}
-	return genericResourceList(d, "vcd_nsxt_transport_zone", []string{manager.Name}, items)
+	return genericResourceList(d, meta, "vcloud_nsxt_transport_zone", []string{manager.Name}, items)
 }
 
 func importablePortGroupList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -791,7 +799,7 @@ func importablePortGroupList(d *schema.ResourceData, meta interface{}) (list []s
 			id:   pg.VcenterImportableDvpg.BackingRef.ID,
 		})
 	}
-	return genericResourceList(d, "vcd_importable_port_group", []string{vCenter.VSphereVCenter.Name}, items)
+	return genericResourceList(d, meta, "vcloud_importable_port_group", []string{vCenter.VSphereVCenter.Name}, items)
 }
 
 func networkPoolList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -811,7 +819,7 @@ func networkPoolList(d *schema.ResourceData, meta interface{}) (list []string, e
 			href: np.HREF,
 		})
 	}
-	return genericResourceList(d, "vcd_network_pool", nil, items)
+	return genericResourceList(d, meta, "vcloud_network_pool", nil, items)
 }
 
 func nsxtManagerList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -832,7 +840,7 @@ func nsxtManagerList(d *schema.ResourceData, meta interface{}) (list []string, e
 		})
 
 	}
-	return genericResourceList(d, "vcd_nsxt_manager", nil, items)
+	return genericResourceList(d, meta, "vcloud_nsxt_manager", nil, items)
 }
 
 func vcenterList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -857,7 +865,7 @@ func vcenterList(d *schema.ResourceData, meta interface{}) (list []string, err e
 		})
 
 	}
-	return genericResourceList(d, "vcd_vcenter", nil, items)
+	return genericResourceList(d, meta, "vcloud_vcenter", nil, items)
 }
 
 func getNsxtEdgeGatewayList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -925,7 +933,7 @@ func getNsxtEdgeGatewayList(d *schema.ResourceData, meta interface{}) (list []st
 			parent: parentName,
 		})
 	}
-	return genericResourceList(d, "vcd_nsxt_edgegateway", ancestors, items)
+	return genericResourceList(d, meta, "vcloud_nsxt_edgegateway", ancestors, items)
 }
 
 func diskList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -953,7 +961,7 @@ func diskList(d *schema.ResourceData, meta interface{}) (list []string, err erro
 			parent: vdc.Vdc.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_independent_disk", []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, "vcloud_independent_disk", []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 func vappList(d *schema.ResourceData, meta interface{}, resType string) (list []string, err error) {
@@ -981,7 +989,7 @@ func vappList(d *schema.ResourceData, meta interface{}, resType string) (list []
 			}
 		}
 	}
-	return genericResourceList(d, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, resType, []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []string, err error) {
@@ -1008,18 +1016,31 @@ func vmList(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (list []s
 		if vappName != "" && vappName != vm.ContainerName {
 			continue
 		}
-		items = append(items, resourceRef{
-			name:     vm.Name,
-			id:       "urn:vcloud:vm:" + extractUuid(vm.HREF),
-			href:     vm.HREF,
-			parent:   vm.ContainerName,           // name of the hidden vApp
-			importId: vmType == standaloneVmType, // import should use entity ID rather than name
-		})
+		item := resourceRef{
+			name:   vm.Name,
+			id:     "urn:vcloud:vm:" + extractUuid(vm.HREF),
+			href:   vm.HREF,
+			parent: vm.ContainerName, // name of the vApp, hidden for standalone VMs
+		}
+		if vm.AutoNature {
+			// import should use entity ID rather than name
+			item.resourceType = "vcloud_vm"
+			item.importId = true
+		} else {
+			// Without a given vApp, the import ID includes the vApp of each VM
+			item.resourceType = "vcloud_vapp_vm"
+			item.importParent = vappName == ""
+		}
+		items = append(items, item)
 	}
+	ancestors := []string{org.Org.Name, vdc.Vdc.Name}
 	if vmType == vappVmType {
-		return genericResourceList(d, "vcd_vapp_vm", []string{org.Org.Name, vdc.Vdc.Name, vappName}, items)
+		if vappName != "" {
+			ancestors = append(ancestors, vappName)
+		}
+		return genericResourceList(d, meta, "vcloud_vapp_vm", ancestors, items)
 	}
-	return genericResourceList(d, "vcd_vm", []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, "vcloud_vm", ancestors, items)
 }
 
 func vappNetworkList(d *schema.ResourceData, vnt vappNetworkType, meta interface{}) (list []string, err error) {
@@ -1053,15 +1074,20 @@ func vappNetworkList(d *schema.ResourceData, vnt vappNetworkType, meta interface
 
 	var items []resourceRef
 	for _, net := range networks {
+		// vApp networks and vApp org networks are imported by name
+		resourceType := "vcloud_vapp_network"
+		if net.IsLinked {
+			resourceType = "vcloud_vapp_org_network"
+		}
 		items = append(items, resourceRef{
-			name:     net.Name,
-			id:       extractUuid(net.HREF),
-			href:     net.HREF,
-			parent:   vappName,
-			importId: true,
+			name:         net.Name,
+			id:           extractUuid(net.HREF),
+			href:         net.HREF,
+			parent:       vappName,
+			resourceType: resourceType,
 		})
 	}
-	return genericResourceList(d, "vcd_vapp_network", []string{org.Org.Name, vdc.Vdc.Name, vappName}, items)
+	return genericResourceList(d, meta, "vcloud_vapp_network", []string{org.Org.Name, vdc.Vdc.Name, vappName}, items)
 }
 
 func vdcTemplateList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1107,7 +1133,7 @@ func vdcTemplateList(d *schema.ResourceData, meta interface{}) (list []string, e
 		}
 		ancestors = []string{org.Org.Name}
 	}
-	return genericResourceList(d, "vcd_org_vdc_template", ancestors, items)
+	return genericResourceList(d, meta, "vcloud_org_vdc_template", ancestors, items)
 }
 
 func nsxtAlbServiceEngineGroup(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1125,7 +1151,7 @@ func nsxtAlbServiceEngineGroup(d *schema.ResourceData, meta interface{}) (list [
 			parent: "System",
 		}
 	}
-	return genericResourceList(d, "vcd_nsxt_alb_service_engine_group", nil, items)
+	return genericResourceList(d, meta, "vcloud_nsxt_alb_service_engine_group", nil, items)
 }
 
 func nsxtAlbServiceEngineGroupAssignment(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1168,21 +1194,25 @@ func nsxtAlbServiceEngineGroupAssignment(d *schema.ResourceData, meta interface{
 		}
 	}
 
-	return genericResourceList(d, "vcd_nsxt_alb_edgegateway_service_engine_group", []string{org.Org.Name, vdc.Vdc.Name, nsxtEdgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_nsxt_alb_edgegateway_service_engine_group", []string{org.Org.Name, vdc.Vdc.Name, nsxtEdgeGateway.EdgeGateway.Name}, items)
 }
 
-func genericResourceList(d *schema.ResourceData, resType string, ancestors []string, refs []resourceRef) (list []string, err error) {
+func genericResourceList(d *schema.ResourceData, meta interface{}, resType string, ancestors []string, refs []resourceRef) (list []string, err error) {
 	listMode := d.Get("list_mode").(string)
 	nameIdSeparator := d.Get("name_id_separator").(string)
 	importFile := d.Get("import_file_name").(string)
+	skeletonFile := d.Get("hcl_skeleton_file_name").(string)
 	nameRegex := d.Get("name_regex").(string)
+	generatedHeader := fmt.Sprintf("# Generated by vcloud_resource_list - %s\n", time.Now().Format(time.RFC3339))
 	var importData strings.Builder
-	importData.WriteString(fmt.Sprintf("# Generated by vcd_resource_list - %s\n", time.Now().Format(time.RFC3339)))
+	var skeletonData strings.Builder
+	importData.WriteString(generatedHeader)
+	skeletonData.WriteString(generatedHeader)
 	var reName *regexp.Regexp
 	if nameRegex != "" {
 		reName, err = regexp.Compile(nameRegex)
 		if err != nil {
-			return nil, fmt.Errorf("[vcd_resource_list - %s] error compiling regular expression given with 'name_regex' '%s': %s",
+			return nil, fmt.Errorf("[vcloud_resource_list - %s] error compiling regular expression given with 'name_regex' '%s': %s",
 				d.Get("name").(string), nameRegex, err)
 		}
 	}
@@ -1221,55 +1251,107 @@ func genericResourceList(d *schema.ResourceData, resType string, ancestors []str
 			}
 		case "href":
 			list = append(list, ref.href)
-		case "import":
+		case "import", "import_block":
 			identifier := ref.name
 			if ref.importId {
 				identifier = ref.id
 			}
-			sanitizedName := ref.name
-			illegalHclNameCharsRegex := regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
-			if illegalHclNameCharsRegex.MatchString(ref.name) {
-				// Names can have special characters in VCD, but must not have in HCL resource names
-				sanitizedName = illegalHclNameCharsRegex.ReplaceAllString(ref.name, "_")
+			sanitizedName := sanitizedHclName(ref.name)
+			importId := identifier
+			ancestorsText := ""
+			importAncestors := ancestors
+			if ref.importParent {
+				importAncestors = append(append([]string{}, ancestors...), ref.parent)
+			}
+			if len(importAncestors) > 0 {
+				ancestorsText = strings.Join(importAncestors, ImportSeparator) + ImportSeparator
+				importId = ancestorsText + identifier
 			}
-			list = append(list, fmt.Sprintf("terraform import %s.%s '%s%s%s'",
-				resourceType,
-				sanitizedName,
-				strings.Join(ancestors, ImportSeparator),
-				ImportSeparator,
-				identifier))
+			address := importAddress(resourceType, ref.name, ref.id)
+			block := importBlock(address, importId)
 
-			ancestorsText := ""
-			if len(ancestors) > 0 {
-				ancestorsText = strings.Join(ancestors, ImportSeparator) + ImportSeparator
+			if listMode == "import" {
+				// The import commands keep the address of the previous versions, without the tail of the ID
+				list = append(list, importCommand(importAddress(resourceType, ref.name, ""), importId))
+			} else {
+				list = append(list, block)
 			}
+
 			importData.WriteString(fmt.Sprintf("# Import directive for %s %s%s \n", resourceType, ancestorsText, sanitizedName))
-			importData.WriteString("import {\n")
-			importData.WriteString(fmt.Sprintf("  to = %s.%s-%s\n", resourceType, sanitizedName, idTail(ref.id)))
-			if len(ancestors) > 0 {
-				importData.WriteString(fmt.Sprintf("  id = \"%s%s%s\"\n",
-					strings.Join(ancestors, ImportSeparator), ImportSeparator, identifier))
-			} else {
-				importData.WriteString(fmt.Sprintf("  id = \"%s\"\n", identifier))
+			importData.WriteString(block + "\n")
+
+			if skeletonFile != "" {
+				if _, isResource := globalResourceMap[resourceType]; !isResource {
+					skeletonData.WriteString(fmt.Sprintf("# %s %s is not managed by a resource\n\n", resourceType, importId))
+					continue
+				}
+				skeleton, err := resourceSkeleton(meta, resourceType, address, importId)
+				if err != nil {
+					return nil, fmt.Errorf("[vcloud_resource_list - %s] error building the definition of %s: %s",
+						d.Get("name").(string), address, err)
+				}
+				skeletonData.WriteString(skeleton + "\n")
 			}
-			importData.WriteString("}\n\n")
 		}
 	}
 
-	if importFile != "" && listMode == "import" {
-		err = os.WriteFile(importFile, []byte(importData.String()), 0600)
-		if err != nil {
-			return nil, err
+	if listMode == "import" || listMode == "import_block" {
+		if importFile != "" {
+			err = os.WriteFile(importFile, []byte(importData.String()), 0600)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if skeletonFile != "" {
+			err = os.WriteFile(skeletonFile, []byte(skeletonData.String()), 0600)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	return list, nil
 }
 
+// illegalHclNameCharsRegex matches the characters that names can have in VCD, but not in HCL resource names
+var illegalHclNameCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
+
+// sanitizedHclName replaces the characters of a VCD name that are not allowed in HCL resource names
+func sanitizedHclName(name string) string {
+	return illegalHclNameCharsRegex.ReplaceAllString(name, "_")
+}
+
+// importAddress returns the address of a listed resource, used in its import block and resource definition.
+// The tail of the ID, when given, keeps apart the resources with the same name. HCL names must start with
+// a letter or an underscore
+func importAddress(resourceType, name, id string) string {
+	var parts []string
+	for _, part := range []string{sanitizedHclName(name), idTail(id)} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	label := strings.Join(parts, "-")
+	if label == "" || !regexp.MustCompile(`^[a-zA-Z_]`).MatchString(label) {
+		label = "_" + label
+	}
+	return resourceType + "." + label
+}
+
+// importCommand returns the terraform import command of the resource at the given address
+func importCommand(address, importId string) string {
+	return fmt.Sprintf("terraform import %s '%s'", address, importId)
+}
+
+// importBlock returns the import block of the resource at the given address
+func importBlock(address, importId string) string {
+	return fmt.Sprintf("import {\n  to = %s\n  id = \"%s\"\n}\n", address, importId)
+}
+
 func idTail(id string) string {
 	if id == "" {
 		return ""
 	}
-	reTail := regexp.MustCompile(`([a-zA-z0-9]+)$`)
+	reTail := regexp.MustCompile(`([a-zA-Z0-9]+)$`)
 	return reTail.FindString(id)
 }
 
@@ -1312,7 +1394,7 @@ func lbServerPoolList(d *schema.ResourceData, meta interface{}) (list []string,
 		})
 	}
 
-	return genericResourceList(d, "vcd_lb_server_pool", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_lb_server_pool", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func lbServiceMonitorList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1334,7 +1416,7 @@ func lbServiceMonitorList(d *schema.ResourceData, meta interface{}) (list []stri
 			parent: edgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_service_monitor", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_lb_service_monitor", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func lbVirtualServerList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1356,7 +1438,7 @@ func lbVirtualServerList(d *schema.ResourceData, meta interface{}) (list []strin
 			parent: edgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_virtual_server", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_lb_virtual_server", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func nsxvFirewallList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1378,7 +1460,7 @@ func nsxvFirewallList(d *schema.ResourceData, meta interface{}) (list []string,
 			parent: edgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_nsxv_firewall_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_nsxv_firewall_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func lbAppRuleList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1400,7 +1482,7 @@ func lbAppRuleList(d *schema.ResourceData, meta interface{}) (list []string, err
 			parent: edgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_app_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_lb_app_rule", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func lbAppProfileList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1422,7 +1504,7 @@ func lbAppProfileList(d *schema.ResourceData, meta interface{}) (list []string,
 			parent: edgeGateway.EdgeGateway.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_lb_app_profile", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_lb_app_profile", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
 }
 
 func ipsetList(d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1450,7 +1532,7 @@ func ipsetList(d *schema.ResourceData, meta interface{}) (list []string, err err
 			parent: vdc.Vdc.Name,
 		})
 	}
-	return genericResourceList(d, "vcd_ipset", []string{org.Org.Name, vdc.Vdc.Name}, items)
+	return genericResourceList(d, meta, "vcloud_ipset", []string{org.Org.Name, vdc.Vdc.Name}, items)
 }
 
 func nsxvNatRuleList(natType string, d *schema.ResourceData, meta interface{}) (list []string, err error) {
@@ -1475,7 +1557,17 @@ func nsxvNatRuleList(natType string, d *schema.ResourceData, meta interface{}) (
 			})
 		}
 	}
-	return genericResourceList(d, "vcd_lb_app_profile", []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+	return genericResourceList(d, meta, "vcloud_nsxv_"+natType, []string{orgName, vdcName, edgeGateway.EdgeGateway.Name}, items)
+}
+
+// resourceListType returns the resource type requested by a vcloud_resource_list data source. Types
+// using the legacy "vcd_" prefix are translated to the registered "vcloud_" names.
+func resourceListType(d *schema.ResourceData) string {
+	requested := d.Get("resource_type").(string)
+	if strings.HasPrefix(requested, "vcd_") {
+		return "vcloud_" + strings.TrimPrefix(requested, "vcd_")
+	}
+	return requested
 }
 
 func getResourcesList() ([]string, error) {
@@ -1491,117 +1583,117 @@ func getResourcesList() ([]string, error) {
 
 func datasourceVcdResourceListRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
-	requested := d.Get("resource_type").(string)
+	requested := resourceListType(d)
 	var err error
 	var list []string
 	switch requested {
 	// Note: do not try to get the data sources list, as it would result in a circular reference
 	case "resource", "resources":
 		list, err = getResourcesList()
-	case "vcd_multisite_site_association":
-		list, err = getSiteAssociationList(d, meta, "vcd_multisite_site_association")
-	case "vcd_multisite_org_association":
-		list, err = getOrgAssociationList(d, meta, "vcd_multisite_org_association")
-	case "vcd_org", "org", "orgs":
-		list, err = getOrgList(d, meta, "vcd_org")
-	case "vcd_org_ldap", "vcd_org_saml":
+	case "vcloud_multisite_site_association":
+		list, err = getSiteAssociationList(d, meta, "vcloud_multisite_site_association")
+	case "vcloud_multisite_org_association":
+		list, err = getOrgAssociationList(d, meta, "vcloud_multisite_org_association")
+	case "vcloud_org", "org", "orgs":
+		list, err = getOrgList(d, meta, "vcloud_org")
+	case "vcloud_org_ldap", "vcloud_org_saml":
 		list, err = getOrgList(d, meta, requested)
-	case "vcd_provider_vdc", "provider_vdc":
+	case "vcloud_provider_vdc", "provider_vdc":
 		list, err = getPvdcList(d, meta)
-	case "vcd_distributed_switch":
+	case "vcloud_distributed_switch":
 		list, err = distributedSwitchList(d, meta)
-	case "vcd_nsxt_transport_zone":
+	case "vcloud_nsxt_transport_zone":
 		list, err = transportZoneList(d, meta)
-	case "vcd_importable_port_group":
+	case "vcloud_importable_port_group":
 		list, err = importablePortGroupList(d, meta)
-	case "vcd_network_pool":
+	case "vcloud_network_pool":
 		list, err = networkPoolList(d, meta)
-	case "vcd_vcenter":
+	case "vcloud_vcenter":
 		list, err = vcenterList(d, meta)
-	case "vcd_nsxt_manager":
+	case "vcloud_nsxt_manager":
 		list, err = nsxtManagerList(d, meta)
-	case "vcd_external_network", "external_network", "external_networks":
+	case "vcloud_external_network", "external_network", "external_networks":
 		list, err = externalNetworkList(d, meta)
-	case "vcd_org_vdc", "vdc", "vdcs":
-		list, err = vdcList(d, meta, "vcd_org_vdc")
-	case "vcd_vdc_group":
+	case "vcloud_org_vdc", "vdc", "vdcs":
+		list, err = vdcList(d, meta, "vcloud_org_vdc")
+	case "vcloud_vdc_group":
 		list, err = getVdcGroups(d, meta)
-	case "vcd_org_vdc_access_control":
-		list, err = vdcList(d, meta, "vcd_org_vdc_access_control")
-	case "vcd_catalog", "catalog", "catalogs", "vcd_subscribed_catalog":
-		list, err = catalogList(d, meta, "vcd_catalog")
-	case "vcd_catalog_access_control":
-		list, err = catalogList(d, meta, "vcd_catalog_access_control")
-	case "vcd_catalog_item", "catalog_item", "catalog_items", "catalogitem", "catalogitems":
-		list, err = catalogItemList(d, meta, "vcd_catalog_item")
-	case "vcd_catalog_vapp_template", "vapp_template":
+	case "vcloud_org_vdc_access_control":
+		list, err = vdcList(d, meta, "vcloud_org_vdc_access_control")
+	case "vcloud_catalog", "catalog", "catalogs", "vcloud_subscribed_catalog":
+		list, err = catalogList(d, meta, "vcloud_catalog")
+	case "vcloud_catalog_access_control":
+		list, err = catalogList(d, meta, "vcloud_catalog_access_control")
+	case "vcloud_catalog_item", "catalog_item", "catalog_items", "catalogitem", "catalogitems":
+		list, err = catalogItemList(d, meta, "vcloud_catalog_item")
+	case "vcloud_catalog_vapp_template", "vapp_template":
 		list, err = vappTemplateList(d, meta)
-	case "vcd_catalog_media", "catalog_media", "media_items", "mediaitems", "mediaitem":
-		list, err = catalogItemList(d, meta, "vcd_catalog_media")
-	case "vcd_independent_disk", "disk", "disks":
+	case "vcloud_catalog_media", "catalog_media", "media_items", "mediaitems", "mediaitem":
+		list, err = catalogItemList(d, meta, "vcloud_catalog_media")
+	case "vcloud_independent_disk", "disk", "disks":
 		list, err = diskList(d, meta)
-	case "vcd_vapp", "vapp", "vapps", "vcd_cloned_vapp":
-		list, err = vappList(d, meta, "vcd_vapp")
-	case "vcd_vapp_access_control":
-		list, err = vappList(d, meta, "vcd_vapp_access_control")
-	case "vcd_vapp_vm", "vapp_vm", "vapp_vms":
+	case "vcloud_vapp", "vapp", "vapps", "vcloud_cloned_vapp":
+		list, err = vappList(d, meta, "vcloud_vapp")
+	case "vcloud_vapp_access_control":
+		list, err = vappList(d, meta, "vcloud_vapp_access_control")
+	case "vcloud_vapp_vm", "vapp_vm", "vapp_vms":
 		list, err = vmList(d, meta, vappVmType)
-	case "vcd_vapp_network", "vapp_network", "vapp_networks":
+	case "vcloud_vapp_network", "vapp_network", "vapp_networks":
 		list, err = vappNetworkList(d, vntVappNetwork, meta)
-	case "vcd_vapp_org_network", "vapp_org_network", "vapp_org_networks":
+	case "vcloud_vapp_org_network", "vapp_org_network", "vapp_org_networks":
 		list, err = vappNetworkList(d, vntVappOrgNetwork, meta)
-	case "vcd_vapp_all_network", "vapp_all_network", "vapp_all_networks":
+	case "vcloud_vapp_all_network", "vapp_all_network", "vapp_all_networks":
 		list, err = vappNetworkList(d, vntVappAllNetworks, meta)
-	case "vcd_vm", "standalone_vm":
+	case "vcloud_vm", "standalone_vm":
 		list, err = vmList(d, meta, standaloneVmType)
-	case "vcd_all_vm", "vm", "vms":
+	case "vcloud_all_vm", "vm", "vms":
 		list, err = vmList(d, meta, "all")
-	case "vcd_org_user", "org_user", "user", "users":
+	case "vcloud_org_user", "org_user", "user", "users":
 		list, err = orgUserList(d, meta)
-	case "vcd_edgegateway", "edge_gateway", "edge", "edgegateway":
-		list, err = getEdgeGatewayList(d, meta, "vcd_edgegateway")
-	case "vcd_edgegateway_settings":
-		list, err = getEdgeGatewayList(d, meta, "vcd_edgegateway_settings")
-	case "vcd_nsxt_edgegateway", "nsxt_edge_gateway", "nsxt_edge", "nsxt_edgegateway":
+	case "vcloud_edgegateway", "edge_gateway", "edge", "edgegateway":
+		list, err = getEdgeGatewayList(d, meta, "vcloud_edgegateway")
+	case "vcloud_edgegateway_settings":
+		list, err = getEdgeGatewayList(d, meta, "vcloud_edgegateway_settings")
+	case "vcloud_nsxt_edgegateway", "nsxt_edge_gateway", "nsxt_edge", "nsxt_edgegateway":
 		list, err = getNsxtEdgeGatewayList(d, meta)
-	case "vcd_lb_server_pool", "lb_server_pool":
+	case "vcloud_lb_server_pool", "lb_server_pool":
 		list, err = lbServerPoolList(d, meta)
-	case "vcd_lb_service_monitor", "lb_service_monitor":
+	case "vcloud_lb_service_monitor", "lb_service_monitor":
 		list, err = lbServiceMonitorList(d, meta)
-	case "vcd_lb_virtual_server", "lb_virtual_server":
+	case "vcloud_lb_virtual_server", "lb_virtual_server":
 		list, err = lbVirtualServerList(d, meta)
-	case "vcd_lb_app_rule", "lb_app_rule":
+	case "vcloud_lb_app_rule", "lb_app_rule":
 		list, err = lbAppRuleList(d, meta)
-	case "vcd_lb_app_profile", "lb_app_profile":
+	case "vcloud_lb_app_profile", "lb_app_profile":
 		list, err = lbAppProfileList(d, meta)
-	case "vcd_nsxv_firewall_rule", "nsxv_firewall_rule":
+	case "vcloud_nsxv_firewall_rule", "nsxv_firewall_rule":
 		list, err = nsxvFirewallList(d, meta)
-	case "vcd_ipset", "ipset":
+	case "vcloud_ipset", "ipset":
 		list, err = ipsetList(d, meta)
-	case "vcd_nsxv_dnat", "nsxv_dnat":
+	case "vcloud_nsxv_dnat", "nsxv_dnat":
 		list, err = nsxvNatRuleList("dnat", d, meta)
-	case "vcd_nsxv_snat", "nsxv_snat":
+	case "vcloud_nsxv_snat", "nsxv_snat":
 		list, err = nsxvNatRuleList("snat", d, meta)
-	case "vcd_network_isolated", "vcd_network_direct", "vcd_network_routed",
+	case "vcloud_network_isolated", "vcloud_network_direct", "vcloud_network_routed",
 		"network", "networks", "network_direct", "network_routed", "network_isolated":
 		list, err = networkList(d, meta)
-	case "vcd_network_routed_v2", "vcd_network_isolated_v2", "vcd_nsxt_network_imported":
+	case "vcloud_network_routed_v2", "vcloud_network_isolated_v2", "vcloud_nsxt_network_imported":
 		list, err = orgNetworkListV2(d, meta)
-	case "vcd_right", "rights":
+	case "vcloud_right", "rights":
 		list, err = rightsList(d, meta)
-	case "vcd_rights_bundle", "rights_bundle":
+	case "vcloud_rights_bundle", "rights_bundle":
 		list, err = rightsBundlesList(d, meta)
-	case "vcd_role", "roles":
+	case "vcloud_role", "roles":
 		list, err = rolesList(d, meta)
-	case "vcd_global_role", "global_roles":
+	case "vcloud_global_role", "global_roles":
 		list, err = globalRolesList(d, meta)
-	case "vcd_library_certificate":
+	case "vcloud_library_certificate":
 		list, err = libraryCertificateList(d, meta)
-	case "vcd_org_vdc_template":
+	case "vcloud_org_vdc_template":
 		list, err = vdcTemplateList(d, meta)
-	case "vcd_nsxt_alb_service_engine_group":
+	case "vcloud_nsxt_alb_service_engine_group":
 		list, err = nsxtAlbServiceEngineGroup(d, meta)
-	case "vcd_nsxt_alb_edgegateway_service_engine_group":
+	case "vcloud_nsxt_alb_edgegateway_service_engine_group":
 		list, err = nsxtAlbServiceEngineGroupAssignment(d, meta)
 
 		//// place holder to remind of what needs to be implemented
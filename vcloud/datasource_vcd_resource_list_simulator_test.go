@@ -0,0 +1,67 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSimulatorResourceListImportBlocks checks the import blocks and resource definitions of a list of
+// several resource types
+func TestSimulatorResourceListImportBlocks(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	resource := datasourceVcdResourceList()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "web-vapp")
+	webId := sim.AddVm(simulatorOrg, simulatorVdc, "web-vapp", "web-1")
+	sim.AddVapp(simulatorOrg, simulatorVdc, "db-vapp")
+	dbId := sim.AddVm(simulatorOrg, simulatorVdc, "db-vapp", "db-1")
+
+	skeletonFile := filepath.Join(t.TempDir(), "vms.tf")
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":                    simulatorOrg,
+		"vdc":                    simulatorVdc,
+		"name":                   "vms",
+		"resource_type":          "vcloud_all_vm",
+		"list_mode":              "import_block",
+		"hcl_skeleton_file_name": skeletonFile,
+	})
+	if diags := resource.ReadContext(context.Background(), d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading the list: %v", diags)
+	}
+
+	// Without a vApp in the list, the import ID of a vApp VM includes its vApp
+	want := map[string]string{
+		importAddress("vcloud_vapp_vm", "web-1", webId): simulatorOrg + "." + simulatorVdc + ".web-vapp.web-1",
+		importAddress("vcloud_vapp_vm", "db-1", dbId):   simulatorOrg + "." + simulatorVdc + ".db-vapp.db-1",
+	}
+	list := d.Get("list").([]interface{})
+	if len(list) != len(want) {
+		t.Fatalf("expected %d import blocks, got %v", len(want), list)
+	}
+	for address, importId := range want {
+		block := importBlock(address, importId)
+		found := false
+		for _, item := range list {
+			found = found || item.(string) == block
+		}
+		if !found {
+			t.Errorf("expected import block %q, got %v", block, list)
+		}
+	}
+
+	contents, err := os.ReadFile(skeletonFile)
+	if err != nil {
+		t.Fatalf("error reading the resource definitions: %s", err)
+	}
+	for address := range want {
+		definition := `resource "vcloud_vapp_vm" "` + strings.TrimPrefix(address, "vcloud_vapp_vm.") + `"`
+		if !strings.Contains(string(contents), definition) {
+			t.Errorf("expected %s in the resource definitions, got:\n%s", definition, contents)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package vcloud
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -30,7 +31,7 @@ func TestAccVcdDatasourceResourceList(t *testing.T) {
 	preTestChecks(t)
 
 	var lists = []listDef{
-		{name: "resources", resourceType: "resources", knownItem: "vcd_org"},
+		{name: "resources", resourceType: "resources", knownItem: "vcloud_org"},
 		{name: "global_role", resourceType: "vcd_global_role", knownItem: "vApp Author"},
 		{name: "rights_bundle", resourceType: "vcd_rights_bundle", knownItem: "Default Rights Bundle"},
 		{name: "right", resourceType: "vcd_right", knownItem: "Catalog: Change Owner"},
@@ -491,8 +492,8 @@ func TestAccVcdDatasourceResourceListSpecialChars(t *testing.T) {
 			{
 				Config: configText,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr(resourceList, fmt.Sprintf("list.%d", lastCatalog), fmt.Sprintf("terraform import vcd_catalog.f_o_o '%s.f o o'", testConfig.VCD.Org)),
-					resource.TestCheckResourceAttr(resourceList, fmt.Sprintf("list.%d", lastCatalog+1), fmt.Sprintf("terraform import vcd_catalog.b_a_r '%s.b%%a$r'", testConfig.VCD.Org)),
+					resource.TestCheckResourceAttr(resourceList, fmt.Sprintf("list.%d", lastCatalog), fmt.Sprintf("terraform import vcloud_catalog.f_o_o '%s.f o o'", testConfig.VCD.Org)),
+					resource.TestCheckResourceAttr(resourceList, fmt.Sprintf("list.%d", lastCatalog+1), fmt.Sprintf("terraform import vcloud_catalog.b_a_r '%s.b%%a$r'", testConfig.VCD.Org)),
 				),
 			},
 		},
@@ -527,3 +528,82 @@ data "vcd_resource_list" "rs" {
   depends_on = [vcd_catalog.cat1, vcd_catalog.cat2]
 }
 `
+
+// TestAccVcdDatasourceResourceListImportBlock checks that the "import_block" list mode returns import blocks
+// and that the HCL skeleton file contains the definition of the listed VDC
+func TestAccVcdDatasourceResourceListImportBlock(t *testing.T) {
+	preTestChecks(t)
+
+	importFileName := "import-block-vdc.tf"
+	skeletonFileName := "skeleton-vdc.tf"
+	var params = StringMap{
+		"Org":          testConfig.VCD.Org,
+		"Vdc":          testConfig.Nsxt.Vdc,
+		"ImportFile":   importFileName,
+		"SkeletonFile": skeletonFileName,
+	}
+	testParamsNotEmpty(t, params)
+	configText := templateFill(testAccVcdDatasourceResourceListImportBlock, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s", configText)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceList := "data.vcloud_resource_list.vdc"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			for _, fileName := range []string{importFileName, skeletonFileName} {
+				if fileExists(fileName) {
+					err := os.Remove(fileName)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceList, "list.#", "1"),
+					resource.TestMatchResourceAttr(resourceList, "list.0",
+						regexp.MustCompile(`(?s)^import \{\n  to = vcloud_org_vdc\.\S+\n  id = "`+regexp.QuoteMeta(testConfig.VCD.Org+ImportSeparator+testConfig.Nsxt.Vdc)+`"\n\}`)),
+					checkImportFile(importFileName, true),
+					checkFileContains(skeletonFileName, `resource "vcloud_org_vdc"`),
+					checkFileContains(skeletonFileName, fmt.Sprintf("%q", testConfig.Nsxt.Vdc)),
+				),
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+// checkFileContains returns an error if the file does not contain the given text
+func checkFileContains(fileName, text string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		contents, err := os.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(contents), text) {
+			return fmt.Errorf("file %s does not contain '%s'", fileName, text)
+		}
+		return nil
+	}
+}
+
+const testAccVcdDatasourceResourceListImportBlock = `
+data "vcloud_resource_list" "vdc" {
+  org                    = "{{.Org}}"
+  name                   = "vdc"
+  resource_type          = "vcloud_org_vdc"
+  name_regex             = "^{{.Vdc}}$"
+  list_mode              = "import_block"
+  import_file_name       = "{{.ImportFile}}"
+  hcl_skeleton_file_name = "{{.SkeletonFile}}"
+}
+`
@@ -0,0 +1,51 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"testing"
+)
+
+// Test_importAddress checks that listed resources get valid and distinct HCL addresses
+func Test_importAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		resName      string
+		id           string
+		want         string
+	}{
+		{name: "URN", resourceType: "vcloud_org_vdc", resName: "vdc1", id: "urn:vcloud:vdc:8a2d3f1b-9e7c-4b0a-a1d2-8a2d3f1b2c4e", want: "vcloud_org_vdc.vdc1-8a2d3f1b2c4e"},
+		{name: "SpecialChars", resourceType: "vcloud_catalog", resName: "b%a$r", id: "urn:vcloud:catalog:1234", want: "vcloud_catalog.b_a_r-1234"},
+		{name: "Spaces", resourceType: "vcloud_catalog", resName: "f o o", id: "urn:vcloud:catalog:1234", want: "vcloud_catalog.f_o_o-1234"},
+		{name: "NoId", resourceType: "vcloud_right", resName: "Catalog: View", id: "", want: "vcloud_right.Catalog_View"},
+		{name: "NoName", resourceType: "vcloud_nsxv_dnat", resName: "", id: "196609", want: "vcloud_nsxv_dnat._196609"},
+		{name: "LeadingDigit", resourceType: "vcloud_vapp", resName: "1st-vapp", id: "urn:vcloud:vapp:abcd", want: "vcloud_vapp._1st-vapp-abcd"},
+		{name: "NoNameNoId", resourceType: "vcloud_vapp", want: "vcloud_vapp._"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importAddress(tt.resourceType, tt.resName, tt.id); got != tt.want {
+				t.Errorf("importAddress() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_importCommandAndBlock checks that the import command keeps the address without the tail of the ID, and that
+// the import block uses the address with it
+func Test_importCommandAndBlock(t *testing.T) {
+	importId := "my-org.f o o"
+
+	command := importCommand(importAddress("vcloud_catalog", "f o o", ""), importId)
+	wantCommand := "terraform import vcloud_catalog.f_o_o 'my-org.f o o'"
+	if command != wantCommand {
+		t.Errorf("importCommand() = %s, want %s", command, wantCommand)
+	}
+
+	block := importBlock(importAddress("vcloud_catalog", "f o o", "urn:vcloud:catalog:1234"), importId)
+	wantBlock := "import {\n  to = vcloud_catalog.f_o_o-1234\n  id = \"my-org.f o o\"\n}\n"
+	if block != wantBlock {
+		t.Errorf("importBlock() = %q, want %q", block, wantBlock)
+	}
+}
@@ -0,0 +1,153 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdVapps() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdVappsRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"filter": listFilterSchema("vApps", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"date":       elementDate,
+				"metadata":   elementMetadata,
+			}),
+			"vapps": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "vApps matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp HREF",
+						},
+						"vdc_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VDC containing the vApp",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vApp status, like POWERED_ON or POWERED_OFF",
+						},
+						"deployed": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the vApp is deployed",
+						},
+						"vm_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of VMs in the vApp",
+						},
+						"cpus": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of virtual CPUs of all the VMs in the vApp",
+						},
+						"memory": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Memory in MB allocated to all the VMs in the vApp",
+						},
+						"storage_kb": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Storage in KB used by the vApp",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the user owning the vApp",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Timestamp of when the vApp was created",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdVappsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	queryType := types.QtVapp
+	if vcdClient.Client.IsSysAdmin {
+		queryType = types.QtAdminVapp
+	}
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		return vdc.SearchByFilter(queryType, "vdc", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, queryType, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving vApps: %s", err)
+	}
+
+	vapps := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		vapp, ok := queryItem.(govcd.QueryVapp)
+		if !ok {
+			continue
+		}
+		vapps = append(vapps, map[string]interface{}{
+			"id":         "urn:vcloud:vapp:" + extractUuid(vapp.HREF),
+			"name":       vapp.Name,
+			"href":       vapp.HREF,
+			"vdc_name":   vapp.VdcName,
+			"status":     vapp.Status,
+			"deployed":   vapp.Deployed,
+			"vm_count":   vapp.NumberOfVMs,
+			"cpus":       vapp.NumberOfCPUs,
+			"memory":     vapp.MemoryAllocationMB,
+			"storage_kb": vapp.StorageKB,
+			"owner_name": vapp.OwnerName,
+			"created":    vapp.CreationDate,
+		})
+	}
+	err = d.Set("vapps", vapps)
+	if err != nil {
+		return diag.Errorf("error setting vApps: %s", err)
+	}
+
+	d.SetId(listFilterId(vdc.Vdc.ID, d.Get("filter")))
+	return nil
+}
@@ -163,6 +163,7 @@ func datasourceVdcGroup() *schema.Resource {
 					},
 				},
 			},
+			"metadata_entry": openApiMetadataEntryDatasourceSchema("VDC Group"),
 		},
 	}
 }
@@ -195,5 +196,5 @@ func datasourceVcdVdcGroupRead(_ context.Context, d *schema.ResourceData, meta i
 		return diag.Errorf("[VDC group read] : %s", err)
 	}
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_vdc_group", newOpenApiEntityMetadata(vcdClient, "vcd_vdc_group", d.Id(), vdcGroup.VdcGroup.Name))
 }
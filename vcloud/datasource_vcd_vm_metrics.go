@@ -0,0 +1,277 @@
+package vcloud
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// VM metrics are read from the 'metrics/current' and 'metrics/historic' links of the VM. A GET returns
+// all the metrics, while a POST with a spec selects the metrics by pattern and, for historic
+// metrics, the time period. The go-vcloud-director SDK does not wrap these requests.
+const (
+	vmMetricsCurrentPath      = "/metrics/current"
+	vmMetricsHistoricPath     = "/metrics/historic"
+	vmMetricsCurrentSpecMime  = "application/vnd.vmware.vcloud.metrics.currentUsageSpec+xml"
+	vmMetricsHistoricSpecMime = "application/vnd.vmware.vcloud.metrics.historicUsageSpec+xml"
+)
+
+// vmCurrentUsageSpec is the payload selecting current metrics
+type vmCurrentUsageSpec struct {
+	XMLName        xml.Name `xml:"CurrentUsageSpec"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	MetricPatterns []string `xml:"MetricPattern,omitempty"`
+}
+
+// vmHistoricUsageSpec is the payload selecting historic metrics
+type vmHistoricUsageSpec struct {
+	XMLName        xml.Name `xml:"HistoricUsageSpec"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	Start          string   `xml:"AbsoluteTimePeriod>Start"`
+	End            string   `xml:"AbsoluteTimePeriod>End"`
+	MetricPatterns []string `xml:"MetricPattern,omitempty"`
+}
+
+// vmCurrentUsage is the response with current metrics
+type vmCurrentUsage struct {
+	Metrics []struct {
+		Name  string  `xml:"name,attr"`
+		Unit  string  `xml:"unit,attr"`
+		Value float64 `xml:"value,attr"`
+	} `xml:"Metric"`
+}
+
+// vmHistoricUsage is the response with historic metrics
+type vmHistoricUsage struct {
+	Series []struct {
+		Name             string `xml:"name,attr"`
+		Unit             string `xml:"unit,attr"`
+		ExpectedInterval int    `xml:"expectedInterval,attr"`
+		Samples          []struct {
+			Timestamp string  `xml:"timestamp,attr"`
+			Value     float64 `xml:"value,attr"`
+		} `xml:"Sample"`
+	} `xml:"MetricSeries"`
+}
+
+func datasourceVcdVmMetrics() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdVmMetricsRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The vApp this VM belongs to",
+			},
+			"vm_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "VM in vApp for which the metrics are read",
+			},
+			"metric_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Patterns of the metrics to read, such as 'cpu.*'. '*' can be used as wildcard. All the metrics when not set",
+			},
+			"since": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "Start of the period of the historic metrics, in RFC 3339 format. Historic metrics are only read when set",
+			},
+			"until": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"since"},
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "End of the period of the historic metrics, in RFC 3339 format. Defaults to the current time",
+			},
+			"metrics": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Current values of the metrics. Empty when the VM is not powered on",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Metric name, such as 'cpu.usage.average'",
+						},
+						"unit": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Unit of the metric, such as 'PERCENT' or 'KILOBYTE'",
+						},
+						"value": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "Current value of the metric",
+						},
+					},
+				},
+			},
+			"historic_metrics": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Samples of the metrics in the period set by 'since' and 'until'",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Metric name, such as 'cpu.usage.average'",
+						},
+						"unit": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Unit of the metric, such as 'PERCENT' or 'KILOBYTE'",
+						},
+						"expected_interval": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Expected interval between samples, in seconds",
+						},
+						"samples": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Samples of the metric, oldest first",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"timestamp": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Time of the sample",
+									},
+									"value": {
+										Type:        schema.TypeFloat,
+										Computed:    true,
+										Description: "Value of the metric",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdVmMetricsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	vm, _, err := getVm(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("[VM metrics read] %s", err)
+	}
+	patterns := convertTypeListToSliceOfStrings(d.Get("metric_patterns").([]interface{}))
+
+	current, err := getVmCurrentMetrics(vcdClient, vm.VM.HREF, patterns)
+	if err != nil {
+		return diag.Errorf("[VM metrics read] error retrieving metrics of VM '%s': %s", vm.VM.Name, err)
+	}
+	metrics := make([]map[string]interface{}, 0, len(current.Metrics))
+	for _, metric := range current.Metrics {
+		metrics = append(metrics, map[string]interface{}{
+			"name":  metric.Name,
+			"unit":  metric.Unit,
+			"value": metric.Value,
+		})
+	}
+	err = d.Set("metrics", metrics)
+	if err != nil {
+		return diag.Errorf("error setting metrics: %s", err)
+	}
+
+	historicMetrics := make([]map[string]interface{}, 0)
+	if since := d.Get("since").(string); since != "" {
+		start, _ := time.Parse(time.RFC3339, since)
+		end := time.Now()
+		if until := d.Get("until").(string); until != "" {
+			end, _ = time.Parse(time.RFC3339, until)
+		}
+		if !end.After(start) {
+			return diag.Errorf("[VM metrics read] 'until' (%s) must be later than 'since' (%s)", end.Format(time.RFC3339), since)
+		}
+		historic, err := getVmHistoricMetrics(vcdClient, vm.VM.HREF, patterns, start, end)
+		if err != nil {
+			return diag.Errorf("[VM metrics read] error retrieving historic metrics of VM '%s': %s", vm.VM.Name, err)
+		}
+		for _, series := range historic.Series {
+			samples := make([]map[string]interface{}, 0, len(series.Samples))
+			for _, sample := range series.Samples {
+				samples = append(samples, map[string]interface{}{
+					"timestamp": sample.Timestamp,
+					"value":     sample.Value,
+				})
+			}
+			historicMetrics = append(historicMetrics, map[string]interface{}{
+				"name":              series.Name,
+				"unit":              series.Unit,
+				"expected_interval": series.ExpectedInterval,
+				"samples":           samples,
+			})
+		}
+	}
+	err = d.Set("historic_metrics", historicMetrics)
+	if err != nil {
+		return diag.Errorf("error setting historic metrics: %s", err)
+	}
+
+	d.SetId(vm.VM.ID)
+	return nil
+}
+
+// getVmCurrentMetrics retrieves the current metrics of the VM identified by vmHref, restricted to the
+// given patterns when there are any
+func getVmCurrentMetrics(vcdClient *VCDClient, vmHref string, patterns []string) (*vmCurrentUsage, error) {
+	usage := &vmCurrentUsage{}
+	method, contentType := http.MethodGet, ""
+	var payload interface{}
+	if len(patterns) > 0 {
+		method, contentType = http.MethodPost, vmMetricsCurrentSpecMime
+		payload = &vmCurrentUsageSpec{Xmlns: types.XMLNamespaceVCloud, MetricPatterns: patterns}
+	}
+	_, err := vcdClient.Client.ExecuteRequest(vmHref+vmMetricsCurrentPath, method, contentType,
+		"error retrieving current metrics: %s", payload, usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// getVmHistoricMetrics retrieves the samples of the metrics of the VM identified by vmHref between
+// start and end, restricted to the given patterns when there are any
+func getVmHistoricMetrics(vcdClient *VCDClient, vmHref string, patterns []string, start, end time.Time) (*vmHistoricUsage, error) {
+	usage := &vmHistoricUsage{}
+	spec := &vmHistoricUsageSpec{
+		Xmlns:          types.XMLNamespaceVCloud,
+		Start:          start.UTC().Format(time.RFC3339),
+		End:            end.UTC().Format(time.RFC3339),
+		MetricPatterns: patterns,
+	}
+	_, err := vcdClient.Client.ExecuteRequest(vmHref+vmMetricsHistoricPath, http.MethodPost, vmMetricsHistoricSpecMime,
+		"error retrieving historic metrics: %s", spec, usage)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
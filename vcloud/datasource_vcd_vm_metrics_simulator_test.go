@@ -0,0 +1,123 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestSimulatorVmMetrics checks the current and historic metrics of a VM, and their patterns
+func TestSimulatorVmMetrics(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "web-vapp")
+	vmId := sim.AddVm(simulatorOrg, simulatorVdc, "web-vapp", "web-1")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		timestamp := start.Add(time.Duration(i) * 5 * time.Minute)
+		sim.AddVmMetricSample(vmId, "cpu.usage.average", "PERCENT", timestamp, float64(10*(i+1)))
+		sim.AddVmMetricSample(vmId, "mem.usage.average", "PERCENT", timestamp, 50)
+		sim.AddVmMetricSample(vmId, "disk.used.latest", "KILOBYTE", timestamp, 1024)
+	}
+
+	readMetrics := func(t *testing.T, values map[string]interface{}) *schema.ResourceData {
+		resource := datasourceVcdVmMetrics()
+		values["org"] = simulatorOrg
+		values["vdc"] = simulatorVdc
+		values["vapp_name"] = "web-vapp"
+		values["vm_name"] = "web-1"
+		d := simulatorResourceData(t, resource, values)
+		if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error reading VM metrics: %v", diags)
+		}
+		return d
+	}
+	names := func(items []interface{}) string {
+		var result []string
+		for _, item := range items {
+			result = append(result, item.(map[string]interface{})["name"].(string))
+		}
+		return strings.Join(result, ",")
+	}
+
+	t.Run("PoweredOff", func(t *testing.T) {
+		d := readMetrics(t, map[string]interface{}{})
+		if metrics := d.Get("metrics").([]interface{}); len(metrics) != 0 {
+			t.Errorf("expected no metrics for a powered off VM, got %v", metrics)
+		}
+		if d.Id() != vmId {
+			t.Errorf("expected ID %s, got %s", vmId, d.Id())
+		}
+	})
+
+	powerOnSimulatorVm(t, simulatorVm(t, vcdClient, vmId))
+
+	t.Run("Current", func(t *testing.T) {
+		d := readMetrics(t, map[string]interface{}{})
+		metrics := d.Get("metrics").([]interface{})
+		if got := names(metrics); got != "cpu.usage.average,disk.used.latest,mem.usage.average" {
+			t.Fatalf("unexpected metrics %q", got)
+		}
+		cpu := metrics[0].(map[string]interface{})
+		if cpu["value"].(float64) != 40 || cpu["unit"] != "PERCENT" {
+			t.Errorf("expected the latest CPU value 40 PERCENT, got %v %v", cpu["value"], cpu["unit"])
+		}
+		if historic := d.Get("historic_metrics").([]interface{}); len(historic) != 0 {
+			t.Errorf("expected no historic metrics without 'since', got %v", historic)
+		}
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		d := readMetrics(t, map[string]interface{}{"metric_patterns": []interface{}{"*.usage.*", "disk.used.latest"}})
+		if got := names(d.Get("metrics").([]interface{})); got != "cpu.usage.average,disk.used.latest,mem.usage.average" {
+			t.Errorf("unexpected metrics %q", got)
+		}
+		d = readMetrics(t, map[string]interface{}{"metric_patterns": []interface{}{"cpu.*"}})
+		if got := names(d.Get("metrics").([]interface{})); got != "cpu.usage.average" {
+			t.Errorf("unexpected metrics %q", got)
+		}
+	})
+
+	t.Run("Historic", func(t *testing.T) {
+		d := readMetrics(t, map[string]interface{}{
+			"metric_patterns": []interface{}{"cpu.*"},
+			"since":           "2024-05-01T10:05:00Z",
+			"until":           "2024-05-01T12:10:00+02:00",
+		})
+		historic := d.Get("historic_metrics").([]interface{})
+		if len(historic) != 1 {
+			t.Fatalf("expected 1 metric series, got %d", len(historic))
+		}
+		series := historic[0].(map[string]interface{})
+		if series["name"] != "cpu.usage.average" || series["expected_interval"].(int) != 300 {
+			t.Errorf("unexpected series %v", series)
+		}
+		var samples []string
+		for _, sample := range series["samples"].([]interface{}) {
+			sample := sample.(map[string]interface{})
+			samples = append(samples, fmt.Sprintf("%s=%g", sample["timestamp"], sample["value"]))
+		}
+		if got := strings.Join(samples, ","); got != "2024-05-01T10:05:00Z=20,2024-05-01T10:10:00Z=30" {
+			t.Errorf("unexpected samples %q", got)
+		}
+	})
+
+	t.Run("InvalidPeriod", func(t *testing.T) {
+		resource := datasourceVcdVmMetrics()
+		d := simulatorResourceData(t, resource, map[string]interface{}{
+			"org": simulatorOrg, "vdc": simulatorVdc, "vapp_name": "web-vapp", "vm_name": "web-1",
+			"since": "2024-05-01T10:00:00Z", "until": "2024-05-01T09:00:00Z",
+		})
+		diags := resource.ReadContext(ctx, d, vcdClient)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, "must be later") {
+			t.Errorf("expected an error about the period, got %v", diags)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourceVcdVmSnapshot() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdVmSnapshotRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The vApp this VM belongs to",
+			},
+			"vm_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "VM in vApp for which the snapshot is read",
+			},
+			"created": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation date of the snapshot",
+			},
+			"powered_on": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the VM was powered on when the snapshot was taken",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Size of the snapshot in bytes",
+			},
+		},
+	}
+}
+
+func datasourceVcdVmSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdVmSnapshotRead(d, meta, "datasource")
+}
@@ -0,0 +1,203 @@
+package vcloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func datasourceVcdVms() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceVcdVmsRead,
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "If set, only the VMs of this vApp are retrieved",
+			},
+			"filter": listFilterSchema("VMs", map[string]*schema.Schema{
+				"name_regex": elementNameRegex,
+				"date":       elementDate,
+				"ip":         elementIp,
+				"metadata":   elementMetadata,
+			}),
+			"vms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "VMs matching the filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "VM ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "VM name",
+						},
+						"href": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "VM HREF",
+						},
+						"vapp_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the vApp containing the VM",
+						},
+						"vdc_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VDC containing the VM",
+						},
+						"standalone": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the VM is a standalone VM, in a hidden vApp",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "VM status, like POWERED_ON or POWERED_OFF",
+						},
+						"deployed": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the VM is deployed",
+						},
+						"os_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Guest operating system",
+						},
+						"cpus": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of virtual CPUs",
+						},
+						"memory": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Memory in MB",
+						},
+						"ip_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "IP address of the VM on its primary network",
+						},
+						"network_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the primary network of the VM",
+						},
+						"hardware_version": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Virtual hardware version",
+						},
+						"storage_profile": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the storage profile of the VM",
+						},
+						"sizing_policy_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM sizing policy",
+						},
+						"placement_policy_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM placement policy",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Timestamp of when the VM was created",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func datasourceVcdVmsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+
+	queryType := types.QtVm
+	if vcdClient.Client.IsSysAdmin {
+		queryType = types.QtAdminVm
+	}
+	vappName := d.Get("vapp_name").(string)
+	var searchFunc = func(queryType string, criteria *govcd.FilterDef) ([]govcd.QueryItem, string, error) {
+		if vappName != "" {
+			err := criteria.AddFilter(types.FilterParent, vappName)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		return vdc.SearchByFilter(queryType, "vdc", criteria)
+	}
+	queryItems, err := getEntitiesByFilter(searchFunc, queryType, d.Get("filter"))
+	if err != nil {
+		return diag.Errorf("error retrieving VMs: %s", err)
+	}
+
+	vms := make([]map[string]interface{}, 0, len(queryItems))
+	for _, queryItem := range queryItems {
+		vm, ok := queryItem.(govcd.QueryVm)
+		// The query also returns the VMs of vApp templates
+		if !ok || vm.VAppTemplate {
+			continue
+		}
+		vms = append(vms, map[string]interface{}{
+			"id":                  "urn:vcloud:vm:" + extractUuid(vm.HREF),
+			"name":                vm.Name,
+			"href":                vm.HREF,
+			"vapp_name":           vm.ContainerName,
+			"vdc_name":            vm.VdcName,
+			"standalone":          vm.AutoNature,
+			"status":              vm.Status,
+			"deployed":            vm.Deployed,
+			"os_type":             vm.GuestOS,
+			"cpus":                vm.Cpus,
+			"memory":              vm.MemoryMB,
+			"ip_address":          vm.IpAddress,
+			"network_name":        vm.NetworkName,
+			"hardware_version":    vm.HardwareVersion,
+			"storage_profile":     vm.StorageProfileName,
+			"sizing_policy_id":    vm.VmSizingPolicyId,
+			"placement_policy_id": vm.VmPlacementPolicyId,
+			"created":             vm.DateCreated,
+		})
+	}
+	err = d.Set("vms", vms)
+	if err != nil {
+		return diag.Errorf("error setting VMs: %s", err)
+	}
+
+	d.SetId(listFilterId(vdc.Vdc.ID, d.Get("filter")))
+	return nil
+}
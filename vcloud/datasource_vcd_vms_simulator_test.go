@@ -0,0 +1,82 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorListDataSources checks the VMs and vApps listed by the plural data sources, with and without filters
+func TestSimulatorListDataSources(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "web-vapp")
+	web1Id := sim.AddVm(simulatorOrg, simulatorVdc, "web-vapp", "web-1")
+	sim.AddVm(simulatorOrg, simulatorVdc, "web-vapp", "web-2")
+	sim.AddVapp(simulatorOrg, simulatorVdc, "db-vapp")
+	db1Id := sim.AddVm(simulatorOrg, simulatorVdc, "db-vapp", "db-1")
+
+	for _, vmId := range []string{web1Id, db1Id} {
+		vm := simulatorVm(t, vcdClient, vmId)
+		err := vm.AddMetadataEntryWithVisibility("env", "prod", types.MetadataStringValue, types.MetadataReadWriteVisibility, false)
+		if err != nil {
+			t.Fatalf("error adding metadata to VM %s: %s", vm.VM.Name, err)
+		}
+	}
+
+	listNames := func(resource *schema.Resource, listField string, values map[string]interface{}) []string {
+		values["org"] = simulatorOrg
+		values["vdc"] = simulatorVdc
+		d := simulatorResourceData(t, resource, values)
+		if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error reading %s: %v", listField, diags)
+		}
+		var names []string
+		for _, item := range d.Get(listField).([]interface{}) {
+			names = append(names, item.(map[string]interface{})["name"].(string))
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		resource *schema.Resource
+		field    string
+		values   map[string]interface{}
+		want     string
+	}{
+		{"AllVms", datasourceVcdVms(), "vms", map[string]interface{}{}, "db-1,web-1,web-2"},
+		{"VmsOfVapp", datasourceVcdVms(), "vms", map[string]interface{}{"vapp_name": "web-vapp"}, "web-1,web-2"},
+		{"VmsByName", datasourceVcdVms(), "vms", map[string]interface{}{
+			"filter": []interface{}{map[string]interface{}{"name_regex": "^web"}},
+		}, "web-1,web-2"},
+		{"VmsByMetadata", datasourceVcdVms(), "vms", map[string]interface{}{
+			"filter": []interface{}{map[string]interface{}{
+				"metadata": []interface{}{map[string]interface{}{"key": "env", "value": "prod"}},
+			}},
+		}, "db-1,web-1"},
+		{"NoMatchingVms", datasourceVcdVms(), "vms", map[string]interface{}{
+			"filter": []interface{}{map[string]interface{}{"name_regex": "^app"}},
+		}, ""},
+		{"AllVapps", datasourceVcdVapps(), "vapps", map[string]interface{}{}, "db-vapp,web-vapp"},
+		{"VappsByName", datasourceVcdVapps(), "vapps", map[string]interface{}{
+			"filter": []interface{}{map[string]interface{}{"name_regex": "^db"}},
+		}, "db-vapp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(listNames(tt.resource, tt.field, tt.values), ",")
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// deletionProtectionKey is the key of the metadata entry that marks a VCD object as protected from deletion. The
+// resources read it back, so that the protection survives a new import, and other tools can check it
+const deletionProtectionKey = "terraform-provider-vcloud.deletion_protection"
+
+// deletionProtectionResourceTypes contains the resources with XML API metadata that have a `deletion_protection`
+// argument, which updateMetadataInState sets from the marker
+var deletionProtectionResourceTypes = map[string]bool{
+	"vcd_org":              true,
+	"vcd_org_vdc":          true,
+	"vcd_catalog":          true,
+	"vcd_vapp":             true,
+	"vcd_vapp_vm":          true,
+	"vcd_vm":               true,
+	"vcd_independent_disk": true,
+}
+
+// deletionProtectionSchema returns the schema of the `deletion_protection` argument of the given object type
+func deletionProtectionSchema(objectType string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Computed: true,
+		Description: fmt.Sprintf("If true, the %s can't be deleted until this is set to false. Defaults to the "+
+			"provider 'deletion_protection'", objectType),
+	}
+}
+
+// deletionProtectionCustomizeDiff sets `deletion_protection` to the provider default when it is not in the
+// configuration
+func deletionProtectionCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.GetAttr("deletion_protection").IsNull() {
+		return nil
+	}
+	defaultValue := meta.(*VCDClient).deletionProtection
+	if d.Id() != "" && d.Get("deletion_protection").(bool) == defaultValue {
+		return nil
+	}
+	return d.SetNew("deletion_protection", defaultValue)
+}
+
+// checkDeletionProtection stops the deletion of a resource that has `deletion_protection` enabled
+func checkDeletionProtection(d *schema.ResourceData, objectType string) diag.Diagnostics {
+	if !d.Get("deletion_protection").(bool) {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s '%s' is protected from deletion", objectType, d.Get("name")),
+		Detail: "The resource has `deletion_protection` enabled. To delete it, set `deletion_protection = false` " +
+			"and apply that change first.",
+	}}
+}
+
+// checkProtectedChildren stops a recursive or forced deletion of an Org or VDC that would remove objects protected
+// from deletion along with it. The objects that `deletion_protection` manages inside Catalogs (vApp templates and
+// media) have no protection, so Catalogs don't need this check
+func checkProtectedChildren(d *schema.ResourceData, objectType string, protectedChildren []string) diag.Diagnostics {
+	if len(protectedChildren) == 0 {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s '%s' contains objects protected from deletion", objectType, d.Get("name")),
+		Detail: fmt.Sprintf("Deleting the %s with `delete_recursive` or `delete_force` would also delete: %s. "+
+			"Set `deletion_protection = false` on them and apply that change first.", objectType,
+			strings.Join(protectedChildren, ", ")),
+	}}
+}
+
+// protectedVdcChildQueries are the query service types of the objects with XML API metadata that can be protected
+// inside a VDC, and the names used to report them
+var protectedVdcChildQueries = []protectedChildQuery{
+	{queryType: types.QtAdminVapp, objectType: "vApp"},
+	{queryType: types.QtAdminVm, objectType: "VM", extraFilter: ";isVAppTemplate==false"},
+	{queryType: "adminDisk", objectType: "independent disk"},
+}
+
+// protectedOrgChildQueries is the equivalent of protectedVdcChildQueries for the objects directly inside an Org
+var protectedOrgChildQueries = []protectedChildQuery{
+	{queryType: types.QtAdminOrgVdc, objectType: "VDC"},
+	{queryType: types.QtAdminCatalog, objectType: "Catalog"},
+}
+
+// protectedChildQuery is a query service type that can contain protected objects. extraFilter is appended to
+// the parent filter
+type protectedChildQuery struct {
+	queryType   string
+	objectType  string
+	extraFilter string
+}
+
+// findProtectedOrgChildren returns the objects inside the Org, including the ones inside its VDCs, that have the
+// deletion protection marker
+func findProtectedOrgChildren(vcdClient *VCDClient, adminOrg *govcd.AdminOrg) ([]string, error) {
+	protected, err := queryProtectedChildren(vcdClient, "org=="+url.QueryEscape(adminOrg.AdminOrg.ID), protectedOrgChildQueries)
+	if err != nil {
+		return nil, err
+	}
+	if adminOrg.AdminOrg.Vdcs != nil {
+		for _, vdcReference := range adminOrg.AdminOrg.Vdcs.Vdcs {
+			vdcId := "urn:vcloud:vdc:" + extractUuid(vdcReference.HREF)
+			vdcChildren, err := queryProtectedChildren(vcdClient, "vdc=="+url.QueryEscape(vdcId), protectedVdcChildQueries)
+			if err != nil {
+				return nil, err
+			}
+			protected = append(protected, vdcChildren...)
+		}
+	}
+	edgeGateways, err := findProtectedEdgeGateways(vcdClient, "orgRef.id=="+adminOrg.AdminOrg.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append(protected, edgeGateways...), nil
+}
+
+// findProtectedVdcChildren returns the objects inside the VDC that have the deletion protection marker
+func findProtectedVdcChildren(vcdClient *VCDClient, vdcId string) ([]string, error) {
+	protected, err := queryProtectedChildren(vcdClient, "vdc=="+url.QueryEscape(vdcId), protectedVdcChildQueries)
+	if err != nil {
+		return nil, err
+	}
+	edgeGateways, err := findProtectedEdgeGateways(vcdClient, "ownerRef.id=="+vdcId)
+	if err != nil {
+		return nil, err
+	}
+	return append(protected, edgeGateways...), nil
+}
+
+// queryProtectedChildren finds the objects with XML API metadata that match the parent filter and have the deletion
+// protection marker, with one query service request per object type and metadata domain
+func queryProtectedChildren(vcdClient *VCDClient, parentFilter string, queries []protectedChildQuery) ([]string, error) {
+	var protected []string
+	for _, query := range queries {
+		for _, metadataPrefix := range []string{"metadata", "metadata@SYSTEM"} {
+			results, err := vcdClient.Client.QueryWithNotEncodedParams(nil, map[string]string{
+				"type": query.queryType,
+				"filter": fmt.Sprintf("%s%s;%s:%s==STRING:true", parentFilter, query.extraFilter, metadataPrefix,
+					deletionProtectionKey),
+				"filterEncoded": "true",
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error looking for protected objects of type %s: %s", query.queryType, err)
+			}
+			for _, name := range queryResultNames(results.Results) {
+				protected = append(protected, fmt.Sprintf("%s '%s'", query.objectType, name))
+			}
+		}
+	}
+	return protected, nil
+}
+
+// queryResultNames returns the names of the records of the query types used by queryProtectedChildren
+func queryResultNames(results *types.QueryResultRecordsType) []string {
+	var names []string
+	if results == nil {
+		return names
+	}
+	for _, record := range results.OrgVdcAdminRecord {
+		names = append(names, record.Name)
+	}
+	for _, record := range results.AdminCatalogRecord {
+		names = append(names, record.Name)
+	}
+	for _, record := range results.AdminVAppRecord {
+		names = append(names, record.Name)
+	}
+	for _, record := range results.AdminVMRecord {
+		names = append(names, record.Name)
+	}
+	for _, record := range results.AdminDiskRecord {
+		names = append(names, record.Name)
+	}
+	return names
+}
+
+// findProtectedEdgeGateways finds the NSX-T Edge Gateways that match the filter and have the deletion protection
+// marker. Their metadata is in the OpenAPI, which can't be used in filters, so each Edge Gateway is checked
+func findProtectedEdgeGateways(vcdClient *VCDClient, filter string) ([]string, error) {
+	queryParams := url.Values{}
+	queryParams.Add("filter", filter)
+	edgeGateways, err := vcdClient.GetAllNsxtEdgeGateways(queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("error looking for protected NSX-T Edge Gateways: %s", err)
+	}
+	var protected []string
+	for _, edgeGateway := range edgeGateways {
+		object := newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", edgeGateway.EdgeGateway.ID, edgeGateway.EdgeGateway.Name)
+		if !object.isSupported() {
+			return protected, nil
+		}
+		allMetadata, err := object.getAllMetadata()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the metadata of NSX-T Edge Gateway %s: %s", edgeGateway.EdgeGateway.Name, err)
+		}
+		if _, isProtected := removeOpenApiDeletionProtectionMarker(allMetadata); isProtected {
+			protected = append(protected, fmt.Sprintf("NSX-T Edge Gateway '%s'", edgeGateway.EdgeGateway.Name))
+		}
+	}
+	return protected, nil
+}
+
+// updateDeletionProtectionMarker adds or removes the deletion protection marker in the metadata of the object,
+// when `deletion_protection` changes. The entry is in the SYSTEM domain, read only for tenants, when the provider
+// connects as System administrator
+func updateDeletionProtectionMarker(d *schema.ResourceData, vcdClient *VCDClient, object metadataCompatible) error {
+	if !d.HasChange("deletion_protection") {
+		return nil
+	}
+	isSystem := vcdClient.Client.IsSysAdmin
+	if d.Get("deletion_protection").(bool) {
+		visibility := types.MetadataReadWriteVisibility
+		if isSystem {
+			visibility = types.MetadataReadOnlyVisibility
+		}
+		err := object.AddMetadataEntryWithVisibility(deletionProtectionKey, "true", types.MetadataStringValue, visibility, isSystem)
+		if err != nil {
+			return fmt.Errorf("error adding the deletion protection metadata entry: %s", err)
+		}
+		return nil
+	}
+	err := object.DeleteMetadataEntryWithDomain(deletionProtectionKey, isSystem)
+	if err != nil && !govcd.ContainsNotFound(err) {
+		return fmt.Errorf("error removing the deletion protection metadata entry: %s", err)
+	}
+	return nil
+}
+
+// updateOpenApiDeletionProtectionMarker is the equivalent of updateDeletionProtectionMarker for the objects with
+// OpenAPI metadata. VCD versions without OpenAPI metadata for the object keep the protection only in the state
+func updateOpenApiDeletionProtectionMarker(d *schema.ResourceData, vcdClient *VCDClient, object openApiEntityMetadata) error {
+	if !d.HasChange("deletion_protection") {
+		return nil
+	}
+	if !object.isSupported() {
+		log.Printf("[DEBUG] the deletion protection of %s is not recorded in metadata, as VCD doesn't support it", d.Id())
+		return nil
+	}
+	entry := deletionProtectionOpenApiEntry(vcdClient)
+	if d.Get("deletion_protection").(bool) {
+		err := object.addMetadata(entry)
+		if err != nil {
+			return fmt.Errorf("error adding the deletion protection metadata entry: %s", err)
+		}
+		return nil
+	}
+	err := object.deleteMetadata(entry)
+	if err != nil && !govcd.ContainsNotFound(err) {
+		return fmt.Errorf("error removing the deletion protection metadata entry: %s", err)
+	}
+	return nil
+}
+
+// deletionProtectionOpenApiEntry returns the OpenAPI metadata entry that marks an object as protected. The entry is in
+// the PROVIDER domain, read only for tenants, when the provider connects as System administrator
+func deletionProtectionOpenApiEntry(vcdClient *VCDClient) types.OpenApiMetadataEntry {
+	domain := "TENANT"
+	if vcdClient.Client.IsSysAdmin {
+		domain = "PROVIDER"
+	}
+	return types.OpenApiMetadataEntry{
+		IsReadOnly: vcdClient.Client.IsSysAdmin,
+		KeyValue: types.OpenApiMetadataKeyValue{
+			Domain: domain,
+			Key:    deletionProtectionKey,
+			Value:  types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "true"},
+		},
+	}
+}
+
+// removeDeletionProtectionMarker removes the deletion protection marker from the metadata retrieved from VCD, so
+// that it doesn't appear in the metadata of the resource, and tells whether it was there
+func removeDeletionProtectionMarker(metadata []*types.MetadataEntry) ([]*types.MetadataEntry, bool) {
+	var result []*types.MetadataEntry
+	found := false
+	for _, entry := range metadata {
+		if entry.Key == deletionProtectionKey {
+			found = found || (entry.TypedValue != nil && entry.TypedValue.Value == "true")
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, found
+}
+
+// removeOpenApiDeletionProtectionMarker is the equivalent of removeDeletionProtectionMarker for OpenAPI metadata
+func removeOpenApiDeletionProtectionMarker(metadata []*types.OpenApiMetadataEntry) ([]*types.OpenApiMetadataEntry, bool) {
+	var result []*types.OpenApiMetadataEntry
+	found := false
+	for _, entry := range metadata {
+		if entry.KeyValue.Key == deletionProtectionKey && entry.KeyValue.Namespace == "" {
+			found = found || entry.KeyValue.Value.Value == "true"
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, found
+}
+
+// readOpenApiDeletionProtection sets `deletion_protection` from the marker in the OpenAPI metadata of the object. On
+// VCD versions without OpenAPI metadata for the object, the value in the state is kept
+func readOpenApiDeletionProtection(d *schema.ResourceData, object openApiEntityMetadata) error {
+	if !object.isSupported() {
+		return nil
+	}
+	allMetadata, err := object.getAllMetadata()
+	if err != nil {
+		return fmt.Errorf("error reading the deletion protection metadata entry: %s", err)
+	}
+	_, isProtected := removeOpenApiDeletionProtectionMarker(allMetadata)
+	dSet(d, "deletion_protection", isProtected)
+	return nil
+}
@@ -0,0 +1,136 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestSimulatorDeletionProtectionChildren checks that a recursive deletion of an Org or VDC is stopped when it would
+// remove a protected object inside it
+func TestSimulatorDeletionProtectionChildren(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	vappResource := resourceVcdVApp()
+	for name, isProtected := range map[string]bool{"protected-child": true, "unprotected-child": false} {
+		d := simulatorResourceData(t, vappResource, map[string]interface{}{
+			"org":                 simulatorOrg,
+			"vdc":                 simulatorVdc,
+			"name":                name,
+			"deletion_protection": isProtected,
+		})
+		if diags := vappResource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error creating vApp %s: %v", name, diags)
+		}
+	}
+	adminOrg, err := vcdClient.GetAdminOrgByName(simulatorOrg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdc, err := adminOrg.GetVDCByName(simulatorVdc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectProtectedChildren := func(t *testing.T, objectType string, diags diag.Diagnostics) {
+		if !diags.HasError() {
+			t.Fatalf("expected the deletion of the %s to be stopped", objectType)
+		}
+		if !strings.Contains(diags[0].Summary, "contains objects protected from deletion") {
+			t.Errorf("unexpected error summary: %s", diags[0].Summary)
+		}
+		if !strings.Contains(diags[0].Detail, "vApp 'protected-child'") || strings.Contains(diags[0].Detail, "unprotected-child") {
+			t.Errorf("expected only the protected vApp to be reported, got: %s", diags[0].Detail)
+		}
+	}
+
+	t.Run("vdc", func(t *testing.T) {
+		resource := resourceVcdOrgVdc()
+		d := simulatorResourceData(t, resource, map[string]interface{}{
+			"org":              simulatorOrg,
+			"name":             simulatorVdc,
+			"delete_recursive": true,
+			"delete_force":     true,
+		})
+		d.SetId(vdc.Vdc.ID)
+		expectProtectedChildren(t, "VDC", resource.DeleteContext(ctx, d, vcdClient))
+		if _, err := adminOrg.GetVDCByName(simulatorVdc, true); err != nil {
+			t.Fatalf("expected the VDC to be kept: %s", err)
+		}
+	})
+
+	t.Run("org", func(t *testing.T) {
+		resource := resourceOrg()
+		d := simulatorResourceData(t, resource, map[string]interface{}{
+			"name":             simulatorOrg,
+			"full_name":        simulatorOrg,
+			"delete_recursive": true,
+			"delete_force":     true,
+		})
+		d.SetId(adminOrg.AdminOrg.ID)
+		expectProtectedChildren(t, "Org", resource.DeleteContext(ctx, d, vcdClient))
+		if _, err := vcdClient.GetAdminOrgByName(simulatorOrg); err != nil {
+			t.Fatalf("expected the Org to be kept: %s", err)
+		}
+	})
+}
+
+// TestSimulatorDeletionProtection checks that a protected vApp is not deleted, and that the protection survives an import
+func TestSimulatorDeletionProtection(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+	values := map[string]interface{}{
+		"org":                 simulatorOrg,
+		"vdc":                 simulatorVdc,
+		"name":                "protected-vapp",
+		"deletion_protection": true,
+	}
+
+	d := simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	vapp := simulatorVapp(t, vcdClient, "protected-vapp")
+	marker, err := vapp.GetMetadataByKey(deletionProtectionKey, vcdClient.Client.IsSysAdmin)
+	if err != nil || marker.TypedValue == nil || marker.TypedValue.Value != "true" {
+		t.Fatalf("expected the deletion protection metadata entry, got %+v (error %v)", marker, err)
+	}
+	if entries := d.Get("metadata_entry").(*schema.Set).Len(); entries != 0 {
+		t.Errorf("expected the marker to be hidden from metadata_entry, got %d entries", entries)
+	}
+
+	diags := resource.DeleteContext(ctx, d, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "vApp 'protected-vapp' is protected from deletion") {
+		t.Fatalf("expected the delete to be stopped, got %v", diags)
+	}
+
+	// The protection is read back from the metadata after an import
+	imported := importSimulatorResource(t, resource, strings.Join([]string{simulatorOrg, simulatorVdc, "protected-vapp"}, ImportSeparator), vcdClient)
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading imported vApp: %v", diags)
+	}
+	if !imported.Get("deletion_protection").(bool) {
+		t.Fatal("expected the imported vApp to be protected")
+	}
+
+	values["deletion_protection"] = false
+	d = simulatorUpdateData(t, resource, d, values)
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error removing the protection: %v", diags)
+	}
+	if d.Get("deletion_protection").(bool) {
+		t.Fatal("expected the protection to be removed")
+	}
+	if _, err := vapp.GetMetadataByKey(deletionProtectionKey, vcdClient.Client.IsSysAdmin); err == nil {
+		t.Errorf("expected the deletion protection metadata entry to be removed")
+	}
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+}
@@ -0,0 +1,130 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_deletionProtectionCustomizeDiff checks that the provider default applies only when the resource doesn't set
+// `deletion_protection`
+func Test_deletionProtectionCustomizeDiff(t *testing.T) {
+	resource := resourceVcdIndependentDisk()
+	configSchema := schema.InternalMap(resource.Schema).CoreConfigSchema()
+	config := func(protection interface{}) map[string]interface{} {
+		values := map[string]interface{}{"name": "disk1", "size_in_mb": 1024}
+		if protection != nil {
+			values["deletion_protection"] = protection
+		}
+		return values
+	}
+	// The raw configuration tells whether the argument is set. Terraform sends it along with the state
+	rawConfig := func(values map[string]interface{}) cty.Value {
+		attributes := map[string]cty.Value{
+			"name":       cty.StringVal(values["name"].(string)),
+			"size_in_mb": cty.NumberIntVal(int64(values["size_in_mb"].(int))),
+		}
+		if protection, ok := values["deletion_protection"]; ok {
+			attributes["deletion_protection"] = cty.BoolVal(protection.(bool))
+		}
+		value, err := configSchema.CoerceValue(cty.ObjectVal(attributes))
+		if err != nil {
+			t.Fatalf("error building the configuration: %s", err)
+		}
+		return value
+	}
+	plan := func(state *terraform.InstanceState, values map[string]interface{}, providerDefault bool) *terraform.InstanceDiff {
+		if state == nil {
+			state = &terraform.InstanceState{}
+		}
+		state.RawConfig = rawConfig(values)
+		diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), state, terraform.NewResourceConfigShimmed(state.RawConfig, configSchema),
+			resource.CustomizeDiff, &VCDClient{deletionProtection: providerDefault}, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return diff
+	}
+	planned := func(diff *terraform.InstanceDiff) string {
+		if diff == nil || diff.Attributes["deletion_protection"] == nil {
+			return "unchanged"
+		}
+		return diff.Attributes["deletion_protection"].New
+	}
+
+	tests := []struct {
+		name            string
+		stateValue      string // empty for a new resource
+		configValue     interface{}
+		providerDefault bool
+		want            string
+	}{
+		{name: "CreateDefaultOff", want: "false"},
+		{name: "CreateDefaultOn", providerDefault: true, want: "true"},
+		{name: "CreateExplicitOff", configValue: false, providerDefault: true, want: "false"},
+		{name: "UpdateDefaultOn", stateValue: "false", providerDefault: true, want: "true"},
+		{name: "UpdateDefaultUnchanged", stateValue: "true", providerDefault: true, want: "unchanged"},
+		{name: "UpdateExplicitOff", stateValue: "true", configValue: false, providerDefault: true, want: "false"},
+		{name: "UpdateExplicitOn", stateValue: "true", configValue: true, want: "unchanged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state *terraform.InstanceState
+			if tt.stateValue != "" {
+				d := schema.TestResourceDataRaw(t, resource.Schema, config(nil))
+				d.SetId("urn:vcloud:disk:1")
+				state = d.State()
+				state.Attributes["deletion_protection"] = tt.stateValue
+			}
+			if got := planned(plan(state, config(tt.configValue), tt.providerDefault)); got != tt.want {
+				t.Errorf("expected deletion_protection %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// Test_checkDeletionProtection checks that only protected resources are kept from deletion
+func Test_checkDeletionProtection(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceVcdVApp().Schema, map[string]interface{}{"name": "vapp1"})
+	if diags := checkDeletionProtection(d, "vApp"); diags != nil {
+		t.Errorf("unexpected diagnostics for an unprotected vApp: %v", diags)
+	}
+	dSet(d, "deletion_protection", true)
+	diags := checkDeletionProtection(d, "vApp")
+	if !diags.HasError() || diags[0].Summary != "vApp 'vapp1' is protected from deletion" ||
+		!strings.Contains(diags[0].Detail, "deletion_protection = false") {
+		t.Errorf("expected an error for a protected vApp, got %v", diags)
+	}
+}
+
+// Test_removeDeletionProtectionMarker checks that the marker is removed from the metadata and detected
+func Test_removeDeletionProtectionMarker(t *testing.T) {
+	entry := func(key, value string) *types.MetadataEntry {
+		return &types.MetadataEntry{Key: key, TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: value}}
+	}
+	metadata, found := removeDeletionProtectionMarker([]*types.MetadataEntry{entry("owner", "team-a"), entry(deletionProtectionKey, "true")})
+	if !found || len(metadata) != 1 || metadata[0].Key != "owner" {
+		t.Errorf("expected the marker to be found and removed, got %v (found %t)", metadata, found)
+	}
+	if metadata, found = removeDeletionProtectionMarker([]*types.MetadataEntry{entry(deletionProtectionKey, "false")}); found || len(metadata) != 0 {
+		t.Errorf("expected a marker without 'true' to be removed but not to protect, got %v (found %t)", metadata, found)
+	}
+
+	openApiEntry := func(namespace, key string) *types.OpenApiMetadataEntry {
+		return &types.OpenApiMetadataEntry{KeyValue: types.OpenApiMetadataKeyValue{Namespace: namespace, Key: key,
+			Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "true"}}}
+	}
+	openApiMetadata, found := removeOpenApiDeletionProtectionMarker([]*types.OpenApiMetadataEntry{
+		openApiEntry("", deletionProtectionKey), openApiEntry("other", deletionProtectionKey),
+	})
+	if !found || len(openApiMetadata) != 1 || openApiMetadata[0].KeyValue.Namespace != "other" {
+		t.Errorf("expected only the marker without namespace to be removed, got %v (found %t)", openApiMetadata, found)
+	}
+}
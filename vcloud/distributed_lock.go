@@ -0,0 +1,356 @@
+package vcloud
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const (
+	// distributedLockKey is the key of the metadata entry that records a lease on a VCD object
+	distributedLockKey = "terraform-provider-vcloud.lock"
+	// distributedLockSettleDelay is the time given to concurrent writers of the same lease before checking who
+	// got it. VCD metadata has no conditional writes, so the last writer wins
+	distributedLockSettleDelay = 2 * time.Second
+)
+
+// lease is the value of the lock metadata entry: the process holding the lock and the time its lease expires
+type lease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// leaseStore reads and writes the lock metadata entry of a single VCD object
+type leaseStore interface {
+	// getLease returns the current lease, or nil when the object is not locked
+	getLease() (*lease, error)
+	putLease(lease) error
+	removeLease() error
+}
+
+// distributedLocks takes leases on VCD objects, so that different Terraform runs (for example, in several CI
+// pipelines) don't modify the same objects at the same time. The leases complement the in-process locks of
+// vcdMutexKV: they are only taken once the in-process lock on the same key is held.
+//
+// A lease expires after ttl unless renewed, which happens periodically while the lock is held. This way a lease left
+// behind by a run that crashed or was killed is broken by the next run that needs the object.
+type distributedLocks struct {
+	owner       string
+	ttl         time.Duration
+	timeout     time.Duration // time spent waiting for a lease held by someone else
+	settleDelay time.Duration
+
+	mu   sync.Mutex
+	held map[string]*heldLease
+}
+
+// heldLease is a lease taken by this process, renewed until stop is closed
+type heldLease struct {
+	store leaseStore
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func newDistributedLocks(ttl, timeout time.Duration) *distributedLocks {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	// The random suffix distinguishes processes with the same PID in different containers
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return &distributedLocks{
+		owner:       fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), hex.EncodeToString(suffix)),
+		ttl:         ttl,
+		timeout:     timeout,
+		settleDelay: distributedLockSettleDelay,
+		held:        make(map[string]*heldLease),
+	}
+}
+
+// acquire takes the lease recorded in store for the lock key, waiting while another owner holds a valid one.
+// Leases that expired are broken.
+func (locks *distributedLocks) acquire(key, object string, store leaseStore) error {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		current, err := store.getLease()
+		if err != nil {
+			return fmt.Errorf("error reading the lock of %s: %s", object, err)
+		}
+		now := time.Now()
+		if current == nil || current.Owner == locks.owner || now.After(current.Expires) {
+			if current != nil && current.Owner != locks.owner {
+				log.Printf("[INFO] breaking the lock of %s held by %s, expired at %s", object, current.Owner, current.Expires)
+			}
+			taken, err := locks.take(store)
+			if err != nil {
+				return fmt.Errorf("error writing the lock of %s: %s", object, err)
+			}
+			if taken {
+				log.Printf("[DEBUG] lock of %s taken by %s", object, locks.owner)
+				locks.hold(key, object, store)
+				return nil
+			}
+			continue
+		}
+
+		delay := throttleDelay(attempt, "")
+		if time.Since(start)+delay > locks.timeout {
+			return fmt.Errorf("timed out after %s waiting for the lock of %s, held by %s until %s",
+				locks.timeout, object, current.Owner, current.Expires.Format(time.RFC3339))
+		}
+		log.Printf("[DEBUG] %s is locked by %s until %s: retrying in %s", object, current.Owner, current.Expires, delay)
+		time.Sleep(delay)
+	}
+}
+
+// take writes a lease owned by this process, and checks that no concurrent writer overwrote it
+func (locks *distributedLocks) take(store leaseStore) (bool, error) {
+	err := store.putLease(lease{Owner: locks.owner, Expires: time.Now().Add(locks.ttl)})
+	if err != nil {
+		return false, err
+	}
+	time.Sleep(locks.settleDelay)
+	current, err := store.getLease()
+	if err != nil {
+		return false, err
+	}
+	return current != nil && current.Owner == locks.owner, nil
+}
+
+// hold renews the lease every third of its TTL until it is released
+func (locks *distributedLocks) hold(key, object string, store leaseStore) {
+	held := &heldLease{store: store, stop: make(chan struct{}), done: make(chan struct{})}
+	locks.mu.Lock()
+	locks.held[key] = held
+	locks.mu.Unlock()
+
+	go func() {
+		defer close(held.done)
+		ticker := time.NewTicker(locks.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-held.stop:
+				return
+			case <-ticker.C:
+				current, err := store.getLease()
+				if err == nil && (current == nil || current.Owner != locks.owner) {
+					log.Printf("[WARN] the lock of %s was taken over while held by %s", object, locks.owner)
+					return
+				}
+				err = store.putLease(lease{Owner: locks.owner, Expires: time.Now().Add(locks.ttl)})
+				if err != nil {
+					log.Printf("[WARN] error renewing the lock of %s: %s", object, err)
+				}
+			}
+		}
+	}()
+}
+
+// release removes the lease taken for the lock key, if any. Errors are only logged, as the lease expires anyway
+func (locks *distributedLocks) release(key string) {
+	locks.mu.Lock()
+	held, ok := locks.held[key]
+	delete(locks.held, key)
+	locks.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	close(held.stop)
+	<-held.done
+	current, err := held.store.getLease()
+	if err == nil && current != nil && current.Owner == locks.owner {
+		err = held.store.removeLease()
+	}
+	if err != nil {
+		log.Printf("[WARN] error releasing the lock %s: %s", key, err)
+	}
+}
+
+// removeDistributedLockEntry removes the lease from the metadata retrieved from VCD. The lease is written and
+// removed by the provider while it holds a lock, also by other processes, so it must never reach the state: a later
+// update would otherwise delete a lease that is still in use
+func removeDistributedLockEntry(metadata []*types.MetadataEntry) []*types.MetadataEntry {
+	var result []*types.MetadataEntry
+	for _, entry := range metadata {
+		if entry.Key != distributedLockKey {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// removeOpenApiDistributedLockEntry is the equivalent of removeDistributedLockEntry for OpenAPI metadata
+func removeOpenApiDistributedLockEntry(metadata []*types.OpenApiMetadataEntry) []*types.OpenApiMetadataEntry {
+	var result []*types.OpenApiMetadataEntry
+	for _, entry := range metadata {
+		if entry.KeyValue.Key != distributedLockKey || entry.KeyValue.Namespace != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// marshalLease and unmarshalLease convert a lease to and from the value of the metadata entry
+func marshalLease(value lease) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func unmarshalLease(value string) (*lease, error) {
+	result := &lease{}
+	if err := json.Unmarshal([]byte(value), result); err != nil {
+		return nil, fmt.Errorf("unexpected lock value %q: %s", value, err)
+	}
+	return result, nil
+}
+
+// xmlLeaseStore keeps the lease in the XML API metadata of the object at href. The entry is in the SYSTEM domain,
+// read only for tenants, when the provider connects as System administrator
+type xmlLeaseStore struct {
+	client   *govcd.VCDClient
+	href     string
+	isSystem bool
+}
+
+func (store xmlLeaseStore) getLease() (*lease, error) {
+	// All the entries are retrieved, as VCD doesn't tell a missing key from a forbidden one
+	metadata, err := store.client.GetMetadataByHref(store.href)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range metadata.MetadataEntry {
+		isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+		if entry.Key == distributedLockKey && isSystem == store.isSystem && entry.TypedValue != nil {
+			return unmarshalLease(entry.TypedValue.Value)
+		}
+	}
+	return nil, nil
+}
+
+func (store xmlLeaseStore) putLease(value lease) error {
+	encoded, err := marshalLease(value)
+	if err != nil {
+		return err
+	}
+	visibility := types.MetadataReadWriteVisibility
+	if store.isSystem {
+		visibility = types.MetadataReadOnlyVisibility
+	}
+	return store.client.AddMetadataEntryWithVisibilityByHref(store.href, distributedLockKey, encoded,
+		types.MetadataStringValue, visibility, store.isSystem)
+}
+
+func (store xmlLeaseStore) removeLease() error {
+	return store.client.DeleteMetadataEntryWithDomainByHref(store.href, distributedLockKey, store.isSystem)
+}
+
+// openApiLeaseStore keeps the lease in the OpenAPI metadata of the entity with the given ID. The entry is in the
+// PROVIDER domain, read only for tenants, when the provider connects as System administrator
+type openApiLeaseStore struct {
+	client   *govcd.Client
+	entityId string
+}
+
+func (store openApiLeaseStore) domain() string {
+	if store.client.IsSysAdmin {
+		return "PROVIDER"
+	}
+	return "TENANT"
+}
+
+func (store openApiLeaseStore) endpoint(suffix string) (*url.URL, error) {
+	return store.client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointRdeEntities,
+		store.entityId, "/metadata", suffix)
+}
+
+// getEntry returns the lock metadata entry of the entity, or nil when there is none
+func (store openApiLeaseStore) getEntry() (*types.OpenApiMetadataEntry, error) {
+	urlRef, err := store.endpoint("")
+	if err != nil {
+		return nil, err
+	}
+	queryParameters := url.Values{}
+	queryParameters.Add("filter", fmt.Sprintf("keyValue.key==%s", distributedLockKey))
+	var entries []*types.OpenApiMetadataEntry
+	err = store.client.OpenApiGetAllItems(store.client.APIVersion, urlRef, queryParameters, &entries, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.KeyValue.Key == distributedLockKey && entry.KeyValue.Domain == store.domain() {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (store openApiLeaseStore) getLease() (*lease, error) {
+	entry, err := store.getEntry()
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	value, ok := entry.KeyValue.Value.Value.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected lock value %v", entry.KeyValue.Value.Value)
+	}
+	return unmarshalLease(value)
+}
+
+func (store openApiLeaseStore) putLease(value lease) error {
+	encoded, err := marshalLease(value)
+	if err != nil {
+		return err
+	}
+	entry, err := store.getEntry()
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		urlRef, err := store.endpoint("")
+		if err != nil {
+			return err
+		}
+		newEntry := types.OpenApiMetadataEntry{
+			IsReadOnly: store.client.IsSysAdmin,
+			KeyValue: types.OpenApiMetadataKeyValue{
+				Domain: store.domain(),
+				Key:    distributedLockKey,
+				Value:  types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: encoded},
+			},
+		}
+		return store.client.OpenApiPostItem(store.client.APIVersion, urlRef, nil, newEntry, &types.OpenApiMetadataEntry{}, nil)
+	}
+	urlRef, err := store.endpoint("/" + entry.ID)
+	if err != nil {
+		return err
+	}
+	entry.KeyValue.Value.Value = encoded
+	return store.client.OpenApiPutItem(store.client.APIVersion, urlRef, nil, entry, &types.OpenApiMetadataEntry{}, nil)
+}
+
+func (store openApiLeaseStore) removeLease() error {
+	entry, err := store.getEntry()
+	if err != nil || entry == nil {
+		return err
+	}
+	urlRef, err := store.endpoint("/" + entry.ID)
+	if err != nil {
+		return err
+	}
+	return store.client.OpenApiDeleteItem(store.client.APIVersion, urlRef, nil, nil)
+}
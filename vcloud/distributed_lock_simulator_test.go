@@ -0,0 +1,205 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// newLockingSimulatorClient returns a simulator client with distributed locks, which gives up at once on a lock held
+// by another run
+func newLockingSimulatorClient(t *testing.T) *VCDClient {
+	_, vcdClient := newSimulatorClient(t, func(config *Config) {
+		config.DistributedLocks = true
+		config.DistributedLockTtl = 60
+		config.DistributedLockTimeout = 0
+	})
+	vcdClient.locks.settleDelay = 0
+	return vcdClient
+}
+
+// TestSimulatorDistributedLockMetadata checks that the lease of a locked vApp doesn't appear in its metadata, and that
+// a metadata update doesn't remove the lease of another process
+func TestSimulatorDistributedLockMetadata(t *testing.T) {
+	vcdClient := newLockingSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+	values := map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "leased-vapp",
+	}
+
+	d := simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	vapp := simulatorVapp(t, vcdClient, "leased-vapp")
+	store := xmlLeaseStore{client: vcdClient.VCDClient, href: vapp.VApp.HREF, isSystem: vcdClient.Client.IsSysAdmin}
+
+	// A read while the lease is held, as the one at the end of a create or update, doesn't see it
+	if err := vcdClient.lockVapp(d); err != nil {
+		t.Fatalf("error locking vApp: %s", err)
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading vApp: %v", diags)
+	}
+	vcdClient.unLockVapp(d)
+	if entries := d.Get("metadata_entry").(*schema.Set).Len(); entries != 0 {
+		t.Fatalf("expected the lease to be hidden from metadata_entry, got %d entries", entries)
+	}
+
+	// A lease of another process that reached the state is kept by a metadata update
+	if err := store.putLease(lease{Owner: "other-run", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("error writing the lease: %s", err)
+	}
+	err := d.Set("metadata_entry", []interface{}{map[string]interface{}{
+		"key": distributedLockKey, "value": "stale", "type": "MetadataStringValue", "user_access": "READWRITE", "is_system": vcdClient.Client.IsSysAdmin,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["metadata_entry"] = []interface{}{map[string]interface{}{
+		"key": "owner", "value": "team-a", "type": "MetadataStringValue", "user_access": "READWRITE", "is_system": false,
+	}}
+	// The update of this process doesn't take the lease of the other one
+	vcdClient.locks = nil
+	d = simulatorUpdateData(t, resource, d, values)
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error updating vApp metadata: %v", diags)
+	}
+	current, err := store.getLease()
+	if err != nil || current == nil || current.Owner != "other-run" {
+		t.Fatalf("expected the lease of other-run to be kept, got %+v (error %v)", current, err)
+	}
+	if entries := d.Get("metadata_entry").(*schema.Set).List(); len(entries) != 1 || entries[0].(map[string]interface{})["key"] != "owner" {
+		t.Errorf("expected only the 'owner' entry in metadata_entry, got %v", entries)
+	}
+
+	if err := store.removeLease(); err != nil {
+		t.Fatal(err)
+	}
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+}
+
+// TestSimulatorDistributedLocks checks that a lease held by another run stops an operation, and that a stale lease is broken
+func TestSimulatorDistributedLocks(t *testing.T) {
+	vcdClient := newLockingSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "locked-vapp",
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	vapp := simulatorVapp(t, vcdClient, "locked-vapp")
+	store := xmlLeaseStore{client: vcdClient.VCDClient, href: vapp.VApp.HREF, isSystem: vcdClient.Client.IsSysAdmin}
+
+	// A lease held by another run makes the operation fail once the timeout is reached
+	if err := store.putLease(lease{Owner: "other-run", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("error writing the lease: %s", err)
+	}
+	diags := resource.DeleteContext(ctx, d, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "held by other-run") {
+		t.Fatalf("expected the delete to time out on the lock, got %v", diags)
+	}
+
+	// A stale lease is broken
+	if err := store.putLease(lease{Owner: "other-run", Expires: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("error writing the lease: %s", err)
+	}
+	if err := vcdClient.lockVapp(d); err != nil {
+		t.Fatalf("expected the stale lease to be broken, got %s", err)
+	}
+	current, err := store.getLease()
+	if err != nil || current == nil || current.Owner != vcdClient.locks.owner {
+		t.Fatalf("expected a lease owned by %s, got %+v (error %v)", vcdClient.locks.owner, current, err)
+	}
+	vcdClient.unLockVapp(d)
+	if current, err := store.getLease(); err != nil || current != nil {
+		t.Fatalf("expected the lease to be removed, got %+v (error %v)", current, err)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+}
+
+// TestSimulatorDistributedParentLocks checks that the locks taken on the parent vApp and on the parent edge gateway
+// are stopped by a lease held by another run
+func TestSimulatorDistributedParentLocks(t *testing.T) {
+	vcdClient := newLockingSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "parent-vapp",
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	vapp := simulatorVapp(t, vcdClient, "parent-vapp")
+	vappStore := xmlLeaseStore{client: vcdClient.VCDClient, href: vapp.VApp.HREF, isSystem: vcdClient.Client.IsSysAdmin}
+
+	child := simulatorResourceData(t, resourceVcdVmSnapshot(), map[string]interface{}{
+		"org":       simulatorOrg,
+		"vdc":       simulatorVdc,
+		"vapp_name": "parent-vapp",
+	})
+	if err := vappStore.putLease(lease{Owner: "other-run", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("error writing the lease: %s", err)
+	}
+	if err := vcdClient.lockParentVapp(child); err == nil || !strings.Contains(err.Error(), "held by other-run") {
+		t.Fatalf("expected the parent vApp lock to time out, got %v", err)
+	}
+	if err := vappStore.removeLease(); err != nil {
+		t.Fatal(err)
+	}
+	if err := vcdClient.lockParentVapp(child); err != nil {
+		t.Fatalf("error locking the parent vApp: %s", err)
+	}
+	current, err := vappStore.getLease()
+	if err != nil || current == nil || current.Owner != vcdClient.locks.owner {
+		t.Fatalf("expected a lease owned by %s, got %+v (error %v)", vcdClient.locks.owner, current, err)
+	}
+	vcdClient.unLockParentVapp(child)
+
+	edgeGateway, err := vcdClient.GetNsxtEdgeGateway(simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway)
+	if err != nil {
+		t.Fatalf("error retrieving edge gateway: %s", err)
+	}
+	edgeStore, _ := vcdClient.leaseStoreById(edgeGateway.EdgeGateway.ID)
+	if err := edgeStore.putLease(lease{Owner: "other-run", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("error writing the lease: %s", err)
+	}
+	if err := vcdClient.lockById(edgeGateway.EdgeGateway.ID); err == nil || !strings.Contains(err.Error(), "held by other-run") {
+		t.Fatalf("expected the edge gateway lock to time out, got %v", err)
+	}
+	if err := edgeStore.removeLease(); err != nil {
+		t.Fatal(err)
+	}
+	if err := vcdClient.lockById(edgeGateway.EdgeGateway.ID); err != nil {
+		t.Fatalf("error locking the edge gateway: %s", err)
+	}
+	vcdClient.unlockById(edgeGateway.EdgeGateway.ID)
+	if current, err := edgeStore.getLease(); err != nil || current != nil {
+		t.Fatalf("expected the lease to be removed, got %+v (error %v)", current, err)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+}
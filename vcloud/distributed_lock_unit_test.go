@@ -0,0 +1,182 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// memoryLeaseStore keeps a lease in memory, in place of the metadata of a VCD object
+type memoryLeaseStore struct {
+	mu      sync.Mutex
+	current *lease
+	writes  int
+}
+
+func (store *memoryLeaseStore) getLease() (*lease, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.current == nil {
+		return nil, nil
+	}
+	value := *store.current
+	return &value, nil
+}
+
+func (store *memoryLeaseStore) putLease(value lease) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.current = &value
+	store.writes++
+	return nil
+}
+
+func (store *memoryLeaseStore) removeLease() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.current = nil
+	return nil
+}
+
+func newTestDistributedLocks(ttl, timeout time.Duration) *distributedLocks {
+	locks := newDistributedLocks(ttl, timeout)
+	locks.settleDelay = 0
+	return locks
+}
+
+// Test_distributedLocksAcquireRelease checks that a lease is written when the object is free, and removed on release
+func Test_distributedLocksAcquireRelease(t *testing.T) {
+	locks := newTestDistributedLocks(time.Minute, time.Minute)
+	store := &memoryLeaseStore{}
+	if err := locks.acquire("key", "object", store); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	current, _ := store.getLease()
+	if current == nil || current.Owner != locks.owner || !current.Expires.After(time.Now()) {
+		t.Fatalf("expected a valid lease owned by %s, got %+v", locks.owner, current)
+	}
+	locks.release("key")
+	if current, _ := store.getLease(); current != nil {
+		t.Fatalf("expected the lease to be removed, got %+v", current)
+	}
+	// Releasing a key that is not held does nothing
+	locks.release("key")
+}
+
+// Test_distributedLocksWait checks that a valid lease of another owner is waited for, and broken once expired
+func Test_distributedLocksWait(t *testing.T) {
+	locks := newTestDistributedLocks(time.Minute, time.Minute)
+	store := &memoryLeaseStore{current: &lease{Owner: "other", Expires: time.Now().Add(time.Second)}}
+	start := time.Now()
+	if err := locks.acquire("key", "object", store); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for the lease of the other owner to expire, waited %s", elapsed)
+	}
+	if current, _ := store.getLease(); current == nil || current.Owner != locks.owner {
+		t.Fatalf("expected the stale lease to be broken, got %+v", current)
+	}
+	locks.release("key")
+}
+
+// Test_distributedLocksTimeout checks that acquiring fails when the lease of another owner outlives the timeout,
+// and that the lease is left untouched
+func Test_distributedLocksTimeout(t *testing.T) {
+	locks := newTestDistributedLocks(time.Minute, 0)
+	other := lease{Owner: "other", Expires: time.Now().Add(time.Hour)}
+	store := &memoryLeaseStore{current: &other}
+	err := locks.acquire("key", "object", store)
+	if err == nil || !strings.Contains(err.Error(), "held by other") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if current, _ := store.getLease(); current == nil || *current != other || store.writes != 0 {
+		t.Fatalf("expected the lease of the other owner to be untouched, got %+v", current)
+	}
+}
+
+// Test_distributedLocksRenewal checks that a held lease is renewed, and not removed on release once taken over
+func Test_distributedLocksRenewal(t *testing.T) {
+	locks := newTestDistributedLocks(300*time.Millisecond, time.Minute)
+	store := &memoryLeaseStore{}
+	if err := locks.acquire("key", "object", store); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if current, _ := store.getLease(); current == nil || !current.Expires.After(time.Now()) {
+		t.Fatalf("expected the lease to be renewed, got %+v", current)
+	}
+
+	locks.release("key")
+
+	// A lease taken over by another owner is not removed. The TTL is long enough not to renew it meanwhile
+	locks = newTestDistributedLocks(time.Hour, time.Minute)
+	if err := locks.acquire("key", "object", store); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	other := lease{Owner: "other", Expires: time.Now().Add(time.Hour)}
+	_ = store.putLease(other)
+	locks.release("key")
+	if current, _ := store.getLease(); current == nil || *current != other {
+		t.Fatalf("expected the lease of the other owner to be kept, got %+v", current)
+	}
+}
+
+// Test_leaseValue checks the conversion of leases to metadata values
+func Test_leaseValue(t *testing.T) {
+	value := lease{Owner: "host/1/abcd", Expires: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	encoded, err := marshalLease(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	decoded, err := unmarshalLease(encoded)
+	if err != nil || !decoded.Expires.Equal(value.Expires) || decoded.Owner != value.Owner {
+		t.Fatalf("expected %+v, got %+v (error %v)", value, decoded, err)
+	}
+	if _, err := unmarshalLease("not a lease"); err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+}
+
+// Test_distributedLockEntryIgnored checks that the lease never reaches the metadata in the state, and that an update of
+// the metadata doesn't touch it
+func Test_distributedLockEntryIgnored(t *testing.T) {
+	entry := func(key string) *types.MetadataEntry {
+		return &types.MetadataEntry{Key: key, TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: "v"}}
+	}
+	metadata := removeDistributedLockEntry([]*types.MetadataEntry{entry("owner"), entry(distributedLockKey)})
+	if len(metadata) != 1 || metadata[0].Key != "owner" {
+		t.Errorf("expected only the lease to be removed, got %v", metadata)
+	}
+
+	openApiEntry := func(namespace, key string) *types.OpenApiMetadataEntry {
+		return &types.OpenApiMetadataEntry{KeyValue: types.OpenApiMetadataKeyValue{Namespace: namespace, Key: key,
+			Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "v"}}}
+	}
+	openApiMetadata := removeOpenApiDistributedLockEntry([]*types.OpenApiMetadataEntry{
+		openApiEntry("", distributedLockKey), openApiEntry("other", distributedLockKey),
+	})
+	if len(openApiMetadata) != 1 || openApiMetadata[0].KeyValue.Namespace != "other" {
+		t.Errorf("expected only the lease without namespace to be removed, got %v", openApiMetadata)
+	}
+
+	// A lease that reached the state of an older provider version is neither deleted nor written
+	stateEntry := func(key, value string) interface{} {
+		return map[string]interface{}{"key": key, "value": value, "type": types.OpenApiMetadataStringEntry,
+			"domain": "TENANT", "namespace": "", "readonly": false, "persistent": false}
+	}
+	toAdd, toUpdate, toDelete, err := getOpenApiMetadataOperations(
+		[]interface{}{stateEntry("owner", "a"), stateEntry(distributedLockKey, "lease")},
+		[]interface{}{stateEntry("owner", "b")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(toAdd) != 0 || len(toDelete) != 0 || len(toUpdate) != 1 || toUpdate[0].KeyValue.Key != "owner" {
+		t.Errorf("expected only the update of 'owner', got add %v, update %v, delete %v", toAdd, toUpdate, toDelete)
+	}
+}
@@ -1,7 +1,9 @@
 package vcloud
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -183,3 +185,28 @@ func nameOrFilterIsSet(d *schema.ResourceData) bool {
 	_, filterOk := d.GetOk("filter")
 	return nameOk || filterOk
 }
+
+// listFilterSchema returns the filter block of the plural data sources, which retrieve all the entities
+// matching the criteria instead of a single one
+func listFilterSchema(label string, criteria map[string]*schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		MaxItems:    1,
+		Optional:    true,
+		Description: fmt.Sprintf("Criteria for retrieving %s by various attributes. All %s are retrieved when not set", label, label),
+		Elem: &schema.Resource{
+			Schema: criteria,
+		},
+	}
+}
+
+// listFilterId returns the ID of a plural data source, as a hash of the entity that holds the results and of
+// the filter block, so that data sources with different criteria on the same entity get different IDs
+func listFilterId(parentId string, filter interface{}) string {
+	// Lists and maps are encoded in a stable order, with sorted keys
+	encodedFilter, err := json.Marshal(filter)
+	if err != nil {
+		encodedFilter = []byte(fmt.Sprintf("%#v", filter))
+	}
+	return strconv.Itoa(hashcodeString(parentId + string(encodedFilter)))
+}
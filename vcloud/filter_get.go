@@ -2,6 +2,7 @@ package vcloud
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/types/v56"
@@ -37,6 +38,55 @@ func getEntityByFilter(search searchByFilterFunc, queryType, label string, filte
 	return queryItems[0], nil
 }
 
+// getEntitiesByFilter is the counterpart of getEntityByFilter for the plural data sources.
+// Returns all the matching items, which may be none
+func getEntitiesByFilter(search searchByFilterFunc, queryType string, filter interface{}) ([]govcd.QueryItem, error) {
+	criteria, err := buildCriteria(filter)
+	if err != nil {
+		return nil, err
+	}
+	queryItems, _, err := search(queryType, criteria)
+	if err != nil {
+		return nil, err
+	}
+	return queryItems, nil
+}
+
+// metadataMatchesCriteria evaluates the metadata definitions of a filter block for the entities that are not
+// supported by the search engine. Values are regular expressions, unless useApiSearch requests exact matches
+func metadataMatchesCriteria(metadata *types.Metadata, definitions []govcd.MetadataDef, useApiSearch bool) (bool, error) {
+	for _, definition := range definitions {
+		wanted := fmt.Sprintf("%v", definition.Value)
+		var re *regexp.Regexp
+		if !useApiSearch {
+			var err error
+			re, err = regexp.Compile(wanted)
+			if err != nil {
+				return false, fmt.Errorf("error compiling regular expression '%s': %s", wanted, err)
+			}
+		}
+		found := false
+		if metadata != nil {
+			for _, entry := range metadata.MetadataEntry {
+				isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+				if entry.Key != definition.Key || isSystem != definition.IsSystem || entry.TypedValue == nil {
+					continue
+				}
+				if useApiSearch {
+					found = entry.TypedValue.Value == wanted
+				} else {
+					found = re.MatchString(entry.TypedValue.Value)
+				}
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // getCatalogByFilter finds a catalog using a filter block
 func getCatalogByFilter(org *govcd.AdminOrg, filter interface{}, isSysAdmin bool) (*govcd.AdminCatalog, error) {
 	queryType := types.QtCatalog
@@ -0,0 +1,141 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_metadataMatchesCriteria checks the local evaluation of metadata criteria, used by the plural
+// data sources whose entities are not supported by the search engine
+func Test_metadataMatchesCriteria(t *testing.T) {
+	metadata := &types.Metadata{
+		MetadataEntry: []*types.MetadataEntry{
+			testMetadataEntry("env", testMetadataValue("prod", false)),
+			testMetadataEntry("tier", testMetadataValue("backend-1", false)),
+			testMetadataEntry("managed", testMetadataValue("yes", true)),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		definitions  []govcd.MetadataDef
+		useApiSearch bool
+		metadata     *types.Metadata
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name:        "NoDefinitions",
+			definitions: nil,
+			metadata:    metadata,
+			want:        true,
+		},
+		{
+			name:        "ExactValue",
+			definitions: []govcd.MetadataDef{{Key: "env", Value: "prod"}},
+			metadata:    metadata,
+			want:        true,
+		},
+		{
+			name:        "RegexValue",
+			definitions: []govcd.MetadataDef{{Key: "env", Value: "prod"}, {Key: "tier", Value: "^backend-\\d$"}},
+			metadata:    metadata,
+			want:        true,
+		},
+		{
+			name:         "RegexValueWithApiSearch",
+			definitions:  []govcd.MetadataDef{{Key: "tier", Value: "^backend-\\d$"}},
+			useApiSearch: true,
+			metadata:     metadata,
+			want:         false,
+		},
+		{
+			name:        "WrongValue",
+			definitions: []govcd.MetadataDef{{Key: "env", Value: "^test$"}},
+			metadata:    metadata,
+			want:        false,
+		},
+		{
+			name:        "MissingKey",
+			definitions: []govcd.MetadataDef{{Key: "owner", Value: ".*"}},
+			metadata:    metadata,
+			want:        false,
+		},
+		{
+			name:        "SystemKeyNotRequested",
+			definitions: []govcd.MetadataDef{{Key: "managed", Value: "yes"}},
+			metadata:    metadata,
+			want:        false,
+		},
+		{
+			name:        "SystemKey",
+			definitions: []govcd.MetadataDef{{Key: "managed", Value: "yes", IsSystem: true}},
+			metadata:    metadata,
+			want:        true,
+		},
+		{
+			name:        "NoMetadata",
+			definitions: []govcd.MetadataDef{{Key: "env", Value: "prod"}},
+			metadata:    nil,
+			want:        false,
+		},
+		{
+			name:        "InvalidRegex",
+			definitions: []govcd.MetadataDef{{Key: "env", Value: "(prod"}},
+			metadata:    metadata,
+			wantErr:     true,
+		},
+		{
+			name:         "ExactValueWithRegexCharactersAndApiSearch",
+			definitions:  []govcd.MetadataDef{{Key: "env", Value: "(prod"}},
+			useApiSearch: true,
+			metadata: &types.Metadata{
+				MetadataEntry: []*types.MetadataEntry{testMetadataEntry("env", testMetadataValue("(prod", false))},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := metadataMatchesCriteria(tt.metadata, tt.definitions, tt.useApiSearch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("metadataMatchesCriteria() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("metadataMatchesCriteria() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_listFilterId checks that plural data sources get a stable ID that depends on their filter
+func Test_listFilterId(t *testing.T) {
+	filter := func(nameRegex, metadataValue string) interface{} {
+		return []interface{}{map[string]interface{}{
+			"name_regex": nameRegex,
+			"metadata": []interface{}{
+				map[string]interface{}{"key": "env", "value": metadataValue, "is_system": false},
+			},
+		}}
+	}
+	vdcId := "urn:vcloud:vdc:8a2d3f1b-9e7c-4b0a-a1d2-8a2d3f1b2c4e"
+
+	id := listFilterId(vdcId, filter("^web", "prod"))
+	if id != listFilterId(vdcId, filter("^web", "prod")) {
+		t.Errorf("expected the same ID for the same filter")
+	}
+	for name, otherId := range map[string]string{
+		"OtherName":     listFilterId(vdcId, filter("^db", "prod")),
+		"OtherMetadata": listFilterId(vdcId, filter("^web", "test")),
+		"NoFilter":      listFilterId(vdcId, []interface{}{}),
+		"OtherParent":   listFilterId("urn:vcloud:vdc:11111111-2222-3333-4444-555555555555", filter("^web", "prod")),
+	} {
+		if otherId == id {
+			t.Errorf("%s: expected an ID different from %s", name, id)
+		}
+	}
+}
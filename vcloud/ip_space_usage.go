@@ -0,0 +1,244 @@
+package vcloud
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// IP Space utilization is computed by the provider from the ranges and prefixes of the IP Space and
+// from its allocations. VCD only reports totals, and does not list which addresses or prefixes are
+// still free.
+
+// ipBlock is a block of contiguous IP addresses, both ends included
+type ipBlock struct {
+	start netip.Addr
+	end   netip.Addr
+}
+
+func (block ipBlock) String() string {
+	if block.start == block.end {
+		return block.start.String()
+	}
+	return block.start.String() + "-" + block.end.String()
+}
+
+// size returns the number of addresses in the block, capped at math.MaxInt64 for large IPv6 blocks
+func (block ipBlock) size() int64 {
+	start, end := block.start.As16(), block.end.As16()
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end[:]), new(big.Int).SetBytes(start[:]))
+	size.Add(size, big.NewInt(1))
+	if !size.IsInt64() {
+		return math.MaxInt64
+	}
+	return size.Int64()
+}
+
+// overlaps returns true if the two blocks have at least one address in common
+func (block ipBlock) overlaps(other ipBlock) bool {
+	return block.start.Compare(other.end) <= 0 && other.start.Compare(block.end) <= 0
+}
+
+// contains returns true if all the addresses of other are in the block
+func (block ipBlock) contains(other ipBlock) bool {
+	return block.start.Compare(other.start) <= 0 && other.end.Compare(block.end) <= 0
+}
+
+// parseIpBlock parses a single IP address, a range such as 10.0.0.1-10.0.0.9, or a CIDR
+func parseIpBlock(value string) (ipBlock, error) {
+	if startText, endText, isRange := strings.Cut(value, "-"); isRange {
+		start, err := netip.ParseAddr(strings.TrimSpace(startText))
+		if err != nil {
+			return ipBlock{}, err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(endText))
+		if err != nil {
+			return ipBlock{}, err
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return ipBlock{}, fmt.Errorf("invalid IP range '%s'", value)
+		}
+		return ipBlock{start: start, end: end}, nil
+	}
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return ipBlock{}, err
+		}
+		return prefixBlock(prefix.Masked()), nil
+	}
+	address, err := netip.ParseAddr(value)
+	if err != nil {
+		return ipBlock{}, err
+	}
+	return ipBlock{start: address, end: address}, nil
+}
+
+// prefixBlock returns the block of addresses of a masked prefix
+func prefixBlock(prefix netip.Prefix) ipBlock {
+	end := prefix.Addr().As16()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	for i := 15; i >= 0 && hostBits > 0; i-- {
+		bits := min(hostBits, 8)
+		end[i] |= byte(1<<bits - 1)
+		hostBits -= bits
+	}
+	last := netip.AddrFrom16(end)
+	if prefix.Addr().Is4() {
+		last = last.Unmap()
+	}
+	return ipBlock{start: prefix.Addr(), end: last}
+}
+
+// freeIpBlocks returns the parts of ranges not covered by the used blocks, sorted by address
+func freeIpBlocks(ranges, used []ipBlock) []ipBlock {
+	used = append([]ipBlock(nil), used...)
+	sort.Slice(used, func(i, j int) bool { return used[i].start.Less(used[j].start) })
+
+	var free []ipBlock
+	for _, ipRange := range ranges {
+		next, exhausted := ipRange.start, false
+		for _, block := range used {
+			if exhausted || !block.overlaps(ipBlock{start: next, end: ipRange.end}) {
+				continue
+			}
+			if next.Less(block.start) {
+				free = append(free, ipBlock{start: next, end: block.start.Prev()})
+			}
+			if block.end.Compare(ipRange.end) >= 0 {
+				exhausted = true
+				continue
+			}
+			next = block.end.Next()
+		}
+		if !exhausted {
+			free = append(free, ipBlock{start: next, end: ipRange.end})
+		}
+	}
+	sort.Slice(free, func(i, j int) bool { return free[i].start.Less(free[j].start) })
+	return free
+}
+
+// findContiguousIpBlock returns the first block of 'quantity' contiguous addresses within the free blocks
+func findContiguousIpBlock(free []ipBlock, quantity int) (ipBlock, bool) {
+	for _, block := range free {
+		if block.size() < int64(quantity) {
+			continue
+		}
+		end := block.start
+		for i := 1; i < quantity; i++ {
+			end = end.Next()
+		}
+		return ipBlock{start: block.start, end: end}, true
+	}
+	return ipBlock{}, false
+}
+
+// ipPrefixUsage is the utilization of the prefixes with the same length, which VCD treats as a single unit
+type ipPrefixUsage struct {
+	prefixLength int
+	total        int
+	allocated    int
+	used         int
+	free         []string
+}
+
+// ipSpacePrefixUsage returns the utilization of the prefix sequences of an IP Space, sorted by prefix length,
+// given the values of the IP_PREFIX allocations
+func ipSpacePrefixUsage(ipSpace *types.IpSpace, allocations []*types.IpSpaceIpAllocation) ([]*ipPrefixUsage, error) {
+	allocated := make(map[string]*types.IpSpaceIpAllocation)
+	for _, allocation := range allocations {
+		prefix, err := netip.ParsePrefix(allocation.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing allocated IP prefix '%s': %s", allocation.Value, err)
+		}
+		allocated[prefix.Masked().String()] = allocation
+	}
+
+	byLength := make(map[int]*ipPrefixUsage)
+	for _, prefixes := range ipSpace.IPSpacePrefixes {
+		for _, sequence := range prefixes.IPPrefixSequence {
+			start, err := netip.ParseAddr(sequence.StartingPrefixIPAddress)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing IP prefix sequence '%s': %s", sequence.StartingPrefixIPAddress, err)
+			}
+			usage := byLength[sequence.PrefixLength]
+			if usage == nil {
+				usage = &ipPrefixUsage{prefixLength: sequence.PrefixLength}
+				byLength[sequence.PrefixLength] = usage
+			}
+			prefix, err := start.Prefix(sequence.PrefixLength)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < sequence.TotalPrefixCount; i++ {
+				usage.total++
+				if allocation, ok := allocated[prefix.String()]; ok {
+					usage.allocated++
+					if allocation.UsageState != "UNUSED" {
+						usage.used++
+					}
+				} else {
+					usage.free = append(usage.free, prefix.String())
+				}
+				next := prefixBlock(prefix).end.Next()
+				if !next.IsValid() {
+					break
+				}
+				prefix = netip.PrefixFrom(next, sequence.PrefixLength)
+			}
+		}
+	}
+
+	result := make([]*ipPrefixUsage, 0, len(byLength))
+	for _, usage := range byLength {
+		result = append(result, usage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].prefixLength < result[j].prefixLength })
+	return result, nil
+}
+
+// ipSpaceRangeBlocks returns the IP ranges of an IP Space as blocks
+func ipSpaceRangeBlocks(ipSpace *types.IpSpace) ([]ipBlock, error) {
+	blocks := make([]ipBlock, 0, len(ipSpace.IPSpaceRanges.IPRanges))
+	for _, ipRange := range ipSpace.IPSpaceRanges.IPRanges {
+		block, err := parseIpBlock(ipRange.StartIPAddress + "-" + ipRange.EndIPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing IP range: %s", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// allocationBlocks returns the addresses of the given allocations as blocks
+func allocationBlocks(allocations []*types.IpSpaceIpAllocation) ([]ipBlock, error) {
+	blocks := make([]ipBlock, 0, len(allocations))
+	for _, allocation := range allocations {
+		block, err := parseIpBlock(allocation.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing allocated IP '%s': %s", allocation.Value, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// getIpSpaceAllocations returns the allocations of the given type in an IP Space
+func getIpSpaceAllocations(ipSpace *govcd.IpSpace, allocationType string) ([]*types.IpSpaceIpAllocation, error) {
+	allocations, err := ipSpace.GetAllIpSpaceAllocations(allocationType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving %s allocations of IP Space '%s': %s", allocationType, ipSpace.IpSpace.Name, err)
+	}
+	result := make([]*types.IpSpaceIpAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		result = append(result, allocation.IpSpaceIpAllocation)
+	}
+	return result, nil
+}
@@ -0,0 +1,241 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func mustParseIpBlocks(t *testing.T, values ...string) []ipBlock {
+	var blocks []ipBlock
+	for _, value := range values {
+		block, err := parseIpBlock(value)
+		if err != nil {
+			t.Fatalf("error parsing %s: %s", value, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func ipBlockStrings(blocks []ipBlock) string {
+	var result []string
+	for _, block := range blocks {
+		result = append(result, block.String())
+	}
+	return strings.Join(result, ",")
+}
+
+// Test_parseIpBlock checks the parsing of single addresses, ranges and CIDRs, and the size of the blocks
+func Test_parseIpBlock(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		size  int64
+	}{
+		{"10.0.0.5", "10.0.0.5", 1},
+		{"10.0.0.1-10.0.0.10", "10.0.0.1-10.0.0.10", 10},
+		{"10.0.0.250-10.0.1.4", "10.0.0.250-10.0.1.4", 11},
+		{"10.0.0.8/29", "10.0.0.8-10.0.0.15", 8},
+		{"10.0.0.13/29", "10.0.0.8-10.0.0.15", 8},
+		{"10.0.0.0/16", "10.0.0.0-10.0.255.255", 65536},
+		{"2001:db8::/126", "2001:db8::-2001:db8::3", 4},
+		{"2001:db8::/32", "2001:db8::-2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		block, err := parseIpBlock(tt.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.value, err)
+			continue
+		}
+		if block.String() != tt.want || block.size() != tt.size {
+			t.Errorf("%s: expected %s with %d addresses, got %s with %d", tt.value, tt.want, tt.size, block, block.size())
+		}
+	}
+	for _, value := range []string{"10.0.0.10-10.0.0.1", "10.0.0.1-2001:db8::1", "10.0.0.256", "10.0.0.0/33"} {
+		if _, err := parseIpBlock(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+// Test_freeIpBlocks checks the subtraction of allocations from IP ranges, and the search of contiguous blocks
+func Test_freeIpBlocks(t *testing.T) {
+	ranges := mustParseIpBlocks(t, "10.0.0.1-10.0.0.10", "10.0.1.1-10.0.1.3", "10.0.2.1-10.0.2.2")
+	used := mustParseIpBlocks(t, "10.0.0.4", "10.0.0.1", "10.0.0.5", "10.0.0.10", "10.0.1.2", "10.0.2.1-10.0.2.2")
+
+	free := freeIpBlocks(ranges, used)
+	if got, want := ipBlockStrings(free), "10.0.0.2-10.0.0.3,10.0.0.6-10.0.0.9,10.0.1.1,10.0.1.3"; got != want {
+		t.Fatalf("expected free blocks %s, got %s", want, got)
+	}
+	if got := ipBlockStrings(freeIpBlocks(ranges, nil)); got != "10.0.0.1-10.0.0.10,10.0.1.1-10.0.1.3,10.0.2.1-10.0.2.2" {
+		t.Errorf("expected the whole ranges without allocations, got %s", got)
+	}
+
+	tests := []struct {
+		quantity int
+		want     string
+	}{
+		{1, "10.0.0.2"},
+		{2, "10.0.0.2-10.0.0.3"},
+		{3, "10.0.0.6-10.0.0.8"},
+		{4, "10.0.0.6-10.0.0.9"},
+	}
+	for _, tt := range tests {
+		block, found := findContiguousIpBlock(free, tt.quantity)
+		if !found || block.String() != tt.want {
+			t.Errorf("quantity %d: expected %s, got %s (found %t)", tt.quantity, tt.want, block, found)
+		}
+	}
+	if block, found := findContiguousIpBlock(free, 5); found {
+		t.Errorf("expected no block of 5 addresses, got %s", block)
+	}
+}
+
+// Test_computeIpSpaceUsage checks the utilization of ranges and prefixes, and the consumption of each Org
+// with custom and default quotas
+func Test_computeIpSpaceUsage(t *testing.T) {
+	ipSpace := &types.IpSpace{
+		IPSpaceRanges: types.IPSpaceRanges{
+			IPRanges: []types.IpSpaceRangeValues{
+				{StartIPAddress: "11.11.11.100", EndIPAddress: "11.11.11.109"},
+				{StartIPAddress: "11.11.12.1", EndIPAddress: "11.11.12.2"},
+			},
+			DefaultFloatingIPQuota: 2,
+		},
+		IPSpacePrefixes: []types.IPSpacePrefixes{
+			{
+				IPPrefixSequence: []types.IPPrefixSequence{
+					{StartingPrefixIPAddress: "10.10.10.96", PrefixLength: 29, TotalPrefixCount: 4},
+				},
+				DefaultQuotaForPrefixLength: 1,
+			},
+			{
+				IPPrefixSequence: []types.IPPrefixSequence{
+					{StartingPrefixIPAddress: "10.10.20.0", PrefixLength: 30, TotalPrefixCount: 2},
+				},
+				DefaultQuotaForPrefixLength: -1,
+			},
+		},
+	}
+	org1 := &types.OpenApiReference{ID: "urn:vcloud:org:1", Name: "org1"}
+	org2 := &types.OpenApiReference{ID: "urn:vcloud:org:2", Name: "org2"}
+	org3 := &types.OpenApiReference{ID: "urn:vcloud:org:3", Name: "org3"}
+	floatingIps := []*types.IpSpaceIpAllocation{
+		{Value: "11.11.11.100", UsageState: "USED", OrgRef: org1},
+		{Value: "11.11.11.101", UsageState: "UNUSED", OrgRef: org1},
+		{Value: "11.11.11.105", UsageState: "USED_MANUAL", OrgRef: org2},
+		{Value: "11.11.12.2", UsageState: "UNUSED", OrgRef: org2},
+	}
+	ipPrefixes := []*types.IpSpaceIpAllocation{
+		{Value: "10.10.10.104/29", UsageState: "USED", OrgRef: org1},
+		{Value: "10.10.20.4/30", UsageState: "UNUSED", OrgRef: org2},
+	}
+	assignments := []*types.IpSpaceOrgAssignment{
+		{
+			OrgRef: org2,
+			CustomQuotas: &types.IpSpaceOrgAssignmentQuotas{
+				FloatingIPQuota: addrOf(5),
+				IPPrefixQuotas:  []types.IpSpaceOrgAssignmentIPPrefixQuotas{{PrefixLength: addrOf(29), Quota: addrOf(3)}},
+			},
+		},
+		{OrgRef: org3},
+	}
+
+	usage, err := computeIpSpaceUsage(ipSpace, floatingIps, ipPrefixes, assignments)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantFloatingIps := []map[string]interface{}{{"total_count": int64(12), "allocated_count": int64(4), "used_count": int64(2), "free_count": int64(8)}}
+	if !reflect.DeepEqual(usage["floating_ips"], wantFloatingIps) {
+		t.Errorf("expected floating IPs %v, got %v", wantFloatingIps, usage["floating_ips"])
+	}
+	ranges := usage["ip_ranges"].([]map[string]interface{})
+	if len(ranges) != 2 || ranges[0]["allocated_count"] != int64(3) || ranges[0]["free_count"] != int64(7) ||
+		ranges[1]["allocated_count"] != int64(1) || ranges[1]["free_count"] != int64(1) {
+		t.Errorf("unexpected IP ranges %v", ranges)
+	}
+	var freeBlocks []string
+	for _, block := range usage["free_ip_blocks"].([]map[string]interface{}) {
+		freeBlocks = append(freeBlocks, block["start_address"].(string)+"-"+block["end_address"].(string))
+	}
+	if got, want := strings.Join(freeBlocks, ","), "11.11.11.102-11.11.11.104,11.11.11.106-11.11.11.109,11.11.12.1-11.11.12.1"; got != want {
+		t.Errorf("expected free blocks %s, got %s", want, got)
+	}
+
+	wantPrefixes := []map[string]interface{}{
+		{"prefix_length": 29, "total_count": 4, "allocated_count": 1, "used_count": 1, "free_count": 3,
+			"free_prefixes": []string{"10.10.10.96/29", "10.10.10.112/29", "10.10.10.120/29"}},
+		{"prefix_length": 30, "total_count": 2, "allocated_count": 1, "used_count": 0, "free_count": 1,
+			"free_prefixes": []string{"10.10.20.0/30"}},
+	}
+	if !reflect.DeepEqual(usage["ip_prefixes"], wantPrefixes) {
+		t.Errorf("expected IP prefixes %v, got %v", wantPrefixes, usage["ip_prefixes"])
+	}
+
+	wantOrgs := []map[string]interface{}{
+		{
+			"org_id": org1.ID, "org_name": "org1", "floating_ip_quota": 2,
+			"floating_ip_allocated_count": 2, "floating_ip_used_count": 1,
+			"ip_prefixes": []map[string]interface{}{
+				{"prefix_length": 29, "quota": 1, "allocated_count": 1, "used_count": 1},
+				{"prefix_length": 30, "quota": -1, "allocated_count": 0, "used_count": 0},
+			},
+		},
+		{
+			"org_id": org2.ID, "org_name": "org2", "floating_ip_quota": 5,
+			"floating_ip_allocated_count": 2, "floating_ip_used_count": 1,
+			"ip_prefixes": []map[string]interface{}{
+				{"prefix_length": 29, "quota": 3, "allocated_count": 0, "used_count": 0},
+				{"prefix_length": 30, "quota": -1, "allocated_count": 1, "used_count": 0},
+			},
+		},
+		{
+			"org_id": org3.ID, "org_name": "org3", "floating_ip_quota": 2,
+			"floating_ip_allocated_count": 0, "floating_ip_used_count": 0,
+			"ip_prefixes": []map[string]interface{}{
+				{"prefix_length": 29, "quota": 1, "allocated_count": 0, "used_count": 0},
+				{"prefix_length": 30, "quota": -1, "allocated_count": 0, "used_count": 0},
+			},
+		},
+	}
+	if !reflect.DeepEqual(usage["org_usage"], wantOrgs) {
+		t.Errorf("expected Org usage\n%v\ngot\n%v", wantOrgs, usage["org_usage"])
+	}
+
+	d := schema.TestResourceDataRaw(t, datasourceVcdIpSpaceUsage().Schema, map[string]interface{}{"ip_space_id": "id"})
+	for field, value := range usage {
+		if err := d.Set(field, value); err != nil {
+			t.Errorf("error setting %s: %s", field, err)
+		}
+	}
+	if got := d.Get("ip_prefixes.0.free_prefixes.1").(string); got != "10.10.10.112/29" {
+		t.Errorf("expected a free prefix in the data source, got %q", got)
+	}
+}
+
+// Test_sortIpAllocationResults checks that the allocations of a range are ordered by IP address
+func Test_sortIpAllocationResults(t *testing.T) {
+	results := []types.IpSpaceIpAllocationRequestResult{
+		{ID: "c", Value: "10.0.0.10"},
+		{ID: "a", Value: "10.0.0.8"},
+		{ID: "b", Value: "10.0.0.9"},
+	}
+	ids, err := sortIpAllocationResults(results)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(ids, ",") != "a,b,c" || results[0].Value != "10.0.0.8" {
+		t.Errorf("expected IDs sorted by IP, got %v", ids)
+	}
+	if _, err := sortIpAllocationResults(nil); err == nil {
+		t.Errorf("expected an error without results")
+	}
+}
@@ -0,0 +1,173 @@
+package vcloud
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// This file contains helpers to update JSON documents unmarshalled with encoding/json, used by the "merge"
+// update mode of Runtime Defined Entities. Patches follow JSON Merge Patch (RFC 7386): objects are merged
+// recursively, a null value removes a property and any other value, including arrays, replaces the target.
+
+// jsonPathWildcard is a path segment that matches any property of an object
+const jsonPathWildcard = "*"
+
+// parseJsonPath splits a path into property names. Two notations are accepted: a JSON Pointer (RFC 6901) like
+// "/status/conditions", and a dotted path like "$.status.conditions" or "status.conditions". In both
+// notations, a "*" segment matches any property
+func parseJsonPath(path string) ([]string, error) {
+	var segments []string
+	switch {
+	case strings.HasPrefix(path, "/"):
+		for _, segment := range strings.Split(path[1:], "/") {
+			segments = append(segments, strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~"))
+		}
+	default:
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if trimmed == "" {
+			return nil, fmt.Errorf("path '%s' must point to a property, not to the whole entity", path)
+		}
+		segments = strings.Split(trimmed, ".")
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("path '%s' contains an empty property name", path)
+		}
+	}
+	return segments, nil
+}
+
+// validateJsonPath is a schema validation function for paths accepted by parseJsonPath
+func validateJsonPath(value interface{}, key string) ([]string, []error) {
+	path, ok := value.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", key)}
+	}
+	if _, err := parseJsonPath(path); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", key, err)}
+	}
+	return nil, nil
+}
+
+// copyJsonValue returns a deep copy of a JSON value
+func copyJsonValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			result[key] = copyJsonValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for index, item := range typedValue {
+			result[index] = copyJsonValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+// removeJsonPath removes the properties matching the path from a document
+func removeJsonPath(document interface{}, segments []string) {
+	object, isObject := document.(map[string]interface{})
+	if !isObject || len(segments) == 0 {
+		return
+	}
+	for key := range object {
+		if segments[0] != jsonPathWildcard && segments[0] != key {
+			continue
+		}
+		if len(segments) == 1 {
+			delete(object, key)
+		} else {
+			removeJsonPath(object[key], segments[1:])
+		}
+	}
+}
+
+// copyJsonPath copies the properties matching the path from one document to another, creating the missing parent
+// objects in the target. Properties that can't be copied, because a parent in the target is not an object, are skipped
+func copyJsonPath(from, to interface{}, segments []string) {
+	source, isSourceObject := from.(map[string]interface{})
+	target, isTargetObject := to.(map[string]interface{})
+	if !isSourceObject || !isTargetObject || len(segments) == 0 {
+		return
+	}
+	for key, value := range source {
+		if segments[0] != jsonPathWildcard && segments[0] != key {
+			continue
+		}
+		if len(segments) == 1 {
+			target[key] = copyJsonValue(value)
+			continue
+		}
+		if _, exists := target[key]; !exists {
+			if _, isObject := value.(map[string]interface{}); isObject {
+				target[key] = map[string]interface{}{}
+			}
+		}
+		copyJsonPath(value, target[key], segments[1:])
+	}
+}
+
+// applyJsonMergePatch applies a JSON Merge Patch to a document and returns the result. The document is modified
+func applyJsonMergePatch(document, patch interface{}) interface{} {
+	patchObject, isPatchObject := patch.(map[string]interface{})
+	if !isPatchObject {
+		return copyJsonValue(patch)
+	}
+	target, isTargetObject := document.(map[string]interface{})
+	if !isTargetObject {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		target[key] = applyJsonMergePatch(target[key], value)
+	}
+	return target
+}
+
+// jsonMergePatchWithRemovals returns a JSON Merge Patch that sets all the values of newDocument and removes the
+// properties of oldDocument that are no longer in newDocument
+func jsonMergePatchWithRemovals(oldDocument, newDocument interface{}) interface{} {
+	oldObject, isOldObject := oldDocument.(map[string]interface{})
+	newObject, isNewObject := newDocument.(map[string]interface{})
+	if !isOldObject || !isNewObject {
+		return copyJsonValue(newDocument)
+	}
+	patch := make(map[string]interface{}, len(newObject))
+	for key, value := range newObject {
+		patch[key] = jsonMergePatchWithRemovals(oldObject[key], value)
+	}
+	for key := range oldObject {
+		if _, found := newObject[key]; !found {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
+
+// isJsonSubset returns true if all the properties of subset have the same value in document. Objects are compared
+// property by property, any other value must be equal
+func isJsonSubset(subset, document interface{}) bool {
+	subsetObject, isSubsetObject := subset.(map[string]interface{})
+	if !isSubsetObject {
+		return reflect.DeepEqual(subset, document)
+	}
+	documentObject, isDocumentObject := document.(map[string]interface{})
+	if !isDocumentObject {
+		return false
+	}
+	for key, value := range subsetObject {
+		documentValue, found := documentObject[key]
+		if !found || !isJsonSubset(value, documentValue) {
+			return false
+		}
+	}
+	return true
+}
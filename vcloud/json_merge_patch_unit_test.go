@@ -0,0 +1,212 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_parseJsonPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "/status", want: []string{"status"}},
+		{path: "/status/conditions", want: []string{"status", "conditions"}},
+		{path: "/metadata/annotations/a~1b~0c", want: []string{"metadata", "annotations", "a/b~c"}},
+		{path: "$.status.conditions", want: []string{"status", "conditions"}},
+		{path: "status.*.phase", want: []string{"status", "*", "phase"}},
+		{path: "$", wantErr: true},
+		{path: "", wantErr: true},
+		{path: "/", wantErr: true},
+		{path: "/status//phase", wantErr: true},
+		{path: "status..phase", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseJsonPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJsonPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJsonPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_applyJsonMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{document: `{"a": "b"}`, patch: `{"a": "c"}`, want: `{"a": "c"}`},
+		{document: `{"a": "b"}`, patch: `{"b": "c"}`, want: `{"a": "b", "b": "c"}`},
+		{document: `{"a": "b"}`, patch: `{"a": null}`, want: `{}`},
+		{document: `{"a": "b", "b": "c"}`, patch: `{"a": null}`, want: `{"b": "c"}`},
+		{document: `{"a": ["b"]}`, patch: `{"a": "c"}`, want: `{"a": "c"}`},
+		{document: `{"a": "c"}`, patch: `{"a": ["b"]}`, want: `{"a": ["b"]}`},
+		{document: `{"a": {"b": "c"}}`, patch: `{"a": {"b": "d", "c": null}}`, want: `{"a": {"b": "d"}}`},
+		{document: `{"a": [{"b": "c"}]}`, patch: `{"a": [1]}`, want: `{"a": [1]}`},
+		{document: `["a", "b"]`, patch: `["c", "d"]`, want: `["c", "d"]`},
+		{document: `{"a": "b"}`, patch: `["c"]`, want: `["c"]`},
+		{document: `{"e": null}`, patch: `{"a": 1}`, want: `{"e": null, "a": 1}`},
+		{document: `[1, 2]`, patch: `{"a": "b", "c": null}`, want: `{"a": "b"}`},
+		{document: `{}`, patch: `{"a": {"bb": {"ccc": null}}}`, want: `{"a": {"bb": {}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got := applyJsonMergePatch(mustUnmarshalJson(t, tt.document), mustUnmarshalJson(t, tt.patch))
+			if want := mustUnmarshalJson(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("applyJsonMergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_jsonMergePatchWithRemovals(t *testing.T) {
+	got := jsonMergePatchWithRemovals(
+		mustUnmarshalJson(t, `{"spec": {"size": 1, "zone": "a", "tags": ["x"]}, "extra": true}`),
+		mustUnmarshalJson(t, `{"spec": {"size": 2, "tags": ["y"]}}`))
+	want := mustUnmarshalJson(t, `{"spec": {"size": 2, "zone": null, "tags": ["y"]}, "extra": null}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonMergePatchWithRemovals() = %v, want %v", got, want)
+	}
+
+	// Without previous document, the patch is the new document
+	newDocument := mustUnmarshalJson(t, `{"spec": {"size": 2}}`)
+	if got := jsonMergePatchWithRemovals(nil, newDocument); !reflect.DeepEqual(got, newDocument) {
+		t.Errorf("jsonMergePatchWithRemovals() = %v, want %v", got, newDocument)
+	}
+}
+
+func Test_removeAndCopyJsonPath(t *testing.T) {
+	document := mustUnmarshalJson(t, `{"status": {"a": {"phase": "up", "x": 1}, "b": {"phase": "down"}}, "spec": {}}`)
+	removeJsonPath(document, []string{"status", "*", "phase"})
+	if want := mustUnmarshalJson(t, `{"status": {"a": {"x": 1}, "b": {}}, "spec": {}}`); !reflect.DeepEqual(document, want) {
+		t.Errorf("removeJsonPath() = %v, want %v", document, want)
+	}
+
+	target := mustUnmarshalJson(t, `{"spec": {"size": 1}, "status": "overwritten"}`)
+	copyJsonPath(mustUnmarshalJson(t, `{"status": {"phase": "up"}, "spec": {"size": 5}}`), target, []string{"status"})
+	if want := mustUnmarshalJson(t, `{"spec": {"size": 1}, "status": {"phase": "up"}}`); !reflect.DeepEqual(target, want) {
+		t.Errorf("copyJsonPath() = %v, want %v", target, want)
+	}
+
+	target = mustUnmarshalJson(t, `{}`)
+	copyJsonPath(mustUnmarshalJson(t, `{"status": {"phase": "up", "x": 1}}`), target, []string{"status", "phase"})
+	if want := mustUnmarshalJson(t, `{"status": {"phase": "up"}}`); !reflect.DeepEqual(target, want) {
+		t.Errorf("copyJsonPath() = %v, want %v", target, want)
+	}
+}
+
+func Test_isJsonSubset(t *testing.T) {
+	document := mustUnmarshalJson(t, `{"spec": {"size": 1, "tags": ["a", "b"]}, "status": {"phase": "up"}}`)
+	tests := []struct {
+		subset string
+		want   bool
+	}{
+		{subset: `{}`, want: true},
+		{subset: `{"spec": {"size": 1}}`, want: true},
+		{subset: `{"spec": {"tags": ["a", "b"]}, "status": {}}`, want: true},
+		{subset: `{"spec": {"tags": ["a"]}}`, want: false},
+		{subset: `{"spec": {"size": 2}}`, want: false},
+		{subset: `{"spec": {"zone": "a"}}`, want: false},
+		{subset: `{"status": "up"}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subset, func(t *testing.T) {
+			if got := isJsonSubset(mustUnmarshalJson(t, tt.subset), document); got != tt.want {
+				t.Errorf("isJsonSubset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildRdeUpdatedEntity(t *testing.T) {
+	current := mustUnmarshalJson(t, `{"spec": {"size": 1, "zone": "a", "owner": "controller"}, "status": {"phase": "up"}}`).(map[string]interface{})
+	previousInput := mustUnmarshalJson(t, `{"spec": {"size": 1, "zone": "a"}}`).(map[string]interface{})
+	input := mustUnmarshalJson(t, `{"spec": {"size": 2}, "status": {"phase": "requested"}}`).(map[string]interface{})
+	ignoredPaths := [][]string{{"status"}}
+
+	got := buildRdeUpdatedEntity("merge", current, previousInput, input, ignoredPaths)
+	if want := mustUnmarshalJson(t, `{"spec": {"size": 2, "owner": "controller"}, "status": {"phase": "up"}}`); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildRdeUpdatedEntity(merge) = %v, want %v", got, want)
+	}
+
+	got = buildRdeUpdatedEntity("replace", current, previousInput, input, ignoredPaths)
+	if want := mustUnmarshalJson(t, `{"spec": {"size": 2}, "status": {"phase": "up"}}`); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildRdeUpdatedEntity(replace) = %v, want %v", got, want)
+	}
+
+	got = buildRdeUpdatedEntity("replace", current, previousInput, input, nil)
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("buildRdeUpdatedEntity(replace) = %v, want %v", got, input)
+	}
+
+	// The current entity is not modified
+	if want := mustUnmarshalJson(t, `{"spec": {"size": 1, "zone": "a", "owner": "controller"}, "status": {"phase": "up"}}`); !reflect.DeepEqual(current, want) {
+		t.Errorf("current entity was modified: %v", current)
+	}
+}
+
+func Test_isRdeEntityInSync(t *testing.T) {
+	computed := mustUnmarshalJson(t, `{"spec": {"size": 2, "owner": "controller"}, "status": {"phase": "up"}}`).(map[string]interface{})
+	tests := []struct {
+		name         string
+		updateMode   string
+		input        string
+		ignoredPaths [][]string
+		want         bool
+	}{
+		{name: "replace with extra properties", updateMode: "replace", input: `{"spec": {"size": 2}}`, want: false},
+		{name: "replace equal", updateMode: "replace", input: `{"spec": {"size": 2, "owner": "controller"}, "status": {"phase": "up"}}`, want: true},
+		{name: "replace ignoring status", updateMode: "replace", input: `{"spec": {"size": 2, "owner": "controller"}}`, ignoredPaths: [][]string{{"status"}}, want: true},
+		{name: "merge owned paths", updateMode: "merge", input: `{"spec": {"size": 2}}`, want: true},
+		{name: "merge drift", updateMode: "merge", input: `{"spec": {"size": 3}}`, want: false},
+		{name: "merge ignoring status", updateMode: "merge", input: `{"spec": {"size": 2}, "status": {"phase": "requested"}}`, ignoredPaths: [][]string{{"status", "phase"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := mustUnmarshalJson(t, tt.input).(map[string]interface{})
+			if got := isRdeEntityInSync(tt.updateMode, computed, input, tt.ignoredPaths); got != tt.want {
+				t.Errorf("isRdeEntityInSync() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resourceVcdRdeCustomizeDiffMergeMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "MergeWithInputEntity", config: map[string]interface{}{"update_mode": "merge", "input_entity": `{"spec": {}}`}},
+		{name: "MergeWithUrl", config: map[string]interface{}{"update_mode": "merge", "input_entity_url": "https://example.com/rde.json"}, wantErr: true},
+		{name: "ReplaceWithUrl", config: map[string]interface{}{"update_mode": "replace", "input_entity_url": "https://example.com/rde.json"}},
+	}
+	resource := resourceVcdRde()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The schema validation is skipped, so that the check doesn't need VCD
+			config := map[string]interface{}{"name": "rde", "rde_type_id": "urn:vcloud:type:vendor:nss:1.0.0", "resolve": true, "validate_input_entity": false}
+			for key, value := range tt.config {
+				config[key] = value
+			}
+			_, err := schema.InternalMap(resource.Schema).Diff(context.Background(), &terraform.InstanceState{},
+				terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CustomizeDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
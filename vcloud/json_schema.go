@@ -0,0 +1,442 @@
+package vcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file contains a JSON Schema validator, used to check Runtime Defined Entities against the schema of their
+// type before sending them to VCD. It covers the validation keywords of drafts 4 to 7, which are the ones accepted
+// by VCD for RDE Types:
+//
+//   - type, enum, const
+//   - properties, required, additionalProperties, patternProperties, minProperties, maxProperties
+//   - items, additionalItems, minItems, maxItems, uniqueItems
+//   - minLength, maxLength, pattern
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//   - allOf, anyOf, oneOf, not, if, then, else
+//   - $ref, pointing to the same document ("#/definitions/...")
+//
+// References to other documents are not followed: the values they describe are accepted without validation.
+// Other keywords, like 'format', 'default' or the VCD 'x-vcloud-*' annotations, are ignored. Patterns that Go
+// can't compile (for instance, with lookarounds) are ignored too, so that the validator never rejects an entity
+// that VCD would accept because of its own limitations.
+
+// jsonSchemaMaxDepth limits the nesting of schemas, which could be infinite with recursive references
+const jsonSchemaMaxDepth = 256
+
+// jsonSchemaError is a validation error, with the path of the offending value in the validated document
+type jsonSchemaError struct {
+	Path    string
+	Message string
+}
+
+func (e jsonSchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+type jsonSchemaValidator struct {
+	root   interface{}
+	errors []jsonSchemaError
+}
+
+// validateJsonSchema validates a JSON document, unmarshalled with encoding/json, against a JSON schema. It returns
+// all the validation errors found, sorted by path
+func validateJsonSchema(jsonSchema map[string]interface{}, document interface{}) []jsonSchemaError {
+	validator := &jsonSchemaValidator{root: jsonSchema}
+	validator.validate(jsonSchema, document, "$", 0)
+	sort.SliceStable(validator.errors, func(i, j int) bool { return validator.errors[i].Path < validator.errors[j].Path })
+	return validator.errors
+}
+
+func (v *jsonSchemaValidator) addError(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, jsonSchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches returns true if the value is valid against the schema, without recording any error
+func (v *jsonSchemaValidator) matches(schema interface{}, value interface{}, path string, depth int) bool {
+	nested := &jsonSchemaValidator{root: v.root}
+	nested.validate(schema, value, path, depth)
+	return len(nested.errors) == 0
+}
+
+func (v *jsonSchemaValidator) validate(rawSchema interface{}, value interface{}, path string, depth int) {
+	if depth > jsonSchemaMaxDepth {
+		v.addError(path, "schema nesting is deeper than %d levels", jsonSchemaMaxDepth)
+		return
+	}
+
+	// Since draft 6, 'true' and 'false' are valid schemas
+	if allowed, isBool := rawSchema.(bool); isBool {
+		if !allowed {
+			v.addError(path, "no value is allowed")
+		}
+		return
+	}
+	schema, isMap := rawSchema.(map[string]interface{})
+	if !isMap {
+		return
+	}
+
+	// Up to draft 7, keywords next to '$ref' are ignored
+	if ref, hasRef := schema["$ref"].(string); hasRef {
+		if !strings.HasPrefix(ref, "#") {
+			log.Printf("[DEBUG] the JSON schema reference '%s' points to another document. %s is not validated", ref, path)
+			return
+		}
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			v.addError(path, "%s", err)
+			return
+		}
+		v.validate(target, value, path, depth+1)
+		return
+	}
+
+	if types, ok := schema["type"]; ok {
+		if !jsonValueHasType(value, types) {
+			v.addError(path, "expected %s, got %s", describeJsonSchemaTypes(types), jsonValueType(value))
+			// The other keywords would only give confusing errors
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonValuesEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addError(path, "value %s is not one of %s", compactJson(value), compactJson(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonValuesEqual(constant, value) {
+		v.addError(path, "value %s is not equal to %s", compactJson(value), compactJson(constant))
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, typedValue, path, depth)
+	case []interface{}:
+		v.validateArray(schema, typedValue, path, depth)
+	case string:
+		v.validateString(schema, typedValue, path)
+	case float64:
+		v.validateNumber(schema, typedValue, path)
+	}
+
+	v.validateCombinations(schema, value, path, depth)
+}
+
+func (v *jsonSchemaValidator) validateObject(schema map[string]interface{}, object map[string]interface{}, path string, depth int) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if nameString, isString := name.(string); isString {
+				if _, found := object[nameString]; !found {
+					v.addError(path, "missing required property '%s'", nameString)
+				}
+			}
+		}
+	}
+	if minProperties, ok := jsonSchemaNumber(schema, "minProperties"); ok && float64(len(object)) < minProperties {
+		v.addError(path, "expected at least %v properties, got %d", minProperties, len(object))
+	}
+	if maxProperties, ok := jsonSchemaNumber(schema, "maxProperties"); ok && float64(len(object)) > maxProperties {
+		v.addError(path, "expected at most %v properties, got %d", maxProperties, len(object))
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	additionalProperties, hasAdditionalProperties := schema["additionalProperties"]
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := jsonPathOfProperty(path, name)
+		matched := false
+		if propertySchema, found := properties[name]; found {
+			matched = true
+			v.validate(propertySchema, object[name], propertyPath, depth+1)
+		}
+		for pattern, propertySchema := range patternProperties {
+			expression, err := regexp.Compile(pattern)
+			if err != nil || !expression.MatchString(name) {
+				continue
+			}
+			matched = true
+			v.validate(propertySchema, object[name], propertyPath, depth+1)
+		}
+		if matched || !hasAdditionalProperties {
+			continue
+		}
+		if allowed, isBool := additionalProperties.(bool); isBool && !allowed {
+			v.addError(path, "property '%s' is not allowed", name)
+			continue
+		}
+		v.validate(additionalProperties, object[name], propertyPath, depth+1)
+	}
+}
+
+func (v *jsonSchemaValidator) validateArray(schema map[string]interface{}, array []interface{}, path string, depth int) {
+	if minItems, ok := jsonSchemaNumber(schema, "minItems"); ok && float64(len(array)) < minItems {
+		v.addError(path, "expected at least %v items, got %d", minItems, len(array))
+	}
+	if maxItems, ok := jsonSchemaNumber(schema, "maxItems"); ok && float64(len(array)) > maxItems {
+		v.addError(path, "expected at most %v items, got %d", maxItems, len(array))
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+		for i := range array {
+			for j := i + 1; j < len(array); j++ {
+				if jsonValuesEqual(array[i], array[j]) {
+					v.addError(path, "items %d and %d are equal, but items must be unique", i, j)
+				}
+			}
+		}
+	}
+
+	switch items := schema["items"].(type) {
+	case []interface{}:
+		// Tuple validation: each item has its own schema, and 'additionalItems' applies to the rest
+		for index, item := range array {
+			itemPath := path + "[" + strconv.Itoa(index) + "]"
+			if index < len(items) {
+				v.validate(items[index], item, itemPath, depth+1)
+				continue
+			}
+			additionalItems, hasAdditionalItems := schema["additionalItems"]
+			if !hasAdditionalItems {
+				break
+			}
+			if allowed, isBool := additionalItems.(bool); isBool && !allowed {
+				v.addError(path, "expected at most %d items, got %d", len(items), len(array))
+				break
+			}
+			v.validate(additionalItems, item, itemPath, depth+1)
+		}
+	case nil:
+	default:
+		for index, item := range array {
+			v.validate(items, item, path+"["+strconv.Itoa(index)+"]", depth+1)
+		}
+	}
+}
+
+func (v *jsonSchemaValidator) validateString(schema map[string]interface{}, value string, path string) {
+	length := float64(utf8.RuneCountInString(value))
+	if minLength, ok := jsonSchemaNumber(schema, "minLength"); ok && length < minLength {
+		v.addError(path, "expected at least %v characters, got %v", minLength, length)
+	}
+	if maxLength, ok := jsonSchemaNumber(schema, "maxLength"); ok && length > maxLength {
+		v.addError(path, "expected at most %v characters, got %v", maxLength, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		expression, err := regexp.Compile(pattern)
+		if err == nil && !expression.MatchString(value) {
+			v.addError(path, "value '%s' does not match pattern '%s'", value, pattern)
+		}
+	}
+}
+
+func (v *jsonSchemaValidator) validateNumber(schema map[string]interface{}, value float64, path string) {
+	// In draft 4, 'exclusiveMinimum' and 'exclusiveMaximum' are booleans that change 'minimum' and 'maximum'.
+	// From draft 6, they are numbers
+	exclusiveMinimum, _ := schema["exclusiveMinimum"].(bool)
+	exclusiveMaximum, _ := schema["exclusiveMaximum"].(bool)
+	if minimum, ok := jsonSchemaNumber(schema, "minimum"); ok {
+		if value < minimum || (exclusiveMinimum && value == minimum) {
+			v.addError(path, "value %v is lower than the minimum %v", value, minimum)
+		}
+	}
+	if maximum, ok := jsonSchemaNumber(schema, "maximum"); ok {
+		if value > maximum || (exclusiveMaximum && value == maximum) {
+			v.addError(path, "value %v is greater than the maximum %v", value, maximum)
+		}
+	}
+	if limit, ok := jsonSchemaNumber(schema, "exclusiveMinimum"); ok && value <= limit {
+		v.addError(path, "value %v must be greater than %v", value, limit)
+	}
+	if limit, ok := jsonSchemaNumber(schema, "exclusiveMaximum"); ok && value >= limit {
+		v.addError(path, "value %v must be lower than %v", value, limit)
+	}
+	if multipleOf, ok := jsonSchemaNumber(schema, "multipleOf"); ok && multipleOf > 0 {
+		quotient := value / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.addError(path, "value %v is not a multiple of %v", value, multipleOf)
+		}
+	}
+}
+
+func (v *jsonSchemaValidator) validateCombinations(schema map[string]interface{}, value interface{}, path string, depth int) {
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, subSchema := range allOf {
+			v.validate(subSchema, value, path, depth+1)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		found := false
+		for _, subSchema := range anyOf {
+			if v.matches(subSchema, value, path, depth+1) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addError(path, "value does not match any of the schemas in 'anyOf'")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matching := 0
+		for _, subSchema := range oneOf {
+			if v.matches(subSchema, value, path, depth+1) {
+				matching++
+			}
+		}
+		if matching != 1 {
+			v.addError(path, "value must match exactly one of the schemas in 'oneOf', but it matches %d", matching)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.matches(not, value, path, depth+1) {
+		v.addError(path, "value must not match the schema in 'not'")
+	}
+	if condition, ok := schema["if"]; ok {
+		if v.matches(condition, value, path, depth+1) {
+			if then, ok := schema["then"]; ok {
+				v.validate(then, value, path, depth+1)
+			}
+		} else if otherwise, ok := schema["else"]; ok {
+			v.validate(otherwise, value, path, depth+1)
+		}
+	}
+}
+
+// resolveRef returns the schema referenced by a JSON pointer into the root schema, like "#/definitions/name"
+func (v *jsonSchemaValidator) resolveRef(ref string) (interface{}, error) {
+	current := v.root
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return current, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, found := node[token]
+			if !found {
+				return nil, fmt.Errorf("reference '%s' not found in the schema", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("reference '%s' not found in the schema", ref)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("reference '%s' not found in the schema", ref)
+		}
+	}
+	return current, nil
+}
+
+// jsonSchemaNumber returns a numeric keyword of a schema
+func jsonSchemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	number, ok := schema[keyword].(float64)
+	return number, ok
+}
+
+// jsonValueType returns the JSON Schema type of a value unmarshalled with encoding/json
+func jsonValueType(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case float64:
+		if typedValue == math.Trunc(typedValue) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonValueHasType returns true if the value has one of the types given by a 'type' keyword
+func jsonValueHasType(value interface{}, types interface{}) bool {
+	var wanted []string
+	switch typedTypes := types.(type) {
+	case string:
+		wanted = []string{typedTypes}
+	case []interface{}:
+		for _, t := range typedTypes {
+			if typeString, ok := t.(string); ok {
+				wanted = append(wanted, typeString)
+			}
+		}
+	default:
+		return true
+	}
+	actual := jsonValueType(value)
+	for _, t := range wanted {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func describeJsonSchemaTypes(types interface{}) string {
+	if typeString, ok := types.(string); ok {
+		return typeString
+	}
+	var names []string
+	if typeList, ok := types.([]interface{}); ok {
+		for _, t := range typeList {
+			names = append(names, fmt.Sprintf("%v", t))
+		}
+	}
+	return "one of " + strings.Join(names, ", ")
+}
+
+func jsonValuesEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func compactJson(value interface{}) string {
+	result, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(result)
+}
+
+var jsonPathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathOfProperty returns the JSON path of an object property, using the bracket notation for names that are not
+// identifiers
+func jsonPathOfProperty(path, name string) string {
+	if jsonPathIdentifier.MatchString(name) {
+		return path + "." + name
+	}
+	return path + "[" + strconv.Quote(name) + "]"
+}
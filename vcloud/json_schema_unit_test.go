@@ -0,0 +1,150 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustUnmarshalJson(t *testing.T, text string) interface{} {
+	var result interface{}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("invalid JSON %s: %s", text, err)
+	}
+	return result
+}
+
+func Test_validateJsonSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		document string
+		want     []string
+	}{
+		{name: "empty schema", schema: `{}`, document: `{"a": 1}`},
+		{name: "type", schema: `{"type": "object"}`, document: `[]`, want: []string{"$: expected object, got array"}},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, document: `null`},
+		{name: "integer is a number", schema: `{"type": "number"}`, document: `3`},
+		{name: "number is not an integer", schema: `{"type": "integer"}`, document: `3.5`, want: []string{"$: expected integer, got number"}},
+		{
+			name:     "required and nested paths",
+			schema:   `{"properties": {"spec": {"required": ["name", "size"], "properties": {"size": {"type": "integer"}}}}}`,
+			document: `{"spec": {"size": "big"}}`,
+			want:     []string{"$.spec: missing required property 'name'", "$.spec.size: expected integer, got string"},
+		},
+		{
+			name:     "additional properties",
+			schema:   `{"properties": {"a": {}}, "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`,
+			document: `{"a": 1, "x-note": "ok", "b": 2}`,
+			want:     []string{"$: property 'b' is not allowed"},
+		},
+		{
+			name:     "additional properties schema",
+			schema:   `{"additionalProperties": {"type": "boolean"}}`,
+			document: `{"enabled": true, "odd key": 1}`,
+			want:     []string{`$["odd key"]: expected boolean, got integer`},
+		},
+		{
+			name:     "array items",
+			schema:   `{"type": "array", "minItems": 1, "maxItems": 2, "uniqueItems": true, "items": {"type": "string"}}`,
+			document: `["a", "a", 3]`,
+			want:     []string{"$: expected at most 2 items, got 3", "$: items 0 and 1 are equal, but items must be unique", "$[2]: expected string, got integer"},
+		},
+		{
+			name:     "tuple items",
+			schema:   `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`,
+			document: `["a", 1, true]`,
+			want:     []string{"$: expected at most 2 items, got 3"},
+		},
+		{
+			name:     "string constraints",
+			schema:   `{"properties": {"name": {"minLength": 2, "maxLength": 4, "pattern": "^[a-z]+$"}}}`,
+			document: `{"name": "Abcde"}`,
+			want:     []string{"$.name: expected at most 4 characters, got 5", "$.name: value 'Abcde' does not match pattern '^[a-z]+$'"},
+		},
+		{name: "unsupported pattern is ignored", schema: `{"pattern": "^(?!x)"}`, document: `"x"`},
+		{
+			name:     "number constraints",
+			schema:   `{"items": [{"minimum": 1}, {"maximum": 10}, {"exclusiveMinimum": 0}, {"multipleOf": 0.5}, {"minimum": 1, "exclusiveMinimum": true}]}`,
+			document: `[0, 11, 0, 1.2, 1]`,
+			want: []string{
+				"$[0]: value 0 is lower than the minimum 1",
+				"$[1]: value 11 is greater than the maximum 10",
+				"$[2]: value 0 must be greater than 0",
+				"$[3]: value 1.2 is not a multiple of 0.5",
+				"$[4]: value 1 is lower than the minimum 1",
+			},
+		},
+		{name: "enum", schema: `{"enum": ["a", 1]}`, document: `"b"`, want: []string{`$: value "b" is not one of ["a",1]`}},
+		{name: "const", schema: `{"const": {"a": 1}}`, document: `{"a": 1}`},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, document: `true`, want: []string{"$: value does not match any of the schemas in 'anyOf'"}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, document: `1`, want: []string{"$: value must match exactly one of the schemas in 'oneOf', but it matches 2"}},
+		{name: "allOf", schema: `{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`, document: `{"a": 1}`, want: []string{"$: missing required property 'b'"}},
+		{name: "not", schema: `{"not": {"type": "null"}}`, document: `null`, want: []string{"$: value must not match the schema in 'not'"}},
+		{
+			name:     "references",
+			schema:   `{"definitions": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}}, "required": ["name"]}}, "$ref": "#/definitions/node"}`,
+			document: `{"name": "root", "children": [{"name": "a"}, {"children": []}]}`,
+			want:     []string{"$.children[1]: missing required property 'name'"},
+		},
+		{name: "missing reference", schema: `{"$ref": "#/definitions/missing"}`, document: `1`, want: []string{"$: reference '#/definitions/missing' not found in the schema"}},
+		{name: "external reference is not validated", schema: `{"properties": {"a": {"$ref": "other.json"}, "b": {"type": "string"}}}`, document: `{"a": 1, "b": 2}`, want: []string{"$.b: expected string, got integer"}},
+		{name: "false schema", schema: `{"properties": {"a": false}}`, document: `{"a": 1}`, want: []string{"$.a: no value is allowed"}},
+		{name: "unknown keywords are ignored", schema: `{"format": "ipv4", "x-vcloud-restricted": "secure"}`, document: `"abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, ok := mustUnmarshalJson(t, tt.schema).(map[string]interface{})
+			if !ok {
+				t.Fatalf("schema %s is not an object", tt.schema)
+			}
+			var got []string
+			for _, validationError := range validateJsonSchema(schema, mustUnmarshalJson(t, tt.document)) {
+				got = append(got, validationError.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateJsonSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func Test_validateJsonSchemaIfThenElse(t *testing.T) {
+	schema := mustUnmarshalJson(t, `{"if": {"properties": {"kind": {"const": "disk"}}}, "then": {"required": ["size"]}, "else": {"required": ["url"]}}`).(map[string]interface{})
+	if errors := validateJsonSchema(schema, mustUnmarshalJson(t, `{"kind": "disk"}`)); len(errors) != 1 || errors[0].Message != "missing required property 'size'" {
+		t.Errorf("unexpected 'then' errors: %v", errors)
+	}
+	if errors := validateJsonSchema(schema, mustUnmarshalJson(t, `{"kind": "iso"}`)); len(errors) != 1 || errors[0].Message != "missing required property 'url'" {
+		t.Errorf("unexpected 'else' errors: %v", errors)
+	}
+}
+
+func Test_validateRdeEntity(t *testing.T) {
+	typeSchema := mustUnmarshalJson(t, `{
+		"type": "object",
+		"definitions": {"settings": {"type": "object", "required": ["ovdcNetwork"]}},
+		"properties": {"spec": {"type": "object", "properties": {"settings": {"$ref": "#/definitions/settings"}}}}
+	}`).(map[string]interface{})
+
+	if err := validateRdeEntity("urn:vcloud:type:vmware:capvcdCluster:1.3.0", typeSchema, "input_entity", `{"spec": {"settings": {"ovdcNetwork": "net"}}}`); err != nil {
+		t.Errorf("unexpected error for a valid entity: %s", err)
+	}
+	if err := validateRdeEntity("urn:vcloud:type:vmware:capvcdCluster:1.3.0", nil, "input_entity", `{"anything": 1}`); err != nil {
+		t.Errorf("unexpected error for a type without schema: %s", err)
+	}
+
+	err := validateRdeEntity("urn:vcloud:type:vmware:capvcdCluster:1.3.0", typeSchema, "input_entity_url", `{"spec": {"settings": {}}}`)
+	want := "'input_entity_url' does not match the schema of RDE Type 'urn:vcloud:type:vmware:capvcdCluster:1.3.0':\n" +
+		"  - $.spec.settings: missing required property 'ovdcNetwork'"
+	if err == nil || err.Error() != want {
+		t.Errorf("validateRdeEntity() error = %v, want %s", err, want)
+	}
+
+	err = validateRdeEntity("urn:vcloud:type:vmware:capvcdCluster:1.3.0", typeSchema, "input_entity", `{"spec": `)
+	if err == nil || !strings.Contains(err.Error(), "'input_entity' is not a valid JSON") {
+		t.Errorf("expected a JSON error, got %v", err)
+	}
+}
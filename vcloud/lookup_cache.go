@@ -0,0 +1,263 @@
+package vcloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// lookupCache keeps the parent objects (Orgs, VDCs and edge gateways) that resources retrieve during a run, so
+// that the same lookups are not repeated for every resource operation.
+//
+// Every entry is tagged with the UUIDs found in the object, which include the object itself and the objects it
+// references, such as the vApps of a VDC. A write request made through the client invalidates the entries tagged
+// with a UUID of its URL, or all of them when the URL has none. A task that completes invalidates the entries
+// tagged with the UUID of its owner, as the change it made is only visible then. A lookup that started before an
+// invalidation is not stored, as its result may already be stale.
+type lookupCache struct {
+	mu         sync.Mutex
+	entries    map[string]*lookupCacheEntry
+	generation uint64
+}
+
+// lookupCacheEntry is an object in the cache, with the UUIDs that invalidate it
+type lookupCacheEntry struct {
+	value interface{}
+	ids   map[string]bool
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{entries: make(map[string]*lookupCacheEntry)}
+}
+
+// get returns the entry stored with key, if any, and the current generation of the cache
+func (cache *lookupCache) get(key string) (interface{}, uint64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry := cache.entries[key]
+	if entry == nil {
+		return nil, cache.generation
+	}
+	return entry.value, cache.generation
+}
+
+// put stores an entry with one or more keys, tagged with the given UUIDs, unless the cache was invalidated after
+// generation
+func (cache *lookupCache) put(generation uint64, value interface{}, ids []string, keys ...string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if generation != cache.generation {
+		return
+	}
+	entry := &lookupCacheEntry{value: value, ids: make(map[string]bool)}
+	for _, id := range ids {
+		entry.ids[strings.ToLower(id)] = true
+	}
+	for _, key := range keys {
+		cache.entries[key] = entry
+	}
+}
+
+// invalidate removes the entries tagged with any of the given UUIDs, or all the entries when there are none
+func (cache *lookupCache) invalidate(ids ...string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generation++
+	if len(ids) == 0 {
+		if len(cache.entries) > 0 {
+			cache.entries = make(map[string]*lookupCacheEntry)
+		}
+		return
+	}
+	for key, entry := range cache.entries {
+		for _, id := range ids {
+			if entry.ids[strings.ToLower(id)] {
+				delete(cache.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// lookupCacheIds returns the UUIDs found in the API data of an object
+func lookupCacheIds(object interface{}) []string {
+	data, err := json.Marshal(object)
+	if err != nil {
+		log.Printf("[DEBUG] unable to find the UUIDs of %T for the lookup cache: %s", object, err)
+		return nil
+	}
+	return getUuidRegex("", "").FindAllString(strings.ToLower(string(data)), -1)
+}
+
+// lookupCacheKey builds a key from the kind of object and the names or IDs that identify it
+func lookupCacheKey(kind string, identifiers ...string) string {
+	return kind + "|" + strings.Join(identifiers, "|")
+}
+
+// cachedLookup returns a copy of the object stored in the cache with key. When there is none, it runs lookup and
+// stores a copy of the result with key and the additional keys returned by otherKeys, if not nil.
+// The cache is bypassed when it is nil, i.e. when disabled by the provider configuration.
+// Callers always get their own copy, which they can modify without affecting the cache.
+func cachedLookup[T any](cache *lookupCache, key string, clone func(*T) (*T, error), lookup func() (*T, error), otherKeys func(*T) []string) (*T, error) {
+	if cache == nil {
+		return lookup()
+	}
+	cached, generation := cache.get(key)
+	if object, ok := cached.(*T); ok {
+		copied, err := clone(object)
+		if err == nil {
+			return copied, nil
+		}
+		log.Printf("[DEBUG] unable to copy cached entry %s, retrieving it again: %s", key, err)
+	}
+
+	object, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := clone(object)
+	if err != nil {
+		log.Printf("[DEBUG] unable to copy %s for the lookup cache: %s", key, err)
+		return object, nil
+	}
+	ids := lookupCacheIds(stored)
+	if len(ids) == 0 {
+		// An entry that no request can invalidate is not stored
+		return object, nil
+	}
+	keys := []string{key}
+	if otherKeys != nil {
+		keys = append(keys, otherKeys(object)...)
+	}
+	cache.put(generation, stored, ids, keys...)
+	return object, nil
+}
+
+// deepCopy returns a copy of value that shares no memory with it
+func deepCopy[T any](value *T) (*T, error) {
+	if value == nil {
+		return nil, nil
+	}
+	copied, err := copystructure.Copy(value)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := copied.(*T)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for a copy of %T", copied, value)
+	}
+	return result, nil
+}
+
+// The clone functions copy the govcd wrappers, keeping their unexported client and parent references, and
+// deep copy the API data that callers may modify.
+
+func cloneOrg(org *govcd.Org) (*govcd.Org, error) {
+	result := *org
+	var err error
+	if result.Org, err = deepCopy(org.Org); err != nil {
+		return nil, err
+	}
+	if result.TenantContext, err = deepCopy(org.TenantContext); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func cloneAdminOrg(adminOrg *govcd.AdminOrg) (*govcd.AdminOrg, error) {
+	result := *adminOrg
+	var err error
+	if result.AdminOrg, err = deepCopy(adminOrg.AdminOrg); err != nil {
+		return nil, err
+	}
+	if result.TenantContext, err = deepCopy(adminOrg.TenantContext); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func cloneVdc(vdc *govcd.Vdc) (*govcd.Vdc, error) {
+	result := *vdc
+	var err error
+	if result.Vdc, err = deepCopy(vdc.Vdc); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func cloneEdgeGateway(edge *govcd.EdgeGateway) (*govcd.EdgeGateway, error) {
+	result := *edge
+	var err error
+	if result.EdgeGateway, err = deepCopy(edge.EdgeGateway); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func cloneNsxtEdgeGateway(edge *govcd.NsxtEdgeGateway) (*govcd.NsxtEdgeGateway, error) {
+	result := *edge
+	var err error
+	if result.EdgeGateway, err = deepCopy(edge.EdgeGateway); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// lookupCacheInvalidator is an http.RoundTripper that invalidates the lookup cache when a request may change
+// the objects in it
+type lookupCacheInvalidator struct {
+	transport http.RoundTripper
+	cache     *lookupCache
+}
+
+func (invalidator *lookupCacheInvalidator) RoundTrip(req *http.Request) (*http.Response, error) {
+	isWrite := req.Method != http.MethodGet && req.Method != http.MethodHead
+	var ids []string
+	if isWrite {
+		ids = getUuidRegex("", "").FindAllString(strings.ToLower(req.URL.Path), -1)
+		invalidator.cache.invalidate(ids...)
+	}
+	resp, err := invalidator.transport.RoundTrip(req)
+	if isWrite {
+		// The changes are visible to the lookups only after the request is completed
+		invalidator.cache.invalidate(ids...)
+	}
+	if err == nil && req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/api/task/") {
+		invalidator.invalidateTaskOwner(resp)
+	}
+	return resp, err
+}
+
+// invalidateTaskOwner invalidates the entries tagged with the owner of a task that is no longer running. The
+// response body is read, and replaced with a copy for the caller
+func (invalidator *lookupCacheInvalidator) invalidateTaskOwner(resp *http.Response) {
+	if resp.StatusCode != http.StatusOK || resp.Body == nil {
+		return
+	}
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	var task types.Task
+	if xml.Unmarshal(data, &task) != nil || isTaskRunning(&task) {
+		return
+	}
+	if task.Owner == nil {
+		invalidator.cache.invalidate()
+		return
+	}
+	ownerIds := getUuidRegex("", "").FindAllString(strings.ToLower(task.Owner.HREF), -1)
+	invalidator.cache.invalidate(ownerIds...)
+}
@@ -0,0 +1,72 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"testing"
+)
+
+// TestSimulatorLookupCache checks that repeated lookups are served from the cache until a write, and that they always reach the API when the cache is disabled
+func TestSimulatorLookupCache(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+
+	_, vdc, err := vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	edge, err := vcdClient.GetNsxtEdgeGateway(simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway)
+	if err != nil {
+		t.Fatalf("error retrieving edge gateway: %s", err)
+	}
+
+	// Repeated lookups, by name or by ID, are served from the cache, and callers get their own copy
+	vdc.Vdc.Name = "modified by the caller"
+	requests := sim.RequestCount()
+	_, vdc, err = vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc)
+	if err != nil {
+		t.Fatalf("error retrieving cached VDC: %s", err)
+	}
+	if vdc.Vdc.Name != simulatorNsxtVdc {
+		t.Fatalf("expected the cached VDC to be unaffected by changes to a copy, got name %q", vdc.Vdc.Name)
+	}
+	cachedEdge, err := vcdClient.GetNsxtEdgeGatewayById(simulatorOrg, edge.EdgeGateway.ID)
+	if err != nil {
+		t.Fatalf("error retrieving cached edge gateway: %s", err)
+	}
+	if cachedEdge.EdgeGateway.Name != simulatorEdgeGateway {
+		t.Fatalf("unexpected edge gateway %q", cachedEdge.EdgeGateway.Name)
+	}
+	if sim.RequestCount() != requests {
+		t.Fatalf("expected cached lookups not to reach the API, got %d requests", sim.RequestCount()-requests)
+	}
+
+	// A write invalidates the cache, so that the lookups see its result
+	vapp, err := vdc.CreateRawVApp("cached-vapp", "")
+	if err != nil {
+		t.Fatalf("error creating vApp: %s", err)
+	}
+	requests = sim.RequestCount()
+	_, vdc, err = vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc)
+	if err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	if sim.RequestCount() == requests {
+		t.Fatal("expected the VDC to be retrieved again after a write")
+	}
+	if _, err := vdc.GetVAppByName(vapp.VApp.Name, false); err != nil {
+		t.Fatalf("expected the VDC to include the new vApp: %s", err)
+	}
+
+	// Without the cache, every lookup reaches the API
+	sim, vcdClient = newSimulatorClient(t, func(config *Config) { config.LookupCache = false })
+	if _, _, err := vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc); err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	requests = sim.RequestCount()
+	if _, _, err := vcdClient.GetOrgAndVdc(simulatorOrg, simulatorNsxtVdc); err != nil {
+		t.Fatalf("error retrieving VDC: %s", err)
+	}
+	if sim.RequestCount() == requests {
+		t.Fatal("expected lookups to reach the API when the cache is disabled")
+	}
+}
@@ -0,0 +1,135 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_lookupCacheGeneration checks that lookups started before an invalidation are not stored
+func Test_lookupCacheGeneration(t *testing.T) {
+	cache := newLookupCache()
+	_, generation := cache.get("key")
+	cache.invalidate()
+	cache.put(generation, "stale", []string{testLookupId}, "key")
+	if value, _ := cache.get("key"); value != nil {
+		t.Fatalf("expected a stale lookup not to be stored, got %v", value)
+	}
+
+	_, generation = cache.get("key")
+	cache.put(generation, "fresh", []string{testLookupId}, "key", "other-key")
+	if value, _ := cache.get("other-key"); value != "fresh" {
+		t.Fatalf("expected the lookup to be stored with all its keys, got %v", value)
+	}
+
+	cache.invalidate("11111111-2222-3333-4444-555555555555")
+	if value, _ := cache.get("key"); value != "fresh" {
+		t.Fatalf("expected an entry with other UUIDs to be kept, got %v", value)
+	}
+	cache.invalidate(strings.ToUpper(testLookupId))
+	if value, _ := cache.get("other-key"); value != nil {
+		t.Fatalf("expected the entry tagged with the UUID to be removed from all its keys, got %v", value)
+	}
+}
+
+// testLookupId is the UUID of the objects stored in the lookup cache by the tests
+const testLookupId = "0f2c1a3e-7b4d-4e2a-9c1f-8d6e5b4a3c2b"
+
+// Test_cachedLookup checks that callers get copies of the cached objects
+func Test_cachedLookup(t *testing.T) {
+	cache := newLookupCache()
+	lookups := 0
+	lookup := func() (*govcd.Vdc, error) {
+		lookups++
+		return &govcd.Vdc{Vdc: &types.Vdc{Name: "vdc", ID: "urn:vcloud:vdc:" + testLookupId}}, nil
+	}
+
+	first, err := cachedLookup(cache, "vdc", cloneVdc, lookup, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	first.Vdc.Name = "changed"
+	second, err := cachedLookup(cache, "vdc", cloneVdc, lookup, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lookups != 1 {
+		t.Fatalf("expected 1 lookup, got %d", lookups)
+	}
+	if second.Vdc.Name != "vdc" {
+		t.Fatalf("expected the cached VDC to be unaffected by changes to a copy, got %q", second.Vdc.Name)
+	}
+
+	if _, err := cachedLookup(nil, "vdc", cloneVdc, lookup, nil); err != nil || lookups != 2 {
+		t.Fatalf("expected a disabled cache to run the lookup, got %d lookups and error %v", lookups, err)
+	}
+}
+
+// Test_lookupCacheInvalidator checks which requests invalidate the cache
+func Test_lookupCacheInvalidator(t *testing.T) {
+	otherId := "11111111-2222-3333-4444-555555555555"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := strings.TrimPrefix(r.URL.Path, "/api/task/")
+		if status == r.URL.Path {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		task := &types.Task{Status: status, Owner: &types.Reference{HREF: "https://vcd/api/vApp/vapp-" + testLookupId}}
+		data, err := xml.Marshal(task)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	cache := newLookupCache()
+	client := http.Client{Transport: &lookupCacheInvalidator{transport: http.DefaultTransport, cache: cache}}
+	tests := []struct {
+		method     string
+		path       string
+		invalidate bool
+	}{
+		{method: http.MethodGet, path: "/api/org/" + testLookupId, invalidate: false},
+		{method: http.MethodGet, path: "/api/task/running", invalidate: false},
+		{method: http.MethodGet, path: "/api/task/success", invalidate: true},
+		{method: http.MethodPost, path: "/api/vdc/" + testLookupId + "/action/composeVApp", invalidate: true},
+		{method: http.MethodPost, path: "/api/vdc/" + otherId + "/action/composeVApp", invalidate: false},
+		{method: http.MethodDelete, path: "/api/vApp/vapp-" + strings.ToUpper(testLookupId), invalidate: true},
+		{method: http.MethodPut, path: "/cloudapi/1.0.0/edgeGateways/urn:vcloud:gateway:" + otherId, invalidate: false},
+		{method: http.MethodPost, path: "/api/sessions", invalidate: true},
+	}
+	for _, tt := range tests {
+		_, generation := cache.get("key")
+		cache.put(generation, "value", []string{testLookupId}, "key")
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s %s: error reading the response: %s", tt.method, tt.path, err)
+		}
+		if strings.HasPrefix(tt.path, "/api/task/") && !bytes.Contains(body, []byte("Task")) {
+			t.Errorf("%s %s: expected the task to be returned to the caller, got %q", tt.method, tt.path, body)
+		}
+		if value, _ := cache.get("key"); (value == nil) != tt.invalidate {
+			t.Errorf("%s %s: expected invalidation %t, got cached value %v", tt.method, tt.path, tt.invalidate, value)
+		}
+	}
+}
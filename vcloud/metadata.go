@@ -3,6 +3,7 @@ package vcloud
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-cty/cty"
@@ -64,6 +65,17 @@ func ignoreMetadataSchema() *schema.Schema {
 	}
 }
 
+// defaultMetadataSchema returns the schema associated to default_metadata for the provider configuration.
+func defaultMetadataSchema() *schema.Schema {
+	entrySchema := metadataEntryResourceSchema("resource")
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Metadata entries added to every resource that supports `metadata_entry` with the XML metadata API. The entries set in the resource with the same key take precedence",
+		Elem:        entrySchema.Elem,
+	}
+}
+
 // IgnoredMetadata extends the SDK IgnoredMetadata type to be able to add also
 // the conflict behavior defined as an attribute in the schema.
 type IgnoredMetadata struct {
@@ -135,6 +147,14 @@ var resourceMetadataApiRelation = map[string]string{
 	"vcd_vapp_vm":               "vApp",
 	"vcd_vm":                    "vApp",
 	"vcd_rde":                   "entity",
+	// The following resources use the generic OpenAPI metadata of entities, and are identified by the type in their URN
+	"vcd_external_network_v2":      "externalNetwork",
+	"vcd_ip_space":                 "ipSpace",
+	"vcd_nsxt_alb_pool":            "loadBalancerPool",
+	"vcd_nsxt_alb_virtual_service": "loadBalancerVirtualService",
+	"vcd_nsxt_edgegateway":         "gateway",
+	"vcd_nsxt_security_group":      "firewallGroup",
+	"vcd_vdc_group":                "vdcGroup",
 }
 
 // metadataEntryDatasourceSchema returns the schema associated to metadata_entry for a given data source.
@@ -283,10 +303,10 @@ type metadataCompatible interface {
 }
 
 // createOrUpdateMetadataEntryInVcd creates or updates metadata entries in VCD for the given resource, only if the attribute
-// metadata_entry has been set or updated in the state.
-func createOrUpdateMetadataEntryInVcd(d *schema.ResourceData, resource metadataCompatible) error {
+// metadata_entry has been set or updated in the state. The provider default metadata is added afterwards.
+func createOrUpdateMetadataEntryInVcd(d *schema.ResourceData, vcdClient *VCDClient, resource metadataCompatible) error {
 	if !d.HasChange("metadata_entry") {
-		return nil
+		return mergeDefaultMetadataInVcd(d, vcdClient, resource)
 	}
 
 	// Delete old metadata from VCD
@@ -295,32 +315,118 @@ func createOrUpdateMetadataEntryInVcd(d *schema.ResourceData, resource metadataC
 	oldKeyMapWithDomain := getMetadataKeyWithDomainMap(oldRaw.(*schema.Set).List())
 	newKeyMapWithDomain := getMetadataKeyWithDomainMap(newMetadata)
 	for oldKey, isSystem := range oldKeyMapWithDomain {
-		if _, newKeyPresent := newKeyMapWithDomain[oldKey]; !newKeyPresent {
-			err := resource.DeleteMetadataEntryWithDomain(oldKey, isSystem)
-			if err != nil {
-				return fmt.Errorf("error deleting metadata entry corresponding to key %s: %s", oldKey, err)
-			}
+		if _, newKeyPresent := newKeyMapWithDomain[oldKey]; newKeyPresent || oldKey == distributedLockKey {
+			continue
+		}
+		// An entry that overrode a default one goes back to the default value, instead of being deleted
+		if defaultValue, isDefault := vcdClient.defaultMetadata[oldKey]; isDefault && isSystemMetadataValue(defaultValue) == isSystem {
+			continue
+		}
+		err := resource.DeleteMetadataEntryWithDomain(oldKey, isSystem)
+		if err != nil {
+			return fmt.Errorf("error deleting metadata entry corresponding to key %s: %s", oldKey, err)
 		}
 	}
 
 	// Update metadata
-	if len(newMetadata) == 0 {
-		return nil
-	}
 	metadataToMerge, err := convertFromStateToMetadataValues(newMetadata)
 	if err != nil {
 		return err
 	}
-	if len(metadataToMerge) == 0 {
+	// The lease of a distributed lock is only written by the lock itself
+	delete(metadataToMerge, distributedLockKey)
+	if len(metadataToMerge) > 0 {
+		err = resource.MergeMetadataWithMetadataValues(metadataToMerge)
+		if err != nil && !strings.Contains(err.Error(), "after filtering metadata, there is no metadata to merge") {
+			return fmt.Errorf("error adding metadata entries: %s", err)
+		}
+	}
+	return mergeDefaultMetadataInVcd(d, vcdClient, resource)
+}
+
+// getDefaultMetadata returns the provider default metadata that applies to the given resource, which are the entries
+// whose keys are not set in its metadata_entry
+func getDefaultMetadata(d *schema.ResourceData, vcdClient *VCDClient) map[string]types.MetadataValue {
+	if len(vcdClient.defaultMetadata) == 0 {
+		return nil
+	}
+	resourceKeys := getMetadataKeyWithDomainMap(d.Get("metadata_entry").(*schema.Set).List())
+	defaults := make(map[string]types.MetadataValue, len(vcdClient.defaultMetadata))
+	for key, value := range vcdClient.defaultMetadata {
+		if _, isSet := resourceKeys[key]; !isSet {
+			defaults[key] = value
+		}
+	}
+	return defaults
+}
+
+// mergeDefaultMetadataInVcd adds the provider default metadata to the given resource, when the entries in VCD are
+// missing or differ from the defaults
+func mergeDefaultMetadataInVcd(d *schema.ResourceData, vcdClient *VCDClient, resource metadataCompatible) error {
+	defaults := getDefaultMetadata(d, vcdClient)
+	if len(defaults) == 0 {
 		return nil
 	}
-	err = resource.MergeMetadataWithMetadataValues(metadataToMerge)
+	metadata, err := resource.GetMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata to add the default entries: %s", err)
+	}
+	for _, entry := range metadata.MetadataEntry {
+		if value, isDefault := defaults[entry.Key]; isDefault && isSameMetadataValue(entry, value) {
+			delete(defaults, entry.Key)
+		}
+	}
+	if len(defaults) == 0 {
+		return nil
+	}
+	err = resource.MergeMetadataWithMetadataValues(defaults)
 	if err != nil && !strings.Contains(err.Error(), "after filtering metadata, there is no metadata to merge") {
-		return fmt.Errorf("error adding metadata entries: %s", err)
+		return fmt.Errorf("error adding default metadata entries: %s", err)
 	}
 	return nil
 }
 
+// removeDefaultMetadata removes from the metadata retrieved from VCD the entries that come from the provider default
+// metadata, so that they don't appear as changes in the resource. configuredKeys contains the keys set in the
+// resource, which are never removed. An entry with a default key but a different value is kept, so that the
+// default value is restored on the next update.
+func removeDefaultMetadata(metadata []*types.MetadataEntry, vcdClient *VCDClient, configuredKeys map[string]bool) []*types.MetadataEntry {
+	if len(vcdClient.defaultMetadata) == 0 {
+		return metadata
+	}
+	var result []*types.MetadataEntry
+	for _, entry := range metadata {
+		value, isDefault := vcdClient.defaultMetadata[entry.Key]
+		if isDefault && !configuredKeys[entry.Key] && isSameMetadataValue(entry, value) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// isSameMetadataValue returns true if the entry retrieved from VCD has the same value, type, domain and visibility
+// as the given value
+func isSameMetadataValue(entry *types.MetadataEntry, value types.MetadataValue) bool {
+	if entry.TypedValue == nil || value.TypedValue == nil {
+		return false
+	}
+	if entry.TypedValue.Value != value.TypedValue.Value || entry.TypedValue.XsiType != value.TypedValue.XsiType {
+		return false
+	}
+	isSystem := entry.Domain != nil && entry.Domain.Domain == "SYSTEM"
+	visibility := types.MetadataReadWriteVisibility
+	if entry.Domain != nil && entry.Domain.Visibility != "" {
+		visibility = entry.Domain.Visibility
+	}
+	return isSystem == isSystemMetadataValue(value) && value.Domain != nil && visibility == value.Domain.Visibility
+}
+
+// isSystemMetadataValue returns true if the given value belongs to the SYSTEM domain
+func isSystemMetadataValue(value types.MetadataValue) bool {
+	return value.Domain != nil && value.Domain.Domain == "SYSTEM"
+}
+
 // checkIgnoredMetadataConflicts checks that no `metadata_entry` managed by Terraform is ignored due to being filtered out
 // in any `ignore_metadata_changes` block and errors/warns if so, depending on the value of `conflict_action`.
 func checkIgnoredMetadataConflicts(d *schema.ResourceData, vcdClient *VCDClient, resourceType string) diag.Diagnostics {
@@ -373,8 +479,9 @@ func checkIgnoredMetadataConflicts(d *schema.ResourceData, vcdClient *VCDClient,
 
 // updateMetadataInStateDeprecated updates deprecated metadata and the new metadata_entry in the Terraform state for the given receiver object.
 // This can be done as both are Computed, for compatibility reasons.
+// The entries that come from the provider default metadata are only set when origin is "datasource".
 // TODO: Remove this function once "metadata" attribute is deleted in a future major release.
-func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject metadataCompatible) diag.Diagnostics {
+func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject metadataCompatible, origin string) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	// We temporarily remove the ignored metadata filter to retrieve the deprecated metadata contents,
@@ -401,6 +508,15 @@ func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClien
 		_ = filterAndGetVcdInheritedMetadata(deprecatedMetadata)
 	}
 
+	deprecatedMetadata.MetadataEntry, _ = removeDeletionProtectionMarker(removeDistributedLockEntry(deprecatedMetadata.MetadataEntry))
+	if origin != "datasource" {
+		configuredKeys := map[string]bool{}
+		for key := range d.Get("metadata").(map[string]interface{}) {
+			configuredKeys[key] = true
+		}
+		deprecatedMetadata.MetadataEntry = removeDefaultMetadata(deprecatedMetadata.MetadataEntry, vcdClient, configuredKeys)
+	}
+
 	// Set deprecated metadata attribute, just for compatibility reasons
 	err = d.Set("metadata", getMetadataStruct(deprecatedMetadata.MetadataEntry))
 	if err != nil {
@@ -408,7 +524,7 @@ func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClien
 	}
 
 	// We get metadata again with the original metadata ignore filtering
-	diags = append(diags, updateMetadataInState(d, vcdClient, resourceType, receiverObject)...)
+	diags = append(diags, updateMetadataInState(d, vcdClient, resourceType, receiverObject, origin)...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -422,7 +538,9 @@ func updateMetadataInStateDeprecated(d *schema.ResourceData, vcdClient *VCDClien
 }
 
 // updateMetadataInState updates ONLY metadata_entry in the Terraform state for the given receiver object.
-func updateMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject metadataCompatible) diag.Diagnostics {
+// The entries that come from the provider default metadata are only set when origin is "datasource": resources don't
+// set them, so that they don't appear as changes.
+func updateMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject metadataCompatible, origin string) diag.Diagnostics {
 	diags := checkIgnoredMetadataConflicts(d, vcdClient, resourceType)
 	if diags != nil && diags.HasError() {
 		return diags
@@ -443,7 +561,16 @@ func updateMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourc
 		}
 	}
 
-	err = setMetadataEntryInState(d, metadata.MetadataEntry)
+	// The deletion protection marker is managed through the `deletion_protection` argument, and the lease through
+	// the distributed locks
+	metadataEntries, isProtected := removeDeletionProtectionMarker(removeDistributedLockEntry(metadata.MetadataEntry))
+	if origin != "datasource" {
+		metadataEntries = removeDefaultMetadata(metadataEntries, vcdClient, getConfiguredMetadataEntryKeys(d))
+		if deletionProtectionResourceTypes[resourceType] {
+			dSet(d, "deletion_protection", isProtected)
+		}
+	}
+	err = setMetadataEntryInState(d, metadataEntries)
 	if err != nil {
 		return append(diags, diag.Errorf("error setting metadata entry in state: %s", err)...)
 	}
@@ -585,6 +712,38 @@ func getMetadataKeyWithDomainMap(metadataAttribute []interface{}) map[string]boo
 	return metadataKeys
 }
 
+// metadataValuesToString returns a representation of the given metadata values that doesn't depend on the order of
+// the keys
+func metadataValuesToString(metadata map[string]types.MetadataValue) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result strings.Builder
+	for _, key := range keys {
+		value := metadata[key]
+		result.WriteString(key)
+		if value.Domain != nil {
+			result.WriteString(fmt.Sprintf("|%s|%s", value.Domain.Domain, value.Domain.Visibility))
+		}
+		if value.TypedValue != nil {
+			result.WriteString(fmt.Sprintf("|%s|%s", value.TypedValue.XsiType, value.TypedValue.Value))
+		}
+		result.WriteString(";")
+	}
+	return result.String()
+}
+
+// getConfiguredMetadataEntryKeys returns the keys of the metadata_entry set in the given resource
+func getConfiguredMetadataEntryKeys(d *schema.ResourceData) map[string]bool {
+	configuredKeys := map[string]bool{}
+	for key := range getMetadataKeyWithDomainMap(d.Get("metadata_entry").(*schema.Set).List()) {
+		configuredKeys[key] = true
+	}
+	return configuredKeys
+}
+
 // getMetadataEmptySubAttributes returns the number of empty attributes inside one metadata_entry.
 // Returned value can be at most len(metadataEntry).
 func getMetadataEmptySubAttributes(metadataEntry map[string]interface{}) int {
@@ -8,8 +8,10 @@ import (
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/types/v56"
 	"github.com/vmware/go-vcloud-director/v3/util"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 // openApiMetadataEntryDatasourceSchema returns the schema associated to the OpenAPI metadata_entry for a given data source.
@@ -134,9 +136,193 @@ type openApiMetadataCompatible interface {
 	AddMetadata(metadataEntry types.OpenApiMetadataEntry) (*govcd.OpenApiMetadataEntry, error)
 }
 
+// openApiMetadataHandler reads and modifies the OpenAPI metadata entries of a VCD object. It is implemented by
+// sdkOpenApiMetadata, for the objects that have metadata methods in the SDK, and by openApiEntityMetadata for the rest.
+type openApiMetadataHandler interface {
+	// getAllMetadata returns the entries of the object, except the ones ignored with 'ignore_metadata_changes'
+	getAllMetadata() ([]*types.OpenApiMetadataEntry, error)
+	addMetadata(entry types.OpenApiMetadataEntry) error
+	// updateMetadata sets the value and persistence of the entry with the same domain, namespace and key
+	updateMetadata(entry types.OpenApiMetadataEntry) error
+	// deleteMetadata removes the entry with the same domain, namespace and key
+	deleteMetadata(entry types.OpenApiMetadataEntry) error
+}
+
+// sdkOpenApiMetadata is the openApiMetadataHandler of the objects that implement openApiMetadataCompatible
+type sdkOpenApiMetadata struct {
+	object openApiMetadataCompatible
+}
+
+func (handler sdkOpenApiMetadata) getAllMetadata() ([]*types.OpenApiMetadataEntry, error) {
+	allMetadata, err := handler.object.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.OpenApiMetadataEntry, len(allMetadata))
+	for i, entry := range allMetadata {
+		result[i] = entry.MetadataEntry
+	}
+	return result, nil
+}
+
+func (handler sdkOpenApiMetadata) addMetadata(entry types.OpenApiMetadataEntry) error {
+	_, err := handler.object.AddMetadata(entry)
+	return err
+}
+
+func (handler sdkOpenApiMetadata) updateMetadata(entry types.OpenApiMetadataEntry) error {
+	toUpdate, err := handler.object.GetMetadataByKey(entry.KeyValue.Domain, entry.KeyValue.Namespace, entry.KeyValue.Key) // Refreshes ETags
+	if err != nil {
+		return err
+	}
+	return toUpdate.Update(entry.KeyValue.Value.Value, entry.IsPersistent)
+}
+
+func (handler sdkOpenApiMetadata) deleteMetadata(entry types.OpenApiMetadataEntry) error {
+	toDelete, err := handler.object.GetMetadataByKey(entry.KeyValue.Domain, entry.KeyValue.Namespace, entry.KeyValue.Key) // Refreshes ETags
+	if err != nil {
+		return err
+	}
+	return toDelete.Delete()
+}
+
+// openApiEntityMetadata is the openApiMetadataHandler of the VCD entities identified by a URN, like NSX-T Edge Gateways
+// or VDC Groups, which metadata is available in the generic "entities/{id}/metadata" endpoint since VCD 10.5.
+type openApiEntityMetadata struct {
+	client     *govcd.Client
+	id         string
+	name       string
+	objectType string // Object type matched by 'ignore_metadata_changes', from resourceMetadataApiRelation
+}
+
+// newOpenApiEntityMetadata returns the metadata handler of the entity with the given ID and name, managed by
+// the given resource type
+func newOpenApiEntityMetadata(vcdClient *VCDClient, resourceType, id, name string) openApiEntityMetadata {
+	return openApiEntityMetadata{
+		client:     &vcdClient.Client,
+		id:         id,
+		name:       name,
+		objectType: resourceMetadataApiRelation[resourceType],
+	}
+}
+
+// isSupported returns whether VCD has OpenAPI metadata for entities that are not Runtime Defined Entities
+func (handler openApiEntityMetadata) isSupported() bool {
+	return handler.client.APIVCDMaxVersionIs(">= 38.0")
+}
+
+func (handler openApiEntityMetadata) endpoint(suffix string) (*url.URL, error) {
+	return handler.client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointRdeEntities,
+		handler.id, "/metadata", suffix)
+}
+
+func (handler openApiEntityMetadata) getAllMetadata() ([]*types.OpenApiMetadataEntry, error) {
+	if !handler.isSupported() {
+		return nil, nil
+	}
+	urlRef, err := handler.endpoint("")
+	if err != nil {
+		return nil, err
+	}
+	var allMetadata []*types.OpenApiMetadataEntry
+	err = handler.client.OpenApiGetAllItems(handler.client.APIVersion, urlRef, nil, &allMetadata, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving metadata of %s: %s", handler.id, err)
+	}
+	var result []*types.OpenApiMetadataEntry
+	for _, entry := range allMetadata {
+		if isIgnoredOpenApiMetadataEntry(handler.client.IgnoredMetadata, handler.objectType, handler.name, entry) {
+			util.Logger.Printf("[DEBUG] the metadata entry with key '%s' and value '%v' of %s is being ignored", entry.KeyValue.Key, entry.KeyValue.Value.Value, handler.id)
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// getMetadataEntry returns the entry with the same domain, namespace and key as the given one, with its ETag
+func (handler openApiEntityMetadata) getMetadataEntry(entry types.OpenApiMetadataEntry) (*types.OpenApiMetadataEntry, string, error) {
+	allMetadata, err := handler.getAllMetadata()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, existing := range allMetadata {
+		if existing.KeyValue.Domain == entry.KeyValue.Domain && existing.KeyValue.Namespace == entry.KeyValue.Namespace &&
+			existing.KeyValue.Key == entry.KeyValue.Key {
+			urlRef, err := handler.endpoint("/" + existing.ID)
+			if err != nil {
+				return nil, "", err
+			}
+			result := &types.OpenApiMetadataEntry{}
+			headers, err := handler.client.OpenApiGetItemAndHeaders(handler.client.APIVersion, urlRef, nil, result, nil)
+			if err != nil {
+				return nil, "", err
+			}
+			return result, headers.Get("Etag"), nil
+		}
+	}
+	return nil, "", fmt.Errorf("%s: no metadata entry with key '%s' in %s", govcd.ErrorEntityNotFound, entry.KeyValue.Key, handler.id)
+}
+
+func (handler openApiEntityMetadata) addMetadata(entry types.OpenApiMetadataEntry) error {
+	if !handler.isSupported() {
+		return fmt.Errorf("the metadata of %s requires VCD 10.5+", handler.id)
+	}
+	urlRef, err := handler.endpoint("")
+	if err != nil {
+		return err
+	}
+	return handler.client.OpenApiPostItem(handler.client.APIVersion, urlRef, nil, entry, &types.OpenApiMetadataEntry{}, nil)
+}
+
+func (handler openApiEntityMetadata) updateMetadata(entry types.OpenApiMetadataEntry) error {
+	existing, etag, err := handler.getMetadataEntry(entry)
+	if err != nil {
+		return err
+	}
+	urlRef, err := handler.endpoint("/" + existing.ID)
+	if err != nil {
+		return err
+	}
+	existing.IsPersistent = entry.IsPersistent
+	existing.KeyValue.Value.Value = entry.KeyValue.Value.Value
+	return handler.client.OpenApiPutItem(handler.client.APIVersion, urlRef, nil, existing, &types.OpenApiMetadataEntry{}, map[string]string{"If-Match": etag})
+}
+
+func (handler openApiEntityMetadata) deleteMetadata(entry types.OpenApiMetadataEntry) error {
+	existing, _, err := handler.getMetadataEntry(entry)
+	if err != nil {
+		return err
+	}
+	urlRef, err := handler.endpoint("/" + existing.ID)
+	if err != nil {
+		return err
+	}
+	return handler.client.OpenApiDeleteItem(handler.client.APIVersion, urlRef, nil, nil)
+}
+
+// isIgnoredOpenApiMetadataEntry returns whether the given entry of the object with the given type and name matches any
+// of the 'ignore_metadata_changes' filters. All the criteria of a filter must match for the entry to be ignored,
+// the same way as the SDK does with the objects it handles.
+func isIgnoredOpenApiMetadataEntry(ignoredMetadata []govcd.IgnoredMetadata, objectType, objectName string, entry *types.OpenApiMetadataEntry) bool {
+	value := fmt.Sprintf("%v", entry.KeyValue.Value.Value)
+	for _, ignored := range ignoredMetadata {
+		if ignored.ObjectType == nil && ignored.ObjectName == nil && ignored.KeyRegex == nil && ignored.ValueRegex == nil {
+			continue
+		}
+		if (ignored.ObjectType == nil || strings.TrimSpace(*ignored.ObjectType) == "" || *ignored.ObjectType == objectType) &&
+			(ignored.ObjectName == nil || strings.TrimSpace(*ignored.ObjectName) == "" || strings.TrimSpace(objectName) == "" || *ignored.ObjectName == objectName) &&
+			(ignored.KeyRegex == nil || ignored.KeyRegex.MatchString(entry.KeyValue.Key)) &&
+			(ignored.ValueRegex == nil || ignored.ValueRegex.MatchString(value)) {
+			return true
+		}
+	}
+	return false
+}
+
 // createOrUpdateOpenApiMetadataEntryInVcd creates or updates OpenAPI metadata entries in VCD for the given resource, only if the attribute
 // metadata_entry has been set or updated in the state.
-func createOrUpdateOpenApiMetadataEntryInVcd(d *schema.ResourceData, resource openApiMetadataCompatible) error {
+func createOrUpdateOpenApiMetadataEntryInVcd(d *schema.ResourceData, resource openApiMetadataHandler) error {
 	if !d.HasChange("metadata_entry") {
 		return nil
 	}
@@ -148,29 +334,21 @@ func createOrUpdateOpenApiMetadataEntryInVcd(d *schema.ResourceData, resource op
 	}
 
 	for _, entry := range metadataToDelete {
-		toDelete, err := resource.GetMetadataByKey(entry.KeyValue.Domain, entry.KeyValue.Namespace, entry.KeyValue.Key) // Refreshes ETags
-		if err != nil {
-			return fmt.Errorf("error reading metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
-		}
-		err = toDelete.Delete()
+		err = resource.deleteMetadata(entry)
 		if err != nil {
 			return fmt.Errorf("error deleting metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
 		}
 	}
 
 	for _, entry := range metadataToUpdate {
-		toUpdate, err := resource.GetMetadataByKey(entry.KeyValue.Domain, entry.KeyValue.Namespace, entry.KeyValue.Key) // Refreshes ETags
-		if err != nil {
-			return fmt.Errorf("error reading metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
-		}
-		err = toUpdate.Update(entry.KeyValue.Value.Value, entry.IsPersistent)
+		err = resource.updateMetadata(entry)
 		if err != nil {
 			return fmt.Errorf("error updating metadata with namespace '%s' and key '%s': %s", entry.KeyValue.Namespace, entry.KeyValue.Key, err)
 		}
 	}
 
 	for _, metadataEntry := range metadataToAdd {
-		_, err := resource.AddMetadata(metadataEntry)
+		err = resource.addMetadata(metadataEntry)
 		if err != nil {
 			return fmt.Errorf("error adding metadata entry: %s", err)
 		}
@@ -189,6 +367,18 @@ func getOpenApiMetadataOperations(oldMetadata []interface{}, newMetadata []inter
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	// The lease of a distributed lock is only written by the lock itself
+	for namespacedKey, entry := range oldMetadataEntries {
+		if entry.KeyValue.Key == distributedLockKey && entry.KeyValue.Namespace == "" {
+			delete(oldMetadataEntries, namespacedKey)
+			delete(newMetadataEntries, namespacedKey)
+		}
+	}
+	for namespacedKey, entry := range newMetadataEntries {
+		if entry.KeyValue.Key == distributedLockKey && entry.KeyValue.Namespace == "" {
+			delete(newMetadataEntries, namespacedKey)
+		}
+	}
 
 	var metadataToRemove []types.OpenApiMetadataEntry
 	for oldNamespacedKey := range oldMetadataEntries {
@@ -276,41 +466,44 @@ func getOpenApiMetadataEntryMap(metadataAttribute []interface{}) (map[string]typ
 
 // updateOpenApiMetadataInState updates metadata_entry in the Terraform state for the given receiver object.
 // This can be done as both are Computed, for compatibility reasons.
-func updateOpenApiMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject openApiMetadataCompatible) diag.Diagnostics {
+func updateOpenApiMetadataInState(d *schema.ResourceData, vcdClient *VCDClient, resourceType string, receiverObject openApiMetadataHandler) diag.Diagnostics {
 	diags := checkIgnoredMetadataConflicts(d, vcdClient, resourceType)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
 
-	allMetadata, err := receiverObject.GetMetadata()
+	allMetadata, err := receiverObject.getAllMetadata()
 	if err != nil {
 		return append(diags, diag.FromErr(err)...)
 	}
+	// The deletion protection marker is managed through the `deletion_protection` argument, and the lease through
+	// the distributed locks
+	allMetadata, _ = removeOpenApiDeletionProtectionMarker(removeOpenApiDistributedLockEntry(allMetadata))
 
 	metadata := make([]interface{}, len(allMetadata))
 	for i, metadataEntryFromVcd := range allMetadata {
 		// We need to set the correct type, otherwise saving the state will fail
 		value := ""
-		switch metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type {
+		switch metadataEntryFromVcd.KeyValue.Value.Type {
 		case types.OpenApiMetadataBooleanEntry:
-			value = fmt.Sprintf("%t", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(bool))
+			value = fmt.Sprintf("%t", metadataEntryFromVcd.KeyValue.Value.Value.(bool))
 		case types.OpenApiMetadataNumberEntry:
-			value = fmt.Sprintf("%.0f", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(float64))
+			value = fmt.Sprintf("%.0f", metadataEntryFromVcd.KeyValue.Value.Value.(float64))
 		case types.OpenApiMetadataStringEntry:
-			value = metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Value.(string)
+			value = metadataEntryFromVcd.KeyValue.Value.Value.(string)
 		default:
-			return append(diags, diag.Errorf("not supported metadata type %s", metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type)...)
+			return append(diags, diag.Errorf("not supported metadata type %s", metadataEntryFromVcd.KeyValue.Value.Type)...)
 		}
 
 		metadataEntry := map[string]interface{}{
-			"id":         metadataEntryFromVcd.MetadataEntry.ID,
-			"key":        metadataEntryFromVcd.MetadataEntry.KeyValue.Key,
-			"readonly":   metadataEntryFromVcd.MetadataEntry.IsReadOnly,
-			"domain":     metadataEntryFromVcd.MetadataEntry.KeyValue.Domain,
-			"namespace":  metadataEntryFromVcd.MetadataEntry.KeyValue.Namespace,
-			"type":       metadataEntryFromVcd.MetadataEntry.KeyValue.Value.Type,
+			"id":         metadataEntryFromVcd.ID,
+			"key":        metadataEntryFromVcd.KeyValue.Key,
+			"readonly":   metadataEntryFromVcd.IsReadOnly,
+			"domain":     metadataEntryFromVcd.KeyValue.Domain,
+			"namespace":  metadataEntryFromVcd.KeyValue.Namespace,
+			"type":       metadataEntryFromVcd.KeyValue.Value.Type,
 			"value":      value,
-			"persistent": metadataEntryFromVcd.MetadataEntry.IsPersistent,
+			"persistent": metadataEntryFromVcd.IsPersistent,
 		}
 		metadata[i] = metadataEntry
 	}
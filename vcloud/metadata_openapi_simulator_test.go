@@ -0,0 +1,96 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorOpenApiMetadata checks the creation and update of OpenAPI metadata entries, and that the ignored ones are not read
+func TestSimulatorOpenApiMetadata(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t, func(config *Config) {
+		config.IgnoredMetadata = []govcd.IgnoredMetadata{{
+			ObjectType: addrOf(resourceMetadataApiRelation["vcd_nsxt_edgegateway"]),
+			KeyRegex:   regexp.MustCompile(`^ignored\.`),
+		}}
+	})
+	ctx := context.Background()
+	resource := resourceVcdNsxtEdgeGateway()
+	dataSource := datasourceVcdNsxtEdgeGateway()
+
+	edge, err := vcdClient.GetNsxtEdgeGateway(simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", edge.EdgeGateway.ID, edge.EdgeGateway.Name)
+
+	entry := func(key, value, valueType string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": value, "type": valueType, "readonly": false,
+			"domain": "TENANT", "namespace": "", "persistent": false}
+	}
+	values := map[string]interface{}{
+		"org":  simulatorOrg,
+		"name": simulatorEdgeGateway,
+		"metadata_entry": []interface{}{
+			entry("owner", "team-a", types.OpenApiMetadataStringEntry),
+			entry("tier", "1", types.OpenApiMetadataNumberEntry),
+		},
+	}
+	d := simulatorResourceData(t, resource, values)
+	d.SetId(edge.EdgeGateway.ID)
+	if err := createOrUpdateOpenApiMetadataEntryInVcd(d, handler); err != nil {
+		t.Fatalf("error creating metadata: %s", err)
+	}
+	// An entry added outside Terraform that matches 'ignore_metadata_changes'
+	err = handler.addMetadata(types.OpenApiMetadataEntry{KeyValue: types.OpenApiMetadataKeyValue{
+		Domain: "TENANT", Key: "ignored.by-terraform",
+		Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataStringEntry, Value: "x"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading edge gateway: %v", diags)
+	}
+	entries := d.Get("metadata_entry").(*schema.Set).List()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 metadata entries, got %v", entries)
+	}
+	for _, rawEntry := range entries {
+		if rawEntry.(map[string]interface{})["id"] == "" {
+			t.Fatalf("expected the metadata entries to have an ID, got %v", entries)
+		}
+	}
+
+	// Changing a value, removing an entry and adding a new one
+	values["metadata_entry"] = []interface{}{
+		entry("owner", "team-b", types.OpenApiMetadataStringEntry),
+		entry("managed", "true", types.OpenApiMetadataBooleanEntry),
+	}
+	d = simulatorUpdateData(t, resource, d, values)
+	if err := createOrUpdateOpenApiMetadataEntryInVcd(d, handler); err != nil {
+		t.Fatalf("error updating metadata: %s", err)
+	}
+
+	ds := simulatorResourceData(t, dataSource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorNsxtVdc,
+		"name": simulatorEdgeGateway,
+	})
+	if diags := dataSource.ReadContext(ctx, ds, vcdClient); diags.HasError() {
+		t.Fatalf("error reading edge gateway data source: %v", diags)
+	}
+	inVcd := map[string]string{}
+	for _, rawEntry := range ds.Get("metadata_entry").(*schema.Set).List() {
+		inVcd[rawEntry.(map[string]interface{})["key"].(string)] = rawEntry.(map[string]interface{})["value"].(string)
+	}
+	if len(inVcd) != 2 || inVcd["owner"] != "team-b" || inVcd["managed"] != "true" {
+		t.Fatalf("unexpected metadata in the data source: %v", inVcd)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func Test_isIgnoredOpenApiMetadataEntry(t *testing.T) {
+	entry := &types.OpenApiMetadataEntry{KeyValue: types.OpenApiMetadataKeyValue{
+		Key:   "owner",
+		Value: types.OpenApiMetadataTypedValue{Type: types.OpenApiMetadataNumberEntry, Value: float64(42)},
+	}}
+	tests := []struct {
+		name    string
+		ignored []govcd.IgnoredMetadata
+		want    bool
+	}{
+		{name: "no filters", ignored: nil, want: false},
+		{name: "empty filter", ignored: []govcd.IgnoredMetadata{{}}, want: false},
+		{name: "key", ignored: []govcd.IgnoredMetadata{{KeyRegex: regexp.MustCompile("^own")}}, want: true},
+		{name: "other key", ignored: []govcd.IgnoredMetadata{{KeyRegex: regexp.MustCompile("^cost")}}, want: false},
+		{name: "typed value", ignored: []govcd.IgnoredMetadata{{ValueRegex: regexp.MustCompile("^42$")}}, want: true},
+		{name: "object type", ignored: []govcd.IgnoredMetadata{{ObjectType: addrOf("gateway"), KeyRegex: regexp.MustCompile(".*")}}, want: true},
+		{name: "other object type", ignored: []govcd.IgnoredMetadata{{ObjectType: addrOf("vdcGroup"), KeyRegex: regexp.MustCompile(".*")}}, want: false},
+		{name: "object name", ignored: []govcd.IgnoredMetadata{{ObjectName: addrOf("edge1"), KeyRegex: regexp.MustCompile(".*")}}, want: true},
+		{name: "other object name", ignored: []govcd.IgnoredMetadata{{ObjectName: addrOf("edge2"), KeyRegex: regexp.MustCompile(".*")}}, want: false},
+		{name: "any filter", ignored: []govcd.IgnoredMetadata{{ObjectName: addrOf("edge2")}, {KeyRegex: regexp.MustCompile("owner")}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isIgnoredOpenApiMetadataEntry(tt.ignored, "gateway", "edge1", entry)
+			if got != tt.want {
+				t.Errorf("isIgnoredOpenApiMetadataEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorDefaultMetadata checks that the default metadata of the provider is merged with the entries of a resource, without being stored in its state
+func TestSimulatorDefaultMetadata(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t, func(config *Config) {
+		config.DefaultMetadata = map[string]types.MetadataValue{
+			"cost-center": {
+				Domain:     &types.MetadataDomainTag{Visibility: types.MetadataReadWriteVisibility, Domain: "GENERAL"},
+				TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: "1234"},
+			},
+			"owner": {
+				Domain:     &types.MetadataDomainTag{Visibility: types.MetadataReadWriteVisibility, Domain: "GENERAL"},
+				TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: "team-a"},
+			},
+		}
+	})
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+
+	values := map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "tagged-vapp",
+		"metadata_entry": []interface{}{
+			map[string]interface{}{"key": "owner", "value": "team-b", "type": types.MetadataStringValue,
+				"user_access": types.MetadataReadWriteVisibility, "is_system": false},
+		},
+	}
+	d := simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+
+	vapp := simulatorVapp(t, vcdClient, "tagged-vapp")
+	metadata, err := vapp.GetMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inVcd := map[string]string{}
+	for _, entry := range metadata.MetadataEntry {
+		inVcd[entry.Key] = entry.TypedValue.Value
+	}
+	if inVcd["cost-center"] != "1234" || inVcd["owner"] != "team-b" {
+		t.Fatalf("expected the default entries to be merged with the resource ones, got %v", inVcd)
+	}
+
+	// The default entries are not in the state, so they are not seen as changes
+	entries := d.Get("metadata_entry").(*schema.Set).List()
+	if len(entries) != 1 || entries[0].(map[string]interface{})["key"] != "owner" {
+		t.Fatalf("expected only the resource entries in the state, got %v", entries)
+	}
+	if _, found := d.Get("metadata").(map[string]interface{})["cost-center"]; found {
+		t.Fatal("expected the default entries not to be in the deprecated metadata")
+	}
+
+	// Removing the entry that overrides a default brings back the default value
+	values["metadata_entry"] = []interface{}{}
+	d = simulatorUpdateData(t, resource, d, values)
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error updating vApp: %v", diags)
+	}
+	value, err := vapp.GetMetadataByKey("owner", false)
+	if err != nil || value.TypedValue.Value != "team-a" {
+		t.Fatalf("expected the default value of 'owner', got %v (error %v)", value, err)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func testMetadataValue(value string, isSystem bool) types.MetadataValue {
+	domain := "GENERAL"
+	if isSystem {
+		domain = "SYSTEM"
+	}
+	return types.MetadataValue{
+		Domain:     &types.MetadataDomainTag{Visibility: types.MetadataReadWriteVisibility, Domain: domain},
+		TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: value},
+	}
+}
+
+func testMetadataEntry(key string, value types.MetadataValue) *types.MetadataEntry {
+	return &types.MetadataEntry{Key: key, Domain: value.Domain, TypedValue: value.TypedValue}
+}
+
+// Test_getDefaultMetadata checks that the entries set in the resource take precedence over the provider defaults
+func Test_getDefaultMetadata(t *testing.T) {
+	vcdClient := &VCDClient{defaultMetadata: map[string]types.MetadataValue{
+		"cost-center": testMetadataValue("1234", false),
+		"owner":       testMetadataValue("team-a", false),
+	}}
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"metadata_entry": metadataEntryResourceSchema("vApp")},
+		map[string]interface{}{
+			"metadata_entry": []interface{}{
+				map[string]interface{}{"key": "owner", "value": "team-b"},
+			},
+		})
+
+	defaults := getDefaultMetadata(d, vcdClient)
+	if len(defaults) != 1 || defaults["cost-center"].TypedValue.Value != "1234" {
+		t.Fatalf("expected only the 'cost-center' default entry, got %v", defaults)
+	}
+	if defaults := getDefaultMetadata(d, &VCDClient{}); defaults != nil {
+		t.Fatalf("expected no default entries, got %v", defaults)
+	}
+}
+
+// Test_removeDefaultMetadata checks which entries retrieved from VCD are hidden from the resources
+func Test_removeDefaultMetadata(t *testing.T) {
+	vcdClient := &VCDClient{defaultMetadata: map[string]types.MetadataValue{
+		"cost-center": testMetadataValue("1234", false),
+		"owner":       testMetadataValue("team-a", false),
+		"tier":        testMetadataValue("gold", false),
+		"zone":        testMetadataValue("eu", true),
+	}}
+	metadata := []*types.MetadataEntry{
+		testMetadataEntry("cost-center", testMetadataValue("1234", false)), // default: removed
+		testMetadataEntry("owner", testMetadataValue("team-a", false)),     // set in the resource: kept
+		testMetadataEntry("tier", testMetadataValue("silver", false)),      // changed outside Terraform: kept
+		testMetadataEntry("zone", testMetadataValue("eu", false)),          // different domain: kept
+		testMetadataEntry("other", testMetadataValue("value", false)),      // not a default: kept
+	}
+
+	result := removeDefaultMetadata(metadata, vcdClient, map[string]bool{"owner": true})
+	var keys []string
+	for _, entry := range result {
+		keys = append(keys, entry.Key)
+	}
+	expected := []string{"owner", "tier", "zone", "other"}
+	if len(keys) != len(expected) {
+		t.Fatalf("expected keys %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected keys %v, got %v", expected, keys)
+		}
+	}
+
+	if result := removeDefaultMetadata(metadata, &VCDClient{}, nil); len(result) != len(metadata) {
+		t.Fatalf("expected all the entries without default metadata, got %d", len(result))
+	}
+}
+
+// Test_metadataValuesToString checks that the representation of metadata values doesn't depend on the map order
+func Test_metadataValuesToString(t *testing.T) {
+	metadata := map[string]types.MetadataValue{
+		"b": testMetadataValue("2", false),
+		"a": testMetadataValue("1", true),
+	}
+	expected := "a|SYSTEM|READWRITE|MetadataStringValue|1;b|GENERAL|READWRITE|MetadataStringValue|2;"
+	for i := 0; i < 10; i++ {
+		if got := metadataValuesToString(metadata); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
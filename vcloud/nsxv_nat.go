@@ -22,7 +22,9 @@ type natRuleDataSetter func(d *schema.ResourceData, natRule *types.EdgeNatRule,
 func natRuleCreate(natType string, setData natRuleDataSetter, getNatRule natRuleTypeGetter) schema.CreateFunc {
 	return func(d *schema.ResourceData, meta interface{}) error {
 		vcdClient := meta.(*VCDClient)
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return err
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 
 		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -51,7 +53,9 @@ func natRuleCreate(natType string, setData natRuleDataSetter, getNatRule natRule
 func natRuleUpdate(natType string, setData natRuleDataSetter, getNatRule natRuleTypeGetter) schema.UpdateFunc {
 	return func(d *schema.ResourceData, meta interface{}) error {
 		vcdClient := meta.(*VCDClient)
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return err
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 
 		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -123,7 +127,9 @@ func natRuleRead(idField, natType string, setData natRuleDataSetter) schema.Read
 func natRuleDelete(natType string) schema.DeleteFunc {
 	return func(d *schema.ResourceData, meta interface{}) error {
 		vcdClient := meta.(*VCDClient)
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return err
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 
 		edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -175,6 +175,21 @@ var globalDataSourceMap = map[string]*schema.Resource{
 	"vcloud_nsxt_alb_virtual_service_http_req_rules":      	datasourceVcdAlbVirtualServiceReqRules(),               // 3.14
 	"vcloud_nsxt_alb_virtual_service_http_resp_rules":     	datasourceVcdAlbVirtualServiceRespRules(),              // 3.14
 	"vcloud_nsxt_alb_virtual_service_http_sec_rules":      	datasourceVcdAlbVirtualServiceSecRules(),               // 3.14
+	"vcloud_vm_snapshot":                                  datasourceVcdVmSnapshot(),                              // 3.14
+	"vcloud_vms":                                         datasourceVcdVms(),                                     // 3.14
+	"vcloud_vapps":                                       datasourceVcdVapps(),                                   // 3.14
+	"vcloud_catalog_items":                               datasourceVcdCatalogItems(),                            // 3.14
+	"vcloud_catalog_vapp_templates":                      datasourceVcdCatalogVappTemplates(),                    // 3.14
+	"vcloud_catalog_media_items":                         datasourceVcdCatalogMediaItems(),                       // 3.14
+	"vcloud_networks":                                    datasourceVcdNetworks(),                                // 3.14
+	"vcloud_edgegateways":                                datasourceVcdEdgeGateways(),                            // 3.14
+	"vcloud_independent_disks":                           datasourceVcdIndependentDisks(),                        // 3.14
+	"vcloud_org_users":                                   datasourceVcdOrgUsers(),                                // 3.14
+	"vcloud_audit_events":                                datasourceVcdAuditEvents(),                             // 3.14
+	"vcloud_vm_metrics":                                  datasourceVcdVmMetrics(),                               // 3.14
+	"vcloud_ip_space_usage":                              datasourceVcdIpSpaceUsage(),                            // 3.14
+	"vcloud_org_email_settings":                          datasourceVcdOrgEmailSettings(),                        // 3.14
+	"vcloud_org_password_policy":                         datasourceVcdOrgPasswordPolicy(),                       // 3.14
 }
 
 var globalResourceMap = map[string]*schema.Resource{
@@ -194,6 +209,7 @@ var globalResourceMap = map[string]*schema.Resource{
 	"vcloud_catalog_item":                                 resourceVcdCatalogItem(),                             // 2.0
 	"vcloud_catalog_media":                                resourceVcdCatalogMedia(),                            // 2.0
 	"vcloud_inserted_media":                               resourceVcdInsertedMedia(),                           // 2.1
+	"vcloud_vm_cloud_init":                                resourceVcdVmCloudInit(),                             // 3.14
 	"vcloud_independent_disk":                             resourceVcdIndependentDisk(),                         // 2.1
 	"vcloud_external_network":                             resourceVcdExternalNetwork(),                         // 2.2
 	"vcloud_lb_service_monitor":                           resourceVcdLbServiceMonitor(),                        // 2.4
@@ -228,6 +244,7 @@ var globalResourceMap = map[string]*schema.Resource{
 	"vcloud_nsxt_ip_set":                                  resourceVcdNsxtIpSet(),                               // 3.3
 	"vcloud_nsxt_security_group":                          resourceVcdSecurityGroup(),                           // 3.3
 	"vcloud_nsxt_firewall":                                resourceVcdNsxtFirewall(),                            // 3.3
+	"vcloud_nsxt_firewall_rule":                           resourceVcdNsxtFirewallRule(),                        // 3.14
 	"vcloud_nsxt_app_port_profile":                        resourceVcdNsxtAppPortProfile(),                      // 3.3
 	"vcloud_nsxt_nat_rule":                                resourceVcdNsxtNatRule(),                             // 3.3
 	"vcloud_nsxt_ipsec_vpn_tunnel":                        resourceVcdNsxtIpSecVpnTunnel(),                      // 3.3
@@ -301,6 +318,10 @@ var globalResourceMap = map[string]*schema.Resource{
 	"vcloud_nsxt_alb_virtual_service_http_req_rules":      	resourceVcdAlbVirtualServiceReqRules(),               // 3.14
 	"vcloud_nsxt_alb_virtual_service_http_resp_rules":     	resourceVcdAlbVirtualServiceRespRules(),              // 3.14
 	"vcloud_nsxt_alb_virtual_service_http_sec_rules":      	resourceVcdAlbVirtualServiceSecRules(),               // 3.14
+	"vcloud_vm_snapshot":                                  resourceVcdVmSnapshot(),                              // 3.14
+	"vcloud_vapp_snapshot":                                resourceVcdVappSnapshot(),                            // 3.14
+	"vcloud_org_email_settings":                           resourceVcdOrgEmailSettings(),                        // 3.14
+	"vcloud_org_password_policy":                          resourceVcdOrgPasswordPolicy(),                       // 3.14
 }
 
 // Provider returns a terraform.ResourceProvider.
@@ -419,6 +440,54 @@ func Provider() *schema.Provider {
 				Description: "Max num seconds to wait for successful response when operating on resources within vCloud (defaults to 60)",
 			},
 
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCLOUD_MAX_CONCURRENT_REQUESTS", 0),
+				Description:  "Max number of API requests sent to VCLOUD at the same time (defaults to 0, no limit)",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"retry_on_status": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "HTTP statuses of VCLOUD responses that are retried with exponential backoff, within 'max_retry_timeout' (defaults to 429 and 503)",
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(400, 599),
+				},
+			},
+
+			"lookup_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VCLOUD_LOOKUP_CACHE", true),
+				Description: "If set, the Orgs, VDCs and edge gateways retrieved by resources are cached during a run, until the next API write (defaults to true)",
+			},
+
+			"distributed_locks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VCLOUD_DISTRIBUTED_LOCKS", false),
+				Description: "If set, the locks on vApps, edge gateways and VDC groups are also recorded as leases in their metadata, to coordinate with other Terraform runs (defaults to false)",
+			},
+
+			"distributed_lock_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCLOUD_DISTRIBUTED_LOCK_TTL", 300),
+				Description:  "Number of seconds after which a lease that is not renewed is considered stale and can be broken (defaults to 300)",
+				ValidateFunc: validation.IntAtLeast(30),
+			},
+
+			"distributed_lock_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VCLOUD_DISTRIBUTED_LOCK_TIMEOUT", 1800),
+				Description:  "Max num seconds to wait for a lease held by another Terraform run (defaults to 1800)",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
 			"allow_unverified_ssl": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -445,10 +514,17 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("VCLOUD_IMPORT_SEPARATOR", "."),
 				Description: "Defines the import separation string to be used with 'terraform import'",
 			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VCLOUD_DELETION_PROTECTION", false),
+				Description: "Default value of 'deletion_protection' for the resources that support it and don't set it (defaults to false)",
+			},
 			"ignore_metadata_changes": ignoreMetadataSchema(),
+			"default_metadata":        defaultMetadataSchema(),
 		},
-		ResourcesMap:         globalResourceMap,
-		DataSourcesMap:       globalDataSourceMap,
+		ResourcesMap:         withThrottleDiagnostics(globalResourceMap),
+		DataSourcesMap:       withThrottleDiagnostics(globalDataSourceMap),
 		ConfigureContextFunc: providerConfigure,
 	}
 }
@@ -482,6 +558,16 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		Href:                    d.Get("url").(string),
 		MaxRetryTimeout:         maxRetryTimeout,
 		InsecureFlag:            d.Get("allow_unverified_ssl").(bool),
+		MaxConcurrentRequests:   d.Get("max_concurrent_requests").(int),
+		RetryOnStatus:           defaultRetryOnStatus,
+		LookupCache:             d.Get("lookup_cache").(bool),
+		DistributedLocks:        d.Get("distributed_locks").(bool),
+		DistributedLockTtl:      d.Get("distributed_lock_ttl").(int),
+		DistributedLockTimeout:  d.Get("distributed_lock_timeout").(int),
+		DeletionProtection:      d.Get("deletion_protection").(bool),
+	}
+	if retryOnStatus := d.Get("retry_on_status").(*schema.Set); retryOnStatus.Len() > 0 {
+		config.RetryOnStatus = convertSchemaSetToSliceOfInts(retryOnStatus)
 	}
 
 	// auth_type dependent configuration
@@ -571,6 +657,11 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		IgnoreMetadataChangesConflictActions[im.IgnoredMetadata.String()] = ignoredMetadata[i].ConflictAction
 	}
 
+	config.DefaultMetadata, err = convertFromStateToMetadataValues(d.Get("default_metadata").(*schema.Set).List())
+	if err != nil {
+		return nil, diag.Errorf("could not process the default metadata: %s", err)
+	}
+
 	vcdClient, err := config.Client()
 	if err != nil {
 		return nil, diag.FromErr(err)
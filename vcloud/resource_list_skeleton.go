@@ -0,0 +1,170 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceSkeleton returns the HCL definition of a resource to be imported, with its required arguments
+// filled from VCD. The values are retrieved by running the importer and the read function of the
+// resource on the given import ID, the same way as `terraform import` would do.
+func resourceSkeleton(meta interface{}, resourceType, address, importId string) (string, error) {
+	res, ok := globalResourceMap[resourceType]
+	if !ok {
+		return "", fmt.Errorf("unhandled resource %s", resourceType)
+	}
+	d, err := readImportedResource(res, importId, meta)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]interface{})
+	for key, field := range res.Schema {
+		if field.Required {
+			values[key] = d.Get(key)
+		}
+	}
+
+	var skeleton strings.Builder
+	skeleton.WriteString(fmt.Sprintf("resource %q %q {\n", resourceType, strings.TrimPrefix(address, resourceType+".")))
+	writeSkeletonArguments(&skeleton, res.Schema, values, "  ")
+	skeleton.WriteString("}\n")
+	return skeleton.String(), nil
+}
+
+// readImportedResource imports the resource identified by importId and reads its state
+func readImportedResource(res *schema.Resource, importId string, meta interface{}) (*schema.ResourceData, error) {
+	ctx := context.Background()
+	if res.Importer == nil {
+		return nil, fmt.Errorf("resource does not support import")
+	}
+
+	d := res.Data(nil)
+	d.SetId(importId)
+	var imported []*schema.ResourceData
+	var err error
+	switch {
+	case res.Importer.StateContext != nil:
+		imported, err = res.Importer.StateContext(ctx, d, meta)
+	case res.Importer.State != nil:
+		imported, err = res.Importer.State(d, meta)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error importing '%s': %s", importId, err)
+	}
+	if len(imported) > 0 {
+		d = imported[0]
+	}
+
+	var diags diag.Diagnostics
+	switch {
+	case res.ReadContext != nil:
+		diags = res.ReadContext(ctx, d, meta)
+	case res.ReadWithoutTimeout != nil:
+		diags = res.ReadWithoutTimeout(ctx, d, meta)
+	case res.Read != nil:
+		diags = diag.FromErr(res.Read(d, meta))
+	}
+	if diags.HasError() {
+		return nil, fmt.Errorf("error reading '%s': %v", importId, diags)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("'%s' not found", importId)
+	}
+	return d, nil
+}
+
+// writeSkeletonArguments writes the given values as HCL arguments and blocks, in alphabetical order.
+// Sensitive values are not written, but left as a comment for the user to fill.
+func writeSkeletonArguments(skeleton *strings.Builder, fields map[string]*schema.Schema, values map[string]interface{}, indent string) {
+	var keys []string
+	keyWidth := 0
+	for key := range values {
+		keys = append(keys, key)
+		if !isSkeletonBlock(fields[key]) && !fields[key].Sensitive && len(key) > keyWidth {
+			keyWidth = len(key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := fields[key]
+		value := values[key]
+		if set, ok := value.(*schema.Set); ok {
+			value = set.List()
+		}
+
+		if isSkeletonBlock(field) {
+			blockFields := field.Elem.(*schema.Resource).Schema
+			items, _ := value.([]interface{})
+			for _, item := range items {
+				itemValues, _ := item.(map[string]interface{})
+				blockValues := make(map[string]interface{})
+				for blockKey, blockField := range blockFields {
+					if blockField.Required {
+						blockValues[blockKey] = itemValues[blockKey]
+					}
+				}
+				skeleton.WriteString(fmt.Sprintf("%s%s {\n", indent, key))
+				writeSkeletonArguments(skeleton, blockFields, blockValues, indent+"  ")
+				skeleton.WriteString(fmt.Sprintf("%s}\n", indent))
+			}
+			continue
+		}
+
+		if field.Sensitive {
+			skeleton.WriteString(fmt.Sprintf("%s# %s = (sensitive value, to be filled in)\n", indent, key))
+			continue
+		}
+		skeleton.WriteString(fmt.Sprintf("%s%-*s = %s\n", indent, keyWidth, key, hclValue(value)))
+	}
+}
+
+// isSkeletonBlock returns true if the field is written as a nested block rather than an argument
+func isSkeletonBlock(field *schema.Schema) bool {
+	if field.Type != schema.TypeList && field.Type != schema.TypeSet {
+		return false
+	}
+	_, isResource := field.Elem.(*schema.Resource)
+	return isResource
+}
+
+// hclValue returns the HCL representation of a scalar, list or map value from a resource state
+func hclValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		quoted := fmt.Sprintf("%q", v)
+		// Template sequences must be escaped to be read literally
+		quoted = strings.ReplaceAll(quoted, "${", "$${")
+		return strings.ReplaceAll(quoted, "%{", "%%{")
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, hclValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var items []string
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", hclValue(key), hclValue(v[key])))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
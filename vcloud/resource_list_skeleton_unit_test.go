@@ -0,0 +1,78 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Test_hclValue checks the HCL representation of values retrieved from a resource state
+func Test_hclValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{name: "string", input: "my-org", want: `"my-org"`},
+		{name: "string with quotes", input: `a "b"`, want: `"a \"b\""`},
+		{name: "string with template sequences", input: "${a} %{b}", want: `"$${a} %%{b}"`},
+		{name: "int", input: 42, want: "42"},
+		{name: "bool", input: true, want: "true"},
+		{name: "nil", input: nil, want: "null"},
+		{name: "list", input: []interface{}{"a", "b"}, want: `["a", "b"]`},
+		{name: "empty map", input: map[string]interface{}{}, want: "{}"},
+		{name: "map", input: map[string]interface{}{"z": "1", "a": "2"}, want: `{ "a" = "2", "z" = "1" }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hclValue(tt.input); got != tt.want {
+				t.Errorf("hclValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_writeSkeletonArguments checks that arguments are aligned, nested blocks only get their required
+// fields, and sensitive values are not written
+func Test_writeSkeletonArguments(t *testing.T) {
+	fields := map[string]*schema.Schema{
+		"name":     {Type: schema.TypeString, Required: true},
+		"org":      {Type: schema.TypeString, Required: true},
+		"password": {Type: schema.TypeString, Required: true, Sensitive: true},
+		"static_ip_pool": {
+			Type:     schema.TypeList,
+			Required: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"start_address": {Type: schema.TypeString, Required: true},
+					"description":   {Type: schema.TypeString, Optional: true},
+				},
+			},
+		},
+	}
+	values := map[string]interface{}{
+		"name":     "net1",
+		"org":      "my-org",
+		"password": "secret",
+		"static_ip_pool": []interface{}{
+			map[string]interface{}{"start_address": "10.0.0.2", "description": "ignored"},
+		},
+	}
+
+	var skeleton strings.Builder
+	writeSkeletonArguments(&skeleton, fields, values, "  ")
+
+	want := `  name = "net1"
+  org  = "my-org"
+  # password = (sensitive value, to be filled in)
+  static_ip_pool {
+    start_address = "10.0.0.2"
+  }
+`
+	if skeleton.String() != want {
+		t.Errorf("writeSkeletonArguments() =\n%s\nwant\n%s", skeleton.String(), want)
+	}
+}
@@ -29,6 +29,7 @@ func resourceVcdCatalog() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdCatalogImport,
 		},
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -99,7 +100,8 @@ func resourceVcdCatalog() *schema.Resource {
 				ConflictsWith: []string{"metadata_entry"},
 				Description:   "Key and value pairs for catalog metadata.",
 			},
-			"metadata_entry": metadataEntryResourceSchemaDeprecated("Catalog"),
+			"metadata_entry":      metadataEntryResourceSchemaDeprecated("Catalog"),
+			"deletion_protection": deletionProtectionSchema("catalog"),
 			"href": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -208,10 +210,14 @@ func resourceVcdCatalogCreate(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	log.Printf("[TRACE] adding metadata for catalog")
-	err = createOrUpdateMetadata(d, catalog, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, catalog, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding catalog metadata: %s", err)
 	}
+	err = updateDeletionProtectionMarker(d, vcdClient, catalog)
+	if err != nil {
+		return diag.Errorf("error protecting catalog: %s", err)
+	}
 
 	log.Printf("[TRACE] Catalog created: %#v", catalog)
 	return resourceVcdCatalogRead(ctx, d, meta)
@@ -289,7 +295,7 @@ func resourceVcdCatalogRead(_ context.Context, d *schema.ResourceData, meta inte
 	dSet(d, "href", adminCatalog.AdminCatalog.HREF)
 	d.SetId(adminCatalog.AdminCatalog.ID)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog", adminCatalog)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog", adminCatalog, "resource")...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to update catalog metadata: %v", diags)
 		return diags
@@ -382,10 +388,14 @@ func genericResourceVcdCatalogUpdate(ctx context.Context, d *schema.ResourceData
 		}
 
 		log.Printf("[TRACE] updating metadata for catalog")
-		err = createOrUpdateMetadata(d, adminCatalog, "metadata")
+		err = createOrUpdateMetadata(d, vcdClient, adminCatalog, "metadata")
 		if err != nil {
 			return diag.Errorf("error updating catalog metadata: %s", err)
 		}
+		err = updateDeletionProtectionMarker(d, vcdClient, adminCatalog)
+		if err != nil {
+			return diag.Errorf("error updating the deletion protection of catalog: %s", err)
+		}
 	}
 
 	// If there are custom catalog update functions, we run them one at the time
@@ -401,6 +411,9 @@ func genericResourceVcdCatalogUpdate(ctx context.Context, d *schema.ResourceData
 }
 
 func resourceVcdCatalogDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "Catalog"); diags != nil {
+		return diags
+	}
 	log.Printf("[TRACE] Catalog delete started")
 
 	vcdClient := meta.(*VCDClient)
@@ -21,6 +21,7 @@ func resourceVcdCatalogItem() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdCatalogItemImport,
 		},
+		Timeouts: taskTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -110,9 +111,9 @@ func resourceVcdCatalogItemCreate(ctx context.Context, d *schema.ResourceData, m
 	var diagError diag.Diagnostics
 	itemName := d.Get("name").(string)
 	if d.Get("ova_path").(string) != "" {
-		diagError = uploadOvaFromFilePath(d, catalog, itemName, "vcd_catalog_item")
+		diagError = uploadOvaFromFilePath(ctx, d, vcdClient, catalog, itemName, "vcd_catalog_item")
 	} else if d.Get("ovf_url").(string) != "" {
-		diagError = uploadFromUrl(d, catalog, itemName, "vcd_catalog_item")
+		diagError = uploadFromUrl(ctx, d, catalog, itemName, "vcd_catalog_item")
 	} else {
 		return diag.Errorf("`ova_path` or `ovf_url` value is missing %s", err)
 	}
@@ -187,6 +188,14 @@ func genericVcdCatalogItemRead(d *schema.ResourceData, meta interface{}, origin
 		return diag.FromErr(err)
 	}
 
+	if origin != "datasource" {
+		configuredKeys := map[string]bool{}
+		for key := range d.Get("catalog_item_metadata").(map[string]interface{}) {
+			configuredKeys[key] = true
+		}
+		deprecatedCatalogItemMetadata.MetadataEntry = removeDefaultMetadata(deprecatedCatalogItemMetadata.MetadataEntry, vcdClient, configuredKeys)
+	}
+
 	// Set deprecated metadata attribute of catalog item, just for compatibility reasons
 	err = d.Set("catalog_item_metadata", getMetadataStruct(deprecatedCatalogItemMetadata.MetadataEntry))
 	if err != nil {
@@ -209,7 +218,11 @@ func genericVcdCatalogItemRead(d *schema.ResourceData, meta interface{}, origin
 		return diag.Errorf("Unable to find catalog item's metadata: %s", err)
 	}
 
-	err = setMetadataEntryInState(d, metadata.MetadataEntry)
+	metadataEntries := metadata.MetadataEntry
+	if origin != "datasource" {
+		metadataEntries = removeDefaultMetadata(metadataEntries, vcdClient, getConfiguredMetadataEntryKeys(d))
+	}
+	err = setMetadataEntryInState(d, metadataEntries)
 	if err != nil {
 		return diag.Errorf("Unable to set catalog item's metadata entries: %s", err)
 	}
@@ -217,7 +230,7 @@ func genericVcdCatalogItemRead(d *schema.ResourceData, meta interface{}, origin
 	return nil
 }
 
-func resourceVcdCatalogItemDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdCatalogItemDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	return deleteCatalogItem(d, meta.(*VCDClient))
 }
 
@@ -270,7 +283,7 @@ func createOrUpdateCatalogItemMetadata(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
-	err = createOrUpdateMetadata(d, catalogItem, "catalog_item_metadata")
+	err = createOrUpdateMetadata(d, meta.(*VCDClient), catalogItem, "catalog_item_metadata")
 	if err != nil {
 		return err
 	}
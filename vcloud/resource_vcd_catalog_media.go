@@ -4,10 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
-	"path"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
@@ -24,6 +21,7 @@ func resourceVcdCatalogMedia() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdCatalogMediaImport,
 		},
+		Timeouts: taskTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"org": {
@@ -87,6 +85,13 @@ func resourceVcdCatalogMedia() *schema.Resource {
 				ForceNew:    false,
 				Description: "shows upload progress in stdout",
 			},
+			"checksum": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSha256(),
+				Description:  "Expected SHA-256 of the Media file, verified before uploading it",
+			},
 			"metadata": {
 				Type:          schema.TypeMap,
 				Optional:      true,
@@ -170,55 +175,22 @@ func resourceVcdMediaCreate(ctx context.Context, d *schema.ResourceData, meta in
 
 	uploadPieceSize := d.Get("upload_piece_size").(int)
 	mediaName := d.Get("name").(string)
-	var task govcd.UploadTask
-	uploadAnyFile := d.Get("upload_any_file").(bool)
-
-	if uploadAnyFile {
-		task, err = catalog.UploadMediaFile(mediaName, d.Get("description").(string), mediaPath, int64(uploadPieceSize)*1024*1024, false) // Convert from megabytes to bytes)
-	} else {
-		task, err = catalog.UploadMediaImage(mediaName, d.Get("description").(string), mediaPath, int64(uploadPieceSize)*1024*1024) // Convert from megabytes to bytes)
+	upload := &catalogUpload{
+		client:      &vcdClient.Client,
+		catalog:     catalog,
+		name:        mediaName,
+		description: d.Get("description").(string),
+		fileName:    mediaPath,
+		pieceSize:   int64(uploadPieceSize) * 1024 * 1024, // Convert from megabytes to bytes
+		checksum:    d.Get("checksum").(string),
 	}
-	if err != nil {
-		log.Printf("Error uploading new catalog media: %s", err)
-		return diag.Errorf("error uploading new catalog media: %s", err)
-	}
-
 	if d.Get("show_upload_progress").(bool) {
-		for {
-			if err := getError(task); err != nil {
-				return diag.FromErr(err)
-			}
-
-			logForScreen("vcd_catalog_media", fmt.Sprintf("vcd_catalog_media."+mediaName+": Upload progress "+task.GetUploadProgress()+"%%\n"))
-			if task.GetUploadProgress() == "100.00" {
-				break
-			}
-			time.Sleep(10 * time.Second)
-		}
-	}
-
-	if d.Get("show_upload_progress").(bool) {
-		for {
-			progress, err := task.GetTaskProgress()
-			if err != nil {
-				log.Printf("VCD Error importing new catalog item: %s", err)
-				return diag.Errorf("VCD Error importing new catalog item: %s", err)
-			}
-			logForScreen("vcd_catalog_media", fmt.Sprintf("vcd_catalog_media.%s: VCD import catalog item progress %s%%\n", mediaName, progress))
-			if task.Task != nil && task.Task.Task != nil && task.Task.Task.Status == "aborted" {
-				return diag.Errorf("VCD Error importing new catalog item: the task %s was aborted", task.Task.Task.ID)
-			}
-			if progress == "100" || (task.Task != nil && task.Task.Task != nil && task.Task.Task.Status == "success") {
-				logForScreen("vcd_catalog_media", fmt.Sprintf("vcd_catalog_media.%s: VCD import catalog item finished with status '%s'\n", mediaName, task.Task.Task.Status))
-				break
-			}
-			time.Sleep(10 * time.Second)
-		}
+		upload.progressOrigin = "vcd_catalog_media"
 	}
-
-	err = task.WaitTaskCompletion()
+	err = upload.uploadMedia(ctx, !d.Get("upload_any_file").(bool))
 	if err != nil {
-		return diag.Errorf("error waiting for task to complete: %+v", err)
+		log.Printf("Error uploading new catalog media: %s", err)
+		return diag.Errorf("error uploading new catalog media: %s", err)
 	}
 
 	log.Printf("[TRACE] Catalog media created: %#v", mediaName)
@@ -322,18 +294,14 @@ func genericVcdMediaRead(d *schema.ResourceData, meta interface{}, origin string
 	if origin == "datasource" {
 		downloadToFile := d.Get("download_to_file").(string)
 		if downloadToFile != "" {
-			contents, err := media.Download()
-			if err != nil {
-				return diag.Errorf("error downloading media contents")
-			}
-			downloadToFile = path.Clean(downloadToFile)
-			err = os.WriteFile(downloadToFile, contents, 0600)
+			checksum, err := downloadMedia(&vcdClient.Client, media, downloadToFile, d.Get("download_checksum").(string))
 			if err != nil {
-				return diag.Errorf("error writing media contents to file '%s'", downloadToFile)
+				return diag.Errorf("error downloading media contents to file '%s': %s", downloadToFile, err)
 			}
+			dSet(d, "download_sha256", checksum)
 		}
 	}
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog_media", media)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog_media", media, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to update media item metadata: %v", diags)
 		return diags
@@ -346,7 +314,7 @@ func genericVcdMediaRead(d *schema.ResourceData, meta interface{}, origin string
 	return nil
 }
 
-func resourceVcdMediaDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdMediaDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	return deleteCatalogItem(d, meta.(*VCDClient))
 }
 
@@ -390,7 +358,7 @@ func createOrUpdateMediaItemMetadata(d *schema.ResourceData, meta interface{}) e
 		return fmt.Errorf("unable to find media item: %s", err)
 	}
 
-	return createOrUpdateMetadata(d, media, "metadata")
+	return createOrUpdateMetadata(d, vcdClient, media, "metadata")
 }
 
 // resourceVcdCatalogMediaImport is responsible for importing the resource.
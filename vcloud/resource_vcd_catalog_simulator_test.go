@@ -0,0 +1,47 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSimulatorCatalogLifecycle checks the creation, import and deletion of a catalog
+func TestSimulatorCatalogLifecycle(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdCatalog()
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":         simulatorOrg,
+		"name":        "test-catalog",
+		"description": "created by the simulator test",
+		"metadata":    map[string]interface{}{"purpose": "test"},
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating catalog: %v", diags)
+	}
+	catalogId := d.Id()
+	if d.Get("description").(string) != "created by the simulator test" {
+		t.Fatalf("unexpected description %q", d.Get("description"))
+	}
+	if d.Get("metadata.purpose").(string) != "test" {
+		t.Fatalf("unexpected metadata %v", d.Get("metadata"))
+	}
+
+	imported := importSimulatorResource(t, resource, simulatorOrg+ImportSeparator+"test-catalog", vcdClient)
+	if imported.Id() != catalogId {
+		t.Fatalf("expected imported catalog ID %s, got %s", catalogId, imported.Id())
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting catalog: %v", diags)
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading deleted catalog: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the deleted catalog to be removed from the state")
+	}
+}
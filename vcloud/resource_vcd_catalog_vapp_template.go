@@ -25,6 +25,7 @@ func resourceVcdCatalogVappTemplate() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdCatalogVappTemplateImport,
 		},
+		Timeouts: taskTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -127,6 +128,14 @@ func resourceVcdCatalogVappTemplate() *schema.Resource {
 				Default:     1,
 				Description: "Size of upload file piece size in megabytes",
 			},
+			"checksum": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ValidateFunc:  validateSha256(),
+				ConflictsWith: []string{"ovf_url", "capture_vapp"},
+				Description:   "Expected SHA-256 of the file set in 'ova_path', verified before uploading it",
+			},
 			"lease": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -182,9 +191,9 @@ func resourceVcdCatalogVappTemplateCreate(ctx context.Context, d *schema.Resourc
 
 	switch {
 	case ovaPath != "":
-		diagError = uploadOvaFromFilePath(d, catalog, vappTemplateName, "vcd_catalog_vapp_template")
+		diagError = uploadOvaFromFilePath(ctx, d, vcdClient, catalog, vappTemplateName, "vcd_catalog_vapp_template")
 	case ovfUrl != "":
-		diagError = uploadFromUrl(d, catalog, vappTemplateName, "vcd_catalog_vapp_template")
+		diagError = uploadFromUrl(ctx, d, catalog, vappTemplateName, "vcd_catalog_vapp_template")
 	case len(capturevAppTemplate) == 1:
 		templateCaptureSettings := capturevAppTemplate[0].(map[string]interface{})
 		sourceId := templateCaptureSettings["source_id"].(string)
@@ -242,13 +251,36 @@ func resourceVcdCatalogVappTemplateCreate(ctx context.Context, d *schema.Resourc
 		}
 
 		// Locking vApp as it becomes busy when an image is being created
-		unlock := vcdClient.lockVappWithName(org.Org.Name, parentVdc.Vdc.Name, vapp.VApp.Name)
+		unlock, err := vcdClient.lockVappWithName(org.Org.Name, parentVdc.Vdc.Name, vapp.VApp.Name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 		defer unlock()
 
-		createdTemplate, err := catalog.CaptureVappTemplate(vAppCaptureParams)
+		task, err := catalog.CaptureVappTemplateAsync(vAppCaptureParams)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		// The ID is set as soon as the task references the new template, so that a template still being
+		// captured when the timeout expires is kept in the state instead of being left behind
+		if capturing, err := capturedVappTemplate(catalog, task); err == nil {
+			d.SetId(capturing.VAppTemplate.ID)
+		}
+		err = waitForTask(ctx, task)
 		if err != nil {
+			d.SetId("")
+			if ctx.Err() != nil {
+				if capturing, lookupErr := capturedVappTemplate(catalog, task); lookupErr == nil {
+					d.SetId(capturing.VAppTemplate.ID)
+				}
+			}
 			return diag.FromErr(err)
 		}
+		createdTemplate, err := capturedVappTemplate(catalog, task)
+		if err != nil {
+			return diag.Errorf("error retrieving captured vApp Template: %s", err)
+		}
+		d.SetId(createdTemplate.VAppTemplate.ID)
 
 		// Explicitly rename created template to what is specified in `name` field because by
 		// default it will have the name of overwritten template
@@ -273,7 +305,7 @@ func resourceVcdCatalogVappTemplateCreate(ctx context.Context, d *schema.Resourc
 		return diag.Errorf("error retrieving vApp Template %s: %s", vappTemplateName, err)
 	}
 
-	err = createOrUpdateMetadata(d, vAppTemplate, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, vAppTemplate, "metadata")
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -288,6 +320,19 @@ func resourceVcdCatalogVappTemplateCreate(ctx context.Context, d *schema.Resourc
 	return resourceVcdCatalogVappTemplateRead(ctx, d, meta)
 }
 
+// capturedVappTemplate returns the vApp Template created by a capture task, which is the owner of the task
+// once VCD has created the template
+func capturedVappTemplate(catalog *govcd.Catalog, task govcd.Task) (*govcd.VAppTemplate, error) {
+	err := task.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	if task.Task.Owner == nil || task.Task.Owner.Type != types.MimeVAppTemplate {
+		return nil, fmt.Errorf("%s: capture task does not reference a vApp Template yet", govcd.ErrorEntityNotFound)
+	}
+	return catalog.GetVappTemplateByHref(task.Task.Owner.HREF)
+}
+
 func resourceVcdCatalogVappTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	return genericVcdCatalogVappTemplateRead(ctx, d, meta, "resource")
 }
@@ -371,7 +416,18 @@ func genericVcdCatalogVappTemplateRead(_ context.Context, d *schema.ResourceData
 	}
 	dSet(d, "catalog_item_id", catalogItemId)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog_vapp_template", vAppTemplate)...)
+	if origin == "datasource" {
+		downloadToFile := d.Get("download_to_file").(string)
+		if downloadToFile != "" {
+			checksum, err := downloadVappTemplate(&vcdClient.Client, vAppTemplate, downloadToFile, d.Get("download_checksum").(string))
+			if err != nil {
+				return diag.Errorf("error exporting vApp Template to file '%s': %s", downloadToFile, err)
+			}
+			dSet(d, "download_sha256", checksum)
+		}
+	}
+
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_catalog_vapp_template", vAppTemplate, origin)...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -404,14 +460,14 @@ func resourceVcdCatalogVappTemplateUpdate(_ context.Context, d *schema.ResourceD
 	if err != nil {
 		return diag.Errorf("error updating VApp template lease terms: %s", err)
 	}
-	err = createOrUpdateMetadata(d, vAppTemplate, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, vAppTemplate, "metadata")
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
 }
 
-func resourceVcdCatalogVappTemplateDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdCatalogVappTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 
 	catalogId := d.Get("catalog_id").(string)
@@ -428,7 +484,10 @@ func resourceVcdCatalogVappTemplateDelete(_ context.Context, d *schema.ResourceD
 		return diag.Errorf("unable to find vApp Template with name %s", vAppTemplateName)
 	}
 
-	err = vAppTemplate.Delete()
+	task, err := vAppTemplate.DeleteAsync()
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
 	if err != nil {
 		log.Printf("[DEBUG] Error removing vApp Template %s", err)
 		return diag.Errorf("error removing vApp Template %s", err)
@@ -639,28 +698,42 @@ func checkSynchronisedVappTemplate(vcdClient *VCDClient, vAppTemplate *govcd.VAp
 }
 
 // uploadOvaFromFilePath uploads an OVA file specified in the resource to the given catalog
-func uploadOvaFromFilePath(d *schema.ResourceData, catalog *govcd.Catalog, vappTemplate, resourceName string) diag.Diagnostics {
+func uploadOvaFromFilePath(ctx context.Context, d *schema.ResourceData, vcdClient *VCDClient, catalog *govcd.Catalog, vappTemplate, resourceName string) diag.Diagnostics {
 	uploadPieceSize := d.Get("upload_piece_size").(int)
-	task, err := catalog.UploadOvf(d.Get("ova_path").(string), vappTemplate, d.Get("description").(string), int64(uploadPieceSize)*1024*1024) // Convert from megabytes to bytes
+	upload := &catalogUpload{
+		client:      &vcdClient.Client,
+		catalog:     catalog,
+		name:        vappTemplate,
+		description: d.Get("description").(string),
+		fileName:    d.Get("ova_path").(string),
+		pieceSize:   int64(uploadPieceSize) * 1024 * 1024, // Convert from megabytes to bytes
+	}
+	// This is a deprecated feature from vcd_catalog_item, to be removed with vcd_catalog_item
+	if resourceName == "vcd_catalog_item" && d.Get("show_upload_progress").(bool) {
+		upload.progressOrigin = resourceName
+	}
+	if resourceName == "vcd_catalog_vapp_template" {
+		upload.checksum = d.Get("checksum").(string)
+	}
+	err := upload.uploadVappTemplate(ctx)
 	if err != nil {
 		log.Printf("[DEBUG] Error uploading file: %s", err)
 		return diag.Errorf("error uploading file: %s", err)
 	}
-
-	return finishHandlingTask(d, *task.Task, vappTemplate, resourceName)
+	return nil
 }
 
-func uploadFromUrl(d *schema.ResourceData, catalog *govcd.Catalog, itemName, resourceName string) diag.Diagnostics {
+func uploadFromUrl(ctx context.Context, d *schema.ResourceData, catalog *govcd.Catalog, itemName, resourceName string) diag.Diagnostics {
 	task, err := catalog.UploadOvfByLink(d.Get("ovf_url").(string), itemName, d.Get("description").(string))
 	if err != nil {
 		log.Printf("[DEBUG] Error uploading OVF from URL: %s", err)
 		return diag.Errorf("error uploading OVF from URL: %s", err)
 	}
 
-	return finishHandlingTask(d, task, itemName, resourceName)
+	return finishHandlingTask(ctx, d, task, itemName, resourceName)
 }
 
-func finishHandlingTask(d *schema.ResourceData, task govcd.Task, itemName string, resourceName string) diag.Diagnostics {
+func finishHandlingTask(ctx context.Context, d *schema.ResourceData, task govcd.Task, itemName string, resourceName string) diag.Diagnostics {
 	// This is a deprecated feature from vcd_catalog_item, to be removed with vcd_catalog_item
 	if resourceName == "vcd_catalog_item" && d.Get("show_upload_progress").(bool) {
 		for {
@@ -673,11 +746,15 @@ func finishHandlingTask(d *schema.ResourceData, task govcd.Task, itemName string
 			if progress == "100" {
 				break
 			}
-			time.Sleep(10 * time.Second)
+			select {
+			case <-ctx.Done():
+				return diag.Errorf("VCD Error importing new catalog item: %s", ctx.Err())
+			case <-time.After(10 * time.Second):
+			}
 		}
 	}
 
-	err := task.WaitTaskCompletion()
+	err := waitForTask(ctx, task)
 	if err != nil {
 		return diag.Errorf("error waiting for task to complete: %+v", err)
 	}
@@ -19,6 +19,7 @@ func resourceVcdClonedVApp() *schema.Resource {
 		CreateContext: resourceVcdClonedVAppCreate,
 		ReadContext:   resourceVcdClonedVAppRead,
 		DeleteContext: resourceVcdClonedVAppDelete,
+		Timeouts:      taskTimeoutsNoUpdate(),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -141,7 +142,9 @@ func resourceVcdClonedVAppCreate(ctx context.Context, d *schema.ResourceData, me
 					" Either set 'delete_source' to false or power off the vApp", sourceVapp.VApp.Name)
 			}
 		}
-		vapp, err = vdc.CloneVapp(params)
+		params.Xmlns = types.XMLNamespaceVCloud
+		params.Ovf = types.XMLNamespaceOVF
+		vapp, err = startVappCreation(vcdClient, vdc, "cloneVApp", types.MimeCloneVapp, params)
 		if err != nil {
 			return diag.Errorf("error cloning vApp %s from another vApp: %s", vappName, err)
 		}
@@ -164,13 +167,18 @@ func resourceVcdClonedVAppCreate(ctx context.Context, d *schema.ResourceData, me
 			IsSourceDelete:   deleteSource,
 			AllEULAsAccepted: true,
 		}
-		vapp, err = vdc.CreateVappFromTemplate(params)
+		params.Xmlns = types.XMLNamespaceVCloud
+		params.Ovf = types.XMLNamespaceOVF
+		vapp, err = startVappCreation(vcdClient, vdc, "instantiateVAppTemplate", types.MimeInstantiateVappTemplateParams, params)
 		if err != nil {
 			return diag.Errorf("error creating vApp %s from template: %s", vappName, err)
 		}
 	}
 
-	d.SetId(vapp.VApp.ID)
+	err = waitForVappCreation(ctx, d, vcdClient, vapp)
+	if err != nil {
+		return diag.Errorf("error creating vApp %s: %s", vappName, err)
+	}
 
 	vappStatus, err := vapp.GetStatus()
 	if err != nil {
@@ -186,7 +194,7 @@ func resourceVcdClonedVAppCreate(ctx context.Context, d *schema.ResourceData, me
 		if err != nil {
 			return diag.Errorf("error requesting power change on vApp '%s': %s", vappName, err)
 		}
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return diag.Errorf("error while powering on vApp '%s': %s", vappName, err)
 		}
@@ -29,6 +29,7 @@ func resourceVcdCseKubernetesCluster() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdCseKubernetesImport,
 		},
+		Timeouts: taskTimeoutsNoUpdate(),
 		Schema: map[string]*schema.Schema{
 			"cse_version": {
 				Type:         schema.TypeString,
@@ -505,7 +506,7 @@ func resourceVcdCseKubernetesClusterCreate(ctx context.Context, d *schema.Resour
 		}
 	}
 
-	cluster, err := org.CseCreateKubernetesCluster(creationData, time.Duration(d.Get("operations_timeout_minutes").(int))*time.Minute)
+	cluster, err := org.CseCreateKubernetesCluster(creationData, getCseOperationsTimeout(ctx, d))
 	if err != nil && cluster == nil {
 		return diag.Errorf("Kubernetes cluster creation failed: %s", err)
 	}
@@ -704,7 +705,7 @@ func resourceVcdCseKubernetesUpdate(ctx context.Context, d *schema.ResourceData,
 // the flags "markForDelete" and "forceDelete" back to true, so the CSE Server is able to delete all cluster elements
 // and perform a cleanup. Hence, this function sends an update of just these two properties and waits for the cluster RDE
 // to be gone.
-func resourceVcdCseKubernetesDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdCseKubernetesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 	cluster, err := vcdClient.CseGetKubernetesClusterById(d.Id())
 	if err != nil {
@@ -713,13 +714,33 @@ func resourceVcdCseKubernetesDelete(_ context.Context, d *schema.ResourceData, m
 		}
 		return diag.FromErr(err)
 	}
-	err = cluster.Delete(time.Duration(d.Get("operations_timeout_minutes").(int)) * time.Minute)
+	err = cluster.Delete(getCseOperationsTimeout(ctx, d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
 }
 
+// getCseOperationsTimeout returns the time to wait for a cluster operation: the value of "operations_timeout_minutes",
+// limited by the deadline of the context, which comes from the resource "timeouts"
+func getCseOperationsTimeout(ctx context.Context, d *schema.ResourceData) time.Duration {
+	timeout := time.Duration(d.Get("operations_timeout_minutes").(int)) * time.Minute
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	remaining := time.Until(deadline)
+	// A zero timeout means "wait indefinitely" for the CSE operations, so it can't be returned when the deadline
+	// has already passed
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	if timeout == 0 || remaining < timeout {
+		return remaining
+	}
+	return timeout
+}
+
 func resourceVcdCseKubernetesImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	vcdClient := meta.(*VCDClient)
 	cluster, err := vcdClient.CseGetKubernetesClusterById(d.Id())
@@ -432,7 +432,9 @@ func genericVcdEdgeGatewayRead(d *schema.ResourceData, meta interface{}, origin
 // resourceVcdEdgeGatewayUpdate updates general load balancer settings only at the moment
 func resourceVcdEdgeGatewayUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockEdgeGateway(d)
+	if err := vcdClient.lockEdgeGateway(d); err != nil {
+		return err
+	}
 	defer vcdClient.unlockEdgeGateway(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "name")
@@ -465,7 +467,9 @@ func resourceVcdEdgeGatewayDelete(d *schema.ResourceData, meta interface{}) erro
 
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockEdgeGateway(d)
+	if err := vcdClient.lockEdgeGateway(d); err != nil {
+		return err
+	}
 	defer vcdClient.unlockEdgeGateway(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "name")
@@ -151,7 +151,9 @@ func resourceVcdEdgeGatewayVpnCreate(d *schema.ResourceData, meta interface{}) e
 	vcdClient := meta.(*VCDClient)
 	log.Printf("[TRACE] CLIENT: %#v", vcdClient)
 
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -241,7 +243,9 @@ func resourceVcdEdgeGatewayVpnDelete(d *schema.ResourceData, meta interface{}) e
 
 	log.Printf("[TRACE] CLIENT: %#v", vcdClient)
 
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
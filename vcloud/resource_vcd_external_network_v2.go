@@ -192,6 +192,7 @@ func resourceVcdExternalNetworkV2() *schema.Resource {
 				Description:  "Reference to NSX-T Tier-0 router or segment and manager",
 				Elem:         networkV2NsxtNetwork,
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("External Network"),
 		},
 	}
 }
@@ -213,6 +214,11 @@ func resourceVcdExternalNetworkV2Create(ctx context.Context, d *schema.ResourceD
 	// Only store ID and leave all the rest to "READ"
 	d.SetId(extNet.ExternalNetwork.ID)
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_external_network_v2", d.Id(), extNet.ExternalNetwork.Name))
+	if err != nil {
+		return diag.Errorf("could not create metadata for the external network V2: %s", err)
+	}
+
 	return resourceVcdExternalNetworkV2Read(ctx, d, meta)
 }
 
@@ -243,6 +249,11 @@ func resourceVcdExternalNetworkV2Update(ctx context.Context, d *schema.ResourceD
 		return diag.Errorf("error updating external network V2: %s", err)
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_external_network_v2", d.Id(), netType.Name))
+	if err != nil {
+		return diag.Errorf("could not update metadata for the external network V2: %s", err)
+	}
+
 	return resourceVcdExternalNetworkV2Read(ctx, d, meta)
 }
 
@@ -264,7 +275,7 @@ func resourceVcdExternalNetworkV2Read(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("%s", err)
 	}
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_external_network_v2", newOpenApiEntityMetadata(vcdClient, "vcd_external_network_v2", d.Id(), extNet.ExternalNetwork.Name))
 }
 
 func resourceVcdExternalNetworkV2Delete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -28,6 +28,8 @@ func resourceVcdIndependentDisk() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdIndependentDiskImport,
 		},
+		Timeouts:      taskTimeouts(),
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -130,7 +132,8 @@ func resourceVcdIndependentDisk() *schema.Resource {
 				Deprecated:    "Use metadata_entry instead",
 				ConflictsWith: []string{"metadata_entry"},
 			},
-			"metadata_entry": metadataEntryResourceSchemaDeprecated("Disk"),
+			"metadata_entry":      metadataEntryResourceSchemaDeprecated("Disk"),
+			"deletion_protection": deletionProtectionSchema("independent disk"),
 		},
 	}
 }
@@ -224,7 +227,7 @@ func resourceVcdIndependentDiskCreate(ctx context.Context, d *schema.ResourceDat
 		return diag.Errorf("error creating independent disk: %s", err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.Errorf("error waiting to finish creation of independent disk: %s", err)
 	}
@@ -237,10 +240,14 @@ func resourceVcdIndependentDiskCreate(ctx context.Context, d *schema.ResourceDat
 
 	d.SetId(disk.Disk.Id)
 
-	err = createOrUpdateMetadata(d, disk, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, disk, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding metadata to independent disk: %s", err)
 	}
+	err = updateDeletionProtectionMarker(d, vcdClient, disk)
+	if err != nil {
+		return diag.Errorf("error protecting independent disk: %s", err)
+	}
 
 	return resourceVcdIndependentDiskRead(ctx, d, meta)
 }
@@ -319,7 +326,7 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 			return diag.Errorf("error updating independent disk: %s", err)
 		}
 
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return diag.Errorf("error waiting to finish updating of independent disk: %s", err)
 		}
@@ -331,7 +338,11 @@ func resourceVcdIndependentDiskUpdate(ctx context.Context, d *schema.ResourceDat
 
 	}
 
-	err = createOrUpdateMetadata(d, disk, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, disk, "metadata")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = updateDeletionProtectionMarker(d, vcdClient, disk)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -486,7 +497,7 @@ func resourceVcdIndependentDiskRead(_ context.Context, d *schema.ResourceData, m
 		return diag.Errorf("unable to find queried disk with name %s: and href: %s, %s", identifier, disk.Disk.HREF, err)
 	}
 
-	diagErr := setMainData(d, vcdClient, disk, diskRecord)
+	diagErr := setMainData(d, vcdClient, disk, diskRecord, "resource")
 	if diagErr != nil {
 		return diagErr
 	}
@@ -495,7 +506,7 @@ func resourceVcdIndependentDiskRead(_ context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func setMainData(d *schema.ResourceData, vcdClient *VCDClient, disk *govcd.Disk, diskRecord *types.DiskRecordType) diag.Diagnostics {
+func setMainData(d *schema.ResourceData, vcdClient *VCDClient, disk *govcd.Disk, diskRecord *types.DiskRecordType, origin string) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	d.SetId(disk.Disk.Id)
@@ -530,7 +541,7 @@ func setMainData(d *schema.ResourceData, vcdClient *VCDClient, disk *govcd.Disk,
 		return diag.Errorf("[Independent disk read] error setting the list of attached VM IDs: %s ", err)
 	}
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_independent_disk", disk)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_independent_disk", disk, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set Independent disk metadata")
 		return diags
@@ -543,7 +554,10 @@ func setMainData(d *schema.ResourceData, vcdClient *VCDClient, disk *govcd.Disk,
 	return nil
 }
 
-func resourceVcdIndependentDiskDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdIndependentDiskDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "Independent disk"); diags != nil {
+		return diags
+	}
 	vcdClient := meta.(*VCDClient)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -573,7 +587,7 @@ func resourceVcdIndependentDiskDelete(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("error deleting disk : %#v", err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		d.SetId("")
 		return diag.Errorf("error waiting for deleting disk : %#v", err)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
@@ -18,6 +20,7 @@ func resourceVcdInsertedMedia() *schema.Resource {
 		DeleteContext: resourceVcdMediaEject,
 		ReadContext:   resourceVcdVmInsertedMediaRead,
 		UpdateContext: resourceVcdMediaEjectUpdate,
+		Timeouts:      taskTimeoutsNoUpdate(),
 
 		Schema: map[string]*schema.Schema{
 			"vdc": {
@@ -73,7 +76,9 @@ func resourceVcdMediaInsert(ctx context.Context, d *schema.ResourceData, meta in
 
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	vm, org, err := getVM(d, meta)
@@ -86,7 +91,7 @@ func resourceVcdMediaInsert(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("error: %s", err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.Errorf("error: %s", err)
 	}
@@ -121,11 +126,13 @@ func resourceVcdVmInsertedMediaRead(_ context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func resourceVcdMediaEject(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdMediaEject(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	vm, org, err := getVM(d, meta)
@@ -138,7 +145,7 @@ func resourceVcdMediaEject(_ context.Context, d *schema.ResourceData, meta inter
 		return diag.Errorf("error: %s", err)
 	}
 
-	err = task.WaitTaskCompletion(d.Get("eject_force").(bool))
+	err = waitForEjectTask(ctx, vm, task, d.Get("eject_force").(bool))
 	if err != nil {
 		return diag.Errorf("error: %s", err)
 	}
@@ -146,6 +153,57 @@ func resourceVcdMediaEject(_ context.Context, d *schema.ResourceData, meta inter
 	return nil
 }
 
+// ejectLockQuestion is the question VCD asks when the guest OS of the VM locks the media being ejected
+const ejectLockQuestion = "Disconnect anyway and override the lock?"
+
+// waitForEjectTask waits for a media eject task like task.WaitTaskCompletion, answering the question asked
+// when the guest OS locks the media with ejectForce, but gives up when the context is done
+func waitForEjectTask(ctx context.Context, vm *govcd.VM, task govcd.EjectTask, ejectForce bool) error {
+	for {
+		err := task.Refresh()
+		if err != nil {
+			return fmt.Errorf("error retrieving task: %s", err)
+		}
+		switch task.Task.Task.Status {
+		case "running", "preRunning", "queued":
+		case "error":
+			errorMessage := ""
+			if task.Task.Task.Error != nil {
+				errorMessage = task.Task.Task.Error.Message
+			}
+			return fmt.Errorf("task did not complete successfully: %s", errorMessage)
+		default:
+			return nil
+		}
+
+		question, err := vm.GetQuestion()
+		if err != nil {
+			return fmt.Errorf("error retrieving the question of VM %s: %s", vm.VM.Name, err)
+		}
+		if question.QuestionId != "" && strings.Contains(question.Question, ejectLockQuestion) {
+			answer := "no"
+			if ejectForce {
+				answer = "yes"
+			}
+			for _, choice := range question.Choices {
+				if strings.Contains(choice.Text, answer) {
+					err = vm.AnswerQuestion(question.QuestionId, choice.Id)
+					if err != nil {
+						return fmt.Errorf("error answering the question of VM %s: %s", vm.VM.Name, err)
+					}
+					break
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task '%s' (%s) did not complete in time: %s", task.Task.Task.Operation, task.Task.Task.HREF, ctx.Err())
+		case <-time.After(taskWaitDelay):
+		}
+	}
+}
+
 func getVM(d *schema.ResourceData, meta interface{}) (*govcd.VM, *govcd.Org, error) {
 	vcdClient := meta.(*VCDClient)
 
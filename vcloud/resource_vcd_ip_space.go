@@ -170,6 +170,7 @@ func resourceVcdIpSpace() *schema.Resource {
 				Default:     false,
 				Description: "Flag whether SNAT rule creation should be enabled (VCD 10.5.0+)",
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("IP Space"),
 		},
 	}
 }
@@ -190,6 +191,11 @@ func resourceVcdIpSpaceCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	d.SetId(createdIpSpace.IpSpace.ID)
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_ip_space", d.Id(), createdIpSpace.IpSpace.Name))
+	if err != nil {
+		return diag.Errorf("could not create metadata for the IP Space: %s", err)
+	}
+
 	return resourceVcdIpSpaceRead(ctx, d, meta)
 }
 
@@ -241,6 +247,11 @@ func resourceVcdIpSpaceUpdate(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(err)
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_ip_space", d.Id(), ipSpaceConfig.Name))
+	if err != nil {
+		return diag.Errorf("could not update metadata for the IP Space: %s", err)
+	}
+
 	return resourceVcdIpSpaceRead(ctx, d, meta)
 }
 
@@ -262,7 +273,7 @@ func resourceVcdIpSpaceRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("error storing IP Space state: %s", err)
 	}
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_ip_space", newOpenApiEntityMetadata(vcdClient, "vcd_ip_space", d.Id(), ipSpace.IpSpace.Name))
 }
 
 func resourceVcdIpSpaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
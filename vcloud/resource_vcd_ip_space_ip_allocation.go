@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/types/v56"
 	"github.com/vmware/go-vcloud-director/v3/util"
 )
@@ -63,23 +65,22 @@ func resourceVcdIpAllocation() *schema.Resource {
 				Optional:      true,
 				Computed:      true,
 				Description:   "Required if 'type' is IP_PREFIX and no custom 'value` is provided",
-				ConflictsWith: []string{"value"},
+				ConflictsWith: []string{"value", "quantity"},
 			},
 			"value": {
 				Type:          schema.TypeString,
 				Optional:      true,
 				Description:   "IP address or CIDR to use. (VCD 10.4.2+)",
-				ConflictsWith: []string{"prefix_length"},
+				ConflictsWith: []string{"prefix_length", "quantity"},
 				ForceNew:      true, // Once a particular IP or Prefix is allocated - its changes are ignored by the API
 
 				// API supports allocation of IP ranges (e.g. 10.10.10.1-10.10.10.3), but this
 				// results in multiple separate allocations with separate IDs which goes against
-				// Terraform principle. We have 'quantity' field disabled for the same reason.
-				// Users can define multiple instances of this resource to allocate IP ranges
+				// Terraform principle. Contiguous IP addresses can be requested with 'quantity'
 				ValidateFunc: validation.StringDoesNotMatch(
 					regexp.MustCompile("-"), // having a hyphen '-' in the value means that it is an IP range
 					"This resource does not support allocating IP ranges due to Terraform resources map to single entity. "+
-						"Please use multiple resource instances to allocate multiple IP addresses",
+						"Please use 'quantity' to allocate multiple contiguous IP addresses",
 				),
 			},
 			"ip_address": {
@@ -102,17 +103,26 @@ func resourceVcdIpAllocation() *schema.Resource {
 				Computed:    true,
 				Description: "IP address part",
 			},
-
-			// The resource supports 'quantity' parameter, however it goes against Terraform concept
-			// of - "one resource:one entity" mapping therefore it is left commented for now unless
-			// a real use case appears and we have to hack around Terraform schema
-			// "quantity": {
-			// 	Type:        schema.TypeString,
-			// 	Optional:    true,
-			// 	Default:     "1",
-			// 	ForceNew:    true,
-			// 	Description: "Number of entries to allocate",
-			// },
+			"quantity": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Number of contiguous floating IP addresses to allocate (VCD 10.4.2+ when more than 1)",
+			},
+			"ip_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "All the IP addresses or CIDRs of this allocation, in ascending order",
+			},
+			"allocation_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the VCD allocations of each IP address, in the same order as 'ip_addresses'",
+			},
 		},
 	}
 }
@@ -155,12 +165,38 @@ func resourceVcdIpAllocationCreate(ctx context.Context, d *schema.ResourceData,
 		allocationConfig.Quantity = nil // Quantity field must not be set when 'value' is specified
 	}
 
+	// A 'quantity' request in the API returns any free IP addresses, which are not necessarily
+	// contiguous. Contiguous IP addresses are found in the IP Space and requested as a range in
+	// 'value', which results in one allocation per IP address
+	if quantity := d.Get("quantity").(int); quantity > 1 {
+		if allocationConfig.Type != types.IpSpaceIpAllocationTypeFloatingIp {
+			return diag.Errorf("'quantity' greater than 1 can only be used with 'type' FLOATING_IP")
+		}
+		if vcdClient.Client.APIVCDMaxVersionIs("< 37.2") {
+			return diag.Errorf("'quantity' greater than 1 can only be specified on VCD 10.4.2+")
+		}
+		block, err := findFreeIpSpaceBlock(ipSpace, quantity)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		allocationConfig.Value = block.String()
+		allocationConfig.Quantity = nil
+	}
+
 	allocation, err := ipSpace.AllocateIp(orgId, org.Org.Name, &allocationConfig)
 	if err != nil {
 		return diag.Errorf("error allocating IP: %s", err)
 	}
+	allocationIds, err := sortIpAllocationResults(allocation)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	d.SetId(allocation[0].ID)
+	d.SetId(allocationIds[0])
+	err = d.Set("allocation_ids", allocationIds)
+	if err != nil {
+		return diag.Errorf("error storing 'allocation_ids': %s", err)
+	}
 	dSet(d, "ip_address", allocation[0].Value)
 
 	// Perform manual reservation if there is a request for USED_MANUAL (it always needs a separate
@@ -174,23 +210,25 @@ func resourceVcdIpAllocationCreate(ctx context.Context, d *schema.ResourceData,
 	// If user specified
 	usageState := d.Get("usage_state").(string)
 	if usageState == "USED_MANUAL" {
-		// Retrieve IP Allocation object
-		ipSpaceAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocation[0].ID)
-		if err != nil {
-			return diag.Errorf("error retrieving IP Space IP Allocation after request: %s", err)
-		}
-
-		ipAllocationUpdate := &types.IpSpaceIpAllocation{
-			ID:          allocation[0].ID,
-			Type:        d.Get("type").(string),
-			UsageState:  usageState,
-			Description: d.Get("description").(string),
-			Value:       ipSpaceAllocation.IpSpaceIpAllocation.Value,
-		}
-
-		_, err = ipSpaceAllocation.Update(ipAllocationUpdate)
-		if err != nil {
-			return diag.Errorf("error updating IP Space IP Allocation after creation: %s", err)
+		for _, allocationId := range allocationIds {
+			// Retrieve IP Allocation object
+			ipSpaceAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationId)
+			if err != nil {
+				return diag.Errorf("error retrieving IP Space IP Allocation after request: %s", err)
+			}
+
+			ipAllocationUpdate := &types.IpSpaceIpAllocation{
+				ID:          allocationId,
+				Type:        d.Get("type").(string),
+				UsageState:  usageState,
+				Description: d.Get("description").(string),
+				Value:       ipSpaceAllocation.IpSpaceIpAllocation.Value,
+			}
+
+			_, err = ipSpaceAllocation.Update(ipAllocationUpdate)
+			if err != nil {
+				return diag.Errorf("error updating IP Space IP Allocation after creation: %s", err)
+			}
 		}
 	}
 
@@ -209,17 +247,19 @@ func resourceVcdIpAllocationUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("error getting Org by ID: %s", err)
 	}
 
-	ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, d.Id())
-	if err != nil {
-		return diag.Errorf("error retrieving IP Allocation: %s", err)
-	}
-
 	if d.HasChange("usage_state") || d.HasChange("description") {
-		ipAllocation.IpSpaceIpAllocation.UsageState = d.Get("usage_state").(string)
-		ipAllocation.IpSpaceIpAllocation.Description = d.Get("description").(string)
-		_, err = ipAllocation.Update(ipAllocation.IpSpaceIpAllocation)
-		if err != nil {
-			return diag.Errorf("error updating IP Space IP Allocation: %s", err)
+		for _, allocationId := range ipAllocationIds(d) {
+			ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationId)
+			if err != nil {
+				return diag.Errorf("error retrieving IP Allocation: %s", err)
+			}
+
+			ipAllocation.IpSpaceIpAllocation.UsageState = d.Get("usage_state").(string)
+			ipAllocation.IpSpaceIpAllocation.Description = d.Get("description").(string)
+			_, err = ipAllocation.Update(ipAllocation.IpSpaceIpAllocation)
+			if err != nil {
+				return diag.Errorf("error updating IP Space IP Allocation: %s", err)
+			}
 		}
 	}
 
@@ -238,10 +278,22 @@ func resourceVcdIpAllocationRead(ctx context.Context, d *schema.ResourceData, me
 		return diag.Errorf("error getting Org by id: %s", err)
 	}
 
+	allocationIds := ipAllocationIds(d)
+	ipAddresses := make([]string, 0, len(allocationIds))
+	for _, allocationId := range allocationIds[1:] {
+		ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationId)
+		if err != nil {
+			return diag.Errorf("error getting IP Space IP Allocation '%s': %s", allocationId, err)
+		}
+		ipAddresses = append(ipAddresses, ipAllocation.IpSpaceIpAllocation.Value)
+	}
+
+	// The first allocation is the one identifying the resource
 	ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, d.Id())
 	if err != nil {
 		return diag.Errorf("error getting IP Space IP Allocation: %s", err)
 	}
+	ipAddresses = append([]string{ipAllocation.IpSpaceIpAllocation.Value}, ipAddresses...)
 
 	dSet(d, "description", ipAllocation.IpSpaceIpAllocation.Description)
 	dSet(d, "type", ipAllocation.IpSpaceIpAllocation.Type)
@@ -254,6 +306,16 @@ func resourceVcdIpAllocationRead(ctx context.Context, d *schema.ResourceData, me
 	dSet(d, "allocation_date", ipAllocation.IpSpaceIpAllocation.AllocationDate)
 	dSet(d, "usage_state", ipAllocation.IpSpaceIpAllocation.UsageState)
 	dSet(d, "ip_address", ipAllocation.IpSpaceIpAllocation.Value)
+	// Allocations imported or created before 'quantity' was supported get it from the number of allocations
+	dSet(d, "quantity", len(allocationIds))
+	err = d.Set("allocation_ids", allocationIds)
+	if err != nil {
+		return diag.Errorf("error storing 'allocation_ids': %s", err)
+	}
+	err = d.Set("ip_addresses", ipAddresses)
+	if err != nil {
+		return diag.Errorf("error storing 'ip_addresses': %s", err)
+	}
 
 	// When IP Prefix is allocated, the returned value is in CIDR format (e.g. 192.168.1.0/24), and
 	// although it can be split using Terraform native functions, we're adding a convenience layer for
@@ -288,17 +350,80 @@ func resourceVcdIpAllocationDelete(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("error getting Org by id: %s", err)
 	}
 
-	ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, d.Id())
-	if err != nil {
-		return diag.Errorf("error getting IP Space IP Allocation: %s", err)
+	// Allocations that are already gone, such as the ones released out of band or by an interrupted
+	// deletion of a multi-allocation, are skipped
+	for _, allocationId := range ipAllocationIds(d) {
+		ipAllocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationId)
+		if govcd.ContainsNotFound(err) {
+			log.Printf("[DEBUG] IP Space IP Allocation '%s' is already deleted", allocationId)
+			continue
+		}
+		if err != nil {
+			return diag.Errorf("error getting IP Space IP Allocation: %s", err)
+		}
+
+		err = ipAllocation.Delete()
+		if err != nil && !govcd.ContainsNotFound(err) {
+			return diag.Errorf("error deleting IP Space IP Allocation: %s", err)
+		}
 	}
 
-	err = ipAllocation.Delete()
+	return nil
+}
+
+// ipAllocationIds returns the IDs of all the VCD allocations of the resource, starting with the one in
+// the resource ID. Resources created before 'quantity' was supported only have the latter
+func ipAllocationIds(d *schema.ResourceData) []string {
+	allocationIds := convertTypeListToSliceOfStrings(d.Get("allocation_ids").([]interface{}))
+	if len(allocationIds) == 0 || allocationIds[0] != d.Id() {
+		return []string{d.Id()}
+	}
+	return allocationIds
+}
+
+// findFreeIpSpaceBlock returns the first block of 'quantity' contiguous IP addresses of the IP Space
+// that are not allocated
+func findFreeIpSpaceBlock(ipSpace *govcd.IpSpace, quantity int) (ipBlock, error) {
+	ranges, err := ipSpaceRangeBlocks(ipSpace.IpSpace)
+	if err != nil {
+		return ipBlock{}, err
+	}
+	allocations, err := getIpSpaceAllocations(ipSpace, types.IpSpaceIpAllocationTypeFloatingIp)
+	if err != nil {
+		return ipBlock{}, err
+	}
+	allocated, err := allocationBlocks(allocations)
 	if err != nil {
-		return diag.Errorf("error deleting IP Space IP Allocation: %s", err)
+		return ipBlock{}, err
+	}
+	block, found := findContiguousIpBlock(freeIpBlocks(ranges, allocated), quantity)
+	if !found {
+		return ipBlock{}, fmt.Errorf("IP Space '%s' has no block of %d contiguous free IP addresses", ipSpace.IpSpace.Name, quantity)
 	}
+	return block, nil
+}
 
-	return nil
+// sortIpAllocationResults sorts the results of an allocation request by IP address and returns their IDs
+func sortIpAllocationResults(results []types.IpSpaceIpAllocationRequestResult) ([]string, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("IP allocation request returned no allocations")
+	}
+	blocks := make(map[string]ipBlock, len(results))
+	for _, result := range results {
+		block, err := parseIpBlock(result.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing allocated IP '%s': %s", result.Value, err)
+		}
+		blocks[result.ID] = block
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return blocks[results[i].ID].start.Less(blocks[results[j].ID].start)
+	})
+	allocationIds := make([]string, 0, len(results))
+	for _, result := range results {
+		allocationIds = append(allocationIds, result.ID)
+	}
+	return allocationIds, nil
 }
 
 func resourceVcdIpAllocationImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
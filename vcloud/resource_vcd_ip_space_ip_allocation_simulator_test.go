@@ -0,0 +1,131 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorIpAllocationQuantity checks that 'quantity' allocates a block of contiguous IP addresses,
+// that 'quantity' is read back, and that the deletion skips the allocations that are already gone
+func TestSimulatorIpAllocationQuantity(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdIpAllocation()
+
+	ipSpaceId := sim.AddIpSpace(types.IpSpace{
+		Name: "public",
+		IPSpaceRanges: types.IPSpaceRanges{
+			IPRanges:               []types.IpSpaceRangeValues{{StartIPAddress: "10.0.0.1", EndIPAddress: "10.0.0.10"}},
+			DefaultFloatingIPQuota: -1,
+		},
+	})
+	org, err := vcdClient.GetOrg(simulatorOrg)
+	if err != nil {
+		t.Fatalf("error retrieving Org: %s", err)
+	}
+
+	// An allocation made out of band leaves 10.0.0.1 as the only free address before 10.0.0.3
+	_, err = org.IpSpaceAllocateIp(ipSpaceId, &types.IpSpaceIpAllocationRequest{
+		Type:  types.IpSpaceIpAllocationTypeFloatingIp,
+		Value: "10.0.0.2",
+	})
+	if err != nil {
+		t.Fatalf("error allocating IP out of band: %s", err)
+	}
+
+	config := func(quantity int) map[string]interface{} {
+		return map[string]interface{}{
+			"org_id":      org.Org.ID,
+			"ip_space_id": ipSpaceId,
+			"type":        "FLOATING_IP",
+			"quantity":    quantity,
+			"usage_state": "USED_MANUAL",
+			"description": "web servers",
+		}
+	}
+
+	d := simulatorResourceData(t, resource, config(20))
+	diags := resource.CreateContext(ctx, d, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "no block of 20 contiguous free IP addresses") {
+		t.Fatalf("expected an error for a block larger than the free addresses, got %v", diags)
+	}
+
+	d = simulatorResourceData(t, resource, config(3))
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating IP allocation: %v", diags)
+	}
+	wantAddresses := []interface{}{"10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	if addresses := d.Get("ip_addresses").([]interface{}); !reflect.DeepEqual(addresses, wantAddresses) {
+		t.Fatalf("expected IP addresses %v, got %v", wantAddresses, addresses)
+	}
+	allocationIds := d.Get("allocation_ids").([]interface{})
+	if len(allocationIds) != 3 || allocationIds[0] != d.Id() {
+		t.Fatalf("expected 3 allocation IDs starting with %s, got %v", d.Id(), allocationIds)
+	}
+	if allocations := sim.IpSpaceAllocations(ipSpaceId); !reflect.DeepEqual(allocations, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}) {
+		t.Fatalf("unexpected allocations in the IP Space: %v", allocations)
+	}
+	for _, allocationId := range allocationIds {
+		allocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationId.(string))
+		if err != nil {
+			t.Fatalf("error retrieving allocation %s: %s", allocationId, err)
+		}
+		if allocation.IpSpaceIpAllocation.UsageState != "USED_MANUAL" || allocation.IpSpaceIpAllocation.Description != "web servers" {
+			t.Errorf("expected allocation %s to be marked for manual use, got %#v", allocationId, allocation.IpSpaceIpAllocation)
+		}
+	}
+
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading IP allocation: %v", diags)
+	}
+	if addresses := d.Get("ip_addresses").([]interface{}); !reflect.DeepEqual(addresses, wantAddresses) {
+		t.Errorf("expected IP addresses %v after a read, got %v", wantAddresses, addresses)
+	}
+	if d.Get("ip_address").(string) != "10.0.0.3" || d.Get("usage_state").(string) != "USED_MANUAL" {
+		t.Errorf("expected the first allocation to be read, got IP %s and usage state %s", d.Get("ip_address"), d.Get("usage_state"))
+	}
+	if d.Get("quantity").(int) != 3 {
+		t.Errorf("expected quantity 3 after a read, got %d", d.Get("quantity"))
+	}
+
+	// An imported allocation, as one created before 'quantity' was supported, reads a quantity of 1 and is not replaced
+	imported := importSimulatorResource(t, resource, strings.Join([]string{simulatorOrg, "public", "FLOATING_IP", "10.0.0.2"}, ImportSeparator), vcdClient)
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading imported IP allocation: %v", diags)
+	}
+	if quantity := imported.State().Attributes["quantity"]; quantity != "1" {
+		t.Errorf("expected quantity 1 in state after an import, got %q", quantity)
+	}
+	diff, err := simulatorPlan(t, resource, imported.State(), map[string]interface{}{
+		"org_id":      org.Org.ID,
+		"ip_space_id": ipSpaceId,
+		"type":        "FLOATING_IP",
+	}, vcdClient)
+	if err != nil {
+		t.Fatalf("error planning the imported IP allocation: %s", err)
+	}
+	if diff != nil && diff.RequiresNew() {
+		t.Errorf("expected the imported IP allocation not to be replaced, got %#v", diff.Attributes)
+	}
+
+	// The middle allocation is released out of band, and the deletion of the others still succeeds
+	allocation, err := org.GetIpSpaceAllocationById(ipSpaceId, allocationIds[1].(string))
+	if err == nil {
+		err = allocation.Delete()
+	}
+	if err != nil {
+		t.Fatalf("error deleting allocation out of band: %s", err)
+	}
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting IP allocation: %v", diags)
+	}
+	if allocations := sim.IpSpaceAllocations(ipSpaceId); !reflect.DeepEqual(allocations, []string{"10.0.0.2"}) {
+		t.Errorf("expected only the out of band allocation to remain, got %v", allocations)
+	}
+}
@@ -113,7 +113,9 @@ func resourceVcdLBAppProfile() *schema.Resource {
 
 func resourceVcdLBAppProfileCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -159,7 +161,9 @@ func resourceVcdLBAppProfileRead(d *schema.ResourceData, meta interface{}) error
 
 func resourceVcdLBAppProfileUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -187,7 +191,9 @@ func resourceVcdLBAppProfileUpdate(d *schema.ResourceData, meta interface{}) err
 
 func resourceVcdLBAppProfileDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -55,7 +55,9 @@ func resourceVcdLBAppRule() *schema.Resource {
 
 func resourceVcdLBAppRuleCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -100,7 +102,9 @@ func resourceVcdLBAppRuleRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceVcdLBAppRuleUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -128,7 +132,9 @@ func resourceVcdLBAppRuleUpdate(d *schema.ResourceData, meta interface{}) error
 
 func resourceVcdLBAppRuleDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -141,7 +141,9 @@ func resourceVcdLBServerPool() *schema.Resource {
 
 func resourceVcdLBServerPoolCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -186,7 +188,9 @@ func resourceVcdLBServerPoolRead(d *schema.ResourceData, meta interface{}) error
 
 func resourceVcdLBServerPoolUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -210,7 +214,9 @@ func resourceVcdLBServerPoolUpdate(d *schema.ResourceData, meta interface{}) err
 
 func resourceVcdLBServerPoolDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -104,7 +104,9 @@ func resourceVcdLbServiceMonitor() *schema.Resource {
 
 func resourceVcdLbServiceMonitorCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -145,7 +147,9 @@ func resourceVcdLbServiceMonitorRead(d *schema.ResourceData, meta interface{}) e
 
 func resourceVcdLbServiceMonitorUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -170,7 +174,9 @@ func resourceVcdLbServiceMonitorUpdate(d *schema.ResourceData, meta interface{})
 
 func resourceVcdLbServiceMonitorDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return err
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeGateway, err := vcdClient.GetEdgeGatewayFromResource(d, "edge_gateway")
@@ -21,6 +21,9 @@ func resourceVcdNetworkDirect() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdNetworkDirectImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(defaultTaskTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -145,7 +148,7 @@ func resourceVcdNetworkDirectCreate(ctx context.Context, d *schema.ResourceData,
 	}
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, network, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding metadata to direct network: %s", err)
 	}
@@ -207,7 +210,7 @@ func genericVcdNetworkDirectRead(_ context.Context, d *schema.ResourceData, meta
 	dSet(d, "description", network.OrgVDCNetwork.Description)
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_direct", network)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_direct", network, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set direct network metadata: %v", diags)
 		return diags
@@ -240,7 +243,7 @@ func resourceVcdNetworkDirectUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("[direct network update] error updating network %s: %s", network.OrgVDCNetwork.Name, err)
 	}
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, network, "metadata")
 	if err != nil {
 		return diag.Errorf("[direct network update] error updating network metadata: %s", err)
 	}
@@ -22,6 +22,9 @@ func resourceVcdNetworkIsolated() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdNetworkIsolatedImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(defaultTaskTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -259,7 +262,7 @@ func resourceVcdNetworkIsolatedCreate(ctx context.Context, d *schema.ResourceDat
 	}
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, network, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding metadata to isolated network: %s", err)
 	}
@@ -344,7 +347,7 @@ func genericVcdNetworkIsolatedRead(_ context.Context, d *schema.ResourceData, me
 	dSet(d, "description", network.OrgVDCNetwork.Description)
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, meta.(*VCDClient), "vcd_network_isolated", network)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, meta.(*VCDClient), "vcd_network_isolated", network, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set isolated network metadata: %v", diags)
 		return diags
@@ -494,7 +497,7 @@ func resourceVcdNetworkIsolatedUpdate(ctx context.Context, d *schema.ResourceDat
 		return diag.Errorf("error updating isolated network: %s", err)
 	}
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, meta.(*VCDClient), network, "metadata")
 	if err != nil {
 		return diag.Errorf("error updating isolated network metadata: %s", err)
 	}
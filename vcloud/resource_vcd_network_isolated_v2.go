@@ -140,7 +140,9 @@ func resourceVcdNetworkIsolatedV2Create(ctx context.Context, d *schema.ResourceD
 
 	// Only when a network is in VDC Group - it must lock parent VDC Group. It doesn't cause lock
 	// issues when created in VDC.
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -160,7 +162,7 @@ func resourceVcdNetworkIsolatedV2Create(ctx context.Context, d *schema.ResourceD
 
 	d.SetId(orgNetwork.OpenApiOrgVdcNetwork.ID)
 
-	err = createOrUpdateOpenApiNetworkMetadata(d, orgNetwork)
+	err = createOrUpdateOpenApiNetworkMetadata(d, vcdClient, orgNetwork)
 	if err != nil {
 		return diag.Errorf("[isolated network v2 create] error adding metadata to Isolated network: %s", err)
 	}
@@ -180,7 +182,9 @@ func resourceVcdNetworkIsolatedV2Update(ctx context.Context, d *schema.ResourceD
 
 	// Only when a network is in VDC Group - it must lock parent VDC Group. It doesn't cause lock
 	// issues when created in VDC.
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -211,7 +215,7 @@ func resourceVcdNetworkIsolatedV2Update(ctx context.Context, d *schema.ResourceD
 		return diag.Errorf("[isolated network v2 update] error updating Isolated network: %s", err)
 	}
 
-	err = createOrUpdateOpenApiNetworkMetadata(d, orgNetwork)
+	err = createOrUpdateOpenApiNetworkMetadata(d, vcdClient, orgNetwork)
 	if err != nil {
 		return diag.Errorf("[isolated network v2 update] error updating Isolated network metadata: %s", err)
 	}
@@ -248,7 +252,7 @@ func resourceVcdNetworkIsolatedV2Read(_ context.Context, d *schema.ResourceData,
 	// Hence, we skip the read to preserve its value in state.
 	var diags diag.Diagnostics
 	if !govcd.OwnerIsVdcGroup(orgNetwork.OpenApiOrgVdcNetwork.OwnerRef.ID) {
-		diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_isolated_v2", orgNetwork)...)
+		diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_isolated_v2", orgNetwork, "resource")...)
 	} else if _, ok := d.GetOk("metadata"); !ok {
 		// If it's a VDC Group and metadata is not set, we explicitly compute it to empty. Otherwise, its value should
 		// be preserved as it is still present in the entity.
@@ -274,7 +278,9 @@ func resourceVcdNetworkIsolatedV2Delete(_ context.Context, d *schema.ResourceDat
 
 	// Only when a network is in VDC Group - it must lock parent VDC Group. It doesn't cause lock
 	// issues when created in VDC.
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -405,7 +411,7 @@ func getOpenApiOrgVdcIsolatedNetworkType(d *schema.ResourceData, vcdClient *VCDC
 	return orgVdcNetworkConfig, nil
 }
 
-func createOrUpdateOpenApiNetworkMetadata(d *schema.ResourceData, network *govcd.OpenApiOrgVdcNetwork) error {
+func createOrUpdateOpenApiNetworkMetadata(d *schema.ResourceData, vcdClient *VCDClient, network *govcd.OpenApiOrgVdcNetwork) error {
 	log.Printf("[TRACE] adding/updating metadata to Network V2")
 
 	// Metadata is not supported when the network is in a VDC Group
@@ -413,5 +419,5 @@ func createOrUpdateOpenApiNetworkMetadata(d *schema.ResourceData, network *govcd
 		return nil
 	}
 
-	return createOrUpdateMetadata(d, network, "metadata")
+	return createOrUpdateMetadata(d, vcdClient, network, "metadata")
 }
@@ -25,6 +25,7 @@ func resourceVcdNetworkRouted() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdNetworkRoutedImport,
 		},
+		Timeouts: taskTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -194,7 +195,9 @@ func resourceVcdNetworkRouted() *schema.Resource {
 func resourceVcdNetworkRoutedCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -286,7 +289,7 @@ func resourceVcdNetworkRoutedCreate(ctx context.Context, d *schema.ResourceData,
 			return diag.Errorf("error adding DHCP pool: %s", err)
 		}
 
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return diag.Errorf(errorCompletingTask, err)
 		}
@@ -294,7 +297,7 @@ func resourceVcdNetworkRoutedCreate(ctx context.Context, d *schema.ResourceData,
 
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, network, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding metadata to routed network: %s", err)
 	}
@@ -396,7 +399,7 @@ func genericVcdNetworkRoutedRead(_ context.Context, d *schema.ResourceData, meta
 	dSet(d, "description", network.OrgVDCNetwork.Description)
 	d.SetId(network.OrgVDCNetwork.ID)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_routed", network)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_routed", network, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set routed network metadata: %v", diags)
 		return diags
@@ -467,13 +470,15 @@ func getDhcpFromEdgeGateway(networkHref string, edgeGateway *govcd.EdgeGateway)
 
 func resourceVcdNetworkDeleteLocked(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	return resourceVcdNetworkDelete(ctx, d, meta)
 }
 
-func resourceVcdNetworkDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -490,7 +495,7 @@ func resourceVcdNetworkDelete(_ context.Context, d *schema.ResourceData, meta in
 	if err != nil {
 		return diag.Errorf("error deleting network: %s", err)
 	}
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -607,7 +612,9 @@ func resourceVcdNetworkRoutedImport(_ context.Context, d *schema.ResourceData, m
 
 func resourceVcdNetworkRoutedUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	networkName := d.Get("name").(string)
@@ -670,14 +677,14 @@ func resourceVcdNetworkRoutedUpdate(ctx context.Context, d *schema.ResourceData,
 				return diag.Errorf("error updating DHCP pool: %s", err)
 			}
 
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.Errorf(errorCompletingTask, err)
 			}
 		}
 	}
 
-	err = createOrUpdateMetadata(d, network, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, network, "metadata")
 	if err != nil {
 		return diag.Errorf("[routed network update] error updating network metadata: %s", err)
 	}
@@ -161,10 +161,14 @@ func resourceVcdNetworkRoutedV2Create(ctx context.Context, d *schema.ResourceDat
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -180,7 +184,7 @@ func resourceVcdNetworkRoutedV2Create(ctx context.Context, d *schema.ResourceDat
 
 	d.SetId(orgNetwork.OpenApiOrgVdcNetwork.ID)
 
-	err = createOrUpdateOpenApiNetworkMetadata(d, orgNetwork)
+	err = createOrUpdateOpenApiNetworkMetadata(d, vcdClient, orgNetwork)
 	if err != nil {
 		return diag.Errorf("[routed network create v2] error adding metadata to Routed network: %s", err)
 	}
@@ -202,10 +206,14 @@ func resourceVcdNetworkRoutedV2Update(ctx context.Context, d *schema.ResourceDat
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -232,7 +240,7 @@ func resourceVcdNetworkRoutedV2Update(ctx context.Context, d *schema.ResourceDat
 		return diag.Errorf("[routed network update v2] error updating Routed network: %s", err)
 	}
 
-	err = createOrUpdateOpenApiNetworkMetadata(d, orgNetwork)
+	err = createOrUpdateOpenApiNetworkMetadata(d, vcdClient, orgNetwork)
 	if err != nil {
 		return diag.Errorf("[routed network v2 update] error updating Routed network metadata: %s", err)
 	}
@@ -269,7 +277,7 @@ func resourceVcdNetworkRoutedV2Read(_ context.Context, d *schema.ResourceData, m
 	// Hence, we skip the read to preserve its value in state.
 	var diags diag.Diagnostics
 	if !govcd.OwnerIsVdcGroup(orgNetwork.OpenApiOrgVdcNetwork.OwnerRef.ID) {
-		diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_routed_v2", orgNetwork)...)
+		diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_network_routed_v2", orgNetwork, "resource")...)
 	} else if _, ok := d.GetOk("metadata"); !ok {
 		// If it's a VDC Group and metadata is not set, we explicitly compute it to empty. Otherwise, its value should
 		// be preserved as it is still present in the entity.
@@ -303,10 +311,14 @@ func resourceVcdNetworkRoutedV2Delete(_ context.Context, d *schema.ResourceData,
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -88,7 +88,9 @@ func resourceVcdAlbEdgeGatewayServiceEngineGroupCreate(ctx context.Context, d *s
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeAlbServiceEngineGroupAssignmentConfig := getAlbServiceEngineGroupAssignmentType(d)
@@ -107,7 +109,9 @@ func resourceVcdAlbEdgeGatewayServiceEngineGroupUpdate(ctx context.Context, d *s
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeAlbServiceEngineGroupAssignment, err := vcdClient.GetAlbServiceEngineGroupAssignmentById(d.Id())
@@ -149,7 +153,9 @@ func resourceVcdAlbEdgeGatewayServiceEngineGroupDelete(_ context.Context, d *sch
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	edgeAlbServiceEngineGroupAssignment, err := vcdClient.GetAlbServiceEngineGroupAssignmentById(d.Id())
@@ -184,6 +184,7 @@ func resourceVcdAlbPool() *schema.Resource {
 				Computed:    true,
 				Description: "Health message",
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("NSX-T ALB Pool"),
 		},
 	}
 }
@@ -276,7 +277,9 @@ var nsxtAlbPoolPersistenceProfile = &schema.Resource{
 
 func resourceVcdAlbPoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albPoolConfig, err := getNsxtAlbPoolType(d)
@@ -290,12 +293,19 @@ func resourceVcdAlbPoolCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	d.SetId(createdAlbPool.NsxtAlbPool.ID)
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_pool", d.Id(), createdAlbPool.NsxtAlbPool.Name))
+	if err != nil {
+		return diag.Errorf("could not create metadata for the NSX-T ALB Pool: %s", err)
+	}
+
 	return resourceVcdAlbPoolRead(ctx, d, meta)
 }
 
 func resourceVcdAlbPoolUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albPool, err := vcdClient.GetAlbPoolById(d.Id())
@@ -314,6 +324,11 @@ func resourceVcdAlbPoolUpdate(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(fmt.Errorf("error updating NSX-T ALB Pool: %s", err))
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_pool", d.Id(), updatePoolConfig.Name))
+	if err != nil {
+		return diag.Errorf("could not update metadata for the NSX-T ALB Pool: %s", err)
+	}
+
 	return resourceVcdAlbPoolRead(ctx, d, meta)
 }
 
@@ -334,12 +349,15 @@ func resourceVcdAlbPoolRead(_ context.Context, d *schema.ResourceData, meta inte
 		return diag.Errorf("error setting NSX-T ALB Pool data: %s", err)
 	}
 	d.SetId(albPool.NsxtAlbPool.ID)
-	return nil
+
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_alb_pool", newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_pool", d.Id(), albPool.NsxtAlbPool.Name))
 }
 
 func resourceVcdAlbPoolDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albPool, err := vcdClient.GetAlbPoolById(d.Id())
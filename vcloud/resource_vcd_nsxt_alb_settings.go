@@ -88,7 +88,9 @@ func resourceVcdAlbSettings() *schema.Resource {
 // endpoint only supports PUT and GET
 func resourceVcdAlbSettingsCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	orgName := d.Get("org").(string)
@@ -151,7 +153,9 @@ func vcdAlbSettingsRead(meta interface{}, d *schema.ResourceData, resourceType s
 
 func resourceVcdAlbSettingsDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	orgName := d.Get("org").(string)
@@ -103,6 +103,7 @@ func resourceVcdAlbVirtualService() *schema.Resource {
 				Computed:    true,
 				Description: "Preserves Client IP on a Virtual Service (VCD 10.4.1+)",
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("NSX-T ALB Virtual Service"),
 		},
 	}
 }
@@ -135,7 +136,9 @@ var nsxtAlbVirtualServicePort = &schema.Resource{
 
 func resourceVcdAlbVirtualServiceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albVirtualServiceConfig, err := getNsxtAlbVirtualServiceType(d, vcdClient)
@@ -149,12 +152,20 @@ func resourceVcdAlbVirtualServiceCreate(ctx context.Context, d *schema.ResourceD
 
 	d.SetId(createdAlbVirtualService.NsxtAlbVirtualService.ID)
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d,
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_virtual_service", d.Id(), createdAlbVirtualService.NsxtAlbVirtualService.Name))
+	if err != nil {
+		return diag.Errorf("could not create metadata for the NSX-T ALB Virtual Service: %s", err)
+	}
+
 	return resourceVcdAlbVirtualServiceRead(ctx, d, meta)
 }
 
 func resourceVcdAlbVirtualServiceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albVirtualService, err := vcdClient.GetAlbVirtualServiceById(d.Id())
@@ -173,6 +184,12 @@ func resourceVcdAlbVirtualServiceUpdate(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(fmt.Errorf("error updating NSX-T ALB Virtual Service: %s", err))
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d,
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_virtual_service", d.Id(), updateVirtualServiceConfig.Name))
+	if err != nil {
+		return diag.Errorf("could not update metadata for the NSX-T ALB Virtual Service: %s", err)
+	}
+
 	return resourceVcdAlbVirtualServiceRead(ctx, d, meta)
 }
 
@@ -193,12 +210,16 @@ func resourceVcdAlbVirtualServiceRead(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("error setting NSX-T ALB Virtual Service data: %s", err)
 	}
 	d.SetId(albVirtualService.NsxtAlbVirtualService.ID)
-	return nil
+
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_alb_virtual_service",
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_alb_virtual_service", d.Id(), albVirtualService.NsxtAlbVirtualService.Name))
 }
 
 func resourceVcdAlbVirtualServiceDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentEdgeGtw(d)
+	if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentEdgeGtw(d)
 
 	albPool, err := vcdClient.GetAlbVirtualServiceById(d.Id())
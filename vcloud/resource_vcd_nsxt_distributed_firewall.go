@@ -152,7 +152,9 @@ func resourceVcdNsxtDistributedFirewall() *schema.Resource {
 // for update.
 func resourceVcdNsxtDistributedFirewallCreateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVdcGroup(d)
+	if err := vcdClient.lockParentVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockParentVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -212,7 +214,9 @@ func resourceVcdNsxtDistributedFirewallRead(_ context.Context, d *schema.Resourc
 
 func resourceVcdNsxtDistributedFirewallDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVdcGroup(d)
+	if err := vcdClient.lockParentVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockParentVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -140,7 +140,9 @@ func resourceVcdNsxtDistributedFirewallRule() *schema.Resource {
 
 func resourceVcdNsxtDistributedFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVdcGroup(d)
+	if err := vcdClient.lockParentVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockParentVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -169,7 +171,9 @@ func resourceVcdNsxtDistributedFirewallRuleCreate(ctx context.Context, d *schema
 
 func resourceVcdNsxtDistributedFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVdcGroup(d)
+	if err := vcdClient.lockParentVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockParentVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -232,7 +236,9 @@ func resourceVcdNsxtDistributedFirewallRuleRead(_ context.Context, d *schema.Res
 
 func resourceVcdNsxtDistributedFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVdcGroup(d)
+	if err := vcdClient.lockParentVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockParentVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
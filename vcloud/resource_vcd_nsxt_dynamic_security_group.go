@@ -104,7 +104,9 @@ func resourceVcdDynamicSecurityGroupCreate(ctx context.Context, d *schema.Resour
 	vcdClient := meta.(*VCDClient)
 
 	vdcGroupId := d.Get("vdc_group_id").(string)
-	vcdClient.lockById(vdcGroupId)
+	if err := vcdClient.lockById(vdcGroupId); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockById(vdcGroupId)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -132,7 +134,9 @@ func resourceVcdDynamicSecurityGroupUpdate(ctx context.Context, d *schema.Resour
 	vcdClient := meta.(*VCDClient)
 
 	vdcGroupId := d.Get("vdc_group_id").(string)
-	vcdClient.lockById(vdcGroupId)
+	if err := vcdClient.lockById(vdcGroupId); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unlockById(vdcGroupId)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -112,6 +112,8 @@ func resourceVcdNsxtEdgeGateway() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdNsxtEdgeGatewayImport,
 		},
+		Timeouts:      taskTimeoutsNoUpdate(),
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -238,6 +240,8 @@ func resourceVcdNsxtEdgeGateway() *schema.Resource {
 				Computed:    true,
 				Description: "Total number of IPs allocated for this Gateway from NSX-T Segment backed External Network uplinks",
 			},
+			"metadata_entry":      openApiMetadataEntryResourceSchema("NSX-T Edge Gateway"),
+			"deletion_protection": deletionProtectionSchema("NSX-T Edge Gateway"),
 		},
 	}
 }
@@ -288,8 +292,19 @@ func resourceVcdNsxtEdgeGatewayCreate(ctx context.Context, d *schema.ResourceDat
 		return diag.Errorf("could not create NSX-T Edge Gateway type: %s", err)
 	}
 
-	createdEdgeGateway, err := adminOrg.CreateNsxtEdgeGateway(nsxtEdgeGatewayType)
+	createdEdgeGateway, err := callWithContext(ctx, func() (*govcd.NsxtEdgeGateway, error) {
+		return adminOrg.CreateNsxtEdgeGateway(nsxtEdgeGatewayType)
+	})
 	if err != nil {
+		// go-vcloud-director does not return the creation task. When the timeout expires, the Edge Gateway
+		// being created is looked up, so that it is kept in the state instead of being left behind
+		if ctx.Err() != nil && nsxtEdgeGatewayType.OwnerRef != nil {
+			partialEdgeGateway, lookupErr := adminOrg.GetNsxtEdgeGatewayByName(nsxtEdgeGatewayType.Name)
+			if lookupErr == nil && partialEdgeGateway.EdgeGateway.OwnerRef != nil &&
+				partialEdgeGateway.EdgeGateway.OwnerRef.ID == nsxtEdgeGatewayType.OwnerRef.ID {
+				d.SetId(partialEdgeGateway.EdgeGateway.ID)
+			}
+		}
 		return diag.Errorf("error creating NSX-T Edge Gateway: %s", err)
 	}
 
@@ -308,6 +323,16 @@ func resourceVcdNsxtEdgeGatewayCreate(ctx context.Context, d *schema.ResourceDat
 		}
 	}
 
+	metadataHandler := newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", d.Id(), createdEdgeGateway.EdgeGateway.Name)
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, metadataHandler)
+	if err != nil {
+		return diag.Errorf("could not create metadata for the NSX-T Edge Gateway: %s", err)
+	}
+	err = updateOpenApiDeletionProtectionMarker(d, vcdClient, metadataHandler)
+	if err != nil {
+		return diag.Errorf("could not protect the NSX-T Edge Gateway: %s", err)
+	}
+
 	return resourceVcdNsxtEdgeGatewayRead(ctx, d, meta)
 }
 
@@ -356,6 +381,16 @@ func resourceVcdNsxtEdgeGatewayUpdate(ctx context.Context, d *schema.ResourceDat
 		return diag.Errorf("error updating NSX-T Edge Gateway with ID '%s': %s", d.Id(), err)
 	}
 
+	metadataHandler := newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", d.Id(), edge.EdgeGateway.Name)
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, metadataHandler)
+	if err != nil {
+		return diag.Errorf("could not update metadata for the NSX-T Edge Gateway: %s", err)
+	}
+	err = updateOpenApiDeletionProtectionMarker(d, vcdClient, metadataHandler)
+	if err != nil {
+		return diag.Errorf("could not update the deletion protection of the NSX-T Edge Gateway: %s", err)
+	}
+
 	return resourceVcdNsxtEdgeGatewayRead(ctx, d, meta)
 }
 
@@ -382,10 +417,19 @@ func resourceVcdNsxtEdgeGatewayRead(_ context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.Errorf("error setting NSX-T Edge Gateway data: %s", err)
 	}
-	return nil
+
+	metadataHandler := newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_edgegateway", d.Id(), edge.EdgeGateway.Name)
+	err = readOpenApiDeletionProtection(d, metadataHandler)
+	if err != nil {
+		return diag.Errorf("error reading NSX-T Edge Gateway deletion protection: %s", err)
+	}
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_edgegateway", metadataHandler)
 }
 
-func resourceVcdNsxtEdgeGatewayDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdNsxtEdgeGatewayDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "NSX-T Edge Gateway"); diags != nil {
+		return diags
+	}
 	log.Printf("[TRACE] edge gateway deletion initiated")
 
 	vcdClient := meta.(*VCDClient)
@@ -399,7 +443,7 @@ func resourceVcdNsxtEdgeGatewayDelete(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("could not retrieve NSX-T Edge Gateway: %s", err)
 	}
 
-	err = edge.Delete()
+	err = runWithContext(ctx, edge.Delete)
 	if err != nil {
 		return diag.Errorf("error deleting NSX-T Edge Gateway: %s", err)
 	}
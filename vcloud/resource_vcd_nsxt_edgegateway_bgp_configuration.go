@@ -90,10 +90,14 @@ func resourceVcdEdgeBgpConfigCreateUpdate(ctx context.Context, d *schema.Resourc
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -166,10 +170,14 @@ func resourceVcdEdgeBgpConfigDelete(_ context.Context, d *schema.ResourceData, m
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -133,10 +133,14 @@ func resourceVcdEdgeBgpNeighborCreate(ctx context.Context, d *schema.ResourceDat
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -173,10 +177,14 @@ func resourceVcdEdgeBgpNeighborUpdate(ctx context.Context, d *schema.ResourceDat
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -246,10 +254,14 @@ func resourceVcdEdgeBgpNeighborDelete(_ context.Context, d *schema.ResourceData,
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
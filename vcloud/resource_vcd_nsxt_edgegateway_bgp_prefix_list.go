@@ -100,10 +100,14 @@ func resourceVcdEdgeBgpIpPrefixListCreate(ctx context.Context, d *schema.Resourc
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -141,10 +145,14 @@ func resourceVcdEdgeBgpIpPrefixListUpdate(ctx context.Context, d *schema.Resourc
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -214,10 +222,14 @@ func resourceVcdEdgeBgpIpPrefixListDelete(_ context.Context, d *schema.ResourceD
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
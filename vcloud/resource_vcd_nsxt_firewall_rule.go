@@ -0,0 +1,378 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func resourceVcdNsxtFirewallRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdNsxtFirewallRuleCreate,
+		ReadContext:   resourceVcdNsxtFirewallRuleRead,
+		UpdateContext: resourceVcdNsxtFirewallRuleUpdate,
+		DeleteContext: resourceVcdNsxtFirewallRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdNsxtFirewallRuleImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"edge_gateway_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Edge Gateway ID in which Firewall Rule is located",
+			},
+			"above_rule_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "An optional firewall rule ID, to put new rule above during creation",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Firewall Rule name",
+			},
+			"direction": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "IN_OUT",
+				Description:  "Direction on which Firewall Rule applies (One of 'IN', 'OUT', 'IN_OUT')",
+				ValidateFunc: validation.StringInSlice([]string{"IN", "OUT", "IN_OUT"}, false),
+			},
+			"ip_protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "IPV4_IPV6",
+				Description:  "Firewall Rule Protocol (One of 'IPV4', 'IPV6', 'IPV4_IPV6')",
+				ValidateFunc: validation.StringInSlice([]string{"IPV4", "IPV6", "IPV4_IPV6"}, false),
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Defines if the rule should 'ALLOW', 'DROP' or 'REJECT' matching traffic",
+				ValidateFunc: validation.StringInSlice([]string{"ALLOW", "DROP", "REJECT"}, false),
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Defined if Firewall Rule is active",
+			},
+			"logging": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Defines if matching traffic should be logged",
+			},
+			"source_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A set of Source Firewall Group IDs (IP Sets or Security Groups). Leaving it empty means 'Any'",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"destination_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A set of Destination Firewall Group IDs (IP Sets or Security Groups). Leaving it empty means 'Any'",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"app_port_profile_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A set of Application Port Profile IDs. Leaving it empty means 'Any'",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdNsxtFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule create] %s", err)
+	}
+	defer unlock()
+
+	nsxtEdge, err := vcdClient.GetNsxtEdgeGatewayById(d.Get("org").(string), d.Get("edge_gateway_id").(string))
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule create] error retrieving Edge Gateway: %s", err)
+	}
+
+	firewall, err := nsxtEdge.GetNsxtFirewall()
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule create] error retrieving NSX-T Firewall Rules: %s", err)
+	}
+
+	// The API only accepts the complete list of user defined rules, therefore the new rule is inserted
+	// in the existing list, which is then sent back as a whole
+	userDefinedRules, newRuleIndex, err := insertNsxtFirewallRule(firewall.NsxtFirewallRuleContainer.UserDefinedRules,
+		d.Get("above_rule_id").(string), getNsxtFirewallRuleType(d))
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule create] %s", err)
+	}
+
+	updatedFirewall, err := nsxtEdge.UpdateNsxtFirewall(&types.NsxtFirewallRuleContainer{UserDefinedRules: userDefinedRules})
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule create] error creating NSX-T Firewall Rule: %s", err)
+	}
+
+	// The order of rules is retained by the API, so the new rule is at the same position it was inserted at
+	updatedRules := updatedFirewall.NsxtFirewallRuleContainer.UserDefinedRules
+	if newRuleIndex >= len(updatedRules) || updatedRules[newRuleIndex].Name != d.Get("name").(string) {
+		return diag.Errorf("[NSX-T Firewall Rule create] could not find created NSX-T Firewall Rule '%s' at position %d",
+			d.Get("name").(string), newRuleIndex)
+	}
+
+	d.SetId(updatedRules[newRuleIndex].ID)
+
+	return resourceVcdNsxtFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceVcdNsxtFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule update] %s", err)
+	}
+	defer unlock()
+
+	nsxtEdge, err := vcdClient.GetNsxtEdgeGatewayById(d.Get("org").(string), d.Get("edge_gateway_id").(string))
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule update] error retrieving Edge Gateway: %s", err)
+	}
+
+	firewall, err := nsxtEdge.GetNsxtFirewall()
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule update] error retrieving NSX-T Firewall Rules: %s", err)
+	}
+
+	userDefinedRules := firewall.NsxtFirewallRuleContainer.UserDefinedRules
+	ruleIndex := findNsxtFirewallRuleIndex(userDefinedRules, d.Id())
+	if ruleIndex < 0 {
+		return diag.Errorf("[NSX-T Firewall Rule update] NSX-T Firewall Rule with ID '%s' not found", d.Id())
+	}
+
+	// Only the managed rule is replaced, keeping its position and version, while all other rules are sent unchanged
+	firewallRuleType := getNsxtFirewallRuleType(d)
+	firewallRuleType.ID = userDefinedRules[ruleIndex].ID
+	firewallRuleType.Version = userDefinedRules[ruleIndex].Version
+	userDefinedRules[ruleIndex] = firewallRuleType
+
+	_, err = nsxtEdge.UpdateNsxtFirewall(&types.NsxtFirewallRuleContainer{UserDefinedRules: userDefinedRules})
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule update] error updating NSX-T Firewall Rule: %s", err)
+	}
+
+	return resourceVcdNsxtFirewallRuleRead(ctx, d, meta)
+}
+
+func resourceVcdNsxtFirewallRuleRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	nsxtEdge, err := vcdClient.GetNsxtEdgeGatewayById(d.Get("org").(string), d.Get("edge_gateway_id").(string))
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[NSX-T Firewall Rule read] error retrieving NSX-T Edge Gateway: %s", err)
+	}
+
+	firewall, err := nsxtEdge.GetNsxtFirewall()
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule read] error retrieving NSX-T Firewall Rules: %s", err)
+	}
+
+	userDefinedRules := firewall.NsxtFirewallRuleContainer.UserDefinedRules
+	ruleIndex := findNsxtFirewallRuleIndex(userDefinedRules, d.Id())
+	if ruleIndex < 0 {
+		log.Printf("[DEBUG] NSX-T Firewall Rule with ID '%s' not found. Removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	err = setNsxtFirewallRuleData(userDefinedRules[ruleIndex], d)
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule read] error storing data to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVcdNsxtFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	unlock, err := vcdClient.lockParentVdcGroupOrEdgeGateway(d)
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule delete] %s", err)
+	}
+	defer unlock()
+
+	nsxtEdge, err := vcdClient.GetNsxtEdgeGatewayById(d.Get("org").(string), d.Get("edge_gateway_id").(string))
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule delete] error retrieving NSX-T Edge Gateway: %s", err)
+	}
+
+	firewall, err := nsxtEdge.GetNsxtFirewall()
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule delete] error retrieving NSX-T Firewall Rules: %s", err)
+	}
+
+	err = firewall.DeleteRuleById(d.Id())
+	if err != nil {
+		return diag.Errorf("[NSX-T Firewall Rule delete] error deleting NSX-T Firewall Rule: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVcdNsxtFirewallRuleImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	log.Printf("[TRACE] NSX-T Firewall Rule import initiated")
+
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 4 {
+		return nil, fmt.Errorf("resource name must be specified as org-name.vdc-or-vdc-group-name.nsxt-edge-gw-name.fw-rule-name")
+	}
+	orgName, vdcOrVdcGroupName, edgeName, fwRuleName := resourceURI[0], resourceURI[1], resourceURI[2], resourceURI[3]
+
+	vcdClient := meta.(*VCDClient)
+	vdcOrVdcGroup, err := lookupVdcOrVdcGroup(vcdClient, orgName, vdcOrVdcGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	edge, err := vdcOrVdcGroup.GetNsxtEdgeGatewayByName(edgeName)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve NSX-T edge gateway '%s': %s", edgeName, err)
+	}
+
+	firewall, err := edge.GetNsxtFirewall()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving NSX-T Firewall Rules: %s", err)
+	}
+
+	var foundRules []*types.NsxtFirewallRule
+	for _, rule := range firewall.NsxtFirewallRuleContainer.UserDefinedRules {
+		if rule.Name == fwRuleName {
+			foundRules = append(foundRules, rule)
+		}
+	}
+	if len(foundRules) == 0 {
+		return nil, fmt.Errorf("could not find NSX-T Firewall Rule by Name '%s': %s", fwRuleName, govcd.ErrorEntityNotFound)
+	}
+	if len(foundRules) > 1 {
+		return nil, fmt.Errorf("found %d NSX-T Firewall Rules with Name '%s'", len(foundRules), fwRuleName)
+	}
+
+	d.SetId(foundRules[0].ID)
+	dSet(d, "org", orgName)
+	dSet(d, "edge_gateway_id", edge.EdgeGateway.ID)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// insertNsxtFirewallRule returns a copy of rules with newRule inserted above the rule with ID
+// aboveRuleId, or appended at the end when aboveRuleId is empty. It also returns the position of newRule
+func insertNsxtFirewallRule(rules []*types.NsxtFirewallRule, aboveRuleId string, newRule *types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, int, error) {
+	newRuleIndex := len(rules)
+	if aboveRuleId != "" {
+		newRuleIndex = findNsxtFirewallRuleIndex(rules, aboveRuleId)
+		if newRuleIndex < 0 {
+			return nil, -1, fmt.Errorf("could not find NSX-T Firewall Rule with ID '%s' specified in 'above_rule_id'", aboveRuleId)
+		}
+	}
+
+	result := make([]*types.NsxtFirewallRule, 0, len(rules)+1)
+	result = append(result, rules[:newRuleIndex]...)
+	result = append(result, newRule)
+	result = append(result, rules[newRuleIndex:]...)
+
+	return result, newRuleIndex, nil
+}
+
+// findNsxtFirewallRuleIndex returns the position of the rule with the given ID, or -1 if it is not found
+func findNsxtFirewallRuleIndex(rules []*types.NsxtFirewallRule, id string) int {
+	for index, rule := range rules {
+		if rule.ID == id {
+			return index
+		}
+	}
+	return -1
+}
+
+func getNsxtFirewallRuleType(d *schema.ResourceData) *types.NsxtFirewallRule {
+	firewallRule := &types.NsxtFirewallRule{
+		Name:        d.Get("name").(string),
+		ActionValue: d.Get("action").(string),
+		Enabled:     d.Get("enabled").(bool),
+		IpProtocol:  d.Get("ip_protocol").(string),
+		Logging:     d.Get("logging").(bool),
+		Direction:   d.Get("direction").(string),
+		Version:     nil,
+	}
+
+	sourceGroupIds := convertSchemaSetToSliceOfStrings(d.Get("source_ids").(*schema.Set))
+	firewallRule.SourceFirewallGroups = convertSliceOfStringsToOpenApiReferenceIds(sourceGroupIds)
+
+	destinationGroupIds := convertSchemaSetToSliceOfStrings(d.Get("destination_ids").(*schema.Set))
+	firewallRule.DestinationFirewallGroups = convertSliceOfStringsToOpenApiReferenceIds(destinationGroupIds)
+
+	appPortProfileIds := convertSchemaSetToSliceOfStrings(d.Get("app_port_profile_ids").(*schema.Set))
+	firewallRule.ApplicationPortProfiles = convertSliceOfStringsToOpenApiReferenceIds(appPortProfileIds)
+
+	return firewallRule
+}
+
+func setNsxtFirewallRuleData(fwRule *types.NsxtFirewallRule, d *schema.ResourceData) error {
+	dSet(d, "name", fwRule.Name)
+	dSet(d, "action", fwRule.ActionValue)
+	dSet(d, "enabled", fwRule.Enabled)
+	dSet(d, "ip_protocol", fwRule.IpProtocol)
+	dSet(d, "direction", fwRule.Direction)
+	dSet(d, "logging", fwRule.Logging)
+
+	sourceSet := convertStringsToTypeSet(extractIdsFromOpenApiReferences(fwRule.SourceFirewallGroups))
+	err := d.Set("source_ids", sourceSet)
+	if err != nil {
+		return fmt.Errorf("error storing 'source_ids': %s", err)
+	}
+
+	destinationSet := convertStringsToTypeSet(extractIdsFromOpenApiReferences(fwRule.DestinationFirewallGroups))
+	err = d.Set("destination_ids", destinationSet)
+	if err != nil {
+		return fmt.Errorf("error storing 'destination_ids': %s", err)
+	}
+
+	appPortProfileSet := convertStringsToTypeSet(extractIdsFromOpenApiReferences(fwRule.ApplicationPortProfiles))
+	err = d.Set("app_port_profile_ids", appPortProfileSet)
+	if err != nil {
+		return fmt.Errorf("error storing 'app_port_profile_ids': %s", err)
+	}
+
+	return nil
+}
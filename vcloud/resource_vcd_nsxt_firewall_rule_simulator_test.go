@@ -0,0 +1,106 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestSimulatorNsxtFirewallRule checks the position of the rules created, updated and deleted on an edge gateway
+func TestSimulatorNsxtFirewallRule(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdNsxtFirewallRule()
+
+	edge, err := vcdClient.GetNsxtEdgeGateway(simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway)
+	if err != nil {
+		t.Fatalf("error retrieving edge gateway: %s", err)
+	}
+	ruleNames := func() []string {
+		firewall, err := edge.GetNsxtFirewall()
+		if err != nil {
+			t.Fatalf("error retrieving firewall rules: %s", err)
+		}
+		var names []string
+		for _, rule := range firewall.NsxtFirewallRuleContainer.UserDefinedRules {
+			names = append(names, rule.Name)
+		}
+		return names
+	}
+	createRule := func(name, aboveRuleId string) *schema.ResourceData {
+		d := simulatorResourceData(t, resource, map[string]interface{}{
+			"org":             simulatorOrg,
+			"edge_gateway_id": edge.EdgeGateway.ID,
+			"above_rule_id":   aboveRuleId,
+			"name":            name,
+			"action":          "ALLOW",
+		})
+		if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error creating firewall rule %s: %v", name, diags)
+		}
+		return d
+	}
+
+	// Rules are appended, unless a rule to put them above is given
+	first := createRule("first", "")
+	second := createRule("second", "")
+	top := createRule("top", first.Id())
+	if names := strings.Join(ruleNames(), ","); names != "top,first,second" {
+		t.Fatalf("unexpected rule order %s", names)
+	}
+	if top.Get("direction").(string) != "IN_OUT" || top.Get("ip_protocol").(string) != "IPV4_IPV6" || !top.Get("enabled").(bool) {
+		t.Fatalf("unexpected defaults in rule: %v", top.State().Attributes)
+	}
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":             simulatorOrg,
+		"edge_gateway_id": edge.EdgeGateway.ID,
+		"above_rule_id":   "missing-rule",
+		"name":            "orphan",
+		"action":          "ALLOW",
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); !diags.HasError() {
+		t.Fatal("expected an error when placing a rule above a rule that does not exist")
+	}
+
+	// An update only changes the managed rule, which keeps its position
+	second = simulatorUpdateData(t, resource, second, map[string]interface{}{
+		"org":             simulatorOrg,
+		"edge_gateway_id": edge.EdgeGateway.ID,
+		"name":            "second-renamed",
+		"action":          "DROP",
+		"logging":         true,
+	})
+	if diags := resource.UpdateContext(ctx, second, vcdClient); diags.HasError() {
+		t.Fatalf("error updating firewall rule: %v", diags)
+	}
+	if second.Get("action").(string) != "DROP" || !second.Get("logging").(bool) {
+		t.Fatalf("unexpected rule after update: %v", second.State().Attributes)
+	}
+	if names := strings.Join(ruleNames(), ","); names != "top,first,second-renamed" {
+		t.Fatalf("unexpected rule order after update %s", names)
+	}
+
+	imported := importSimulatorResource(t, resource, strings.Join([]string{simulatorOrg, simulatorNsxtVdc, simulatorEdgeGateway, "top"}, ImportSeparator), vcdClient)
+	if imported.Id() != top.Id() || imported.Get("edge_gateway_id").(string) != edge.EdgeGateway.ID {
+		t.Fatalf("expected imported rule %s, got %s", top.Id(), imported.Id())
+	}
+
+	// Deleting a rule leaves the other ones in place, and a deleted rule is removed from state when read
+	if diags := resource.DeleteContext(ctx, first, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting firewall rule: %v", diags)
+	}
+	if names := strings.Join(ruleNames(), ","); names != "top,second-renamed" {
+		t.Fatalf("unexpected rules after delete %s", names)
+	}
+	if diags := resource.ReadContext(ctx, first, vcdClient); diags.HasError() {
+		t.Fatalf("error reading deleted firewall rule: %v", diags)
+	}
+	if first.Id() != "" {
+		t.Fatalf("expected deleted rule to be removed from state, got ID %s", first.Id())
+	}
+}
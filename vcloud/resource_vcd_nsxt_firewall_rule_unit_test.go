@@ -0,0 +1,56 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func Test_insertNsxtFirewallRule(t *testing.T) {
+	rules := []*types.NsxtFirewallRule{{ID: "r1"}, {ID: "r2"}, {ID: "r3"}}
+	newRule := &types.NsxtFirewallRule{Name: "new"}
+
+	tests := []struct {
+		name        string
+		rules       []*types.NsxtFirewallRule
+		aboveRuleId string
+		wantIds     []string
+		wantIndex   int
+		wantErr     bool
+	}{
+		{name: "empty list", rules: nil, wantIds: []string{""}, wantIndex: 0},
+		{name: "append", rules: rules, wantIds: []string{"r1", "r2", "r3", ""}, wantIndex: 3},
+		{name: "above first", rules: rules, aboveRuleId: "r1", wantIds: []string{"", "r1", "r2", "r3"}, wantIndex: 0},
+		{name: "above middle", rules: rules, aboveRuleId: "r2", wantIds: []string{"r1", "", "r2", "r3"}, wantIndex: 1},
+		{name: "above last", rules: rules, aboveRuleId: "r3", wantIds: []string{"r1", "r2", "", "r3"}, wantIndex: 2},
+		{name: "unknown rule", rules: rules, aboveRuleId: "r4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotIndex, err := insertNsxtFirewallRule(tt.rules, tt.aboveRuleId, newRule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("insertNsxtFirewallRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var gotIds []string
+			for _, rule := range got {
+				gotIds = append(gotIds, rule.ID)
+			}
+			if !reflect.DeepEqual(gotIds, tt.wantIds) {
+				t.Errorf("insertNsxtFirewallRule() rules = %v, want %v", gotIds, tt.wantIds)
+			}
+			if gotIndex != tt.wantIndex || got[gotIndex] != newRule {
+				t.Errorf("insertNsxtFirewallRule() index = %d, want %d", gotIndex, tt.wantIndex)
+			}
+		})
+	}
+	// The original list must not be modified
+	if len(rules) != 3 || rules[0].ID != "r1" || rules[1].ID != "r2" || rules[2].ID != "r3" {
+		t.Errorf("insertNsxtFirewallRule() modified the original rules")
+	}
+}
@@ -79,11 +79,15 @@ func resourceVcdNsxtIpSetCreate(ctx context.Context, d *schema.ResourceData, met
 
 	var ipSet *types.NsxtFirewallGroup
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 		ipSet = getNsxtIpSetType(d, parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 		ipSet = getNsxtIpSetType(d, d.Get("edge_gateway_id").(string))
 	}
@@ -109,11 +113,15 @@ func resourceVcdNsxtIpSetUpdate(ctx context.Context, d *schema.ResourceData, met
 
 	var updateIpSet *types.NsxtFirewallGroup
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 		updateIpSet = getNsxtIpSetType(d, parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 		updateIpSet = getNsxtIpSetType(d, d.Get("edge_gateway_id").(string))
 	}
@@ -193,10 +201,14 @@ func resourceVcdNsxtIpSetDelete(_ context.Context, d *schema.ResourceData, meta
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
@@ -145,7 +145,9 @@ func resourceVcdNsxtNetworkImportedCreate(ctx context.Context, d *schema.Resourc
 		return diag.Errorf("[nsxt imported network create] only System Administrator can operate NSX-T Imported networks")
 	}
 
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -191,7 +193,9 @@ func resourceVcdNsxtNetworkImportedUpdate(ctx context.Context, d *schema.Resourc
 			"Please use `owner_id` field for moving network to/from VDC Group")
 	}
 
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
@@ -266,7 +270,9 @@ func resourceVcdNsxtNetworkImportedDelete(ctx context.Context, d *schema.Resourc
 		return diag.Errorf("[nsxt imported network delete] only System Administrator can operate NSX-T Imported networks")
 	}
 
-	vcdClient.lockIfOwnerIsVdcGroup(d)
+	if err := vcdClient.lockIfOwnerIsVdcGroup(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockIfOwnerIsVdcGroup(d)
 
 	org, err := vcdClient.GetOrgFromResource(d)
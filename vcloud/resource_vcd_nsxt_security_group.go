@@ -72,6 +72,7 @@ func resourceVcdSecurityGroup() *schema.Resource {
 				Description: "Set of VM IDs",
 				Elem:        nsxtFirewallGroupMemberVms,
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("NSX-T Security Group"),
 		},
 	}
 }
@@ -118,13 +119,17 @@ func resourceVcdSecurityGroupCreate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 		securityGroup = getNsxtSecurityGroupType(d, parentEdgeGatewayOwnerId)
 		vdcOrVdcGroup, err = org.GetVdcGroupById(parentEdgeGatewayOwnerId)
 		diag.Errorf("[nsxt security group create] error retrieving VDC Group with ID %s: %s", parentEdgeGatewayOwnerId, err)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 		securityGroup = getNsxtSecurityGroupType(d, d.Get("edge_gateway_id").(string))
 		vdcOrVdcGroup, err = org.GetVDCById(parentEdgeGatewayOwnerId, false)
@@ -140,6 +145,11 @@ func resourceVcdSecurityGroupCreate(ctx context.Context, d *schema.ResourceData,
 	dSet(d, "edge_gateway_id", nsxtEdgeGateway.EdgeGateway.ID)
 	d.SetId(createdFwGroup.NsxtFirewallGroup.ID)
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_security_group", d.Id(), securityGroup.Name))
+	if err != nil {
+		return diag.Errorf("[nsxt security group create] could not create metadata for NSX-T Security Group '%s': %s", securityGroup.Name, err)
+	}
+
 	return resourceVcdSecurityGroupRead(ctx, d, meta)
 }
 
@@ -160,13 +170,17 @@ func resourceVcdSecurityGroupUpdate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 		updateSecurityGroup = getNsxtSecurityGroupType(d, parentEdgeGatewayOwnerId)
 		vdcOrVdcGroup, err = org.GetVdcGroupById(parentEdgeGatewayOwnerId)
 		diag.Errorf("[nsxt security group update] error retrieving VDC Group with ID %s: %s", parentEdgeGatewayOwnerId, err)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 		updateSecurityGroup = getNsxtSecurityGroupType(d, d.Get("edge_gateway_id").(string))
 		vdcOrVdcGroup, err = org.GetVDCById(parentEdgeGatewayOwnerId, false)
@@ -187,6 +201,11 @@ func resourceVcdSecurityGroupUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("[nsxt security group update] error updating NSX-T Security Group '%s': %s", securityGroup.NsxtFirewallGroup.Name, err)
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_security_group", d.Id(), updateSecurityGroup.Name))
+	if err != nil {
+		return diag.Errorf("[nsxt security group update] could not update metadata for NSX-T Security Group '%s': %s", updateSecurityGroup.Name, err)
+	}
+
 	return resourceVcdSecurityGroupRead(ctx, d, meta)
 }
 
@@ -249,7 +268,8 @@ func resourceVcdSecurityGroupRead(_ context.Context, d *schema.ResourceData, met
 		return diag.Errorf("[nsxt security group resource read] error getting associated VMs for Security Group '%s': %s", securityGroup.NsxtFirewallGroup.Name, err)
 	}
 
-	return nil
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_nsxt_security_group",
+		newOpenApiEntityMetadata(vcdClient, "vcd_nsxt_security_group", d.Id(), securityGroup.NsxtFirewallGroup.Name))
 }
 
 func resourceVcdSecurityGroupDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -260,10 +280,14 @@ func resourceVcdSecurityGroupDelete(_ context.Context, d *schema.ResourceData, m
 	}
 
 	if govcd.OwnerIsVdcGroup(parentEdgeGatewayOwnerId) {
-		vcdClient.lockById(parentEdgeGatewayOwnerId)
+		if err := vcdClient.lockById(parentEdgeGatewayOwnerId); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unlockById(parentEdgeGatewayOwnerId)
 	} else {
-		vcdClient.lockParentEdgeGtw(d)
+		if err := vcdClient.lockParentEdgeGtw(d); err != nil {
+			return diag.FromErr(err)
+		}
 		defer vcdClient.unLockParentEdgeGtw(d)
 	}
 
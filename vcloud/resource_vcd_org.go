@@ -35,6 +35,8 @@ func resourceOrg() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdOrgImport,
 		},
+		Timeouts:      taskTimeouts(),
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -195,7 +197,8 @@ func resourceOrg() *schema.Resource {
 				Deprecated:    "Use metadata_entry instead",
 				ConflictsWith: []string{"metadata_entry"},
 			},
-			"metadata_entry": metadataEntryResourceSchemaDeprecated("Organization"),
+			"metadata_entry":      metadataEntryResourceSchemaDeprecated("Organization"),
+			"deletion_protection": deletionProtectionSchema("Organization"),
 		},
 	}
 }
@@ -231,7 +234,7 @@ func resourceOrgCreate(ctx context.Context, d *schema.ResourceData, m interface{
 		return diag.Errorf("[org creation] error creating Org %s: %s", orgName, err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		log.Printf("[DEBUG] Error running Org creation task: %s", err)
 		return diag.Errorf("[org creation] error running Org (%s) creation task: %s", orgName, err)
@@ -247,10 +250,14 @@ func resourceOrgCreate(ctx context.Context, d *schema.ResourceData, m interface{
 
 	d.SetId(org.AdminOrg.ID)
 
-	err = createOrUpdateMetadata(d, org, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, org, "metadata")
 	if err != nil {
 		return diag.Errorf("error adding metadata to Org: %s", err)
 	}
+	err = updateDeletionProtectionMarker(d, vcdClient, org)
+	if err != nil {
+		return diag.Errorf("error protecting Org: %s", err)
+	}
 
 	return resourceOrgRead(ctx, d, m)
 }
@@ -333,7 +340,10 @@ func getSettings(d *schema.ResourceData) *types.OrgSettings {
 }
 
 // Deletes org
-func resourceOrgDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceOrgDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "Org"); diags != nil {
+		return diags
+	}
 
 	//DELETING
 	vcdClient := m.(*VCDClient)
@@ -361,10 +371,22 @@ func resourceOrgDelete(_ context.Context, d *schema.ResourceData, m interface{})
 	}
 
 	log.Printf("[TRACE] Org %s found", orgName)
+	if deleteForce || deleteRecursive {
+		protectedChildren, err := findProtectedOrgChildren(vcdClient, adminOrg)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if diags := checkProtectedChildren(d, "Org", protectedChildren); diags != nil {
+			return diags
+		}
+	}
+
 	//deletes organization
 	log.Printf("[TRACE] Deleting Org %s", orgName)
 
-	err = adminOrg.Delete(deleteForce, deleteRecursive)
+	err = runWithContext(ctx, func() error {
+		return adminOrg.Delete(deleteForce, deleteRecursive)
+	})
 	if err != nil {
 		log.Printf("[DEBUG] Error deleting org %s: %s", orgName, err)
 		return diag.FromErr(err)
@@ -428,23 +450,27 @@ func resourceOrgUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 		log.Printf("[DEBUG] Error updating Org %s : %s", orgName, err)
 		return diag.Errorf("error updating Org %s", err)
 	}
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		log.Printf("[DEBUG] Error completing update of Org %s : %s", orgName, err)
 		return diag.Errorf("error completing update of Org %s", err)
 	}
 
-	err = createOrUpdateMetadata(d, adminOrg, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, adminOrg, "metadata")
 	if err != nil {
 		return diag.Errorf("error updating metadata from Org: %s", err)
 	}
+	err = updateDeletionProtectionMarker(d, vcdClient, adminOrg)
+	if err != nil {
+		return diag.Errorf("error updating the deletion protection of Org: %s", err)
+	}
 
 	log.Printf("[TRACE] Org %s updated", orgName)
 	return resourceOrgRead(ctx, d, m)
 }
 
 // setOrgData sets the data into the resource, taking it from the provided adminOrg
-func setOrgData(d *schema.ResourceData, vcdClient *VCDClient, adminOrg *govcd.AdminOrg) diag.Diagnostics {
+func setOrgData(d *schema.ResourceData, vcdClient *VCDClient, adminOrg *govcd.AdminOrg, origin string) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	dSet(d, "name", adminOrg.AdminOrg.Name)
@@ -545,7 +571,7 @@ func setOrgData(d *schema.ResourceData, vcdClient *VCDClient, adminOrg *govcd.Ad
 		}
 	}
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_org", adminOrg)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_org", adminOrg, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set Org metadata")
 		return diags
@@ -594,7 +620,7 @@ func resourceOrgRead(_ context.Context, d *schema.ResourceData, m interface{}) d
 	log.Printf("[TRACE] Org with id %s found", identifier)
 	d.SetId(adminOrg.AdminOrg.ID)
 
-	diags = append(diags, setOrgData(d, vcdClient, adminOrg)...)
+	diags = append(diags, setOrgData(d, vcdClient, adminOrg, "resource")...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -622,7 +648,7 @@ func resourceVcdOrgImport(ctx context.Context, d *schema.ResourceData, meta inte
 		return nil, fmt.Errorf(errorRetrievingOrg, err)
 	}
 
-	diags := setOrgData(d, vcdClient, adminOrg)
+	diags := setOrgData(d, vcdClient, adminOrg, "resource")
 	if diags != nil && diags.HasError() {
 		return []*schema.ResourceData{}, fmt.Errorf("error setting Org data: %v", diags)
 	}
@@ -0,0 +1,361 @@
+package vcloud
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// The email settings of an Org are read and replaced with the 'settings/email' link of the admin Org. The
+// go-vcloud-director SDK does not wrap these requests. VCD never returns the SMTP password.
+const (
+	orgEmailSettingsPath = "/settings/email"
+	orgEmailSettingsMime = "application/vnd.vmware.admin.organizationEmailSettings+xml"
+)
+
+// orgEmailSettings is the email configuration of an Org (OrgEmailSettingsType)
+type orgEmailSettings struct {
+	XMLName                 xml.Name               `xml:"OrgEmailSettings"`
+	Xmlns                   string                 `xml:"xmlns,attr"`
+	IsDefaultSmtpServer     bool                   `xml:"IsDefaultSmtpServer"`
+	IsDefaultOrgEmail       bool                   `xml:"IsDefaultOrgEmail"`
+	FromEmailAddress        string                 `xml:"FromEmailAddress"`
+	DefaultSubjectPrefix    string                 `xml:"DefaultSubjectPrefix"`
+	IsAlertEmailToAllAdmins bool                   `xml:"IsAlertEmailToAllAdmins"`
+	AlertEmailTo            string                 `xml:"AlertEmailTo,omitempty"` // comma separated addresses
+	SmtpServerSettings      *orgSmtpServerSettings `xml:"SmtpServerSettings,omitempty"`
+}
+
+// orgSmtpServerSettings is the SMTP server used by an Org that doesn't use the system one (SmtpServerSettingsType)
+type orgSmtpServerSettings struct {
+	IsUseAuthentication bool   `xml:"IsUseAuthentication"`
+	Host                string `xml:"Host"`
+	Port                int    `xml:"Port"`
+	Username            string `xml:"Username,omitempty"`
+	Password            string `xml:"Password,omitempty"`
+	SmtpSecureMode      string `xml:"SmtpSecureMode,omitempty"`
+}
+
+func resourceVcdOrgEmailSettings() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdOrgEmailSettingsCreate,
+		ReadContext:   resourceVcdOrgEmailSettingsRead,
+		UpdateContext: resourceVcdOrgEmailSettingsUpdate,
+		DeleteContext: resourceVcdOrgEmailSettingsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdOrgEmailSettingsImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"use_default_sender": { // IsDefaultOrgEmail
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If true, emails are sent with the sender address of the system. Otherwise, `sender_email` is used",
+			},
+			"sender_email": { // FromEmailAddress
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Sender address of the Org emails. Required when `use_default_sender` is false",
+			},
+			"subject_prefix": { // DefaultSubjectPrefix
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Text added at the start of the subject of the Org emails",
+			},
+			"alert_all_admins": { // IsAlertEmailToAllAdmins
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If true, alerts are sent to all the Org administrators. Otherwise, to `alert_recipients`",
+			},
+			"alert_recipients": { // AlertEmailTo
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Addresses that receive the alerts. Required when `alert_all_admins` is false",
+			},
+			"use_default_smtp_server": { // IsDefaultSmtpServer
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If true, the Org uses the SMTP server of the system. Otherwise, `smtp_server` is used",
+			},
+			"smtp_server": { // SmtpServerSettings
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "SMTP server of the Org. Required when `use_default_smtp_server` is false, and not allowed otherwise",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": { // Host
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Host name or IP address of the SMTP server",
+						},
+						"port": { // Port
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      25,
+							Description:  "Port of the SMTP server",
+							ValidateFunc: validation.IsPortNumber,
+						},
+						"security_mode": { // SmtpSecureMode
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "NONE",
+							Description:  "Security of the connection to the SMTP server (one of NONE, STARTTLS, SSL)",
+							ValidateFunc: validation.StringInSlice([]string{"NONE", "STARTTLS", "SSL"}, false),
+						},
+						"username": { // Username
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "User name to authenticate with the SMTP server. No authentication is used when empty",
+						},
+						"password": { // Password
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+							Description: "Password to authenticate with the SMTP server. This value is never returned by VCD, " +
+								"and it is sent on every update",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdOrgEmailSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceVcdOrgEmailSettingsCreateOrUpdate(ctx, d, meta, "create")
+}
+
+func resourceVcdOrgEmailSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceVcdOrgEmailSettingsCreateOrUpdate(ctx, d, meta, "update")
+}
+
+func resourceVcdOrgEmailSettingsCreateOrUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	settings, err := fillOrgEmailSettings(d)
+	if err != nil {
+		return diag.Errorf("[Org email settings %s] error collecting settings values: %s", origin, err)
+	}
+	err = updateOrgEmailSettings(vcdClient, adminOrg, settings)
+	if err != nil {
+		return diag.Errorf("[Org email settings %s] %s", origin, err)
+	}
+	d.SetId(adminOrg.AdminOrg.ID)
+	return resourceVcdOrgEmailSettingsRead(ctx, d, meta)
+}
+
+func resourceVcdOrgEmailSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdOrgEmailSettingsRead(ctx, d, meta, "resource")
+}
+
+func genericVcdOrgEmailSettingsRead(_ context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if govcd.ContainsNotFound(err) && origin == "resource" {
+		log.Printf("[INFO] unable to find Organization %s: %s. Removing email settings from state", vcdClient.getOrgName(d), err)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	settings, err := getOrgEmailSettings(vcdClient, adminOrg)
+	if err != nil {
+		return diag.Errorf("[Org email settings read %s] %s", origin, err)
+	}
+
+	dSet(d, "org", adminOrg.AdminOrg.Name)
+	dSet(d, "use_default_sender", settings.IsDefaultOrgEmail)
+	dSet(d, "sender_email", settings.FromEmailAddress)
+	dSet(d, "subject_prefix", settings.DefaultSubjectPrefix)
+	dSet(d, "alert_all_admins", settings.IsAlertEmailToAllAdmins)
+	dSet(d, "use_default_smtp_server", settings.IsDefaultSmtpServer)
+	err = d.Set("alert_recipients", convertStringsToTypeSet(splitOrgEmailAddresses(settings.AlertEmailTo)))
+	if err != nil {
+		return diag.Errorf("[Org email settings read %s] error setting 'alert_recipients': %s", origin, err)
+	}
+
+	// VCD keeps the SMTP server of an Org that went back to the system one. It is only shown when in use
+	var smtpServer []map[string]interface{}
+	if !settings.IsDefaultSmtpServer && settings.SmtpServerSettings != nil {
+		smtpServerSettings := settings.SmtpServerSettings
+		securityMode := smtpServerSettings.SmtpSecureMode
+		if securityMode == "" {
+			securityMode = "NONE"
+		}
+		block := map[string]interface{}{
+			"address":       smtpServerSettings.Host,
+			"port":          smtpServerSettings.Port,
+			"security_mode": securityMode,
+			"username":      smtpServerSettings.Username,
+		}
+		// The password is never returned by GET. The resource keeps the one of the configuration
+		if origin == "resource" {
+			block["password"] = d.Get("smtp_server.0.password").(string)
+		}
+		smtpServer = append(smtpServer, block)
+	}
+	err = d.Set("smtp_server", smtpServer)
+	if err != nil {
+		return diag.Errorf("[Org email settings read %s] error setting 'smtp_server': %s", origin, err)
+	}
+
+	d.SetId(adminOrg.AdminOrg.ID)
+	return nil
+}
+
+// resourceVcdOrgEmailSettingsDelete brings the Org back to the email settings of the system
+func resourceVcdOrgEmailSettingsDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	settings := &orgEmailSettings{
+		IsDefaultSmtpServer:     true,
+		IsDefaultOrgEmail:       true,
+		IsAlertEmailToAllAdmins: true,
+	}
+	// The SMTP server is kept, without credentials, as VCD requires one
+	current, err := getOrgEmailSettings(vcdClient, adminOrg)
+	if err != nil {
+		return diag.Errorf("[Org email settings delete] %s", err)
+	}
+	if current.SmtpServerSettings != nil {
+		settings.SmtpServerSettings = &orgSmtpServerSettings{
+			Host:           current.SmtpServerSettings.Host,
+			Port:           current.SmtpServerSettings.Port,
+			SmtpSecureMode: current.SmtpServerSettings.SmtpSecureMode,
+		}
+	}
+	err = updateOrgEmailSettings(vcdClient, adminOrg, settings)
+	if err != nil {
+		return diag.Errorf("[Org email settings delete] %s", err)
+	}
+	return nil
+}
+
+// fillOrgEmailSettings builds the email settings from the resource, checking that the custom values are set
+// where the defaults of the system are not used
+func fillOrgEmailSettings(d *schema.ResourceData) (*orgEmailSettings, error) {
+	settings := &orgEmailSettings{
+		IsDefaultOrgEmail:       d.Get("use_default_sender").(bool),
+		FromEmailAddress:        d.Get("sender_email").(string),
+		DefaultSubjectPrefix:    d.Get("subject_prefix").(string),
+		IsAlertEmailToAllAdmins: d.Get("alert_all_admins").(bool),
+		IsDefaultSmtpServer:     d.Get("use_default_smtp_server").(bool),
+	}
+	if !settings.IsDefaultOrgEmail && settings.FromEmailAddress == "" {
+		return nil, fmt.Errorf("'sender_email' is required when 'use_default_sender' is false")
+	}
+
+	recipients := convertSchemaSetToSliceOfStrings(d.Get("alert_recipients").(*schema.Set))
+	if !settings.IsAlertEmailToAllAdmins && len(recipients) == 0 {
+		return nil, fmt.Errorf("'alert_recipients' is required when 'alert_all_admins' is false")
+	}
+	sort.Strings(recipients)
+	settings.AlertEmailTo = strings.Join(recipients, ",")
+
+	smtpServer := d.Get("smtp_server").([]interface{})
+	if len(smtpServer) == 0 || smtpServer[0] == nil {
+		if !settings.IsDefaultSmtpServer {
+			return nil, fmt.Errorf("'smtp_server' is required when 'use_default_smtp_server' is false")
+		}
+		return settings, nil
+	}
+	// The SMTP server of an Org that uses the system one is not read back, and would always show as a change
+	if settings.IsDefaultSmtpServer {
+		return nil, fmt.Errorf("'smtp_server' can't be set when 'use_default_smtp_server' is true")
+	}
+	smtpServerMap := smtpServer[0].(map[string]interface{})
+	settings.SmtpServerSettings = &orgSmtpServerSettings{
+		Host:           smtpServerMap["address"].(string),
+		Port:           smtpServerMap["port"].(int),
+		SmtpSecureMode: smtpServerMap["security_mode"].(string),
+		Username:       smtpServerMap["username"].(string),
+		Password:       smtpServerMap["password"].(string),
+	}
+	settings.SmtpServerSettings.IsUseAuthentication = settings.SmtpServerSettings.Username != ""
+	return settings, nil
+}
+
+// splitOrgEmailAddresses returns the addresses of a comma separated list
+func splitOrgEmailAddresses(addresses string) []string {
+	var result []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+// getOrgEmailSettings retrieves the email settings of an Org
+func getOrgEmailSettings(vcdClient *VCDClient, adminOrg *govcd.AdminOrg) (*orgEmailSettings, error) {
+	settings := &orgEmailSettings{}
+	_, err := vcdClient.Client.ExecuteRequest(adminOrg.AdminOrg.HREF+orgEmailSettingsPath, http.MethodGet, orgEmailSettingsMime,
+		"error retrieving email settings: %s", nil, settings)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving email settings of Org '%s': %s", adminOrg.AdminOrg.Name, err)
+	}
+	return settings, nil
+}
+
+// updateOrgEmailSettings replaces the email settings of an Org
+func updateOrgEmailSettings(vcdClient *VCDClient, adminOrg *govcd.AdminOrg, settings *orgEmailSettings) error {
+	settings.Xmlns = types.XMLNamespaceVCloud
+	_, err := vcdClient.Client.ExecuteRequest(adminOrg.AdminOrg.HREF+orgEmailSettingsPath, http.MethodPut, orgEmailSettingsMime,
+		"error updating email settings: %s", settings, nil)
+	if err != nil {
+		return fmt.Errorf("error updating email settings of Org '%s': %s", adminOrg.AdminOrg.Name, err)
+	}
+	return nil
+}
+
+// resourceVcdOrgEmailSettingsImport is responsible for importing the resource.
+// The d.ID() field as being passed from `terraform import _resource_name_ _the_id_string_ requires
+// a name based dot-formatted path to the object to lookup the object and sets the id of object.
+// `terraform import` automatically performs `refresh` operation which loads up all other fields.
+// For this resource, the import path is just the org name.
+//
+// Example import path (id): orgName
+func resourceVcdOrgEmailSettingsImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	orgName := d.Id()
+
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgByName(orgName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrg, err)
+	}
+
+	dSet(d, "org", adminOrg.AdminOrg.Name)
+	d.SetId(adminOrg.AdminOrg.ID)
+	return []*schema.ResourceData{d}, nil
+}
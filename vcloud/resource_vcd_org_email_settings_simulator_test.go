@@ -0,0 +1,101 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestSimulatorOrgEmailSettings checks the lifecycle of the email settings of an org, whose SMTP password is never read back
+func TestSimulatorOrgEmailSettings(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdOrgEmailSettings()
+	values := map[string]interface{}{
+		"org":                     simulatorOrg,
+		"use_default_sender":      false,
+		"sender_email":            "noreply@example.com",
+		"subject_prefix":          "[cloud]",
+		"alert_all_admins":        false,
+		"alert_recipients":        []interface{}{"ops@example.com", "noc@example.com"},
+		"use_default_smtp_server": false,
+		"smtp_server": []interface{}{map[string]interface{}{
+			"address":       "smtp.example.com",
+			"port":          587,
+			"security_mode": "STARTTLS",
+			"username":      "mailer",
+			"password":      "secret",
+		}},
+	}
+
+	d := simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating email settings: %v", diags)
+	}
+	if password := sim.OrgSmtpPassword(simulatorOrg); password != "secret" {
+		t.Fatalf("expected the SMTP password to be sent, got %q", password)
+	}
+	if d.Get("smtp_server.0.password").(string) != "secret" || d.Get("smtp_server.0.port").(int) != 587 ||
+		d.Get("alert_recipients").(*schema.Set).Len() != 2 {
+		t.Errorf("unexpected state after create: %#v", d.State().Attributes)
+	}
+
+	// The data source and an import read everything but the password
+	datasource := datasourceVcdOrgEmailSettings()
+	dsData := simulatorResourceData(t, datasource, map[string]interface{}{"org": simulatorOrg})
+	if diags := datasource.ReadContext(ctx, dsData, vcdClient); diags.HasError() {
+		t.Fatalf("error reading email settings data source: %v", diags)
+	}
+	if dsData.Get("sender_email").(string) != "noreply@example.com" || dsData.Get("smtp_server.0.username").(string) != "mailer" {
+		t.Errorf("unexpected data source values: %#v", dsData.State().Attributes)
+	}
+	imported := importSimulatorResource(t, resource, simulatorOrg, vcdClient)
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading imported email settings: %v", diags)
+	}
+	if imported.Id() != d.Id() || imported.Get("smtp_server.0.address").(string) != "smtp.example.com" ||
+		imported.Get("smtp_server.0.password").(string) != "" {
+		t.Errorf("unexpected imported values: %#v", imported.State().Attributes)
+	}
+
+	values["use_default_sender"] = true
+	values["use_default_smtp_server"] = true
+	delete(values, "smtp_server")
+	d = simulatorUpdateData(t, resource, d, values)
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error switching to the system defaults: %v", diags)
+	}
+	if len(d.Get("smtp_server").([]interface{})) != 0 {
+		t.Errorf("expected no SMTP server with the system one, got %v", d.Get("smtp_server"))
+	}
+	// Custom values without their settings, and an SMTP server that is not used, are rejected before reaching VCD
+	withSmtpServer := simulatorUpdateData(t, resource, d, map[string]interface{}{
+		"org":                     simulatorOrg,
+		"use_default_smtp_server": true,
+		"smtp_server":             []interface{}{map[string]interface{}{"address": "smtp.example.com", "port": 25}},
+	})
+	diags := resource.UpdateContext(ctx, withSmtpServer, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "'smtp_server' can't be set") {
+		t.Fatalf("expected an error for an SMTP server with the system one, got %v", diags)
+	}
+	values["alert_recipients"] = []interface{}{}
+	d = simulatorUpdateData(t, resource, d, values)
+	diags = resource.UpdateContext(ctx, d, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "'alert_recipients' is required") {
+		t.Fatalf("expected an error without alert recipients, got %v", diags)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting email settings: %v", diags)
+	}
+	if diags := datasource.ReadContext(ctx, dsData, vcdClient); diags.HasError() {
+		t.Fatalf("error reading email settings data source: %v", diags)
+	}
+	if !dsData.Get("use_default_sender").(bool) || !dsData.Get("alert_all_admins").(bool) || dsData.Get("subject_prefix").(string) != "" {
+		t.Errorf("expected the system defaults after delete, got %#v", dsData.State().Attributes)
+	}
+}
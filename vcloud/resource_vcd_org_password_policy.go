@@ -0,0 +1,188 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// The password policy of an Org is read and replaced with the 'settings/passwordPolicy' link of the admin Org.
+// The go-vcloud-director SDK has the type, but does not wrap these requests.
+const (
+	orgPasswordPolicyPath = "/settings/passwordPolicy"
+	orgPasswordPolicyMime = "application/vnd.vmware.admin.organizationPasswordPolicySettings+xml"
+)
+
+// Values of the password policy of a new Org, which the resource restores on delete
+const (
+	defaultInvalidLoginsBeforeLockout    = 5
+	defaultAccountLockoutIntervalMinutes = 10
+)
+
+func resourceVcdOrgPasswordPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdOrgPasswordPolicyCreate,
+		ReadContext:   resourceVcdOrgPasswordPolicyRead,
+		UpdateContext: resourceVcdOrgPasswordPolicyUpdate,
+		DeleteContext: resourceVcdOrgPasswordPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdOrgPasswordPolicyImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"account_lockout_enabled": { // AccountLockoutEnabled
+				Type:        schema.TypeBool,
+				Required:    true,
+				Description: "If true, users are locked out after `invalid_logins_before_lockout` failed logins",
+			},
+			"invalid_logins_before_lockout": { // InvalidLoginsBeforeLockout
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultInvalidLoginsBeforeLockout,
+				Description:  "Number of invalid login attempts that trigger the lockout of an account",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"lockout_interval_minutes": { // AccountLockoutIntervalMinutes
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultAccountLockoutIntervalMinutes,
+				Description:  "Number of minutes a locked out account stays locked",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+func resourceVcdOrgPasswordPolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceVcdOrgPasswordPolicyCreateOrUpdate(ctx, d, meta, "create")
+}
+
+func resourceVcdOrgPasswordPolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceVcdOrgPasswordPolicyCreateOrUpdate(ctx, d, meta, "update")
+}
+
+func resourceVcdOrgPasswordPolicyCreateOrUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	policy := &types.OrgPasswordPolicySettings{
+		AccountLockoutEnabled:         d.Get("account_lockout_enabled").(bool),
+		InvalidLoginsBeforeLockout:    d.Get("invalid_logins_before_lockout").(int),
+		AccountLockoutIntervalMinutes: d.Get("lockout_interval_minutes").(int),
+	}
+	err = updateOrgPasswordPolicy(vcdClient, adminOrg, policy)
+	if err != nil {
+		return diag.Errorf("[Org password policy %s] %s", origin, err)
+	}
+	d.SetId(adminOrg.AdminOrg.ID)
+	return resourceVcdOrgPasswordPolicyRead(ctx, d, meta)
+}
+
+func resourceVcdOrgPasswordPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdOrgPasswordPolicyRead(ctx, d, meta, "resource")
+}
+
+func genericVcdOrgPasswordPolicyRead(_ context.Context, d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if govcd.ContainsNotFound(err) && origin == "resource" {
+		log.Printf("[INFO] unable to find Organization %s: %s. Removing password policy from state", vcdClient.getOrgName(d), err)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	policy, err := getOrgPasswordPolicy(vcdClient, adminOrg)
+	if err != nil {
+		return diag.Errorf("[Org password policy read %s] %s", origin, err)
+	}
+
+	dSet(d, "org", adminOrg.AdminOrg.Name)
+	dSet(d, "account_lockout_enabled", policy.AccountLockoutEnabled)
+	dSet(d, "invalid_logins_before_lockout", policy.InvalidLoginsBeforeLockout)
+	dSet(d, "lockout_interval_minutes", policy.AccountLockoutIntervalMinutes)
+	d.SetId(adminOrg.AdminOrg.ID)
+	return nil
+}
+
+// resourceVcdOrgPasswordPolicyDelete brings the Org back to the password policy of a new Org
+func resourceVcdOrgPasswordPolicyDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgFromResource(d)
+	if err != nil {
+		return diag.Errorf(errorRetrievingOrg, err)
+	}
+
+	policy := &types.OrgPasswordPolicySettings{
+		AccountLockoutEnabled:         false,
+		InvalidLoginsBeforeLockout:    defaultInvalidLoginsBeforeLockout,
+		AccountLockoutIntervalMinutes: defaultAccountLockoutIntervalMinutes,
+	}
+	err = updateOrgPasswordPolicy(vcdClient, adminOrg, policy)
+	if err != nil {
+		return diag.Errorf("[Org password policy delete] %s", err)
+	}
+	return nil
+}
+
+// getOrgPasswordPolicy retrieves the password policy of an Org
+func getOrgPasswordPolicy(vcdClient *VCDClient, adminOrg *govcd.AdminOrg) (*types.OrgPasswordPolicySettings, error) {
+	policy := &types.OrgPasswordPolicySettings{}
+	_, err := vcdClient.Client.ExecuteRequest(adminOrg.AdminOrg.HREF+orgPasswordPolicyPath, http.MethodGet, orgPasswordPolicyMime,
+		"error retrieving password policy: %s", nil, policy)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving password policy of Org '%s': %s", adminOrg.AdminOrg.Name, err)
+	}
+	return policy, nil
+}
+
+// updateOrgPasswordPolicy replaces the password policy of an Org
+func updateOrgPasswordPolicy(vcdClient *VCDClient, adminOrg *govcd.AdminOrg, policy *types.OrgPasswordPolicySettings) error {
+	// As for LDAP settings, Xmlns is only needed when the policy is updated on its own
+	policy.Xmlns = types.XMLNamespaceVCloud
+	_, err := vcdClient.Client.ExecuteRequest(adminOrg.AdminOrg.HREF+orgPasswordPolicyPath, http.MethodPut, orgPasswordPolicyMime,
+		"error updating password policy: %s", policy, nil)
+	if err != nil {
+		return fmt.Errorf("error updating password policy of Org '%s': %s", adminOrg.AdminOrg.Name, err)
+	}
+	return nil
+}
+
+// resourceVcdOrgPasswordPolicyImport is responsible for importing the resource.
+// The d.ID() field as being passed from `terraform import _resource_name_ _the_id_string_ requires
+// a name based dot-formatted path to the object to lookup the object and sets the id of object.
+// `terraform import` automatically performs `refresh` operation which loads up all other fields.
+// For this resource, the import path is just the org name.
+//
+// Example import path (id): orgName
+func resourceVcdOrgPasswordPolicyImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	orgName := d.Id()
+
+	vcdClient := meta.(*VCDClient)
+	adminOrg, err := vcdClient.GetAdminOrgByName(orgName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrg, err)
+	}
+
+	dSet(d, "org", adminOrg.AdminOrg.Name)
+	d.SetId(adminOrg.AdminOrg.ID)
+	return []*schema.ResourceData{d}, nil
+}
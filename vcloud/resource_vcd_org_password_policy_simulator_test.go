@@ -0,0 +1,60 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSimulatorOrgPasswordPolicy checks the lifecycle of the password policy of an org, which goes back to the defaults on delete
+func TestSimulatorOrgPasswordPolicy(t *testing.T) {
+	_, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdOrgPasswordPolicy()
+	values := map[string]interface{}{
+		"org":                           simulatorOrg,
+		"account_lockout_enabled":       true,
+		"invalid_logins_before_lockout": 3,
+		"lockout_interval_minutes":      30,
+	}
+
+	d := simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating password policy: %v", diags)
+	}
+
+	datasource := datasourceVcdOrgPasswordPolicy()
+	dsData := simulatorResourceData(t, datasource, map[string]interface{}{"org": simulatorOrg})
+	if diags := datasource.ReadContext(ctx, dsData, vcdClient); diags.HasError() {
+		t.Fatalf("error reading password policy data source: %v", diags)
+	}
+	if !dsData.Get("account_lockout_enabled").(bool) || dsData.Get("invalid_logins_before_lockout").(int) != 3 ||
+		dsData.Get("lockout_interval_minutes").(int) != 30 {
+		t.Errorf("unexpected data source values: %#v", dsData.State().Attributes)
+	}
+
+	values["invalid_logins_before_lockout"] = 6
+	d = simulatorUpdateData(t, resource, d, values)
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error updating password policy: %v", diags)
+	}
+	imported := importSimulatorResource(t, resource, simulatorOrg, vcdClient)
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading imported password policy: %v", diags)
+	}
+	if imported.Id() != d.Id() || imported.Get("invalid_logins_before_lockout").(int) != 6 ||
+		imported.Get("lockout_interval_minutes").(int) != 30 {
+		t.Errorf("unexpected imported values: %#v", imported.State().Attributes)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting password policy: %v", diags)
+	}
+	if diags := datasource.ReadContext(ctx, dsData, vcdClient); diags.HasError() {
+		t.Fatalf("error reading password policy data source: %v", diags)
+	}
+	if dsData.Get("account_lockout_enabled").(bool) || dsData.Get("invalid_logins_before_lockout").(int) != defaultInvalidLoginsBeforeLockout {
+		t.Errorf("expected the defaults after delete, got %#v", dsData.State().Attributes)
+	}
+}
@@ -58,6 +58,8 @@ func resourceVcdOrgVdc() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdOrgVdcImport,
 		},
+		Timeouts:      taskTimeoutsNoUpdate(),
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -238,7 +240,8 @@ func resourceVcdOrgVdc() *schema.Resource {
 				Deprecated:    "Use metadata_entry instead",
 				ConflictsWith: []string{"metadata_entry"},
 			},
-			"metadata_entry": metadataEntryResourceSchemaDeprecated("VDC"),
+			"metadata_entry":      metadataEntryResourceSchemaDeprecated("VDC"),
+			"deletion_protection": deletionProtectionSchema("VDC"),
 			"vm_sizing_policy_ids": {
 				Type:        schema.TypeSet,
 				Optional:    true,
@@ -334,12 +337,32 @@ func resourceVcdVdcCreate(ctx context.Context, d *schema.ResourceData, meta inte
 
 	log.Printf("[DEBUG] Creating VDC: %#v", params)
 
-	vdc, err := adminOrg.CreateOrgVdc(params)
+	task, err := adminOrg.CreateOrgVdcAsync(params)
 	if err != nil {
 		log.Printf("[DEBUG] Error creating VDC: %s", err)
 		return diag.Errorf("error creating VDC: %s", err)
 	}
 
+	// The VDC exists as soon as its creation starts. Its ID is set before waiting, so that a VDC still
+	// being created when the timeout expires is kept in the state instead of being left behind. It is
+	// removed when the task fails.
+	vdc, err := adminOrg.GetVDCByName(orgVdcName, true)
+	if err == nil {
+		d.SetId(vdc.Vdc.ID)
+	}
+	err = waitForTask(ctx, task)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.SetId("")
+		}
+		log.Printf("[DEBUG] Error creating VDC: %s", err)
+		return diag.Errorf("error creating VDC: %s", err)
+	}
+	vdc, err = adminOrg.GetVDCByName(orgVdcName, true)
+	if err != nil {
+		return diag.Errorf("error retrieving VDC %s after creation: %s", orgVdcName, err)
+	}
+
 	d.SetId(vdc.Vdc.ID)
 	log.Printf("[TRACE] VDC created: %#v", vdc)
 
@@ -399,7 +422,7 @@ func resourceVcdVdcRead(_ context.Context, d *schema.ResourceData, meta interfac
 		return diag.Errorf("unable to find VDC %s, err: %s", vdcName, err)
 	}
 
-	diags = append(diags, setOrgVdcData(d, vcdClient, adminVdc)...)
+	diags = append(diags, setOrgVdcData(d, vcdClient, adminVdc, "resource")...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -426,7 +449,7 @@ func resourceVcdVdcRead(_ context.Context, d *schema.ResourceData, meta interfac
 }
 
 // setOrgVdcData sets object state from *govcd.AdminVdc
-func setOrgVdcData(d *schema.ResourceData, vcdClient *VCDClient, adminVdc *govcd.AdminVdc) diag.Diagnostics {
+func setOrgVdcData(d *schema.ResourceData, vcdClient *VCDClient, adminVdc *govcd.AdminVdc, origin string) diag.Diagnostics {
 	var diags diag.Diagnostics
 	dSet(d, "allocation_model", adminVdc.AdminVdc.AllocationModel)
 	if adminVdc.AdminVdc.ResourceGuaranteedCpu != nil {
@@ -526,7 +549,7 @@ func setOrgVdcData(d *schema.ResourceData, vcdClient *VCDClient, adminVdc *govcd
 		return diag.FromErr(err)
 	}
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_org_vdc", adminVdc)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_org_vdc", adminVdc, origin)...)
 	if diags != nil && diags.HasError() {
 		log.Printf("[DEBUG] Unable to set VDC metadata")
 		return diags
@@ -858,7 +881,10 @@ func updateStorageProfiles(set *schema.Set, client *VCDClient, adminVdc *govcd.A
 }
 
 // Deletes a VDC, optionally removing all objects in it as well
-func resourceVcdVdcDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVdcDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "VDC"); diags != nil {
+		return diags
+	}
 	vdcName := d.Get("name").(string)
 	log.Printf("[TRACE] VDC delete started: %s", vdcName)
 
@@ -880,7 +906,21 @@ func resourceVcdVdcDelete(_ context.Context, d *schema.ResourceData, meta interf
 		return nil
 	}
 
-	err = vdc.DeleteWait(d.Get("delete_force").(bool), d.Get("delete_recursive").(bool))
+	deleteForce := d.Get("delete_force").(bool)
+	deleteRecursive := d.Get("delete_recursive").(bool)
+	if deleteForce || deleteRecursive {
+		protectedChildren, err := findProtectedVdcChildren(vcdClient, vdc.Vdc.ID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if diags := checkProtectedChildren(d, "VDC", protectedChildren); diags != nil {
+			return diags
+		}
+	}
+	task, err := vdc.Delete(deleteForce, deleteRecursive)
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
 	if err != nil {
 		log.Printf("[DEBUG] Error removing VDC %s, err: %s", vdcName, err)
 		return diag.Errorf("error removing VDC %s, err: %s", vdcName, err)
@@ -1096,7 +1136,11 @@ func createOrUpdateOrgMetadata(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf(errorRetrievingVdcFromOrg, d.Get("org").(string), d.Get("name").(string), err)
 	}
 
-	return createOrUpdateMetadata(d, adminVdc, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, adminVdc, "metadata")
+	if err != nil {
+		return err
+	}
+	return updateDeletionProtectionMarker(d, vcdClient, adminVdc)
 }
 
 // helper for transforming the compute capacity section of the resource input into the VdcConfiguration structure
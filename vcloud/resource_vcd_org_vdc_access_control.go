@@ -135,7 +135,7 @@ func resourceVcdVdcAccessControlRead(_ context.Context, d *schema.ResourceData,
 		return diag.Errorf("error while reading Org - %s", err)
 	}
 
-	vdc, err := org.GetVDCById(d.Id(), false)
+	vdc, err := vcdClient.GetVdcById(org, d.Id())
 	if err != nil {
 		if govcd.IsNotFound(err) {
 			d.SetId("")
@@ -181,7 +181,7 @@ func resourceVcdVdcAccessControlDelete(_ context.Context, d *schema.ResourceData
 		diag.Errorf("error when retrieving Org - %s", err)
 	}
 
-	vdc, err := org.GetVDCById(d.Id(), false)
+	vdc, err := vcdClient.GetVdcById(org, d.Id())
 	if err != nil {
 		diag.Errorf("error when retrieving VDC - %s", err)
 	}
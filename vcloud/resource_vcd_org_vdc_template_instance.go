@@ -86,7 +86,7 @@ func resourceVcdVdcTemplateInstantiateRead(_ context.Context, d *schema.Resource
 		return diag.Errorf("could not retrieve the Organization of the instantiated VDC: %s", err)
 	}
 
-	vdc, err := org.GetVDCById(d.Id(), false)
+	vdc, err := vcdClient.GetVdcById(org, d.Id())
 	if err != nil {
 		return diag.Errorf("could not retrieve the instantiated VDC: %s", err)
 	}
@@ -115,7 +115,7 @@ func resourceVcdVdcTemplateInstantiateDelete(_ context.Context, d *schema.Resour
 		return diag.Errorf("could not retrieve the Organization of the instantiated VDC: %s", err)
 	}
 
-	vdc, err := org.GetVDCById(d.Id(), false)
+	vdc, err := vcdClient.GetVdcById(org, d.Id())
 	if err != nil {
 		if govcd.ContainsNotFound(err) {
 			// The VDC is already gone
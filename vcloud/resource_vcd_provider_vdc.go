@@ -66,6 +66,7 @@ func resourceVcdProviderVdc() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceProviderVdcImport,
 		},
+		Timeouts: taskTimeoutsNoUpdate(),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -331,8 +332,18 @@ func resourceVcdProviderVdcCreate(ctx context.Context, d *schema.ResourceData, m
 		NetworkPool:           networkPoolReference,
 		AutoCreateNetworkPool: false,
 	}
-	providerVdc, err := vcdClient.CreateProviderVdc(&providerVdcCreation)
+	providerVdc, err := callWithContext(ctx, func() (*govcd.ProviderVdcExtended, error) {
+		return vcdClient.CreateProviderVdc(&providerVdcCreation)
+	})
 	if err != nil {
+		// go-vcloud-director does not return the creation tasks. When the timeout expires, the provider VDC
+		// being created is looked up, so that it is kept in the state instead of being left behind
+		if ctx.Err() != nil {
+			partialProviderVdc, lookupErr := vcdClient.GetProviderVdcExtendedByName(providerVdcCreation.Name)
+			if lookupErr == nil {
+				d.SetId(partialProviderVdc.VMWProviderVdc.ID)
+			}
+		}
 		return diag.FromErr(err)
 	}
 
@@ -340,7 +351,7 @@ func resourceVcdProviderVdcCreate(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.Errorf("could not create metadata for Provider VDC '%s': %s", providerVdc.VMWProviderVdc.ID, err)
 	}
-	err = createOrUpdateMetadataEntryInVcd(d, metadataCompatiblePvdc)
+	err = createOrUpdateMetadataEntryInVcd(d, vcdClient, metadataCompatiblePvdc)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -488,7 +499,7 @@ func genericResourceVcdProviderVdcRead(ctx context.Context, d *schema.ResourceDa
 		dSet(d, "vcenter_id", extendedProviderVdc.VMWProviderVdc.VimServer[0].ID)
 	}
 
-	diags = append(diags, updateMetadataInState(d, vcdClient, "vcd_provider_vdc", providerVdc)...)
+	diags = append(diags, updateMetadataInState(d, vcdClient, "vcd_provider_vdc", providerVdc, origin)...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -555,7 +566,7 @@ func resourceVcdProviderVdcUpdate(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.Errorf("could not create metadata for Provider VDC '%s': %s", pvdc.VMWProviderVdc.ID, err)
 	}
-	err = createOrUpdateMetadataEntryInVcd(d, metadataCompatiblePvdc)
+	err = createOrUpdateMetadataEntryInVcd(d, vcdClient, metadataCompatiblePvdc)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -582,7 +593,7 @@ func resourceVcdProviderVdcDelete(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
 	"github.com/vmware/go-vcloud-director/v3/types/v56"
 	"github.com/vmware/go-vcloud-director/v3/util"
@@ -21,6 +23,7 @@ func resourceVcdRde() *schema.Resource {
 		ReadContext:   resourceVcdRdeRead,
 		UpdateContext: resourceVcdRdeUpdate,
 		DeleteContext: resourceVcdRdeDelete,
+		CustomizeDiff: resourceVcdRdeCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdRdeImport,
 		},
@@ -62,6 +65,31 @@ func resourceVcdRde() *schema.Resource {
 				DiffSuppressFunc:      hasJsonValueChanged,
 				DiffSuppressOnRefresh: true,
 			},
+			"validate_input_entity": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "If true, `input_entity` or the contents of `input_entity_url` are validated against the JSON schema " +
+					"of the RDE Type during plan",
+			},
+			"update_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "replace",
+				ValidateFunc: validation.StringInSlice([]string{"replace", "merge"}, false),
+				Description: "How updates are applied. 'replace' overrides the whole entity with the input. 'merge' applies the input " +
+					"as a JSON merge patch to the entity in VCD, so that properties written by behaviors and controllers are kept",
+			},
+			"ignored_paths": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateJsonPath,
+				},
+				Description: "Paths of the entity owned by other actors, like '/status'. Their values in VCD are never changed " +
+					"by updates and they are not compared to compute 'entity_in_sync'",
+			},
 			"computed_entity": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -98,9 +126,10 @@ func resourceVcdRde() *schema.Resource {
 				Computed: true,
 			},
 			"entity_in_sync": {
-				Type:        schema.TypeBool,
-				Description: "If true, `computed_entity` is equal to either `input_entity` or the contents of `input_entity_url`",
-				Computed:    true,
+				Type: schema.TypeBool,
+				Description: "If true, `computed_entity` is equal to either `input_entity` or the contents of `input_entity_url`. " +
+					"With 'merge' update mode, only the properties present in the input are compared. Ignored paths are never compared",
+				Computed: true,
 			},
 			"metadata_entry": openApiMetadataEntryResourceSchema("Runtime Defined Entity"),
 		},
@@ -150,7 +179,7 @@ func resourceVcdRdeCreate(ctx context.Context, d *schema.ResourceData, meta inte
 		}
 	}
 
-	err = createOrUpdateOpenApiMetadataEntryInVcd(d, rde)
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, sdkOpenApiMetadata{rde})
 	if err != nil {
 		return diag.Errorf("could not create metadata for the Runtime Defined Entity: %s", err)
 	}
@@ -158,6 +187,77 @@ func resourceVcdRdeCreate(ctx context.Context, d *schema.ResourceData, meta inte
 	return resourceVcdRdeRead(ctx, d, meta)
 }
 
+// resourceVcdRdeCustomizeDiff rejects 'update_mode' "merge" combined with 'input_entity_url', and validates the
+// input entity against the JSON schema of the RDE Type during plan, so that an invalid entity is not sent to VCD
+func resourceVcdRdeCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	// The contents of the URL are not kept in state, so a 'merge' update could not remove the properties
+	// that were dropped from them since the previous apply
+	if diff.Get("update_mode").(string) == "merge" && (diff.Get("input_entity_url").(string) != "" || !diff.NewValueKnown("input_entity_url")) {
+		return fmt.Errorf("'update_mode' 'merge' can only be used with 'input_entity'. To merge the contents of a URL, " +
+			"read them in 'input_entity', for example with the 'http' data source")
+	}
+
+	if !diff.Get("validate_input_entity").(bool) {
+		return nil
+	}
+	if diff.Id() != "" && !diff.HasChanges("rde_type_id", "input_entity", "input_entity_url") {
+		return nil
+	}
+	for _, field := range []string{"rde_type_id", "input_entity", "input_entity_url"} {
+		// Values that depend on other resources can only be validated during apply
+		if !diff.NewValueKnown(field) {
+			return nil
+		}
+	}
+
+	vcdClient := meta.(*VCDClient)
+	rdeTypeId := diff.Get("rde_type_id").(string)
+	rdeType, err := vcdClient.GetRdeTypeById(rdeTypeId)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			// Creating or updating the RDE will fail with a clearer error
+			log.Printf("[DEBUG] RDE Type '%s' not found, skipping the validation of the input entity", rdeTypeId)
+			return nil
+		}
+		return fmt.Errorf("could not retrieve RDE Type with ID '%s' to validate the input entity: %s", rdeTypeId, err)
+	}
+
+	field := "input_entity"
+	jsonEntity := diff.Get("input_entity").(string)
+	if url := diff.Get("input_entity_url").(string); url != "" {
+		field = "input_entity_url"
+		jsonEntity, err = fileFromUrlToString(vcdClient, url, ".json")
+		if err != nil {
+			return fmt.Errorf("could not download JSON RDE from url %s: %s", url, err)
+		}
+	}
+
+	return validateRdeEntity(rdeTypeId, rdeType.DefinedEntityType.Schema, field, jsonEntity)
+}
+
+// validateRdeEntity validates a JSON entity, taken from the given field, against the JSON schema of an RDE Type.
+// The returned error lists all the offending values with their JSON path
+func validateRdeEntity(rdeTypeId string, typeSchema map[string]interface{}, field, jsonEntity string) error {
+	var entity interface{}
+	err := json.Unmarshal([]byte(jsonEntity), &entity)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid JSON: %s", field, err)
+	}
+	if len(typeSchema) == 0 {
+		return nil
+	}
+
+	validationErrors := validateJsonSchema(typeSchema, entity)
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	messages := make([]string, len(validationErrors))
+	for i, validationError := range validationErrors {
+		messages[i] = "  - " + validationError.Error()
+	}
+	return fmt.Errorf("'%s' does not match the schema of RDE Type '%s':\n%s", field, rdeTypeId, strings.Join(messages, "\n"))
+}
+
 // getRdeJson gets the RDE as JSON from the Terraform configuration
 func getRdeJson(vcdClient *VCDClient, d *schema.ResourceData) (map[string]interface{}, error) {
 	var jsonRde string
@@ -180,6 +280,57 @@ func getRdeJson(vcdClient *VCDClient, d *schema.ResourceData) (map[string]interf
 	return unmarshalledJson, err
 }
 
+// getRdeIgnoredPaths returns the parsed paths of the 'ignored_paths' argument
+func getRdeIgnoredPaths(d *schema.ResourceData) ([][]string, error) {
+	var ignoredPaths [][]string
+	for _, path := range d.Get("ignored_paths").(*schema.Set).List() {
+		segments, err := parseJsonPath(path.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'ignored_paths': %s", err)
+		}
+		ignoredPaths = append(ignoredPaths, segments)
+	}
+	return ignoredPaths, nil
+}
+
+// buildRdeUpdatedEntity returns the entity to send to VCD on updates. In 'replace' mode, this is the input.
+// In 'merge' mode, the input is applied as a JSON merge patch to the current entity, also removing the properties
+// that were in the previous input and are not in the new one. In both modes, the values of the ignored paths
+// are taken from the current entity
+func buildRdeUpdatedEntity(updateMode string, currentEntity, previousInput, input map[string]interface{}, ignoredPaths [][]string) map[string]interface{} {
+	var result map[string]interface{}
+	if updateMode == "merge" {
+		patch := jsonMergePatchWithRemovals(previousInput, input)
+		for _, path := range ignoredPaths {
+			removeJsonPath(patch, path)
+		}
+		result, _ = applyJsonMergePatch(copyJsonValue(currentEntity), patch).(map[string]interface{})
+		return result
+	}
+
+	result, _ = copyJsonValue(input).(map[string]interface{})
+	for _, path := range ignoredPaths {
+		removeJsonPath(result, path)
+		copyJsonPath(currentEntity, result, path)
+	}
+	return result
+}
+
+// isRdeEntityInSync returns true if the entity retrieved from VCD matches the input, not considering the ignored paths.
+// In 'merge' mode, only the properties present in the input are compared
+func isRdeEntityInSync(updateMode string, computedEntity, input map[string]interface{}, ignoredPaths [][]string) bool {
+	computed := copyJsonValue(computedEntity)
+	owned := copyJsonValue(input)
+	for _, path := range ignoredPaths {
+		removeJsonPath(computed, path)
+		removeJsonPath(owned, path)
+	}
+	if updateMode == "merge" {
+		return isJsonSubset(owned, computed)
+	}
+	return reflect.DeepEqual(owned, computed)
+}
+
 func resourceVcdRdeRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 	rde, err := getRde(d, vcdClient, "resource")
@@ -223,18 +374,14 @@ func resourceVcdRdeRead(_ context.Context, d *schema.ResourceData, meta interfac
 		if err != nil {
 			return diag.Errorf("error getting JSON from RDE '%s' configuration: %s", rde.DefinedEntity.ID, err)
 		}
-		inputJsonMarshaled, err := json.Marshal(inputJson)
-		if err != nil {
-			return diag.Errorf("error marshaling JSON retrieved from RDE '%s' configuration: %s", rde.DefinedEntity.ID, err)
-		}
-		areJsonEqual, err := areMarshaledJsonEqual([]byte(jsonEntity), inputJsonMarshaled)
+		ignoredPaths, err := getRdeIgnoredPaths(d)
 		if err != nil {
-			return diag.Errorf("error comparing %s with %s of RDE '%s': %s", jsonEntity, inputJsonMarshaled, rde.DefinedEntity.ID, err)
+			return diag.FromErr(err)
 		}
-		dSet(d, "entity_in_sync", areJsonEqual)
+		dSet(d, "entity_in_sync", isRdeEntityInSync(d.Get("update_mode").(string), rde.DefinedEntity.Entity, inputJson, ignoredPaths))
 	}
 
-	diags := updateOpenApiMetadataInState(d, vcdClient, "vcd_rde", rde)
+	diags := updateOpenApiMetadataInState(d, vcdClient, "vcd_rde", sdkOpenApiMetadata{rde})
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -320,12 +467,26 @@ func resourceVcdRdeUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	ignoredPaths, err := getRdeIgnoredPaths(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The previous input is used to remove the properties that are no longer in the input in 'merge' mode,
+	// which only accepts 'input_entity'
+	var previousJsonEntity map[string]interface{}
+	if previousInput, _ := d.GetChange("input_entity"); previousInput.(string) != "" {
+		err = json.Unmarshal([]byte(previousInput.(string)), &previousJsonEntity)
+		if err != nil {
+			return diag.Errorf("could not read the previous 'input_entity' of the Runtime Defined Entity '%s': %s", rde.DefinedEntity.ID, err)
+		}
+	}
 
 	err = rde.Update(types.DefinedEntity{
 		Name:       d.Get("name").(string),
 		EntityType: d.Get("rde_type_id").(string),
 		ExternalId: d.Get("external_id").(string),
-		Entity:     jsonEntity,
+		Entity:     buildRdeUpdatedEntity(d.Get("update_mode").(string), rde.DefinedEntity.Entity, previousJsonEntity, jsonEntity, ignoredPaths),
 	})
 	if err != nil {
 		return diag.Errorf("could not update the Runtime Defined Entity '%s' with ID '%s': %s", rde.DefinedEntity.Name, rde.DefinedEntity.ID, err)
@@ -338,7 +499,7 @@ func resourceVcdRdeUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		}
 	}
 
-	err = createOrUpdateOpenApiMetadataEntryInVcd(d, rde)
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, sdkOpenApiMetadata{rde})
 	if err != nil {
 		return diag.Errorf("could not create metadata for the Runtime Defined Entity: %s", err)
 	}
@@ -323,6 +323,9 @@ resource "vcd_rde" "rde_naughty" {
   input_entity       = "{ \"this_json_is_bad\": \"yes\"}"
   resolve_on_removal = false
 
+  # The bad JSON must reach VCD to test the resolution errors
+  validate_input_entity = false
+
   depends_on = [vcd_rights_bundle.rde_type_bundle]
 }
 
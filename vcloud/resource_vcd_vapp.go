@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
 )
 
 const vAppUnknownStatus = "-unknown-status-"
@@ -25,6 +28,8 @@ func resourceVcdVApp() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappImport,
 		},
+		Timeouts:      taskTimeouts(),
+		CustomizeDiff: deletionProtectionCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -59,7 +64,8 @@ func resourceVcdVApp() *schema.Resource {
 				Deprecated:    "Use metadata_entry instead",
 				ConflictsWith: []string{"metadata_entry"},
 			},
-			"metadata_entry": metadataEntryResourceSchemaDeprecated("vApp"),
+			"metadata_entry":      metadataEntryResourceSchemaDeprecated("vApp"),
+			"deletion_protection": deletionProtectionSchema("vApp"),
 			"href": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -71,6 +77,8 @@ func resourceVcdVApp() *schema.Resource {
 				Default:     false,
 				Description: "A boolean value stating if this vApp should be powered on",
 			},
+			"power_off_mode":         powerOffModeSchema("vApp"),
+			"guest_shutdown_timeout": guestShutdownTimeoutSchema("vApp"),
 			"guest_properties": {
 				Type:        schema.TypeMap,
 				Optional:    true,
@@ -145,10 +153,25 @@ func resourceVcdVAppCreate(ctx context.Context, d *schema.ResourceData, meta int
 
 	vappName := d.Get("name").(string)
 	vappDescription := d.Get("description").(string)
-	vcdClient.lockVapp(d)
+	if err := vcdClient.lockVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockVapp(d)
 
-	vapp, err := vdc.CreateRawVApp(vappName, vappDescription)
+	compose := &types.ComposeVAppParams{
+		Ovf:         types.XMLNamespaceOVF,
+		Xsi:         types.XMLNamespaceXSI,
+		Xmlns:       types.XMLNamespaceVCloud,
+		Deploy:      false,
+		Name:        vappName,
+		PowerOn:     false,
+		Description: vappDescription,
+	}
+	vapp, err := startVappCreation(vcdClient, vdc, "composeVApp", types.MimeComposeVappParams, compose)
+	if err != nil {
+		return diag.Errorf("error creating vApp %s: %s", vappName, err)
+	}
+	err = waitForVappCreation(ctx, d, vcdClient, vapp)
 	if err != nil {
 		return diag.Errorf("error creating vApp %s: %s", vappName, err)
 	}
@@ -179,6 +202,34 @@ func resourceVcdVAppCreate(ctx context.Context, d *schema.ResourceData, meta int
 	return resourceVcdVAppUpdate(ctx, d, meta)
 }
 
+// startVappCreation sends a vApp creation request (composeVApp, instantiateVAppTemplate or cloneVApp) to a
+// VDC, and returns the new vApp with the tasks creating it. Unlike the go-vcloud-director calls sending the
+// same requests, it does not wait for the tasks.
+func startVappCreation(vcdClient *VCDClient, vdc *govcd.Vdc, action, mimeType string, params interface{}) (*govcd.VApp, error) {
+	vapp := govcd.NewVApp(&vcdClient.Client)
+	_, err := vcdClient.Client.ExecuteRequest(vdc.Vdc.HREF+"/action/"+action, http.MethodPost, mimeType,
+		"error sending vApp creation request: %s", params, vapp.VApp)
+	if err != nil {
+		return nil, err
+	}
+	return vapp, nil
+}
+
+// waitForVappCreation waits for the tasks of a vApp returned by startVappCreation. The ID of the vApp is
+// set before waiting, so that a vApp still being created when the timeout expires is kept in the state
+// instead of being left behind. It is removed when a task fails, as VCD does not keep the vApp then.
+func waitForVappCreation(ctx context.Context, d *schema.ResourceData, vcdClient *VCDClient, vapp *govcd.VApp) error {
+	d.SetId(vapp.VApp.ID)
+	err := waitForTasks(ctx, &vcdClient.Client, vapp.VApp.Tasks)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.SetId("")
+		}
+		return err
+	}
+	return vapp.Refresh()
+}
+
 func resourceVcdVAppUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 
@@ -241,7 +292,11 @@ func resourceVcdVAppUpdate(ctx context.Context, d *schema.ResourceData, meta int
 		}
 	}
 
-	err = createOrUpdateMetadata(d, vapp, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, vapp, "metadata")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = updateDeletionProtectionMarker(d, vcdClient, vapp)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -254,21 +309,19 @@ func resourceVcdVAppUpdate(ctx context.Context, d *schema.ResourceData, meta int
 			if err != nil {
 				return diag.Errorf("error Powering On: %s", err)
 			}
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.Errorf("error completing tasks: %s", err)
 			}
 		}
 
 		if shouldBePoweredOff {
-			task, err := vapp.Undeploy() // UI Button "Power Off" calls undeploy API endpoint
+			// UI Button "Power Off" calls undeploy API endpoint
+			powerOffMode, shutdownTimeout := getPowerOffMode(d)
+			err = undeployVapp(ctx, vcdClient, vapp, powerOffMode, shutdownTimeout)
 			if err != nil {
 				return diag.Errorf("error Powering Off: %s", err)
 			}
-			err = task.WaitTaskCompletion()
-			if err != nil {
-				return diag.Errorf("error completing tasks: %s", err)
-			}
 		}
 	}
 
@@ -381,7 +434,7 @@ func genericVcdVAppRead(d *schema.ResourceData, meta interface{}, origin string)
 	dSet(d, "description", vapp.VApp.Description)
 	d.SetId(vapp.VApp.ID)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_vapp", vapp)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_vapp", vapp, origin)...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -393,10 +446,15 @@ func genericVcdVAppRead(d *schema.ResourceData, meta interface{}, origin string)
 	return nil
 }
 
-func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := checkDeletionProtection(d, "vApp"); diags != nil {
+		return diags
+	}
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockVapp(d)
+	if err := vcdClient.lockVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -414,12 +472,13 @@ func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta inter
 	if err != nil {
 		return diag.Errorf("error with networking change: %#v", err)
 	}
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.Errorf("error changing network: %#v", err)
 	}
 
-	err = tryUndeploy(*vapp)
+	powerOffMode, shutdownTimeout := getPowerOffMode(d)
+	err = tryUndeploy(ctx, vcdClient, vapp, powerOffMode, shutdownTimeout)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -429,7 +488,7 @@ func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta inter
 		return diag.Errorf("error deleting: %#v", err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = waitForTask(ctx, task)
 	if err != nil {
 		return diag.Errorf("error with deleting vApp task: %#v", err)
 	}
@@ -437,12 +496,12 @@ func resourceVcdVAppDelete(_ context.Context, d *schema.ResourceData, meta inter
 	return nil
 }
 
-// Try to undeploy a vApp, but do not throw an error if the vApp is powered off.
-// Very often the vApp is powered off at this point and Undeploy() would fail with error:
+// Try to undeploy a vApp with the given power off mode, but do not throw an error if the vApp is powered off.
+// Very often the vApp is powered off at this point and the undeploy would fail with error:
 // "The requested operation could not be executed since vApp vApp_name is not running"
 // So, if the error matches we just ignore it and the caller may fast forward to vapp.Delete()
-func tryUndeploy(vapp govcd.VApp) error {
-	task, err := vapp.Undeploy()
+func tryUndeploy(ctx context.Context, vcdClient *VCDClient, vapp *govcd.VApp, powerOffMode string, shutdownTimeout time.Duration) error {
+	err := undeployVapp(ctx, vcdClient, vapp, powerOffMode, shutdownTimeout)
 	var reErr = regexp.MustCompile(`.*The requested operation could not be executed since vApp.*is not running.*`)
 	if err != nil && reErr.MatchString(err.Error()) {
 		// ignore - can't be undeployed
@@ -450,11 +509,6 @@ func tryUndeploy(vapp govcd.VApp) error {
 	} else if err != nil {
 		return fmt.Errorf("error undeploying vApp: %#v", err)
 	}
-
-	err = task.WaitTaskCompletion()
-	if err != nil {
-		return fmt.Errorf("error undeploying vApp: %#v", err)
-	}
 	return nil
 }
 
@@ -490,6 +544,9 @@ func resourceVcdVappImport(_ context.Context, d *schema.ResourceData, meta inter
 	dSet(d, "name", vappName)
 	dSet(d, "org", orgName)
 	dSet(d, "vdc", vdcName)
+	// Set defaults value for fields that can't be recovered from VCD
+	dSet(d, "power_off_mode", d.Get("power_off_mode"))
+	dSet(d, "guest_shutdown_timeout", d.Get("guest_shutdown_timeout"))
 	d.SetId(vapp.VApp.ID)
 	return []*schema.ResourceData{d}, nil
 }
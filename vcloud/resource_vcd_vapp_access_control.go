@@ -138,7 +138,9 @@ func resourceAccessControlVappUpdate(ctx context.Context, d *schema.ResourceData
 	if err != nil {
 		return diag.Errorf("[resourceAccessControlVappUpdate] error finding vApp %s. %s", vappId, err)
 	}
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	if !isSharedWithEveryone {
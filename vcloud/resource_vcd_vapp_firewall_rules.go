@@ -174,7 +174,9 @@ func resourceVcdVappFirewallRulesUpdate(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(err)
 	}
 
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	networkId := d.Get("network_id").(string)
@@ -217,7 +219,9 @@ func resourceVappFirewallRulesDelete(_ context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	err = vapp.RemoveAllNetworkFirewallRules(d.Get("network_id").(string))
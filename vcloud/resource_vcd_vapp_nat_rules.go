@@ -147,7 +147,9 @@ func resourceVappNetworkNatRulesUpdate(ctx context.Context, d *schema.ResourceDa
 	if err != nil {
 		return diag.Errorf("error finding vApp. %s", err)
 	}
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	networkId := d.Get("network_id").(string)
@@ -192,7 +194,9 @@ func resourceVAppNetworkNatRulesDelete(_ context.Context, d *schema.ResourceData
 		return diag.Errorf("error finding vApp. %s", err)
 	}
 
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	err = vapp.RemoveAllNetworkNatRules(d.Get("network_id").(string))
@@ -25,6 +25,9 @@ func resourceVcdVappNetwork() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappNetworkImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(defaultTaskTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -181,7 +184,9 @@ func resourceVcdVappNetwork() *schema.Resource {
 
 func resourceVappNetworkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -390,7 +395,9 @@ func resourceVappNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -455,9 +462,11 @@ func resourceVappNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta
 // Note. This function is used for both resource `vcd_vapp_network` and `vcd_vapp_org_network`
 // because deletion of these networks is the same operation and maintaining two functions might
 // become inconsistent. They can be split again, if required.
-func resourceVappAndVappOrgNetworkDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVappAndVappOrgNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	// Should vApp be power cycled before deleting network? ('reboot_vapp_on_removal=true')
@@ -495,7 +504,7 @@ func resourceVappAndVappOrgNetworkDelete(_ context.Context, d *schema.ResourceDa
 			if err != nil {
 				return diag.Errorf("error Powering Off: %s", err)
 			}
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.Errorf("error completing vApp Power Off task: %s", err)
 			}
@@ -528,7 +537,7 @@ func resourceVappAndVappOrgNetworkDelete(_ context.Context, d *schema.ResourceDa
 		if err != nil {
 			return diag.Errorf("error powering on vApp: %s", err)
 		}
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return diag.Errorf("error completing vApp power on task: %s", err)
 		}
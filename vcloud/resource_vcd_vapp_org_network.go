@@ -22,6 +22,9 @@ func resourceVcdVappOrgNetwork() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappOrgNetworkImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(defaultTaskTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"vapp_name": {
@@ -73,7 +76,9 @@ func resourceVcdVappOrgNetwork() *schema.Resource {
 
 func resourceVappOrgNetworkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -196,7 +201,9 @@ func resourceVappOrgNetworkUpdate(ctx context.Context, d *schema.ResourceData, m
 		return resourceVappOrgNetworkRead(ctx, d, meta)
 	}
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -0,0 +1,115 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSimulatorVappLifecycle checks the creation, import, power change and deletion of a vApp
+func TestSimulatorVappLifecycle(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":      simulatorOrg,
+		"vdc":      simulatorVdc,
+		"name":     "test-vapp",
+		"power_on": true,
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	if status, _ := sim.Status(d.Id()); status != 4 {
+		t.Fatalf("expected the vApp to be powered on, got status %d", status)
+	}
+	if d.Get("status_text").(string) != "POWERED_ON" {
+		t.Fatalf("unexpected status_text %q", d.Get("status_text"))
+	}
+
+	imported := importSimulatorResource(t, resource, strings.Join([]string{simulatorOrg, simulatorVdc, "test-vapp"}, ImportSeparator), vcdClient)
+	if imported.Id() != d.Id() {
+		t.Fatalf("expected imported vApp ID %s, got %s", d.Id(), imported.Id())
+	}
+
+	d = simulatorUpdateData(t, resource, d, map[string]interface{}{
+		"org":      simulatorOrg,
+		"vdc":      simulatorVdc,
+		"name":     "test-vapp",
+		"power_on": false,
+	})
+	if diags := resource.UpdateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error powering off vApp: %v", diags)
+	}
+	if status, _ := sim.Status(d.Id()); status != 8 {
+		t.Fatalf("expected the vApp to be powered off, got status %d", status)
+	}
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+	if _, found := sim.Status(imported.Id()); found {
+		t.Fatal("expected the vApp to be deleted")
+	}
+}
+
+// TestSimulatorErrorPaths checks that failed tasks and API errors are reported, and that a vApp removed outside of Terraform is removed from the state
+func TestSimulatorErrorPaths(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVApp()
+
+	// A failed creation task must be reported, and leave nothing in the state
+	sim.FailNextTask("vdcComposeVapp")
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "failing-vapp",
+	})
+	diags := resource.CreateContext(ctx, d, vcdClient)
+	if !diags.HasError() {
+		t.Fatal("expected an error when the creation task fails")
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected no ID after a failed creation, got %s", d.Id())
+	}
+
+	// An API error during an update is reported, and the entity is left unchanged
+	d = simulatorResourceData(t, resource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "test-vapp",
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating vApp: %v", diags)
+	}
+	sim.InjectError(http.MethodPost, `/power/action/powerOn$`, http.StatusBadRequest, "simulated power on failure")
+	updated := simulatorUpdateData(t, resource, d, map[string]interface{}{
+		"org":      simulatorOrg,
+		"vdc":      simulatorVdc,
+		"name":     "test-vapp",
+		"power_on": true,
+	})
+	diags = resource.UpdateContext(ctx, updated, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "simulated power on failure") {
+		t.Fatalf("expected the injected error, got %v", diags)
+	}
+	if status, _ := sim.Status(d.Id()); status == 4 {
+		t.Fatal("expected the vApp not to be powered on")
+	}
+
+	// An entity removed outside of Terraform is removed from the state
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting vApp: %v", diags)
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading deleted vApp: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the deleted vApp to be removed from the state")
+	}
+}
@@ -0,0 +1,314 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+func resourceVcdVappSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVappSnapshotCreate,
+		ReadContext:   resourceVcdVappSnapshotRead,
+		UpdateContext: resourceVcdVappSnapshotUpdate,
+		DeleteContext: resourceVcdVappSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVappSnapshotImport,
+		},
+		Timeouts: taskTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The vApp for which the snapshot of all VMs is taken",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Name of the snapshot",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Description of the snapshot",
+			},
+			"memory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Include the memory of powered on VMs in the snapshot",
+			},
+			"quiesce": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Quiesce the guest file systems before taking the snapshot (requires VMware Tools)",
+			},
+			"revert_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Any change to this value reverts all the VMs of the vApp to their snapshot. " +
+					"Its value is not sent to VCD",
+			},
+			"vm_snapshot": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Snapshots of the VMs in the vApp",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"vm_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VM",
+						},
+						"vm_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Creation date of the snapshot",
+						},
+						"powered_on": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the VM was powered on when the snapshot was taken",
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the snapshot in bytes",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdVappSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vapp, err := getVappForSnapshot(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("[vApp snapshot create] %s", err)
+	}
+
+	// Creating a snapshot would silently replace the ones taken outside of this resource
+	if vapp.VApp.Children != nil {
+		for _, vm := range vapp.VApp.Children.VM {
+			existing, err := getSnapshot(vcdClient, vm.HREF)
+			if err != nil {
+				return diag.Errorf("[vApp snapshot create] error retrieving snapshot for VM '%s': %s", vm.Name, err)
+			}
+			if existing != nil {
+				return diag.Errorf("[vApp snapshot create] VM '%s' of vApp '%s' already has a snapshot, created on %s. "+
+					"Import the vApp snapshot into this resource or remove it before creating a new one", vm.Name, vapp.VApp.Name, existing.Created)
+			}
+		}
+	}
+
+	err = createSnapshot(ctx, vcdClient, vapp.VApp.HREF, snapshotParamsFromResource(d))
+	if err != nil {
+		return diag.Errorf("[vApp snapshot create] error creating snapshot for vApp '%s': %s", vapp.VApp.Name, err)
+	}
+
+	d.SetId(vapp.VApp.ID)
+	return resourceVcdVappSnapshotRead(ctx, d, meta)
+}
+
+// resourceVcdVappSnapshotRead reads the snapshots of all VMs in the vApp. The snapshot is considered
+// removed when none of the VMs has one, as VMs added to the vApp after the snapshot was taken have
+// none, and replaced when the snapshot of a VM in state has a different creation date.
+func resourceVcdVappSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	createdByVm := make(map[string]string)
+	for _, item := range d.Get("vm_snapshot").(*schema.Set).List() {
+		vmSnapshot := item.(map[string]interface{})
+		createdByVm[vmSnapshot["vm_id"].(string)] = vmSnapshot["created"].(string)
+	}
+
+	vapp, err := getVappForSnapshot(vcdClient, d)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			log.Printf("[DEBUG] vApp '%s' not found. Removing snapshot from state", d.Get("vapp_name").(string))
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[vApp snapshot read] %s", err)
+	}
+
+	var vmSnapshots []interface{}
+	if vapp.VApp.Children != nil {
+		for _, vm := range vapp.VApp.Children.VM {
+			snapshot, err := getSnapshot(vcdClient, vm.HREF)
+			if err != nil {
+				return diag.Errorf("[vApp snapshot read] error retrieving snapshot for VM '%s': %s", vm.Name, err)
+			}
+			if snapshot == nil {
+				continue
+			}
+			// A different creation date means that the snapshot was replaced outside of Terraform
+			if created := createdByVm[vm.ID]; created != "" && created != snapshot.Created {
+				log.Printf("[DEBUG] snapshot of VM '%s' created on %s was replaced by one created on %s. Removing vApp snapshot from state",
+					vm.Name, created, snapshot.Created)
+				d.SetId("")
+				return nil
+			}
+			vmSnapshots = append(vmSnapshots, map[string]interface{}{
+				"vm_name":    vm.Name,
+				"vm_id":      vm.ID,
+				"created":    snapshot.Created,
+				"powered_on": snapshot.PoweredOn,
+				"size":       snapshot.Size,
+			})
+		}
+	}
+
+	if len(vmSnapshots) == 0 {
+		log.Printf("[DEBUG] no VM in vApp '%s' has a snapshot. Removing it from state", vapp.VApp.Name)
+		d.SetId("")
+		return nil
+	}
+
+	err = d.Set("vm_snapshot", vmSnapshots)
+	if err != nil {
+		return diag.Errorf("[vApp snapshot read] error setting VM snapshots: %s", err)
+	}
+	d.SetId(vapp.VApp.ID)
+
+	return nil
+}
+
+// resourceVcdVappSnapshotUpdate only handles 'revert_trigger', as all other fields force a new snapshot
+func resourceVcdVappSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if !d.HasChange("revert_trigger") {
+		return resourceVcdVappSnapshotRead(ctx, d, meta)
+	}
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vapp, err := getVappForSnapshot(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("[vApp snapshot update] %s", err)
+	}
+
+	log.Printf("[DEBUG] reverting vApp '%s' to its current snapshot", vapp.VApp.Name)
+	err = runSnapshotAction(ctx, vcdClient, vapp.VApp.HREF, snapshotActionRevert)
+	if err != nil {
+		return diag.Errorf("[vApp snapshot update] error reverting vApp '%s' to snapshot: %s", vapp.VApp.Name, err)
+	}
+
+	return resourceVcdVappSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVappSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vapp, err := getVappForSnapshot(vcdClient, d)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[vApp snapshot delete] %s", err)
+	}
+
+	err = runSnapshotAction(ctx, vcdClient, vapp.VApp.HREF, snapshotActionRemoveAll)
+	if err != nil {
+		return diag.Errorf("[vApp snapshot delete] error removing snapshots of vApp '%s': %s", vapp.VApp.Name, err)
+	}
+
+	return nil
+}
+
+// resourceVcdVappSnapshotImport is responsible for importing the resource.
+// The following steps happen as part of import
+// 1. The user supplies `terraform import _resource_name_ _the_id_string_` command
+// 2. `_the_id_string_` contains a dot formatted path to resource as in the example below
+// 3. The functions splits the dot-formatted path and tries to lookup the object
+// 4. If the lookup succeeds it sets the ID field for `_resource_name_` resource in statefile
+// (the resource must be already defined in .tf config otherwise `terraform import` will complain)
+// 5. `terraform refresh` is being implicitly launched. The Read method looks up all other fields
+// based on the known ID of object.
+//
+// Example resource name (_resource_name_): vcloud_vapp_snapshot.my-snapshot
+// Example import path (_the_id_string_): org-name.vdc-name.vapp-name
+func resourceVcdVappSnapshotImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 3 {
+		return nil, fmt.Errorf("resource name must be specified as org-name.vdc-name.vapp-name")
+	}
+	orgName, vdcName, vappName := resourceURI[0], resourceURI[1], resourceURI[2]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppByName(vappName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp '%s': %s", vappName, err)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vapp_name", vappName)
+	dSet(d, "memory", false)
+	dSet(d, "quiesce", false)
+	d.SetId(vapp.VApp.ID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func getVappForSnapshot(vcdClient *VCDClient, d *schema.ResourceData) (*govcd.VApp, error) {
+	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vappName := d.Get("vapp_name").(string)
+	vapp, err := vdc.GetVAppByName(vappName, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp '%s': %s", vappName, err)
+	}
+	return vapp, nil
+}
@@ -0,0 +1,88 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorVappSnapshotLifecycle checks the creation, import, replacement and deletion of a vApp snapshot
+func TestSimulatorVappSnapshotLifecycle(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVappSnapshot()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "snapshot-vapp")
+	webId := sim.AddVm(simulatorOrg, simulatorVdc, "snapshot-vapp", "web")
+	dbId := sim.AddVm(simulatorOrg, simulatorVdc, "snapshot-vapp", "db")
+	values := map[string]interface{}{
+		"org":       simulatorOrg,
+		"vdc":       simulatorVdc,
+		"vapp_name": "snapshot-vapp",
+		"name":      "before-upgrade",
+	}
+
+	// A snapshot taken on one of the VMs would be replaced by the vApp snapshot
+	db := simulatorVm(t, vcdClient, dbId)
+	err := createSnapshot(ctx, vcdClient, db.VM.HREF, &createSnapshotParams{Xmlns: types.XMLNamespaceVCloud})
+	if err != nil {
+		t.Fatalf("error creating VM snapshot: %s", err)
+	}
+	d := simulatorResourceData(t, resource, values)
+	diags := resource.CreateContext(ctx, d, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "'db' of vApp 'snapshot-vapp' already has a snapshot") {
+		t.Fatalf("expected the creation of the vApp snapshot to fail, got %v", diags)
+	}
+	err = runSnapshotAction(ctx, vcdClient, db.VM.HREF, snapshotActionRemoveAll)
+	if err != nil {
+		t.Fatalf("error removing VM snapshot: %s", err)
+	}
+
+	d = simulatorResourceData(t, resource, values)
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating snapshot: %v", diags)
+	}
+	if sim.Snapshot(webId) == nil || sim.Snapshot(dbId) == nil {
+		t.Fatal("expected both VMs to have a snapshot")
+	}
+	if count := d.Get("vm_snapshot").(*schema.Set).Len(); count != 2 {
+		t.Fatalf("expected 2 VM snapshots, got %d", count)
+	}
+
+	imported := importSimulatorResource(t, resource,
+		strings.Join([]string{simulatorOrg, simulatorVdc, "snapshot-vapp"}, ImportSeparator), vcdClient)
+	if imported.Id() != d.Id() {
+		t.Fatalf("expected imported snapshot ID %s, got %s", d.Id(), imported.Id())
+	}
+
+	// A snapshot of one VM replaced outside of Terraform removes the vApp snapshot from state
+	err = createSnapshot(ctx, vcdClient, db.VM.HREF, &createSnapshotParams{Xmlns: types.XMLNamespaceVCloud})
+	if err != nil {
+		t.Fatalf("error replacing the snapshot: %s", err)
+	}
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading snapshot: %v", diags)
+	}
+	if imported.Id() == "" {
+		t.Fatal("expected an import of the new snapshot to be kept in state")
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading snapshot: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the replaced snapshot to be removed from state")
+	}
+	d.SetId(imported.Id())
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting snapshot: %v", diags)
+	}
+	if sim.Snapshot(webId) != nil || sim.Snapshot(dbId) != nil {
+		t.Fatal("expected the snapshots to be removed")
+	}
+}
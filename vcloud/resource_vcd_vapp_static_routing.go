@@ -96,7 +96,9 @@ func resourceVappNetworkStaticRoutingUpdate(ctx context.Context, d *schema.Resou
 	if err != nil {
 		return diag.Errorf("error finding vApp. %s", err)
 	}
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	networkId := d.Get("network_id").(string)
@@ -129,7 +131,9 @@ func resourceVAppNetworkStaticRoutingDelete(_ context.Context, d *schema.Resourc
 		return diag.Errorf("error finding vApp. %s", err)
 	}
 
-	vcdClient.lockParentVappWithName(d, vapp.VApp.Name)
+	if err := vcdClient.lockParentVappWithName(d, vapp.VApp.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVappWithName(d, vapp.VApp.Name)
 
 	err = vapp.RemoveAllNetworkStaticRoutes(d.Get("network_id").(string))
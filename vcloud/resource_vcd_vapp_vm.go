@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -78,7 +79,9 @@ func resourceVcdVAppVm() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappVmImport,
 		},
-		Schema: vmSchemaFunc(vappVmType),
+		Timeouts:      taskTimeouts(),
+		Schema:        vmSchemaFunc(vappVmType),
+		CustomizeDiff: vmCustomizeDiff(vappVmType),
 	}
 }
 
@@ -90,8 +93,7 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Required:    vmType == vappVmType,
 			Optional:    vmType == standaloneVmType,
 			Computed:    vmType == standaloneVmType,
-			ForceNew:    vmType == vappVmType,
-			Description: "The vApp this VM belongs to - Required, unless it is a standalone VM",
+			Description: "The vApp this VM belongs to - Required, unless it is a standalone VM. Changing it moves the VM to another vApp",
 		},
 		"vapp_id": {
 			Type:        schema.TypeString,
@@ -106,7 +108,6 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 		"name": {
 			Type:        schema.TypeString,
 			Required:    true,
-			ForceNew:    true,
 			Description: "A name for the VM, unique within the vApp",
 		},
 		"computer_name": {
@@ -123,10 +124,10 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 				"level. Useful when connected as sysadmin working across different organizations",
 		},
 		"vdc": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			ForceNew:    true,
-			Description: "The name of VDC to use, optional if defined at provider level",
+			Type:     schema.TypeString,
+			Optional: true,
+			Description: "The name of VDC to use, optional if defined at provider level. Changing it moves a vApp VM " +
+				"to another VDC, and replaces a standalone VM",
 		},
 		"template_name": {
 			Type:             schema.TypeString,
@@ -249,7 +250,8 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Deprecated:    "Use metadata_entry instead",
 			ConflictsWith: []string{"metadata_entry"},
 		},
-		"metadata_entry": metadataEntryResourceSchemaDeprecated("VM"),
+		"metadata_entry":      metadataEntryResourceSchemaDeprecated("VM"),
+		"deletion_protection": deletionProtectionSchema("VM"),
 		"href": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -726,6 +728,12 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Default:     false,
 			Description: "True if the virtual machine supports addition of memory while powered on.",
 		},
+		"requires_power_cycle": {
+			Type:     schema.TypeBool,
+			Computed: true,
+			Description: "Set by the plan: true when the update powers off the running VM and powers it on again " +
+				"to apply the planned changes",
+		},
 		"prevent_update_power_off": {
 			Type:             schema.TypeBool,
 			Optional:         true,
@@ -733,6 +741,8 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 			Description:      "True if the update of resource should fail when virtual machine power off needed.",
 			DiffSuppressFunc: suppressFieldAfterImport("prevent_update_power_off"),
 		},
+		"power_off_mode":         powerOffModeSchema("VM"),
+		"guest_shutdown_timeout": guestShutdownTimeoutSchema("VM"),
 		"sizing_policy_id": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -777,7 +787,7 @@ func vmSchemaFunc(vmType typeOfVm) map[string]*schema.Schema {
 
 // resourceVcdVAppVmCreate is an entry function for VM within vApp creation. It locks parent vApp and cascades down the
 // other functions that need to be run
-func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppVmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	startTime := time.Now()
 
 	vappName := d.Get("vapp_name").(string)
@@ -789,7 +799,9 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 
 	// vApp lock must be acquired for VMs that are vApp members
 	vcdClient := meta.(*VCDClient)
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	// If VM is a copy of another VM (has 'copy_from_vm_id' specified), parent vApp lock of source
@@ -828,7 +840,10 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 		if parentSourceVapp.VApp.Name != vappName || parentSourceVdc.Vdc.Name != destinationVdc.Vdc.Name {
 			util.Logger.Printf("[DEBUG] [VM create] locking parent vApp for source VM  (Org Name: '%s', VDC Name: '%s', vApp name: '%s', VM Name: '%s')",
 				destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name, sourceVm.VM.Name)
-			unlock := vcdClient.lockVappWithName(destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name)
+			unlock, err := vcdClient.lockVappWithName(destinationOrg.Org.Name, parentSourceVdc.Vdc.Name, parentSourceVapp.VApp.Name)
+			if err != nil {
+				return diag.FromErr(err)
+			}
 			defer unlock()
 		} else {
 			util.Logger.Printf("[DEBUG] [VM create] not locking parent vApp for source VM because source and destination are the same (Source vApp: '%s', Destination vApp: '%s')  (Source VDC: '%s', Destination VDC: '%s')",
@@ -836,7 +851,7 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 		}
 	}
 
-	diags := genericResourceVmCreate(d, meta, vappVmType)
+	diags := genericResourceVmCreate(ctx, d, meta, vappVmType)
 	// We need to check if there were errors, as genericResourceVmCreate can also return a warning
 	if diags.HasError() {
 		return diags
@@ -855,7 +870,7 @@ func resourceVcdVAppVmCreate(_ context.Context, d *schema.ResourceData, meta int
 // genericResourceVmCreate does the following:
 // * Executes VM create functions based on the type of VM (standalone or vApp member)
 // * Runs additional customization functions which are common for all 4 types of VMs
-func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
+func genericResourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
 	diags := diag.Diagnostics{}
 	vcdClient := meta.(*VCDClient)
 
@@ -876,19 +891,19 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 	switch {
 	case isVmFromTemplateDeprecated || isVmFromTemplate:
 		util.Logger.Printf("[DEBUG] [VM create] creating VM from template")
-		vm, err = createVmFromImage(d, meta, vmType, vmSourceCatalogTemplate)
+		vm, err = createVmFromImage(ctx, d, meta, vmType, vmSourceCatalogTemplate)
 		if err != nil {
 			return diag.Errorf("error creating VM from template: %s", err)
 		}
 	case isVmCopy:
 		util.Logger.Printf("[DEBUG] [VM create] creating VM copy")
-		vm, err = createVmFromImage(d, meta, vmType, vmSourceVmCopy)
+		vm, err = createVmFromImage(ctx, d, meta, vmType, vmSourceVmCopy)
 		if err != nil {
 			return diag.Errorf("error creating VM copy: %s", err)
 		}
 	case isEmptyVm:
 		util.Logger.Printf("[DEBUG] [VM create] creating empty VM")
-		vm, err = createVmEmpty(d, meta, vmType)
+		vm, err = createVmEmpty(ctx, d, meta, vmType)
 		if err != nil {
 			return diag.Errorf("error creating empty VM: %s", err)
 		}
@@ -958,10 +973,14 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 	// Handle Metadata
 	// Such schema fields are processed:
 	// * metadata
-	err = createOrUpdateMetadata(d, vm, "metadata")
+	err = createOrUpdateMetadata(d, vcdClient, vm, "metadata")
 	if err != nil {
 		return diag.Errorf("error setting metadata: %s", err)
 	}
+	err = updateDeletionProtectionMarker(d, vcdClient, vm)
+	if err != nil {
+		return diag.Errorf("error protecting VM: %s", err)
+	}
 
 	// Handle Hardware Virtualization setting (used for hypervisor nesting)
 	// Such schema fields are processed:
@@ -1081,7 +1100,7 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 			if err != nil {
 				return diag.Errorf("error powering on: %s", err)
 			}
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.Errorf(errorCompletingTask, err)
 			}
@@ -1120,7 +1139,7 @@ func genericResourceVmCreate(d *schema.ResourceData, meta interface{}, vmType ty
 // 3. Perform additional operations which are common for both types of VMs
 //
 // Note. VM Power ON (if it wasn't disabled in HCL configuration) occurs as last step after all configuration is done.
-func createVmFromImage(d *schema.ResourceData, meta interface{}, vmType typeOfVm, sourceImageType vmImageSource) (*govcd.VM, error) {
+func createVmFromImage(ctx context.Context, d *schema.ResourceData, meta interface{}, vmType typeOfVm, sourceImageType vmImageSource) (*govcd.VM, error) {
 	vcdClient := meta.(*VCDClient)
 
 	// Step 1 - lookup common information
@@ -1212,13 +1231,14 @@ func createVmFromImage(d *schema.ResourceData, meta interface{}, vmType typeOfVm
 		}
 
 		util.Logger.Printf("%# v", pretty.Formatter(standaloneVmParams))
-		vm, err = vdc.CreateStandaloneVMFromTemplate(&standaloneVmParams)
+		task, err := vdc.CreateStandaloneVMFromTemplateAsync(&standaloneVmParams)
+		if err != nil {
+			return nil, fmt.Errorf("[VM creation] error creating standalone VM from template %s : %s", vmName, err)
+		}
+		vm, err = waitForVmCreation(ctx, d, task, standaloneVmLookup(vcdClient, vdc, task))
 		if err != nil {
-			d.SetId("")
 			return nil, fmt.Errorf("[VM creation] error creating standalone VM from template %s : %s", vmName, err)
 		}
-
-		d.SetId(vm.VM.ID)
 
 		util.Logger.Printf("[VM create] VM from template after creation %# v", pretty.Formatter(vm.VM))
 		vapp, err = vm.GetParentVApp()
@@ -1269,13 +1289,17 @@ func createVmFromImage(d *schema.ResourceData, meta interface{}, vmType typeOfVm
 			},
 		}
 
-		vm, err = vapp.AddRawVM(vappVmParams)
+		// vapp.AddRawVM does not return its task: the recompose request is sent here instead
+		task, err := vcdClient.Client.ExecuteTaskRequestWithApiVersion(vapp.VApp.HREF+"/action/recomposeVApp", http.MethodPost,
+			types.MimeRecomposeVappParams, "error instantiating a new VM: %s", vappVmParams,
+			vcdClient.Client.GetSpecificApiVersionOnCondition(">=37.1", "37.1"))
+		if err != nil {
+			return nil, fmt.Errorf("[VM creation] error creating VM %s : %s", vmName, err)
+		}
+		vm, err = waitForVmCreation(ctx, d, task, vappVmLookup(vapp, vmName))
 		if err != nil {
-			d.SetId("")
 			return nil, fmt.Errorf("[VM creation] error getting VM %s : %s", vmName, err)
 		}
-
-		d.SetId(vm.VM.ID)
 		dSet(d, "vm_type", string(vappVmType))
 
 	////////////////////////////////////////////////////////////////////////////////////////////
@@ -1393,6 +1417,58 @@ func createVmFromImage(d *schema.ResourceData, meta interface{}, vmType typeOfVm
 	return vm, nil
 }
 
+// waitForVmCreation waits for the task creating a VM, and returns the VM found with lookup. When the
+// timeout expires first, the ID of the VM is set anyway, so that a VM still being created is kept in the
+// state instead of being left behind.
+func waitForVmCreation(ctx context.Context, d *schema.ResourceData, task govcd.Task, lookup func() (*govcd.VM, error)) (*govcd.VM, error) {
+	err := waitForTask(ctx, task)
+	if err != nil {
+		if ctx.Err() != nil {
+			if vm, lookupErr := lookup(); lookupErr == nil {
+				d.SetId(vm.VM.ID)
+			}
+		}
+		return nil, err
+	}
+	vm, err := lookup()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM after creation: %s", err)
+	}
+	d.SetId(vm.VM.ID)
+	return vm, nil
+}
+
+// standaloneVmLookup finds the VM of a standalone VM creation task. The owner of the task is the hidden
+// vApp of the VM, which contains only that VM.
+func standaloneVmLookup(vcdClient *VCDClient, vdc *govcd.Vdc, task govcd.Task) func() (*govcd.VM, error) {
+	return func() (*govcd.VM, error) {
+		if task.Task.Owner == nil || task.Task.Owner.HREF == "" {
+			err := task.Refresh()
+			if err != nil {
+				return nil, err
+			}
+			if task.Task.Owner == nil || task.Task.Owner.HREF == "" {
+				return nil, fmt.Errorf("%s: VM creation task has no owner", govcd.ErrorEntityNotFound)
+			}
+		}
+		vapp, err := vdc.GetVAppByHref(task.Task.Owner.HREF)
+		if err != nil {
+			return nil, err
+		}
+		if vapp.VApp.Children == nil || len(vapp.VApp.Children.VM) != 1 {
+			return nil, fmt.Errorf("%s: no single VM in vApp %s", govcd.ErrorEntityNotFound, vapp.VApp.Name)
+		}
+		return vcdClient.Client.GetVMByHref(vapp.VApp.Children.VM[0].HREF)
+	}
+}
+
+// vappVmLookup finds a VM of a vApp by name, as VM creation tasks in a vApp don't reference the VM
+func vappVmLookup(vapp *govcd.VApp, vmName string) func() (*govcd.VM, error) {
+	return func() (*govcd.VM, error) {
+		return vapp.GetVMByName(vmName, true)
+	}
+}
+
 // createVmEmpty is responsible for creating empty VMs of two types:
 // * Standalone VMs
 // * VMs inside vApp (vApp VMs)
@@ -1414,7 +1490,7 @@ func createVmFromImage(d *schema.ResourceData, meta interface{}, vmType typeOfVm
 // 3. Perform additional operations which are common for both types of VMs
 //
 // Note. VM Power ON (if it wasn't disabled in HCL configuration) occurs as last step after all configuration is done.
-func createVmEmpty(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (*govcd.VM, error) {
+func createVmEmpty(ctx context.Context, d *schema.ResourceData, meta interface{}, vmType typeOfVm) (*govcd.VM, error) {
 	util.Logger.Printf("[TRACE] Creating empty VM: %s", d.Get("name").(string))
 
 	vcdClient := meta.(*VCDClient)
@@ -1626,12 +1702,15 @@ func createVmEmpty(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (*
 			Media: mediaReference,
 		}
 
-		newVm, err = vdc.CreateStandaloneVm(&params)
+		task, err := vdc.CreateStandaloneVmAsync(&params)
 		if err != nil {
 			return nil, err
 		}
 		// VM created - store its ID
-		d.SetId(newVm.VM.ID)
+		newVm, err = waitForVmCreation(ctx, d, task, standaloneVmLookup(vcdClient, vdc, task))
+		if err != nil {
+			return nil, err
+		}
 		dSet(d, "vm_type", string(standaloneVmType))
 
 	////////////////////////////////////////////////////////////////////////////////////////////
@@ -1676,12 +1755,15 @@ func createVmEmpty(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (*
 		}
 
 		util.Logger.Printf("[VM create - add empty VM] recomposeVAppParamsForEmptyVm %# v", pretty.Formatter(recomposeVAppParamsForEmptyVm))
-		newVm, err = vapp.AddEmptyVm(recomposeVAppParamsForEmptyVm)
+		task, err := vapp.AddEmptyVmAsync(recomposeVAppParamsForEmptyVm)
 		if err != nil {
 			return nil, fmt.Errorf("[VM creation] error creating VM %s : %s", vmName, err)
 		}
 		// VM created - store its ID
-		d.SetId(newVm.VM.ID)
+		newVm, err = waitForVmCreation(ctx, d, task, vappVmLookup(vapp, vmName))
+		if err != nil {
+			return nil, fmt.Errorf("[VM creation] error creating VM %s : %s", vmName, err)
+		}
 		dSet(d, "vm_type", string(vappVmType))
 
 	////////////////////////////////////////////////////////////////////////////////////////////
@@ -1732,11 +1814,11 @@ func createVmEmpty(d *schema.ResourceData, meta interface{}, vmType typeOfVm) (*
 	return newVm, nil
 }
 
-func resourceVcdVAppVmUpdate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return genericResourceVcdVmUpdate(d, meta, vappVmType)
+func resourceVcdVAppVmUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericResourceVcdVmUpdate(ctx, d, meta, vappVmType)
 }
 
-func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
+func genericResourceVcdVmUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
 	log.Printf("[DEBUG] [VM update] started with lock")
 	vcdClient := meta.(*VCDClient)
 
@@ -1745,9 +1827,12 @@ func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType
 	// To avoid them, below block is using mutex as a workaround,
 	// so that the one vApp VMs are created not in parallelisation.
 
+	// A VM that moves to another vApp also locks the vApp it leaves
 	if vmType == vappVmType {
-		vcdClient.lockParentVapp(d)
-		defer vcdClient.unLockParentVapp(d)
+		for _, key := range vmMoveLockKeys(vcdClient, d) {
+			vcdMutexKV.kvLock(key)
+			defer vcdMutexKV.kvUnlock(key)
+		}
 	}
 
 	// Exit early only if "network_dhcp_wait_seconds" is changed because this field only supports
@@ -1757,12 +1842,19 @@ func genericResourceVcdVmUpdate(d *schema.ResourceData, meta interface{}, vmType
 		return genericVcdVmRead(d, meta, "resource")
 	}
 
+	if vmType == vappVmType && d.HasChanges("vapp_name", "vdc") {
+		err := moveVm(ctx, d, vcdClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	err := resourceVmHotUpdate(d, meta, vmType)
 	if err != nil {
 		return err
 	}
 
-	return resourceVcdVAppVmUpdateExecute(d, meta, "update", vmType, nil)
+	return resourceVcdVAppVmUpdateExecute(ctx, d, meta, "update", vmType, nil)
 }
 
 func resourceVmHotUpdate(d *schema.ResourceData, meta interface{}, vmType typeOfVm) diag.Diagnostics {
@@ -1796,7 +1888,11 @@ func resourceVmHotUpdate(d *schema.ResourceData, meta interface{}, vmType typeOf
 		}
 	}
 
-	err = createOrUpdateMetadata(d, vm, "metadata")
+	err = createOrUpdateMetadata(d, meta.(*VCDClient), vm, "metadata")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = updateDeletionProtectionMarker(d, meta.(*VCDClient), vm)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -1866,7 +1962,7 @@ func resourceVmHotUpdate(d *schema.ResourceData, meta interface{}, vmType typeOf
 	return nil
 }
 
-func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, executionType string, vmType typeOfVm, computePolicy *types.VdcComputePolicy) diag.Diagnostics {
+func resourceVcdVAppVmUpdateExecute(ctx context.Context, d *schema.ResourceData, meta interface{}, executionType string, vmType typeOfVm, computePolicy *types.VdcComputePolicy) diag.Diagnostics {
 	diags := diag.Diagnostics{}
 	log.Printf("[DEBUG] [VM update] started without lock")
 
@@ -1880,6 +1976,13 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 		return diag.Errorf("[VM update] error getting VM (%s) status before update: %s", identifier, err)
 	}
 
+	if executionType == "update" && d.HasChange("name") {
+		err = renameVm(ctx, vcd, vm, d.Get("name").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	// Check if the user requested for forced customization of VM
 	customizationNeeded := isForcedCustomization(d.Get("customization"))
 
@@ -1902,29 +2005,19 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 		}
 	}
 
-	memoryNeedsColdChange := false
-	cpusNeedsColdChange := false
-	networksNeedsColdChange := false
-	if executionType == "update" {
-		if !d.Get("memory_hot_add_enabled").(bool) && d.HasChange("memory") {
-			memoryNeedsColdChange = true
-		}
-		if !d.Get("cpu_hot_add_enabled").(bool) && d.HasChange("cpus") {
-			cpusNeedsColdChange = true
-		}
-		if d.HasChange("network") && isPrimaryNicRemoved(d) {
-			networksNeedsColdChange = true
-		}
+	// this represents fields which have to be changed in cold (with VM power off). At creation, the networks
+	// are always set with the VM powered off
+	coldChanges := vmColdChanges(d)
+	if executionType == "create" && len(d.Get("network").([]interface{})) > 0 && !contains(coldChanges, "network") {
+		coldChanges = append(coldChanges, "network")
 	}
-	if executionType == "create" && len(d.Get("network").([]interface{})) > 0 {
-		networksNeedsColdChange = true
-	}
-	log.Printf("[TRACE] VM %s requires cold changes: memory(%t), cpu(%t), network(%t)", vm.VM.Name, memoryNeedsColdChange, cpusNeedsColdChange, networksNeedsColdChange)
+	log.Printf("[TRACE] VM %s requires cold changes: %v", vm.VM.Name, coldChanges)
+	memoryNeedsColdChange := contains(coldChanges, "memory")
+	cpusNeedsColdChange := contains(coldChanges, "cpus")
+	networksNeedsColdChange := contains(coldChanges, "network")
 
-	// this represents fields which have to be changed in cold (with VM power off)
-	if d.HasChanges("cpu_cores", "power_on", "disk", "expose_hardware_virtualization", "boot_image",
-		"hardware_version", "os_type", "description", "cpu_hot_add_enabled",
-		"memory_hot_add_enabled", "firmware", "boot_options.0.efi_secure_boot") || memoryNeedsColdChange || cpusNeedsColdChange || networksNeedsColdChange {
+	needsReconfiguration := len(coldChanges) > 0
+	if needsReconfiguration || d.HasChange("power_on") {
 
 		log.Printf("[TRACE] VM %s has changes: memory(%t), cpus(%t), cpu_cores(%t),"+
 			"power_on(%t), disk(%t), expose_hardware_virtualization(%t),"+
@@ -1938,19 +2031,19 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 			d.HasChange("memory_hot_add_enabled"), d.HasChange("firmware"),
 			d.HasChange("boot_options.0.efi_secure_boot"), d.HasChange("network"))
 
-		if vmStatusBeforeUpdate != "POWERED_OFF" {
+		powerOffMode := vmUpdatePowerOffMode(d, needsReconfiguration)
+		// A suspended VM that only needs a change of power state is resumed or left alone
+		keepSuspended := vmStatusBeforeUpdate == "SUSPENDED" && powerOffMode == powerOffModeSuspend
+		if vmStatusBeforeUpdate != "POWERED_OFF" && !keepSuspended {
 			if d.Get("prevent_update_power_off").(bool) && executionType == "update" {
 				return diag.Errorf("update stopped: VM needs to power off to change properties, but `prevent_update_power_off` is `true`")
 			}
-			log.Printf("[DEBUG] Un-deploying VM %s for offline update. Previous state %s",
-				vm.VM.Name, vmStatusBeforeUpdate)
-			task, err := vm.Undeploy()
-			if err != nil {
-				return diag.Errorf("error triggering undeploy for VM %s: %s", vm.VM.Name, err)
-			}
-			err = task.WaitTaskCompletion()
+			log.Printf("[DEBUG] Un-deploying VM %s for offline update (%s). Previous state %s",
+				vm.VM.Name, powerOffMode, vmStatusBeforeUpdate)
+			_, shutdownTimeout := getPowerOffMode(d)
+			err = undeployVm(ctx, vcd, vm, powerOffMode, shutdownTimeout)
 			if err != nil {
-				return diag.Errorf("error waiting for undeploy task for VM %s: %s", vm.VM.Name, err)
+				return diag.FromErr(err)
 			}
 		}
 
@@ -2026,7 +2119,7 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 				return diag.Errorf("error changing hardware assisted virtualization: %s", err)
 			}
 
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.FromErr(err)
 			}
@@ -2188,7 +2281,7 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 			if err != nil {
 				return diag.Errorf("error powering on: %s", err)
 			}
-			err = task.WaitTaskCompletion()
+			err = waitForTask(ctx, task)
 			if err != nil {
 				return diag.Errorf(errorCompletingTask, err)
 			}
@@ -2202,13 +2295,10 @@ func resourceVcdVAppVmUpdateExecute(d *schema.ResourceData, meta interface{}, ex
 
 			if vmStatus != "POWERED_OFF" {
 				log.Printf("[TRACE] VM %s is in state %s. Un-deploying", vm.VM.Name, vmStatus)
-				task, err := vm.Undeploy()
-				if err != nil {
-					return diag.Errorf("error triggering undeploy for VM %s: %s", vm.VM.Name, err)
-				}
-				err = task.WaitTaskCompletion()
+				_, shutdownTimeout := getPowerOffMode(d)
+				err = undeployVm(ctx, vcd, vm, vmUpdatePowerOffMode(d, true), shutdownTimeout)
 				if err != nil {
-					return diag.Errorf("error waiting for undeploy task for VM %s: %s", vm.VM.Name, err)
+					return diag.FromErr(err)
 				}
 			}
 
@@ -2313,6 +2403,8 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 	dSet(d, "description", vm.VM.Description)
 	d.SetId(vm.VM.ID)
 	dSet(d, "vm_type", computedVmType)
+	// The power cycle predicted by the plan, if any, has been done by the update
+	dSet(d, "requires_power_cycle", false)
 
 	networks, err := readNetworks(d, *vm, *vapp, vdc)
 	if err != nil {
@@ -2445,7 +2537,7 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 	dSet(d, "status", vm.VM.Status)
 	dSet(d, "status_text", statusText)
 
-	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_vapp_vm", vm)...)
+	diags = append(diags, updateMetadataInStateDeprecated(d, vcdClient, "vcd_vapp_vm", vm, origin)...)
 	if diags != nil && diags.HasError() {
 		return diags
 	}
@@ -2459,12 +2551,17 @@ func genericVcdVmRead(d *schema.ResourceData, meta interface{}, origin string) d
 	return nil
 }
 
-func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdVAppVmDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] [VM delete] started")
+	if diags := checkDeletionProtection(d, "VM"); diags != nil {
+		return diags
+	}
 
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	_, vdc, err := vcdClient.GetOrgAndVdcFromResource(d)
@@ -2492,9 +2589,27 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 		return diag.Errorf("[VM delete] error getting VM %s : %s", identifier, err)
 	}
 
-	// If it is a standalone VM, we remove it in one go
+	powerOffMode, shutdownTimeout := getPowerOffMode(d)
+
+	// If it is a standalone VM, we remove it in one go. VCD powers it off, unless another
+	// power off mode was requested
 	if vapp.VApp.IsAutoNature {
-		err = vm.Delete()
+		if powerOffMode != powerOffModePowerOff {
+			deployed, err := vm.IsDeployed()
+			if err != nil {
+				return diag.Errorf("error getting VM deploy status: %s", err)
+			}
+			if deployed {
+				err = undeployVm(ctx, vcdClient, vm, powerOffMode, shutdownTimeout)
+				if err != nil {
+					return diag.FromErr(err)
+				}
+			}
+		}
+		task, err := vm.DeleteAsync()
+		if err == nil {
+			err = waitForTask(ctx, task)
+		}
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -2509,13 +2624,8 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 
 	log.Printf("[TRACE] VM deploy Status: %t", deployed)
 	if deployed {
-		log.Printf("[TRACE] Undeploying VM: %s", vm.VM.Name)
-		task, err := vm.Undeploy()
-		if err != nil {
-			return diag.Errorf("error Undeploying: %s", err)
-		}
-
-		err = task.WaitTaskCompletion()
+		log.Printf("[TRACE] Undeploying VM: %s (%s)", vm.VM.Name, powerOffMode)
+		err = undeployVm(ctx, vcdClient, vm, powerOffMode, shutdownTimeout)
 		if err != nil {
 			return diag.Errorf("error Undeploying VM: %s", err)
 		}
@@ -2535,14 +2645,14 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 		if err != nil {
 			return diag.Errorf("error detaching disk `%s`: %s", existingDiskHref, err)
 		}
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return diag.Errorf("error waiting detaching disk task to finish`%s`: %s", existingDiskHref, err)
 		}
 	}
 
 	log.Printf("[TRACE] Removing VM: %s", vm.VM.Name)
-	err = vapp.RemoveVM(*vm)
+	err = removeVappVm(ctx, vcdClient, vapp, vm)
 	if err != nil {
 		return diag.Errorf("error deleting: %s", err)
 	}
@@ -2550,6 +2660,41 @@ func resourceVcdVAppVmDelete(_ context.Context, d *schema.ResourceData, meta int
 	return nil
 }
 
+// removeVappVm removes a VM from its vApp, as vapp.RemoveVM does, but waits on the recompose task with the
+// context of the operation, so that the vApp lock is not released while the VM is still being removed
+func removeVappVm(ctx context.Context, vcdClient *VCDClient, vapp *govcd.VApp, vm *govcd.VM) error {
+	err := vapp.Refresh()
+	if err != nil {
+		return fmt.Errorf("error refreshing vApp before removing VM: %s", err)
+	}
+	// Leftover tasks of the vApp must be complete before it can be recomposed
+	if vapp.VApp.Tasks != nil {
+		unfinished := &types.TasksInProgress{}
+		for _, task := range vapp.VApp.Tasks.Task {
+			if task != nil && task.Status != "error" && task.Status != "success" {
+				unfinished.Task = append(unfinished.Task, task)
+			}
+		}
+		err = waitForTasks(ctx, &vcdClient.Client, unfinished)
+		if err != nil {
+			return fmt.Errorf("error waiting for the tasks of vApp %s: %s", vapp.VApp.Name, err)
+		}
+	}
+
+	recompose := &types.ReComposeVAppParams{
+		Ovf:        types.XMLNamespaceOVF,
+		Xsi:        types.XMLNamespaceXSI,
+		Xmlns:      types.XMLNamespaceVCloud,
+		DeleteItem: &types.DeleteItem{HREF: vm.VM.HREF},
+	}
+	task, err := vcdClient.Client.ExecuteTaskRequest(vapp.VApp.HREF+"/action/recomposeVApp", http.MethodPost,
+		types.MimeRecomposeVappParams, "error removing VM: %s", recompose)
+	if err != nil {
+		return err
+	}
+	return waitForTask(ctx, task)
+}
+
 // resourceVcdVappVmImport is responsible for importing the resource.
 // The following steps happen as part of import
 // 1. The user supplies `terraform import _resource_name_ _the_id_string_` command
@@ -2635,6 +2780,8 @@ func resourceVcdVappVmImport(_ context.Context, d *schema.ResourceData, meta int
 	dSet(d, "template_name", defaultImportedValue)
 	dSet(d, "accept_all_eulas", true)
 	dSet(d, "prevent_update_power_off", d.Get("prevent_update_power_off"))
+	dSet(d, "power_off_mode", d.Get("power_off_mode"))
+	dSet(d, "guest_shutdown_timeout", d.Get("guest_shutdown_timeout"))
 	dSet(d, "power_on", d.Get("power_on"))
 	dSet(d, "consolidate_disks_on_create", d.Get("consolidate_disks_on_create"))
 	dSet(d, "imported", true)
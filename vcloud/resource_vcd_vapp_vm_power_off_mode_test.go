@@ -0,0 +1,102 @@
+//go:build vapp || vm || ALL || functional
+
+package vcloud
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccVcdVAppVmPowerOffMode checks that a cold update and the destroy of a vApp and its VM
+// work with a guest shutdown. The test template has no VMware Tools, which forces the fallback
+// to a hard power off once the guest shutdown fails.
+func TestAccVcdVAppVmPowerOffMode(t *testing.T) {
+	preTestChecks(t)
+
+	vappName := t.Name() + "-vapp"
+	vmName := t.Name() + "-vm"
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.Nsxt.Vdc,
+		"Catalog":     testSuiteCatalogName,
+		"CatalogItem": testSuiteCatalogOVAItem,
+		"VappName":    vappName,
+		"VmName":      vmName,
+		"CpuCores":    "1",
+		"FuncName":    t.Name(),
+		"Tags":        "vapp vm",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVAppVmPowerOffMode, params)
+	params["FuncName"] = t.Name() + "-update"
+	params["CpuCores"] = "2"
+	configTextUpdate := templateFill(testAccVcdVAppVmPowerOffMode, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n%s", configText, configTextUpdate)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceName := "vcloud_vapp_vm.vm"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdNsxtVAppVmDestroy(vappName),
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "power_off_mode", "shutdown_guest"),
+					resource.TestCheckResourceAttr(resourceName, "guest_shutdown_timeout", "30"),
+					resource.TestCheckResourceAttr(resourceName, "cpu_cores", "1"),
+					resource.TestCheckResourceAttr("vcloud_vapp.vapp", "power_off_mode", "shutdown_guest"),
+				),
+			},
+			{
+				Config: configTextUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "cpu_cores", "2"),
+					resource.TestCheckResourceAttr(resourceName, "status_text", "POWERED_ON"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: importCustomObject([]string{testConfig.VCD.Org, testConfig.Nsxt.Vdc, vappName, vmName}),
+				// These fields can't be retrieved from user data
+				ImportStateVerifyIgnore: []string{"template_name", "catalog_name", "org", "vdc",
+					"accept_all_eulas", "power_on", "computer_name", "prevent_update_power_off",
+					"power_off_mode", "guest_shutdown_timeout",
+					"consolidate_disks_on_create", "imported", "vapp_template_id"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdVAppVmPowerOffMode = `
+resource "vcloud_vapp" "vapp" {
+  org            = "{{.Org}}"
+  vdc            = "{{.Vdc}}"
+  name           = "{{.VappName}}"
+  power_off_mode = "shutdown_guest"
+}
+
+resource "vcloud_vapp_vm" "vm" {
+  org                    = "{{.Org}}"
+  vdc                    = "{{.Vdc}}"
+  vapp_name              = vcloud_vapp.vapp.name
+  name                   = "{{.VmName}}"
+  description            = "{{.FuncName}}"
+  catalog_name           = "{{.Catalog}}"
+  template_name          = "{{.CatalogItem}}"
+  memory                 = 1024
+  cpus                   = 2
+  cpu_cores              = {{.CpuCores}}
+  power_off_mode         = "shutdown_guest"
+  guest_shutdown_timeout = 30
+}
+`
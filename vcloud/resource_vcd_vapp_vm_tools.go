@@ -407,7 +407,7 @@ func addRemoveGuestProperties(d *schema.ResourceData, vm *govcd.VM) error {
 }
 
 // isPrimaryNicRemoved checks if the updated schema has a primary NIC at all
-func isPrimaryNicRemoved(d *schema.ResourceData) bool {
+func isPrimaryNicRemoved(d vmChangeReader) bool {
 	_, newNetworkRaw := d.GetChange("network")
 	newNetworks := newNetworkRaw.([]interface{})
 
@@ -182,6 +182,7 @@ func resourceVdcGroup() *schema.Resource {
 				Default:     false,
 				Description: "Forces deletion of VDC Group during destroy",
 			},
+			"metadata_entry": openApiMetadataEntryResourceSchema("VDC Group"),
 		},
 	}
 }
@@ -233,6 +234,12 @@ func resourceVcdVdcGroupCreate(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	d.SetId(createdVdcGroup.VdcGroup.Id)
+
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_vdc_group", d.Id(), vdcGroupConfig.Name))
+	if err != nil {
+		return diag.Errorf("could not create metadata for the VDC group: %s", err)
+	}
+
 	return resourceVcdVdcGroupRead(ctx, d, meta)
 }
 
@@ -314,6 +321,11 @@ func resourceVcdVdcGroupUpdate(ctx context.Context, d *schema.ResourceData, meta
 		}
 	}
 
+	err = createOrUpdateOpenApiMetadataEntryInVcd(d, newOpenApiEntityMetadata(vcdClient, "vcd_vdc_group", d.Id(), vdcGroup.VdcGroup.Name))
+	if err != nil {
+		return diag.Errorf("could not update metadata for the VDC group: %s", err)
+	}
+
 	return resourceVcdVdcGroupRead(ctx, d, meta)
 }
 
@@ -379,7 +391,8 @@ func resourceVcdVdcGroupRead(_ context.Context, d *schema.ResourceData, meta int
 			return diag.Errorf("[VDC group read] could not set participating_vdc_ids block: %s", err)
 		}
 	}
-	return nil
+
+	return updateOpenApiMetadataInState(d, vcdClient, "vcd_vdc_group", newOpenApiEntityMetadata(vcdClient, "vcd_vdc_group", d.Id(), vdcGroup.VdcGroup.Name))
 }
 
 func getDefaultPolicyStatus(vdcGroup *govcd.VdcGroup) (*bool, error) {
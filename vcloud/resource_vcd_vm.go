@@ -19,19 +19,21 @@ func resourceVcdStandaloneVm() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVappVmImport,
 		},
-		Schema:      vmSchemaFunc(standaloneVmType),
-		Description: "Standalone VM",
+		Timeouts:      taskTimeouts(),
+		Schema:        vmSchemaFunc(standaloneVmType),
+		CustomizeDiff: vmCustomizeDiff(standaloneVmType),
+		Description:   "Standalone VM",
 	}
 }
 
-func resourceVcdStandaloneVmCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceVcdStandaloneVmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	startTime := time.Now()
 	util.Logger.Printf("[DEBUG] [VM create] started standalone VM creation")
 	if d.Get("vapp_name").(string) != "" {
 		return diag.Errorf("vApp name must not be set for a standalone VM (resource `vcd_vm`)")
 	}
 
-	diags := genericResourceVmCreate(d, meta, standaloneVmType)
+	diags := genericResourceVmCreate(ctx, d, meta, standaloneVmType)
 	// We need to check if there were errors, as genericResourceVmCreate can also return a warning
 	if diags.HasError() {
 		return diags
@@ -46,8 +48,8 @@ func resourceVcdStandaloneVmCreate(_ context.Context, d *schema.ResourceData, me
 	return genericVcdVmRead(d, meta, "create")
 }
 
-func resourceVcdStandaloneVmUpdate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return genericResourceVcdVmUpdate(d, meta, standaloneVmType)
+func resourceVcdStandaloneVmUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericResourceVcdVmUpdate(ctx, d, meta, standaloneVmType)
 }
 
 func resourceVcdVStandaloneVmRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -0,0 +1,484 @@
+package vcloud
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// cloudInitVolumeId is the volume identifier that cloud-init looks for to detect a NoCloud seed image
+const cloudInitVolumeId = "cidata"
+
+func resourceVcdVmCloudInit() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVmCloudInitCreate,
+		ReadContext:   resourceVcdVmCloudInitRead,
+		UpdateContext: resourceVcdVmCloudInitUpdate,
+		DeleteContext: resourceVcdVmCloudInitDelete,
+		CustomizeDiff: resourceVcdVmCloudInitCustomizeDiff,
+		Timeouts:      taskTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "vApp of the VM",
+			},
+			"vm_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "VM in which the cloud-init seed image will be inserted",
+			},
+			"catalog": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the catalog where the cloud-init seed image is uploaded",
+			},
+			"user_data": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Content of the cloud-init 'user-data' file",
+			},
+			"meta_data": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Content of the cloud-init 'meta-data' file. When empty, an 'instance-id' based on the content " +
+					"hash and a 'local-hostname' set to the VM name are used",
+			},
+			"network_config": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Content of the cloud-init 'network-config' file. The file is not included when empty",
+			},
+			"upload_piece_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "Size in MB for splitting upload size. It can possibly impact upload performance. Default 1MB.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"eject_force": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "When ejecting answers automatically to question yes",
+			},
+			"content_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 hash of the cloud-init content. A change of hash replaces the seed image",
+			},
+			"media_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the catalog media holding the cloud-init seed image",
+			},
+		},
+	}
+}
+
+// resourceVcdVmCloudInitCustomizeDiff computes the content hash and media name at plan time, so that a change
+// of content shows which seed image is going to be replaced
+func resourceVcdVmCloudInitCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	for _, field := range []string{"vapp_name", "vm_name", "user_data", "meta_data", "network_config"} {
+		if !diff.NewValueKnown(field) {
+			if err := diff.SetNewComputed("content_hash"); err != nil {
+				return err
+			}
+			return diff.SetNewComputed("media_name")
+		}
+	}
+
+	contentHash := cloudInitContentHash(diff.Get("user_data").(string), diff.Get("meta_data").(string), diff.Get("network_config").(string))
+	// An empty media name means that the seed image was ejected outside of Terraform, and must be inserted again
+	if contentHash == diff.Get("content_hash").(string) && diff.Get("media_name").(string) != "" {
+		return nil
+	}
+	if err := diff.SetNew("content_hash", contentHash); err != nil {
+		return err
+	}
+	return diff.SetNew("media_name", cloudInitMediaName(diff.Get("vapp_name").(string), diff.Get("vm_name").(string), contentHash))
+}
+
+func resourceVcdVmCloudInitCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[TRACE] VM cloud-init seed image creation initiated")
+
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vm, org, err := getVM(d, meta)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init create] %s", err)
+	}
+
+	contentHash, mediaName, err := uploadCloudInitSeedImage(ctx, d, org)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init create] %s", err)
+	}
+
+	task, err := vm.HandleInsertMedia(org, d.Get("catalog").(string), mediaName)
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
+	if err != nil {
+		// The seed image is of no use when it can't be inserted
+		if deleteErr := deleteCloudInitSeedImage(ctx, org, d.Get("catalog").(string), mediaName); deleteErr != nil {
+			log.Printf("[DEBUG] error removing cloud-init seed image '%s': %s", mediaName, deleteErr)
+		}
+		return diag.Errorf("[VM cloud-init create] error inserting cloud-init seed image '%s': %s", mediaName, err)
+	}
+
+	d.SetId(vm.VM.ID)
+	dSet(d, "content_hash", contentHash)
+	dSet(d, "media_name", mediaName)
+
+	return resourceVcdVmCloudInitRead(ctx, d, meta)
+}
+
+// resourceVcdVmCloudInitUpdate replaces the seed image when the content changes: the new image is uploaded,
+// the old one is ejected and the new one inserted. The old image is only removed from the catalog once the new one
+// is inserted, so that it can be inserted again when the insert fails. It also inserts again a seed image that was
+// ejected outside of Terraform
+func resourceVcdVmCloudInitUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChanges("user_data", "meta_data", "network_config", "media_name") {
+		return resourceVcdVmCloudInitRead(ctx, d, meta)
+	}
+	log.Printf("[TRACE] VM cloud-init seed image update initiated")
+
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	// On failure, the state keeps the previous content hash and media name, so that the next plan tries again
+	d.Partial(true)
+
+	vm, org, err := getVM(d, meta)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init update] %s", err)
+	}
+
+	catalogName := d.Get("catalog").(string)
+	oldValue, _ := d.GetChange("media_name")
+	oldContentHash, _ := d.GetChange("content_hash")
+	oldMediaName := cloudInitStateMediaName(d, oldValue.(string), oldContentHash.(string))
+
+	contentHash := cloudInitContentHash(d.Get("user_data").(string), d.Get("meta_data").(string), d.Get("network_config").(string))
+	mediaName := cloudInitMediaName(d.Get("vapp_name").(string), d.Get("vm_name").(string), contentHash)
+	media, err := getCloudInitSeedImage(org, catalogName, mediaName)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init update] %s", err)
+	}
+	uploaded := false
+	if media == nil {
+		if _, _, err = uploadCloudInitSeedImage(ctx, d, org); err != nil {
+			return diag.Errorf("[VM cloud-init update] %s", err)
+		}
+		uploaded = true
+	}
+	// removeNewImage removes the new seed image when it could not be inserted
+	removeNewImage := func() {
+		if !uploaded {
+			return
+		}
+		if err := deleteCloudInitSeedImage(ctx, org, catalogName, mediaName); err != nil {
+			log.Printf("[DEBUG] error removing cloud-init seed image '%s': %s", mediaName, err)
+		}
+	}
+
+	replaced := oldMediaName != "" && oldMediaName != mediaName
+	if replaced {
+		if err = ejectCloudInitSeedImage(ctx, d, vm, org, oldMediaName); err != nil {
+			removeNewImage()
+			return diag.Errorf("[VM cloud-init update] %s", err)
+		}
+	}
+
+	task, err := vm.HandleInsertMedia(org, catalogName, mediaName)
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
+	if err != nil {
+		removeNewImage()
+		if replaced {
+			task, insertErr := vm.HandleInsertMedia(org, catalogName, oldMediaName)
+			if insertErr == nil {
+				insertErr = waitForTask(ctx, task)
+			}
+			if insertErr != nil {
+				log.Printf("[DEBUG] error inserting again cloud-init seed image '%s': %s", oldMediaName, insertErr)
+			}
+		}
+		return diag.Errorf("[VM cloud-init update] error inserting cloud-init seed image '%s': %s", mediaName, err)
+	}
+
+	d.Partial(false)
+	dSet(d, "content_hash", contentHash)
+	dSet(d, "media_name", mediaName)
+
+	if replaced {
+		if err = deleteCloudInitSeedImage(ctx, org, catalogName, oldMediaName); err != nil {
+			return diag.Errorf("[VM cloud-init update] %s", err)
+		}
+	}
+
+	return resourceVcdVmCloudInitRead(ctx, d, meta)
+}
+
+// resourceVcdVmCloudInitRead removes the resource from state when its seed image is no longer in the catalog. When
+// the seed image is no longer inserted in the VM, it clears 'media_name' so that the next plan inserts it again
+func resourceVcdVmCloudInitRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[TRACE] VM cloud-init seed image read initiated")
+
+	vm, org, err := getVM(d, meta)
+	if err != nil {
+		// error logged and d.SetId("") is done in getVM function
+		return nil
+	}
+
+	catalog, err := org.GetCatalogByName(d.Get("catalog").(string), false)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			log.Printf("[DEBUG] Catalog '%s' not found. Removing cloud-init seed image from state", d.Get("catalog").(string))
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[VM cloud-init read] error retrieving catalog: %s", err)
+	}
+
+	mediaName := cloudInitStateMediaName(d, d.Get("media_name").(string), d.Get("content_hash").(string))
+	_, err = catalog.GetMediaByName(mediaName, false)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			log.Printf("[DEBUG] Cloud-init seed image '%s' not found. Removing from state", mediaName)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[VM cloud-init read] error retrieving cloud-init seed image: %s", err)
+	}
+
+	isIsoMounted := false
+	if vm.VM.VirtualHardwareSection != nil {
+		for _, hardwareItem := range vm.VM.VirtualHardwareSection.Item {
+			if hardwareItem.ResourceSubType == types.VMsCDResourceSubType {
+				isIsoMounted = true
+				break
+			}
+		}
+	}
+	if isIsoMounted {
+		dSet(d, "media_name", mediaName)
+	} else {
+		log.Printf("[DEBUG] Cloud-init seed image '%s' is not inserted in the VM", mediaName)
+		dSet(d, "media_name", "")
+	}
+
+	log.Printf("[TRACE] VM cloud-init seed image read completed.")
+	return nil
+}
+
+func resourceVcdVmCloudInitDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vm, org, err := getVM(d, meta)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init delete] %s", err)
+	}
+
+	mediaName := cloudInitStateMediaName(d, d.Get("media_name").(string), d.Get("content_hash").(string))
+	err = ejectAndDeleteCloudInitSeedImage(ctx, d, vm, org, mediaName)
+	if err != nil {
+		return diag.Errorf("[VM cloud-init delete] %s", err)
+	}
+
+	return nil
+}
+
+// uploadCloudInitSeedImage builds the NoCloud seed image from the resource content and uploads it to the catalog.
+// It returns the content hash and the name of the uploaded media
+func uploadCloudInitSeedImage(ctx context.Context, d *schema.ResourceData, org *govcd.Org) (string, string, error) {
+	userData := d.Get("user_data").(string)
+	metaData := d.Get("meta_data").(string)
+	networkConfig := d.Get("network_config").(string)
+	vmName := d.Get("vm_name").(string)
+
+	contentHash := cloudInitContentHash(userData, metaData, networkConfig)
+	mediaName := cloudInitMediaName(d.Get("vapp_name").(string), vmName, contentHash)
+	if metaData == "" {
+		metaData = defaultCloudInitMetaData(vmName, contentHash)
+	}
+
+	image, err := buildIso9660Image(cloudInitVolumeId, cloudInitSeedFiles(userData, metaData, networkConfig))
+	if err != nil {
+		return "", "", fmt.Errorf("error building cloud-init seed image: %s", err)
+	}
+
+	// Media can only be uploaded from a file
+	file, err := os.CreateTemp("", "cloud-init-*.iso")
+	if err != nil {
+		return "", "", fmt.Errorf("error creating temporary file for cloud-init seed image: %s", err)
+	}
+	defer func() {
+		if err := os.Remove(file.Name()); err != nil {
+			log.Printf("[DEBUG] error removing temporary file %s: %s", file.Name(), err)
+		}
+	}()
+	_, err = file.Write(image)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error writing cloud-init seed image to %s: %s", file.Name(), err)
+	}
+
+	catalog, err := org.GetCatalogByName(d.Get("catalog").(string), false)
+	if err != nil {
+		return "", "", fmt.Errorf("error finding Catalog: %s", err)
+	}
+
+	uploadPieceSize := d.Get("upload_piece_size").(int)
+	task, err := catalog.UploadMediaImage(mediaName, "cloud-init seed image of VM "+vmName, file.Name(), int64(uploadPieceSize)*1024*1024) // Convert from megabytes to bytes
+	if err != nil {
+		return "", "", fmt.Errorf("error uploading cloud-init seed image '%s': %s", mediaName, err)
+	}
+	err = waitForTask(ctx, *task.Task)
+	if err == nil {
+		err = getError(task)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error uploading cloud-init seed image '%s': %s", mediaName, err)
+	}
+
+	log.Printf("[TRACE] Cloud-init seed image uploaded: %s", mediaName)
+	return contentHash, mediaName, nil
+}
+
+// ejectAndDeleteCloudInitSeedImage ejects a seed image from the VM, if it is still inserted, and removes it from
+// the catalog. A seed image that no longer exists is ignored
+func ejectAndDeleteCloudInitSeedImage(ctx context.Context, d *schema.ResourceData, vm *govcd.VM, org *govcd.Org, mediaName string) error {
+	if err := ejectCloudInitSeedImage(ctx, d, vm, org, mediaName); err != nil {
+		return err
+	}
+	return deleteCloudInitSeedImage(ctx, org, d.Get("catalog").(string), mediaName)
+}
+
+// ejectCloudInitSeedImage ejects a seed image from the VM. A seed image that no longer exists, or that was ejected
+// outside of Terraform, is ignored
+func ejectCloudInitSeedImage(ctx context.Context, d *schema.ResourceData, vm *govcd.VM, org *govcd.Org, mediaName string) error {
+	task, err := vm.HandleEjectMedia(org, d.Get("catalog").(string), mediaName)
+	if err != nil {
+		if !govcd.ContainsNotFound(err) {
+			// The media is no longer inserted, when it was ejected outside of Terraform
+			log.Printf("[DEBUG] error ejecting cloud-init seed image '%s': %s", mediaName, err)
+		}
+		return nil
+	}
+	err = waitForEjectTask(ctx, vm, task, d.Get("eject_force").(bool))
+	if err != nil {
+		return fmt.Errorf("error ejecting cloud-init seed image '%s': %s", mediaName, err)
+	}
+	return nil
+}
+
+// getCloudInitSeedImage returns the seed image with the given name, or nil when it is not in the catalog
+func getCloudInitSeedImage(org *govcd.Org, catalogName, mediaName string) (*govcd.Media, error) {
+	catalog, err := org.GetCatalogByName(catalogName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error finding Catalog: %s", err)
+	}
+	media, err := catalog.GetMediaByName(mediaName, false)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error retrieving cloud-init seed image '%s': %s", mediaName, err)
+	}
+	return media, nil
+}
+
+func deleteCloudInitSeedImage(ctx context.Context, org *govcd.Org, catalogName, mediaName string) error {
+	media, err := getCloudInitSeedImage(org, catalogName, mediaName)
+	if err != nil || media == nil {
+		return err
+	}
+
+	task, err := media.Delete()
+	if err == nil {
+		err = waitForTask(ctx, task)
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting cloud-init seed image '%s': %s", mediaName, err)
+	}
+	return nil
+}
+
+// cloudInitContentHash returns the SHA-256 hash of the cloud-init content
+func cloudInitContentHash(userData, metaData, networkConfig string) string {
+	hash := sha256.New()
+	for _, content := range []string{userData, metaData, networkConfig} {
+		// The length prefix keeps the hash unambiguous when content moves between files
+		_, _ = fmt.Fprintf(hash, "%d:%s", len(content), content)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cloudInitMediaName returns the catalog media name of a seed image. The name includes part of the content hash,
+// so that the new image can be uploaded before the old one is removed
+func cloudInitMediaName(vappName, vmName, contentHash string) string {
+	return fmt.Sprintf("%s-%s-cloud-init-%s.iso", vappName, vmName, contentHash[:12])
+}
+
+// cloudInitStateMediaName returns the name of the seed image recorded in state. When 'media_name' was cleared because
+// the image was ejected outside of Terraform, the name is derived from the content hash
+func cloudInitStateMediaName(d *schema.ResourceData, mediaName, contentHash string) string {
+	if mediaName != "" || contentHash == "" {
+		return mediaName
+	}
+	return cloudInitMediaName(d.Get("vapp_name").(string), d.Get("vm_name").(string), contentHash)
+}
+
+// defaultCloudInitMetaData returns the 'meta-data' used when none is given. The instance ID changes with the
+// content, so that cloud-init applies the new content on next boot
+func defaultCloudInitMetaData(vmName, contentHash string) string {
+	var metaData strings.Builder
+	metaData.WriteString("instance-id: iid-" + contentHash[:16] + "\n")
+	metaData.WriteString("local-hostname: " + vmName + "\n")
+	return metaData.String()
+}
@@ -0,0 +1,137 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestSimulatorVmCloudInitLifecycle checks that the seed image is uploaded and inserted on create, replaced
+// when the content changes, inserted again after an eject outside of Terraform, and ejected and removed on destroy
+func TestSimulatorVmCloudInitLifecycle(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVmCloudInit()
+
+	catalogId := sim.AddCatalog(simulatorOrg, "cloud-init-catalog")
+	sim.AddVapp(simulatorOrg, simulatorVdc, "web-vapp")
+	vmId := sim.AddVm(simulatorOrg, simulatorVdc, "web-vapp", "web-1")
+
+	config := func(userData string) map[string]interface{} {
+		return map[string]interface{}{
+			"org":       simulatorOrg,
+			"vdc":       simulatorVdc,
+			"vapp_name": "web-vapp",
+			"vm_name":   "web-1",
+			"catalog":   "cloud-init-catalog",
+			"user_data": userData,
+		}
+	}
+	checkMedia := func(step, mediaName string) {
+		t.Helper()
+		if inserted := sim.InsertedMedia(vmId); inserted != mediaName {
+			t.Errorf("%s: expected media %q to be inserted, got %q", step, mediaName, inserted)
+		}
+		var want []string
+		if mediaName != "" {
+			want = []string{mediaName}
+		}
+		if media := sim.CatalogMedia(catalogId); !reflect.DeepEqual(media, want) {
+			t.Errorf("%s: expected catalog media %v, got %v", step, want, media)
+		}
+	}
+
+	d := simulatorResourceData(t, resource, config("#cloud-config\npackages: [nginx]\n"))
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating cloud-init seed image: %v", diags)
+	}
+	if d.Id() != vmId {
+		t.Fatalf("expected ID %s, got %s", vmId, d.Id())
+	}
+	firstMedia := d.Get("media_name").(string)
+	checkMedia("create", firstMedia)
+
+	// planUpdate plans the given content from the state of d, and returns the data seen by the update
+	planUpdate := func(step string, d *schema.ResourceData, userData string) (*schema.ResourceData, *terraform.ResourceAttrDiff) {
+		t.Helper()
+		state := d.State()
+		diff, err := simulatorPlan(t, resource, state, config(userData), vcdClient)
+		if err != nil {
+			t.Fatalf("%s: error planning the update: %s", step, err)
+		}
+		updated, err := schema.InternalMap(resource.Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("%s: error preparing the update: %s", step, err)
+		}
+		return updated, diff.Attributes["media_name"]
+	}
+
+	// A change of content is planned as a new seed image, which replaces the inserted one
+	updated, plannedMedia := planUpdate("update", d, "#cloud-config\npackages: [nginx, git]\n")
+	if plannedMedia == nil || plannedMedia.Old != firstMedia || plannedMedia.New == firstMedia {
+		t.Fatalf("expected a new media name to be planned, got %#v", plannedMedia)
+	}
+	if diags := resource.UpdateContext(ctx, updated, vcdClient); diags.HasError() {
+		t.Fatalf("error updating cloud-init seed image: %v", diags)
+	}
+	if updated.Get("media_name").(string) != plannedMedia.New {
+		t.Errorf("expected media %s after the update, got %s", plannedMedia.New, updated.Get("media_name"))
+	}
+	secondMedia := plannedMedia.New
+	checkMedia("update", secondMedia)
+
+	if diags := resource.ReadContext(ctx, updated, vcdClient); diags.HasError() {
+		t.Fatalf("error reading cloud-init seed image: %v", diags)
+	}
+	if updated.Id() == "" {
+		t.Fatal("expected the seed image to be found after the update")
+	}
+
+	// A failed insert puts the previous seed image back, and keeps it in state
+	sim.InjectError(http.MethodPost, `/media/action/insertMedia$`, http.StatusBadRequest, "simulated insert failure")
+	failed, _ := planUpdate("failed update", updated, "#cloud-config\npackages: [nginx, git, vim]\n")
+	diags := resource.UpdateContext(ctx, failed, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "simulated insert failure") {
+		t.Fatalf("expected the injected error, got %v", diags)
+	}
+	if state := failed.State(); state.Attributes["media_name"] != secondMedia || state.Attributes["content_hash"] != updated.Get("content_hash") {
+		t.Errorf("expected the previous seed image to be kept in state, got %#v", state.Attributes)
+	}
+	checkMedia("failed update", secondMedia)
+
+	// A seed image ejected outside of Terraform is inserted again
+	org, err := vcdClient.GetOrgByName(simulatorOrg)
+	if err != nil {
+		t.Fatalf("error retrieving org: %s", err)
+	}
+	vm := simulatorVm(t, vcdClient, vmId)
+	if _, err := vm.HandleEjectMediaAndAnswer(org, "cloud-init-catalog", secondMedia, true); err != nil {
+		t.Fatalf("error ejecting the seed image: %s", err)
+	}
+	if diags := resource.ReadContext(ctx, updated, vcdClient); diags.HasError() {
+		t.Fatalf("error reading cloud-init seed image: %v", diags)
+	}
+	if updated.Id() == "" || updated.Get("media_name").(string) != "" {
+		t.Fatalf("expected the ejected seed image to be kept in state without media name, got %#v", updated.State().Attributes)
+	}
+	reinserted, plannedMedia := planUpdate("insert again", updated, "#cloud-config\npackages: [nginx, git]\n")
+	if plannedMedia == nil || plannedMedia.New != secondMedia {
+		t.Fatalf("expected media %s to be planned, got %#v", secondMedia, plannedMedia)
+	}
+	if diags := resource.UpdateContext(ctx, reinserted, vcdClient); diags.HasError() {
+		t.Fatalf("error inserting the seed image again: %v", diags)
+	}
+	checkMedia("insert again", secondMedia)
+
+	if diags := resource.DeleteContext(ctx, reinserted, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting cloud-init seed image: %v", diags)
+	}
+	checkMedia("delete", "")
+}
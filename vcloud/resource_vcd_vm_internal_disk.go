@@ -25,6 +25,7 @@ func resourceVmInternalDisk() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceVcdVmInternalDiskImport,
 		},
+		Timeouts: taskTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"org": {
 				Type:     schema.TypeString,
@@ -123,7 +124,9 @@ var internalDiskBusTypesFromValues = map[string]string{
 func resourceVmInternalDiskCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	vm, vdc, err := getVm(vcdClient, d)
@@ -166,19 +169,39 @@ func resourceVmInternalDiskCreate(ctx context.Context, d *schema.ResourceData, m
 		OverrideVmDefault:   overrideVmDefault,
 	}
 
-	vmStatusBefore, err := powerOffIfNeeded(d, vm)
+	vmStatusBefore, err := powerOffIfNeeded(ctx, d, vm)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	diskId, err := vm.AddInternalDisk(diskSetting)
+	err = vm.Refresh()
+	if err != nil {
+		return diag.Errorf("error refreshing VM: %s", err)
+	}
+	vmSpecSection := vm.VM.VmSpecSection
+	if vmSpecSection.DiskSection == nil {
+		vmSpecSection.DiskSection = &types.DiskSection{}
+	}
+	vmSpecSection.DiskSection.DiskSettings = append(vmSpecSection.DiskSection.DiskSettings, diskSetting)
+	err = updateInternalDisks(ctx, vm, vmSpecSection)
 	if err != nil {
+		// When the timeout expires, the disk being created is looked up by its position, so that it is
+		// kept in the state instead of being left behind
+		if ctx.Err() != nil {
+			if createdDisk := findInternalDiskByPosition(vm, diskSetting); createdDisk != nil {
+				d.SetId(createdDisk.DiskId)
+			}
+		}
 		return diag.Errorf("error updating VM disks: %s", err)
 	}
 
-	d.SetId(diskId)
+	createdDisk := findInternalDiskByPosition(vm, diskSetting)
+	if createdDisk == nil {
+		return diag.Errorf("created disk wasn't in list of returned VM internal disks")
+	}
+	d.SetId(createdDisk.DiskId)
 
-	err = powerOnIfNeeded(d, vm, vmStatusBefore)
+	err = powerOnIfNeeded(ctx, d, vm, vmStatusBefore)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -186,6 +209,36 @@ func resourceVmInternalDiskCreate(ctx context.Context, d *schema.ResourceData, m
 	return resourceVmInternalDiskRead(ctx, d, meta)
 }
 
+// updateInternalDisks replaces the internal disks of a VM, as vm.UpdateInternalDisks does, but waits on the
+// task with the context of the operation, so that the vApp lock is not released while the VM is changing
+func updateInternalDisks(ctx context.Context, vm *govcd.VM, vmSpecSection *types.VmSpecSection) error {
+	task, err := vm.UpdateVmSpecSectionAsync(vmSpecSection, vm.VM.Description)
+	if err != nil {
+		return err
+	}
+	err = waitForTask(ctx, task)
+	if refreshErr := vm.Refresh(); err == nil && refreshErr != nil {
+		return fmt.Errorf("error refreshing VM: %s", refreshErr)
+	}
+	return err
+}
+
+// findInternalDiskByPosition returns the internal disk of a VM that uses the adapter, bus and unit of the
+// given disk settings, or nil when there is none
+func findInternalDiskByPosition(vm *govcd.VM, position *types.DiskSettings) *types.DiskSettings {
+	if vm.VM.VmSpecSection == nil || vm.VM.VmSpecSection.DiskSection == nil {
+		return nil
+	}
+	for _, diskSetting := range vm.VM.VmSpecSection.DiskSection.DiskSettings {
+		if diskSetting.AdapterType == position.AdapterType &&
+			diskSetting.BusNumber == position.BusNumber &&
+			diskSetting.UnitNumber == position.UnitNumber {
+			return diskSetting
+		}
+	}
+	return nil
+}
+
 func getIopsValue(d *schema.ResourceData, vcdClient *VCDClient, storageProfilePrt *types.Reference) (int64, error) {
 	storageProfileDetails, err := vcdClient.GetStorageProfileByHref(storageProfilePrt.HREF)
 	if err != nil {
@@ -205,7 +258,7 @@ func getIopsValue(d *schema.ResourceData, vcdClient *VCDClient, storageProfilePr
 	return iops, nil
 }
 
-func powerOnIfNeeded(d *schema.ResourceData, vm *govcd.VM, vmStatusBefore string) error {
+func powerOnIfNeeded(ctx context.Context, d *schema.ResourceData, vm *govcd.VM, vmStatusBefore string) error {
 	vmStatus, err := vm.GetStatus()
 	if err != nil {
 		return fmt.Errorf("error getting VM status before ensuring it is powered on: %s", err)
@@ -218,7 +271,7 @@ func powerOnIfNeeded(d *schema.ResourceData, vm *govcd.VM, vmStatusBefore string
 		if err != nil {
 			return fmt.Errorf("error powering on VM for adding/updating internal disk: %s", err)
 		}
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return fmt.Errorf(errorCompletingTask, err)
 		}
@@ -226,7 +279,7 @@ func powerOnIfNeeded(d *schema.ResourceData, vm *govcd.VM, vmStatusBefore string
 	return nil
 }
 
-func powerOffIfNeeded(d *schema.ResourceData, vm *govcd.VM) (string, error) {
+func powerOffIfNeeded(ctx context.Context, d *schema.ResourceData, vm *govcd.VM) (string, error) {
 	vmStatus, err := vm.GetStatus()
 	if err != nil {
 		return "", fmt.Errorf("error getting VM status before ensuring it is powered off: %s", err)
@@ -240,7 +293,7 @@ func powerOffIfNeeded(d *schema.ResourceData, vm *govcd.VM) (string, error) {
 		if err != nil {
 			return vmStatusBefore, fmt.Errorf("error powering off VM for adding internal disk: %s", err)
 		}
-		err = task.WaitTaskCompletion()
+		err = waitForTask(ctx, task)
 		if err != nil {
 			return vmStatusBefore, fmt.Errorf(errorCompletingTask, err)
 		}
@@ -249,10 +302,12 @@ func powerOffIfNeeded(d *schema.ResourceData, vm *govcd.VM) (string, error) {
 }
 
 // resourceVmInternalDiskDelete deletes disk from VM
-func resourceVmInternalDiskDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceVmInternalDiskDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	vcdClient := m.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	vm, _, err := getVm(vcdClient, d)
@@ -260,17 +315,41 @@ func resourceVmInternalDiskDelete(_ context.Context, d *schema.ResourceData, m i
 		return diag.FromErr(err)
 	}
 
-	vmStatusBefore, err := powerOffIfNeeded(d, vm)
+	vmStatusBefore, err := powerOffIfNeeded(ctx, d, vm)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	err = vm.DeleteInternalDisk(d.Id())
+	diskId := d.Id()
+	err = vm.Refresh()
+	if err != nil {
+		return diag.Errorf("[resourceVmInternalDiskDelete] error refreshing VM: %s", err)
+	}
+	vmSpecSection := vm.VM.VmSpecSection
+	var remainingDisks []*types.DiskSettings
+	found := false
+	if vmSpecSection.DiskSection != nil {
+		for _, diskSetting := range vmSpecSection.DiskSection.DiskSettings {
+			if diskSetting.DiskId == diskId {
+				found = true
+				continue
+			}
+			remainingDisks = append(remainingDisks, diskSetting)
+		}
+	}
+	if !found {
+		return diag.Errorf("[resourceVmInternalDiskDelete] failed to delete internal disk: %s", govcd.ErrorEntityNotFound)
+	}
+	if remainingDisks == nil {
+		remainingDisks = []*types.DiskSettings{}
+	}
+	vmSpecSection.DiskSection.DiskSettings = remainingDisks
+	err = updateInternalDisks(ctx, vm, vmSpecSection)
 	if err != nil {
 		return diag.Errorf("[resourceVmInternalDiskDelete] failed to delete internal disk: %s", err)
 	}
 
-	err = powerOnIfNeeded(d, vm, vmStatusBefore)
+	err = powerOnIfNeeded(ctx, d, vm, vmStatusBefore)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -301,7 +380,9 @@ func resourceVmInternalDiskUpdate(ctx context.Context, d *schema.ResourceData, m
 	log.Printf("[TRACE] Update Internal Disk with ID: %s started.", d.Id())
 	vcdClient := meta.(*VCDClient)
 
-	vcdClient.lockParentVapp(d)
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
 	defer vcdClient.unLockParentVapp(d)
 
 	// ignore only allow_vm_reboot change, allows to avoid empty update
@@ -314,7 +395,7 @@ func resourceVmInternalDiskUpdate(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	// has refresh inside
-	vmStatusBefore, err := powerOffIfNeeded(d, vm)
+	vmStatusBefore, err := powerOffIfNeeded(ctx, d, vm)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -352,12 +433,12 @@ func resourceVmInternalDiskUpdate(ctx context.Context, d *schema.ResourceData, m
 	}
 	diskSettingsToUpdate.IopsAllocation.Reservation = iops
 
-	_, err = vm.UpdateInternalDisks(vm.VM.VmSpecSection)
+	err = updateInternalDisks(ctx, vm, vm.VM.VmSpecSection)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	err = powerOnIfNeeded(d, vm, vmStatusBefore)
+	err = powerOnIfNeeded(ctx, d, vm, vmStatusBefore)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -0,0 +1,360 @@
+package vcloud
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// VCD keeps at most one snapshot per VM. Creating a new snapshot replaces the existing one, and
+// the same actions are available on a vApp, where they apply to all its VMs at once. The
+// go-vcloud-director SDK does not wrap these actions, so the requests below are built from the
+// entity HREF.
+const (
+	snapshotCreateParamsMime = "application/vnd.vmware.vcloud.createSnapshotParams+xml"
+	snapshotActionCreate     = "/action/createSnapshot"
+	snapshotActionRevert     = "/action/revertToCurrentSnapshot"
+	snapshotActionRemoveAll  = "/action/removeAllSnapshots"
+	snapshotSectionPath      = "/snapshotSection"
+)
+
+// createSnapshotParams is the payload of the 'createSnapshot' action
+type createSnapshotParams struct {
+	XMLName     xml.Name `xml:"CreateSnapshotParams"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	Name        string   `xml:"name,attr,omitempty"`
+	Memory      bool     `xml:"memory,attr"`
+	Quiesce     bool     `xml:"quiesce,attr"`
+	Description string   `xml:"Description,omitempty"`
+}
+
+func resourceVcdVmSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVcdVmSnapshotCreate,
+		ReadContext:   resourceVcdVmSnapshotRead,
+		UpdateContext: resourceVcdVmSnapshotUpdate,
+		DeleteContext: resourceVcdVmSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVcdVmSnapshotImport,
+		},
+		Timeouts: taskTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"org": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Description: "The name of organization to use, optional if defined at provider " +
+					"level. Useful when connected as sysadmin working across different organizations",
+			},
+			"vdc": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of VDC to use, optional if defined at provider level",
+			},
+			"vapp_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The vApp this VM belongs to",
+			},
+			"vm_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "VM in vApp for which the snapshot is taken",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Name of the snapshot",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Description of the snapshot",
+			},
+			"memory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Include the memory of a powered on VM in the snapshot",
+			},
+			"quiesce": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Quiesce the guest file system before taking the snapshot (requires VMware Tools)",
+			},
+			"revert_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Any change to this value reverts the VM to the snapshot. " +
+					"Its value is not sent to VCD",
+			},
+			"created": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation date of the snapshot",
+			},
+			"powered_on": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the VM was powered on when the snapshot was taken",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Size of the snapshot in bytes",
+			},
+		},
+	}
+}
+
+func resourceVcdVmSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vm, _, err := getVm(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("[VM snapshot create] %s", err)
+	}
+
+	// Creating a snapshot would silently replace the one taken outside of this resource
+	existing, err := getSnapshot(vcdClient, vm.VM.HREF)
+	if err != nil {
+		return diag.Errorf("[VM snapshot create] error retrieving snapshot for VM '%s': %s", vm.VM.Name, err)
+	}
+	if existing != nil {
+		return diag.Errorf("[VM snapshot create] VM '%s' already has a snapshot, created on %s. "+
+			"Import it into this resource or remove it before creating a new one", vm.VM.Name, existing.Created)
+	}
+
+	err = createSnapshot(ctx, vcdClient, vm.VM.HREF, snapshotParamsFromResource(d))
+	if err != nil {
+		return diag.Errorf("[VM snapshot create] error creating snapshot for VM '%s': %s", vm.VM.Name, err)
+	}
+
+	d.SetId(vm.VM.ID)
+	return resourceVcdVmSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVmSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return genericVcdVmSnapshotRead(d, meta, "resource")
+}
+
+func genericVcdVmSnapshotRead(d *schema.ResourceData, meta interface{}, origin string) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	vm, _, err := getVm(vcdClient, d)
+	if err != nil {
+		if origin == "resource" && govcd.ContainsNotFound(err) {
+			log.Printf("[DEBUG] VM '%s' not found. Removing snapshot from state", d.Get("vm_name").(string))
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[VM snapshot read] %s", err)
+	}
+
+	snapshot, err := getSnapshot(vcdClient, vm.VM.HREF)
+	if err != nil {
+		return diag.Errorf("[VM snapshot read] error retrieving snapshot for VM '%s': %s", vm.VM.Name, err)
+	}
+	if snapshot == nil {
+		if origin == "resource" {
+			log.Printf("[DEBUG] VM '%s' has no snapshot. Removing it from state", vm.VM.Name)
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("[VM snapshot read] VM '%s' has no snapshot", vm.VM.Name)
+	}
+	// A different creation date means that the snapshot was replaced outside of Terraform
+	if created := d.Get("created").(string); origin == "resource" && created != "" && created != snapshot.Created {
+		log.Printf("[DEBUG] snapshot of VM '%s' created on %s was replaced by one created on %s. Removing it from state",
+			vm.VM.Name, created, snapshot.Created)
+		d.SetId("")
+		return nil
+	}
+
+	dSet(d, "created", snapshot.Created)
+	dSet(d, "powered_on", snapshot.PoweredOn)
+	dSet(d, "size", snapshot.Size)
+	d.SetId(vm.VM.ID)
+
+	return nil
+}
+
+// resourceVcdVmSnapshotUpdate only handles 'revert_trigger', as all other fields force a new snapshot
+func resourceVcdVmSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if !d.HasChange("revert_trigger") {
+		return resourceVcdVmSnapshotRead(ctx, d, meta)
+	}
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vm, _, err := getVm(vcdClient, d)
+	if err != nil {
+		return diag.Errorf("[VM snapshot update] %s", err)
+	}
+
+	log.Printf("[DEBUG] reverting VM '%s' to its current snapshot", vm.VM.Name)
+	err = runSnapshotAction(ctx, vcdClient, vm.VM.HREF, snapshotActionRevert)
+	if err != nil {
+		return diag.Errorf("[VM snapshot update] error reverting VM '%s' to snapshot: %s", vm.VM.Name, err)
+	}
+
+	return resourceVcdVmSnapshotRead(ctx, d, meta)
+}
+
+func resourceVcdVmSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcdClient := meta.(*VCDClient)
+
+	if err := vcdClient.lockParentVapp(d); err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcdClient.unLockParentVapp(d)
+
+	vm, _, err := getVm(vcdClient, d)
+	if err != nil {
+		if govcd.ContainsNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[VM snapshot delete] %s", err)
+	}
+
+	snapshot, err := getSnapshot(vcdClient, vm.VM.HREF)
+	if err != nil {
+		return diag.Errorf("[VM snapshot delete] error retrieving snapshot for VM '%s': %s", vm.VM.Name, err)
+	}
+	if snapshot == nil {
+		return nil
+	}
+
+	err = runSnapshotAction(ctx, vcdClient, vm.VM.HREF, snapshotActionRemoveAll)
+	if err != nil {
+		return diag.Errorf("[VM snapshot delete] error removing snapshot of VM '%s': %s", vm.VM.Name, err)
+	}
+
+	return nil
+}
+
+// resourceVcdVmSnapshotImport is responsible for importing the resource.
+// The following steps happen as part of import
+// 1. The user supplies `terraform import _resource_name_ _the_id_string_` command
+// 2. `_the_id_string_` contains a dot formatted path to resource as in the example below
+// 3. The functions splits the dot-formatted path and tries to lookup the object
+// 4. If the lookup succeeds it sets the ID field for `_resource_name_` resource in statefile
+// (the resource must be already defined in .tf config otherwise `terraform import` will complain)
+// 5. `terraform refresh` is being implicitly launched. The Read method looks up all other fields
+// based on the known ID of object.
+//
+// Example resource name (_resource_name_): vcloud_vm_snapshot.my-snapshot
+// Example import path (_the_id_string_): org-name.vdc-name.vapp-name.vm-name
+func resourceVcdVmSnapshotImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	resourceURI := strings.Split(d.Id(), ImportSeparator)
+	if len(resourceURI) != 4 {
+		return nil, fmt.Errorf("resource name must be specified as org-name.vdc-name.vapp-name.vm-name")
+	}
+	orgName, vdcName, vappName, vmName := resourceURI[0], resourceURI[1], resourceURI[2], resourceURI[3]
+
+	vcdClient := meta.(*VCDClient)
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcName)
+	if err != nil {
+		return nil, fmt.Errorf(errorRetrievingOrgAndVdc, err)
+	}
+	vapp, err := vdc.GetVAppByName(vappName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp '%s': %s", vappName, err)
+	}
+	vm, err := vapp.GetVMByName(vmName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM '%s': %s", vmName, err)
+	}
+
+	snapshot, err := getSnapshot(vcdClient, vm.VM.HREF)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving snapshot for VM '%s': %s", vmName, err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("VM '%s' has no snapshot", vmName)
+	}
+
+	dSet(d, "org", orgName)
+	dSet(d, "vdc", vdcName)
+	dSet(d, "vapp_name", vappName)
+	dSet(d, "vm_name", vmName)
+	dSet(d, "memory", false)
+	dSet(d, "quiesce", false)
+	d.SetId(vm.VM.ID)
+	return []*schema.ResourceData{d}, nil
+}
+
+// snapshotParamsFromResource builds the 'createSnapshot' payload from the fields shared by VM
+// and vApp snapshot resources
+func snapshotParamsFromResource(d *schema.ResourceData) *createSnapshotParams {
+	return &createSnapshotParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        d.Get("name").(string),
+		Memory:      d.Get("memory").(bool),
+		Quiesce:     d.Get("quiesce").(bool),
+		Description: d.Get("description").(string),
+	}
+}
+
+// createSnapshot takes a snapshot of the VM or vApp identified by entityHref and waits for the
+// task to complete
+func createSnapshot(ctx context.Context, vcdClient *VCDClient, entityHref string, params *createSnapshotParams) error {
+	task, err := vcdClient.Client.ExecuteTaskRequest(entityHref+snapshotActionCreate, http.MethodPost,
+		snapshotCreateParamsMime, "error creating snapshot: %s", params)
+	if err != nil {
+		return err
+	}
+	return waitForTask(ctx, task)
+}
+
+// runSnapshotAction runs one of the payload-less snapshot actions (revert, remove) on the VM or
+// vApp identified by entityHref and waits for the task to complete
+func runSnapshotAction(ctx context.Context, vcdClient *VCDClient, entityHref, action string) error {
+	task, err := vcdClient.Client.ExecuteTaskRequest(entityHref+action, http.MethodPost,
+		"", "error running snapshot action: %s", nil)
+	if err != nil {
+		return err
+	}
+	return waitForTask(ctx, task)
+}
+
+// getSnapshot retrieves the current snapshot of the VM identified by vmHref.
+// It returns nil without error when the VM has no snapshot
+func getSnapshot(vcdClient *VCDClient, vmHref string) (*types.SnapshotItem, error) {
+	snapshotSection := &types.SnapshotSection{}
+	_, err := vcdClient.Client.ExecuteRequest(vmHref+snapshotSectionPath, http.MethodGet,
+		"", "error retrieving snapshot section: %s", nil, snapshotSection)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshotSection.Snapshot) == 0 {
+		return nil, nil
+	}
+	return snapshotSection.Snapshot[0], nil
+}
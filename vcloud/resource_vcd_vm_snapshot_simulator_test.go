@@ -0,0 +1,87 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorVmSnapshotLifecycle checks the creation, import, replacement and deletion of a VM snapshot
+func TestSimulatorVmSnapshotLifecycle(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVmSnapshot()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "snapshot-vapp")
+	vmId := sim.AddVm(simulatorOrg, simulatorVdc, "snapshot-vapp", "snapshot-vm")
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":       simulatorOrg,
+		"vdc":       simulatorVdc,
+		"vapp_name": "snapshot-vapp",
+		"vm_name":   "snapshot-vm",
+		"name":      "before-upgrade",
+	})
+	if diags := resource.CreateContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error creating snapshot: %v", diags)
+	}
+	if sim.Snapshot(vmId) == nil {
+		t.Fatal("expected the VM to have a snapshot")
+	}
+	if d.Get("created").(string) == "" {
+		t.Fatal("expected the snapshot creation date to be set")
+	}
+
+	imported := importSimulatorResource(t, resource,
+		strings.Join([]string{simulatorOrg, simulatorVdc, "snapshot-vapp", "snapshot-vm"}, ImportSeparator), vcdClient)
+	if imported.Id() != d.Id() {
+		t.Fatalf("expected imported snapshot ID %s, got %s", d.Id(), imported.Id())
+	}
+
+	// A second snapshot of the same VM would replace the first one
+	second := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":       simulatorOrg,
+		"vdc":       simulatorVdc,
+		"vapp_name": "snapshot-vapp",
+		"vm_name":   "snapshot-vm",
+		"name":      "after-upgrade",
+	})
+	diags := resource.CreateContext(ctx, second, vcdClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "already has a snapshot") {
+		t.Fatalf("expected the creation of a second snapshot to fail, got %v", diags)
+	}
+	if created := sim.Snapshot(vmId).Created; created != d.Get("created").(string) {
+		t.Fatalf("expected the snapshot created on %s to be kept, got one created on %s", d.Get("created"), created)
+	}
+
+	// A snapshot replaced outside of Terraform is removed from state, and can be imported again
+	vm := simulatorVm(t, vcdClient, vmId)
+	err := createSnapshot(ctx, vcdClient, vm.VM.HREF, &createSnapshotParams{Xmlns: types.XMLNamespaceVCloud})
+	if err != nil {
+		t.Fatalf("error replacing the snapshot: %s", err)
+	}
+	if diags := resource.ReadContext(ctx, imported, vcdClient); diags.HasError() {
+		t.Fatalf("error reading snapshot: %v", diags)
+	}
+	if imported.Id() == "" {
+		t.Fatal("expected an import of the new snapshot to be kept in state")
+	}
+	if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error reading snapshot: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the replaced snapshot to be removed from state")
+	}
+	d.SetId(imported.Id())
+
+	if diags := resource.DeleteContext(ctx, d, vcdClient); diags.HasError() {
+		t.Fatalf("error deleting snapshot: %v", diags)
+	}
+	if sim.Snapshot(vmId) != nil {
+		t.Fatal("expected the snapshot to be removed")
+	}
+}
@@ -0,0 +1,240 @@
+//go:build vapp || vm || ALL || functional
+
+package vcloud
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccVcdVmSnapshot(t *testing.T) {
+	preTestChecks(t)
+
+	vappName := t.Name() + "-vapp"
+	vmName := t.Name() + "-vm"
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.Nsxt.Vdc,
+		"Catalog":     testSuiteCatalogName,
+		"CatalogItem": testSuiteCatalogOVAItem,
+		"VappName":    vappName,
+		"VmName":      vmName,
+		"Trigger":     "initial",
+		"FuncName":    t.Name(),
+		"Tags":        "vapp vm",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVmSnapshot, params)
+	params["FuncName"] = t.Name() + "-revert"
+	params["Trigger"] = "revert-1"
+	configTextRevert := templateFill(testAccVcdVmSnapshot, params)
+	params["FuncName"] = t.Name() + "-DS"
+	configTextDS := templateFill(testAccVcdVmSnapshot+testAccVcdVmSnapshotDS, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n%s\n%s", configText, configTextRevert, configTextDS)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceName := "vcloud_vm_snapshot.snap"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckVcdVmSnapshotDestroyed(vappName, vmName),
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName, "id", "vcloud_vapp_vm.vm", "id"),
+					resource.TestCheckResourceAttr(resourceName, "name", "snap-1"),
+					resource.TestCheckResourceAttr(resourceName, "memory", "false"),
+					resource.TestCheckResourceAttr(resourceName, "quiesce", "false"),
+					resource.TestCheckResourceAttrSet(resourceName, "created"),
+				),
+			},
+			{
+				Config: configTextRevert,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "revert_trigger", "revert-1"),
+					resource.TestCheckResourceAttrSet(resourceName, "created"),
+				),
+			},
+			{
+				Config: configTextDS,
+				Check: resource.ComposeTestCheckFunc(
+					resourceFieldsEqual(resourceName, "data.vcloud_vm_snapshot.snap", []string{"%", "name", "description",
+						"memory", "quiesce", "revert_trigger"}),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateIdFunc:       importCustomObject([]string{testConfig.VCD.Org, testConfig.Nsxt.Vdc, vappName, vmName}),
+				ImportStateVerifyIgnore: []string{"org", "vdc", "name", "description", "revert_trigger"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+func testAccCheckVcdVmSnapshotDestroyed(vappName, vmName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*VCDClient)
+		_, vdc, err := conn.GetOrgAndVdc(testConfig.VCD.Org, testConfig.Nsxt.Vdc)
+		if err != nil {
+			return fmt.Errorf(errorRetrievingVdcFromOrg, testConfig.Nsxt.Vdc, testConfig.VCD.Org, err)
+		}
+		vapp, err := vdc.GetVAppByName(vappName, false)
+		if err != nil {
+			// the vApp was removed together with its VM and snapshot
+			return nil
+		}
+		vm, err := vapp.GetVMByName(vmName, false)
+		if err != nil {
+			return nil
+		}
+		snapshot, err := getSnapshot(conn, vm.VM.HREF)
+		if err != nil {
+			return err
+		}
+		if snapshot != nil {
+			return fmt.Errorf("snapshot of VM %s was not removed", vmName)
+		}
+		return nil
+	}
+}
+
+const testAccVcdVmSnapshot = `
+resource "vcloud_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcloud_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcloud_vapp.vapp.name
+  name             = "{{.VmName}}"
+  catalog_name     = "{{.Catalog}}"
+  template_name    = "{{.CatalogItem}}"
+  memory           = 1024
+  cpus             = 1
+  power_on         = false
+}
+
+resource "vcloud_vm_snapshot" "snap" {
+  org            = "{{.Org}}"
+  vdc            = "{{.Vdc}}"
+  vapp_name      = vcloud_vapp.vapp.name
+  vm_name        = vcloud_vapp_vm.vm.name
+  name           = "snap-1"
+  description    = "{{.FuncName}}"
+  revert_trigger = "{{.Trigger}}"
+}
+`
+
+// TestAccVcdVappSnapshot takes a snapshot of all the VMs in a vApp
+func TestAccVcdVappSnapshot(t *testing.T) {
+	preTestChecks(t)
+
+	vappName := t.Name() + "-vapp"
+	var params = StringMap{
+		"Org":         testConfig.VCD.Org,
+		"Vdc":         testConfig.Nsxt.Vdc,
+		"Catalog":     testSuiteCatalogName,
+		"CatalogItem": testSuiteCatalogOVAItem,
+		"VappName":    vappName,
+		"VmName":      t.Name() + "-vm",
+		"Trigger":     "initial",
+		"FuncName":    t.Name(),
+		"Tags":        "vapp vm",
+	}
+	testParamsNotEmpty(t, params)
+
+	configText := templateFill(testAccVcdVappSnapshot, params)
+	params["FuncName"] = t.Name() + "-revert"
+	params["Trigger"] = "revert-1"
+	configTextRevert := templateFill(testAccVcdVappSnapshot, params)
+	debugPrintf("#[DEBUG] CONFIGURATION: %s\n%s", configText, configTextRevert)
+
+	if vcdShortTest {
+		t.Skip(acceptanceTestsSkipped)
+		return
+	}
+
+	resourceName := "vcloud_vapp_snapshot.snap"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: configText,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName, "id", "vcloud_vapp.vapp", "id"),
+					resource.TestCheckResourceAttr(resourceName, "vm_snapshot.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair(resourceName, "vm_snapshot.*.vm_id", "vcloud_vapp_vm.vm", "id"),
+				),
+			},
+			{
+				Config: configTextRevert,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "revert_trigger", "revert-1"),
+					resource.TestCheckResourceAttr(resourceName, "vm_snapshot.#", "1"),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateIdFunc:       importCustomObject([]string{testConfig.VCD.Org, testConfig.Nsxt.Vdc, vappName}),
+				ImportStateVerifyIgnore: []string{"org", "vdc", "name", "description", "revert_trigger"},
+			},
+		},
+	})
+	postTestChecks(t)
+}
+
+const testAccVcdVappSnapshot = `
+resource "vcloud_vapp" "vapp" {
+  org  = "{{.Org}}"
+  vdc  = "{{.Vdc}}"
+  name = "{{.VappName}}"
+}
+
+resource "vcloud_vapp_vm" "vm" {
+  org              = "{{.Org}}"
+  vdc              = "{{.Vdc}}"
+  vapp_name        = vcloud_vapp.vapp.name
+  name             = "{{.VmName}}"
+  catalog_name     = "{{.Catalog}}"
+  template_name    = "{{.CatalogItem}}"
+  memory           = 1024
+  cpus             = 1
+  power_on         = false
+}
+
+resource "vcloud_vapp_snapshot" "snap" {
+  org            = "{{.Org}}"
+  vdc            = "{{.Vdc}}"
+  vapp_name      = vcloud_vapp.vapp.name
+  name           = "vapp-snap-1"
+  description    = "{{.FuncName}}"
+  revert_trigger = "{{.Trigger}}"
+
+  depends_on = [vcloud_vapp_vm.vm]
+}
+`
+
+const testAccVcdVmSnapshotDS = `
+data "vcloud_vm_snapshot" "snap" {
+  org       = "{{.Org}}"
+  vdc       = "{{.Vdc}}"
+  vapp_name = vcloud_vm_snapshot.snap.vapp_name
+  vm_name   = vcloud_vm_snapshot.snap.vm_name
+}
+`
@@ -0,0 +1,173 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/viettelidc-provider/terraform-provider-vcloud/v3/internal/vcdsim"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+)
+
+// These tests run resource and data source operations against a local VCD simulator, with a
+// simulator for each test. They don't need a live VCD nor the terraform binary.
+
+// newSimulatorClient starts a simulator with the same entities as the one started by TestMain in
+// simulator mode, and returns a provider client connected to it, configured with the provider defaults.
+// The options can change the configuration before connecting
+func newSimulatorClient(t *testing.T, options ...func(*Config)) (*vcdsim.Simulator, *VCDClient) {
+	sim, config := startTestSimulator()
+	t.Cleanup(func() {
+		sim.Close()
+		if unhandled := sim.Unhandled(); len(unhandled) > 0 {
+			t.Errorf("requests not handled by the simulator:\n%s", strings.Join(unhandled, "\n"))
+		}
+	})
+
+	providerConfig := Config{
+		User:            config.Provider.User,
+		Password:        config.Provider.Password,
+		SysOrg:          config.Provider.SysOrg,
+		Org:             config.VCD.Org,
+		Vdc:             config.VCD.Vdc,
+		Href:            config.Provider.Url,
+		MaxRetryTimeout: config.Provider.MaxRetryTimeout,
+		InsecureFlag:    config.Provider.AllowInsecure,
+		RetryOnStatus:   defaultRetryOnStatus,
+		LookupCache:     true,
+	}
+	for _, option := range options {
+		option(&providerConfig)
+	}
+	vcdClient, err := providerConfig.Client()
+	if err != nil {
+		t.Fatalf("error connecting to the simulator: %s", err)
+	}
+	return sim, vcdClient
+}
+
+// simulatorResourceData returns the data of a resource or data source configured with the given values
+func simulatorResourceData(t *testing.T, resource *schema.Resource, values map[string]interface{}) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resource.Schema, values)
+}
+
+// simulatorUpdateData returns the data of a resource moving from the state in d to the given values,
+// as seen by an update operation
+func simulatorUpdateData(t *testing.T, resource *schema.Resource, d *schema.ResourceData, values map[string]interface{}) *schema.ResourceData {
+	internalMap := schema.InternalMap(resource.Schema)
+	state := d.State()
+	diff, err := internalMap.Diff(context.Background(), state, terraform.NewResourceConfigRaw(values), nil, nil, true)
+	if err != nil {
+		t.Fatalf("error computing the update of %s: %s", d.Id(), err)
+	}
+	updated, err := internalMap.Data(state, diff)
+	if err != nil {
+		t.Fatalf("error preparing the update of %s: %s", d.Id(), err)
+	}
+	return updated
+}
+
+// simulatorPlan returns the plan of a resource from the state to the given values, including its CustomizeDiff.
+// As Terraform does, the plan gets the raw configuration along with the state
+func simulatorPlan(t *testing.T, resource *schema.Resource, state *terraform.InstanceState, values map[string]interface{},
+	vcdClient *VCDClient) (*terraform.InstanceDiff, error) {
+	internalMap := schema.InternalMap(resource.Schema)
+	configSchema := internalMap.CoreConfigSchema()
+	rawConfig, err := configSchema.CoerceValue(simulatorCtyValue(t, values))
+	if err != nil {
+		t.Fatalf("error building the configuration: %s", err)
+	}
+	if state == nil {
+		state = &terraform.InstanceState{}
+	}
+	state.RawConfig = rawConfig
+	return internalMap.Diff(context.Background(), state, terraform.NewResourceConfigShimmed(rawConfig, configSchema),
+		resource.CustomizeDiff, vcdClient, true)
+}
+
+// simulatorCtyValue converts configuration values to cty values. Lists become tuples, which the schema
+// coerces to lists or sets
+func simulatorCtyValue(t *testing.T, value interface{}) cty.Value {
+	switch v := value.(type) {
+	case string:
+		return cty.StringVal(v)
+	case int:
+		return cty.NumberIntVal(int64(v))
+	case bool:
+		return cty.BoolVal(v)
+	case []interface{}:
+		items := make([]cty.Value, len(v))
+		for i, item := range v {
+			items[i] = simulatorCtyValue(t, item)
+		}
+		return cty.TupleVal(items)
+	case map[string]interface{}:
+		attributes := make(map[string]cty.Value, len(v))
+		for key, item := range v {
+			attributes[key] = simulatorCtyValue(t, item)
+		}
+		return cty.ObjectVal(attributes)
+	}
+	t.Fatalf("unhandled configuration value %#v", value)
+	return cty.NilVal
+}
+
+// importSimulatorResource runs the importer of a resource with the given import ID, and returns the
+// imported data
+func importSimulatorResource(t *testing.T, resource *schema.Resource, importId string, vcdClient *VCDClient) *schema.ResourceData {
+	// As in Terraform, the importer gets no configuration, so that attributes with a default are not set
+	d := resource.Data(&terraform.InstanceState{ID: importId})
+	imported, err := resource.Importer.StateContext(context.Background(), d, vcdClient)
+	if err != nil {
+		t.Fatalf("error importing %s: %s", importId, err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("expected 1 imported entity, got %d", len(imported))
+	}
+	return imported[0]
+}
+
+// simulatorVapp returns the vApp with the given name in the default VDC of the simulator
+func simulatorVapp(t *testing.T, vcdClient *VCDClient, name string) *govcd.VApp {
+	t.Helper()
+	_, vdc, err := vcdClient.GetOrgAndVdc(simulatorOrg, simulatorVdc)
+	if err != nil {
+		t.Fatalf("error retrieving VDC %s: %s", simulatorVdc, err)
+	}
+	vapp, err := vdc.GetVAppByName(name, true)
+	if err != nil {
+		t.Fatalf("error retrieving vApp %s: %s", name, err)
+	}
+	return vapp
+}
+
+// simulatorVm returns the VM with the given ID in the default VDC of the simulator
+func simulatorVm(t *testing.T, vcdClient *VCDClient, vmId string) *govcd.VM {
+	t.Helper()
+	_, vdc, err := vcdClient.GetOrgAndVdc(simulatorOrg, simulatorVdc)
+	if err != nil {
+		t.Fatalf("error retrieving VDC %s: %s", simulatorVdc, err)
+	}
+	vm, err := vdc.QueryVmById(vmId)
+	if err != nil {
+		t.Fatalf("error retrieving VM %s: %s", vmId, err)
+	}
+	return vm
+}
+
+// powerOnSimulatorVm powers on a VM and waits for the task to complete
+func powerOnSimulatorVm(t *testing.T, vm *govcd.VM) {
+	t.Helper()
+	task, err := vm.PowerOn()
+	if err == nil {
+		err = task.WaitTaskCompletion()
+	}
+	if err != nil {
+		t.Fatalf("error powering on VM %s: %s", vm.VM.Name, err)
+	}
+}
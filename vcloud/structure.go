@@ -279,11 +279,11 @@ func areMarshaledJsonEqual(json1, json2 []byte) (bool, error) {
 // createOrUpdateMetadata creates or updates metadata entries for the given resource and attribute name
 // TODO: This function implementation should be replaced with the implementation of `createOrUpdateMetadataEntryInVcd`
 // once "metadata" field is removed.
-func createOrUpdateMetadata(d *schema.ResourceData, resource metadataCompatible, attributeName string) error {
+func createOrUpdateMetadata(d *schema.ResourceData, vcdClient *VCDClient, resource metadataCompatible, attributeName string) error {
 	// We invoke the new "metadata_entry" metadata creation here to have it centralized and reduce duplication.
 	// Ideally, once "metadata" is removed in a new major version, the implementation of `createOrUpdateMetadataEntryInVcd` should
 	// just go here in the `createOrUpdateMetadata` body.
-	err := createOrUpdateMetadataEntryInVcd(d, resource)
+	err := createOrUpdateMetadataEntryInVcd(d, vcdClient, resource)
 	if err != nil {
 		return err
 	}
@@ -296,7 +296,7 @@ func createOrUpdateMetadata(d *schema.ResourceData, resource metadataCompatible,
 		// Check if any key in old metadata was removed in new metadata.
 		// Creates a list of keys to be removed.
 		for k := range oldMetadata {
-			if _, ok := newMetadata[k]; !ok {
+			if _, ok := newMetadata[k]; !ok && k != distributedLockKey {
 				toBeRemovedMetadata = append(toBeRemovedMetadata, k)
 			}
 		}
@@ -306,8 +306,15 @@ func createOrUpdateMetadata(d *schema.ResourceData, resource metadataCompatible,
 				return fmt.Errorf("error deleting metadata: %s", err)
 			}
 		}
-		if len(newMetadata) > 0 {
-			err = resource.MergeMetadata(types.MetadataStringValue, newMetadata)
+		// The lease of a distributed lock is only written by the lock itself
+		metadataToMerge := make(map[string]interface{}, len(newMetadata))
+		for k, v := range newMetadata {
+			if k != distributedLockKey {
+				metadataToMerge[k] = v
+			}
+		}
+		if len(metadataToMerge) > 0 {
+			err = resource.MergeMetadata(types.MetadataStringValue, metadataToMerge)
 			if err != nil {
 				return fmt.Errorf("error adding metadata: %s", err)
 			}
@@ -0,0 +1,120 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// defaultTaskTimeout is the default `timeouts` value of resources that wait on VCD tasks. It is
+// generous, as task waits had no limit before the timeouts could be configured.
+const defaultTaskTimeout = 180 * time.Minute
+
+// taskWaitDelay is the time between two checks of a running task
+const taskWaitDelay = 3 * time.Second
+
+// taskTimeouts returns the create, update and delete timeouts of resources that wait on VCD tasks
+func taskTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTaskTimeout),
+		Update: schema.DefaultTimeout(defaultTaskTimeout),
+		Delete: schema.DefaultTimeout(defaultTaskTimeout),
+	}
+}
+
+// taskTimeoutsNoUpdate returns the create and delete timeouts of resources that wait on VCD tasks,
+// but can't be updated
+func taskTimeoutsNoUpdate() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTaskTimeout),
+		Delete: schema.DefaultTimeout(defaultTaskTimeout),
+	}
+}
+
+// waitForTask waits for a VCD task to finish, like task.WaitTaskCompletion, but gives up when the
+// context is done. The task is not cancelled, and keeps running in VCD.
+func waitForTask(ctx context.Context, task govcd.Task) error {
+	if task.Task == nil {
+		return fmt.Errorf("cannot wait for task: task is empty")
+	}
+	for {
+		err := task.Refresh()
+		if err != nil {
+			return fmt.Errorf("error retrieving task: %s", err)
+		}
+		switch task.Task.Status {
+		case "running", "preRunning", "queued":
+		case "error":
+			errorMessage := ""
+			if task.Task.Error != nil {
+				errorMessage = task.Task.Error.Message
+			}
+			return fmt.Errorf("task did not complete successfully: %s", errorMessage)
+		default:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task '%s' (%s) did not complete in time: %s", task.Task.Operation, task.Task.HREF, ctx.Err())
+		case <-time.After(taskWaitDelay):
+		}
+	}
+}
+
+// waitForTasks waits for the tasks that VCD lists in an entity it has just started changing, such as
+// the vApp returned by a compose, instantiate or clone request
+func waitForTasks(ctx context.Context, client *govcd.Client, tasks *types.TasksInProgress) error {
+	if tasks == nil {
+		return nil
+	}
+	for _, taskInProgress := range tasks.Task {
+		if taskInProgress == nil {
+			continue
+		}
+		task := govcd.NewTask(client)
+		task.Task = taskInProgress
+		err := waitForTask(ctx, *task)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runWithContext runs a go-vcloud-director call that waits on VCD tasks without returning them, and
+// returns early when the context is done. The call, and the VCD tasks it started, keep running in the
+// background. It is only meant for operations that have no asynchronous variant: when a task is
+// available, use waitForTask, so that the entity being created is saved in the state before waiting.
+// Callers creating entities must look them up when an error is returned, so that a timeout does not
+// leave them behind.
+func runWithContext(ctx context.Context, operation func() error) error {
+	_, err := callWithContext(ctx, func() (struct{}, error) {
+		return struct{}{}, operation()
+	})
+	return err
+}
+
+// callWithContext is the same as runWithContext, for operations returning a value
+func callWithContext[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+	type outcome struct {
+		value T
+		err   error
+	}
+	result := make(chan outcome, 1)
+	go func() {
+		value, err := operation()
+		result <- outcome{value, err}
+	}()
+	select {
+	case r := <-result:
+		return r.value, r.err
+	case <-ctx.Done():
+		var empty T
+		return empty, fmt.Errorf("operation did not complete in time: %s", ctx.Err())
+	}
+}
@@ -0,0 +1,37 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSimulatorCreateTimeoutKeepsId checks that a vApp still being created when the create timeout expires is
+// kept in the state, so that Terraform marks it as tainted instead of leaving it behind
+func TestSimulatorCreateTimeoutKeepsId(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	resource := resourceVcdVApp()
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org":  simulatorOrg,
+		"vdc":  simulatorVdc,
+		"name": "slow-vapp",
+	})
+
+	sim.StallNextTask("vdcComposeVapp")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	diags := resource.CreateContext(ctx, d, vcdClient)
+	if !diags.HasError() {
+		t.Fatal("expected the vApp creation to time out")
+	}
+	if d.Id() == "" {
+		t.Fatal("expected the ID of the vApp being created to be kept after the timeout")
+	}
+
+	vapp := simulatorVapp(t, vcdClient, "slow-vapp")
+	if vapp.VApp.ID != d.Id() {
+		t.Fatalf("expected ID %s, got %s", vapp.VApp.ID, d.Id())
+	}
+}
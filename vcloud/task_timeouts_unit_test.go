@@ -0,0 +1,69 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Test_callWithContext checks that operations return their own results, unless the context is done first
+func Test_callWithContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		duration  time.Duration
+		opError   error
+		want      string
+		wantError bool
+	}{
+		{
+			name:     "completes in time",
+			timeout:  time.Second,
+			duration: 0,
+			want:     "done",
+		},
+		{
+			name:      "fails in time",
+			timeout:   time.Second,
+			duration:  0,
+			opError:   fmt.Errorf("operation error"),
+			want:      "done",
+			wantError: true,
+		},
+		{
+			name:      "context deadline expires",
+			timeout:   10 * time.Millisecond,
+			duration:  time.Second,
+			want:      "",
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			got, err := callWithContext(ctx, func() (string, error) {
+				time.Sleep(tt.duration)
+				return "done", tt.opError
+			})
+			if (err != nil) != tt.wantError {
+				t.Errorf("callWithContext() error = %v, wantError %v", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("callWithContext() = %q, want %q", got, tt.want)
+			}
+
+			err = runWithContext(ctx, func() error {
+				time.Sleep(tt.duration)
+				return tt.opError
+			})
+			if (err != nil) != tt.wantError {
+				t.Errorf("runWithContext() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -196,6 +196,12 @@ func validateAlphanumericWithUnderscoresAndHyphens() schema.SchemaValidateFunc {
 	return validation.StringMatch(regexp.MustCompile(`(?i)^[a-z0-9_-]+$`), "only alphanumeric characters, underscores and hyphens allowed")
 }
 
+// validateSha256 returns a SchemaValidateFunc that tests whether the target attribute is a SHA-256
+// checksum written as 64 hexadecimal characters
+func validateSha256() schema.SchemaValidateFunc {
+	return validation.StringMatch(regexp.MustCompile(`^[0-9a-fA-F]{64}$`), "must be a SHA-256 checksum of 64 hexadecimal characters")
+}
+
 // allowTokenFileIfIsBoolAndTrue checks if the 'allow_token_file' is set to true, otherwise returns
 // a descriptive error
 func allowTokenFileIfIsBoolAndTrue() schema.SchemaValidateDiagFunc {
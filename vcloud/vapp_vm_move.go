@@ -0,0 +1,282 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// VMs are renamed and moved in place, keeping their ID, disks and NICs (and so their MAC addresses):
+// * a rename is a `reconfigureVm` operation, which works while the VM is running
+// * a move to another vApp, in the same VDC or in another VDC of the same Org, is a recompose of the
+//   destination vApp with the VM as source item and `sourceDelete`, which VCD runs as a move. The VM
+//   must be powered off
+// VCD can't move a VM to another Org. In that case, the VM is replaced.
+// A standalone VM that changes VDC is replaced too. The recompose would make it a VM of a vApp of the other
+// VDC, and the VCD operation that moves a whole vApp, with the hidden vApp of a standalone VM, to another VDC
+// is not available in go-vcloud-director.
+// The plan replaces a vApp VM instead of moving it when the move would fail: when a network of its NICs
+// doesn't exist in the destination vApp, or its storage profile doesn't exist in the destination VDC. A
+// destination VDC or vApp created by the same apply can't be checked.
+
+// vmMoveCustomizeDiff shows the move of a VM to another vApp or VDC in the plan, by marking `vapp_id`
+// as unknown, and requires the replacement of the VM when VCD can't move it
+func vmMoveCustomizeDiff(vmType typeOfVm) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() == "" || !d.HasChanges("vapp_name", "vdc") {
+			return nil
+		}
+		vcdClient := meta.(*VCDClient)
+		oldVdc, newVdc := d.GetChange("vdc")
+		vdcChanged := vdcNameOrDefault(vcdClient, oldVdc.(string)) != vdcNameOrDefault(vcdClient, newVdc.(string))
+
+		if vmType == standaloneVmType {
+			if vdcChanged {
+				return d.ForceNew("vdc")
+			}
+			return nil
+		}
+		oldVapp, newVapp := d.GetChange("vapp_name")
+		if !vdcChanged && oldVapp.(string) == newVapp.(string) {
+			return nil
+		}
+		if d.NewValueKnown("vapp_name") && d.NewValueKnown("vdc") {
+			reason, err := vmMoveBlocker(vcdClient, d, vdcChanged)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				log.Printf("[INFO] VM %s can't move to vApp '%s' and will be replaced: %s", d.Id(), newVapp, reason)
+				if d.HasChange("vapp_name") {
+					return d.ForceNew("vapp_name")
+				}
+				return d.ForceNew("vdc")
+			}
+		}
+		log.Printf("[DEBUG] VM %s will move from vApp '%s' to vApp '%s' (VDC changed: %t)", d.Id(), oldVapp, newVapp, vdcChanged)
+		return d.SetNewComputed("vapp_id")
+	}
+}
+
+// vmMoveBlocker returns the reason why VCD can't move a vApp VM to the destination vApp and VDC, or an
+// empty string when the move can go ahead. The networks of the NICs are the ones in the state, as the
+// move happens before the update of the NICs
+func vmMoveBlocker(vcdClient *VCDClient, d *schema.ResourceDiff, vdcChanged bool) (string, error) {
+	orgName := d.Get("org").(string)
+	if orgName == "" {
+		orgName = vcdClient.Org
+	}
+	targetVdcName := vdcNameOrDefault(vcdClient, d.Get("vdc").(string))
+	targetVappName := d.Get("vapp_name").(string)
+	_, targetVdc, err := vcdClient.GetOrgAndVdc(orgName, targetVdcName)
+	if govcd.ContainsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("[VM move] error retrieving destination VDC '%s': %s", targetVdcName, err)
+	}
+
+	storageProfileName := d.Get("storage_profile").(string)
+	if vdcChanged && storageProfileName != "" {
+		_, err = targetVdc.FindStorageProfileReference(storageProfileName)
+		if err != nil {
+			return fmt.Sprintf("storage profile '%s' doesn't exist in VDC '%s'", storageProfileName, targetVdcName), nil
+		}
+	}
+
+	targetVapp, err := targetVdc.GetVAppByName(targetVappName, false)
+	if govcd.ContainsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("[VM move] error retrieving destination vApp '%s': %s", targetVappName, err)
+	}
+	networkConfig, err := targetVapp.GetNetworkConfig()
+	if err != nil {
+		return "", fmt.Errorf("[VM move] error retrieving networks of vApp '%s': %s", targetVappName, err)
+	}
+	vappNetworks := make(map[string]bool)
+	for _, network := range networkConfig.NetworkConfig {
+		vappNetworks[network.NetworkName] = true
+	}
+	oldNetworks, _ := d.GetChange("network")
+	for _, item := range oldNetworks.([]interface{}) {
+		nic, ok := item.(map[string]interface{})
+		if !ok || nic["type"].(string) == "none" || nic["name"].(string) == "" {
+			continue
+		}
+		if !vappNetworks[nic["name"].(string)] {
+			return fmt.Sprintf("network '%s' doesn't exist in vApp '%s'", nic["name"], targetVappName), nil
+		}
+	}
+	return "", nil
+}
+
+// vdcNameOrDefault returns the given VDC name, or the provider VDC when it is empty
+func vdcNameOrDefault(vcdClient *VCDClient, vdcName string) string {
+	if vdcName == "" {
+		return vcdClient.Vdc
+	}
+	return vdcName
+}
+
+// vmMoveLockKeys returns the lock keys of the vApps that a VM update involves: the parent vApp and,
+// when the VM moves, the previous one. The keys are sorted, so that VMs moving in opposite directions
+// at the same time can't wait on each other
+func vmMoveLockKeys(vcdClient *VCDClient, d *schema.ResourceData) []string {
+	orgName := vcdClient.getOrgName(d)
+	oldVdc, newVdc := d.GetChange("vdc")
+	oldVapp, newVapp := d.GetChange("vapp_name")
+	newKey := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", orgName, vdcNameOrDefault(vcdClient, newVdc.(string)), newVapp)
+	oldKey := fmt.Sprintf("org:%s|vdc:%s|vapp:%s", orgName, vdcNameOrDefault(vcdClient, oldVdc.(string)), oldVapp)
+	if oldVapp.(string) == "" || oldKey == newKey {
+		return []string{newKey}
+	}
+	if oldKey < newKey {
+		return []string{oldKey, newKey}
+	}
+	return []string{newKey, oldKey}
+}
+
+// moveVm moves a vApp VM to the vApp and VDC set in the resource, when they differ from the ones in
+// the state. A running VM is powered off first, unless `prevent_update_power_off` is set. The update
+// powers it on again afterwards, according to `power_on`
+func moveVm(ctx context.Context, d *schema.ResourceData, vcdClient *VCDClient) error {
+	oldVdcName, newVdcName := d.GetChange("vdc")
+	oldVappName, newVappName := d.GetChange("vapp_name")
+	sourceVdcName := vdcNameOrDefault(vcdClient, oldVdcName.(string))
+	targetVdcName := vdcNameOrDefault(vcdClient, newVdcName.(string))
+	if sourceVdcName == targetVdcName && oldVappName.(string) == newVappName.(string) {
+		return nil
+	}
+
+	orgName := vcdClient.getOrgName(d)
+	_, sourceVdc, err := vcdClient.GetOrgAndVdc(orgName, sourceVdcName)
+	if err != nil {
+		return fmt.Errorf("[VM move] error retrieving source VDC '%s': %s", sourceVdcName, err)
+	}
+	sourceVapp, err := sourceVdc.GetVAppByName(oldVappName.(string), false)
+	if err != nil {
+		return fmt.Errorf("[VM move] error retrieving source vApp '%s': %s", oldVappName, err)
+	}
+	vm, err := sourceVapp.GetVMById(d.Id(), false)
+	if err != nil {
+		return fmt.Errorf("[VM move] error retrieving VM %s in vApp '%s': %s", d.Id(), oldVappName, err)
+	}
+
+	targetVdc := sourceVdc
+	if targetVdcName != sourceVdcName {
+		_, targetVdc, err = vcdClient.GetOrgAndVdc(orgName, targetVdcName)
+		if err != nil {
+			return fmt.Errorf("[VM move] error retrieving destination VDC '%s': %s", targetVdcName, err)
+		}
+	}
+	targetVapp, err := targetVdc.GetVAppByName(newVappName.(string), false)
+	if err != nil {
+		return fmt.Errorf("[VM move] error retrieving destination vApp '%s': %s", newVappName, err)
+	}
+
+	status, err := vm.GetStatus()
+	if err != nil {
+		return fmt.Errorf("[VM move] error getting status of VM %s: %s", vm.VM.Name, err)
+	}
+	// GetStatus refreshes the VM, so that Deployed is current
+	if status != "POWERED_OFF" || vm.VM.Deployed {
+		if d.Get("prevent_update_power_off").(bool) {
+			return fmt.Errorf("update stopped: VM needs to power off to move to vApp '%s', but `prevent_update_power_off` is `true`", newVappName)
+		}
+		log.Printf("[DEBUG] [VM move] un-deploying VM %s before moving it. Previous state %s", vm.VM.Name, status)
+		_, shutdownTimeout := getPowerOffMode(d)
+		err = undeployVm(ctx, vcdClient, vm, vmUpdatePowerOffMode(d, true), shutdownTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	sourcedItem := &types.SourcedCompositionItemParam{
+		SourceDelete: true,
+		Source: &types.Reference{
+			HREF: vm.VM.HREF,
+			Name: vm.VM.Name,
+		},
+	}
+	// Keeping the NICs as they are preserves their MAC addresses. The networks must exist in the destination vApp
+	if vm.VM.NetworkConnectionSection != nil {
+		sourcedItem.InstantiationParams = &types.InstantiationParams{
+			NetworkConnectionSection: vm.VM.NetworkConnectionSection,
+		}
+	}
+	// The storage profile of the VM may not exist in another VDC. Without one, the VM gets the default of the VDC
+	if storageProfileName := d.Get("storage_profile").(string); storageProfileName != "" && targetVdc != sourceVdc {
+		storageProfile, err := targetVdc.FindStorageProfileReference(storageProfileName)
+		if err != nil {
+			return fmt.Errorf("[VM move] error retrieving storage profile '%s' in VDC '%s': %s", storageProfileName, targetVdcName, err)
+		}
+		sourcedItem.StorageProfile = &storageProfile
+	}
+
+	recompose := &types.ReComposeVAppParams{
+		Ovf:         types.XMLNamespaceOVF,
+		Xsi:         types.XMLNamespaceXSI,
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        targetVapp.VApp.Name,
+		SourcedItem: sourcedItem,
+	}
+	log.Printf("[DEBUG] [VM move] moving VM %s from vApp '%s' (VDC '%s') to vApp '%s' (VDC '%s')",
+		vm.VM.Name, oldVappName, sourceVdcName, newVappName, targetVdcName)
+	task, err := vcdClient.Client.ExecuteTaskRequest(strings.TrimSuffix(targetVapp.VApp.HREF, "/")+"/action/recomposeVApp",
+		http.MethodPost, types.MimeRecomposeVappParams, "error moving VM: %s", recompose)
+	if err != nil {
+		return fmt.Errorf("[VM move] error moving VM %s to vApp '%s': %s", vm.VM.Name, newVappName, err)
+	}
+	err = waitForTask(ctx, task)
+	if err != nil {
+		return fmt.Errorf("[VM move] error waiting for the move of VM %s to vApp '%s': %s", vm.VM.Name, newVappName, err)
+	}
+
+	err = targetVapp.Refresh()
+	if err != nil {
+		return fmt.Errorf("[VM move] error refreshing vApp '%s': %s", newVappName, err)
+	}
+	// VCD keeps the ID of moved VMs. Looking it up by name covers the case where it doesn't
+	movedVm, err := targetVapp.GetVMById(d.Id(), false)
+	if govcd.ContainsNotFound(err) {
+		movedVm, err = targetVapp.GetVMByName(vm.VM.Name, false)
+	}
+	if err != nil {
+		return fmt.Errorf("[VM move] error retrieving VM %s in vApp '%s' after the move: %s", vm.VM.Name, newVappName, err)
+	}
+	if movedVm.VM.ID != d.Id() {
+		log.Printf("[WARN] [VM move] VM %s has a new ID after the move: %s", d.Id(), movedVm.VM.ID)
+		d.SetId(movedVm.VM.ID)
+	}
+	dSet(d, "vapp_id", targetVapp.VApp.ID)
+	return nil
+}
+
+// renameVm changes the name of a VM, keeping its description. Unlike most VM changes, it doesn't need
+// the VM to be powered off
+func renameVm(ctx context.Context, vcdClient *VCDClient, vm *govcd.VM, name string) error {
+	payload := &types.Vm{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Ovf:         types.XMLNamespaceOVF,
+		Name:        name,
+		Description: vm.VM.Description,
+	}
+	task, err := vcdClient.Client.ExecuteTaskRequest(strings.TrimSuffix(vm.VM.HREF, "/")+"/action/reconfigureVm",
+		http.MethodPost, types.MimeVM, "error renaming VM: %s", payload)
+	if err != nil {
+		return fmt.Errorf("[VM update] error renaming VM %s to '%s': %s", vm.VM.Name, name, err)
+	}
+	err = waitForTask(ctx, task)
+	if err != nil {
+		return fmt.Errorf("[VM update] error waiting for the rename of VM %s to '%s': %s", vm.VM.Name, name, err)
+	}
+	return vm.Refresh()
+}
@@ -0,0 +1,187 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// TestSimulatorVmMoveAndRename checks the plan and apply of a VM moving to another vApp or VDC, and its rename
+func TestSimulatorVmMoveAndRename(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVAppVm()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "move-source")
+	targetId := sim.AddVapp(simulatorOrg, simulatorVdc, "move-target")
+	sim.AddVappNetwork(simulatorOrg, simulatorVdc, "move-target", "net1")
+	sim.AddVdc(simulatorOrg, "move-vdc", true)
+	remoteId := sim.AddVapp(simulatorOrg, "move-vdc", "move-remote")
+	vmId := sim.AddVm(simulatorOrg, simulatorVdc, "move-source", "app-1")
+	sim.AddVm(simulatorOrg, "move-vdc", "move-remote", "app-2")
+
+	vmState := func(t *testing.T, vdcName, vappName string) *schema.ResourceData {
+		d := simulatorResourceData(t, resource, map[string]interface{}{
+			"org":       simulatorOrg,
+			"vdc":       vdcName,
+			"vapp_name": vappName,
+			"name":      "app-1",
+		})
+		d.SetId(vmId)
+		return d
+	}
+	vmConfig := func(vdcName, vappName string, preventPowerOff bool) map[string]interface{} {
+		return map[string]interface{}{
+			"org":                      simulatorOrg,
+			"vdc":                      vdcName,
+			"vapp_name":                vappName,
+			"name":                     "app-1",
+			"prevent_update_power_off": preventPowerOff,
+		}
+	}
+	expectLocation := func(t *testing.T, vappName, vdcName, vmName string) {
+		gotVapp, gotVdc, gotName, found := sim.VmLocation(vmId)
+		if !found || gotVapp != vappName || gotVdc != vdcName || gotName != vmName {
+			t.Fatalf("expected VM %s in vApp %s of VDC %s, got %s in vApp %s of VDC %s (found: %t)",
+				vmName, vappName, vdcName, gotName, gotVapp, gotVdc, found)
+		}
+	}
+
+	t.Run("Plan", func(t *testing.T) {
+		state := vmState(t, simulatorVdc, "move-source").State()
+		diff, err := simulatorPlan(t, resource, state, vmConfig(simulatorVdc, "move-target", false), vcdClient)
+		if err != nil {
+			t.Fatalf("error planning the move: %s", err)
+		}
+		if diff.RequiresNew() || diff.Attributes["vapp_id"] == nil || !diff.Attributes["vapp_id"].NewComputed {
+			t.Errorf("expected an in-place move with an unknown vApp ID, got %#v", diff.Attributes)
+		}
+
+		// The VM is replaced when the destination lacks the network of a NIC or the storage profile
+		planMove := func(values map[string]interface{}, vdcName, vappName string) *terraform.InstanceDiff {
+			values["org"] = simulatorOrg
+			values["vdc"] = simulatorVdc
+			values["vapp_name"] = "move-source"
+			values["name"] = "app-1"
+			d := simulatorResourceData(t, resource, values)
+			d.SetId(vmId)
+			config := vmConfig(vdcName, vappName, false)
+			for key, value := range values {
+				if key != "vdc" && key != "vapp_name" {
+					config[key] = value
+				}
+			}
+			diff, err := simulatorPlan(t, resource, d.State(), config, vcdClient)
+			if err != nil {
+				t.Fatalf("error planning the move to vApp %s: %s", vappName, err)
+			}
+			return diff
+		}
+		withNic := map[string]interface{}{"network": []interface{}{
+			map[string]interface{}{"type": "vapp", "name": "net1", "ip_allocation_mode": "POOL"},
+		}}
+		tests := []struct {
+			name        string
+			values      map[string]interface{}
+			vdc         string
+			vapp        string
+			wantReplace bool
+		}{
+			{"NetworkInVapp", withNic, simulatorVdc, "move-target", false},
+			{"NetworkNotInVapp", withNic, "move-vdc", "move-remote", true},
+			{"StorageProfileInVdc", map[string]interface{}{"storage_profile": "*"}, "move-vdc", "move-remote", false},
+			{"StorageProfileNotInVdc", map[string]interface{}{"storage_profile": "gold"}, "move-vdc", "move-remote", true},
+			{"VappNotFound", withNic, simulatorVdc, "new-vapp", false},
+		}
+		for _, tt := range tests {
+			values := make(map[string]interface{})
+			for key, value := range tt.values {
+				values[key] = value
+			}
+			if diff := planMove(values, tt.vdc, tt.vapp); diff.RequiresNew() != tt.wantReplace {
+				t.Errorf("%s: expected replacement %t, got %#v", tt.name, tt.wantReplace, diff.Attributes)
+			}
+		}
+
+		standalone := resourceVcdStandaloneVm()
+		diff, err = simulatorPlan(t, standalone, state, map[string]interface{}{"org": simulatorOrg, "vdc": "move-vdc", "name": "app-1"},
+			vcdClient)
+		if err != nil {
+			t.Fatalf("error planning the move of a standalone VM: %s", err)
+		}
+		if !diff.RequiresNew() {
+			t.Errorf("expected the replacement of a standalone VM moving to another VDC")
+		}
+	})
+
+	vm := simulatorVm(t, vcdClient, vmId)
+	powerOnSimulatorVm(t, vm)
+
+	t.Run("PlanPowerCycle", func(t *testing.T) {
+		d := vmState(t, simulatorVdc, "move-source")
+		dSet(d, "status", 4)
+		diff, err := simulatorPlan(t, resource, d.State(), vmConfig(simulatorVdc, "move-target", false), vcdClient)
+		if err != nil {
+			t.Fatalf("error planning the move: %s", err)
+		}
+		if diff.Attributes["requires_power_cycle"] == nil || diff.Attributes["requires_power_cycle"].New != "true" {
+			t.Errorf("expected the move of a running VM to require a power cycle, got %#v", diff.Attributes["requires_power_cycle"])
+		}
+	})
+
+	t.Run("PreventPowerOff", func(t *testing.T) {
+		d := simulatorUpdateData(t, resource, vmState(t, simulatorVdc, "move-source"), vmConfig(simulatorVdc, "move-target", true))
+		err := moveVm(ctx, d, vcdClient)
+		if err == nil || !strings.Contains(err.Error(), "prevent_update_power_off") {
+			t.Fatalf("expected the move to be stopped by 'prevent_update_power_off', got %v", err)
+		}
+		expectLocation(t, "move-source", simulatorVdc, "app-1")
+	})
+
+	t.Run("SameVdc", func(t *testing.T) {
+		d := simulatorUpdateData(t, resource, vmState(t, simulatorVdc, "move-source"), vmConfig(simulatorVdc, "move-target", false))
+		if err := moveVm(ctx, d, vcdClient); err != nil {
+			t.Fatalf("error moving VM: %s", err)
+		}
+		expectLocation(t, "move-target", simulatorVdc, "app-1")
+		if d.Id() != vmId || d.Get("vapp_id").(string) != targetId {
+			t.Errorf("expected VM %s in vApp %s, got %s in vApp %s", vmId, targetId, d.Id(), d.Get("vapp_id"))
+		}
+		if status, _ := sim.Status(vmId); types.VAppStatuses[status] != "POWERED_OFF" {
+			t.Errorf("expected the VM to be powered off for the move, got status %d", status)
+		}
+	})
+
+	t.Run("OtherVdc", func(t *testing.T) {
+		d := simulatorUpdateData(t, resource, vmState(t, simulatorVdc, "move-target"), vmConfig("move-vdc", "move-remote", false))
+		if err := moveVm(ctx, d, vcdClient); err != nil {
+			t.Fatalf("error moving VM to another VDC: %s", err)
+		}
+		expectLocation(t, "move-remote", "move-vdc", "app-1")
+		if d.Get("vapp_id").(string) != remoteId {
+			t.Errorf("expected vApp ID %s, got %s", remoteId, d.Get("vapp_id"))
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		if err := vm.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		if err := renameVm(ctx, vcdClient, vm, "app-2"); err == nil {
+			t.Fatal("expected an error renaming the VM with the name of another VM of the vApp")
+		}
+		if err := renameVm(ctx, vcdClient, vm, "app-renamed"); err != nil {
+			t.Fatalf("error renaming VM: %s", err)
+		}
+		expectLocation(t, "move-remote", "move-vdc", "app-renamed")
+		if vm.VM.Name != "app-renamed" || vm.VM.ID != vmId {
+			t.Errorf("expected the refreshed VM to be %s with ID %s, got %s with ID %s", "app-renamed", vmId, vm.VM.Name, vm.VM.ID)
+		}
+	})
+}
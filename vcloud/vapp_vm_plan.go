@@ -0,0 +1,426 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// The plan of a VM predicts what the update will do, so that reboots can be approved before the apply:
+// * `requires_power_cycle` is true when the update powers off a running VM and powers it on again
+// * changes that the update would reject are reported as plan errors: CPU and memory that conflict
+//   with the sizing policy, hot changes that VCD can't apply, hardware version downgrades, and NICs
+//   that don't fit the static IP pool of their network
+// The checks that need VCD only run for values known at plan time, and for entities that already exist.
+
+// vmChangeReader is the part of schema.ResourceData and schema.ResourceDiff used to decide how a VM is
+// updated, so that the apply and the plan share the same decisions
+type vmChangeReader interface {
+	Get(key string) interface{}
+	GetChange(key string) (interface{}, interface{})
+	HasChange(key string) bool
+}
+
+// vmColdUpdateFields are the fields that can only be changed while the VM is powered off
+var vmColdUpdateFields = []string{"cpu_cores", "disk", "expose_hardware_virtualization", "boot_image",
+	"hardware_version", "os_type", "description", "cpu_hot_add_enabled", "memory_hot_add_enabled", "firmware",
+	"boot_options.0.efi_secure_boot"}
+
+// vmColdChanges returns the changed fields that need the VM to be powered off, as applied by
+// resourceVcdVAppVmUpdateExecute
+func vmColdChanges(d vmChangeReader) []string {
+	var changes []string
+	for _, field := range vmColdUpdateFields {
+		if d.HasChange(field) {
+			changes = append(changes, field)
+		}
+	}
+	if !d.Get("memory_hot_add_enabled").(bool) && d.HasChange("memory") {
+		changes = append(changes, "memory")
+	}
+	if !d.Get("cpu_hot_add_enabled").(bool) && d.HasChange("cpus") {
+		changes = append(changes, "cpus")
+	}
+	if d.HasChange("network") && isPrimaryNicRemoved(d) {
+		changes = append(changes, "network")
+	}
+	return changes
+}
+
+// vmCustomizeDiff is the CustomizeDiff function of vcloud_vapp_vm and vcloud_vm
+func vmCustomizeDiff(vmType typeOfVm) schema.CustomizeDiffFunc {
+	moveCustomizeDiff := vmMoveCustomizeDiff(vmType)
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		err := deletionProtectionCustomizeDiff(ctx, d, meta)
+		if err != nil {
+			return err
+		}
+		err = moveCustomizeDiff(ctx, d, meta)
+		if err != nil {
+			return err
+		}
+		vcdClient := meta.(*VCDClient)
+
+		if d.Id() == "" {
+			err = d.SetNew("requires_power_cycle", false)
+			if err != nil {
+				return err
+			}
+		} else {
+			if !vmHasPlannedChanges(d) {
+				return nil
+			}
+			reasons := vmPowerCycleReasons(vcdClient, d, vmType)
+			if len(reasons) > 0 {
+				log.Printf("[INFO] VM %s requires a power cycle to update: %s", d.Get("name"), strings.Join(reasons, ", "))
+				if d.Get("prevent_update_power_off").(bool) {
+					return fmt.Errorf("VM %s needs to power off to change %s, but `prevent_update_power_off` is `true`",
+						d.Get("name"), strings.Join(reasons, ", "))
+				}
+			}
+			err = d.SetNew("requires_power_cycle", len(reasons) > 0)
+			if err != nil {
+				return err
+			}
+			err = checkVmHotChanges(d)
+			if err != nil {
+				return err
+			}
+			err = checkVmHardwareVersionChange(d.GetChange("hardware_version"))
+			if err != nil {
+				return err
+			}
+		}
+
+		firmware := d.Get("firmware").(string)
+		if d.HasChanges("firmware", "boot_options") && firmware != "" && firmware != "efi" && d.Get("boot_options.0.efi_secure_boot").(bool) {
+			return fmt.Errorf("`boot_options.0.efi_secure_boot` can only be enabled with `firmware` set to 'efi'")
+		}
+		return checkVmPlanAgainstVcd(vcdClient, d)
+	}
+}
+
+// vmHasPlannedChanges returns true if the plan changes any field other than `requires_power_cycle`
+func vmHasPlannedChanges(d *schema.ResourceDiff) bool {
+	for _, key := range d.GetChangedKeysPrefix("") {
+		if key != "requires_power_cycle" {
+			return true
+		}
+	}
+	return false
+}
+
+// vmPowerCycleReasons returns the changed fields that will make the update power off the VM and power it on
+// again. This only happens to a VM that is running and stays on, according to `power_on`
+func vmPowerCycleReasons(vcdClient *VCDClient, d *schema.ResourceDiff, vmType typeOfVm) []string {
+	oldStatus, _ := d.GetChange("status")
+	if types.VAppStatuses[oldStatus.(int)] != "POWERED_ON" || !d.Get("power_on").(bool) {
+		return nil
+	}
+	reasons := vmColdChanges(d)
+	if vmType == vappVmType {
+		// A VM is moved to another vApp while powered off
+		oldVdc, newVdc := d.GetChange("vdc")
+		if d.HasChange("vapp_name") || vdcNameOrDefault(vcdClient, oldVdc.(string)) != vdcNameOrDefault(vcdClient, newVdc.(string)) {
+			reasons = append(reasons, "vapp_name")
+		}
+	}
+	if isForcedCustomization(d.Get("customization")) {
+		reasons = append(reasons, "customization.0.force")
+	}
+	return reasons
+}
+
+// checkVmHotChanges reports the changes that the update would apply to a running VM through hot add, but
+// VCD can't apply: hot add can't remove CPUs or memory, and it must be enabled before it is used
+func checkVmHotChanges(d vmChangeReader) error {
+	oldStatus, _ := d.GetChange("status")
+	if types.VAppStatuses[oldStatus.(int)] != "POWERED_ON" {
+		return nil
+	}
+	for _, resource := range []struct{ field, hotAddField string }{
+		{"memory", "memory_hot_add_enabled"},
+		{"cpus", "cpu_hot_add_enabled"},
+	} {
+		if !d.HasChange(resource.field) || !d.Get(resource.hotAddField).(bool) {
+			continue
+		}
+		oldValue, newValue := d.GetChange(resource.field)
+		if d.HasChange(resource.hotAddField) {
+			return fmt.Errorf("`%s` can't change on a running VM in the same update that enables `%s`: "+
+				"enable it first, or set `power_on = false`", resource.field, resource.hotAddField)
+		}
+		if newValue.(int) < oldValue.(int) {
+			return fmt.Errorf("`%s` can't be reduced from %d to %d on a running VM, as `%s` only allows adding: "+
+				"set `power_on = false`, or disable `%s`", resource.field, oldValue, newValue, resource.hotAddField, resource.hotAddField)
+		}
+	}
+	return nil
+}
+
+// hardwareVersionNumber returns the number of a hardware version such as 'vmx-19'
+func hardwareVersionNumber(hardwareVersion string) (int, bool) {
+	number, err := strconv.Atoi(strings.TrimPrefix(hardwareVersion, "vmx-"))
+	return number, err == nil
+}
+
+// checkVmHardwareVersionChange reports a downgrade of the hardware version, which vSphere doesn't support
+func checkVmHardwareVersionChange(oldValue, newValue interface{}) error {
+	oldNumber, oldOk := hardwareVersionNumber(oldValue.(string))
+	newNumber, newOk := hardwareVersionNumber(newValue.(string))
+	if oldOk && newOk && newNumber < oldNumber {
+		return fmt.Errorf("`hardware_version` can't be downgraded from '%s' to '%s'", oldValue, newValue)
+	}
+	return nil
+}
+
+// checkVmPlanAgainstVcd runs the plan checks that need the sizing policy, the VDC and the networks of the VM
+func checkVmPlanAgainstVcd(vcdClient *VCDClient, d *schema.ResourceDiff) error {
+	if d.Id() == "" || d.HasChanges("sizing_policy_id", "cpus", "cpu_cores", "memory") {
+		if sizingPolicyId := d.Get("sizing_policy_id").(string); sizingPolicyId != "" && d.NewValueKnown("sizing_policy_id") {
+			policy, err := vcdClient.GetVdcComputePolicyV2ById(sizingPolicyId)
+			if err != nil {
+				return fmt.Errorf("error retrieving sizing policy %s: %s", sizingPolicyId, err)
+			}
+			err = checkVmSizingPolicyConflicts(policy.VdcComputePolicyV2, configuredInt(d, "cpus"), configuredInt(d, "cpu_cores"), configuredInt(d, "memory"))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	hardwareVersion := d.Get("hardware_version").(string)
+	checkHardwareVersion := d.HasChange("hardware_version") && hardwareVersion != "" && d.NewValueKnown("hardware_version")
+	checkNetworks := d.HasChange("network")
+	if !checkHardwareVersion && !checkNetworks {
+		return nil
+	}
+	if !d.NewValueKnown("org") || !d.NewValueKnown("vdc") {
+		return nil
+	}
+	orgName := d.Get("org").(string)
+	if orgName == "" {
+		orgName = vcdClient.Org
+	}
+	_, vdc, err := vcdClient.GetOrgAndVdc(orgName, vdcNameOrDefault(vcdClient, d.Get("vdc").(string)))
+	if govcd.ContainsNotFound(err) {
+		// The VDC can be created in the same apply
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error retrieving VDC to check the VM plan: %s", err)
+	}
+
+	if checkHardwareVersion && !vdcSupportsHardwareVersion(vdc.Vdc, hardwareVersion) {
+		return fmt.Errorf("`hardware_version` '%s' is not supported by VDC '%s'", hardwareVersion, vdc.Vdc.Name)
+	}
+	if checkNetworks {
+		return checkVmNicsAgainstNetworks(vdc, d)
+	}
+	return nil
+}
+
+// configuredInt returns the value of an integer field if it is set in the configuration, and nil if it
+// is not set or not known yet. Computed fields such as `memory` hold a value from the state otherwise
+func configuredInt(d *schema.ResourceDiff, field string) *int {
+	value := d.GetRawConfig().GetAttr(field)
+	if value.IsNull() || !value.IsKnown() {
+		return nil
+	}
+	return addrOf(d.Get(field).(int))
+}
+
+// checkVmSizingPolicyConflicts reports the CPU and memory settings that differ from the ones defined by the
+// sizing policy, which VCD enforces
+func checkVmSizingPolicyConflicts(policy *types.VdcComputePolicyV2, cpus, cpuCores, memory *int) error {
+	for _, setting := range []struct {
+		field      string
+		configured *int
+		policy     *int
+	}{
+		{"cpus", cpus, policy.CPUCount},
+		{"cpu_cores", cpuCores, policy.CoresPerSocket},
+		{"memory", memory, policy.Memory},
+	} {
+		if setting.configured != nil && setting.policy != nil && *setting.configured != *setting.policy {
+			return fmt.Errorf("`%s` is %d, but sizing policy '%s' sets it to %d: remove `%s` or use a sizing policy that does not set it",
+				setting.field, *setting.configured, policy.Name, *setting.policy, setting.field)
+		}
+	}
+	return nil
+}
+
+// vdcSupportsHardwareVersion returns true if the VDC lists the hardware version among the supported ones,
+// or if it doesn't report them
+func vdcSupportsHardwareVersion(vdc *types.Vdc, hardwareVersion string) bool {
+	if len(vdc.Capabilities) == 0 || vdc.Capabilities[0].SupportedHardwareVersions == nil {
+		return true
+	}
+	for _, supported := range vdc.Capabilities[0].SupportedHardwareVersions.SupportedHardwareVersion {
+		if supported.Name == hardwareVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// vmNicPlan is a NIC in the plan of a VM
+type vmNicPlan struct {
+	index          int
+	networkType    string
+	networkName    string
+	allocationMode string
+	ip             string
+}
+
+// plannedVmNics returns the NICs of the given `network` list value
+func plannedVmNics(networks interface{}) []vmNicPlan {
+	var nics []vmNicPlan
+	for index, network := range networks.([]interface{}) {
+		nic, ok := network.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		plan := vmNicPlan{index: index}
+		plan.networkType, _ = nic["type"].(string)
+		plan.networkName, _ = nic["name"].(string)
+		plan.allocationMode, _ = nic["ip_allocation_mode"].(string)
+		plan.ip, _ = nic["ip"].(string)
+		nics = append(nics, plan)
+	}
+	return nics
+}
+
+// checkVmNicsAgainstNetworks checks the NICs connected to Org VDC networks against the subnets and the static
+// IP pools of the networks
+func checkVmNicsAgainstNetworks(vdc *govcd.Vdc, d *schema.ResourceDiff) error {
+	oldNetworks, newNetworks := d.GetChange("network")
+	oldNics := plannedVmNics(oldNetworks)
+	newNics := plannedVmNics(newNetworks)
+
+	rawNetworks := d.GetRawConfig().GetAttr("network")
+	byNetwork := make(map[string][]vmNicPlan)
+	for _, nic := range newNics {
+		if nic.allocationMode == "MANUAL" && nic.ip == "" && nicConfigKnown(rawNetworks, nic.index, "ip") {
+			return fmt.Errorf("NIC %d: `ip` is required with `ip_allocation_mode` MANUAL", nic.index)
+		}
+		if nic.networkType != "org" || nic.networkName == "" || !nicConfigKnown(rawNetworks, nic.index, "name") {
+			continue
+		}
+		byNetwork[nic.networkName] = append(byNetwork[nic.networkName], nic)
+	}
+
+	names := make([]string, 0, len(byNetwork))
+	for name := range byNetwork {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		network, err := vdc.GetOpenApiOrgVdcNetworkByName(name)
+		if govcd.ContainsNotFound(err) {
+			// The network can be created in the same apply, or be shared by a VDC group
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error retrieving Org VDC network '%s' to check the VM plan: %s", name, err)
+		}
+		err = checkVmNicsInNetwork(network.OpenApiOrgVdcNetwork, byNetwork[name], countPoolNics(oldNics, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nicConfigKnown returns true if the field of the NIC at the given index is known in the configuration
+func nicConfigKnown(rawNetworks cty.Value, index int, field string) bool {
+	if rawNetworks.IsNull() || !rawNetworks.IsKnown() || !rawNetworks.CanIterateElements() || rawNetworks.LengthInt() <= index {
+		return false
+	}
+	nic := rawNetworks.Index(cty.NumberIntVal(int64(index)))
+	if nic.IsNull() || !nic.IsKnown() {
+		return false
+	}
+	return nic.GetAttr(field).IsKnown()
+}
+
+// countPoolNics returns the number of NICs that take an IP from the static IP pool of the given network
+func countPoolNics(nics []vmNicPlan, networkName string) int {
+	count := 0
+	for _, nic := range nics {
+		if nic.networkType == "org" && nic.networkName == networkName && nic.allocationMode == "POOL" {
+			count++
+		}
+	}
+	return count
+}
+
+// checkVmNicsInNetwork checks that MANUAL IPs belong to a subnet of the network, and that the static IP pool
+// has room for the NICs that will take an IP from it. poolNicsInState is the number of NICs that already
+// hold an IP of the pool
+func checkVmNicsInNetwork(network *types.OpenApiOrgVdcNetwork, nics []vmNicPlan, poolNicsInState int) error {
+	var subnets []netip.Prefix
+	poolSize := 0
+	for _, subnet := range network.Subnets.Values {
+		gateway, err := netip.ParseAddr(subnet.Gateway)
+		if err != nil {
+			continue
+		}
+		subnets = append(subnets, netip.PrefixFrom(gateway, subnet.PrefixLength).Masked())
+		poolSize += len(subnet.IPRanges.Values)
+	}
+
+	for _, nic := range nics {
+		if nic.allocationMode != "MANUAL" || nic.ip == "" {
+			continue
+		}
+		ip, err := netip.ParseAddr(nic.ip)
+		if err != nil {
+			return fmt.Errorf("NIC %d: invalid IP '%s': %s", nic.index, nic.ip, err)
+		}
+		inSubnet := false
+		for i, subnet := range subnets {
+			if subnet.Contains(ip) {
+				inSubnet = true
+				if ip.String() == network.Subnets.Values[i].Gateway {
+					return fmt.Errorf("NIC %d: IP %s is the gateway of network '%s'", nic.index, ip, network.Name)
+				}
+			}
+		}
+		if !inSubnet && len(subnets) > 0 {
+			return fmt.Errorf("NIC %d: IP %s is not in a subnet of network '%s' (%s)", nic.index, ip, network.Name, prefixesString(subnets))
+		}
+	}
+
+	poolNics := countPoolNics(nics, network.Name)
+	if poolNics == 0 {
+		return nil
+	}
+	if poolSize == 0 {
+		return fmt.Errorf("%d NIC(s) use `ip_allocation_mode` POOL, but network '%s' has no static IP pool", poolNics, network.Name)
+	}
+	if network.TotalIpCount != nil && network.UsedIpCount != nil {
+		free := *network.TotalIpCount - *network.UsedIpCount
+		if added := poolNics - poolNicsInState; added > free {
+			return fmt.Errorf("%d more NIC(s) need an IP from the static IP pool of network '%s', which has %d free", added, network.Name, free)
+		}
+	}
+	return nil
+}
+
+func prefixesString(prefixes []netip.Prefix) string {
+	result := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		result[i] = prefix.String()
+	}
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,84 @@
+//go:build simulator || ALL
+
+package vcloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestSimulatorVmPowerCyclePlan checks that `requires_power_cycle` is only set by the plan that predicts the
+// power cycle: after the apply, a plan of the same configuration is empty
+func TestSimulatorVmPowerCyclePlan(t *testing.T) {
+	sim, vcdClient := newSimulatorClient(t)
+	ctx := context.Background()
+	resource := resourceVcdVAppVm()
+
+	sim.AddVapp(simulatorOrg, simulatorVdc, "vapp1")
+	vmId := sim.AddVm(simulatorOrg, simulatorVdc, "vapp1", "vm1")
+	powerOnSimulatorVm(t, simulatorVm(t, vcdClient, vmId))
+
+	plan := func(state *terraform.InstanceState, memory int) *terraform.InstanceDiff {
+		diff, err := simulatorPlan(t, resource, state, map[string]interface{}{
+			"org":       simulatorOrg,
+			"vdc":       simulatorVdc,
+			"vapp_name": "vapp1",
+			"name":      "vm1",
+			"memory":    memory,
+			"cpus":      1,
+			"cpu_cores": 1,
+			"power_on":  true,
+		}, vcdClient)
+		if err != nil {
+			t.Fatalf("error planning memory %d: %s", memory, err)
+		}
+		return diff
+	}
+	read := func(d *schema.ResourceData) *terraform.InstanceState {
+		if diags := resource.ReadContext(ctx, d, vcdClient); diags.HasError() {
+			t.Fatalf("error reading VM: %v", diags)
+		}
+		return d.State()
+	}
+
+	d := simulatorResourceData(t, resource, map[string]interface{}{
+		"org": simulatorOrg, "vdc": simulatorVdc, "vapp_name": "vapp1", "name": "vm1",
+	})
+	d.SetId(vmId)
+	state := read(d)
+
+	// The plan predicts the power cycle of a cold memory change
+	diff := plan(state, 2048)
+	if diff.Attributes["requires_power_cycle"] == nil || diff.Attributes["requires_power_cycle"].New != "true" {
+		t.Fatalf("expected requires_power_cycle to be planned as true, got %#v", diff.Attributes["requires_power_cycle"])
+	}
+
+	// The apply powers the VM off, changes the memory and powers it on again
+	updated, err := schema.InternalMap(resource.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("error preparing the update: %s", err)
+	}
+	if diags := resource.UpdateContext(ctx, updated, vcdClient); diags.HasError() {
+		t.Fatalf("error updating VM: %v", diags)
+	}
+	state = updated.State()
+	if state.Attributes["requires_power_cycle"] != "false" {
+		t.Errorf("expected requires_power_cycle to be false after the update, got %q", state.Attributes["requires_power_cycle"])
+	}
+	if state.Attributes["memory"] != "2048" {
+		t.Errorf("expected memory 2048 after the update, got %q", state.Attributes["memory"])
+	}
+	if status, _ := sim.Status(vmId); status != 4 {
+		t.Errorf("expected the VM to be powered on after the update, got status %d", status)
+	}
+
+	// The refresh and the next plan of the same configuration change nothing
+	state = read(updated)
+	diff = plan(state, 2048)
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected an empty plan after the update, got %#v", diff.Attributes)
+	}
+}
@@ -0,0 +1,214 @@
+//go:build unit || ALL
+
+package vcloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// vmPlanState returns the state of a vApp VM with the given values and status
+func vmPlanState(t *testing.T, values map[string]interface{}, status int) *terraform.InstanceState {
+	d := schema.TestResourceDataRaw(t, resourceVcdVAppVm().Schema, values)
+	d.SetId("urn:vcloud:vm:11111111-2222-3333-4444-555555555555")
+	if err := d.Set("status", status); err != nil {
+		t.Fatalf("error setting status: %s", err)
+	}
+	return d.State()
+}
+
+// planVmUpdate runs the plan of a vApp VM from the state to the given configuration, including its
+// CustomizeDiff
+func planVmUpdate(t *testing.T, state *terraform.InstanceState, config map[string]interface{}) (*terraform.InstanceDiff, error) {
+	resource := resourceVcdVAppVm()
+	vcdClient := &VCDClient{Org: "org1", Vdc: "vdc1"}
+	return schema.InternalMap(resource.Schema).Diff(context.Background(), state, terraform.NewResourceConfigRaw(config),
+		resource.CustomizeDiff, vcdClient, true)
+}
+
+func vmPlanValues(changes map[string]interface{}) map[string]interface{} {
+	values := map[string]interface{}{
+		"vapp_name": "vapp1",
+		"name":      "vm1",
+		"memory":    2048,
+		"cpus":      2,
+		"cpu_cores": 1,
+		"power_on":  true,
+	}
+	for key, value := range changes {
+		values[key] = value
+	}
+	return values
+}
+
+// Test_vmPowerCyclePlan checks the prediction of power cycles and the plan errors of running VMs
+func Test_vmPowerCyclePlan(t *testing.T) {
+	const poweredOn, poweredOff = 4, 8
+	tests := []struct {
+		name       string
+		state      map[string]interface{}
+		status     int
+		config     map[string]interface{}
+		wantCycle  bool
+		wantErrMsg string
+	}{
+		{name: "ColdMemory", config: vmPlanValues(map[string]interface{}{"memory": 4096}), status: poweredOn, wantCycle: true},
+		{name: "CpuCores", config: vmPlanValues(map[string]interface{}{"cpu_cores": 2}), status: poweredOn, wantCycle: true},
+		{name: "PoweredOff", config: vmPlanValues(map[string]interface{}{"memory": 4096}), status: poweredOff},
+		{name: "PowerOff", config: vmPlanValues(map[string]interface{}{"memory": 4096, "power_on": false}), status: poweredOn},
+		{name: "Rename", config: vmPlanValues(map[string]interface{}{"name": "vm2"}), status: poweredOn},
+		{
+			name:   "HotMemory",
+			state:  vmPlanValues(map[string]interface{}{"memory_hot_add_enabled": true}),
+			config: vmPlanValues(map[string]interface{}{"memory_hot_add_enabled": true, "memory": 4096}),
+			status: poweredOn,
+		},
+		{
+			name:       "HotMemoryReduced",
+			state:      vmPlanValues(map[string]interface{}{"memory_hot_add_enabled": true}),
+			config:     vmPlanValues(map[string]interface{}{"memory_hot_add_enabled": true, "memory": 1024}),
+			status:     poweredOn,
+			wantErrMsg: "`memory` can't be reduced from 2048 to 1024",
+		},
+		{
+			name:       "HotCpuEnabledTogether",
+			config:     vmPlanValues(map[string]interface{}{"cpu_hot_add_enabled": true, "cpus": 4}),
+			status:     poweredOn,
+			wantErrMsg: "in the same update that enables `cpu_hot_add_enabled`",
+		},
+		{
+			name:       "PreventPowerOff",
+			config:     vmPlanValues(map[string]interface{}{"cpu_cores": 2, "prevent_update_power_off": true}),
+			status:     poweredOn,
+			wantErrMsg: "needs to power off to change cpu_cores",
+		},
+		{
+			name:       "HardwareVersionDowngrade",
+			state:      vmPlanValues(map[string]interface{}{"hardware_version": "vmx-19"}),
+			config:     vmPlanValues(map[string]interface{}{"hardware_version": "vmx-14"}),
+			status:     poweredOff,
+			wantErrMsg: "can't be downgraded from 'vmx-19' to 'vmx-14'",
+		},
+		{
+			name:       "SecureBootWithBios",
+			config:     vmPlanValues(map[string]interface{}{"firmware": "bios", "boot_options": []interface{}{map[string]interface{}{"efi_secure_boot": true}}}),
+			status:     poweredOff,
+			wantErrMsg: "efi_secure_boot` can only be enabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateValues := tt.state
+			if stateValues == nil {
+				stateValues = vmPlanValues(nil)
+			}
+			diff, err := planVmUpdate(t, vmPlanState(t, stateValues, tt.status), tt.config)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErrMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff.RequiresNew() {
+				t.Errorf("expected an update in place")
+			}
+			gotCycle := diff.Attributes["requires_power_cycle"] != nil && diff.Attributes["requires_power_cycle"].New == "true"
+			if gotCycle != tt.wantCycle {
+				t.Errorf("expected requires_power_cycle %t, got %#v", tt.wantCycle, diff.Attributes["requires_power_cycle"])
+			}
+		})
+	}
+
+	// A plan without changes leaves the flag alone, even if a stale state still has it. The state has the
+	// computed blocks that a read would set
+	state := vmPlanState(t, vmPlanValues(nil), poweredOn)
+	for _, key := range []string{"boot_options.#", "customization.#", "extra_config.#", "internal_disk.#", "inherited_metadata.%", "metadata.%"} {
+		state.Attributes[key] = "0"
+	}
+	state.Attributes["requires_power_cycle"] = "true"
+	diff, err := planVmUpdate(t, state, vmPlanValues(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected an empty plan, got %#v", diff.Attributes)
+	}
+}
+
+// Test_checkVmSizingPolicyConflicts checks that only configured values that differ from the policy are reported
+func Test_checkVmSizingPolicyConflicts(t *testing.T) {
+	policy := &types.VdcComputePolicyV2{
+		VdcComputePolicy: types.VdcComputePolicy{Name: "small", CPUCount: addrOf(2), Memory: addrOf(2048)},
+	}
+	if err := checkVmSizingPolicyConflicts(policy, addrOf(2), addrOf(4), addrOf(2048)); err != nil {
+		t.Errorf("unexpected error with values matching the policy: %s", err)
+	}
+	if err := checkVmSizingPolicyConflicts(policy, nil, nil, nil); err != nil {
+		t.Errorf("unexpected error without configured values: %s", err)
+	}
+	err := checkVmSizingPolicyConflicts(policy, nil, nil, addrOf(4096))
+	if err == nil || !strings.Contains(err.Error(), "`memory` is 4096, but sizing policy 'small' sets it to 2048") {
+		t.Errorf("expected a memory conflict, got %v", err)
+	}
+}
+
+// Test_checkVmNicsInNetwork checks MANUAL IPs against the subnets and POOL NICs against the static IP pool
+func Test_checkVmNicsInNetwork(t *testing.T) {
+	network := &types.OpenApiOrgVdcNetwork{
+		Name: "net1",
+		Subnets: types.OrgVdcNetworkSubnets{Values: []types.OrgVdcNetworkSubnetValues{{
+			Gateway:      "10.0.0.1",
+			PrefixLength: 24,
+			IPRanges: types.OrgVdcNetworkSubnetIPRanges{Values: []types.OrgVdcNetworkSubnetIPRangeValues{
+				{StartAddress: "10.0.0.10", EndAddress: "10.0.0.12"},
+			}},
+		}}},
+		TotalIpCount: addrOf(3),
+		UsedIpCount:  addrOf(2),
+	}
+	manual := func(ip string) vmNicPlan {
+		return vmNicPlan{networkType: "org", networkName: "net1", allocationMode: "MANUAL", ip: ip}
+	}
+	pool := vmNicPlan{networkType: "org", networkName: "net1", allocationMode: "POOL"}
+
+	tests := []struct {
+		name            string
+		nics            []vmNicPlan
+		poolNicsInState int
+		wantErrMsg      string
+	}{
+		{name: "ManualInSubnet", nics: []vmNicPlan{manual("10.0.0.50")}},
+		{name: "ManualOutOfSubnet", nics: []vmNicPlan{manual("10.0.1.5")}, wantErrMsg: "not in a subnet of network 'net1' (10.0.0.0/24)"},
+		{name: "ManualGateway", nics: []vmNicPlan{manual("10.0.0.1")}, wantErrMsg: "is the gateway"},
+		{name: "OnePoolNic", nics: []vmNicPlan{pool}},
+		{name: "PoolExhausted", nics: []vmNicPlan{pool, pool}, wantErrMsg: "2 more NIC(s) need an IP from the static IP pool of network 'net1', which has 1 free"},
+		{name: "PoolNicsKept", nics: []vmNicPlan{pool, pool}, poolNicsInState: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVmNicsInNetwork(network, tt.nics, tt.poolNicsInState)
+			if tt.wantErrMsg == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tt.wantErrMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErrMsg)) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErrMsg, err)
+			}
+		})
+	}
+
+	noPool := &types.OpenApiOrgVdcNetwork{Name: "net2", Subnets: types.OrgVdcNetworkSubnets{
+		Values: []types.OrgVdcNetworkSubnetValues{{Gateway: "10.0.2.1", PrefixLength: 24}},
+	}}
+	err := checkVmNicsInNetwork(noPool, []vmNicPlan{{networkType: "org", networkName: "net2", allocationMode: "POOL"}}, 0)
+	if err == nil || !strings.Contains(err.Error(), "has no static IP pool") {
+		t.Errorf("expected an error for a network without static IP pool, got %v", err)
+	}
+}
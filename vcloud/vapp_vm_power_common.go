@@ -0,0 +1,164 @@
+package vcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/go-vcloud-director/v3/govcd"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Values of the `power_off_mode` field of vApps and VMs
+const (
+	powerOffModeShutdownGuest = "shutdown_guest"
+	powerOffModePowerOff      = "power_off"
+	powerOffModeSuspend       = "suspend"
+)
+
+// defaultGuestShutdownTimeout is the number of seconds that a guest OS gets to shut down before the
+// VM or vApp is powered off
+const defaultGuestShutdownTimeout = 300
+
+// undeployPowerActions maps `power_off_mode` values to the power actions of the undeploy API call
+var undeployPowerActions = map[string]string{
+	powerOffModeShutdownGuest: "shutdown",
+	powerOffModePowerOff:      "powerOff",
+	powerOffModeSuspend:       "suspend",
+}
+
+// powerOffModeSchema returns the `power_off_mode` field shared by vcloud_vapp, vcloud_vapp_vm and
+// vcloud_vm
+func powerOffModeSchema(entity string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      powerOffModePowerOff,
+		ValidateFunc: validation.StringInSlice([]string{powerOffModeShutdownGuest, powerOffModePowerOff, powerOffModeSuspend}, false),
+		Description: fmt.Sprintf("How the %s is powered off when an update or destroy requires it. One of '%s', '%s' or '%s'",
+			entity, powerOffModeShutdownGuest, powerOffModePowerOff, powerOffModeSuspend),
+	}
+}
+
+// guestShutdownTimeoutSchema returns the `guest_shutdown_timeout` field shared by vcloud_vapp,
+// vcloud_vapp_vm and vcloud_vm
+func guestShutdownTimeoutSchema(entity string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      defaultGuestShutdownTimeout,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description: fmt.Sprintf("Seconds to wait for the guest OS to shut down when 'power_off_mode' is '%s'. "+
+			"The %s is powered off when the timeout expires", powerOffModeShutdownGuest, entity),
+	}
+}
+
+// getPowerOffMode returns the `power_off_mode` and `guest_shutdown_timeout` settings of a resource.
+// Resources without these fields, such as vcloud_cloned_vapp, get the defaults.
+func getPowerOffMode(d *schema.ResourceData) (string, time.Duration) {
+	mode, _ := d.Get("power_off_mode").(string)
+	if mode == "" {
+		mode = powerOffModePowerOff
+	}
+	timeout, _ := d.Get("guest_shutdown_timeout").(int)
+	if timeout <= 0 {
+		timeout = defaultGuestShutdownTimeout
+	}
+	return mode, time.Duration(timeout) * time.Second
+}
+
+// vmUpdatePowerOffMode returns the power off mode to use when an update needs to undeploy a VM. A
+// suspended VM cannot be reconfigured, so `suspend` is only honoured when the power state is the
+// only change. Otherwise, the guest OS is shut down instead.
+func vmUpdatePowerOffMode(d *schema.ResourceData, needsReconfiguration bool) string {
+	mode, _ := getPowerOffMode(d)
+	if mode == powerOffModeSuspend && needsReconfiguration {
+		return powerOffModeShutdownGuest
+	}
+	return mode
+}
+
+// undeployVm undeploys a VM using the requested power off mode
+func undeployVm(ctx context.Context, vcdClient *VCDClient, vm *govcd.VM, mode string, timeout time.Duration) error {
+	return undeployWithMode(ctx, vcdClient, vm.VM.HREF, "VM "+vm.VM.Name, mode, timeout, vm.IsDeployed)
+}
+
+// undeployVapp undeploys a vApp and all its VMs using the requested power off mode
+func undeployVapp(ctx context.Context, vcdClient *VCDClient, vapp *govcd.VApp, mode string, timeout time.Duration) error {
+	isDeployed := func() (bool, error) {
+		err := vapp.Refresh()
+		if err != nil {
+			return false, fmt.Errorf("error refreshing vApp: %s", err)
+		}
+		return vapp.VApp.Deployed, nil
+	}
+	return undeployWithMode(ctx, vcdClient, vapp.VApp.HREF, "vApp "+vapp.VApp.Name, mode, timeout, isDeployed)
+}
+
+// undeployWithMode runs the undeploy action on the entity at the given HREF. With
+// `powerOffModeShutdownGuest`, the guest OS gets `timeout` to shut down. When the timeout expires or
+// the guest shutdown fails (e.g. because VMware Tools are not running), the shutdown task is
+// cancelled and the entity is powered off instead.
+func undeployWithMode(ctx context.Context, vcdClient *VCDClient, href, entityName, mode string, timeout time.Duration, isDeployed func() (bool, error)) error {
+	if mode != powerOffModeShutdownGuest {
+		task, err := startUndeploy(vcdClient, href, undeployPowerActions[mode])
+		if err != nil {
+			return fmt.Errorf("error triggering undeploy (%s) for %s: %s", mode, entityName, err)
+		}
+		err = waitForTask(ctx, task)
+		if err != nil {
+			return fmt.Errorf("error waiting for undeploy (%s) task for %s: %s", mode, entityName, err)
+		}
+		return nil
+	}
+
+	log.Printf("[DEBUG] shutting down guest OS of %s, waiting up to %s", entityName, timeout)
+	task, err := startUndeploy(vcdClient, href, undeployPowerActions[powerOffModeShutdownGuest])
+	if err == nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = waitForTask(shutdownCtx, task)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		cancelErr := task.CancelTask()
+		if cancelErr != nil {
+			log.Printf("[DEBUG] error cancelling guest shutdown task of %s: %s", entityName, cancelErr)
+		}
+	}
+	log.Printf("[WARN] guest shutdown of %s did not complete (%s): powering it off", entityName, err)
+
+	// The guest may have completed the shutdown while the task was being cancelled
+	deployed, err := isDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		return nil
+	}
+	task, err = startUndeploy(vcdClient, href, undeployPowerActions[powerOffModePowerOff])
+	if err != nil {
+		return fmt.Errorf("error triggering undeploy for %s: %s", entityName, err)
+	}
+	err = waitForTask(ctx, task)
+	if err != nil {
+		return fmt.Errorf("error waiting for undeploy task for %s: %s", entityName, err)
+	}
+	return nil
+}
+
+// startUndeploy posts an undeploy request with the given power action ("powerOff", "shutdown" or
+// "suspend") to a vApp or VM
+func startUndeploy(vcdClient *VCDClient, href, powerAction string) (govcd.Task, error) {
+	params := &types.UndeployVAppParams{
+		Xmlns:               types.XMLNamespaceVCloud,
+		UndeployPowerAction: powerAction,
+	}
+	return vcdClient.Client.ExecuteTaskRequest(strings.TrimSuffix(href, "/")+"/action/undeploy", http.MethodPost,
+		types.MimeUndeployVappParams, "error undeploying: %s", params)
+}